@@ -0,0 +1,92 @@
+package autoscaling
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+// BasicClient provides a cocoa.AutoScalingClient implementation that wraps the
+// AWS EC2 Auto Scaling API. It supports retrying requests using exponential
+// backoff and jitter.
+type BasicClient struct {
+	awsutil.BaseClient
+	as *autoscaling.AutoScaling
+}
+
+// NewBasicClient creates a new AWS EC2 Auto Scaling client from the given
+// options.
+func NewBasicClient(opts awsutil.ClientOptions) (*BasicClient, error) {
+	c := &BasicClient{
+		BaseClient: awsutil.NewBaseClient(opts),
+	}
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	return c, nil
+}
+
+func (c *BasicClient) setup() error {
+	if c.as != nil {
+		return nil
+	}
+
+	sess, err := c.GetSession()
+	if err != nil {
+		return errors.Wrap(err, "initializing session")
+	}
+
+	c.as = autoscaling.New(sess)
+
+	return nil
+}
+
+// DescribeScalingActivities gets information about the scaling activities for
+// an Auto Scaling group.
+func (c *BasicClient) DescribeScalingActivities(ctx context.Context, in *autoscaling.DescribeScalingActivitiesInput) (*autoscaling.DescribeScalingActivitiesOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *autoscaling.DescribeScalingActivitiesOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DescribeScalingActivities", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.as.DescribeScalingActivitiesWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DescribeScalingActivities", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// Close cleans up all resources owned by the client.
+func (c *BasicClient) Close(ctx context.Context) error {
+	return c.BaseClient.Close(ctx)
+}
+
+func (c *BasicClient) isNonRetryableErrorCode(code string) bool {
+	switch code {
+	case "AccessDenied",
+		"ValidationError",
+		autoscaling.ErrCodeInvalidNextToken,
+		request.InvalidParameterErrCode,
+		request.ParamRequiredErrCode:
+		return true
+	default:
+		return false
+	}
+}
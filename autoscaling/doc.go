@@ -0,0 +1,4 @@
+/*
+Package autoscaling provides interfaces to interact with AWS EC2 Auto Scaling.
+*/
+package autoscaling
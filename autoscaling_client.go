@@ -0,0 +1,19 @@
+package cocoa
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+)
+
+// AutoScalingClient provides a common interface to interact with a client
+// backed by AWS EC2 Auto Scaling. Implementations must handle retrying and
+// backoff.
+type AutoScalingClient interface {
+	// DescribeScalingActivities gets information about the scaling activities
+	// for an Auto Scaling group.
+	DescribeScalingActivities(ctx context.Context, in *autoscaling.DescribeScalingActivitiesInput) (*autoscaling.DescribeScalingActivitiesOutput, error)
+	// Close closes the client and cleans up its resources. Implementations
+	// should ensure that this is idempotent.
+	Close(ctx context.Context) error
+}
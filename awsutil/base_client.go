@@ -2,9 +2,12 @@ package awsutil
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/level"
 	"github.com/pkg/errors"
 )
 
@@ -48,6 +51,25 @@ func (c *BaseClient) GetRetryOptions() utility.RetryOptions {
 	return *c.opts.RetryOpts
 }
 
+// GetLogLevel returns the priority at which the client logs its API calls.
+func (c *BaseClient) GetLogLevel() level.Priority {
+	if c.opts.LogLevel == level.Invalid {
+		return level.Debug
+	}
+	return c.opts.LogLevel
+}
+
+// LogAPICall logs a message about an API call at the client's log level.
+func (c *BaseClient) LogAPICall(msg interface{}) {
+	grip.Log(c.GetLogLevel(), msg)
+}
+
+// GetHTTPClient returns the HTTP client used to make requests. The HTTP client
+// is only available once the session is initialized.
+func (c *BaseClient) GetHTTPClient() *http.Client {
+	return c.opts.HTTPClient
+}
+
 // Close closes the client and cleans up its resources.
 func (c *BaseClient) Close(ctx context.Context) error {
 	c.opts.Close()
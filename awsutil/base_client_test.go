@@ -0,0 +1,41 @@
+package awsutil
+
+import (
+	"testing"
+
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/mongodb/grip/send"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseClientLogAPICall(t *testing.T) {
+	sender, err := send.NewInternalLogger("test", send.LevelInfo{Default: level.Trace, Threshold: level.Trace})
+	require.NoError(t, err)
+	prev := grip.GetSender()
+	require.NoError(t, grip.SetSender(sender))
+	defer func() {
+		assert.NoError(t, grip.SetSender(prev))
+	}()
+
+	t.Run("DefaultsToDebug", func(t *testing.T) {
+		c := NewBaseClient(*NewClientOptions())
+		assert.Equal(t, level.Debug, c.GetLogLevel())
+
+		c.LogAPICall(message.Fields{"message": "AWS API call"})
+		msg, ok := sender.GetMessageSafe()
+		require.True(t, ok)
+		assert.Equal(t, level.Debug, msg.Priority)
+	})
+	t.Run("UsesConfiguredLogLevel", func(t *testing.T) {
+		c := NewBaseClient(*NewClientOptions().SetLogLevel(level.Warning))
+		assert.Equal(t, level.Warning, c.GetLogLevel())
+
+		c.LogAPICall(message.Fields{"message": "AWS API call"})
+		msg, ok := sender.GetMessageSafe()
+		require.True(t, ok)
+		assert.Equal(t, level.Warning, msg.Priority)
+	})
+}
@@ -1,14 +1,21 @@
 package awsutil
 
 import (
+	"crypto/tls"
 	"net/http"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/evergreen-ci/utility"
 	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
 	"github.com/pkg/errors"
 )
 
@@ -29,13 +36,61 @@ type ClientOptions struct {
 	RetryOpts *utility.RetryOptions
 	// HTTPClient is the HTTP client to use to make requests.
 	HTTPClient *http.Client
+	// Endpoint is the URL to send API requests to instead of the default AWS
+	// endpoint (e.g. to test against a local AWS emulator).
+	Endpoint *string
+	// HTTP2Enabled sets whether or not the HTTP client should use HTTP/2 for
+	// requests to endpoints that support it. If this is not set, the HTTP
+	// client's transport is used as-is.
+	HTTP2Enabled *bool
+	// TLSConfig is the TLS configuration that the HTTP client should use to
+	// connect to endpoints (e.g. to pin CA certificates or require a minimum
+	// TLS version). If this is not set, the HTTP client's TLS configuration is
+	// used as-is.
+	TLSConfig *tls.Config
+	// SessionRefreshEnabled sets whether or not the session should refresh its
+	// credentials when a request fails because the credentials expired (e.g.
+	// the assumed role's session token expired), so that the next attempt
+	// assumes the role again. It also refreshes assumed role credentials
+	// shortly before they expire. This is not necessary for credentials that
+	// refresh themselves, such as those from the EC2 instance metadata.
+	SessionRefreshEnabled bool
+	// WebIdentityRoleARN is the role to assume using a web identity token
+	// (e.g. from an OIDC provider such as Kubernetes service accounts or
+	// GitHub Actions). If specified, WebIdentityTokenFile must also be
+	// specified and the web identity credentials are used in place of Creds.
+	WebIdentityRoleARN *string
+	// WebIdentityTokenFile is the path to the file containing the web identity
+	// token. The file is read each time the credentials are retrieved, so it
+	// can be rotated by the token provider.
+	WebIdentityTokenFile *string
+	// FIPSEndpointsEnabled sets whether or not API requests should be sent to
+	// FIPS 140-2 validated endpoints. The endpoints are resolved by the AWS
+	// SDK, so this is only supported in regions where the SDK knows of FIPS
+	// endpoints for the services (see SupportsFIPSEndpoints).
+	FIPSEndpointsEnabled bool
+	// DualStackEndpointsEnabled sets whether or not API requests should be
+	// sent to dual-stack endpoints, which can be reached over both IPv4 and
+	// IPv6 (e.g. from tasks in IPv6-only subnets). The endpoints are resolved
+	// by the AWS SDK, so this is only supported in regions where the SDK
+	// knows of dual-stack endpoints for the services (see
+	// SupportsDualStackEndpoints). Requests to services without dual-stack
+	// endpoints fail rather than using the IPv4-only endpoint.
+	DualStackEndpointsEnabled bool
+	// LogLevel is the priority at which the client logs its API calls. By
+	// default, API calls are logged at debug level.
+	LogLevel level.Priority
 
-	stsSession *session.Session
-	stsCreds   *credentials.Credentials
+	stsSession       *session.Session
+	stsCreds         *credentials.Credentials
+	webIdentityCreds *credentials.Credentials
 
 	session *session.Session
 
 	ownsHTTPClient bool
+	// baseHTTPClient is the original HTTP client before it was copied to
+	// configure its transport.
+	baseHTTPClient *http.Client
 }
 
 // NewClientOptions returns new unconfigured client options.
@@ -61,7 +116,8 @@ func (o *ClientOptions) SetRegion(region string) *ClientOptions {
 	return o
 }
 
-// SetRetryOptions sets the client's retry options.
+// SetRetryOptions sets the client's retry options. The options can be created
+// with NewRetryOptions.
 func (o *ClientOptions) SetRetryOptions(opts utility.RetryOptions) *ClientOptions {
 	o.RetryOpts = &opts
 	return o
@@ -73,13 +129,86 @@ func (o *ClientOptions) SetHTTPClient(hc *http.Client) *ClientOptions {
 	return o
 }
 
+// SetEndpoint sets the URL to send API requests to.
+func (o *ClientOptions) SetEndpoint(endpoint string) *ClientOptions {
+	o.Endpoint = &endpoint
+	return o
+}
+
+// SetHTTP2Enabled sets whether or not the HTTP client should use HTTP/2.
+func (o *ClientOptions) SetHTTP2Enabled(enabled bool) *ClientOptions {
+	o.HTTP2Enabled = &enabled
+	return o
+}
+
+// SetLogLevel sets the priority at which the client logs its API calls.
+func (o *ClientOptions) SetLogLevel(l level.Priority) *ClientOptions {
+	o.LogLevel = l
+	return o
+}
+
+// SetTLSConfig sets the TLS configuration for the HTTP client.
+func (o *ClientOptions) SetTLSConfig(cfg *tls.Config) *ClientOptions {
+	o.TLSConfig = cfg
+	return o
+}
+
+// SetSessionRefreshEnabled sets whether or not the session should refresh
+// expired credentials.
+func (o *ClientOptions) SetSessionRefreshEnabled(enabled bool) *ClientOptions {
+	o.SessionRefreshEnabled = enabled
+	return o
+}
+
+// SetWebIdentityToken sets the role to assume and the path to the web identity
+// token file used to authenticate to STS.
+func (o *ClientOptions) SetWebIdentityToken(roleARN, tokenFile string) *ClientOptions {
+	o.WebIdentityRoleARN = &roleARN
+	o.WebIdentityTokenFile = &tokenFile
+	return o
+}
+
+// SetFIPSEndpoints sets whether or not API requests should be sent to FIPS
+// endpoints.
+func (o *ClientOptions) SetFIPSEndpoints(enabled bool) *ClientOptions {
+	o.FIPSEndpointsEnabled = enabled
+	return o
+}
+
+// SetDualStackEndpoints sets whether or not API requests should be sent to
+// dual-stack endpoints.
+func (o *ClientOptions) SetDualStackEndpoints(enabled bool) *ClientOptions {
+	o.DualStackEndpointsEnabled = enabled
+	return o
+}
+
+// endpointValidationServices are the endpoint IDs of the services whose
+// endpoints are checked when validating the endpoint options.
+var endpointValidationServices = []string{"ecs", "secretsmanager"}
+
 // Validate checks that all required fields are given and sets defaults for
 // unspecified options.
 func (o *ClientOptions) Validate() error {
 	catcher := grip.NewBasicCatcher()
 
 	catcher.NewWhen(o.Region == nil, "must provide geographical region")
-	catcher.NewWhen(o.Role == nil && o.Creds == nil, "must provide either explicit credentials, role to assume, or both")
+	catcher.NewWhen(o.Role == nil && o.Creds == nil && o.WebIdentityRoleARN == nil, "must provide either explicit credentials, web identity token, role to assume, or both")
+	if o.WebIdentityRoleARN != nil || o.WebIdentityTokenFile != nil {
+		catcher.NewWhen(utility.FromStringPtr(o.WebIdentityRoleARN) == "", "must provide role ARN for web identity token")
+		catcher.NewWhen(utility.FromStringPtr(o.WebIdentityTokenFile) == "", "must provide token file for web identity token")
+	}
+	if o.FIPSEndpointsEnabled && o.Region != nil {
+		for _, service := range endpointValidationServices {
+			catcher.ErrorfWhen(!SupportsFIPSEndpoints(service, *o.Region), "FIPS endpoints are not supported for service '%s' in region '%s'", service, *o.Region)
+		}
+	}
+	if o.DualStackEndpointsEnabled && o.Region != nil {
+		for _, service := range endpointValidationServices {
+			catcher.ErrorfWhen(!SupportsDualStackEndpoints(service, *o.Region), "dual-stack endpoints are not supported for service '%s' in region '%s'", service, *o.Region)
+		}
+	}
+
+	catcher.ErrorfWhen(o.LogLevel != level.Invalid && !o.LogLevel.IsValid(), "invalid log level %d", o.LogLevel)
 
 	if catcher.HasErrors() {
 		return catcher.Resolve()
@@ -89,6 +218,11 @@ func (o *ClientOptions) Validate() error {
 		o.HTTPClient = utility.GetHTTPClient()
 		o.ownsHTTPClient = true
 	}
+	if (o.HTTP2Enabled != nil || o.TLSConfig != nil) && o.baseHTTPClient == nil {
+		if err := o.configureTransport(); err != nil {
+			return errors.Wrap(err, "configuring HTTP client transport")
+		}
+	}
 
 	if o.RetryOpts == nil {
 		o.RetryOpts = &utility.RetryOptions{}
@@ -98,13 +232,68 @@ func (o *ClientOptions) Validate() error {
 	return nil
 }
 
+// http2NextProto is the TLS application-layer protocol negotiation identifier
+// for HTTP/2.
+const http2NextProto = "h2"
+
+// configureTransport replaces the HTTP client with a copy whose transport uses
+// the TLS configuration and enables or disables HTTP/2. The copy ensures that
+// the changes do not affect other users of the original HTTP client. When
+// HTTP/2 is enabled, the transport populates TLSNextProto with the HTTP/2
+// upgrade the first time it makes a request.
+func (o *ClientOptions) configureTransport() error {
+	transport := o.HTTPClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	base, ok := transport.(*http.Transport)
+	if !ok {
+		return errors.Errorf("cannot configure HTTP client transport of type %T", transport)
+	}
+
+	configured := base.Clone()
+	if o.TLSConfig != nil {
+		configured.TLSClientConfig = o.TLSConfig.Clone()
+	}
+	if o.HTTP2Enabled != nil && *o.HTTP2Enabled {
+		configured.ForceAttemptHTTP2 = true
+		configured.TLSNextProto = nil
+	} else if o.HTTP2Enabled != nil {
+		configured.ForceAttemptHTTP2 = false
+		configured.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		// The original transport may have already advertised HTTP/2 support
+		// during TLS negotiation, which the copy inherits.
+		if configured.TLSClientConfig != nil {
+			var nextProtos []string
+			for _, proto := range configured.TLSClientConfig.NextProtos {
+				if proto != http2NextProto {
+					nextProtos = append(nextProtos, proto)
+				}
+			}
+			configured.TLSClientConfig.NextProtos = nextProtos
+		}
+	}
+
+	hc := *o.HTTPClient
+	hc.Transport = configured
+	o.baseHTTPClient = o.HTTPClient
+	o.HTTPClient = &hc
+
+	return nil
+}
+
 // GetCredentials retrieves the appropriate credentials to use for the client.
 func (o *ClientOptions) GetCredentials() (*credentials.Credentials, error) {
-	if o.Role == nil && o.Creds == nil {
-		return nil, errors.New("cannot get client credentials when neither explicit credentials are given, nor the role to assume is given")
+	if o.Role == nil && o.Creds == nil && o.WebIdentityRoleARN == nil {
+		return nil, errors.New("cannot get client credentials when neither explicit credentials, nor web identity token, nor the role to assume is given")
+	}
+
+	creds, err := o.getBaseCredentials()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting base credentials")
 	}
 	if o.Role == nil {
-		return o.Creds, nil
+		return creds, nil
 	}
 
 	if o.stsCreds != nil {
@@ -112,11 +301,7 @@ func (o *ClientOptions) GetCredentials() (*credentials.Credentials, error) {
 	}
 
 	if o.stsSession == nil {
-		sess, err := session.NewSession(&aws.Config{
-			HTTPClient:  o.HTTPClient,
-			Region:      o.Region,
-			Credentials: o.Creds,
-		})
+		sess, err := o.newSTSSession(creds)
 		if err != nil {
 			return nil, errors.Wrap(err, "creating session")
 		}
@@ -124,11 +309,84 @@ func (o *ClientOptions) GetCredentials() (*credentials.Credentials, error) {
 		o.stsSession = sess
 	}
 
-	o.stsCreds = stscreds.NewCredentials(o.stsSession, *o.Role)
+	o.stsCreds = stscreds.NewCredentials(o.stsSession, *o.Role, func(p *stscreds.AssumeRoleProvider) {
+		if o.SessionRefreshEnabled {
+			p.ExpiryWindow = sessionRefreshExpiryWindow
+		}
+	})
 
 	return o.stsCreds, nil
 }
 
+// getBaseCredentials returns the credentials that are used either to access the
+// API directly or to assume the role. If a web identity token is given, these
+// are the credentials exchanged for the web identity token; otherwise, they
+// are the explicit credentials.
+func (o *ClientOptions) getBaseCredentials() (*credentials.Credentials, error) {
+	if o.WebIdentityRoleARN == nil {
+		return o.Creds, nil
+	}
+	if o.webIdentityCreds != nil {
+		return o.webIdentityCreds, nil
+	}
+
+	// AssumeRoleWithWebIdentity does not require signed requests, so the
+	// explicit credentials are only used if they are given.
+	sess, err := o.newSTSSession(o.Creds)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating web identity session")
+	}
+
+	p := stscreds.NewWebIdentityRoleProvider(sts.New(sess), *o.WebIdentityRoleARN, "", utility.FromStringPtr(o.WebIdentityTokenFile))
+	if o.SessionRefreshEnabled {
+		p.ExpiryWindow = sessionRefreshExpiryWindow
+	}
+	o.webIdentityCreds = credentials.NewCredentials(p)
+
+	return o.webIdentityCreds, nil
+}
+
+// newSTSSession creates a session to authenticate to STS using the given
+// credentials.
+func (o *ClientOptions) newSTSSession(creds *credentials.Credentials) (*session.Session, error) {
+	return session.NewSession(&aws.Config{
+		HTTPClient:           o.HTTPClient,
+		Region:               o.Region,
+		Endpoint:             o.Endpoint,
+		UseFIPSEndpoint:      o.fipsEndpointState(),
+		UseDualStackEndpoint: o.dualStackEndpointState(),
+		EndpointResolver:     o.endpointResolver(),
+		Credentials:          creds,
+	})
+}
+
+// endpointResolver returns the resolver for the session's endpoints. If it
+// returns nil, the session uses the AWS SDK's default resolver.
+func (o *ClientOptions) endpointResolver() endpoints.Resolver {
+	if IsGovCloudRegion(utility.FromStringPtr(o.Region)) {
+		return GovCloudEndpointResolver{}
+	}
+	return nil
+}
+
+// fipsEndpointState returns whether or not the session should resolve FIPS
+// endpoints.
+func (o *ClientOptions) fipsEndpointState() endpoints.FIPSEndpointState {
+	if o.FIPSEndpointsEnabled {
+		return endpoints.FIPSEndpointStateEnabled
+	}
+	return endpoints.FIPSEndpointStateUnset
+}
+
+// dualStackEndpointState returns whether or not the session should resolve
+// dual-stack endpoints.
+func (o *ClientOptions) dualStackEndpointState() endpoints.DualStackEndpointState {
+	if o.DualStackEndpointsEnabled {
+		return endpoints.DualStackEndpointStateEnabled
+	}
+	return endpoints.DualStackEndpointStateUnset
+}
+
 // GetSession gets the authenticated session to perform authorized API actions.
 func (o *ClientOptions) GetSession() (*session.Session, error) {
 	if o.session != nil {
@@ -141,22 +399,62 @@ func (o *ClientOptions) GetSession() (*session.Session, error) {
 	}
 
 	sess, err := session.NewSession(&aws.Config{
-		HTTPClient:  o.HTTPClient,
-		Region:      o.Region,
-		Credentials: creds,
+		HTTPClient:           o.HTTPClient,
+		Region:               o.Region,
+		Endpoint:             o.Endpoint,
+		UseFIPSEndpoint:      o.fipsEndpointState(),
+		UseDualStackEndpoint: o.dualStackEndpointState(),
+		EndpointResolver:     o.endpointResolver(),
+		Credentials:          creds,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "creating session")
 	}
 
+	if o.SessionRefreshEnabled {
+		sess.Handlers.Retry.PushFrontNamed(refreshExpiredCredentialsHandler)
+	}
+
 	o.session = sess
 
 	return o.session, nil
 }
 
+// sessionRefreshExpiryWindow is how long before assumed role credentials
+// expire that they are refreshed when session refresh is enabled.
+const sessionRefreshExpiryWindow = time.Minute
+
+// refreshExpiredCredentialsHandler is a request retry handler that expires the
+// cached credentials when a request fails because the credentials expired and
+// marks the request as retryable. The SDK normally only expires the cached
+// credentials if it retries the request itself, so this ensures that the next
+// attempt gets fresh credentials even if the SDK does not retry (e.g. because
+// it has exhausted its own retries).
+var refreshExpiredCredentialsHandler = request.NamedHandler{
+	Name: "cocoa.RefreshExpiredCredentialsHandler",
+	Fn: func(r *request.Request) {
+		if !r.IsErrorExpired() {
+			return
+		}
+		grip.Debug(message.WrapError(r.Error, message.Fields{
+			"message":   "refreshing expired credentials",
+			"operation": r.Operation.Name,
+		}))
+		if r.Config.Credentials != nil {
+			r.Config.Credentials.Expire()
+		}
+		r.Retryable = aws.Bool(true)
+	},
+}
+
 // Close cleans up the HTTP client if it is owned by this client.
 func (o *ClientOptions) Close() {
-	if o.ownsHTTPClient {
-		utility.PutHTTPClient(o.HTTPClient)
+	if !o.ownsHTTPClient {
+		return
+	}
+	if o.baseHTTPClient != nil {
+		utility.PutHTTPClient(o.baseHTTPClient)
+		return
 	}
+	utility.PutHTTPClient(o.HTTPClient)
 }
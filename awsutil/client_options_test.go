@@ -1,12 +1,23 @@
 package awsutil
 
 import (
+	"crypto/tls"
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip/level"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -46,7 +57,55 @@ func TestClientOptions(t *testing.T) {
 		assert.Equal(t, hc, opts.HTTPClient)
 		assert.False(t, opts.ownsHTTPClient)
 	})
+	t.Run("SetEndpoint", func(t *testing.T) {
+		endpoint := "http://localhost:4566"
+		opts := NewClientOptions().SetEndpoint(endpoint)
+		require.NotNil(t, opts.Endpoint)
+		assert.Equal(t, endpoint, *opts.Endpoint)
+	})
+	t.Run("SetHTTP2Enabled", func(t *testing.T) {
+		opts := NewClientOptions().SetHTTP2Enabled(true)
+		require.NotNil(t, opts.HTTP2Enabled)
+		assert.True(t, *opts.HTTP2Enabled)
+	})
+	t.Run("SetTLSConfig", func(t *testing.T) {
+		cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+		opts := NewClientOptions().SetTLSConfig(cfg)
+		assert.Equal(t, cfg, opts.TLSConfig)
+	})
+	t.Run("SetSessionRefreshEnabled", func(t *testing.T) {
+		opts := NewClientOptions().SetSessionRefreshEnabled(true)
+		assert.True(t, opts.SessionRefreshEnabled)
+	})
+	t.Run("SetWebIdentityToken", func(t *testing.T) {
+		opts := NewClientOptions().SetWebIdentityToken("role_arn", "token_file")
+		require.NotNil(t, opts.WebIdentityRoleARN)
+		assert.Equal(t, "role_arn", *opts.WebIdentityRoleARN)
+		require.NotNil(t, opts.WebIdentityTokenFile)
+		assert.Equal(t, "token_file", *opts.WebIdentityTokenFile)
+	})
+	t.Run("SetFIPSEndpoints", func(t *testing.T) {
+		opts := NewClientOptions().SetFIPSEndpoints(true)
+		assert.True(t, opts.FIPSEndpointsEnabled)
+	})
+	t.Run("SetDualStackEndpoints", func(t *testing.T) {
+		opts := NewClientOptions().SetDualStackEndpoints(true)
+		assert.True(t, opts.DualStackEndpointsEnabled)
+	})
+	t.Run("SetLogLevel", func(t *testing.T) {
+		opts := NewClientOptions().SetLogLevel(level.Warning)
+		assert.Equal(t, level.Warning, opts.LogLevel)
+	})
 	t.Run("Validate", func(t *testing.T) {
+		t.Run("FailsWithInvalidLogLevel", func(t *testing.T) {
+			opts := NewClientOptions().
+				SetCredentials(credentials.NewEnvCredentials()).
+				SetRegion("us-east-1").
+				SetHTTPClient(http.DefaultClient).
+				SetLogLevel(level.Priority(1000))
+
+			assert.Error(t, opts.Validate())
+		})
 		t.Run("SucceedsWithAllOptionSet", func(t *testing.T) {
 			creds := credentials.NewEnvCredentials()
 			role := "role"
@@ -122,6 +181,85 @@ func TestClientOptions(t *testing.T) {
 
 			assert.Error(t, opts.Validate())
 		})
+		t.Run("SucceedsWithOnlyWebIdentityToken", func(t *testing.T) {
+			opts := NewClientOptions().
+				SetRegion("region").
+				SetHTTPClient(http.DefaultClient).
+				SetWebIdentityToken("role_arn", "token_file")
+
+			assert.NoError(t, opts.Validate())
+		})
+		t.Run("FailsWithWebIdentityTokenMissingTokenFile", func(t *testing.T) {
+			opts := NewClientOptions().
+				SetRegion("region").
+				SetHTTPClient(http.DefaultClient).
+				SetWebIdentityToken("role_arn", "")
+
+			assert.Error(t, opts.Validate())
+		})
+		t.Run("FailsWithWebIdentityTokenMissingRoleARN", func(t *testing.T) {
+			opts := NewClientOptions().
+				SetRegion("region").
+				SetHTTPClient(http.DefaultClient).
+				SetWebIdentityToken("", "token_file")
+
+			assert.Error(t, opts.Validate())
+		})
+		t.Run("SucceedsWithGovCloudRegion", func(t *testing.T) {
+			for _, region := range []string{RegionGovCloudUSEast1, RegionGovCloudUSWest1} {
+				opts := NewClientOptions().
+					SetCredentials(credentials.NewEnvCredentials()).
+					SetRegion(region).
+					SetHTTPClient(http.DefaultClient)
+
+				assert.NoError(t, opts.Validate(), region)
+			}
+		})
+		t.Run("SucceedsWithFIPSEndpointsInGovCloudRegion", func(t *testing.T) {
+			opts := NewClientOptions().
+				SetCredentials(credentials.NewEnvCredentials()).
+				SetRegion(RegionGovCloudUSEast1).
+				SetHTTPClient(http.DefaultClient).
+				SetFIPSEndpoints(true)
+
+			assert.NoError(t, opts.Validate())
+		})
+		t.Run("SucceedsWithFIPSEndpointsInCommercialRegion", func(t *testing.T) {
+			opts := NewClientOptions().
+				SetCredentials(credentials.NewEnvCredentials()).
+				SetRegion("us-east-1").
+				SetHTTPClient(http.DefaultClient).
+				SetFIPSEndpoints(true)
+
+			assert.NoError(t, opts.Validate())
+		})
+		t.Run("FailsWithFIPSEndpointsInUnsupportedRegion", func(t *testing.T) {
+			opts := NewClientOptions().
+				SetCredentials(credentials.NewEnvCredentials()).
+				SetRegion("region").
+				SetHTTPClient(http.DefaultClient).
+				SetFIPSEndpoints(true)
+
+			assert.Error(t, opts.Validate())
+		})
+		t.Run("SucceedsWithDualStackEndpointsInSupportedRegion", func(t *testing.T) {
+			opts := NewClientOptions().
+				SetCredentials(credentials.NewEnvCredentials()).
+				SetRegion("us-east-1").
+				SetHTTPClient(http.DefaultClient).
+				SetDualStackEndpoints(true)
+
+			assert.NoError(t, opts.Validate())
+		})
+		t.Run("FailsWithDualStackEndpointsInUnsupportedRegion", func(t *testing.T) {
+			opts := NewClientOptions().
+				SetCredentials(credentials.NewEnvCredentials()).
+				SetRegion("us-iso-east-1").
+				SetHTTPClient(http.DefaultClient).
+				SetDualStackEndpoints(true)
+
+			assert.Error(t, opts.Validate())
+		})
 		t.Run("FailsWithoutRegion", func(t *testing.T) {
 			creds := credentials.NewEnvCredentials()
 			role := "role"
@@ -164,5 +302,318 @@ func TestClientOptions(t *testing.T) {
 			assert.NotZero(t, opts.HTTPClient)
 			assert.True(t, opts.ownsHTTPClient)
 		})
+		t.Run("HTTP2", func(t *testing.T) {
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			srv.EnableHTTP2 = true
+			srv.StartTLS()
+			defer srv.Close()
+
+			newHTTPClient := func() *http.Client {
+				return &http.Client{
+					Transport: &http.Transport{
+						TLSClientConfig: &tls.Config{
+							RootCAs: srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs,
+						},
+					},
+				}
+			}
+			getProtoMajor := func(t *testing.T, hc *http.Client) int {
+				resp, err := hc.Get(srv.URL)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				return resp.ProtoMajor
+			}
+
+			t.Run("EnablesHTTP2", func(t *testing.T) {
+				hc := newHTTPClient()
+				opts := NewClientOptions().
+					SetCredentials(credentials.NewEnvCredentials()).
+					SetRegion("region").
+					SetHTTPClient(hc).
+					SetHTTP2Enabled(true)
+
+				require.NoError(t, opts.Validate())
+				assert.NotEqual(t, hc, opts.HTTPClient, "should copy the HTTP client")
+				assert.Equal(t, 2, getProtoMajor(t, opts.HTTPClient))
+				assert.Equal(t, 1, getProtoMajor(t, hc), "original HTTP client should not be modified")
+			})
+			t.Run("DisablesHTTP2", func(t *testing.T) {
+				hc := newHTTPClient()
+				hc.Transport.(*http.Transport).ForceAttemptHTTP2 = true
+				opts := NewClientOptions().
+					SetCredentials(credentials.NewEnvCredentials()).
+					SetRegion("region").
+					SetHTTPClient(hc).
+					SetHTTP2Enabled(false)
+
+				require.NoError(t, opts.Validate())
+				assert.Equal(t, 1, getProtoMajor(t, opts.HTTPClient))
+			})
+			t.Run("IsIdempotent", func(t *testing.T) {
+				opts := NewClientOptions().
+					SetCredentials(credentials.NewEnvCredentials()).
+					SetRegion("region").
+					SetHTTPClient(newHTTPClient()).
+					SetHTTP2Enabled(true)
+
+				require.NoError(t, opts.Validate())
+				hc := opts.HTTPClient
+				require.NoError(t, opts.Validate())
+				assert.Equal(t, hc, opts.HTTPClient)
+			})
+			t.Run("ReturnsOriginalOwnedHTTPClient", func(t *testing.T) {
+				opts := NewClientOptions().
+					SetCredentials(credentials.NewEnvCredentials()).
+					SetRegion("region").
+					SetHTTP2Enabled(true)
+
+				require.NoError(t, opts.Validate())
+				defer opts.Close()
+				assert.True(t, opts.ownsHTTPClient)
+				assert.NotZero(t, opts.baseHTTPClient)
+				assert.NotEqual(t, opts.baseHTTPClient, opts.HTTPClient)
+			})
+			t.Run("FailsWithNonstandardTransport", func(t *testing.T) {
+				opts := NewClientOptions().
+					SetCredentials(credentials.NewEnvCredentials()).
+					SetRegion("region").
+					SetHTTPClient(&http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}).
+					SetHTTP2Enabled(true)
+
+				assert.Error(t, opts.Validate())
+			})
+		})
+		t.Run("TLSConfig", func(t *testing.T) {
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			defer srv.Close()
+			rootCAs := srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+			t.Run("UsesPinnedCACertificates", func(t *testing.T) {
+				hc := &http.Client{Transport: &http.Transport{}}
+				opts := NewClientOptions().
+					SetCredentials(credentials.NewEnvCredentials()).
+					SetRegion("region").
+					SetHTTPClient(hc).
+					SetTLSConfig(&tls.Config{RootCAs: rootCAs})
+
+				require.NoError(t, opts.Validate())
+				assert.NotEqual(t, hc, opts.HTTPClient, "should copy the HTTP client")
+
+				resp, err := opts.HTTPClient.Get(srv.URL)
+				require.NoError(t, err)
+				assert.NoError(t, resp.Body.Close())
+
+				_, err = hc.Get(srv.URL)
+				assert.Error(t, err, "original HTTP client should not trust the server certificate")
+			})
+			t.Run("FailsToConnectBelowMinimumTLSVersion", func(t *testing.T) {
+				srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+				srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+				srv.StartTLS()
+				defer srv.Close()
+
+				opts := NewClientOptions().
+					SetCredentials(credentials.NewEnvCredentials()).
+					SetRegion("region").
+					SetHTTPClient(&http.Client{Transport: &http.Transport{}}).
+					SetTLSConfig(&tls.Config{
+						RootCAs:    srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs,
+						MinVersion: tls.VersionTLS13,
+					})
+
+				require.NoError(t, opts.Validate())
+				_, err := opts.HTTPClient.Get(srv.URL)
+				assert.Error(t, err)
+			})
+			t.Run("CopiesTLSConfig", func(t *testing.T) {
+				cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+				opts := NewClientOptions().
+					SetCredentials(credentials.NewEnvCredentials()).
+					SetRegion("region").
+					SetHTTPClient(&http.Client{Transport: &http.Transport{}}).
+					SetTLSConfig(cfg)
+
+				require.NoError(t, opts.Validate())
+				cfg.MinVersion = tls.VersionTLS13
+
+				transport, ok := opts.HTTPClient.Transport.(*http.Transport)
+				require.True(t, ok)
+				require.NotZero(t, transport.TLSClientConfig)
+				assert.EqualValues(t, tls.VersionTLS12, transport.TLSClientConfig.MinVersion)
+			})
+		})
+	})
+}
+
+// countingCredentialsProvider is a credentials provider that counts the number
+// of times credentials are retrieved.
+type countingCredentialsProvider struct {
+	retrieved int
+	expired   bool
+}
+
+func (p *countingCredentialsProvider) Retrieve() (credentials.Value, error) {
+	p.retrieved++
+	p.expired = false
+	return credentials.Value{AccessKeyID: "access_key_id", SecretAccessKey: "secret_access_key"}, nil
+}
+
+func (p *countingCredentialsProvider) IsExpired() bool {
+	return p.expired
+}
+
+func TestClientOptionsSessionRefresh(t *testing.T) {
+	newServer := func(expiredResponses int) *httptest.Server {
+		var requests int
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			if requests <= expiredResponses {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type": "ExpiredTokenException", "message": "The security token included in the request is expired"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"Name": "name", "SecretString": "value"}`))
+		}))
+	}
+	// newClient returns a client that does not retry within the SDK, so that
+	// each failed request is only retried by the caller.
+	newClient := func(t *testing.T, srv *httptest.Server, provider credentials.Provider, refresh bool) *secretsmanager.SecretsManager {
+		opts := NewClientOptions().
+			SetHTTPClient(srv.Client()).
+			SetCredentials(credentials.NewCredentials(provider)).
+			SetRegion("us-east-1").
+			SetEndpoint(srv.URL).
+			SetSessionRefreshEnabled(refresh)
+		require.NoError(t, opts.Validate())
+		sess, err := opts.GetSession()
+		require.NoError(t, err)
+		return secretsmanager.New(sess, aws.NewConfig().WithMaxRetries(0))
+	}
+	getSecretValue := func(c *secretsmanager.SecretsManager) error {
+		_, err := c.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: aws.String("name")})
+		return err
+	}
+
+	t.Run("RefreshesCredentialsForNextAttemptAfterExpiry", func(t *testing.T) {
+		srv := newServer(1)
+		defer srv.Close()
+		provider := &countingCredentialsProvider{}
+		c := newClient(t, srv, provider, true)
+
+		err := getSecretValue(c)
+		require.Error(t, err)
+		assert.True(t, request.IsErrorExpiredCreds(err))
+
+		require.NoError(t, getSecretValue(c))
+		assert.Equal(t, 2, provider.retrieved)
+	})
+	t.Run("DoesNotRefreshCredentialsByDefault", func(t *testing.T) {
+		srv := newServer(1)
+		defer srv.Close()
+		provider := &countingCredentialsProvider{}
+		c := newClient(t, srv, provider, false)
+
+		err := getSecretValue(c)
+		require.Error(t, err)
+		assert.True(t, request.IsErrorExpiredCreds(err))
+
+		require.NoError(t, getSecretValue(c))
+		assert.Equal(t, 1, provider.retrieved)
+	})
+}
+
+func TestClientOptionsWebIdentityToken(t *testing.T) {
+	writeTokenFile := func(t *testing.T, token string) string {
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, ioutil.WriteFile(tokenFile, []byte(token), 0600))
+		return tokenFile
+	}
+
+	t.Run("ExchangesTokenForCredentials", func(t *testing.T) {
+		const roleARN = "arn:aws:iam::123456789012:role/role"
+		var gotToken, gotRoleARN string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, r.ParseForm())
+			gotToken = r.Form.Get("WebIdentityToken")
+			gotRoleARN = r.Form.Get("RoleArn")
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = w.Write([]byte(`<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>access_key_id</AccessKeyId>
+      <SecretAccessKey>secret_access_key</SecretAccessKey>
+      <SessionToken>session_token</SessionToken>
+      <Expiration>` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`))
+		}))
+		defer srv.Close()
+
+		opts := NewClientOptions().
+			SetHTTPClient(srv.Client()).
+			SetRegion("us-east-1").
+			SetEndpoint(srv.URL).
+			SetWebIdentityToken(roleARN, writeTokenFile(t, "token"))
+		require.NoError(t, opts.Validate())
+
+		creds, err := opts.GetCredentials()
+		require.NoError(t, err)
+		val, err := creds.Get()
+		require.NoError(t, err)
+		assert.Equal(t, "access_key_id", val.AccessKeyID)
+		assert.Equal(t, "secret_access_key", val.SecretAccessKey)
+		assert.Equal(t, "session_token", val.SessionToken)
+		assert.Equal(t, "token", gotToken)
+		assert.Equal(t, roleARN, gotRoleARN)
+	})
+	t.Run("FailsWithNonexistentTokenFile", func(t *testing.T) {
+		opts := NewClientOptions().
+			SetHTTPClient(http.DefaultClient).
+			SetRegion("us-east-1").
+			SetWebIdentityToken("role_arn", filepath.Join(t.TempDir(), "nonexistent"))
+		require.NoError(t, opts.Validate())
+
+		creds, err := opts.GetCredentials()
+		require.NoError(t, err)
+		_, err = creds.Get()
+		assert.Error(t, err)
+	})
+	t.Run("IntegrationWithGitHubActions", func(t *testing.T) {
+		// These are set by GitHub Actions when the workflow has permission
+		// to request an OIDC token.
+		requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+		requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+		roleARN := os.Getenv("AWS_WEB_IDENTITY_ROLE_ARN")
+		region := os.Getenv("AWS_REGION")
+		if requestURL == "" || requestToken == "" || roleARN == "" || region == "" {
+			t.Skip("skipping test because GitHub Actions OIDC token request or AWS web identity role environment variables are missing")
+		}
+
+		req, err := http.NewRequest(http.MethodGet, requestURL+"&audience=sts.amazonaws.com", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "bearer "+requestToken)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var token struct {
+			Value string `json:"value"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&token))
+		require.NotZero(t, token.Value)
+
+		opts := NewClientOptions().
+			SetRegion(region).
+			SetWebIdentityToken(roleARN, writeTokenFile(t, token.Value))
+		require.NoError(t, opts.Validate())
+		defer opts.Close()
+
+		sess, err := opts.GetSession()
+		require.NoError(t, err)
+		out, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+		require.NoError(t, err)
+		assert.NotZero(t, out.Arn)
 	})
 }
@@ -0,0 +1,133 @@
+package awsutil
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// ConnectionPoolStats contains statistics about the HTTP connections used by a
+// client.
+type ConnectionPoolStats struct {
+	// IdleConns is the number of connections that are open but not in use.
+	IdleConns int
+	// ActiveConns is the number of connections that are currently in use by
+	// requests.
+	ActiveConns int
+	// MaxIdleConns is the maximum number of idle connections that the HTTP
+	// transport keeps open. If it is 0, there is no limit. If the HTTP client
+	// does not use an *http.Transport, this is -1.
+	MaxIdleConns int
+}
+
+// ConnectionPoolTracker tracks the HTTP connections used by requests made by an
+// AWS client. The connection counts are based on the connections observed by
+// the client's requests, so they are approximate; for example, idle
+// connections that the HTTP transport closes are not observed and are still
+// counted as idle. It is safe for concurrent use.
+type ConnectionPoolTracker struct {
+	mu     sync.Mutex
+	idle   map[net.Conn]struct{}
+	active int
+}
+
+// connUse tracks the connection used by the current attempt of a request.
+type connUse struct {
+	conn   net.Conn
+	active bool
+}
+
+type connUseKey struct{}
+
+// Attach adds request handlers to track the connections used by requests.
+func (t *ConnectionPoolTracker) Attach(h *request.Handlers) {
+	h.Send.PushFront(func(r *request.Request) {
+		// Retries reuse the request's context, so the trace only needs to
+		// be added on the first attempt. Adding it again would call the
+		// trace hooks once for each previous attempt.
+		if _, ok := r.HTTPRequest.Context().Value(connUseKey{}).(*connUse); ok {
+			return
+		}
+		use := &connUse{}
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				t.acquire(use, info.Conn)
+			},
+			PutIdleConn: func(err error) {
+				t.release(use, err == nil)
+			},
+		}
+		ctx := httptrace.WithClientTrace(r.HTTPRequest.Context(), trace)
+		r.HTTPRequest = r.HTTPRequest.WithContext(context.WithValue(ctx, connUseKey{}, use))
+	})
+	// The complete attempt handlers are deferred until the attempt finishes,
+	// so the connection is released even if the attempt fails before the
+	// connection can be returned to the pool (e.g. due to a transport error).
+	h.CompleteAttempt.PushBack(func(r *request.Request) {
+		if r.HTTPRequest == nil {
+			return
+		}
+		if use, ok := r.HTTPRequest.Context().Value(connUseKey{}).(*connUse); ok {
+			t.release(use, false)
+		}
+	})
+}
+
+// acquire marks the connection as in use by the request attempt.
+func (t *ConnectionPoolTracker) acquire(use *connUse, conn net.Conn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.idle, conn)
+	use.conn = conn
+	if !use.active {
+		use.active = true
+		t.active++
+	}
+}
+
+// release marks the connection used by the request attempt as no longer in
+// use. If idle is true, the connection was returned to the pool.
+func (t *ConnectionPoolTracker) release(use *connUse, idle bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if use.active {
+		use.active = false
+		t.active--
+	}
+	if idle && use.conn != nil {
+		if t.idle == nil {
+			t.idle = map[net.Conn]struct{}{}
+		}
+		t.idle[use.conn] = struct{}{}
+	}
+}
+
+// Stats returns the current connection statistics for requests made using the
+// HTTP client.
+func (t *ConnectionPoolTracker) Stats(hc *http.Client) ConnectionPoolStats {
+	t.mu.Lock()
+	stats := ConnectionPoolStats{
+		IdleConns:    len(t.idle),
+		ActiveConns:  t.active,
+		MaxIdleConns: -1,
+	}
+	t.mu.Unlock()
+
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	transport := hc.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if ht, ok := transport.(*http.Transport); ok {
+		stats.MaxIdleConns = ht.MaxIdleConns
+	}
+	return stats
+}
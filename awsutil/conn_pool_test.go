@@ -0,0 +1,89 @@
+package awsutil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionPoolTracker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_, _ = w.Write([]byte(`{"Name": "name", "SecretString": "value"}`))
+	}))
+	defer srv.Close()
+
+	newClient := func(t *testing.T, hc *http.Client) (*secretsmanager.SecretsManager, *ConnectionPoolTracker) {
+		opts := NewClientOptions().
+			SetHTTPClient(hc).
+			SetCredentials(credentials.NewStaticCredentials("access_key_id", "secret_access_key", "")).
+			SetRegion("us-east-1").
+			SetEndpoint(srv.URL)
+		require.NoError(t, opts.Validate())
+		sess, err := opts.GetSession()
+		require.NoError(t, err)
+
+		c := secretsmanager.New(sess)
+		var tracker ConnectionPoolTracker
+		tracker.Attach(&c.Handlers)
+		return c, &tracker
+	}
+
+	t.Run("TracksIdleConnectionsAfterRequests", func(t *testing.T) {
+		transport := &http.Transport{MaxIdleConns: 5}
+		defer transport.CloseIdleConnections()
+		hc := &http.Client{Transport: transport}
+		c, tracker := newClient(t, hc)
+
+		assert.Equal(t, ConnectionPoolStats{MaxIdleConns: 5}, tracker.Stats(hc))
+
+		for i := 0; i < 3; i++ {
+			_, err := c.GetSecretValueWithContext(context.Background(), &secretsmanager.GetSecretValueInput{SecretId: aws.String("name")})
+			require.NoError(t, err)
+		}
+
+		stats := tracker.Stats(hc)
+		assert.Equal(t, 1, stats.IdleConns)
+		assert.Zero(t, stats.ActiveConns)
+		assert.Equal(t, 5, stats.MaxIdleConns)
+	})
+	t.Run("ReleasesConnectionsAfterFailedRequests", func(t *testing.T) {
+		closingSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			require.NoError(t, conn.Close())
+		}))
+		defer closingSrv.Close()
+
+		transport := &http.Transport{MaxIdleConns: 5}
+		defer transport.CloseIdleConnections()
+		hc := &http.Client{Transport: transport}
+		c, tracker := newClient(t, hc)
+		c.Endpoint = closingSrv.URL
+
+		_, err := c.GetSecretValueWithContext(context.Background(), &secretsmanager.GetSecretValueInput{SecretId: aws.String("name")})
+		require.Error(t, err)
+
+		stats := tracker.Stats(hc)
+		assert.Zero(t, stats.IdleConns)
+		assert.Zero(t, stats.ActiveConns)
+	})
+	t.Run("ReportsUnknownMaxIdleConnsForCustomTransport", func(t *testing.T) {
+		var tracker ConnectionPoolTracker
+		hc := &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}
+		assert.Equal(t, -1, tracker.Stats(hc).MaxIdleConns)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,19 @@
+package awsutil
+
+import (
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+)
+
+// SupportsDualStackEndpoints returns whether or not the service has dual-stack
+// endpoints, which accept both IPv4 and IPv6 requests, in the region according
+// to the AWS SDK's partition metadata. The service is identified by its
+// endpoint ID (e.g. "ecs").
+func SupportsDualStackEndpoints(service, region string) bool {
+	if region == "" {
+		return false
+	}
+	_, err := endpoints.DefaultResolver().EndpointFor(service, region, func(o *endpoints.Options) {
+		o.UseDualStackEndpoint = endpoints.DualStackEndpointStateEnabled
+	})
+	return err == nil
+}
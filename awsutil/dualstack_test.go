@@ -0,0 +1,65 @@
+package awsutil
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportsDualStackEndpoints(t *testing.T) {
+	assert.True(t, SupportsDualStackEndpoints("ecs", "us-east-1"))
+	assert.True(t, SupportsDualStackEndpoints("secretsmanager", RegionGovCloudUSWest1))
+	assert.False(t, SupportsDualStackEndpoints("ecs", "us-iso-east-1"), "isolated regions should not be supported")
+	assert.False(t, SupportsDualStackEndpoints("foo", "us-east-1"), "unknown services should not be supported")
+	assert.False(t, SupportsDualStackEndpoints("ecs", ""))
+}
+
+func TestDualStackEndpoints(t *testing.T) {
+	for tName, tCase := range map[string]struct {
+		region      string
+		service     string
+		expectedURL string
+	}{
+		"ResolvesDualStackEndpoint": {
+			service:     "ecs",
+			region:      "us-east-1",
+			expectedURL: "https://ecs.us-east-1.api.aws",
+		},
+		"ResolvesDualStackEndpointForSecretsManager": {
+			service:     "secretsmanager",
+			region:      "eu-west-1",
+			expectedURL: "https://secretsmanager.eu-west-1.api.aws",
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			opts := NewClientOptions().
+				SetCredentials(credentials.NewStaticCredentials("id", "secret", "")).
+				SetRegion(tCase.region).
+				SetDualStackEndpoints(true)
+			require.NoError(t, opts.Validate())
+			defer opts.Close()
+
+			sess, err := opts.GetSession()
+			require.NoError(t, err)
+			cfg := sess.ClientConfig(tCase.service)
+			assert.Equal(t, tCase.expectedURL, cfg.Endpoint)
+			assert.Equal(t, tCase.region, cfg.SigningRegion)
+		})
+	}
+
+	t.Run("IsOverriddenByExplicitEndpoint", func(t *testing.T) {
+		opts := NewClientOptions().
+			SetCredentials(credentials.NewStaticCredentials("id", "secret", "")).
+			SetRegion("us-east-1").
+			SetEndpoint("http://localhost:4566").
+			SetDualStackEndpoints(true)
+		require.NoError(t, opts.Validate())
+		defer opts.Close()
+
+		sess, err := opts.GetSession()
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:4566", sess.ClientConfig("ecs").Endpoint)
+	})
+}
@@ -0,0 +1,78 @@
+package awsutil
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// Secrets Manager filter keys used to list secrets by their tags.
+const (
+	secretsManagerFilterTagKey   = "tag-key"
+	secretsManagerFilterTagValue = "tag-value"
+)
+
+// BuildTagFilters builds the Secrets Manager filters to list secrets matching
+// the given tags. Each tag key-value pair produces one filter on the tag key and
+// one filter on the tag value. Since Secrets Manager requires secrets to match
+// all the filters, a secret will only match if it has all of the given tag keys
+// and all of the given tag values.
+//
+// The filters do not enforce that each key is paired with its value, so with
+// multiple tags, a secret can match even if its tag values belong to different
+// keys (e.g. for the tags {a: 1, b: 2}, a secret tagged a=2 and b=1 matches).
+// Use FilterSecretsByTags on the listed secrets to only keep the secrets that
+// have the exact tags.
+// Docs: https://docs.aws.amazon.com/secretsmanager/latest/userguide/manage_search-secret.html
+func BuildTagFilters(tags map[string]string) []*secretsmanager.Filter {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	filters := make([]*secretsmanager.Filter, 0, 2*len(tags))
+	for _, k := range keys {
+		filters = append(filters,
+			&secretsmanager.Filter{
+				Key:    aws.String(secretsManagerFilterTagKey),
+				Values: []*string{aws.String(k)},
+			},
+			&secretsmanager.Filter{
+				Key:    aws.String(secretsManagerFilterTagValue),
+				Values: []*string{aws.String(tags[k])},
+			},
+		)
+	}
+	return filters
+}
+
+// FilterSecretsByTags returns the secrets that have all of the given tags with
+// the exact same values.
+func FilterSecretsByTags(secrets []*secretsmanager.SecretListEntry, tags map[string]string) []*secretsmanager.SecretListEntry {
+	var filtered []*secretsmanager.SecretListEntry
+	for _, s := range secrets {
+		if s != nil && hasAllTags(s.Tags, tags) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// hasAllTags returns whether or not the tags contain all of the given tags with
+// the exact same values.
+func hasAllTags(tags []*secretsmanager.Tag, want map[string]string) bool {
+	have := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		if tag != nil {
+			have[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+	}
+	for k, v := range want {
+		if value, ok := have[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
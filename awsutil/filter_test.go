@@ -0,0 +1,79 @@
+package awsutil
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTagFilters(t *testing.T) {
+	t.Run("ReturnsNoFiltersForNoTags", func(t *testing.T) {
+		assert.Empty(t, BuildTagFilters(nil))
+		assert.Empty(t, BuildTagFilters(map[string]string{}))
+	})
+	t.Run("ReturnsKeyAndValueFiltersForEachTag", func(t *testing.T) {
+		filters := BuildTagFilters(map[string]string{
+			"b": "2",
+			"a": "1",
+		})
+		require.Len(t, filters, 4)
+
+		expected := []struct {
+			key   string
+			value string
+		}{
+			{key: "tag-key", value: "a"},
+			{key: "tag-value", value: "1"},
+			{key: "tag-key", value: "b"},
+			{key: "tag-value", value: "2"},
+		}
+		for i, f := range filters {
+			require.NotZero(t, f)
+			assert.Equal(t, expected[i].key, utility.FromStringPtr(f.Key))
+			assert.Equal(t, []string{expected[i].value}, utility.FromStringPtrSlice(f.Values))
+		}
+	})
+}
+
+func TestFilterSecretsByTags(t *testing.T) {
+	newSecret := func(name string, tags map[string]string) *secretsmanager.SecretListEntry {
+		s := &secretsmanager.SecretListEntry{Name: aws.String(name)}
+		for k, v := range tags {
+			s.Tags = append(s.Tags, &secretsmanager.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+		return s
+	}
+	names := func(secrets []*secretsmanager.SecretListEntry) []string {
+		var names []string
+		for _, s := range secrets {
+			names = append(names, utility.FromStringPtr(s.Name))
+		}
+		return names
+	}
+
+	t.Run("KeepsSecretsWithExactTags", func(t *testing.T) {
+		secrets := []*secretsmanager.SecretListEntry{
+			newSecret("exact", map[string]string{"a": "1", "b": "2"}),
+			newSecret("superset", map[string]string{"a": "1", "b": "2", "c": "3"}),
+			newSecret("swapped", map[string]string{"a": "2", "b": "1"}),
+			newSecret("missing", map[string]string{"a": "1"}),
+			nil,
+		}
+		assert.Equal(t, []string{"exact", "superset"}, names(FilterSecretsByTags(secrets, map[string]string{"a": "1", "b": "2"})))
+	})
+	t.Run("KeepsAllSecretsForNoTags", func(t *testing.T) {
+		secrets := []*secretsmanager.SecretListEntry{
+			newSecret("tagged", map[string]string{"a": "1"}),
+			newSecret("untagged", nil),
+		}
+		assert.Equal(t, []string{"tagged", "untagged"}, names(FilterSecretsByTags(secrets, nil)))
+	})
+	t.Run("ReturnsNoSecretsWithoutMatches", func(t *testing.T) {
+		secrets := []*secretsmanager.SecretListEntry{newSecret("other", map[string]string{"a": "2"})}
+		assert.Empty(t, FilterSecretsByTags(secrets, map[string]string{"a": "1"}))
+	})
+}
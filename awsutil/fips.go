@@ -0,0 +1,22 @@
+package awsutil
+
+import (
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+)
+
+// SupportsFIPSEndpoints returns whether or not the service has a FIPS 140-2
+// validated endpoint in the region according to the AWS SDK's endpoint
+// metadata. The service is identified by its endpoint ID (e.g. "ecs").
+func SupportsFIPSEndpoints(service, region string) bool {
+	if region == "" {
+		return false
+	}
+	// Strict matching only resolves endpoints that the metadata lists for the
+	// region, rather than constructing a FIPS URL from the partition's default
+	// template, which may not exist.
+	_, err := endpoints.DefaultResolver().EndpointFor(service, region, func(o *endpoints.Options) {
+		o.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+		o.StrictMatching = true
+	})
+	return err == nil
+}
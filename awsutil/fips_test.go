@@ -0,0 +1,56 @@
+package awsutil
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportsFIPSEndpoints(t *testing.T) {
+	assert.True(t, SupportsFIPSEndpoints("ecs", "us-east-1"))
+	assert.True(t, SupportsFIPSEndpoints("secretsmanager", "ca-central-1"))
+	assert.True(t, SupportsFIPSEndpoints("ecs", RegionGovCloudUSWest1))
+	assert.False(t, SupportsFIPSEndpoints("ecs", "eu-west-1"))
+	assert.False(t, SupportsFIPSEndpoints("foo", "us-east-1"), "unknown services should not be supported")
+	assert.False(t, SupportsFIPSEndpoints("ecs", ""))
+}
+
+func TestFIPSEndpoints(t *testing.T) {
+	for tName, tCase := range map[string]struct {
+		opts        *ClientOptions
+		service     string
+		expectedURL string
+	}{
+		"ResolvesFIPSEndpoint": {
+			opts:        NewClientOptions().SetFIPSEndpoints(true),
+			service:     "ecs",
+			expectedURL: "https://ecs-fips.us-west-2.amazonaws.com",
+		},
+		"ResolvesFIPSEndpointForSecretsManager": {
+			opts:        NewClientOptions().SetFIPSEndpoints(true),
+			service:     "secretsmanager",
+			expectedURL: "https://secretsmanager-fips.us-west-2.amazonaws.com",
+		},
+		"ResolvesDualStackFIPSEndpoint": {
+			opts:        NewClientOptions().SetFIPSEndpoints(true).SetDualStackEndpoints(true),
+			service:     "ecs",
+			expectedURL: "https://ecs-fips.us-west-2.api.aws",
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			opts := tCase.opts.
+				SetCredentials(credentials.NewStaticCredentials("id", "secret", "")).
+				SetRegion("us-west-2")
+			require.NoError(t, opts.Validate())
+			defer opts.Close()
+
+			sess, err := opts.GetSession()
+			require.NoError(t, err)
+			cfg := sess.ClientConfig(tCase.service)
+			assert.Equal(t, tCase.expectedURL, cfg.Endpoint)
+			assert.Equal(t, "us-west-2", cfg.SigningRegion)
+		})
+	}
+}
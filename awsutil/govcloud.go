@@ -0,0 +1,35 @@
+package awsutil
+
+import (
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/pkg/errors"
+)
+
+const (
+	// RegionGovCloudUSEast1 is the AWS GovCloud (US-East) region.
+	RegionGovCloudUSEast1 = "us-gov-east-1"
+	// RegionGovCloudUSWest1 is the AWS GovCloud (US-West) region.
+	RegionGovCloudUSWest1 = "us-gov-west-1"
+)
+
+// IsGovCloudRegion returns whether or not the region is in the AWS GovCloud
+// (US) partition according to the AWS SDK's partition metadata.
+func IsGovCloudRegion(region string) bool {
+	p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region)
+	return ok && p.ID() == endpoints.AwsUsGovPartitionID
+}
+
+// GovCloudEndpointResolver resolves service endpoints in the AWS GovCloud (US)
+// regions. It is a thin wrapper around the AWS SDK's partition metadata, which
+// already knows the standard, FIPS and dual-stack endpoints for GovCloud, and
+// only adds a check that the region is actually in GovCloud so that a
+// misconfigured region is not silently sent to a commercial endpoint.
+type GovCloudEndpointResolver struct{}
+
+// EndpointFor returns the endpoint for the service in the GovCloud region.
+func (GovCloudEndpointResolver) EndpointFor(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+	if !IsGovCloudRegion(region) {
+		return endpoints.ResolvedEndpoint{}, errors.Errorf("region '%s' is not a GovCloud region", region)
+	}
+	return endpoints.DefaultResolver().EndpointFor(service, region, opts...)
+}
@@ -0,0 +1,121 @@
+package awsutil
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsGovCloudRegion(t *testing.T) {
+	assert.True(t, IsGovCloudRegion(RegionGovCloudUSEast1))
+	assert.True(t, IsGovCloudRegion(RegionGovCloudUSWest1))
+	assert.False(t, IsGovCloudRegion("us-east-1"))
+	assert.False(t, IsGovCloudRegion("cn-north-1"))
+	assert.False(t, IsGovCloudRegion(""))
+}
+
+func TestGovCloudEndpointResolver(t *testing.T) {
+	var r endpoints.Resolver = GovCloudEndpointResolver{}
+
+	for tName, tCase := range map[string]struct {
+		service     string
+		region      string
+		opts        []func(*endpoints.Options)
+		expectedURL string
+	}{
+		"ResolvesStandardEndpoint": {
+			service:     "ecs",
+			region:      RegionGovCloudUSEast1,
+			expectedURL: "https://ecs.us-gov-east-1.amazonaws.com",
+		},
+		"ResolvesFIPSEndpoint": {
+			service:     "secretsmanager",
+			region:      RegionGovCloudUSWest1,
+			opts:        []func(*endpoints.Options){func(o *endpoints.Options) { o.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled }},
+			expectedURL: "https://secretsmanager-fips.us-gov-west-1.amazonaws.com",
+		},
+		"ResolvesDualStackEndpoint": {
+			service:     "secretsmanager",
+			region:      RegionGovCloudUSEast1,
+			opts:        []func(*endpoints.Options){func(o *endpoints.Options) { o.UseDualStackEndpoint = endpoints.DualStackEndpointStateEnabled }},
+			expectedURL: "https://secretsmanager.us-gov-east-1.api.aws",
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			endpoint, err := r.EndpointFor(tCase.service, tCase.region, tCase.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tCase.expectedURL, endpoint.URL)
+			assert.Equal(t, tCase.region, endpoint.SigningRegion)
+		})
+	}
+
+	t.Run("FailsWithNonGovCloudRegion", func(t *testing.T) {
+		_, err := r.EndpointFor("ecs", "us-east-1")
+		assert.Error(t, err)
+	})
+}
+
+func TestGovCloudEndpoints(t *testing.T) {
+	for tName, tCase := range map[string]struct {
+		opts        *ClientOptions
+		service     string
+		region      string
+		expectedURL string
+	}{
+		"ResolvesStandardEndpoint": {
+			opts:        NewClientOptions(),
+			service:     "ecs",
+			region:      RegionGovCloudUSWest1,
+			expectedURL: "https://ecs.us-gov-west-1.amazonaws.com",
+		},
+		"ResolvesFIPSEndpoint": {
+			opts:        NewClientOptions().SetFIPSEndpoints(true),
+			service:     "ecs",
+			region:      RegionGovCloudUSWest1,
+			expectedURL: "https://ecs-fips.us-gov-west-1.amazonaws.com",
+		},
+		"ResolvesFIPSEndpointForSecretsManager": {
+			opts:        NewClientOptions().SetFIPSEndpoints(true),
+			service:     "secretsmanager",
+			region:      RegionGovCloudUSEast1,
+			expectedURL: "https://secretsmanager-fips.us-gov-east-1.amazonaws.com",
+		},
+		"ResolvesDualStackEndpoint": {
+			opts:        NewClientOptions().SetDualStackEndpoints(true),
+			service:     "secretsmanager",
+			region:      RegionGovCloudUSEast1,
+			expectedURL: "https://secretsmanager.us-gov-east-1.api.aws",
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			opts := tCase.opts.
+				SetCredentials(credentials.NewStaticCredentials("id", "secret", "")).
+				SetRegion(tCase.region)
+			require.NoError(t, opts.Validate())
+			defer opts.Close()
+
+			sess, err := opts.GetSession()
+			require.NoError(t, err)
+			cfg := sess.ClientConfig(tCase.service)
+			assert.Equal(t, tCase.expectedURL, cfg.Endpoint)
+			assert.Equal(t, tCase.region, cfg.SigningRegion)
+		})
+	}
+
+	t.Run("IsOverriddenByExplicitEndpoint", func(t *testing.T) {
+		opts := NewClientOptions().
+			SetCredentials(credentials.NewStaticCredentials("id", "secret", "")).
+			SetRegion(RegionGovCloudUSWest1).
+			SetEndpoint("http://localhost:4566").
+			SetFIPSEndpoints(true)
+		require.NoError(t, opts.Validate())
+		defer opts.Close()
+
+		sess, err := opts.GetSession()
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:4566", sess.ClientConfig("ecs").Endpoint)
+	})
+}
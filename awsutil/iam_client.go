@@ -0,0 +1,89 @@
+package awsutil
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+// BasicIAMClient provides a cocoa.IAMClient implementation that wraps the AWS
+// Identity and Access Management API. It supports retrying requests using
+// exponential backoff and jitter.
+type BasicIAMClient struct {
+	BaseClient
+	iam *iam.IAM
+}
+
+// NewBasicIAMClient creates a new AWS Identity and Access Management client
+// from the given options.
+func NewBasicIAMClient(opts ClientOptions) (*BasicIAMClient, error) {
+	c := &BasicIAMClient{
+		BaseClient: NewBaseClient(opts),
+	}
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	return c, nil
+}
+
+func (c *BasicIAMClient) setup() error {
+	if c.iam != nil {
+		return nil
+	}
+
+	sess, err := c.GetSession()
+	if err != nil {
+		return errors.Wrap(err, "initializing session")
+	}
+
+	c.iam = iam.New(sess)
+
+	return nil
+}
+
+// GetRole gets information about an IAM role.
+func (c *BasicIAMClient) GetRole(ctx context.Context, in *iam.GetRoleInput) (*iam.GetRoleOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *iam.GetRoleOutput
+	var err error
+	if stats, err := RetryWithStats(ctx, func() (bool, error) {
+		msg := MakeAPILogMessage("GetRole", in, RequestIDFrom(ctx))
+		out, err = c.iam.GetRoleWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, MakeAPIRetryFailureLogMessage("GetRole", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// Close cleans up all resources owned by the client.
+func (c *BasicIAMClient) Close(ctx context.Context) error {
+	return c.BaseClient.Close(ctx)
+}
+
+func (c *BasicIAMClient) isNonRetryableErrorCode(code string) bool {
+	switch code {
+	case "AccessDenied",
+		iam.ErrCodeNoSuchEntityException,
+		request.InvalidParameterErrCode,
+		request.ParamRequiredErrCode:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,97 @@
+package awsutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBasicIAMClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.IAMClient)(nil), &BasicIAMClient{})
+
+	t.Run("FailsWithInvalidOptions", func(t *testing.T) {
+		c, err := NewBasicIAMClient(*NewClientOptions())
+		assert.Error(t, err)
+		assert.Zero(t, c)
+	})
+
+	const roleARN = "arn:aws:iam::123456789012:role/app"
+
+	// The IAM API uses the query protocol, so the fake server reads the form
+	// parameters and responds with XML.
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+		if roleName := r.FormValue("RoleName"); roleName != "app" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `<ErrorResponse xmlns="https://iam.amazonaws.com/doc/2010-05-08/"><Error><Type>Sender</Type><Code>%s</Code><Message>The role with name %s cannot be found.</Message></Error><RequestId>request_id</RequestId></ErrorResponse>`, iam.ErrCodeNoSuchEntityException, roleName)
+			return
+		}
+		fmt.Fprintf(w, `<GetRoleResponse xmlns="https://iam.amazonaws.com/doc/2010-05-08/"><GetRoleResult><Role><Path>/</Path><RoleName>app</RoleName><RoleId>role_id</RoleId><Arn>%s</Arn><CreateDate>2020-01-01T00:00:00Z</CreateDate></Role></GetRoleResult><ResponseMetadata><RequestId>request_id</RequestId></ResponseMetadata></GetRoleResponse>`, roleARN)
+	}))
+	defer srv.Close()
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *BasicIAMClient){
+		"GetRoleSucceeds": func(ctx context.Context, t *testing.T, c *BasicIAMClient) {
+			out, err := c.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String("app")})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			require.NotZero(t, out.Role)
+			assert.Equal(t, "app", utility.FromStringPtr(out.Role.RoleName))
+			assert.Equal(t, roleARN, utility.FromStringPtr(out.Role.Arn))
+			assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+		},
+		"GetRoleFailsWithNonexistentRoleWithoutRetrying": func(ctx context.Context, t *testing.T, c *BasicIAMClient) {
+			out, err := c.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String("nonexistent")})
+			require.Error(t, err)
+			assert.Zero(t, out)
+			awsErr, ok := err.(awserr.Error)
+			require.True(t, ok, "error should be an AWS error")
+			assert.Equal(t, iam.ErrCodeNoSuchEntityException, awsErr.Code())
+			assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+		},
+		"GetRoleFailsWithInvalidInput": func(ctx context.Context, t *testing.T, c *BasicIAMClient) {
+			out, err := c.GetRole(ctx, &iam.GetRoleInput{})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+			assert.Zero(t, atomic.LoadInt32(&requests), "invalid input should not be sent")
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			atomic.StoreInt32(&requests, 0)
+
+			c, err := NewBasicIAMClient(*NewClientOptions().
+				SetCredentials(credentials.NewStaticCredentials("access_key_id", "secret_access_key", "")).
+				SetRegion("us-east-1").
+				SetEndpoint(srv.URL))
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, c.Close(ctx))
+			}()
+
+			tCase(ctx, t, c)
+		})
+	}
+}
@@ -1,12 +1,156 @@
 package awsutil
 
-import "github.com/mongodb/grip/message"
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip/message"
+)
+
+// redactedValue replaces the values of redacted fields in API log messages.
+const redactedValue = "<redacted>"
+
+// defaultRedactedFields are the input fields whose values are always redacted
+// from API log messages because they contain secret values.
+var defaultRedactedFields = []string{"SecretString", "SecretBinary"}
+
+// apiLogOptions are the options to create an API log message.
+type apiLogOptions struct {
+	redactedFields []string
+	requestID      string
+}
+
+// APILogOption is an option to create an API log message.
+type APILogOption func(*apiLogOptions)
+
+// RedactedFields sets additional top-level input field names (e.g.
+// "Environment") whose values should be omitted from the API log message, on
+// top of the secret values that are always redacted.
+func RedactedFields(fields []string) APILogOption {
+	return func(o *apiLogOptions) {
+		o.redactedFields = append(o.redactedFields, fields...)
+	}
+}
+
+// RequestIDFrom includes the request ID carried by the context (see
+// WithRequestID) in the API log message, if there is one.
+func RequestIDFrom(ctx context.Context) APILogOption {
+	return func(o *apiLogOptions) {
+		if id, ok := RequestIDFromContext(ctx); ok {
+			o.requestID = id
+		}
+	}
+}
 
 // MakeAPILogMessage creates a message to log information about an API call.
-func MakeAPILogMessage(op string, in interface{}) message.Fields {
-	return message.Fields{
+// Secret values in the input (i.e. SecretString and SecretBinary) are
+// redacted, along with any additional fields given by RedactedFields. Besides
+// the input, the message includes a summary of the input's scalar parameters.
+// For ECS calls, the summary also includes the number of tasks, if any.
+func MakeAPILogMessage(op string, in interface{}, opts ...APILogOption) message.Fields {
+	o := apiLogOptions{redactedFields: append([]string{}, defaultRedactedFields...)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	redacted, params := redactAPIInput(in, o.redactedFields)
+	msg := message.Fields{
 		"message": "AWS API call",
 		"op":      op,
-		"input":   in,
+		"input":   redacted,
+	}
+	if len(params) != 0 {
+		msg["params"] = params
+	}
+	if o.requestID != "" {
+		msg["request_id"] = o.requestID
+	}
+
+	return msg
+}
+
+// redactAPIInput returns a shallow copy of the input with the redacted fields
+// cleared, along with a summary of its scalar parameters. If the input is not
+// a pointer to a struct, it is returned as-is without a summary.
+func redactAPIInput(in interface{}, redactedFields []string) (interface{}, map[string]interface{}) {
+	v := reflect.ValueOf(in)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return in, nil
+	}
+
+	cp := reflect.New(v.Elem().Type())
+	cp.Elem().Set(v.Elem())
+	elem := cp.Elem()
+	t := elem.Type()
+
+	params := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		field := elem.Field(i)
+		if field.IsZero() {
+			continue
+		}
+		if utility.StringSliceContains(redactedFields, f.Name) {
+			params[f.Name] = redactedValue
+			field.Set(reflect.Zero(f.Type))
+			continue
+		}
+		if val, ok := scalarParam(field); ok {
+			params[f.Name] = val
+		}
+	}
+
+	if strings.HasSuffix(t.PkgPath(), "/service/ecs") {
+		if count, ok := ecsTaskCount(elem); ok {
+			params["TaskCount"] = count
+		}
+	}
+
+	return cp.Interface(), params
+}
+
+// scalarParam returns the value of the field if it is a pointer to a string,
+// number, boolean or time.
+func scalarParam(field reflect.Value) (interface{}, bool) {
+	if field.Kind() != reflect.Ptr || field.IsNil() {
+		return nil, false
+	}
+	elem := field.Elem()
+	switch elem.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int64, reflect.Float64:
+		return elem.Interface(), true
+	}
+	if ts, ok := elem.Interface().(time.Time); ok {
+		return ts, true
+	}
+	return nil, false
+}
+
+// ecsTaskCount returns the number of tasks that the ECS input refers to,
+// either as a list of tasks or as a count of tasks to start.
+func ecsTaskCount(elem reflect.Value) (int64, bool) {
+	if tasks := elem.FieldByName("Tasks"); tasks.IsValid() && tasks.Kind() == reflect.Slice && !tasks.IsNil() {
+		return int64(tasks.Len()), true
+	}
+	if count := elem.FieldByName("Count"); count.IsValid() && count.Kind() == reflect.Ptr && !count.IsNil() && count.Elem().Kind() == reflect.Int64 {
+		return count.Elem().Int(), true
+	}
+	return 0, false
+}
+
+// MakeAPIRetryFailureLogMessage creates a message to log information about an
+// API call that failed after all of its attempts.
+func MakeAPIRetryFailureLogMessage(op string, stats RetryStats) message.Fields {
+	return message.Fields{
+		"message":          "AWS API call failed",
+		"op":               op,
+		"attempts":         stats.Attempts,
+		"total_delay_secs": stats.TotalDelay.Seconds(),
 	}
 }
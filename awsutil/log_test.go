@@ -0,0 +1,92 @@
+package awsutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeAPILogMessage(t *testing.T) {
+	t.Run("RedactsSecretValues", func(t *testing.T) {
+		in := &secretsmanager.CreateSecretInput{
+			Name:         aws.String("name"),
+			SecretString: aws.String("hunter2"),
+			SecretBinary: []byte("hunter2"),
+		}
+		msg := MakeAPILogMessage("CreateSecret", in)
+		assert.Equal(t, "CreateSecret", msg["op"])
+
+		redacted, ok := msg["input"].(*secretsmanager.CreateSecretInput)
+		require.True(t, ok)
+		assert.Nil(t, redacted.SecretString)
+		assert.Nil(t, redacted.SecretBinary)
+		assert.Equal(t, "name", aws.StringValue(redacted.Name))
+
+		params, ok := msg["params"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "name", params["Name"])
+		assert.Equal(t, redactedValue, params["SecretString"])
+		assert.Equal(t, redactedValue, params["SecretBinary"])
+
+		assert.Equal(t, "hunter2", aws.StringValue(in.SecretString), "original input should not be modified")
+		assert.Equal(t, []byte("hunter2"), in.SecretBinary, "original input should not be modified")
+	})
+	t.Run("IncludesECSClusterAndTaskCount", func(t *testing.T) {
+		msg := MakeAPILogMessage("DescribeTasks", &ecs.DescribeTasksInput{
+			Cluster: aws.String("cluster"),
+			Tasks:   aws.StringSlice([]string{"task0", "task1"}),
+		})
+		params, ok := msg["params"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "cluster", params["Cluster"])
+		assert.EqualValues(t, 2, params["TaskCount"])
+	})
+	t.Run("IncludesECSTaskCountFromCount", func(t *testing.T) {
+		msg := MakeAPILogMessage("RunTask", &ecs.RunTaskInput{
+			Cluster: aws.String("cluster"),
+			Count:   aws.Int64(3),
+		})
+		params, ok := msg["params"].(map[string]interface{})
+		require.True(t, ok)
+		assert.EqualValues(t, 3, params["TaskCount"])
+	})
+	t.Run("RedactsAdditionalFields", func(t *testing.T) {
+		in := &ecs.RunTaskInput{
+			Cluster:   aws.String("cluster"),
+			StartedBy: aws.String("user"),
+		}
+		msg := MakeAPILogMessage("RunTask", in, RedactedFields([]string{"StartedBy"}))
+
+		redacted, ok := msg["input"].(*ecs.RunTaskInput)
+		require.True(t, ok)
+		assert.Nil(t, redacted.StartedBy)
+		assert.Equal(t, "cluster", aws.StringValue(redacted.Cluster))
+
+		params, ok := msg["params"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, redactedValue, params["StartedBy"])
+		assert.Equal(t, "user", aws.StringValue(in.StartedBy))
+	})
+	t.Run("IncludesRequestIDFromContext", func(t *testing.T) {
+		ctx := WithRequestID(context.Background(), "request-id")
+		msg := MakeAPILogMessage("RunTask", &ecs.RunTaskInput{}, RequestIDFrom(ctx))
+		assert.Equal(t, "request-id", msg["request_id"])
+	})
+	t.Run("OmitsMissingRequestID", func(t *testing.T) {
+		msg := MakeAPILogMessage("RunTask", &ecs.RunTaskInput{}, RequestIDFrom(context.Background()))
+		assert.NotContains(t, msg, "request_id")
+	})
+	t.Run("PassesThroughNonStructInput", func(t *testing.T) {
+		msg := MakeAPILogMessage("Op", "input")
+		assert.Equal(t, "input", msg["input"])
+		assert.NotContains(t, msg, "params")
+
+		msg = MakeAPILogMessage("Op", (*ecs.RunTaskInput)(nil))
+		assert.NotContains(t, msg, "params")
+	})
+}
@@ -0,0 +1,27 @@
+package awsutil
+
+import "context"
+
+// requestIDKey is the context key for the request ID.
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of the context that carries the request ID.
+// The request ID is included in the logs for all API calls made with the
+// context, so that the logs for multiple API calls that are part of the same
+// logical operation (e.g. running a task and then tagging it) can be
+// correlated.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by the context, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	if !ok || id == "" {
+		return "", false
+	}
+	return id, true
+}
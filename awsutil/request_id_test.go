@@ -0,0 +1,34 @@
+package awsutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	t.Run("RoundTripsThroughContext", func(t *testing.T) {
+		ctx := WithRequestID(context.Background(), "id")
+		id, ok := RequestIDFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "id", id)
+	})
+	t.Run("MissingFromContext", func(t *testing.T) {
+		id, ok := RequestIDFromContext(context.Background())
+		assert.False(t, ok)
+		assert.Empty(t, id)
+	})
+	t.Run("EmptyIDIsMissing", func(t *testing.T) {
+		id, ok := RequestIDFromContext(WithRequestID(context.Background(), ""))
+		assert.False(t, ok)
+		assert.Empty(t, id)
+	})
+	t.Run("InheritedByChildContexts", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithRequestID(context.Background(), "id"))
+		defer cancel()
+		id, ok := RequestIDFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "id", id)
+	})
+}
@@ -0,0 +1,117 @@
+package awsutil
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/evergreen-ci/utility"
+)
+
+// ExponentialBackoff calls fn until it succeeds, it returns an error that
+// cannot be retried, or it has been attempted the maximum number of times. It
+// uses the same exponential backoff and jitter between attempts as the AWS
+// clients, so it can be used to retry AWS API calls that are not made through
+// a client. Unset retry options are set to their defaults; in particular, if
+// the maximum number of attempts is not set, fn is only attempted once.
+func ExponentialBackoff(ctx context.Context, opts utility.RetryOptions, fn func() (bool, error)) error {
+	opts.Validate()
+	return utility.Retry(ctx, fn, opts)
+}
+
+// RetryStats contains statistics about the attempts made to perform a
+// retryable operation.
+type RetryStats struct {
+	// Attempts is the number of times the operation was attempted.
+	Attempts int
+	// TotalDelay is the total time spent waiting between attempts.
+	TotalDelay time.Duration
+	// LastError is the error returned by the last attempt, if any.
+	LastError error
+}
+
+// RetryWithStats is the same as utility.Retry but also returns statistics
+// about the attempts that were made.
+func RetryWithStats(ctx context.Context, fn utility.RetryableFunc, opts utility.RetryOptions) (RetryStats, error) {
+	var stats RetryStats
+	var lastAttemptEnd time.Time
+	err := utility.Retry(ctx, func() (bool, error) {
+		if stats.Attempts > 0 {
+			stats.TotalDelay += time.Since(lastAttemptEnd)
+		}
+		stats.Attempts++
+
+		canRetry, err := fn()
+		lastAttemptEnd = time.Now()
+		stats.LastError = err
+
+		return canRetry, err
+	}, opts)
+
+	return stats, err
+}
+
+// minRetryDelay is the smallest delay between attempts that utility.Retry
+// allows.
+const minRetryDelay = 100 * time.Millisecond
+
+// RetryOptionsBuilder builds the options to retry a request using exponential
+// backoff.
+type RetryOptionsBuilder struct {
+	opts       utility.RetryOptions
+	multiplier float64
+}
+
+// NewRetryOptions returns a new builder for retry options. By default, the
+// options are the same as the zero utility.RetryOptions, so requests are only
+// attempted once.
+func NewRetryOptions() *RetryOptionsBuilder {
+	return &RetryOptionsBuilder{}
+}
+
+// WithMaxAttempts sets the total number of times a request can be attempted.
+func (b *RetryOptionsBuilder) WithMaxAttempts(n int) *RetryOptionsBuilder {
+	b.opts.MaxAttempts = n
+	return b
+}
+
+// WithInitialDelay sets the delay before the first retry. Delays shorter than
+// 100ms are raised to 100ms.
+func (b *RetryOptionsBuilder) WithInitialDelay(d time.Duration) *RetryOptionsBuilder {
+	b.opts.MinDelay = d
+	return b
+}
+
+// WithMaxDelay sets the maximum delay between attempts.
+func (b *RetryOptionsBuilder) WithMaxDelay(d time.Duration) *RetryOptionsBuilder {
+	b.opts.MaxDelay = d
+	return b
+}
+
+// WithMultiplier sets how quickly the delay may grow over all the attempts.
+// utility.Retry always doubles the delay between consecutive attempts (with
+// jitter) and cannot use a different factor, so the multiplier is instead
+// mapped onto the maximum delay if it is not explicitly set: the maximum delay
+// is the initial delay multiplied by the multiplier once per attempt. For example, a multiplier of 1.5 with 4 attempts allows
+// the delay to grow to about 5 times the initial delay.
+func (b *RetryOptionsBuilder) WithMultiplier(m float64) *RetryOptionsBuilder {
+	b.multiplier = m
+	return b
+}
+
+// Build returns the retry options.
+func (b *RetryOptionsBuilder) Build() utility.RetryOptions {
+	opts := b.opts
+	if b.multiplier > 0 && opts.MaxDelay <= 0 {
+		minDelay := opts.MinDelay
+		if minDelay < minRetryDelay {
+			minDelay = minRetryDelay
+		}
+		attempts := opts.MaxAttempts
+		if attempts < 1 {
+			attempts = 1
+		}
+		opts.MaxDelay = time.Duration(float64(minDelay) * math.Pow(b.multiplier, float64(attempts)))
+	}
+	return opts
+}
@@ -0,0 +1,149 @@
+package awsutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Run("SucceedsAfterRetrying", func(t *testing.T) {
+		var calls int
+		err := ExponentialBackoff(context.Background(), utility.RetryOptions{MaxAttempts: 3}, func() (bool, error) {
+			calls++
+			if calls < 2 {
+				return true, errors.New("fake error")
+			}
+			return false, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("FailsAfterMaxAttempts", func(t *testing.T) {
+		var calls int
+		err := ExponentialBackoff(context.Background(), utility.RetryOptions{MaxAttempts: 2}, func() (bool, error) {
+			calls++
+			return true, errors.New("fake error")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("AttemptsOnceWithoutMaxAttempts", func(t *testing.T) {
+		var calls int
+		err := ExponentialBackoff(context.Background(), utility.RetryOptions{}, func() (bool, error) {
+			calls++
+			return true, errors.New("fake error")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("StopsOnNonRetryableError", func(t *testing.T) {
+		var calls int
+		err := ExponentialBackoff(context.Background(), utility.RetryOptions{MaxAttempts: 3}, func() (bool, error) {
+			calls++
+			return false, errors.New("fake error")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestRetryWithStats(t *testing.T) {
+	opts := utility.RetryOptions{
+		MaxAttempts: 3,
+		MinDelay:    10 * time.Millisecond,
+		MaxDelay:    10 * time.Millisecond,
+	}
+
+	t.Run("CountsSingleAttemptOnSuccess", func(t *testing.T) {
+		stats, err := RetryWithStats(context.Background(), func() (bool, error) {
+			return false, nil
+		}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.Attempts)
+		assert.Zero(t, stats.TotalDelay)
+		assert.NoError(t, stats.LastError)
+	})
+	t.Run("CountsAttemptsUntilSuccess", func(t *testing.T) {
+		var calls int
+		stats, err := RetryWithStats(context.Background(), func() (bool, error) {
+			calls++
+			if calls < 2 {
+				return true, errors.New("fake error")
+			}
+			return false, nil
+		}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 2, stats.Attempts)
+		assert.True(t, stats.TotalDelay >= opts.MinDelay, "delay should be at least the minimum retry delay")
+		assert.NoError(t, stats.LastError)
+	})
+	t.Run("CountsAllAttemptsOnFailure", func(t *testing.T) {
+		stats, err := RetryWithStats(context.Background(), func() (bool, error) {
+			return true, errors.New("fake error")
+		}, opts)
+		require.Error(t, err)
+		assert.Equal(t, opts.MaxAttempts, stats.Attempts)
+		assert.True(t, stats.TotalDelay >= 2*opts.MinDelay, "delay should be at least the minimum retry delay between each attempt")
+		require.Error(t, stats.LastError)
+		assert.Contains(t, stats.LastError.Error(), "fake error")
+	})
+	t.Run("StopsOnNonRetryableError", func(t *testing.T) {
+		stats, err := RetryWithStats(context.Background(), func() (bool, error) {
+			return false, errors.New("fake error")
+		}, opts)
+		require.Error(t, err)
+		assert.Equal(t, 1, stats.Attempts)
+		assert.Error(t, stats.LastError)
+	})
+}
+
+func TestRetryOptionsBuilder(t *testing.T) {
+	t.Run("BuildsZeroOptionsByDefault", func(t *testing.T) {
+		assert.Equal(t, utility.RetryOptions{}, NewRetryOptions().Build())
+	})
+	t.Run("BuildsOptionsWithAllFields", func(t *testing.T) {
+		opts := NewRetryOptions().
+			WithMaxAttempts(5).
+			WithInitialDelay(200 * time.Millisecond).
+			WithMaxDelay(time.Second).
+			Build()
+		assert.Equal(t, utility.RetryOptions{
+			MaxAttempts: 5,
+			MinDelay:    200 * time.Millisecond,
+			MaxDelay:    time.Second,
+		}, opts)
+	})
+	t.Run("MultiplierSetsMaxDelay", func(t *testing.T) {
+		opts := NewRetryOptions().
+			WithMaxAttempts(2).
+			WithInitialDelay(time.Second).
+			WithMultiplier(3).
+			Build()
+		assert.Equal(t, 9*time.Second, opts.MaxDelay)
+	})
+	t.Run("MultiplierUsesMinimumInitialDelay", func(t *testing.T) {
+		opts := NewRetryOptions().WithMaxAttempts(1).WithMultiplier(2).Build()
+		assert.Equal(t, 200*time.Millisecond, opts.MaxDelay)
+		assert.Zero(t, opts.MinDelay)
+	})
+	t.Run("ExplicitMaxDelayTakesPrecedenceOverMultiplier", func(t *testing.T) {
+		opts := NewRetryOptions().
+			WithMaxAttempts(3).
+			WithMaxDelay(time.Second).
+			WithMultiplier(10).
+			Build()
+		assert.Equal(t, time.Second, opts.MaxDelay)
+	})
+	t.Run("BuiltOptionsCanBeUsedByClientOptions", func(t *testing.T) {
+		retryOpts := NewRetryOptions().WithMaxAttempts(3).Build()
+		opts := NewClientOptions().SetRetryOptions(retryOpts)
+		require.NotZero(t, opts.RetryOpts)
+		assert.Equal(t, retryOpts, *opts.RetryOpts)
+	})
+}
@@ -0,0 +1,116 @@
+package awsutil
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+)
+
+// tagAllResourcesConcurrency is the maximum number of concurrent requests that
+// TagAllResources makes to each service.
+const tagAllResourcesConcurrency = 10
+
+// ECSResourceTagger tags ECS resources. It is satisfied by cocoa.ECSClient.
+type ECSResourceTagger interface {
+	TagResource(ctx context.Context, in *ecs.TagResourceInput) (*ecs.TagResourceOutput, error)
+}
+
+// SecretTagger tags Secrets Manager secrets. It is satisfied by
+// cocoa.SecretsManagerClient.
+type SecretTagger interface {
+	TagResource(ctx context.Context, in *secretsmanager.TagResourceInput) (*secretsmanager.TagResourceOutput, error)
+}
+
+// TagAllResources adds the same tags to the ECS resources and Secrets Manager
+// secrets. The resources are tagged concurrently across both services. It
+// returns an error for each resource that could not be tagged, ordered with
+// the ECS resources first and then the secrets, in the order they were given.
+// If all the resources are tagged, it returns no errors.
+func TagAllResources(ctx context.Context, ecsc ECSResourceTagger, smc SecretTagger, tags map[string]string, ecsARNs, secretARNs []string) []error {
+	ecsTags := make([]*ecs.Tag, 0, len(tags))
+	secretTags := make([]*secretsmanager.Tag, 0, len(tags))
+	for _, kv := range sortedTags(tags) {
+		ecsTags = append(ecsTags, &ecs.Tag{Key: aws.String(kv[0]), Value: aws.String(kv[1])})
+		secretTags = append(secretTags, &secretsmanager.Tag{Key: aws.String(kv[0]), Value: aws.String(kv[1])})
+	}
+
+	errs := make([]error, len(ecsARNs)+len(secretARNs))
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		tagConcurrently(ctx, ecsARNs, errs[:len(ecsARNs)], func(arn string) error {
+			if ecsc == nil {
+				return errors.New("missing ECS client")
+			}
+			_, err := ecsc.TagResource(ctx, &ecs.TagResourceInput{
+				ResourceArn: aws.String(arn),
+				Tags:        ecsTags,
+			})
+			return errors.Wrapf(err, "tagging ECS resource '%s'", arn)
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		tagConcurrently(ctx, secretARNs, errs[len(ecsARNs):], func(arn string) error {
+			if smc == nil {
+				return errors.New("missing Secrets Manager client")
+			}
+			_, err := smc.TagResource(ctx, &secretsmanager.TagResourceInput{
+				SecretId: aws.String(arn),
+				Tags:     secretTags,
+			})
+			return errors.Wrapf(err, "tagging secret '%s'", arn)
+		})
+	}()
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	return failed
+}
+
+// tagConcurrently tags each resource and records its error at the same index
+// in errs. Resources that have not started being tagged when the context is
+// done fail with the context error.
+func tagConcurrently(ctx context.Context, arns []string, errs []error, tag func(arn string) error) {
+	sem := make(chan struct{}, tagAllResourcesConcurrency)
+	var wg sync.WaitGroup
+	for i, arn := range arns {
+		select {
+		case <-ctx.Done():
+			errs[i] = errors.Wrapf(ctx.Err(), "tagging resource '%s'", arn)
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, arn string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = tag(arn)
+		}(i, arn)
+	}
+	wg.Wait()
+}
+
+// sortedTags returns the tags as key-value pairs sorted by key.
+func sortedTags(tags map[string]string) [][2]string {
+	kvs := make([][2]string, 0, len(tags))
+	for k, v := range tags {
+		kvs = append(kvs, [2]string{k, v})
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i][0] < kvs[j][0] })
+	return kvs
+}
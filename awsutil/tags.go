@@ -0,0 +1,114 @@
+package awsutil
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/utility"
+)
+
+// Tag keys for the standard metadata tags.
+const (
+	OwnerTagKey       = "Owner"
+	EnvironmentTagKey = "Environment"
+	ComponentTagKey   = "Component"
+	CreatedAtTagKey   = "CreatedAt"
+)
+
+// Tag keys for cost attribution tags, which are used alongside the
+// environment and creation time tags to attribute resource costs.
+const (
+	ProjectTagKey = "Project"
+	TeamTagKey    = "Team"
+)
+
+// standardTags returns the standard metadata tags as key-value pairs in a
+// consistent order. Metadata that is empty is omitted. The creation timestamp
+// is always included and is formatted as an RFC 3339 timestamp in UTC.
+func standardTags(owner, environment, component string) [][2]string {
+	var tags [][2]string
+	for _, kv := range [][2]string{
+		{OwnerTagKey, owner},
+		{EnvironmentTagKey, environment},
+		{ComponentTagKey, component},
+	} {
+		if kv[1] != "" {
+			tags = append(tags, kv)
+		}
+	}
+	return append(tags, [2]string{CreatedAtTagKey, time.Now().UTC().Format(time.RFC3339)})
+}
+
+// StandardTags returns the standard set of metadata tags for an ECS resource,
+// which includes the owner, environment, component, and the creation time.
+// Metadata that is empty is omitted.
+func StandardTags(owner, environment, component string) []*ecs.Tag {
+	var tags []*ecs.Tag
+	for _, kv := range standardTags(owner, environment, component) {
+		tags = append(tags, &ecs.Tag{Key: aws.String(kv[0]), Value: aws.String(kv[1])})
+	}
+	return tags
+}
+
+// StandardSecretTags returns the standard set of metadata tags for a Secrets
+// Manager secret, which includes the owner, environment, component, and the
+// creation time. Metadata that is empty is omitted.
+func StandardSecretTags(owner, environment, component string) []*secretsmanager.Tag {
+	var tags []*secretsmanager.Tag
+	for _, kv := range standardTags(owner, environment, component) {
+		tags = append(tags, &secretsmanager.Tag{Key: aws.String(kv[0]), Value: aws.String(kv[1])})
+	}
+	return tags
+}
+
+// MergeECSTags combines the sets of ECS tags into a single set of tags with no
+// duplicate keys. If the same key appears in multiple tag sets, the value from
+// the last tag set takes precedence. Tags are returned in the order that their
+// keys first appear.
+func MergeECSTags(tagSets ...[]*ecs.Tag) []*ecs.Tag {
+	var merged []*ecs.Tag
+	idxByKey := map[string]int{}
+	for _, tags := range tagSets {
+		for _, t := range tags {
+			if t == nil {
+				continue
+			}
+			key := utility.FromStringPtr(t.Key)
+			tag := &ecs.Tag{Key: aws.String(key), Value: aws.String(utility.FromStringPtr(t.Value))}
+			if idx, ok := idxByKey[key]; ok {
+				merged[idx] = tag
+				continue
+			}
+			idxByKey[key] = len(merged)
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// MergeSecretTags combines the sets of Secrets Manager tags into a single set
+// of tags with no duplicate keys. If the same key appears in multiple tag sets,
+// the value from the last tag set takes precedence. Tags are returned in the
+// order that their keys first appear.
+func MergeSecretTags(tagSets ...[]*secretsmanager.Tag) []*secretsmanager.Tag {
+	var merged []*secretsmanager.Tag
+	idxByKey := map[string]int{}
+	for _, tags := range tagSets {
+		for _, t := range tags {
+			if t == nil {
+				continue
+			}
+			key := utility.FromStringPtr(t.Key)
+			tag := &secretsmanager.Tag{Key: aws.String(key), Value: aws.String(utility.FromStringPtr(t.Value))}
+			if idx, ok := idxByKey[key]; ok {
+				merged[idx] = tag
+				continue
+			}
+			idxByKey[key] = len(merged)
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
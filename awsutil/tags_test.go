@@ -0,0 +1,95 @@
+package awsutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandardTags(t *testing.T) {
+	t.Run("IncludesAllMetadata", func(t *testing.T) {
+		tags := StandardTags("owner", "prod", "component")
+		require.Len(t, tags, 4)
+		assert.Equal(t, OwnerTagKey, utility.FromStringPtr(tags[0].Key))
+		assert.Equal(t, "owner", utility.FromStringPtr(tags[0].Value))
+		assert.Equal(t, EnvironmentTagKey, utility.FromStringPtr(tags[1].Key))
+		assert.Equal(t, "prod", utility.FromStringPtr(tags[1].Value))
+		assert.Equal(t, ComponentTagKey, utility.FromStringPtr(tags[2].Key))
+		assert.Equal(t, "component", utility.FromStringPtr(tags[2].Value))
+		assert.Equal(t, CreatedAtTagKey, utility.FromStringPtr(tags[3].Key))
+		createdAt, err := time.Parse(time.RFC3339, utility.FromStringPtr(tags[3].Value))
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), createdAt, time.Minute)
+	})
+	t.Run("OmitsEmptyMetadata", func(t *testing.T) {
+		tags := StandardTags("", "prod", "")
+		require.Len(t, tags, 2)
+		assert.Equal(t, EnvironmentTagKey, utility.FromStringPtr(tags[0].Key))
+		assert.Equal(t, CreatedAtTagKey, utility.FromStringPtr(tags[1].Key))
+	})
+}
+
+func TestStandardSecretTags(t *testing.T) {
+	tags := StandardSecretTags("owner", "prod", "component")
+	require.Len(t, tags, 4)
+	for i, key := range []string{OwnerTagKey, EnvironmentTagKey, ComponentTagKey, CreatedAtTagKey} {
+		assert.Equal(t, key, utility.FromStringPtr(tags[i].Key))
+		assert.NotZero(t, utility.FromStringPtr(tags[i].Value))
+	}
+}
+
+func TestMergeECSTags(t *testing.T) {
+	t.Run("CombinesTagsWithoutDuplicates", func(t *testing.T) {
+		merged := MergeECSTags(
+			[]*ecs.Tag{
+				{Key: aws.String("a"), Value: aws.String("1")},
+				{Key: aws.String("b"), Value: aws.String("2")},
+			},
+			[]*ecs.Tag{
+				{Key: aws.String("b"), Value: aws.String("3")},
+				{Key: aws.String("c"), Value: aws.String("4")},
+				nil,
+			},
+		)
+		assert.Equal(t, []*ecs.Tag{
+			{Key: aws.String("a"), Value: aws.String("1")},
+			{Key: aws.String("b"), Value: aws.String("3")},
+			{Key: aws.String("c"), Value: aws.String("4")},
+		}, merged)
+	})
+	t.Run("DoesNotModifyInput", func(t *testing.T) {
+		base := []*ecs.Tag{{Key: aws.String("a"), Value: aws.String("1")}}
+		_ = MergeECSTags(base, []*ecs.Tag{{Key: aws.String("a"), Value: aws.String("2")}})
+		assert.Equal(t, "1", utility.FromStringPtr(base[0].Value))
+	})
+	t.Run("ReturnsNoTagsForNoInput", func(t *testing.T) {
+		assert.Empty(t, MergeECSTags())
+	})
+}
+
+func TestMergeSecretTags(t *testing.T) {
+	t.Run("CombinesTagsWithoutDuplicates", func(t *testing.T) {
+		merged := MergeSecretTags(
+			StandardSecretTags("owner", "", ""),
+			[]*secretsmanager.Tag{
+				{Key: aws.String(OwnerTagKey), Value: aws.String("other_owner")},
+				{Key: aws.String("key"), Value: aws.String("value")},
+			},
+		)
+		require.Len(t, merged, 3)
+		assert.Equal(t, OwnerTagKey, utility.FromStringPtr(merged[0].Key))
+		assert.Equal(t, "other_owner", utility.FromStringPtr(merged[0].Value))
+		assert.Equal(t, CreatedAtTagKey, utility.FromStringPtr(merged[1].Key))
+		assert.Equal(t, "key", utility.FromStringPtr(merged[2].Key))
+		assert.Equal(t, "value", utility.FromStringPtr(merged[2].Value))
+	})
+	t.Run("ReturnsNoTagsForNoInput", func(t *testing.T) {
+		assert.Empty(t, MergeSecretTags())
+	})
+}
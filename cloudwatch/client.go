@@ -0,0 +1,111 @@
+package cloudwatch
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+// BasicClient provides a cocoa.CloudWatchClient implementation that wraps the
+// AWS CloudWatch metrics API. It supports retrying requests using exponential
+// backoff and jitter.
+type BasicClient struct {
+	awsutil.BaseClient
+	cw *cloudwatch.CloudWatch
+}
+
+// NewBasicClient creates a new AWS CloudWatch client from the given options.
+func NewBasicClient(opts awsutil.ClientOptions) (*BasicClient, error) {
+	c := &BasicClient{
+		BaseClient: awsutil.NewBaseClient(opts),
+	}
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	return c, nil
+}
+
+func (c *BasicClient) setup() error {
+	if c.cw != nil {
+		return nil
+	}
+
+	sess, err := c.GetSession()
+	if err != nil {
+		return errors.Wrap(err, "initializing session")
+	}
+
+	c.cw = cloudwatch.New(sess)
+
+	return nil
+}
+
+// GetMetricStatistics gets statistics for a metric.
+func (c *BasicClient) GetMetricStatistics(ctx context.Context, in *cloudwatch.GetMetricStatisticsInput) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *cloudwatch.GetMetricStatisticsOutput
+	var err error
+	if err := utility.Retry(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("GetMetricStatistics", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.cw.GetMetricStatisticsWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutMetricData publishes metric data points.
+func (c *BasicClient) PutMetricData(ctx context.Context, in *cloudwatch.PutMetricDataInput) (*cloudwatch.PutMetricDataOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *cloudwatch.PutMetricDataOutput
+	var err error
+	if err := utility.Retry(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("PutMetricData", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.cw.PutMetricDataWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Close cleans up all resources owned by the client.
+func (c *BasicClient) Close(ctx context.Context) error {
+	return c.BaseClient.Close(ctx)
+}
+
+func (c *BasicClient) isNonRetryableErrorCode(code string) bool {
+	switch code {
+	case cloudwatch.ErrCodeInvalidParameterValueException,
+		cloudwatch.ErrCodeInvalidParameterCombinationException,
+		cloudwatch.ErrCodeMissingRequiredParameterException:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,12 @@
+package cloudwatch
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/cocoa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.CloudWatchClient)(nil), &BasicClient{})
+}
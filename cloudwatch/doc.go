@@ -0,0 +1,4 @@
+/*
+Package cloudwatch provides interfaces to interact with AWS CloudWatch.
+*/
+package cloudwatch
@@ -0,0 +1,87 @@
+package cloudwatch
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+// BasicLogsClient provides a cocoa.CloudWatchLogsClient implementation that
+// wraps the AWS CloudWatch Logs API. It supports retrying requests using
+// exponential backoff and jitter.
+type BasicLogsClient struct {
+	awsutil.BaseClient
+	cwl *cloudwatchlogs.CloudWatchLogs
+}
+
+// NewBasicLogsClient creates a new AWS CloudWatch Logs client from the given
+// options.
+func NewBasicLogsClient(opts awsutil.ClientOptions) (*BasicLogsClient, error) {
+	c := &BasicLogsClient{
+		BaseClient: awsutil.NewBaseClient(opts),
+	}
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	return c, nil
+}
+
+func (c *BasicLogsClient) setup() error {
+	if c.cwl != nil {
+		return nil
+	}
+
+	sess, err := c.GetSession()
+	if err != nil {
+		return errors.Wrap(err, "initializing session")
+	}
+
+	c.cwl = cloudwatchlogs.New(sess)
+
+	return nil
+}
+
+// GetLogEvents gets log events from a log stream.
+func (c *BasicLogsClient) GetLogEvents(ctx context.Context, in *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *cloudwatchlogs.GetLogEventsOutput
+	var err error
+	if err := utility.Retry(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("GetLogEvents", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.cwl.GetLogEventsWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Close cleans up all resources owned by the client.
+func (c *BasicLogsClient) Close(ctx context.Context) error {
+	return c.BaseClient.Close(ctx)
+}
+
+func (c *BasicLogsClient) isNonRetryableErrorCode(code string) bool {
+	switch code {
+	case cloudwatchlogs.ErrCodeInvalidParameterException,
+		cloudwatchlogs.ErrCodeResourceNotFoundException:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,64 @@
+package cloudwatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultTestTimeout is the standard timeout for tests against CloudWatch.
+const defaultTestTimeout = time.Minute
+
+func TestBasicLogsClientWithRecordedFixtures(t *testing.T) {
+	assert.Implements(t, (*cocoa.CloudWatchLogsClient)(nil), &BasicLogsClient{})
+
+	const (
+		logGroup  = "cocoa"
+		logStream = "prefix/container/0123456789abcdef0123456789abcdef"
+	)
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *BasicLogsClient){
+		"GetLogEvents": func(ctx context.Context, t *testing.T, c *BasicLogsClient) {
+			out, err := c.GetLogEvents(ctx, &cloudwatchlogs.GetLogEventsInput{
+				LogGroupName:  aws.String(logGroup),
+				LogStreamName: aws.String(logStream),
+				StartFromHead: aws.Bool(true),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			require.Len(t, out.Events, 2)
+			assert.Equal(t, "hello", utility.FromStringPtr(out.Events[0].Message))
+			assert.Equal(t, "world", utility.FromStringPtr(out.Events[1].Message))
+			assert.NotZero(t, utility.FromStringPtr(out.NextForwardToken))
+		},
+		"GetLogEventsFailsWithNonexistentLogStream": func(ctx context.Context, t *testing.T, c *BasicLogsClient) {
+			out, err := c.GetLogEvents(ctx, &cloudwatchlogs.GetLogEventsInput{
+				LogGroupName:  aws.String(logGroup),
+				LogStreamName: aws.String("nonexistent"),
+			})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+			defer cancel()
+
+			c, err := NewBasicLogsClient(testutil.RecordedAWSOptions(t, "testdata/fixtures"))
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, c.Close(ctx))
+			}()
+
+			tCase(ctx, t, c)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package cocoa
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+// CloudWatchClient provides a common interface to interact with a client
+// backed by AWS CloudWatch metrics. Implementations must handle retrying and
+// backoff.
+type CloudWatchClient interface {
+	// GetMetricStatistics gets statistics for a metric.
+	GetMetricStatistics(ctx context.Context, in *cloudwatch.GetMetricStatisticsInput) (*cloudwatch.GetMetricStatisticsOutput, error)
+	// PutMetricData publishes metric data points.
+	PutMetricData(ctx context.Context, in *cloudwatch.PutMetricDataInput) (*cloudwatch.PutMetricDataOutput, error)
+	// Close closes the client and cleans up its resources. Implementations
+	// should ensure that this is idempotent.
+	Close(ctx context.Context) error
+}
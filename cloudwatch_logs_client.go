@@ -0,0 +1,18 @@
+package cocoa
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+)
+
+// CloudWatchLogsClient provides a common interface to interact with a client
+// backed by AWS CloudWatch Logs. Implementations must handle retrying and
+// backoff.
+type CloudWatchLogsClient interface {
+	// GetLogEvents gets log events from a log stream.
+	GetLogEvents(ctx context.Context, in *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error)
+	// Close closes the client and cleans up its resources. Implementations
+	// should ensure that this is idempotent.
+	Close(ctx context.Context) error
+}
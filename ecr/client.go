@@ -0,0 +1,91 @@
+package ecr
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+// BasicClient provides a cocoa.ECRClient implementation that wraps the AWS
+// Elastic Container Registry API. It supports retrying requests using
+// exponential backoff and jitter.
+type BasicClient struct {
+	awsutil.BaseClient
+	ecr *ecr.ECR
+}
+
+// NewBasicClient creates a new AWS Elastic Container Registry client from the
+// given options.
+func NewBasicClient(opts awsutil.ClientOptions) (*BasicClient, error) {
+	c := &BasicClient{
+		BaseClient: awsutil.NewBaseClient(opts),
+	}
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	return c, nil
+}
+
+func (c *BasicClient) setup() error {
+	if c.ecr != nil {
+		return nil
+	}
+
+	sess, err := c.GetSession()
+	if err != nil {
+		return errors.Wrap(err, "initializing session")
+	}
+
+	c.ecr = ecr.New(sess)
+
+	return nil
+}
+
+// BatchGetImage gets information about images in a repository.
+func (c *BasicClient) BatchGetImage(ctx context.Context, in *ecr.BatchGetImageInput) (*ecr.BatchGetImageOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecr.BatchGetImageOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("BatchGetImage", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecr.BatchGetImageWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("BatchGetImage", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// Close cleans up all resources owned by the client.
+func (c *BasicClient) Close(ctx context.Context) error {
+	return c.BaseClient.Close(ctx)
+}
+
+func (c *BasicClient) isNonRetryableErrorCode(code string) bool {
+	switch code {
+	case "AccessDeniedException",
+		ecr.ErrCodeInvalidParameterException,
+		ecr.ErrCodeRepositoryNotFoundException,
+		request.InvalidParameterErrCode,
+		request.ParamRequiredErrCode:
+		return true
+	default:
+		return false
+	}
+}
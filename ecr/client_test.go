@@ -0,0 +1,52 @@
+package ecr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultTestTimeout is the standard timeout for tests against ECR.
+const defaultTestTimeout = time.Minute
+
+func TestBasicClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.ECRClient)(nil), &BasicClient{})
+}
+
+func TestBasicClientWithAWS(t *testing.T) {
+	testutil.CheckAWSEnvVarsForECR(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	hc := utility.GetHTTPClient()
+	defer utility.PutHTTPClient(hc)
+
+	c, err := NewBasicClient(testutil.ValidIntegrationAWSOptions(hc))
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, c.Close(ctx))
+	}()
+
+	t.Run("BatchGetImageReturnsFailureForNonexistentImage", func(t *testing.T) {
+		out, err := c.BatchGetImage(ctx, &ecr.BatchGetImageInput{
+			RepositoryName: aws.String(testutil.ECRRepository()),
+			ImageIds: []*ecr.ImageIdentifier{
+				{ImageTag: aws.String(utility.RandomString())},
+			},
+		})
+		require.NoError(t, err)
+		require.NotZero(t, out)
+		assert.Empty(t, out.Images)
+		require.Len(t, out.Failures, 1)
+		assert.Equal(t, ecr.ImageFailureCodeImageNotFound, utility.FromStringPtr(out.Failures[0].FailureCode))
+	})
+}
@@ -0,0 +1,4 @@
+/*
+Package ecr provides interfaces to interact with AWS Elastic Container Registry.
+*/
+package ecr
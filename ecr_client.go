@@ -0,0 +1,18 @@
+package cocoa
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/ecr"
+)
+
+// ECRClient provides a common interface to interact with a client backed by
+// AWS Elastic Container Registry. Implementations must handle retrying and
+// backoff.
+type ECRClient interface {
+	// BatchGetImage gets information about images in a repository.
+	BatchGetImage(ctx context.Context, in *ecr.BatchGetImageInput) (*ecr.BatchGetImageOutput, error)
+	// Close closes the client and cleans up its resources. Implementations
+	// should ensure that this is idempotent.
+	Close(ctx context.Context) error
+}
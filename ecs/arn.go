@@ -0,0 +1,113 @@
+package ecs
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/pkg/errors"
+)
+
+// TaskARN represents the parts of an ECS task ARN.
+type TaskARN struct {
+	// Region is the region that the task is in.
+	Region string
+	// AccountID is the ID of the AWS account that owns the task.
+	AccountID string
+	// Cluster is the name of the cluster that the task is in. This is empty
+	// for task ARNs in the old format, which do not include the cluster.
+	Cluster string
+	// TaskID is the unique ID of the task.
+	TaskID string
+}
+
+// ParseTaskARN parses an ECS task ARN. Task ARNs can be in either the old
+// format (arn:aws:ecs:<region>:<account ID>:task/<task ID>) or the new format
+// (arn:aws:ecs:<region>:<account ID>:task/<cluster>/<task ID>). The format of
+// new task ARNs depends on the account's ARN format (see
+// (*BasicClient).GetARNFormat).
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/ecs-account-settings.html#ecs-resource-ids
+func ParseTaskARN(taskARN string) (*TaskARN, error) {
+	parsed, err := arn.Parse(taskARN)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing ARN")
+	}
+	if parsed.Service != "ecs" {
+		return nil, errors.Errorf("ARN service is '%s', but should be 'ecs'", parsed.Service)
+	}
+
+	parts := strings.Split(parsed.Resource, "/")
+	if parts[0] != "task" {
+		return nil, errors.Errorf("ARN resource type is '%s', but should be 'task'", parts[0])
+	}
+
+	res := TaskARN{
+		Region:    parsed.Region,
+		AccountID: parsed.AccountID,
+	}
+	switch len(parts) {
+	case 2:
+		res.TaskID = parts[1]
+	case 3:
+		res.Cluster = parts[1]
+		res.TaskID = parts[2]
+	default:
+		return nil, errors.Errorf("ARN resource '%s' should be in the format 'task/<task ID>' or 'task/<cluster>/<task ID>'", parsed.Resource)
+	}
+	if res.TaskID == "" {
+		return nil, errors.New("ARN is missing the task ID")
+	}
+
+	return &res, nil
+}
+
+// TaskDefinitionARN represents the parts of an ECS task definition ARN.
+type TaskDefinitionARN struct {
+	// Region is the region that the task definition is in.
+	Region string
+	// AccountID is the ID of the AWS account that owns the task definition.
+	AccountID string
+	// Family is the name of the task definition family.
+	Family string
+	// Revision is the revision of the task definition within its family.
+	Revision int64
+}
+
+// ParseTaskDefinitionARN parses an ECS task definition ARN, which has the format
+// arn:aws:ecs:<region>:<account ID>:task-definition/<family>:<revision>. Unlike
+// task ARNs, task definition ARNs only have a single format, which is not
+// affected by the account's ARN format.
+func ParseTaskDefinitionARN(taskDefARN string) (*TaskDefinitionARN, error) {
+	parsed, err := arn.Parse(taskDefARN)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing ARN")
+	}
+	if parsed.Service != "ecs" {
+		return nil, errors.Errorf("ARN service is '%s', but should be 'ecs'", parsed.Service)
+	}
+
+	const resourcePrefix = "task-definition/"
+	if !strings.HasPrefix(parsed.Resource, resourcePrefix) {
+		return nil, errors.Errorf("ARN resource '%s' should start with '%s'", parsed.Resource, resourcePrefix)
+	}
+	familyAndRevision := strings.TrimPrefix(parsed.Resource, resourcePrefix)
+
+	revisionIdx := strings.LastIndex(familyAndRevision, ":")
+	if revisionIdx <= 0 {
+		return nil, errors.Errorf("ARN resource '%s' is missing the family or revision", parsed.Resource)
+	}
+	revision, err := strconv.ParseInt(familyAndRevision[revisionIdx+1:], 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing revision in ARN resource '%s'", parsed.Resource)
+	}
+	if revision <= 0 {
+		return nil, errors.Errorf("revision in ARN resource '%s' must be positive", parsed.Resource)
+	}
+
+	return &TaskDefinitionARN{
+		Region:    parsed.Region,
+		AccountID: parsed.AccountID,
+		Family:    familyAndRevision[:revisionIdx],
+		Revision:  revision,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskARN(t *testing.T) {
+	t.Run("ParsesNewFormat", func(t *testing.T) {
+		taskARN, err := ParseTaskARN("arn:aws:ecs:us-east-1:123456789012:task/cluster/0123456789abcdef")
+		require.NoError(t, err)
+		require.NotZero(t, taskARN)
+		assert.Equal(t, "us-east-1", taskARN.Region)
+		assert.Equal(t, "123456789012", taskARN.AccountID)
+		assert.Equal(t, "cluster", taskARN.Cluster)
+		assert.Equal(t, "0123456789abcdef", taskARN.TaskID)
+	})
+	t.Run("ParsesOldFormat", func(t *testing.T) {
+		taskARN, err := ParseTaskARN("arn:aws:ecs:us-east-1:123456789012:task/01234567-89ab-cdef-0123-456789abcdef")
+		require.NoError(t, err)
+		require.NotZero(t, taskARN)
+		assert.Equal(t, "us-east-1", taskARN.Region)
+		assert.Equal(t, "123456789012", taskARN.AccountID)
+		assert.Zero(t, taskARN.Cluster)
+		assert.Equal(t, "01234567-89ab-cdef-0123-456789abcdef", taskARN.TaskID)
+	})
+	t.Run("FailsWithInvalidARN", func(t *testing.T) {
+		taskARN, err := ParseTaskARN("0123456789abcdef")
+		assert.Error(t, err)
+		assert.Zero(t, taskARN)
+	})
+	t.Run("FailsWithNonECSARN", func(t *testing.T) {
+		taskARN, err := ParseTaskARN("arn:aws:secretsmanager:us-east-1:123456789012:secret:name")
+		assert.Error(t, err)
+		assert.Zero(t, taskARN)
+	})
+	t.Run("FailsWithNonTaskARN", func(t *testing.T) {
+		taskARN, err := ParseTaskARN("arn:aws:ecs:us-east-1:123456789012:task-definition/family:1")
+		assert.Error(t, err)
+		assert.Zero(t, taskARN)
+	})
+	t.Run("FailsWithTooManyResourceParts", func(t *testing.T) {
+		taskARN, err := ParseTaskARN("arn:aws:ecs:us-east-1:123456789012:task/cluster/0123456789abcdef/extra")
+		assert.Error(t, err)
+		assert.Zero(t, taskARN)
+	})
+	t.Run("FailsWithoutTaskID", func(t *testing.T) {
+		taskARN, err := ParseTaskARN("arn:aws:ecs:us-east-1:123456789012:task/cluster/")
+		assert.Error(t, err)
+		assert.Zero(t, taskARN)
+	})
+}
+
+func TestParseTaskDefinitionARN(t *testing.T) {
+	t.Run("Succeeds", func(t *testing.T) {
+		taskDefARN, err := ParseTaskDefinitionARN("arn:aws:ecs:us-east-1:123456789012:task-definition/family:12")
+		require.NoError(t, err)
+		require.NotZero(t, taskDefARN)
+		assert.Equal(t, "us-east-1", taskDefARN.Region)
+		assert.Equal(t, "123456789012", taskDefARN.AccountID)
+		assert.Equal(t, "family", taskDefARN.Family)
+		assert.EqualValues(t, 12, taskDefARN.Revision)
+	})
+	t.Run("FailsWithInvalidARN", func(t *testing.T) {
+		taskDefARN, err := ParseTaskDefinitionARN("family:1")
+		assert.Error(t, err)
+		assert.Zero(t, taskDefARN)
+	})
+	t.Run("FailsWithNonECSARN", func(t *testing.T) {
+		taskDefARN, err := ParseTaskDefinitionARN("arn:aws:secretsmanager:us-east-1:123456789012:secret:name")
+		assert.Error(t, err)
+		assert.Zero(t, taskDefARN)
+	})
+	t.Run("FailsWithNonTaskDefinitionARN", func(t *testing.T) {
+		taskDefARN, err := ParseTaskDefinitionARN("arn:aws:ecs:us-east-1:123456789012:task/cluster/0123456789abcdef")
+		assert.Error(t, err)
+		assert.Zero(t, taskDefARN)
+	})
+	t.Run("FailsWithoutRevision", func(t *testing.T) {
+		taskDefARN, err := ParseTaskDefinitionARN("arn:aws:ecs:us-east-1:123456789012:task-definition/family")
+		assert.Error(t, err)
+		assert.Zero(t, taskDefARN)
+	})
+	t.Run("FailsWithInvalidRevision", func(t *testing.T) {
+		for _, revision := range []string{"foo", "0", "-1", ""} {
+			taskDefARN, err := ParseTaskDefinitionARN("arn:aws:ecs:us-east-1:123456789012:task-definition/family:" + revision)
+			assert.Error(t, err, revision)
+			assert.Zero(t, taskDefARN, revision)
+		}
+	})
+	t.Run("FailsWithoutFamily", func(t *testing.T) {
+		taskDefARN, err := ParseTaskDefinitionARN("arn:aws:ecs:us-east-1:123456789012:task-definition/:1")
+		assert.Error(t, err)
+		assert.Zero(t, taskDefARN)
+	})
+}
@@ -0,0 +1,156 @@
+package ecs
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AttributeNameAvailabilityZone is the name of the built-in attribute for
+	// the availability zone of a container instance.
+	AttributeNameAvailabilityZone = "ecs.availability-zone"
+	// AttributeNameGPUType is the name of the custom attribute for the type of
+	// GPU available on a container instance. ECS does not set this attribute,
+	// so it must be put on the container instances that have GPUs.
+	AttributeNameGPUType = "gpu-type"
+	// attributeTargetTypeContainerInstance is the only type of resource that
+	// attributes can be put on.
+	attributeTargetTypeContainerInstance = "container-instance"
+	// maxAttributeLength is the maximum number of characters in an attribute
+	// name or value.
+	maxAttributeLength = 128
+)
+
+var (
+	// attributeNameRegexp matches valid attribute names.
+	attributeNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_./-]+$`)
+	// attributeValueRegexp matches valid attribute values.
+	attributeValueRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.@/\\: -]+$`)
+	// attributeExpressionValueRegexp matches attribute values that can be
+	// used in a cluster query language expression. The expression does not
+	// support quoting or escaping, so the value cannot contain whitespace,
+	// backslashes or colons.
+	attributeExpressionValueRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.@/-]+$`)
+)
+
+// AttributeBuilder builds an ECS attribute, which is a name-value pair on a
+// container instance that tasks can be placed by.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/task-placement-constraints.html#attributes
+type AttributeBuilder struct {
+	name       string
+	value      *string
+	targetType *string
+	targetID   *string
+}
+
+// NewAttributeBuilder returns a new builder for an attribute.
+func NewAttributeBuilder() *AttributeBuilder {
+	return &AttributeBuilder{}
+}
+
+// GPUAttribute returns a new builder for the custom attribute that indicates
+// the type of GPU available on a container instance.
+func GPUAttribute(gpuType string) *AttributeBuilder {
+	return NewAttributeBuilder().WithName(AttributeNameGPUType).WithValue(gpuType)
+}
+
+// AZAttribute returns a new builder for the built-in attribute that indicates
+// the availability zone of a container instance.
+func AZAttribute(az string) *AttributeBuilder {
+	return NewAttributeBuilder().WithName(AttributeNameAvailabilityZone).WithValue(az)
+}
+
+// WithName sets the name of the attribute.
+func (b *AttributeBuilder) WithName(name string) *AttributeBuilder {
+	b.name = name
+	return b
+}
+
+// WithValue sets the value of the attribute. If the value is not set, the
+// attribute only has a name.
+func (b *AttributeBuilder) WithValue(value string) *AttributeBuilder {
+	b.value = &value
+	return b
+}
+
+// WithTargetType sets the type of resource that the attribute applies to. The
+// only supported type is "container-instance".
+func (b *AttributeBuilder) WithTargetType(t string) *AttributeBuilder {
+	b.targetType = &t
+	return b
+}
+
+// WithTargetID sets the ID of the resource that the attribute applies to
+// (e.g. the container instance ARN).
+func (b *AttributeBuilder) WithTargetID(id string) *AttributeBuilder {
+	b.targetID = &id
+	return b
+}
+
+// Validate checks that the attribute has a valid name, a valid value if one is
+// set, and a supported target type if one is set.
+func (b *AttributeBuilder) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	catcher.NewWhen(b.name == "", "must specify an attribute name")
+	catcher.ErrorfWhen(len(b.name) > maxAttributeLength, "attribute name cannot exceed %d characters", maxAttributeLength)
+	catcher.ErrorfWhen(b.name != "" && !attributeNameRegexp.MatchString(b.name), "invalid attribute name '%s'", b.name)
+	if b.value != nil {
+		catcher.ErrorfWhen(len(*b.value) > maxAttributeLength, "attribute value cannot exceed %d characters", maxAttributeLength)
+		catcher.ErrorfWhen(!attributeValueRegexp.MatchString(*b.value), "invalid attribute value '%s'", *b.value)
+	}
+	catcher.ErrorfWhen(b.targetType != nil && *b.targetType != attributeTargetTypeContainerInstance, "invalid target type '%s', must be '%s'", aws.StringValue(b.targetType), attributeTargetTypeContainerInstance)
+	catcher.NewWhen(b.targetID != nil && *b.targetID == "", "target ID cannot be empty")
+	return catcher.Resolve()
+}
+
+// Build validates the attribute and returns it.
+func (b *AttributeBuilder) Build() (*ecs.Attribute, error) {
+	if err := b.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid attribute")
+	}
+	return &ecs.Attribute{
+		Name:       aws.String(b.name),
+		Value:      b.value,
+		TargetType: b.targetType,
+		TargetId:   b.targetID,
+	}, nil
+}
+
+// Expression returns the cluster query language expression that matches
+// container instances that have the attribute. If the attribute has a value,
+// the instance's attribute must have the same value; otherwise, the instance
+// only needs to have the attribute. Values that contain whitespace,
+// backslashes or colons are valid attribute values but cannot be matched by an
+// expression, so they return an error.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/cluster-query-language.html
+func (b *AttributeBuilder) Expression() (string, error) {
+	if err := b.Validate(); err != nil {
+		return "", errors.Wrap(err, "invalid attribute")
+	}
+	if b.value == nil {
+		return fmt.Sprintf("attribute:%s exists", b.name), nil
+	}
+	if !attributeExpressionValueRegexp.MatchString(*b.value) {
+		return "", errors.Errorf("attribute value '%s' cannot be used in an expression", *b.value)
+	}
+	return fmt.Sprintf("attribute:%s == %s", b.name, *b.value), nil
+}
+
+// BuildConstraint validates the attribute and returns a memberOf placement
+// constraint that places tasks only on container instances that have the
+// attribute.
+func (b *AttributeBuilder) BuildConstraint() (*ecs.PlacementConstraint, error) {
+	expr, err := b.Expression()
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.PlacementConstraint{
+		Type:       aws.String(ecs.PlacementConstraintTypeMemberOf),
+		Expression: aws.String(expr),
+	}, nil
+}
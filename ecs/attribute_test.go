@@ -0,0 +1,121 @@
+package ecs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributeBuilder(t *testing.T) {
+	t.Run("BuildsAttributeWithAllOptions", func(t *testing.T) {
+		attr, err := NewAttributeBuilder().
+			WithName("stack").
+			WithValue("production").
+			WithTargetType("container-instance").
+			WithTargetID("arn:aws:ecs:us-east-1:123456789012:container-instance/cluster/id").
+			Build()
+		require.NoError(t, err)
+		require.NotZero(t, attr)
+		assert.Equal(t, "stack", utility.FromStringPtr(attr.Name))
+		assert.Equal(t, "production", utility.FromStringPtr(attr.Value))
+		assert.Equal(t, "container-instance", utility.FromStringPtr(attr.TargetType))
+		assert.Equal(t, "arn:aws:ecs:us-east-1:123456789012:container-instance/cluster/id", utility.FromStringPtr(attr.TargetId))
+		assert.NoError(t, attr.Validate())
+	})
+	t.Run("BuildsAttributeWithOnlyName", func(t *testing.T) {
+		attr, err := NewAttributeBuilder().WithName("stack").Build()
+		require.NoError(t, err)
+		require.NotZero(t, attr)
+		assert.Equal(t, "stack", utility.FromStringPtr(attr.Name))
+		assert.Nil(t, attr.Value)
+		assert.Nil(t, attr.TargetType)
+		assert.Nil(t, attr.TargetId)
+	})
+	t.Run("BuildsGPUAttribute", func(t *testing.T) {
+		attr, err := GPUAttribute("nvidia-t4").Build()
+		require.NoError(t, err)
+		assert.Equal(t, AttributeNameGPUType, utility.FromStringPtr(attr.Name))
+		assert.Equal(t, "nvidia-t4", utility.FromStringPtr(attr.Value))
+	})
+	t.Run("BuildsAZAttribute", func(t *testing.T) {
+		attr, err := AZAttribute("us-east-1a").Build()
+		require.NoError(t, err)
+		assert.Equal(t, AttributeNameAvailabilityZone, utility.FromStringPtr(attr.Name))
+		assert.Equal(t, "us-east-1a", utility.FromStringPtr(attr.Value))
+	})
+	t.Run("FailsWithoutName", func(t *testing.T) {
+		attr, err := NewAttributeBuilder().WithValue("value").Build()
+		assert.Error(t, err)
+		assert.Zero(t, attr)
+	})
+	t.Run("FailsWithInvalidName", func(t *testing.T) {
+		attr, err := NewAttributeBuilder().WithName("invalid name").Build()
+		assert.Error(t, err)
+		assert.Zero(t, attr)
+	})
+	t.Run("FailsWithNameTooLong", func(t *testing.T) {
+		attr, err := NewAttributeBuilder().WithName(strings.Repeat("a", maxAttributeLength+1)).Build()
+		assert.Error(t, err)
+		assert.Zero(t, attr)
+	})
+	t.Run("FailsWithInvalidValue", func(t *testing.T) {
+		attr, err := NewAttributeBuilder().WithName("name").WithValue("invalid$value").Build()
+		assert.Error(t, err)
+		assert.Zero(t, attr)
+	})
+	t.Run("FailsWithEmptyValue", func(t *testing.T) {
+		attr, err := NewAttributeBuilder().WithName("name").WithValue("").Build()
+		assert.Error(t, err)
+		assert.Zero(t, attr)
+	})
+	t.Run("FailsWithInvalidTargetType", func(t *testing.T) {
+		attr, err := NewAttributeBuilder().WithName("name").WithTargetType("task").Build()
+		assert.Error(t, err)
+		assert.Zero(t, attr)
+	})
+	t.Run("FailsWithEmptyTargetID", func(t *testing.T) {
+		attr, err := NewAttributeBuilder().WithName("name").WithTargetID("").Build()
+		assert.Error(t, err)
+		assert.Zero(t, attr)
+	})
+	t.Run("ExpressionMatchesAttributeValue", func(t *testing.T) {
+		expr, err := AZAttribute("us-east-1a").Expression()
+		require.NoError(t, err)
+		assert.Equal(t, "attribute:ecs.availability-zone == us-east-1a", expr)
+	})
+	t.Run("ExpressionMatchesAttributeExistence", func(t *testing.T) {
+		expr, err := NewAttributeBuilder().WithName("gpu").Expression()
+		require.NoError(t, err)
+		assert.Equal(t, "attribute:gpu exists", expr)
+	})
+	t.Run("ExpressionFailsWithInvalidAttribute", func(t *testing.T) {
+		expr, err := NewAttributeBuilder().Expression()
+		assert.Error(t, err)
+		assert.Zero(t, expr)
+	})
+	t.Run("ExpressionFailsWithValueThatCannotBeMatched", func(t *testing.T) {
+		for _, value := range []string{"a b", `a\b`, "a:b"} {
+			b := NewAttributeBuilder().WithName("name").WithValue(value)
+			require.NoError(t, b.Validate())
+			expr, err := b.Expression()
+			assert.Error(t, err, value)
+			assert.Zero(t, expr)
+		}
+	})
+	t.Run("BuildsMemberOfConstraint", func(t *testing.T) {
+		constraint, err := GPUAttribute("nvidia-t4").BuildConstraint()
+		require.NoError(t, err)
+		require.NotZero(t, constraint)
+		assert.Equal(t, ecs.PlacementConstraintTypeMemberOf, utility.FromStringPtr(constraint.Type))
+		assert.Equal(t, "attribute:gpu-type == nvidia-t4", utility.FromStringPtr(constraint.Expression))
+	})
+	t.Run("BuildConstraintFailsWithInvalidAttribute", func(t *testing.T) {
+		constraint, err := NewAttributeBuilder().BuildConstraint()
+		assert.Error(t, err)
+		assert.Zero(t, constraint)
+	})
+}
@@ -0,0 +1,227 @@
+package ecs
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultCanaryStartTimeout is the default maximum amount of time to wait
+	// for the canary tasks to start running.
+	defaultCanaryStartTimeout = 5 * time.Minute
+	// canaryStopTimeout is the maximum amount of time to spend stopping the
+	// canary tasks.
+	canaryStopTimeout = time.Minute
+	// canaryStartedBy is the value of the StartedBy field for canary tasks.
+	canaryStartedBy = "cocoa-canary"
+)
+
+// CanaryDeploymentOptions represent options to deploy a new task definition to
+// a service using canary tasks.
+type CanaryDeploymentOptions struct {
+	// Cluster is the name of the cluster that the service runs in.
+	Cluster string
+	// Service is the name of the service to update.
+	Service string
+	// TaskDefinition is the ARN or family and revision of the new task
+	// definition to deploy.
+	TaskDefinition string
+	// CanaryCount is the number of canary tasks to run alongside the service.
+	// By default, it runs 1 canary task. It can run at most 10 canary tasks.
+	CanaryCount int
+	// RunTaskTemplate is the input used to run the canary tasks, such as their
+	// capacity provider strategy or network configuration. Its cluster, task
+	// definition and count are replaced by the options. If it is not set, the
+	// canary tasks run with the cluster's defaults.
+	RunTaskTemplate *ecs.RunTaskInput
+	// HealthCheck is an optional smoke test that runs once all the canary
+	// tasks are running. If it returns an error, the deployment is rolled
+	// back.
+	HealthCheck func(context.Context) error
+	// StartTimeout is the maximum amount of time to wait for the canary tasks
+	// to start running. By default, it is 5 minutes.
+	StartTimeout time.Duration
+}
+
+// Validate checks that the required options are given and sets defaults for
+// unspecified options.
+func (o *CanaryDeploymentOptions) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	catcher.NewWhen(o.Cluster == "", "must specify a cluster")
+	catcher.NewWhen(o.Service == "", "must specify a service")
+	catcher.NewWhen(o.TaskDefinition == "", "must specify a task definition")
+	catcher.ErrorfWhen(o.CanaryCount < 0 || o.CanaryCount > maxRunTaskCount, "canary count must be between 0 and %d", maxRunTaskCount)
+	catcher.NewWhen(o.StartTimeout < 0, "start timeout cannot be negative")
+	if catcher.HasErrors() {
+		return catcher.Resolve()
+	}
+
+	if o.CanaryCount == 0 {
+		o.CanaryCount = 1
+	}
+	if o.StartTimeout == 0 {
+		o.StartTimeout = defaultCanaryStartTimeout
+	}
+
+	return nil
+}
+
+// CanaryDeployment deploys a new task definition to an ECS service by first
+// running canary tasks with the new task definition alongside the service.
+type CanaryDeployment struct {
+	client       cocoa.ECSClient
+	pollInterval time.Duration
+}
+
+// NewCanaryDeployment returns a new canary deployment that uses the given
+// client.
+func NewCanaryDeployment(c cocoa.ECSClient) (*CanaryDeployment, error) {
+	if c == nil {
+		return nil, errors.New("missing client")
+	}
+	return &CanaryDeployment{
+		client:       c,
+		pollInterval: defaultTaskPollInterval,
+	}, nil
+}
+
+// SetPollInterval sets the interval between checks of whether the canary
+// tasks are running. By default, it is 1 second.
+func (d *CanaryDeployment) SetPollInterval(interval time.Duration) *CanaryDeployment {
+	if interval > 0 {
+		d.pollInterval = interval
+	}
+	return d
+}
+
+// Deploy runs the canary tasks with the new task definition, waits for them to
+// start running, and runs the health check if there is one. If the canary
+// tasks are healthy, it updates the service to use the new task definition.
+// Otherwise, it rolls back by stopping the canary tasks and leaves the service
+// unchanged. In both cases, the canary tasks are stopped once the deployment
+// finishes, since they are not managed by the service.
+func (d *CanaryDeployment) Deploy(ctx context.Context, opts CanaryDeploymentOptions) error {
+	if err := opts.Validate(); err != nil {
+		return errors.Wrap(err, "invalid canary deployment options")
+	}
+
+	taskARNs, err := d.runCanaries(ctx, opts)
+	if err != nil {
+		if stopErr := d.stopCanaries(opts.Cluster, taskARNs, "canary tasks failed to start"); stopErr != nil {
+			return errors.Wrapf(stopErr, "rolling back after failing to run canary tasks: %s", err.Error())
+		}
+		return errors.Wrap(err, "running canary tasks")
+	}
+
+	if err := d.checkCanaries(ctx, opts, taskARNs); err != nil {
+		if stopErr := d.stopCanaries(opts.Cluster, taskARNs, "canary deployment rolled back"); stopErr != nil {
+			return errors.Wrapf(stopErr, "rolling back after unhealthy canary tasks: %s", err.Error())
+		}
+		return errors.Wrap(err, "canary tasks are unhealthy, rolled back")
+	}
+
+	if _, err := d.client.UpdateService(ctx, &ecs.UpdateServiceInput{
+		Cluster:        aws.String(opts.Cluster),
+		Service:        aws.String(opts.Service),
+		TaskDefinition: aws.String(opts.TaskDefinition),
+	}); err != nil {
+		if stopErr := d.stopCanaries(opts.Cluster, taskARNs, "service update failed"); stopErr != nil {
+			return errors.Wrapf(stopErr, "stopping canary tasks after failing to update service: %s", err.Error())
+		}
+		return errors.Wrapf(err, "updating service '%s'", opts.Service)
+	}
+
+	return errors.Wrap(d.stopCanaries(opts.Cluster, taskARNs, "canary deployment succeeded"), "stopping canary tasks")
+}
+
+// runCanaries runs the canary tasks and returns the ARNs of the tasks that
+// started. If it returns an error, the ARNs are those of the tasks that
+// started before the error occurred.
+func (d *CanaryDeployment) runCanaries(ctx context.Context, opts CanaryDeploymentOptions) ([]string, error) {
+	var in ecs.RunTaskInput
+	if opts.RunTaskTemplate != nil {
+		in = *opts.RunTaskTemplate
+	}
+	in.SetCluster(opts.Cluster).
+		SetTaskDefinition(opts.TaskDefinition).
+		SetCount(int64(opts.CanaryCount))
+	if in.StartedBy == nil {
+		in.SetStartedBy(canaryStartedBy)
+	}
+
+	out, err := d.client.RunTask(ctx, &in)
+	if err != nil {
+		return nil, err
+	}
+
+	var taskARNs []string
+	for _, task := range out.Tasks {
+		if task != nil && task.TaskArn != nil {
+			taskARNs = append(taskARNs, *task.TaskArn)
+		}
+	}
+	for _, f := range out.Failures {
+		if f != nil {
+			return taskARNs, ConvertFailureToError(f)
+		}
+	}
+	if len(taskARNs) != opts.CanaryCount {
+		return taskARNs, errors.Errorf("expected %d canary tasks to start, but %d started", opts.CanaryCount, len(taskARNs))
+	}
+
+	return taskARNs, nil
+}
+
+// checkCanaries waits for the canary tasks to be running and then runs the
+// health check, if any.
+func (d *CanaryDeployment) checkCanaries(ctx context.Context, opts CanaryDeploymentOptions, taskARNs []string) error {
+	waitCtx, cancel := context.WithTimeout(ctx, opts.StartTimeout)
+	defer cancel()
+
+	for _, arn := range taskARNs {
+		task, err := WaitForTaskStatus(waitCtx, d.client, opts.Cluster, arn, func(task *ecs.Task) bool {
+			status := TaskStatus(utility.FromStringPtr(task.LastStatus))
+			return status == TaskStatusRunning || status == TaskStatusStopped
+		}, d.pollInterval)
+		if err != nil {
+			return errors.Wrapf(err, "waiting for canary task '%s' to run", arn)
+		}
+		if TaskStatus(utility.FromStringPtr(task.LastStatus)) == TaskStatusStopped {
+			return errors.Errorf("canary task '%s' stopped before it was running: %s", arn, ExtractStopReason(task))
+		}
+	}
+
+	if opts.HealthCheck != nil {
+		if err := opts.HealthCheck(ctx); err != nil {
+			return errors.Wrap(err, "running health check")
+		}
+	}
+
+	return nil
+}
+
+// stopCanaries stops all the canary tasks. The tasks are stopped with a
+// separate context, since they must be stopped even if the deployment's context
+// is done.
+func (d *CanaryDeployment) stopCanaries(cluster string, taskARNs []string, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), canaryStopTimeout)
+	defer cancel()
+
+	var errs cocoa.MultiError
+	for _, arn := range taskARNs {
+		_, err := d.client.StopTask(ctx, &ecs.StopTaskInput{
+			Cluster: aws.String(cluster),
+			Task:    aws.String(arn),
+			Reason:  aws.String(reason),
+		})
+		errs.Wrapf(err, "stopping canary task '%s'", arn)
+	}
+	return errs.Resolve()
+}
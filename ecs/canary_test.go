@@ -0,0 +1,61 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanaryDeploymentOptions(t *testing.T) {
+	t.Run("SetsDefaults", func(t *testing.T) {
+		opts := CanaryDeploymentOptions{
+			Cluster:        "cluster",
+			Service:        "service",
+			TaskDefinition: "family:1",
+		}
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, 1, opts.CanaryCount)
+		assert.Equal(t, defaultCanaryStartTimeout, opts.StartTimeout)
+	})
+	t.Run("PreservesExplicitValues", func(t *testing.T) {
+		opts := CanaryDeploymentOptions{
+			Cluster:        "cluster",
+			Service:        "service",
+			TaskDefinition: "family:1",
+			CanaryCount:    3,
+			StartTimeout:   time.Minute,
+		}
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, 3, opts.CanaryCount)
+		assert.Equal(t, time.Minute, opts.StartTimeout)
+	})
+	t.Run("FailsWithoutRequiredFields", func(t *testing.T) {
+		opts := CanaryDeploymentOptions{}
+		assert.Error(t, opts.Validate())
+	})
+	t.Run("FailsWithTooManyCanaries", func(t *testing.T) {
+		opts := CanaryDeploymentOptions{
+			Cluster:        "cluster",
+			Service:        "service",
+			TaskDefinition: "family:1",
+			CanaryCount:    maxRunTaskCount + 1,
+		}
+		assert.Error(t, opts.Validate())
+	})
+	t.Run("FailsWithNegativeStartTimeout", func(t *testing.T) {
+		opts := CanaryDeploymentOptions{
+			Cluster:        "cluster",
+			Service:        "service",
+			TaskDefinition: "family:1",
+			StartTimeout:   -time.Second,
+		}
+		assert.Error(t, opts.Validate())
+	})
+}
+
+func TestNewCanaryDeployment(t *testing.T) {
+	_, err := NewCanaryDeployment(nil)
+	assert.Error(t, err)
+}
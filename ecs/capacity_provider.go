@@ -0,0 +1,294 @@
+package ecs
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultScalingActivityPollInterval is the default interval between checks
+	// of an Auto Scaling group's scaling activities.
+	defaultScalingActivityPollInterval = 30 * time.Second
+	// maxScalingActivityRecords is the number of most recent scaling
+	// activities to check on each poll.
+	maxScalingActivityRecords = 20
+	// asgNameResourcePrefix is the prefix in the resource section of an Auto
+	// Scaling group ARN that precedes the group name.
+	asgNameResourcePrefix = "autoScalingGroupName/"
+)
+
+// ScalingActivityType describes the kind of scaling action that an Auto Scaling
+// group performed.
+type ScalingActivityType string
+
+const (
+	// ScalingActivityScaleOut indicates that the Auto Scaling group is
+	// launching an instance.
+	ScalingActivityScaleOut ScalingActivityType = "scale-out"
+	// ScalingActivityScaleIn indicates that the Auto Scaling group is
+	// terminating an instance.
+	ScalingActivityScaleIn ScalingActivityType = "scale-in"
+	// ScalingActivityOther indicates any other kind of scaling activity.
+	ScalingActivityOther ScalingActivityType = "other"
+)
+
+// ScalingActivityEvent represents a new scaling activity or a change in the
+// status of a scaling activity for a capacity provider's Auto Scaling group.
+type ScalingActivityEvent struct {
+	// Type is the kind of scaling action.
+	Type ScalingActivityType
+	// AutoScalingGroupName is the name of the Auto Scaling group backing the
+	// capacity provider.
+	AutoScalingGroupName string
+	// ActivityID is the ID of the scaling activity.
+	ActivityID string
+	// StatusCode is the current status of the scaling activity (e.g.
+	// InProgress, Successful, Failed).
+	StatusCode string
+	// StatusMessage is a message explaining the status, if any. This is
+	// typically only set if the activity failed.
+	StatusMessage string
+	// Description is a summary of the scaling activity.
+	Description string
+	// Cause is the reason that the scaling activity happened.
+	Cause string
+	// Progress is a percentage indicating how close the scaling activity is to
+	// completion.
+	Progress int64
+	// StartTime is the time at which the scaling activity started.
+	StartTime time.Time
+	// EndTime is the time at which the scaling activity finished. This is zero
+	// if it has not finished.
+	EndTime time.Time
+	// Timestamp is the time at which the event was observed.
+	Timestamp time.Time
+	// Err is set if the scaling activities could not be checked. If it is set,
+	// this is the last event sent.
+	Err error
+}
+
+// PollCapacityProviderScalingActivity starts polling the scaling activities of
+// the Auto Scaling group that backs the capacity provider and returns a channel
+// that receives an event each time a scaling activity starts or its status
+// changes. This can help explain why tasks that use the capacity provider are
+// stuck provisioning while the Auto Scaling group adds instances. Activities
+// that already finished before polling starts are not reported. The channel is
+// closed once the context is done or the scaling activities cannot be checked.
+// If the poll interval is not positive, it defaults to 30 seconds.
+func PollCapacityProviderScalingActivity(ctx context.Context, c cocoa.ECSClient, asc cocoa.AutoScalingClient, provider string, pollInterval time.Duration) (<-chan ScalingActivityEvent, error) {
+	if c == nil {
+		return nil, errors.New("missing ECS client")
+	}
+	if asc == nil {
+		return nil, errors.New("missing Auto Scaling client")
+	}
+	if provider == "" {
+		return nil, errors.New("must specify a capacity provider")
+	}
+	if pollInterval <= 0 {
+		pollInterval = defaultScalingActivityPollInterval
+	}
+
+	group, err := getCapacityProviderAutoScalingGroup(ctx, c, provider)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting Auto Scaling group for capacity provider '%s'", provider)
+	}
+
+	activities, err := getScalingActivities(ctx, asc, group)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting initial scaling activities")
+	}
+
+	seen := map[string]string{}
+	var initial []*autoscaling.Activity
+	for _, a := range activities {
+		seen[utility.FromStringPtr(a.ActivityId)] = utility.FromStringPtr(a.StatusCode)
+		if !isScalingActivityFinished(a) {
+			initial = append(initial, a)
+		}
+	}
+
+	events := make(chan ScalingActivityEvent)
+	go func() {
+		defer close(events)
+
+		send := func(a *autoscaling.Activity) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case events <- newScalingActivityEvent(group, a):
+				return true
+			}
+		}
+
+		for i := len(initial) - 1; i >= 0; i-- {
+			if !send(initial[i]) {
+				return
+			}
+		}
+
+		timer := time.NewTimer(pollInterval)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				activities, err := getScalingActivities(ctx, asc, group)
+				if err != nil {
+					select {
+					case <-ctx.Done():
+					case events <- ScalingActivityEvent{
+						AutoScalingGroupName: group,
+						Timestamp:            time.Now(),
+						Err:                  errors.Wrap(err, "checking scaling activities"),
+					}:
+					}
+					return
+				}
+
+				// Activities are returned from most to least recent, so send
+				// them in reverse to report them in the order they happened.
+				for i := len(activities) - 1; i >= 0; i-- {
+					a := activities[i]
+					id := utility.FromStringPtr(a.ActivityId)
+					status := utility.FromStringPtr(a.StatusCode)
+					if prevStatus, ok := seen[id]; ok && prevStatus == status {
+						continue
+					}
+					seen[id] = status
+					if !send(a) {
+						return
+					}
+				}
+
+				timer.Reset(pollInterval)
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// getCapacityProviderAutoScalingGroup gets the name of the Auto Scaling group
+// that backs the capacity provider.
+func getCapacityProviderAutoScalingGroup(ctx context.Context, c cocoa.ECSClient, provider string) (string, error) {
+	out, err := c.DescribeCapacityProviders(ctx, &ecs.DescribeCapacityProvidersInput{
+		CapacityProviders: []*string{aws.String(provider)},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "describing capacity provider")
+	}
+	if len(out.Failures) > 0 && out.Failures[0] != nil {
+		return "", ConvertFailureToError(out.Failures[0])
+	}
+	if len(out.CapacityProviders) == 0 || out.CapacityProviders[0] == nil {
+		return "", errors.Errorf("capacity provider '%s' was not returned in the response", provider)
+	}
+
+	asgProvider := out.CapacityProviders[0].AutoScalingGroupProvider
+	if asgProvider == nil || utility.FromStringPtr(asgProvider.AutoScalingGroupArn) == "" {
+		return "", errors.New("capacity provider is not backed by an Auto Scaling group")
+	}
+
+	return parseAutoScalingGroupName(utility.FromStringPtr(asgProvider.AutoScalingGroupArn))
+}
+
+// parseAutoScalingGroupName returns the Auto Scaling group name from the Auto
+// Scaling group ARN, which has the format
+// arn:aws:autoscaling:<region>:<account>:autoScalingGroup:<uuid>:autoScalingGroupName/<name>.
+// If the identifier is not an ARN, it is assumed to already be the name.
+func parseAutoScalingGroupName(id string) (string, error) {
+	if !arn.IsARN(id) {
+		return id, nil
+	}
+
+	parsed, err := arn.Parse(id)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing Auto Scaling group ARN '%s'", id)
+	}
+	idx := strings.Index(parsed.Resource, asgNameResourcePrefix)
+	if idx == -1 {
+		return "", errors.Errorf("Auto Scaling group ARN '%s' does not contain the group name", id)
+	}
+	name := parsed.Resource[idx+len(asgNameResourcePrefix):]
+	if name == "" {
+		return "", errors.Errorf("Auto Scaling group ARN '%s' has an empty group name", id)
+	}
+
+	return name, nil
+}
+
+// getScalingActivities gets the most recent scaling activities for the Auto
+// Scaling group.
+func getScalingActivities(ctx context.Context, asc cocoa.AutoScalingClient, group string) ([]*autoscaling.Activity, error) {
+	out, err := asc.DescribeScalingActivities(ctx, &autoscaling.DescribeScalingActivitiesInput{
+		AutoScalingGroupName: aws.String(group),
+		MaxRecords:           aws.Int64(maxScalingActivityRecords),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describing scaling activities")
+	}
+
+	var activities []*autoscaling.Activity
+	for _, a := range out.Activities {
+		if a == nil || a.ActivityId == nil {
+			continue
+		}
+		activities = append(activities, a)
+	}
+	return activities, nil
+}
+
+// isScalingActivityFinished returns whether or not the scaling activity has
+// reached a terminal status.
+func isScalingActivityFinished(a *autoscaling.Activity) bool {
+	switch utility.FromStringPtr(a.StatusCode) {
+	case autoscaling.ScalingActivityStatusCodeSuccessful,
+		autoscaling.ScalingActivityStatusCodeFailed,
+		autoscaling.ScalingActivityStatusCodeCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// scalingActivityType infers the kind of scaling action from the scaling
+// activity's description, since Auto Scaling does not report it directly.
+func scalingActivityType(a *autoscaling.Activity) ScalingActivityType {
+	description := utility.FromStringPtr(a.Description)
+	switch {
+	case strings.HasPrefix(description, "Launching"):
+		return ScalingActivityScaleOut
+	case strings.HasPrefix(description, "Terminating"):
+		return ScalingActivityScaleIn
+	default:
+		return ScalingActivityOther
+	}
+}
+
+func newScalingActivityEvent(group string, a *autoscaling.Activity) ScalingActivityEvent {
+	return ScalingActivityEvent{
+		Type:                 scalingActivityType(a),
+		AutoScalingGroupName: group,
+		ActivityID:           utility.FromStringPtr(a.ActivityId),
+		StatusCode:           utility.FromStringPtr(a.StatusCode),
+		StatusMessage:        utility.FromStringPtr(a.StatusMessage),
+		Description:          utility.FromStringPtr(a.Description),
+		Cause:                utility.FromStringPtr(a.Cause),
+		Progress:             utility.FromInt64Ptr(a.Progress),
+		StartTime:            utility.FromTimePtr(a.StartTime),
+		EndTime:              utility.FromTimePtr(a.EndTime),
+		Timestamp:            time.Now(),
+	}
+}
@@ -0,0 +1,31 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAutoScalingGroupName(t *testing.T) {
+	t.Run("ParsesNameFromARN", func(t *testing.T) {
+		name, err := parseAutoScalingGroupName("arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:01234567-89ab-cdef-0123-456789abcdef:autoScalingGroupName/group")
+		require.NoError(t, err)
+		assert.Equal(t, "group", name)
+	})
+	t.Run("ReturnsNonARNAsName", func(t *testing.T) {
+		name, err := parseAutoScalingGroupName("group")
+		require.NoError(t, err)
+		assert.Equal(t, "group", name)
+	})
+	t.Run("FailsWithARNMissingGroupName", func(t *testing.T) {
+		name, err := parseAutoScalingGroupName("arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:01234567-89ab-cdef-0123-456789abcdef")
+		assert.Error(t, err)
+		assert.Zero(t, name)
+	})
+	t.Run("FailsWithEmptyGroupName", func(t *testing.T) {
+		name, err := parseAutoScalingGroupName("arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:01234567-89ab-cdef-0123-456789abcdef:autoScalingGroupName/")
+		assert.Error(t, err)
+		assert.Zero(t, name)
+	})
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ecs"
@@ -18,11 +21,27 @@ import (
 
 // BasicClient provides a cocoa.ECSClient implementation that wraps the AWS
 // ECS API. It supports retrying requests using exponential backoff and jitter.
+// Once created, its ECS API methods are safe for concurrent use. Its Set
+// methods are not, so they should only be called before the client is used
+// concurrently.
 type BasicClient struct {
 	awsutil.BaseClient
-	ecs *ecs.ECS
+	ecs                 *ecs.ECS
+	tagConcurrency      int
+	describeConcurrency int
+	// allowedCostValues maps each cost attribution tag key to its allowed
+	// values.
+	allowedCostValues map[string][]string
 }
 
+// defaultTagConcurrency is the default maximum number of concurrent requests
+// to tag resources when tagging multiple resources.
+const defaultTagConcurrency = 10
+
+// defaultDescribeConcurrency is the default maximum number of concurrent
+// requests to describe tasks when describing tasks in batches.
+const defaultDescribeConcurrency = 10
+
 // NewBasicClient creates a new AWS ECS client from the given options.
 func NewBasicClient(opts awsutil.ClientOptions) (*BasicClient, error) {
 	c := &BasicClient{
@@ -50,25 +69,30 @@ func (c *BasicClient) setup() error {
 	return nil
 }
 
-// RegisterTaskDefinition registers a new task definition.
+// RegisterTaskDefinition validates the input and registers a new task
+// definition.
 func (c *BasicClient) RegisterTaskDefinition(ctx context.Context, in *ecs.RegisterTaskDefinitionInput) (*ecs.RegisterTaskDefinitionOutput, error) {
+	if err := ValidateRegisterTaskDefinitionInput(in); err != nil {
+		return nil, errors.Wrap(err, "invalid input")
+	}
 	if err := c.setup(); err != nil {
 		return nil, errors.Wrap(err, "setting up client")
 	}
 
 	var out *ecs.RegisterTaskDefinitionOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("RegisterTaskDefinition", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("RegisterTaskDefinition", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.ecs.RegisterTaskDefinitionWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("RegisterTaskDefinition", stats)))
 		return nil, err
 	}
 
@@ -83,22 +107,37 @@ func (c *BasicClient) DescribeTaskDefinition(ctx context.Context, in *ecs.Descri
 
 	var out *ecs.DescribeTaskDefinitionOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("DescribeTaskDefinition", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DescribeTaskDefinition", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.ecs.DescribeTaskDefinitionWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DescribeTaskDefinition", stats)))
 		return nil, err
 	}
 	return out, nil
 }
 
+// DescribeTaskDefinitionWithTags returns information about the task definition
+// given by its family, family and revision, or ARN, including its tags. ECS
+// only returns the tags when they are explicitly requested, so this avoids a
+// separate call to list the task definition's tags.
+func (c *BasicClient) DescribeTaskDefinitionWithTags(ctx context.Context, familyOrARN string) (*ecs.DescribeTaskDefinitionOutput, error) {
+	if familyOrARN == "" {
+		return nil, errors.New("must specify a task definition")
+	}
+	return c.DescribeTaskDefinition(ctx, &ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(familyOrARN),
+		Include:        aws.StringSlice([]string{ecs.TaskDefinitionFieldTags}),
+	})
+}
+
 // ListTaskDefinitions returns the ARNs for the task definitions that match the
 // input filters.
 func (c *BasicClient) ListTaskDefinitions(ctx context.Context, in *ecs.ListTaskDefinitionsInput) (*ecs.ListTaskDefinitionsOutput, error) {
@@ -108,17 +147,18 @@ func (c *BasicClient) ListTaskDefinitions(ctx context.Context, in *ecs.ListTaskD
 
 	var out *ecs.ListTaskDefinitionsOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("ListTaskDefinitions", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("ListTaskDefinitions", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.ecs.ListTaskDefinitionsWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("ListTaskDefinitions", stats)))
 		return nil, err
 	}
 	return out, nil
@@ -132,17 +172,18 @@ func (c *BasicClient) DeregisterTaskDefinition(ctx context.Context, in *ecs.Dere
 
 	var out *ecs.DeregisterTaskDefinitionOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("DeregisterTaskDefinition", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DeregisterTaskDefinition", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.ecs.DeregisterTaskDefinitionWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DeregisterTaskDefinition", stats)))
 		return nil, err
 	}
 
@@ -157,11 +198,11 @@ func (c *BasicClient) RunTask(ctx context.Context, in *ecs.RunTaskInput) (*ecs.R
 
 	var out *ecs.RunTaskOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("RunTask", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("RunTask", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.ecs.RunTaskWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if strings.Contains(awsErr.Error(), "provisioning capacity limit exceeded") {
 				// The ECS cluster has exceeded its maximum limit for number of
 				// tasks in the PROVISIONING state. This is a service-side issue
@@ -189,7 +230,7 @@ func (c *BasicClient) RunTask(ctx context.Context, in *ecs.RunTaskInput) (*ecs.R
 				if f == nil {
 					continue
 				}
-				if utility.StringSliceContains([]string{"RESOURCE:CPU", "RESOURCE:MEMORY"}, utility.FromStringPtr(f.Reason)) {
+				if category := categorizePlacementFailure(f); category == PlacementFailureInsufficientCPU || category == PlacementFailureInsufficientMemory {
 					catcher.Add(ConvertFailureToError(f))
 				}
 			}
@@ -198,6 +239,7 @@ func (c *BasicClient) RunTask(ctx context.Context, in *ecs.RunTaskInput) (*ecs.R
 
 		return false, nil
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("RunTask", stats)))
 		return nil, err
 	}
 
@@ -212,17 +254,18 @@ func (c *BasicClient) DescribeTasks(ctx context.Context, in *ecs.DescribeTasksIn
 
 	var out *ecs.DescribeTasksOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("DescribeTasks", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DescribeTasks", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.ecs.DescribeTasksWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DescribeTasks", stats)))
 		return nil, err
 	}
 	return out, nil
@@ -236,17 +279,18 @@ func (c *BasicClient) ListTasks(ctx context.Context, in *ecs.ListTasksInput) (*e
 
 	var out *ecs.ListTasksOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("ListTasks", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("ListTasks", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.ecs.ListTasksWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("ListTasks", stats)))
 		return nil, err
 	}
 	return out, nil
@@ -260,13 +304,13 @@ func (c *BasicClient) StopTask(ctx context.Context, in *ecs.StopTaskInput) (*ecs
 
 	var out *ecs.StopTaskOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("StopTask", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("StopTask", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.ecs.StopTaskWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if isTaskNotFoundError(awsErr) {
-				return false, cocoa.NewECSTaskNotFoundError(utility.FromStringPtr(in.Task))
+				return false, cocoa.NewECSTaskNotFoundError(utility.FromStringPtr(in.Task), awsErr)
 			}
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
@@ -274,6 +318,7 @@ func (c *BasicClient) StopTask(ctx context.Context, in *ecs.StopTaskInput) (*ecs
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("StopTask", stats)))
 		return nil, err
 	}
 	return out, nil
@@ -287,22 +332,731 @@ func (c *BasicClient) TagResource(ctx context.Context, in *ecs.TagResourceInput)
 
 	var out *ecs.TagResourceOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("TagResource", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("TagResource", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.ecs.TagResourceWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("TagResource", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListContainerInstances lists all ECS container instances matching the input.
+func (c *BasicClient) ListContainerInstances(ctx context.Context, in *ecs.ListContainerInstancesInput) (*ecs.ListContainerInstancesOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.ListContainerInstancesOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("ListContainerInstances", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.ListContainerInstancesWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("ListContainerInstances", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// DescribeContainerInstances gets information about the configuration and
+// status of container instances.
+func (c *BasicClient) DescribeContainerInstances(ctx context.Context, in *ecs.DescribeContainerInstancesInput) (*ecs.DescribeContainerInstancesOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.DescribeContainerInstancesOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DescribeContainerInstances", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.DescribeContainerInstancesWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DescribeContainerInstances", stats)))
 		return nil, err
 	}
 	return out, nil
 }
 
+// DescribeClusters describes existing clusters.
+func (c *BasicClient) DescribeClusters(ctx context.Context, in *ecs.DescribeClustersInput) (*ecs.DescribeClustersOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.DescribeClustersOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DescribeClusters", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.DescribeClustersWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DescribeClusters", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListAccountSettings lists the account settings for ECS resources.
+func (c *BasicClient) ListAccountSettings(ctx context.Context, in *ecs.ListAccountSettingsInput) (*ecs.ListAccountSettingsOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.ListAccountSettingsOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("ListAccountSettings", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.ListAccountSettingsWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("ListAccountSettings", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// ARN formats for ECS resources.
+const (
+	// ARNFormatNew is the long ARN format, which includes the cluster name in
+	// task ARNs.
+	ARNFormatNew = "new"
+	// ARNFormatOld is the short ARN format, which does not include the cluster
+	// name in task ARNs.
+	ARNFormatOld = "old"
+)
+
+// accountSettingTaskLongARNFormat is the name of the account setting that
+// determines whether task ARNs use the long ARN format.
+const accountSettingTaskLongARNFormat = "taskLongArnFormat"
+
+// GetARNFormat returns the ARN format that ECS uses for new tasks, which is
+// either ARNFormatNew or ARNFormatOld depending on the account's
+// taskLongArnFormat setting. Tasks created before the account setting changed
+// keep the ARN format they were created with.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/ecs-account-settings.html#ecs-resource-ids
+func (c *BasicClient) GetARNFormat(ctx context.Context) (string, error) {
+	out, err := c.ListAccountSettings(ctx, &ecs.ListAccountSettingsInput{
+		Name:              aws.String(accountSettingTaskLongARNFormat),
+		EffectiveSettings: aws.Bool(true),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "listing account settings")
+	}
+
+	for _, setting := range out.Settings {
+		if setting == nil || utility.FromStringPtr(setting.Name) != accountSettingTaskLongARNFormat {
+			continue
+		}
+		switch val := utility.FromStringPtr(setting.Value); val {
+		case "enabled":
+			return ARNFormatNew, nil
+		case "disabled":
+			return ARNFormatOld, nil
+		default:
+			return "", errors.Errorf("unrecognized value '%s' for account setting '%s'", val, accountSettingTaskLongARNFormat)
+		}
+	}
+
+	return "", errors.Errorf("account setting '%s' was not returned in the response", accountSettingTaskLongARNFormat)
+}
+
+// DescribeCapacityProviders gets information about the configuration and status
+// of capacity providers.
+func (c *BasicClient) DescribeCapacityProviders(ctx context.Context, in *ecs.DescribeCapacityProvidersInput) (*ecs.DescribeCapacityProvidersOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.DescribeCapacityProvidersOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DescribeCapacityProviders", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.DescribeCapacityProvidersWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DescribeCapacityProviders", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListTaskDefinitionFamilies lists all ECS task definition families matching
+// the input.
+func (c *BasicClient) ListTaskDefinitionFamilies(ctx context.Context, in *ecs.ListTaskDefinitionFamiliesInput) (*ecs.ListTaskDefinitionFamiliesOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.ListTaskDefinitionFamiliesOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("ListTaskDefinitionFamilies", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.ListTaskDefinitionFamiliesWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("ListTaskDefinitionFamilies", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// RunAndWaitForTask runs a single task and waits for it to stop, polling the
+// task's status at the given interval. It returns the final state of the
+// stopped task, which can be used to check the task's stop reason and its
+// containers' exit codes. If the poll interval is not positive, it defaults to
+// 1 second.
+func (c *BasicClient) RunAndWaitForTask(ctx context.Context, in *ecs.RunTaskInput, pollInterval time.Duration) (*ecs.Task, error) {
+	if in == nil {
+		return nil, errors.New("must specify the task to run")
+	}
+	if in.Count != nil && *in.Count != 1 {
+		return nil, errors.Errorf("can only run and wait for a single task, but count is %d", *in.Count)
+	}
+
+	out, err := c.RunTask(ctx, in)
+	if err != nil {
+		return nil, errors.Wrap(err, "running task")
+	}
+	if len(out.Failures) > 0 && out.Failures[0] != nil {
+		return nil, errors.Wrap(ConvertFailureToError(out.Failures[0]), "running task")
+	}
+	if len(out.Tasks) == 0 || out.Tasks[0] == nil {
+		return nil, errors.New("task was not returned in the response")
+	}
+
+	task := out.Tasks[0]
+	taskARN := utility.FromStringPtr(task.TaskArn)
+	stopped, err := WaitForTaskStatus(ctx, c, utility.FromStringPtr(task.ClusterArn), taskARN, func(t *ecs.Task) bool {
+		return TaskStatus(utility.FromStringPtr(t.LastStatus)) == TaskStatusStopped
+	}, pollInterval)
+	if err != nil {
+		return nil, errors.Wrapf(err, "waiting for task '%s' to stop", taskARN)
+	}
+
+	return stopped, nil
+}
+
+// ListActiveTaskDefinitionFamilies lists the names of all task definition
+// families that match the prefix and have at least one active revision. If the
+// prefix is empty, it lists all active families.
+func (c *BasicClient) ListActiveTaskDefinitionFamilies(ctx context.Context, prefix string) ([]string, error) {
+	return c.listTaskDefinitionFamilies(ctx, prefix, ecs.TaskDefinitionFamilyStatusActive)
+}
+
+// ListInactiveTaskDefinitionFamilies lists the names of all task definition
+// families that match the prefix and have no active revisions. If the prefix
+// is empty, it lists all inactive families.
+func (c *BasicClient) ListInactiveTaskDefinitionFamilies(ctx context.Context, prefix string) ([]string, error) {
+	return c.listTaskDefinitionFamilies(ctx, prefix, ecs.TaskDefinitionFamilyStatusInactive)
+}
+
+// listTaskDefinitionFamilies lists the names of all task definition families
+// that match the prefix and status, following pagination.
+func (c *BasicClient) listTaskDefinitionFamilies(ctx context.Context, prefix, status string) ([]string, error) {
+	in := &ecs.ListTaskDefinitionFamiliesInput{
+		Status: aws.String(status),
+	}
+	if prefix != "" {
+		in.FamilyPrefix = aws.String(prefix)
+	}
+
+	var families []string
+	for {
+		out, err := c.ListTaskDefinitionFamilies(ctx, in)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing task definition families")
+		}
+		families = append(families, utility.FromStringPtrSlice(out.Families)...)
+
+		if out.NextToken == nil {
+			return families, nil
+		}
+		in.NextToken = out.NextToken
+	}
+}
+
+// CreateCluster creates a new ECS cluster.
+func (c *BasicClient) CreateCluster(ctx context.Context, in *ecs.CreateClusterInput) (*ecs.CreateClusterOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.CreateClusterOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("CreateCluster", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.CreateClusterWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("CreateCluster", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// HealthCheck checks that the client can reach ECS by listing at most one
+// cluster. It makes a single request without the client's retry options so that
+// it reports failures promptly instead of backing off, which makes it suitable
+// for readiness probes.
+func (c *BasicClient) HealthCheck(ctx context.Context) error {
+	if err := c.setup(); err != nil {
+		return errors.Wrap(err, "setting up client")
+	}
+
+	in := &ecs.ListClustersInput{MaxResults: aws.Int64(1)}
+	if _, err := c.ecs.ListClustersWithContext(ctx, in); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPILogMessage("ListClusters", in, awsutil.RequestIDFrom(ctx))))
+		return errors.Wrap(err, "checking ECS health by listing clusters")
+	}
+	return nil
+}
+
+// CreateService creates a new service in a cluster.
+func (c *BasicClient) CreateService(ctx context.Context, in *ecs.CreateServiceInput) (*ecs.CreateServiceOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.CreateServiceOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("CreateService", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.CreateServiceWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if awsErr.Code() == ecs.ErrCodeClusterNotFoundException {
+				return false, cocoa.NewECSClusterNotFoundError(utility.FromStringPtr(in.Cluster), awsErr)
+			}
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("CreateService", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListServices lists the services in a cluster.
+func (c *BasicClient) ListServices(ctx context.Context, in *ecs.ListServicesInput) (*ecs.ListServicesOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.ListServicesOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("ListServices", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.ListServicesWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if awsErr.Code() == ecs.ErrCodeClusterNotFoundException {
+				return false, cocoa.NewECSClusterNotFoundError(utility.FromStringPtr(in.Cluster), awsErr)
+			}
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("ListServices", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// DescribeServices describes one or more services in a cluster.
+func (c *BasicClient) DescribeServices(ctx context.Context, in *ecs.DescribeServicesInput) (*ecs.DescribeServicesOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.DescribeServicesOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DescribeServices", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.DescribeServicesWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if awsErr.Code() == ecs.ErrCodeClusterNotFoundException {
+				return false, cocoa.NewECSClusterNotFoundError(utility.FromStringPtr(in.Cluster), awsErr)
+			}
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DescribeServices", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateService modifies the configuration of an existing service.
+func (c *BasicClient) UpdateService(ctx context.Context, in *ecs.UpdateServiceInput) (*ecs.UpdateServiceOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.UpdateServiceOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("UpdateService", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.UpdateServiceWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if awsErr.Code() == ecs.ErrCodeServiceNotFoundException {
+				return false, cocoa.NewECSServiceNotFoundError(utility.FromStringPtr(in.Service), awsErr)
+			}
+			if awsErr.Code() == ecs.ErrCodeClusterNotFoundException {
+				return false, cocoa.NewECSClusterNotFoundError(utility.FromStringPtr(in.Cluster), awsErr)
+			}
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("UpdateService", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// RegisterContainerInstance registers an instance as a container instance in a
+// cluster. This is usually done by the ECS container agent, but can be used to
+// register external instances managed by ECS Anywhere.
+func (c *BasicClient) RegisterContainerInstance(ctx context.Context, in *ecs.RegisterContainerInstanceInput) (*ecs.RegisterContainerInstanceOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.RegisterContainerInstanceOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("RegisterContainerInstance", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.RegisterContainerInstanceWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("RegisterContainerInstance", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeregisterContainerInstance deregisters a container instance from a cluster.
+func (c *BasicClient) DeregisterContainerInstance(ctx context.Context, in *ecs.DeregisterContainerInstanceInput) (*ecs.DeregisterContainerInstanceOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.DeregisterContainerInstanceOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DeregisterContainerInstance", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.DeregisterContainerInstanceWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DeregisterContainerInstance", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateContainerInstancesState updates the status of container instances (e.g.
+// to drain them before they are terminated).
+func (c *BasicClient) UpdateContainerInstancesState(ctx context.Context, in *ecs.UpdateContainerInstancesStateInput) (*ecs.UpdateContainerInstancesStateOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *ecs.UpdateContainerInstancesStateOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("UpdateContainerInstancesState", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.ecs.UpdateContainerInstancesStateWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("UpdateContainerInstancesState", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// SetTagConcurrency sets the maximum number of concurrent requests made when
+// tagging multiple resources. By default, it is 10.
+func (c *BasicClient) SetTagConcurrency(n int) *BasicClient {
+	c.tagConcurrency = n
+	return c
+}
+
+// TagMultipleResources adds the same tags to all the given ECS resources. The
+// requests to tag the resources are made concurrently, up to the client's tag
+// concurrency limit. If any resource cannot be tagged, this returns a
+// cocoa.MultiError containing the errors for all resources that failed.
+func (c *BasicClient) TagMultipleResources(ctx context.Context, resourceARNs []string, tags map[string]string) error {
+	if len(tags) == 0 {
+		return errors.New("must specify at least one tag")
+	}
+
+	concurrency := c.tagConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultTagConcurrency
+	}
+	ecsTags := ExportTags(tags)
+
+	var errs cocoa.MultiError
+	var errsMu sync.Mutex
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, arn := range resourceARNs {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			errs.Wrapf(ctx.Err(), "tagging resources")
+			return errs.Resolve()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(arn string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			_, err := c.TagResource(ctx, &ecs.TagResourceInput{
+				ResourceArn: aws.String(arn),
+				Tags:        ecsTags,
+			})
+			errsMu.Lock()
+			errs.Wrapf(err, "tagging resource '%s'", arn)
+			errsMu.Unlock()
+		}(arn)
+	}
+	wg.Wait()
+
+	return errs.Resolve()
+}
+
+// SetAllowedCostAttributionValues sets the allowed values for the given cost
+// attribution tag key, which must be one of awsutil.ProjectTagKey,
+// awsutil.TeamTagKey, or awsutil.EnvironmentTagKey. By default, any non-empty
+// value is allowed.
+func (c *BasicClient) SetAllowedCostAttributionValues(key string, values ...string) *BasicClient {
+	if c.allowedCostValues == nil {
+		c.allowedCostValues = map[string][]string{}
+	}
+	c.allowedCostValues[key] = values
+	return c
+}
+
+// ApplyCostAttributionTags tags the task with the project, team, and
+// environment that its costs should be attributed to, along with the time it
+// was tagged. Each value must be non-empty and, if the client has allowed
+// values configured for the tag, must be one of the allowed values.
+func (c *BasicClient) ApplyCostAttributionTags(ctx context.Context, taskARN, project, team, environment string) error {
+	tags := [][2]string{
+		{awsutil.ProjectTagKey, project},
+		{awsutil.TeamTagKey, team},
+		{awsutil.EnvironmentTagKey, environment},
+	}
+
+	catcher := grip.NewBasicCatcher()
+	for _, kv := range tags {
+		key, val := kv[0], kv[1]
+		if val == "" {
+			catcher.Errorf("must specify a value for tag '%s'", key)
+			continue
+		}
+		allowed, ok := c.allowedCostValues[key]
+		catcher.ErrorfWhen(ok && !utility.StringSliceContains(allowed, val), "value '%s' for tag '%s' is not one of the allowed values %s", val, key, allowed)
+	}
+	if catcher.HasErrors() {
+		return errors.Wrap(catcher.Resolve(), "invalid cost attribution tags")
+	}
+
+	ecsTags := make([]*ecs.Tag, 0, len(tags)+1)
+	for _, kv := range tags {
+		ecsTags = append(ecsTags, &ecs.Tag{
+			Key:   aws.String(kv[0]),
+			Value: aws.String(kv[1]),
+		})
+	}
+	ecsTags = append(ecsTags, &ecs.Tag{
+		Key:   aws.String(awsutil.CreatedAtTagKey),
+		Value: aws.String(time.Now().UTC().Format(time.RFC3339)),
+	})
+
+	if _, err := c.TagResource(ctx, &ecs.TagResourceInput{
+		ResourceArn: aws.String(taskARN),
+		Tags:        ecsTags,
+	}); err != nil {
+		return errors.Wrapf(err, "tagging task '%s'", taskARN)
+	}
+
+	return nil
+}
+
+// SetDescribeConcurrency sets the maximum number of concurrent requests made
+// when describing tasks in batches. By default, it is 10.
+func (c *BasicClient) SetDescribeConcurrency(n int) *BasicClient {
+	c.describeConcurrency = n
+	return c
+}
+
+// DescribeTasksInBatches describes all the given tasks in the cluster by
+// splitting them into batches of at most batchSize tasks and describing the
+// batches concurrently, up to the client's describe concurrency limit. The
+// batch size must be at most 100, which is the maximum number of tasks that
+// can be described in a single request; if it is not positive, it defaults to
+// 100. The described tasks and failures from all batches are returned in the
+// same order as the batches. If any batch cannot be described, this returns a
+// cocoa.MultiError containing the errors for all batches that failed.
+func (c *BasicClient) DescribeTasksInBatches(ctx context.Context, cluster string, taskARNs []string, batchSize int) ([]*ecs.Task, []*ecs.Failure, error) {
+	if batchSize <= 0 {
+		batchSize = maxDescribeTasks
+	}
+	if batchSize > maxDescribeTasks {
+		return nil, nil, errors.Errorf("batch size cannot exceed %d", maxDescribeTasks)
+	}
+
+	concurrency := c.describeConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultDescribeConcurrency
+	}
+
+	var batches [][]string
+	for start := 0; start < len(taskARNs); start += batchSize {
+		end := start + batchSize
+		if end > len(taskARNs) {
+			end = len(taskARNs)
+		}
+		batches = append(batches, taskARNs[start:end])
+	}
+
+	outs := make([]*ecs.DescribeTasksOutput, len(batches))
+	var errs cocoa.MultiError
+	var errsMu sync.Mutex
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			errs.Wrapf(ctx.Err(), "describing tasks")
+			return nil, nil, errs.Resolve()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, batch []string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			out, err := c.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+				Cluster: aws.String(cluster),
+				Tasks:   aws.StringSlice(batch),
+			})
+			if err != nil {
+				errsMu.Lock()
+				errs.Wrapf(err, "describing batch %d of tasks", i)
+				errsMu.Unlock()
+				return
+			}
+			outs[i] = out
+		}(i, batch)
+	}
+	wg.Wait()
+
+	if errs.HasErrors() {
+		return nil, nil, errs.Resolve()
+	}
+
+	var tasks []*ecs.Task
+	var failures []*ecs.Failure
+	for _, out := range outs {
+		tasks = append(tasks, out.Tasks...)
+		failures = append(failures, out.Failures...)
+	}
+
+	return tasks, failures, nil
+}
+
 // Close cleans up all resources owned by the client.
 func (c *BasicClient) Close(ctx context.Context) error {
 	return c.BaseClient.Close(ctx)
@@ -316,6 +1070,8 @@ func (c *BasicClient) isNonRetryableErrorCode(code string) bool {
 		ecs.ErrCodeClientException,
 		ecs.ErrCodeInvalidParameterException,
 		ecs.ErrCodeClusterNotFoundException,
+		ecs.ErrCodeServiceNotFoundException,
+		ecs.ErrCodeServiceNotActiveException,
 		request.InvalidParameterErrCode,
 		request.ParamRequiredErrCode:
 		return true
@@ -344,11 +1100,14 @@ func ConvertFailureToError(f *ecs.Failure) error {
 	}
 
 	if isTaskNotFoundFailure(*f) {
-		return cocoa.NewECSTaskNotFoundError(utility.FromStringPtr(f.Arn))
+		return cocoa.NewECSTaskNotFoundError(utility.FromStringPtr(f.Arn), nil)
 	}
 	var parts []string
 	if arn := utility.FromStringPtr(f.Arn); arn != "" {
 		parts = append(parts, fmt.Sprintf("task '%s'", arn))
+		if taskARN, err := ParseTaskARN(arn); err == nil && taskARN.Cluster != "" {
+			parts = append(parts, fmt.Sprintf("(cluster) %s", taskARN.Cluster))
+		}
 	}
 	if reason := utility.FromStringPtr(f.Reason); reason != "" {
 		parts = append(parts, fmt.Sprintf("(reason) %s", reason))
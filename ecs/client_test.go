@@ -2,12 +2,15 @@ package ecs
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	awsECS "github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/awsutil"
 	"github.com/evergreen-ci/cocoa/internal/testcase"
 	"github.com/evergreen-ci/cocoa/internal/testutil"
 	"github.com/evergreen-ci/utility"
@@ -85,6 +88,14 @@ func TestConvertFailureToError(t *testing.T) {
 		assert.Contains(t, err.Error(), reason)
 		assert.Contains(t, err.Error(), detail)
 	})
+	t.Run("IncludesClusterFromTaskARN", func(t *testing.T) {
+		err := ConvertFailureToError(&awsECS.Failure{
+			Arn:    aws.String("arn:aws:ecs:us-east-1:123456789012:task/some_cluster/0123456789abcdef"),
+			Reason: aws.String("some reason"),
+		})
+		require.NotZero(t, err)
+		assert.Contains(t, err.Error(), "(cluster) some_cluster")
+	})
 	t.Run("ConvertsMissingTaskFailureToTaskNotFound", func(t *testing.T) {
 		err := ConvertFailureToError(&awsECS.Failure{
 			Arn:    aws.String("arn"),
@@ -93,3 +104,241 @@ func TestConvertFailureToError(t *testing.T) {
 		assert.True(t, cocoa.IsECSTaskNotFoundError(err))
 	})
 }
+
+func TestBasicECSClientConcurrentUse(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	c, err := NewBasicClient(testutil.RecordedAWSOptions(t, "testdata/fixtures"))
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, c.Close(ctx))
+	}()
+
+	t.Run("Parallel", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			t.Run(fmt.Sprintf("ListTasks%d", i), func(t *testing.T) {
+				t.Parallel()
+
+				out, err := c.ListTasks(ctx, &awsECS.ListTasksInput{
+					Cluster: aws.String("cluster"),
+				})
+				require.NoError(t, err)
+				require.NotZero(t, out)
+				assert.Len(t, out.TaskArns, 1)
+			})
+		}
+	})
+}
+
+func TestBasicECSClientFIPSEndpoints(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	opts := awsutil.NewClientOptions().
+		SetCredentials(credentials.NewStaticCredentials("id", "secret", "")).
+		SetRegion("us-east-1").
+		SetFIPSEndpoints(true)
+	c, err := NewBasicClient(*opts)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, c.Close(ctx))
+	}()
+
+	require.NoError(t, c.setup())
+	assert.Equal(t, "https://ecs-fips.us-east-1.amazonaws.com", c.ecs.Endpoint)
+}
+
+func TestBasicECSClientWithRecordedFixtures(t *testing.T) {
+	const (
+		cluster = "cluster"
+		taskARN = "arn:aws:ecs:us-east-1:123456789012:task/cluster/0123456789abcdef0123456789abcdef"
+	)
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *BasicClient){
+		"ListTasks": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			out, err := c.ListTasks(ctx, &awsECS.ListTasksInput{
+				Cluster: aws.String(cluster),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			require.Len(t, out.TaskArns, 2)
+			assert.Equal(t, taskARN, utility.FromStringPtr(out.TaskArns[0]))
+		},
+		"DescribeTasks": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			out, err := c.DescribeTasks(ctx, &awsECS.DescribeTasksInput{
+				Cluster: aws.String(cluster),
+				Tasks:   []*string{aws.String(taskARN), aws.String("arn:aws:ecs:us-east-1:123456789012:task/cluster/fedcba9876543210fedcba9876543210")},
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			require.Len(t, out.Tasks, 1)
+			assert.Equal(t, taskARN, utility.FromStringPtr(out.Tasks[0].TaskArn))
+			assert.Equal(t, TaskStatusRunning, TaskStatus(utility.FromStringPtr(out.Tasks[0].LastStatus)))
+			require.Len(t, out.Tasks[0].Containers, 1)
+			require.Len(t, out.Failures, 1)
+			assert.True(t, cocoa.IsECSTaskNotFoundError(ConvertFailureToError(out.Failures[0])))
+		},
+		"RunTaskRetriesOnServerError": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			out, err := c.RunTask(ctx, &awsECS.RunTaskInput{
+				Cluster:        aws.String(cluster),
+				TaskDefinition: aws.String("family:1"),
+				Count:          aws.Int64(1),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			require.Len(t, out.Tasks, 1)
+			assert.Equal(t, taskARN, utility.FromStringPtr(out.Tasks[0].TaskArn))
+		},
+		"StopTaskFailsWithNonexistentTask": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			out, err := c.StopTask(ctx, &awsECS.StopTaskInput{
+				Cluster: aws.String(cluster),
+				Task:    aws.String(taskARN),
+			})
+			assert.True(t, cocoa.IsECSTaskNotFoundError(err))
+			assert.Zero(t, out)
+		},
+		"TagMultipleResources": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			arns := []string{
+				taskARN,
+				"arn:aws:ecs:us-east-1:123456789012:task/cluster/fedcba9876543210fedcba9876543210",
+				"arn:aws:ecs:us-east-1:123456789012:task-definition/family:1",
+			}
+			assert.NoError(t, c.TagMultipleResources(ctx, arns, map[string]string{"key": "value"}))
+		},
+		"TagMultipleResourcesFailsWithInvalidResource": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			c.SetTagConcurrency(1)
+			err := c.TagMultipleResources(ctx, []string{taskARN, "foo"}, map[string]string{"key": "value"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "foo")
+			assert.NotContains(t, err.Error(), taskARN)
+		},
+		"ApplyCostAttributionTags": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			c.SetAllowedCostAttributionValues(awsutil.EnvironmentTagKey, "staging", "production")
+			assert.NoError(t, c.ApplyCostAttributionTags(ctx, taskARN, "project", "team", "staging"))
+		},
+		"ApplyCostAttributionTagsFailsWithDisallowedValue": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			c.SetAllowedCostAttributionValues(awsutil.EnvironmentTagKey, "staging", "production")
+			err := c.ApplyCostAttributionTags(ctx, taskARN, "project", "team", "development")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "development")
+		},
+		"ApplyCostAttributionTagsFailsWithEmptyValue": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			err := c.ApplyCostAttributionTags(ctx, taskARN, "project", "", "staging")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), awsutil.TeamTagKey)
+		},
+		"GetARNFormat": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			format, err := c.GetARNFormat(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, ARNFormatNew, format)
+		},
+		"GetARNFormatFailsWithoutSetting": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			format, err := c.GetARNFormat(ctx)
+			assert.Error(t, err)
+			assert.Zero(t, format)
+		},
+		"HealthCheck": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			assert.NoError(t, c.HealthCheck(ctx))
+		},
+		"HealthCheckFailsWithAccessDenied": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			err := c.HealthCheck(ctx)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "AccessDeniedException")
+		},
+		"RunAndWaitForTask": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			task, err := c.RunAndWaitForTask(ctx, &awsECS.RunTaskInput{
+				Cluster:        aws.String(cluster),
+				TaskDefinition: aws.String("family:1"),
+			}, time.Millisecond)
+			require.NoError(t, err)
+			require.NotZero(t, task)
+			assert.Equal(t, taskARN, utility.FromStringPtr(task.TaskArn))
+			assert.Equal(t, TaskStatusStopped, TaskStatus(utility.FromStringPtr(task.LastStatus)))
+			assert.Equal(t, map[string]int64{"print_foo": 0}, ExtractContainerExitCodes(task))
+		},
+		"RunAndWaitForTaskFailsWithRunTaskFailure": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			task, err := c.RunAndWaitForTask(ctx, &awsECS.RunTaskInput{
+				Cluster:        aws.String(cluster),
+				TaskDefinition: aws.String("family:1"),
+			}, time.Millisecond)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "RESOURCE:MEMORY")
+			assert.Zero(t, task)
+		},
+		"RunAndWaitForTaskFailsWithMultipleTasks": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			task, err := c.RunAndWaitForTask(ctx, &awsECS.RunTaskInput{
+				Cluster:        aws.String(cluster),
+				TaskDefinition: aws.String("family:1"),
+				Count:          aws.Int64(2),
+			}, time.Millisecond)
+			assert.Error(t, err)
+			assert.Zero(t, task)
+		},
+		"ListActiveTaskDefinitionFamilies": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			families, err := c.ListActiveTaskDefinitionFamilies(ctx, "cocoa")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"cocoa-family0", "cocoa-family1", "cocoa-family2"}, families)
+		},
+		"ListInactiveTaskDefinitionFamilies": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			families, err := c.ListInactiveTaskDefinitionFamilies(ctx, "cocoa")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"cocoa-old"}, families)
+		},
+		"DescribeTaskDefinitionWithTags": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			out, err := c.DescribeTaskDefinitionWithTags(ctx, "family:1")
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			require.NotZero(t, out.TaskDefinition)
+			assert.Equal(t, "arn:aws:ecs:us-east-1:123456789012:task-definition/family:1", utility.FromStringPtr(out.TaskDefinition.TaskDefinitionArn))
+			require.Len(t, out.Tags, 1)
+			assert.Equal(t, "key", utility.FromStringPtr(out.Tags[0].Key))
+			assert.Equal(t, "value", utility.FromStringPtr(out.Tags[0].Value))
+		},
+		"DescribeTaskDefinitionWithTagsFailsWithoutTaskDefinition": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			out, err := c.DescribeTaskDefinitionWithTags(ctx, "")
+			assert.Error(t, err)
+			assert.Zero(t, out)
+		},
+		"DescribeTasksInBatches": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			c.SetDescribeConcurrency(1)
+			arns := []string{
+				taskARN,
+				"arn:aws:ecs:us-east-1:123456789012:task/cluster/11111111111111111111111111111111",
+				"arn:aws:ecs:us-east-1:123456789012:task/cluster/fedcba9876543210fedcba9876543210",
+			}
+			tasks, failures, err := c.DescribeTasksInBatches(ctx, cluster, arns, 2)
+			require.NoError(t, err)
+			require.Len(t, tasks, 2)
+			assert.Equal(t, arns[0], utility.FromStringPtr(tasks[0].TaskArn))
+			assert.Equal(t, arns[1], utility.FromStringPtr(tasks[1].TaskArn))
+			require.Len(t, failures, 1)
+			assert.Equal(t, arns[2], utility.FromStringPtr(failures[0].Arn))
+		},
+		"DescribeTasksInBatchesFailsWithNonexistentCluster": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			tasks, failures, err := c.DescribeTasksInBatches(ctx, "nonexistent", []string{taskARN}, 0)
+			assert.Error(t, err)
+			assert.Empty(t, tasks)
+			assert.Empty(t, failures)
+		},
+		"DescribeTasksInBatchesFailsWithBatchSizeOverLimit": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			tasks, failures, err := c.DescribeTasksInBatches(ctx, cluster, []string{taskARN}, 101)
+			assert.Error(t, err)
+			assert.Empty(t, tasks)
+			assert.Empty(t, failures)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+			defer cancel()
+
+			c, err := NewBasicClient(testutil.RecordedAWSOptions(t, "testdata/fixtures"))
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, c.Close(ctx))
+			}()
+
+			tCase(ctx, t, c)
+		})
+	}
+}
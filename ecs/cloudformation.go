@@ -0,0 +1,211 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// cloudFormationTaskDefinitionType is the CloudFormation resource type for an
+// ECS task definition.
+const cloudFormationTaskDefinitionType = "AWS::ECS::TaskDefinition"
+
+// ImportFromCloudFormation parses a CloudFormation template in either YAML or
+// JSON format, finds the first AWS::ECS::TaskDefinition resource in it, and
+// converts the resource's properties to the input to register the task
+// definition. Property names are matched to the input's fields
+// case-insensitively and scalar values are converted to the field's type
+// (e.g. a numeric CPU is converted to a string).
+//
+// Since the template is not deployed as a stack, intrinsic functions (e.g.
+// !Ref or Fn::GetAtt) cannot be resolved, so properties that use them result
+// in an error. Properties that do not correspond to any field in the input
+// also result in an error rather than being silently dropped. The returned
+// input is not validated, so callers should check it (e.g. with
+// ValidateRegisterTaskDefinitionInput) before registering it, since
+// CloudFormation allows the family to be omitted.
+func ImportFromCloudFormation(data []byte) (*ecs.RegisterTaskDefinitionInput, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "parsing CloudFormation template")
+	}
+	if len(doc.Content) == 0 {
+		return nil, errors.New("CloudFormation template is empty")
+	}
+
+	resources := mappingValue(doc.Content[0], "Resources")
+	if resources == nil || resources.Kind != yaml.MappingNode {
+		return nil, errors.New("CloudFormation template does not have any resources")
+	}
+
+	for i := 0; i+1 < len(resources.Content); i += 2 {
+		name := resources.Content[i].Value
+		resource := resources.Content[i+1]
+		if resourceType := mappingValue(resource, "Type"); resourceType == nil || resourceType.Value != cloudFormationTaskDefinitionType {
+			continue
+		}
+
+		var in ecs.RegisterTaskDefinitionInput
+		props := mappingValue(resource, "Properties")
+		if props == nil {
+			return &in, nil
+		}
+		if err := decodeCloudFormationNode(props, reflect.ValueOf(&in).Elem(), fmt.Sprintf("Resources.%s.Properties", name)); err != nil {
+			return nil, errors.Wrapf(err, "converting properties of task definition resource '%s'", name)
+		}
+		return &in, nil
+	}
+
+	return nil, errors.Errorf("CloudFormation template does not have a resource of type '%s'", cloudFormationTaskDefinitionType)
+}
+
+// mappingValue returns the value for the key in the YAML mapping node. If the
+// node is not a mapping or does not have the key, it returns nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// intrinsicFunction returns the name of the CloudFormation intrinsic function
+// used by the node, if any. Intrinsic functions are either written as a YAML
+// tag (e.g. !Ref) or as a mapping with a single key (e.g. {"Ref": ...} or
+// {"Fn::GetAtt": ...}).
+func intrinsicFunction(n *yaml.Node) string {
+	if strings.HasPrefix(n.Tag, "!") && !strings.HasPrefix(n.Tag, "!!") {
+		return n.Tag
+	}
+	if n.Kind == yaml.MappingNode && len(n.Content) == 2 {
+		key := n.Content[0].Value
+		if key == "Ref" || key == "Condition" || strings.HasPrefix(key, "Fn::") {
+			return key
+		}
+	}
+	return ""
+}
+
+// decodeCloudFormationNode decodes the YAML node into the value, which must
+// be settable. The path is the location of the node in the template, which is
+// used to report errors.
+func decodeCloudFormationNode(n *yaml.Node, v reflect.Value, path string) error {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if fn := intrinsicFunction(n); fn != "" {
+		return errors.Errorf("%s: intrinsic function '%s' cannot be resolved outside of a CloudFormation stack", path, fn)
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := decodeCloudFormationNode(n, elem.Elem(), path); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return errors.Errorf("%s: expected an object", path)
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			field := cloudFormationField(v, key)
+			if !field.IsValid() {
+				return errors.Errorf("%s: unrecognized property '%s'", path, key)
+			}
+			if err := decodeCloudFormationNode(n.Content[i+1], field, path+"."+key); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			return errors.Errorf("%s: expected a list", path)
+		}
+		s := reflect.MakeSlice(v.Type(), len(n.Content), len(n.Content))
+		for i, item := range n.Content {
+			if err := decodeCloudFormationNode(item, s.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return errors.Errorf("%s: expected an object", path)
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			val := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeCloudFormationNode(n.Content[i+1], val, path+"."+key); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), val)
+		}
+		v.Set(m)
+		return nil
+	}
+
+	if n.Kind != yaml.ScalarNode {
+		return errors.Errorf("%s: expected a scalar value", path)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(n.Value)
+	case reflect.Int64:
+		i, err := strconv.ParseInt(n.Value, 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "%s: expected an integer", path)
+		}
+		v.SetInt(i)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(n.Value, 64)
+		if err != nil {
+			return errors.Wrapf(err, "%s: expected a number", path)
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(n.Value)
+		if err != nil {
+			return errors.Wrapf(err, "%s: expected a boolean", path)
+		}
+		v.SetBool(b)
+	default:
+		return errors.Errorf("%s: cannot convert to type %s", path, v.Type())
+	}
+
+	return nil
+}
+
+// cloudFormationField returns the exported field of the struct whose name
+// matches the CloudFormation property name case-insensitively. If there is no
+// such field, it returns an invalid value.
+func cloudFormationField(v reflect.Value, name string) reflect.Value {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Name == "_" {
+			continue
+		}
+		if strings.EqualFold(f.Name, name) {
+			return v.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
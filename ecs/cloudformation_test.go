@@ -0,0 +1,184 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportFromCloudFormation(t *testing.T) {
+	t.Run("ConvertsYAMLTemplate", func(t *testing.T) {
+		in, err := ImportFromCloudFormation([]byte(`
+AWSTemplateFormatVersion: "2010-09-09"
+Resources:
+  Cluster:
+    Type: AWS::ECS::Cluster
+  TaskDefinition:
+    Type: AWS::ECS::TaskDefinition
+    Properties:
+      Family: family
+      Cpu: 256
+      Memory: "512"
+      NetworkMode: awsvpc
+      RequiresCompatibilities:
+        - FARGATE
+      Tags:
+        - Key: team
+          Value: evergreen
+      Volumes:
+        - Name: data
+          EFSVolumeConfiguration:
+            FilesystemId: fs-12345
+            TransitEncryption: ENABLED
+      ContainerDefinitions:
+        - Name: app
+          Image: busybox
+          Cpu: "128"
+          Memory: 256
+          Essential: true
+          Command: [echo, hello]
+          Environment:
+            - Name: KEY
+              Value: value
+          DockerLabels:
+            label: value
+          PortMappings:
+            - ContainerPort: 8080
+              Protocol: tcp
+`))
+		require.NoError(t, err)
+		require.NotZero(t, in)
+
+		assert.Equal(t, "family", utility.FromStringPtr(in.Family))
+		assert.Equal(t, "256", utility.FromStringPtr(in.Cpu))
+		assert.Equal(t, "512", utility.FromStringPtr(in.Memory))
+		assert.Equal(t, ecs.NetworkModeAwsvpc, utility.FromStringPtr(in.NetworkMode))
+		assert.Equal(t, []string{ecs.CompatibilityFargate}, utility.FromStringPtrSlice(in.RequiresCompatibilities))
+		require.Len(t, in.Tags, 1)
+		assert.Equal(t, "team", utility.FromStringPtr(in.Tags[0].Key))
+		assert.Equal(t, "evergreen", utility.FromStringPtr(in.Tags[0].Value))
+		require.Len(t, in.Volumes, 1)
+		require.NotZero(t, in.Volumes[0].EfsVolumeConfiguration)
+		assert.Equal(t, "fs-12345", utility.FromStringPtr(in.Volumes[0].EfsVolumeConfiguration.FileSystemId))
+
+		require.Len(t, in.ContainerDefinitions, 1)
+		def := in.ContainerDefinitions[0]
+		assert.Equal(t, "app", utility.FromStringPtr(def.Name))
+		assert.Equal(t, "busybox", utility.FromStringPtr(def.Image))
+		assert.EqualValues(t, 128, utility.FromInt64Ptr(def.Cpu))
+		assert.EqualValues(t, 256, utility.FromInt64Ptr(def.Memory))
+		assert.True(t, utility.FromBoolPtr(def.Essential))
+		assert.Equal(t, []string{"echo", "hello"}, utility.FromStringPtrSlice(def.Command))
+		require.Len(t, def.Environment, 1)
+		assert.Equal(t, "KEY", utility.FromStringPtr(def.Environment[0].Name))
+		assert.Equal(t, "value", utility.FromStringPtr(def.DockerLabels["label"]))
+		require.Len(t, def.PortMappings, 1)
+		assert.EqualValues(t, 8080, utility.FromInt64Ptr(def.PortMappings[0].ContainerPort))
+
+		assert.NoError(t, ValidateRegisterTaskDefinitionInput(in))
+	})
+	t.Run("ConvertsJSONTemplate", func(t *testing.T) {
+		in, err := ImportFromCloudFormation([]byte(`{
+  "Resources": {
+    "TaskDefinition": {
+      "Type": "AWS::ECS::TaskDefinition",
+      "Properties": {
+        "Family": "family",
+        "ContainerDefinitions": [{"Name": "app", "Image": "busybox", "Memory": 128}]
+      }
+    }
+  }
+}`))
+		require.NoError(t, err)
+		assert.Equal(t, "family", utility.FromStringPtr(in.Family))
+		require.Len(t, in.ContainerDefinitions, 1)
+		assert.EqualValues(t, 128, utility.FromInt64Ptr(in.ContainerDefinitions[0].Memory))
+	})
+	t.Run("UsesFirstTaskDefinition", func(t *testing.T) {
+		in, err := ImportFromCloudFormation([]byte(`
+Resources:
+  Second:
+    Type: AWS::ECS::TaskDefinition
+    Properties:
+      Family: second
+  First:
+    Type: AWS::ECS::TaskDefinition
+    Properties:
+      Family: first
+`))
+		require.NoError(t, err)
+		assert.Equal(t, "second", utility.FromStringPtr(in.Family))
+	})
+	t.Run("FailsWithIntrinsicFunctionTag", func(t *testing.T) {
+		_, err := ImportFromCloudFormation([]byte(`
+Resources:
+  TaskDefinition:
+    Type: AWS::ECS::TaskDefinition
+    Properties:
+      Family: family
+      TaskRoleArn: !GetAtt Role.Arn
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "!GetAtt")
+		assert.Contains(t, err.Error(), "TaskRoleArn")
+	})
+	t.Run("FailsWithIntrinsicFunctionMapping", func(t *testing.T) {
+		_, err := ImportFromCloudFormation([]byte(`
+Resources:
+  TaskDefinition:
+    Type: AWS::ECS::TaskDefinition
+    Properties:
+      Family:
+        Ref: FamilyParameter
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Ref")
+	})
+	t.Run("FailsWithUnrecognizedProperty", func(t *testing.T) {
+		_, err := ImportFromCloudFormation([]byte(`
+Resources:
+  TaskDefinition:
+    Type: AWS::ECS::TaskDefinition
+    Properties:
+      Family: family
+      Nonexistent: value
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Nonexistent")
+	})
+	t.Run("FailsWithMismatchedType", func(t *testing.T) {
+		_, err := ImportFromCloudFormation([]byte(`
+Resources:
+  TaskDefinition:
+    Type: AWS::ECS::TaskDefinition
+    Properties:
+      ContainerDefinitions:
+        - Name: app
+          Memory: lots
+`))
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithoutTaskDefinition", func(t *testing.T) {
+		_, err := ImportFromCloudFormation([]byte(`
+Resources:
+  Cluster:
+    Type: AWS::ECS::Cluster
+`))
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithoutResources", func(t *testing.T) {
+		_, err := ImportFromCloudFormation([]byte(`AWSTemplateFormatVersion: "2010-09-09"`))
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithInvalidTemplate", func(t *testing.T) {
+		_, err := ImportFromCloudFormation([]byte("Resources: ["))
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithEmptyTemplate", func(t *testing.T) {
+		_, err := ImportFromCloudFormation(nil)
+		assert.Error(t, err)
+	})
+}
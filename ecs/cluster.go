@@ -0,0 +1,107 @@
+package ecs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+)
+
+// Names of container instance resources.
+const (
+	resourceCPU    = "CPU"
+	resourceMemory = "MEMORY"
+)
+
+// CapacityCheckResult is the result of checking whether a cluster has enough
+// capacity to run a task.
+type CapacityCheckResult struct {
+	// ContainerInstances is the number of container instances registered to
+	// the cluster.
+	ContainerInstances int64
+	// RegisteredCPU is the total CPU units registered by the cluster's
+	// container instances.
+	RegisteredCPU int64
+	// RegisteredMemoryMiB is the total memory registered by the cluster's
+	// container instances.
+	RegisteredMemoryMiB int64
+	// RemainingCPU is the total CPU units that are not reserved by tasks
+	// across all the cluster's container instances.
+	RemainingCPU int64
+	// RemainingMemoryMiB is the total memory that is not reserved by tasks
+	// across all the cluster's container instances.
+	RemainingMemoryMiB int64
+	// HasCapacity indicates whether at least one container instance has
+	// enough remaining CPU and memory to place the task.
+	HasCapacity bool
+}
+
+// CheckClusterCapacity checks whether the cluster's container instances have
+// enough remaining resources to run a task that requires the given CPU units
+// and memory. A task must be placed on a single container instance, so the
+// cluster only has capacity if one of its container instances can fit the
+// entire task. Clusters that only run Fargate tasks have no container
+// instances, so this will report that they have no capacity.
+func CheckClusterCapacity(ctx context.Context, c cocoa.ECSClient, cluster string, cpu, memoryMiB int64) (*CapacityCheckResult, error) {
+	out, err := c.DescribeClusters(ctx, &ecs.DescribeClustersInput{
+		Clusters: []*string{aws.String(cluster)},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describing cluster")
+	}
+	if len(out.Failures) > 0 && out.Failures[0] != nil {
+		f := out.Failures[0]
+		return nil, errors.Errorf("describing cluster '%s': %s", cluster, utility.FromStringPtr(f.Reason))
+	}
+	if len(out.Clusters) == 0 || out.Clusters[0] == nil {
+		return nil, errors.Errorf("cluster '%s' was not returned in the response", cluster)
+	}
+
+	res := &CapacityCheckResult{
+		ContainerInstances: utility.FromInt64Ptr(out.Clusters[0].RegisteredContainerInstancesCount),
+	}
+	if res.ContainerInstances == 0 {
+		return res, nil
+	}
+
+	instances, err := describeAllContainerInstances(ctx, c, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting container instances")
+	}
+	for _, instance := range instances {
+		if utility.FromStringPtr(instance.Status) != ecs.ContainerInstanceStatusActive {
+			continue
+		}
+		registeredCPU, registeredMem := extractResources(instance.RegisteredResources)
+		remainingCPU, remainingMem := extractResources(instance.RemainingResources)
+		res.RegisteredCPU += registeredCPU
+		res.RegisteredMemoryMiB += registeredMem
+		res.RemainingCPU += remainingCPU
+		res.RemainingMemoryMiB += remainingMem
+		if remainingCPU >= cpu && remainingMem >= memoryMiB {
+			res.HasCapacity = true
+		}
+	}
+
+	return res, nil
+}
+
+// extractResources returns the CPU units and memory from the container
+// instance resources.
+func extractResources(resources []*ecs.Resource) (cpu, memoryMiB int64) {
+	for _, r := range resources {
+		if r == nil {
+			continue
+		}
+		switch utility.FromStringPtr(r.Name) {
+		case resourceCPU:
+			cpu = utility.FromInt64Ptr(r.IntegerValue)
+		case resourceMemory:
+			memoryMiB = utility.FromInt64Ptr(r.IntegerValue)
+		}
+	}
+	return cpu, memoryMiB
+}
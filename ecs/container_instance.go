@@ -0,0 +1,178 @@
+package ecs
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// maxDescribeContainerInstances is the maximum number of container instances
+// that can be described in a single DescribeContainerInstances request.
+const maxDescribeContainerInstances = 100
+
+// GetContainerInstanceAgentVersions returns the ECS agent version running on
+// each container instance in the cluster. The versions are keyed by the
+// container instance's EC2 instance ID, or by the container instance ARN if it
+// is not an EC2 instance.
+func GetContainerInstanceAgentVersions(ctx context.Context, c cocoa.ECSClient, cluster string) (map[string]string, error) {
+	instances, err := describeAllContainerInstances(ctx, c, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := map[string]string{}
+	for _, instance := range instances {
+		id := utility.FromStringPtr(instance.Ec2InstanceId)
+		if id == "" {
+			id = utility.FromStringPtr(instance.ContainerInstanceArn)
+		}
+		var version string
+		if instance.VersionInfo != nil {
+			version = utility.FromStringPtr(instance.VersionInfo.AgentVersion)
+		}
+		versions[id] = version
+	}
+
+	return versions, nil
+}
+
+// DrainAndWait sets the container instance's status to draining so that no
+// new tasks are placed on it, then polls it until it has no more running or
+// pending tasks. This should be done before terminating the container
+// instance's underlying instance. ECS only stops tasks that are part of a
+// service when draining, so standalone tasks must finish or be stopped
+// separately. If the poll interval is not positive, it defaults to 1 second.
+func DrainAndWait(ctx context.Context, c cocoa.ECSClient, cluster, instanceARN string, pollInterval time.Duration) error {
+	if cluster == "" {
+		return errors.New("must specify a cluster")
+	}
+	if instanceARN == "" {
+		return errors.New("must specify a container instance")
+	}
+	if pollInterval <= 0 {
+		pollInterval = defaultTaskPollInterval
+	}
+
+	out, err := c.UpdateContainerInstancesState(ctx, &ecs.UpdateContainerInstancesStateInput{
+		Cluster:            aws.String(cluster),
+		ContainerInstances: []*string{aws.String(instanceARN)},
+		Status:             aws.String(ecs.ContainerInstanceStatusDraining),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "draining container instance '%s'", instanceARN)
+	}
+	for _, f := range out.Failures {
+		if f != nil {
+			return errors.Errorf("draining container instance '%s': %s", instanceARN, utility.FromStringPtr(f.Reason))
+		}
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "waiting for container instance '%s' to drain", instanceARN)
+		case <-timer.C:
+			remaining, err := countContainerInstanceTasks(ctx, c, cluster, instanceARN)
+			if err != nil {
+				return errors.Wrapf(err, "checking tasks on container instance '%s'", instanceARN)
+			}
+			if remaining == 0 {
+				return nil
+			}
+			timer.Reset(pollInterval)
+		}
+	}
+}
+
+// countContainerInstanceTasks returns the number of running and pending tasks
+// on the container instance.
+func countContainerInstanceTasks(ctx context.Context, c cocoa.ECSClient, cluster, instanceARN string) (int64, error) {
+	out, err := c.DescribeContainerInstances(ctx, &ecs.DescribeContainerInstancesInput{
+		Cluster:            aws.String(cluster),
+		ContainerInstances: []*string{aws.String(instanceARN)},
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "describing container instance")
+	}
+	for _, f := range out.Failures {
+		if f != nil {
+			return 0, errors.Errorf("describing container instance: %s", utility.FromStringPtr(f.Reason))
+		}
+	}
+	if len(out.ContainerInstances) == 0 || out.ContainerInstances[0] == nil {
+		return 0, errors.New("expected the container instance to exist in ECS, but none was returned")
+	}
+
+	instance := out.ContainerInstances[0]
+	return utility.FromInt64Ptr(instance.RunningTasksCount) + utility.FromInt64Ptr(instance.PendingTasksCount), nil
+}
+
+// describeAllContainerInstances describes all the container instances in the
+// cluster.
+func describeAllContainerInstances(ctx context.Context, c cocoa.ECSClient, cluster string) ([]*ecs.ContainerInstance, error) {
+	arns, err := listAllContainerInstances(ctx, c, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing container instances")
+	}
+
+	var instances []*ecs.ContainerInstance
+	for start := 0; start < len(arns); start += maxDescribeContainerInstances {
+		end := start + maxDescribeContainerInstances
+		if end > len(arns) {
+			end = len(arns)
+		}
+
+		out, err := c.DescribeContainerInstances(ctx, &ecs.DescribeContainerInstancesInput{
+			Cluster:            aws.String(cluster),
+			ContainerInstances: arns[start:end],
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "describing container instances")
+		}
+		if len(out.Failures) != 0 {
+			catcher := grip.NewBasicCatcher()
+			for _, f := range out.Failures {
+				if f == nil {
+					continue
+				}
+				catcher.Errorf("container instance '%s': %s", utility.FromStringPtr(f.Arn), utility.FromStringPtr(f.Reason))
+			}
+			return nil, errors.Wrap(catcher.Resolve(), "describing container instances")
+		}
+
+		for _, instance := range out.ContainerInstances {
+			if instance != nil {
+				instances = append(instances, instance)
+			}
+		}
+	}
+
+	return instances, nil
+}
+
+// listAllContainerInstances lists the ARNs of all the container instances in
+// the cluster.
+func listAllContainerInstances(ctx context.Context, c cocoa.ECSClient, cluster string) ([]*string, error) {
+	in := &ecs.ListContainerInstancesInput{Cluster: aws.String(cluster)}
+	var arns []*string
+	for {
+		out, err := c.ListContainerInstances(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		arns = append(arns, out.ContainerInstanceArns...)
+		if out.NextToken == nil {
+			return arns, nil
+		}
+		in.NextToken = out.NextToken
+	}
+}
@@ -0,0 +1,83 @@
+package ecs
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+)
+
+// DependencyBuilder builds the dependencies of a container on other containers
+// in the same task, which control the order in which the containers start.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/APIReference/API_ContainerDependency.html
+type DependencyBuilder struct {
+	deps []*ecs.ContainerDependency
+}
+
+// NewDependencyBuilder returns a new builder for container dependencies.
+func NewDependencyBuilder() *DependencyBuilder {
+	return &DependencyBuilder{}
+}
+
+// DependsOnStart makes the container wait for the other container to start.
+func (b *DependencyBuilder) DependsOnStart(containerName string) *DependencyBuilder {
+	return b.DependsOn(containerName, ecs.ContainerConditionStart)
+}
+
+// DependsOnHealthy makes the container wait for the other container to pass
+// its health check.
+func (b *DependencyBuilder) DependsOnHealthy(containerName string) *DependencyBuilder {
+	return b.DependsOn(containerName, ecs.ContainerConditionHealthy)
+}
+
+// DependsOnSuccess makes the container wait for the other container to exit
+// with a zero exit code.
+func (b *DependencyBuilder) DependsOnSuccess(containerName string) *DependencyBuilder {
+	return b.DependsOn(containerName, ecs.ContainerConditionSuccess)
+}
+
+// DependsOn makes the container wait for the other container to reach the
+// condition (i.e. START, COMPLETE, SUCCESS or HEALTHY). If the container
+// already depends on the other container, the condition is replaced.
+func (b *DependencyBuilder) DependsOn(containerName, condition string) *DependencyBuilder {
+	for _, dep := range b.deps {
+		if utility.FromStringPtr(dep.ContainerName) == containerName {
+			dep.SetCondition(condition)
+			return b
+		}
+	}
+	b.deps = append(b.deps, &ecs.ContainerDependency{
+		ContainerName: aws.String(containerName),
+		Condition:     aws.String(condition),
+	})
+	return b
+}
+
+// Validate checks that every dependency names a container and has a known
+// condition.
+func (b *DependencyBuilder) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	for _, dep := range b.deps {
+		name := utility.FromStringPtr(dep.ContainerName)
+		condition := utility.FromStringPtr(dep.Condition)
+		catcher.NewWhen(name == "", "must specify a container name for a dependency")
+		catcher.ErrorfWhen(!utility.StringSliceContains(ecs.ContainerCondition_Values(), condition), "invalid condition '%s' for dependency on container '%s'", condition, name)
+	}
+	return catcher.Resolve()
+}
+
+// Build returns the dependencies in the order that they were added. It does
+// not validate the dependencies, so callers should check Validate first.
+func (b *DependencyBuilder) Build() []*ecs.ContainerDependency {
+	deps := make([]*ecs.ContainerDependency, 0, len(b.deps))
+	for _, dep := range b.deps {
+		deps = append(deps, &ecs.ContainerDependency{
+			ContainerName: aws.String(utility.FromStringPtr(dep.ContainerName)),
+			Condition:     aws.String(utility.FromStringPtr(dep.Condition)),
+		})
+	}
+	if len(deps) == 0 {
+		return nil
+	}
+	return deps
+}
@@ -0,0 +1,55 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependencyBuilder(t *testing.T) {
+	dep := func(name, condition string) *ecs.ContainerDependency {
+		return &ecs.ContainerDependency{ContainerName: aws.String(name), Condition: aws.String(condition)}
+	}
+
+	t.Run("BuildsDependenciesInOrder", func(t *testing.T) {
+		b := NewDependencyBuilder().
+			DependsOnStart("log_router").
+			DependsOnHealthy("db").
+			DependsOnSuccess("migrate")
+		assert.NoError(t, b.Validate())
+		assert.Equal(t, []*ecs.ContainerDependency{
+			dep("log_router", ecs.ContainerConditionStart),
+			dep("db", ecs.ContainerConditionHealthy),
+			dep("migrate", ecs.ContainerConditionSuccess),
+		}, b.Build())
+	})
+	t.Run("DependingOnContainerAgainReplacesCondition", func(t *testing.T) {
+		b := NewDependencyBuilder().DependsOnStart("db").DependsOnHealthy("db")
+		assert.NoError(t, b.Validate())
+		assert.Equal(t, []*ecs.ContainerDependency{dep("db", ecs.ContainerConditionHealthy)}, b.Build())
+	})
+	t.Run("BuildsDependencyWithGenericCondition", func(t *testing.T) {
+		b := NewDependencyBuilder().DependsOn("init", ecs.ContainerConditionComplete)
+		assert.NoError(t, b.Validate())
+		assert.Equal(t, []*ecs.ContainerDependency{dep("init", ecs.ContainerConditionComplete)}, b.Build())
+	})
+	t.Run("BuildDoesNotShareDependenciesWithBuilder", func(t *testing.T) {
+		b := NewDependencyBuilder().DependsOnStart("db")
+		deps := b.Build()
+		b.DependsOnHealthy("db")
+		assert.Equal(t, ecs.ContainerConditionStart, aws.StringValue(deps[0].Condition))
+	})
+	t.Run("BuildsNothingWhenEmpty", func(t *testing.T) {
+		b := NewDependencyBuilder()
+		assert.NoError(t, b.Validate())
+		assert.Empty(t, b.Build())
+	})
+	t.Run("FailsWithEmptyContainerName", func(t *testing.T) {
+		assert.Error(t, NewDependencyBuilder().DependsOnStart("").Validate())
+	})
+	t.Run("FailsWithUnknownCondition", func(t *testing.T) {
+		assert.Error(t, NewDependencyBuilder().DependsOn("db", "READY").Validate())
+	})
+}
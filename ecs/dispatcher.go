@@ -0,0 +1,123 @@
+package ecs
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/pkg/errors"
+)
+
+// MultiClusterECSDispatcher runs tasks across multiple clusters. Each task is
+// run in a cluster chosen randomly in proportion to the clusters' weights. It
+// is safe for concurrent use if its client is safe for concurrent use.
+type MultiClusterECSDispatcher struct {
+	client cocoa.ECSClient
+
+	mu          sync.Mutex
+	clusters    []dispatchCluster
+	totalWeight int
+	rand        *rand.Rand
+}
+
+// dispatchCluster is a cluster that tasks can be dispatched to.
+type dispatchCluster struct {
+	name   string
+	arn    string
+	weight int
+}
+
+// NewMultiClusterECSDispatcher returns a new dispatcher that runs tasks using
+// the given client. Clusters must be added before it can run tasks.
+func NewMultiClusterECSDispatcher(c cocoa.ECSClient) (*MultiClusterECSDispatcher, error) {
+	if c == nil {
+		return nil, errors.New("missing client")
+	}
+	return &MultiClusterECSDispatcher{
+		client: c,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// AddCluster adds a cluster that tasks can be dispatched to. Tasks are run in
+// the cluster by its ARN if it is given, or by its name otherwise. The weight
+// determines how often tasks are run in the cluster relative to the other
+// clusters and must be positive.
+func (d *MultiClusterECSDispatcher) AddCluster(name, arn string, weight int) error {
+	if name == "" {
+		return errors.New("must specify a cluster name")
+	}
+	if weight <= 0 {
+		return errors.Errorf("weight for cluster '%s' must be positive", name)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, c := range d.clusters {
+		if c.name == name {
+			return errors.Errorf("cluster '%s' has already been added", name)
+		}
+	}
+	d.clusters = append(d.clusters, dispatchCluster{
+		name:   name,
+		arn:    arn,
+		weight: weight,
+	})
+	d.totalWeight += weight
+
+	return nil
+}
+
+// RunTask runs the task in a randomly selected cluster and returns the output
+// along with the name of the cluster that the task was run in. Any cluster
+// specified in the input is ignored; the input itself is not modified.
+func (d *MultiClusterECSDispatcher) RunTask(ctx context.Context, in *ecs.RunTaskInput) (*ecs.RunTaskOutput, string, error) {
+	if in == nil {
+		return nil, "", errors.New("cannot run task with nil input")
+	}
+
+	cluster, err := d.selectCluster()
+	if err != nil {
+		return nil, "", err
+	}
+
+	target := cluster.arn
+	if target == "" {
+		target = cluster.name
+	}
+	dispatchIn := *in
+	dispatchIn.Cluster = aws.String(target)
+
+	out, err := d.client.RunTask(ctx, &dispatchIn)
+	if err != nil {
+		return nil, cluster.name, errors.Wrapf(err, "running task in cluster '%s'", cluster.name)
+	}
+
+	return out, cluster.name, nil
+}
+
+// selectCluster selects a cluster randomly in proportion to the clusters'
+// weights.
+func (d *MultiClusterECSDispatcher) selectCluster() (dispatchCluster, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.clusters) == 0 {
+		return dispatchCluster{}, errors.New("no clusters have been added")
+	}
+
+	n := d.rand.Intn(d.totalWeight)
+	for _, c := range d.clusters {
+		if n < c.weight {
+			return c, nil
+		}
+		n -= c.weight
+	}
+
+	return d.clusters[len(d.clusters)-1], nil
+}
@@ -0,0 +1,72 @@
+package ecs
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiClusterECSDispatcher(t *testing.T) {
+	t.Run("NewFailsWithoutClient", func(t *testing.T) {
+		d, err := NewMultiClusterECSDispatcher(nil)
+		assert.Error(t, err)
+		assert.Zero(t, d)
+	})
+	t.Run("AddCluster", func(t *testing.T) {
+		t.Run("Succeeds", func(t *testing.T) {
+			d, err := NewMultiClusterECSDispatcher(&BasicClient{})
+			require.NoError(t, err)
+			require.NoError(t, d.AddCluster("cluster0", "arn0", 1))
+			require.NoError(t, d.AddCluster("cluster1", "", 2))
+			assert.Len(t, d.clusters, 2)
+			assert.Equal(t, 3, d.totalWeight)
+		})
+		t.Run("FailsWithoutName", func(t *testing.T) {
+			d, err := NewMultiClusterECSDispatcher(&BasicClient{})
+			require.NoError(t, err)
+			assert.Error(t, d.AddCluster("", "arn", 1))
+			assert.Empty(t, d.clusters)
+		})
+		t.Run("FailsWithNonPositiveWeight", func(t *testing.T) {
+			d, err := NewMultiClusterECSDispatcher(&BasicClient{})
+			require.NoError(t, err)
+			assert.Error(t, d.AddCluster("cluster", "arn", 0))
+			assert.Error(t, d.AddCluster("cluster", "arn", -1))
+			assert.Empty(t, d.clusters)
+		})
+		t.Run("FailsWithDuplicateName", func(t *testing.T) {
+			d, err := NewMultiClusterECSDispatcher(&BasicClient{})
+			require.NoError(t, err)
+			require.NoError(t, d.AddCluster("cluster", "arn0", 1))
+			assert.Error(t, d.AddCluster("cluster", "arn1", 1))
+			assert.Len(t, d.clusters, 1)
+		})
+	})
+	t.Run("SelectCluster", func(t *testing.T) {
+		t.Run("FailsWithoutClusters", func(t *testing.T) {
+			d, err := NewMultiClusterECSDispatcher(&BasicClient{})
+			require.NoError(t, err)
+			_, err = d.selectCluster()
+			assert.Error(t, err)
+		})
+		t.Run("SelectsClustersInProportionToWeight", func(t *testing.T) {
+			d, err := NewMultiClusterECSDispatcher(&BasicClient{})
+			require.NoError(t, err)
+			d.rand = rand.New(rand.NewSource(0))
+			require.NoError(t, d.AddCluster("light", "", 1))
+			require.NoError(t, d.AddCluster("heavy", "", 3))
+
+			counts := map[string]int{}
+			const numSelections = 4000
+			for i := 0; i < numSelections; i++ {
+				c, err := d.selectCluster()
+				require.NoError(t, err)
+				counts[c.name]++
+			}
+			assert.InDelta(t, numSelections/4, counts["light"], numSelections/20)
+			assert.InDelta(t, 3*numSelections/4, counts["heavy"], numSelections/20)
+		})
+	})
+}
@@ -0,0 +1,102 @@
+package ecs
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// EnvBuilder builds the environment variables of a container. Variables are
+// kept by name, so setting a variable again replaces its value, and the built
+// variables are sorted by name so that the output is deterministic.
+type EnvBuilder struct {
+	vars  map[string]string
+	unset map[string]struct{}
+}
+
+// NewEnvBuilder returns a new builder for container environment variables.
+func NewEnvBuilder() *EnvBuilder {
+	return &EnvBuilder{
+		vars:  map[string]string{},
+		unset: map[string]struct{}{},
+	}
+}
+
+// Set sets the environment variable to the value.
+func (b *EnvBuilder) Set(key, value string) *EnvBuilder {
+	b.vars[key] = value
+	delete(b.unset, key)
+	return b
+}
+
+// SetFrom sets all the environment variables in the map.
+func (b *EnvBuilder) SetFrom(env map[string]string) *EnvBuilder {
+	for key, value := range env {
+		b.Set(key, value)
+	}
+	return b
+}
+
+// Merge sets all the environment variables from the other builder, replacing
+// any existing values, and unsets the variables that the other builder unsets.
+func (b *EnvBuilder) Merge(other *EnvBuilder) *EnvBuilder {
+	if other == nil {
+		return b
+	}
+	for key := range other.unset {
+		b.Unset(key)
+	}
+	return b.SetFrom(other.vars)
+}
+
+// Unset removes the environment variable. If the variables are used as
+// container overrides, the variable is also cleared (see BuildAsOverrides).
+func (b *EnvBuilder) Unset(key string) *EnvBuilder {
+	delete(b.vars, key)
+	b.unset[key] = struct{}{}
+	return b
+}
+
+// Build returns the environment variables for a container definition. Unset
+// variables are omitted.
+func (b *EnvBuilder) Build() []*ecs.KeyValuePair {
+	return exportKeyValuePairs(b.vars)
+}
+
+// BuildAsOverrides returns the environment variables for a container override
+// when running a task. Container overrides can only add to or replace the
+// variables in the task definition, so unset variables are included with an
+// empty value to clear any value that the task definition sets for them.
+func (b *EnvBuilder) BuildAsOverrides() []*ecs.KeyValuePair {
+	vars := make(map[string]string, len(b.vars)+len(b.unset))
+	for key := range b.unset {
+		vars[key] = ""
+	}
+	for key, value := range b.vars {
+		vars[key] = value
+	}
+	return exportKeyValuePairs(vars)
+}
+
+// exportKeyValuePairs converts the map into ECS key-value pairs sorted by key.
+func exportKeyValuePairs(vars map[string]string) []*ecs.KeyValuePair {
+	if len(vars) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]*ecs.KeyValuePair, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, &ecs.KeyValuePair{
+			Name:  aws.String(key),
+			Value: aws.String(vars[key]),
+		})
+	}
+	return pairs
+}
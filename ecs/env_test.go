@@ -0,0 +1,56 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvBuilder(t *testing.T) {
+	pair := func(name, value string) *ecs.KeyValuePair {
+		return &ecs.KeyValuePair{Name: aws.String(name), Value: aws.String(value)}
+	}
+
+	t.Run("BuildsSortedVariables", func(t *testing.T) {
+		env := NewEnvBuilder().Set("B", "2").Set("A", "1").Build()
+		assert.Equal(t, []*ecs.KeyValuePair{pair("A", "1"), pair("B", "2")}, env)
+	})
+	t.Run("SetReplacesExistingValue", func(t *testing.T) {
+		env := NewEnvBuilder().Set("A", "1").Set("A", "2").Build()
+		assert.Equal(t, []*ecs.KeyValuePair{pair("A", "2")}, env)
+	})
+	t.Run("SetFromSetsAllVariables", func(t *testing.T) {
+		env := NewEnvBuilder().Set("A", "0").SetFrom(map[string]string{"A": "1", "B": "2"}).Build()
+		assert.Equal(t, []*ecs.KeyValuePair{pair("A", "1"), pair("B", "2")}, env)
+	})
+	t.Run("UnsetRemovesVariable", func(t *testing.T) {
+		env := NewEnvBuilder().SetFrom(map[string]string{"A": "1", "B": "2"}).Unset("A").Build()
+		assert.Equal(t, []*ecs.KeyValuePair{pair("B", "2")}, env)
+	})
+	t.Run("SetAfterUnsetRestoresVariable", func(t *testing.T) {
+		b := NewEnvBuilder().Unset("A").Set("A", "1")
+		assert.Equal(t, []*ecs.KeyValuePair{pair("A", "1")}, b.Build())
+		assert.Equal(t, []*ecs.KeyValuePair{pair("A", "1")}, b.BuildAsOverrides())
+	})
+	t.Run("MergeAppliesOtherBuilder", func(t *testing.T) {
+		other := NewEnvBuilder().Set("B", "3").Set("C", "4").Unset("A")
+		b := NewEnvBuilder().SetFrom(map[string]string{"A": "1", "B": "2"}).Merge(other)
+		assert.Equal(t, []*ecs.KeyValuePair{pair("B", "3"), pair("C", "4")}, b.Build())
+		assert.Equal(t, []*ecs.KeyValuePair{pair("A", ""), pair("B", "3"), pair("C", "4")}, b.BuildAsOverrides())
+	})
+	t.Run("MergeIgnoresNilBuilder", func(t *testing.T) {
+		env := NewEnvBuilder().Set("A", "1").Merge(nil).Build()
+		assert.Equal(t, []*ecs.KeyValuePair{pair("A", "1")}, env)
+	})
+	t.Run("BuildAsOverridesClearsUnsetVariables", func(t *testing.T) {
+		env := NewEnvBuilder().Set("B", "2").Unset("A").BuildAsOverrides()
+		assert.Equal(t, []*ecs.KeyValuePair{pair("A", ""), pair("B", "2")}, env)
+	})
+	t.Run("BuildsNothingWhenEmpty", func(t *testing.T) {
+		b := NewEnvBuilder()
+		assert.Empty(t, b.Build())
+		assert.Empty(t, b.BuildAsOverrides())
+	})
+}
@@ -0,0 +1,213 @@
+package ecs
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CapacityProviderFargate is the name of the capacity provider for
+	// on-demand Fargate tasks.
+	CapacityProviderFargate = "FARGATE"
+	// CapacityProviderFargateSpot is the name of the capacity provider for
+	// Fargate Spot tasks, which run on spare capacity and may be interrupted.
+	CapacityProviderFargateSpot = "FARGATE_SPOT"
+)
+
+// FargateSpotStrategy returns a capacity provider strategy that splits tasks
+// between Fargate Spot and on-demand Fargate. The base is the minimum number of
+// tasks to run on Fargate Spot before distributing the rest, and the weight is
+// the share of the remaining tasks to run on Fargate Spot relative to
+// on-demand Fargate, which has a weight of 1. For example, a weight of 3 runs
+// roughly three tasks on Fargate Spot for every task on on-demand Fargate.
+// ECS rejects a base outside of 0-100000 or a weight outside of 0-1000.
+//
+// The split is fixed by the weights: ECS does not fall back to on-demand
+// Fargate when Fargate Spot capacity is unavailable, so tasks assigned to
+// Fargate Spot stay pending until Spot capacity frees up. Callers that need
+// the tasks to run must retry them with FargateOnDemandStrategy.
+func FargateSpotStrategy(base, weight int) []*ecs.CapacityProviderStrategyItem {
+	return []*ecs.CapacityProviderStrategyItem{
+		{
+			CapacityProvider: aws.String(CapacityProviderFargateSpot),
+			Base:             aws.Int64(int64(base)),
+			Weight:           aws.Int64(int64(weight)),
+		},
+		{
+			CapacityProvider: aws.String(CapacityProviderFargate),
+			Weight:           aws.Int64(1),
+		},
+	}
+}
+
+// FargateOnDemandStrategy returns a capacity provider strategy that runs all
+// tasks on on-demand Fargate.
+func FargateOnDemandStrategy() []*ecs.CapacityProviderStrategyItem {
+	return []*ecs.CapacityProviderStrategyItem{
+		{
+			CapacityProvider: aws.String(CapacityProviderFargate),
+			Weight:           aws.Int64(1),
+		},
+	}
+}
+
+// Priority represents how important it is for a task to run without
+// interruption.
+type Priority string
+
+// Constants representing task priorities.
+const (
+	// PriorityLow indicates that the task can tolerate interruptions.
+	PriorityLow Priority = "low"
+	// PriorityMedium indicates that the task can tolerate some interruptions.
+	PriorityMedium Priority = "medium"
+	// PriorityHigh indicates that the task should not be interrupted.
+	PriorityHigh Priority = "high"
+	// PriorityCritical indicates that the task must not be interrupted.
+	PriorityCritical Priority = "critical"
+)
+
+// SelectCapacityProviderStrategy returns the Fargate capacity provider
+// strategy for a task with the given priority. The priorities map to
+// strategies as follows:
+//   - PriorityLow runs 100% of tasks on Fargate Spot.
+//   - PriorityMedium runs 50% of tasks on Fargate Spot and 50% on on-demand
+//     Fargate.
+//   - PriorityHigh and PriorityCritical run 100% of tasks on on-demand
+//     Fargate, since Fargate Spot tasks may be interrupted.
+//
+// Any other priority is treated as PriorityHigh so that tasks are not
+// unexpectedly interrupted.
+func SelectCapacityProviderStrategy(taskPriority Priority) []*ecs.CapacityProviderStrategyItem {
+	switch taskPriority {
+	case PriorityLow:
+		return []*ecs.CapacityProviderStrategyItem{
+			{
+				CapacityProvider: aws.String(CapacityProviderFargateSpot),
+				Weight:           aws.Int64(1),
+			},
+		}
+	case PriorityMedium:
+		return FargateSpotStrategy(0, 1)
+	default:
+		return FargateOnDemandStrategy()
+	}
+}
+
+// fargateMemoryRange is the range of memory values (in MiB) that Fargate
+// supports for a given amount of CPU.
+type fargateMemoryRange struct {
+	// values are the exact memory values that are supported. If this is set,
+	// the min, max and step are ignored.
+	values   []int64
+	min, max int64
+	step     int64
+}
+
+// validValues returns all the memory values in the range.
+func (r fargateMemoryRange) validValues() []int64 {
+	if len(r.values) != 0 {
+		return r.values
+	}
+	var vals []int64
+	for mem := r.min; mem <= r.max; mem += r.step {
+		vals = append(vals, mem)
+	}
+	return vals
+}
+
+// contains returns whether or not the memory value is in the range.
+func (r fargateMemoryRange) contains(memoryMiB int64) bool {
+	if len(r.values) != 0 {
+		for _, v := range r.values {
+			if v == memoryMiB {
+				return true
+			}
+		}
+		return false
+	}
+	return memoryMiB >= r.min && memoryMiB <= r.max && (memoryMiB-r.min)%r.step == 0
+}
+
+// fargateResources maps each CPU value (in CPU units) that Fargate supports
+// to the memory values (in MiB) that it supports for that amount of CPU.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/task_definition_parameters.html#task_size
+var fargateResources = map[int64]fargateMemoryRange{
+	256:   {values: []int64{512, 1024, 2048}},
+	512:   {min: 1024, max: 4096, step: 1024},
+	1024:  {min: 2048, max: 8192, step: 1024},
+	2048:  {min: 4096, max: 16384, step: 1024},
+	4096:  {min: 8192, max: 30720, step: 1024},
+	8192:  {min: 16384, max: 61440, step: 4096},
+	16384: {min: 32768, max: 122880, step: 8192},
+}
+
+// ValidateFargateResources checks that the task-level CPU (in CPU units) and
+// memory (in MiB) are a combination that Fargate supports. For example, 256
+// CPU units (0.25 vCPU) supports 512, 1024 or 2048 MiB of memory. If the
+// combination is invalid, the error lists the valid options.
+func ValidateFargateResources(cpu, memoryMiB int64) error {
+	memRange, ok := fargateResources[cpu]
+	if !ok {
+		cpus := make([]int64, 0, len(fargateResources))
+		for c := range fargateResources {
+			cpus = append(cpus, c)
+		}
+		sort.Slice(cpus, func(i, j int) bool { return cpus[i] < cpus[j] })
+		return errors.Errorf("Fargate does not support %d CPU units, valid CPU values are: %s", cpu, joinInts(cpus))
+	}
+	if !memRange.contains(memoryMiB) {
+		return errors.Errorf("Fargate does not support %d MiB of memory with %d CPU units, valid memory values are: %s", memoryMiB, cpu, joinInts(memRange.validValues()))
+	}
+	return nil
+}
+
+// validateFargateTaskDefinitionResources checks that the CPU and memory of a
+// task definition are a combination that Fargate supports. Task definitions
+// can specify CPU either in CPU units (e.g. "1024") or in vCPUs (e.g. "1
+// vCPU") and memory either in MiB (e.g. "2048") or in GB (e.g. "2 GB").
+func validateFargateTaskDefinitionResources(cpu, memory string) error {
+	cpuUnits, err := parseTaskDefinitionResource(cpu, "vcpu")
+	if err != nil {
+		return errors.Wrapf(err, "parsing CPU '%s'", cpu)
+	}
+	memoryMiB, err := parseTaskDefinitionResource(memory, "gb")
+	if err != nil {
+		return errors.Wrapf(err, "parsing memory '%s'", memory)
+	}
+	return ValidateFargateResources(cpuUnits, memoryMiB)
+}
+
+// parseTaskDefinitionResource parses a task definition CPU or memory value,
+// which is either an integer or a number followed by the unit. Values with the
+// unit are converted by multiplying by 1024 (e.g. 1 vCPU is 1024 CPU units and
+// 1 GB is 1024 MiB).
+func parseTaskDefinitionResource(val, unit string) (int64, error) {
+	val = strings.TrimSpace(val)
+	if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return n, nil
+	}
+
+	lower := strings.ToLower(val)
+	if !strings.HasSuffix(lower, unit) {
+		return 0, errors.Errorf("must be an integer or a number followed by '%s'", unit)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(lower, unit)), 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "parsing number")
+	}
+	return int64(f * 1024), nil
+}
+
+func joinInts(vals []int64) string {
+	strs := make([]string, 0, len(vals))
+	for _, v := range vals {
+		strs = append(strs, strconv.FormatInt(v, 10))
+	}
+	return strings.Join(strs, ", ")
+}
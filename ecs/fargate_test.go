@@ -0,0 +1,103 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFargateSpotStrategy(t *testing.T) {
+	strategy := FargateSpotStrategy(2, 3)
+	require.Len(t, strategy, 2)
+
+	assert.Equal(t, CapacityProviderFargateSpot, utility.FromStringPtr(strategy[0].CapacityProvider))
+	assert.EqualValues(t, 2, utility.FromInt64Ptr(strategy[0].Base))
+	assert.EqualValues(t, 3, utility.FromInt64Ptr(strategy[0].Weight))
+
+	assert.Equal(t, CapacityProviderFargate, utility.FromStringPtr(strategy[1].CapacityProvider))
+	assert.Zero(t, strategy[1].Base, "only one capacity provider in a strategy can have a base")
+	assert.EqualValues(t, 1, utility.FromInt64Ptr(strategy[1].Weight))
+
+	in := &ecs.RunTaskInput{}
+	in.SetCapacityProviderStrategy(strategy)
+	for _, item := range in.CapacityProviderStrategy {
+		assert.NoError(t, item.Validate())
+	}
+}
+
+func TestFargateOnDemandStrategy(t *testing.T) {
+	strategy := FargateOnDemandStrategy()
+	require.Len(t, strategy, 1)
+	assert.Equal(t, CapacityProviderFargate, utility.FromStringPtr(strategy[0].CapacityProvider))
+	assert.EqualValues(t, 1, utility.FromInt64Ptr(strategy[0].Weight))
+	assert.NoError(t, strategy[0].Validate())
+	assert.Nil(t, strategy[0].Base)
+}
+
+func TestSelectCapacityProviderStrategy(t *testing.T) {
+	// weights returns the weight of each capacity provider in the strategy.
+	weights := func(t *testing.T, strategy []*ecs.CapacityProviderStrategyItem) map[string]int64 {
+		w := map[string]int64{}
+		for _, item := range strategy {
+			require.NotZero(t, item)
+			assert.NoError(t, item.Validate())
+			assert.Zero(t, utility.FromInt64Ptr(item.Base))
+			w[utility.FromStringPtr(item.CapacityProvider)] = utility.FromInt64Ptr(item.Weight)
+		}
+		return w
+	}
+
+	t.Run("LowPriorityRunsOnSpot", func(t *testing.T) {
+		assert.Equal(t, map[string]int64{CapacityProviderFargateSpot: 1}, weights(t, SelectCapacityProviderStrategy(PriorityLow)))
+	})
+	t.Run("MediumPrioritySplitsEvenly", func(t *testing.T) {
+		assert.Equal(t, map[string]int64{
+			CapacityProviderFargateSpot: 1,
+			CapacityProviderFargate:     1,
+		}, weights(t, SelectCapacityProviderStrategy(PriorityMedium)))
+	})
+	t.Run("HighPriorityRunsOnDemand", func(t *testing.T) {
+		assert.Equal(t, map[string]int64{CapacityProviderFargate: 1}, weights(t, SelectCapacityProviderStrategy(PriorityHigh)))
+	})
+	t.Run("CriticalPriorityRunsOnDemand", func(t *testing.T) {
+		assert.Equal(t, map[string]int64{CapacityProviderFargate: 1}, weights(t, SelectCapacityProviderStrategy(PriorityCritical)))
+	})
+	t.Run("UnknownPriorityRunsOnDemand", func(t *testing.T) {
+		assert.Equal(t, map[string]int64{CapacityProviderFargate: 1}, weights(t, SelectCapacityProviderStrategy("unknown")))
+	})
+}
+
+func TestValidateFargateResources(t *testing.T) {
+	t.Run("SucceedsWithValidCombinations", func(t *testing.T) {
+		for _, combo := range [][2]int64{
+			{256, 512},
+			{256, 2048},
+			{512, 3072},
+			{1024, 8192},
+			{2048, 4096},
+			{4096, 30720},
+			{8192, 20480},
+			{16384, 122880},
+		} {
+			assert.NoError(t, ValidateFargateResources(combo[0], combo[1]), "CPU %d, memory %d", combo[0], combo[1])
+		}
+	})
+	t.Run("FailsWithUnsupportedCPU", func(t *testing.T) {
+		err := ValidateFargateResources(128, 512)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "256, 512, 1024, 2048, 4096, 8192, 16384")
+	})
+	t.Run("FailsWithMemoryOutOfRange", func(t *testing.T) {
+		err := ValidateFargateResources(256, 4096)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "512, 1024, 2048")
+	})
+	t.Run("FailsWithMemoryBetweenIncrements", func(t *testing.T) {
+		assert.Error(t, ValidateFargateResources(256, 1536))
+		assert.Error(t, ValidateFargateResources(512, 1500))
+		assert.Error(t, ValidateFargateResources(8192, 17408))
+	})
+}
@@ -0,0 +1,246 @@
+package ecs
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// Options for the FireLens configuration.
+const (
+	// FirelensOptionEnableECSLogMetadata is the FireLens option to include
+	// the task and container metadata in the logs.
+	FirelensOptionEnableECSLogMetadata = "enable-ecs-log-metadata"
+	// FirelensOptionConfigFileType is the FireLens option for the location of
+	// a custom log router configuration file, which is either "s3" or "file".
+	FirelensOptionConfigFileType = "config-file-type"
+	// FirelensOptionConfigFileValue is the FireLens option for the S3 ARN or
+	// file path of a custom log router configuration file.
+	FirelensOptionConfigFileValue = "config-file-value"
+)
+
+// defaultFirelensRouterName is the name of the log router container created
+// by FirelensRouterContainer.
+const defaultFirelensRouterName = "log_router"
+
+// FirelensConfigBuilder builds the FireLens configuration of a log router
+// container, which determines the log router and how it is configured. To
+// build the log router container along with its configuration, use
+// NewFirelensRouterContainer instead.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/APIReference/API_FirelensConfiguration.html
+type FirelensConfigBuilder struct {
+	firelensType string
+	options      map[string]string
+}
+
+// NewFirelensConfigBuilder returns a new builder for a FireLens configuration.
+// By default, the log router uses Fluent Bit.
+func NewFirelensConfigBuilder() *FirelensConfigBuilder {
+	return &FirelensConfigBuilder{
+		firelensType: ecs.FirelensConfigurationTypeFluentbit,
+	}
+}
+
+// WithType sets the type of log router, which must be either "fluentbit" or
+// "fluentd".
+func (b *FirelensConfigBuilder) WithType(t string) *FirelensConfigBuilder {
+	b.firelensType = t
+	return b
+}
+
+// ForFluentBit makes the log router use Fluent Bit.
+func (b *FirelensConfigBuilder) ForFluentBit() *FirelensConfigBuilder {
+	return b.WithType(ecs.FirelensConfigurationTypeFluentbit)
+}
+
+// ForFluentd makes the log router use Fluentd.
+func (b *FirelensConfigBuilder) ForFluentd() *FirelensConfigBuilder {
+	return b.WithType(ecs.FirelensConfigurationTypeFluentd)
+}
+
+// WithOptions sets the options to configure the log router, such as a custom
+// configuration file to route logs to Kinesis, S3 or a third-party service.
+func (b *FirelensConfigBuilder) WithOptions(opts map[string]string) *FirelensConfigBuilder {
+	b.options = opts
+	return b
+}
+
+// Validate checks that the FireLens type is valid and that the options are
+// known FireLens options with valid values.
+func (b *FirelensConfigBuilder) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	catcher.Add(validateFirelensType(b.firelensType))
+
+	keys := make([]string, 0, len(b.options))
+	for key := range b.options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		val := b.options[key]
+		switch key {
+		case FirelensOptionEnableECSLogMetadata:
+			catcher.ErrorfWhen(val != "true" && val != "false", "option '%s' must be 'true' or 'false'", key)
+		case FirelensOptionConfigFileType:
+			catcher.ErrorfWhen(val != "s3" && val != "file", "option '%s' must be 's3' or 'file'", key)
+		case FirelensOptionConfigFileValue:
+			catcher.ErrorfWhen(val == "", "option '%s' cannot be empty", key)
+		default:
+			catcher.Errorf("unknown FireLens option '%s'", key)
+		}
+	}
+	_, hasFileType := b.options[FirelensOptionConfigFileType]
+	_, hasFileValue := b.options[FirelensOptionConfigFileValue]
+	catcher.ErrorfWhen(hasFileType != hasFileValue, "options '%s' and '%s' must be specified together", FirelensOptionConfigFileType, FirelensOptionConfigFileValue)
+
+	return catcher.Resolve()
+}
+
+// validateFirelensType checks that the FireLens type is either "fluentbit" or
+// "fluentd".
+func validateFirelensType(firelensType string) error {
+	if firelensType != ecs.FirelensConfigurationTypeFluentbit && firelensType != ecs.FirelensConfigurationTypeFluentd {
+		return errors.Errorf("invalid FireLens type '%s', must be '%s' or '%s'", firelensType, ecs.FirelensConfigurationTypeFluentbit, ecs.FirelensConfigurationTypeFluentd)
+	}
+	return nil
+}
+
+// Build validates the FireLens configuration and returns it.
+func (b *FirelensConfigBuilder) Build() (*ecs.FirelensConfiguration, error) {
+	if err := b.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid FireLens configuration")
+	}
+
+	return b.firelensConfig(), nil
+}
+
+// firelensConfig returns the FireLens configuration without validating it.
+func (b *FirelensConfigBuilder) firelensConfig() *ecs.FirelensConfiguration {
+	cfg := &ecs.FirelensConfiguration{
+		Type: aws.String(b.firelensType),
+	}
+	if len(b.options) != 0 {
+		cfg.SetOptions(aws.StringMap(b.options))
+	}
+	return cfg
+}
+
+// FirelensContainerBuilder builds a FireLens log router sidecar container,
+// which routes the logs of the other containers in the task. Its FireLens
+// configuration is built with a FirelensConfigBuilder.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/using_firelens.html
+type FirelensContainerBuilder struct {
+	name     string
+	image    string
+	config   *FirelensConfigBuilder
+	envVars  map[string]string
+	memoryMB *int64
+}
+
+// NewFirelensRouterContainer returns a new builder for a FireLens log router
+// container with the given name and image. By default, the log router uses
+// Fluent Bit. This is the entry point for a customized log router; for a log
+// router with all the defaults, FirelensRouterContainer is a shorthand.
+func NewFirelensRouterContainer(name, image string) *FirelensContainerBuilder {
+	return &FirelensContainerBuilder{
+		name:   name,
+		image:  image,
+		config: NewFirelensConfigBuilder(),
+	}
+}
+
+// FirelensRouterContainer returns an essential Fluent Bit log router container
+// named "log_router" that runs the image (e.g. the AWS for Fluent Bit image).
+// The other containers in the task can route their logs through it by using
+// the awsfirelens log driver. It is equivalent to building
+// NewFirelensRouterContainer("log_router", image) without validation, so use
+// NewFirelensRouterContainer instead to customize or validate the log router.
+func FirelensRouterContainer(image string) *ecs.ContainerDefinition {
+	b := NewFirelensRouterContainer(defaultFirelensRouterName, image)
+	return b.containerDefinition(b.config.firelensConfig())
+}
+
+// WithFirelensConfig sets the type of log router, which must be either
+// "fluentbit" or "fluentd".
+func (b *FirelensContainerBuilder) WithFirelensConfig(firelensType string) *FirelensContainerBuilder {
+	b.config.WithType(firelensType)
+	return b
+}
+
+// WithFirelensOptions sets the FireLens options to configure the log router.
+// See FirelensConfigBuilder.WithOptions for the available options.
+func (b *FirelensContainerBuilder) WithFirelensOptions(opts map[string]string) *FirelensContainerBuilder {
+	b.config.WithOptions(opts)
+	return b
+}
+
+// WithEnvironment sets the environment variables for the log router container.
+func (b *FirelensContainerBuilder) WithEnvironment(envVars map[string]string) *FirelensContainerBuilder {
+	b.envVars = envVars
+	return b
+}
+
+// WithMemory sets the memory limit (in MiB) for the log router container.
+func (b *FirelensContainerBuilder) WithMemory(mib int64) *FirelensContainerBuilder {
+	b.memoryMB = &mib
+	return b
+}
+
+// Validate checks that the log router container has a name and image, a valid
+// FireLens configuration, valid environment variable names, and a positive
+// memory limit if one is set.
+func (b *FirelensContainerBuilder) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	catcher.NewWhen(b.name == "", "must specify a container name")
+	catcher.NewWhen(b.image == "", "must specify a container image")
+	catcher.Wrap(b.config.Validate(), "invalid FireLens configuration")
+	for name := range b.envVars {
+		catcher.ErrorfWhen(!envVarNameRegexp.MatchString(name), "invalid environment variable name '%s'", name)
+	}
+	catcher.NewWhen(b.memoryMB != nil && *b.memoryMB <= 0, "memory must be positive")
+	return catcher.Resolve()
+}
+
+// Build validates the log router container and returns its container
+// definition along with its FireLens configuration. The FireLens configuration
+// is also set in the container definition.
+func (b *FirelensContainerBuilder) Build() (*ecs.ContainerDefinition, *ecs.FirelensConfiguration, error) {
+	if err := b.Validate(); err != nil {
+		return nil, nil, errors.Wrap(err, "invalid FireLens log router container")
+	}
+
+	firelensConfig := b.config.firelensConfig()
+
+	return b.containerDefinition(firelensConfig), firelensConfig, nil
+}
+
+// containerDefinition returns the log router container definition with the
+// given FireLens configuration without validating it.
+func (b *FirelensContainerBuilder) containerDefinition(firelensConfig *ecs.FirelensConfiguration) *ecs.ContainerDefinition {
+	names := make([]string, 0, len(b.envVars))
+	for name := range b.envVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var env []*ecs.KeyValuePair
+	for _, name := range names {
+		env = append(env, &ecs.KeyValuePair{
+			Name:  aws.String(name),
+			Value: aws.String(b.envVars[name]),
+		})
+	}
+
+	return &ecs.ContainerDefinition{
+		Name:  aws.String(b.name),
+		Image: aws.String(b.image),
+		// The log router must be essential so that the task does not keep
+		// running without routing its logs.
+		Essential:             aws.Bool(true),
+		Environment:           env,
+		Memory:                b.memoryMB,
+		FirelensConfiguration: firelensConfig,
+	}
+}
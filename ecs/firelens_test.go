@@ -0,0 +1,154 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirelensContainerBuilder(t *testing.T) {
+	t.Run("BuildsFluentBitContainerByDefault", func(t *testing.T) {
+		def, firelensConfig, err := NewFirelensRouterContainer("log_router", "amazon/aws-for-fluent-bit").Build()
+		require.NoError(t, err)
+		require.NotZero(t, def)
+		require.NotZero(t, firelensConfig)
+
+		assert.Equal(t, ecs.FirelensConfigurationTypeFluentbit, utility.FromStringPtr(firelensConfig.Type))
+		assert.Equal(t, "log_router", utility.FromStringPtr(def.Name))
+		assert.Equal(t, "amazon/aws-for-fluent-bit", utility.FromStringPtr(def.Image))
+		assert.True(t, utility.FromBoolPtr(def.Essential))
+		assert.Equal(t, firelensConfig, def.FirelensConfiguration)
+		assert.Zero(t, def.Memory)
+		assert.Empty(t, def.Environment)
+	})
+	t.Run("BuildsContainerWithAllOptions", func(t *testing.T) {
+		def, firelensConfig, err := NewFirelensRouterContainer("log_router", "fluent/fluentd").
+			WithFirelensConfig(ecs.FirelensConfigurationTypeFluentd).
+			WithEnvironment(map[string]string{"B": "2", "A": "1"}).
+			WithMemory(128).
+			Build()
+		require.NoError(t, err)
+		require.NotZero(t, def)
+		require.NotZero(t, firelensConfig)
+
+		assert.Equal(t, ecs.FirelensConfigurationTypeFluentd, utility.FromStringPtr(firelensConfig.Type))
+		assert.EqualValues(t, 128, utility.FromInt64Ptr(def.Memory))
+		assert.Equal(t, []*ecs.KeyValuePair{
+			{Name: aws.String("A"), Value: aws.String("1")},
+			{Name: aws.String("B"), Value: aws.String("2")},
+		}, def.Environment)
+	})
+	t.Run("BuildsContainerWithFirelensOptions", func(t *testing.T) {
+		opts := map[string]string{FirelensOptionEnableECSLogMetadata: "false"}
+		def, firelensConfig, err := NewFirelensRouterContainer("log_router", "amazon/aws-for-fluent-bit").
+			WithFirelensOptions(opts).
+			Build()
+		require.NoError(t, err)
+		require.NotZero(t, def)
+		require.NotZero(t, firelensConfig)
+
+		assert.Equal(t, aws.StringMap(opts), firelensConfig.Options)
+		assert.Equal(t, firelensConfig, def.FirelensConfiguration)
+	})
+	t.Run("FailsWithoutName", func(t *testing.T) {
+		def, firelensConfig, err := NewFirelensRouterContainer("", "image").Build()
+		assert.Error(t, err)
+		assert.Zero(t, def)
+		assert.Zero(t, firelensConfig)
+	})
+	t.Run("FailsWithoutImage", func(t *testing.T) {
+		def, firelensConfig, err := NewFirelensRouterContainer("name", "").Build()
+		assert.Error(t, err)
+		assert.Zero(t, def)
+		assert.Zero(t, firelensConfig)
+	})
+	t.Run("FailsWithInvalidFirelensType", func(t *testing.T) {
+		def, firelensConfig, err := NewFirelensRouterContainer("name", "image").WithFirelensConfig("logstash").Build()
+		assert.Error(t, err)
+		assert.Zero(t, def)
+		assert.Zero(t, firelensConfig)
+	})
+	t.Run("FailsWithInvalidFirelensOption", func(t *testing.T) {
+		def, firelensConfig, err := NewFirelensRouterContainer("name", "image").WithFirelensOptions(map[string]string{"foo": "bar"}).Build()
+		assert.Error(t, err)
+		assert.Zero(t, def)
+		assert.Zero(t, firelensConfig)
+	})
+	t.Run("FailsWithInvalidEnvironmentVariableName", func(t *testing.T) {
+		def, firelensConfig, err := NewFirelensRouterContainer("name", "image").WithEnvironment(map[string]string{"1INVALID": "value"}).Build()
+		assert.Error(t, err)
+		assert.Zero(t, def)
+		assert.Zero(t, firelensConfig)
+	})
+	t.Run("FailsWithNonPositiveMemory", func(t *testing.T) {
+		def, firelensConfig, err := NewFirelensRouterContainer("name", "image").WithMemory(0).Build()
+		assert.Error(t, err)
+		assert.Zero(t, def)
+		assert.Zero(t, firelensConfig)
+	})
+}
+
+func TestFirelensConfigBuilder(t *testing.T) {
+	t.Run("BuildsFluentBitConfigurationByDefault", func(t *testing.T) {
+		cfg, err := NewFirelensConfigBuilder().Build()
+		require.NoError(t, err)
+		require.NotZero(t, cfg)
+		assert.Equal(t, ecs.FirelensConfigurationTypeFluentbit, utility.FromStringPtr(cfg.Type))
+		assert.Empty(t, cfg.Options)
+	})
+	t.Run("BuildsFluentdConfiguration", func(t *testing.T) {
+		cfg, err := NewFirelensConfigBuilder().ForFluentd().Build()
+		require.NoError(t, err)
+		assert.Equal(t, ecs.FirelensConfigurationTypeFluentd, utility.FromStringPtr(cfg.Type))
+	})
+	t.Run("ForFluentBitOverridesType", func(t *testing.T) {
+		cfg, err := NewFirelensConfigBuilder().WithType(ecs.FirelensConfigurationTypeFluentd).ForFluentBit().Build()
+		require.NoError(t, err)
+		assert.Equal(t, ecs.FirelensConfigurationTypeFluentbit, utility.FromStringPtr(cfg.Type))
+	})
+	t.Run("BuildsConfigurationWithOptions", func(t *testing.T) {
+		opts := map[string]string{
+			FirelensOptionEnableECSLogMetadata: "true",
+			FirelensOptionConfigFileType:       "s3",
+			FirelensOptionConfigFileValue:      "arn:aws:s3:::bucket/fluent-bit.conf",
+		}
+		cfg, err := NewFirelensConfigBuilder().WithOptions(opts).Build()
+		require.NoError(t, err)
+		assert.Equal(t, aws.StringMap(opts), cfg.Options)
+	})
+	t.Run("FailsWithInvalidType", func(t *testing.T) {
+		cfg, err := NewFirelensConfigBuilder().WithType("logstash").Build()
+		assert.Error(t, err)
+		assert.Zero(t, cfg)
+	})
+	t.Run("FailsWithUnknownOption", func(t *testing.T) {
+		_, err := NewFirelensConfigBuilder().WithOptions(map[string]string{"foo": "bar"}).Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithInvalidOptionValue", func(t *testing.T) {
+		_, err := NewFirelensConfigBuilder().WithOptions(map[string]string{FirelensOptionEnableECSLogMetadata: "yes"}).Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithConfigFileTypeWithoutValue", func(t *testing.T) {
+		_, err := NewFirelensConfigBuilder().WithOptions(map[string]string{FirelensOptionConfigFileType: "file"}).Build()
+		assert.Error(t, err)
+	})
+}
+
+func TestFirelensRouterContainer(t *testing.T) {
+	def := FirelensRouterContainer("amazon/aws-for-fluent-bit")
+	require.NotZero(t, def)
+	assert.Equal(t, "log_router", utility.FromStringPtr(def.Name))
+	assert.Equal(t, "amazon/aws-for-fluent-bit", utility.FromStringPtr(def.Image))
+	assert.True(t, utility.FromBoolPtr(def.Essential))
+	require.NotZero(t, def.FirelensConfiguration)
+	assert.Equal(t, ecs.FirelensConfigurationTypeFluentbit, utility.FromStringPtr(def.FirelensConfiguration.Type))
+
+	built, _, err := NewFirelensRouterContainer("log_router", "amazon/aws-for-fluent-bit").Build()
+	require.NoError(t, err)
+	assert.Equal(t, built, def, "should match the default log router container from the builder")
+}
@@ -0,0 +1,120 @@
+package ecs
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// healthCheckCommandExec is the health check command prefix to run the
+	// command directly.
+	healthCheckCommandExec = "CMD"
+	// healthCheckCommandShell is the health check command prefix to run the
+	// command with the container's default shell.
+	healthCheckCommandShell = "CMD-SHELL"
+)
+
+// HealthCheckBuilder builds the health check of a container. By default, it
+// uses the same interval, timeout and retries as ECS (30 seconds, 5 seconds
+// and 3 retries).
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/APIReference/API_HealthCheck.html
+type HealthCheckBuilder struct {
+	command     []string
+	interval    time.Duration
+	timeout     time.Duration
+	retries     int64
+	startPeriod time.Duration
+}
+
+// NewHealthCheckBuilder returns a new builder for a container health check.
+func NewHealthCheckBuilder() *HealthCheckBuilder {
+	return &HealthCheckBuilder{
+		interval: 30 * time.Second,
+		timeout:  5 * time.Second,
+		retries:  3,
+	}
+}
+
+// WithCommand sets the command that checks the container's health. If the
+// command starts with "CMD" or "CMD-SHELL", it is used as-is; otherwise, the
+// command is run directly as if it were prefixed with "CMD".
+func (b *HealthCheckBuilder) WithCommand(cmd ...string) *HealthCheckBuilder {
+	if len(cmd) != 0 && cmd[0] != healthCheckCommandExec && cmd[0] != healthCheckCommandShell {
+		cmd = append([]string{healthCheckCommandExec}, cmd...)
+	}
+	b.command = cmd
+	return b
+}
+
+// WithInterval sets the time between health checks. It must be a whole number
+// of seconds between 5 and 300 seconds.
+func (b *HealthCheckBuilder) WithInterval(d time.Duration) *HealthCheckBuilder {
+	b.interval = d
+	return b
+}
+
+// WithTimeout sets the time to wait for a health check to succeed before it
+// is considered failed. It must be a whole number of seconds between 2 and 60
+// seconds.
+func (b *HealthCheckBuilder) WithTimeout(d time.Duration) *HealthCheckBuilder {
+	b.timeout = d
+	return b
+}
+
+// WithRetries sets the number of consecutive failed health checks before the
+// container is considered unhealthy. It must be between 1 and 10.
+func (b *HealthCheckBuilder) WithRetries(n int64) *HealthCheckBuilder {
+	b.retries = n
+	return b
+}
+
+// WithStartPeriod sets the grace period for the container to start before
+// failed health checks count towards the retries. It must be a whole number
+// of seconds up to 300 seconds.
+func (b *HealthCheckBuilder) WithStartPeriod(d time.Duration) *HealthCheckBuilder {
+	b.startPeriod = d
+	return b
+}
+
+// Validate checks that the command is set, that the durations and retries are
+// within the limits allowed by ECS, and that the interval is longer than the
+// timeout.
+func (b *HealthCheckBuilder) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	catcher.NewWhen(len(b.command) < 2, "must specify a command to run")
+	validateHealthCheckDuration(catcher, "interval", b.interval, 5*time.Second, 300*time.Second)
+	validateHealthCheckDuration(catcher, "timeout", b.timeout, 2*time.Second, 60*time.Second)
+	validateHealthCheckDuration(catcher, "start period", b.startPeriod, 0, 300*time.Second)
+	catcher.ErrorfWhen(b.interval <= b.timeout, "interval %s must be longer than timeout %s", b.interval, b.timeout)
+	catcher.ErrorfWhen(b.retries < 1 || b.retries > 10, "retries %d must be between 1 and 10", b.retries)
+	return catcher.Resolve()
+}
+
+// validateHealthCheckDuration checks that the duration is a whole number of
+// seconds within the range.
+func validateHealthCheckDuration(catcher grip.Catcher, name string, d, min, max time.Duration) {
+	catcher.ErrorfWhen(d%time.Second != 0, "%s %s must be a whole number of seconds", name, d)
+	catcher.ErrorfWhen(d < min || d > max, "%s %s must be between %s and %s", name, d, min, max)
+}
+
+// Build validates the health check and returns it.
+func (b *HealthCheckBuilder) Build() (*ecs.HealthCheck, error) {
+	if err := b.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid health check")
+	}
+
+	hc := &ecs.HealthCheck{
+		Command:  aws.StringSlice(b.command),
+		Interval: aws.Int64(int64(b.interval / time.Second)),
+		Timeout:  aws.Int64(int64(b.timeout / time.Second)),
+		Retries:  aws.Int64(b.retries),
+	}
+	if b.startPeriod > 0 {
+		hc.SetStartPeriod(int64(b.startPeriod / time.Second))
+	}
+	return hc, nil
+}
@@ -0,0 +1,77 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthCheckBuilder(t *testing.T) {
+	t.Run("BuildsHealthCheckWithDefaults", func(t *testing.T) {
+		hc, err := NewHealthCheckBuilder().WithCommand("CMD-SHELL", "curl -f http://localhost/ || exit 1").Build()
+		require.NoError(t, err)
+		require.NotZero(t, hc)
+		assert.Equal(t, []string{"CMD-SHELL", "curl -f http://localhost/ || exit 1"}, aws.StringValueSlice(hc.Command))
+		assert.EqualValues(t, 30, utility.FromInt64Ptr(hc.Interval))
+		assert.EqualValues(t, 5, utility.FromInt64Ptr(hc.Timeout))
+		assert.EqualValues(t, 3, utility.FromInt64Ptr(hc.Retries))
+		assert.Zero(t, hc.StartPeriod)
+	})
+	t.Run("BuildsHealthCheckWithAllFields", func(t *testing.T) {
+		hc, err := NewHealthCheckBuilder().
+			WithCommand("CMD", "/bin/check").
+			WithInterval(time.Minute).
+			WithTimeout(10 * time.Second).
+			WithRetries(5).
+			WithStartPeriod(2 * time.Minute).
+			Build()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CMD", "/bin/check"}, aws.StringValueSlice(hc.Command))
+		assert.EqualValues(t, 60, utility.FromInt64Ptr(hc.Interval))
+		assert.EqualValues(t, 10, utility.FromInt64Ptr(hc.Timeout))
+		assert.EqualValues(t, 5, utility.FromInt64Ptr(hc.Retries))
+		assert.EqualValues(t, 120, utility.FromInt64Ptr(hc.StartPeriod))
+	})
+	t.Run("PrefixesCommandToRunDirectly", func(t *testing.T) {
+		hc, err := NewHealthCheckBuilder().WithCommand("/bin/check", "--quick").Build()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CMD", "/bin/check", "--quick"}, aws.StringValueSlice(hc.Command))
+	})
+	t.Run("FailsWithoutCommand", func(t *testing.T) {
+		hc, err := NewHealthCheckBuilder().Build()
+		assert.Error(t, err)
+		assert.Zero(t, hc)
+	})
+	t.Run("FailsWithOnlyCommandPrefix", func(t *testing.T) {
+		_, err := NewHealthCheckBuilder().WithCommand("CMD-SHELL").Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithIntervalNotLongerThanTimeout", func(t *testing.T) {
+		_, err := NewHealthCheckBuilder().WithCommand("/bin/check").WithInterval(10 * time.Second).WithTimeout(10 * time.Second).Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithZeroRetries", func(t *testing.T) {
+		_, err := NewHealthCheckBuilder().WithCommand("/bin/check").WithRetries(0).Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithTooManyRetries", func(t *testing.T) {
+		_, err := NewHealthCheckBuilder().WithCommand("/bin/check").WithRetries(11).Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithDurationsOutOfRange", func(t *testing.T) {
+		_, err := NewHealthCheckBuilder().WithCommand("/bin/check").WithInterval(10 * time.Minute).Build()
+		assert.Error(t, err)
+		_, err = NewHealthCheckBuilder().WithCommand("/bin/check").WithTimeout(time.Second).Build()
+		assert.Error(t, err)
+		_, err = NewHealthCheckBuilder().WithCommand("/bin/check").WithStartPeriod(-time.Second).Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithFractionalSeconds", func(t *testing.T) {
+		_, err := NewHealthCheckBuilder().WithCommand("/bin/check").WithInterval(7500 * time.Millisecond).Build()
+		assert.Error(t, err)
+	})
+}
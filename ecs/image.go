@@ -0,0 +1,119 @@
+package ecs
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+)
+
+// ecrRegistryPattern matches the registry host of an ECR image URI and
+// captures the registry ID.
+var ecrRegistryPattern = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(-fips)?\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// defaultImageTag is the tag that Docker uses for images that do not specify
+// a tag.
+const defaultImageTag = "latest"
+
+// ecrImage is a parsed ECR image URI.
+type ecrImage struct {
+	// registry is the registry host (e.g.
+	// 123456789012.dkr.ecr.us-east-1.amazonaws.com).
+	registry   string
+	registryID string
+	repo       string
+	tag        string
+	digest     string
+}
+
+// parseECRImage parses an ECR image URI of the form
+// registry/repository[:tag][@digest].
+func parseECRImage(image string) (*ecrImage, error) {
+	slash := strings.Index(image, "/")
+	if slash == -1 {
+		return nil, errors.Errorf("image '%s' is not in ECR because it does not specify a registry", image)
+	}
+	registry := image[:slash]
+	matches := ecrRegistryPattern.FindStringSubmatch(registry)
+	if matches == nil {
+		return nil, errors.Errorf("image '%s' is not in ECR because registry '%s' is not an ECR registry", image, registry)
+	}
+
+	parsed := ecrImage{
+		registry:   registry,
+		registryID: matches[1],
+		repo:       image[slash+1:],
+	}
+	if at := strings.Index(parsed.repo, "@"); at != -1 {
+		parsed.digest = parsed.repo[at+1:]
+		parsed.repo = parsed.repo[:at]
+	}
+	if colon := strings.LastIndex(parsed.repo, ":"); colon != -1 {
+		parsed.tag = parsed.repo[colon+1:]
+		parsed.repo = parsed.repo[:colon]
+	}
+	if parsed.repo == "" {
+		return nil, errors.Errorf("image '%s' does not specify a repository", image)
+	}
+
+	return &parsed, nil
+}
+
+// PinImageDigest resolves the tag of the container definition's ECR image to
+// the digest of the image that it currently refers to. It returns a shallow
+// copy of the container definition with its image set to
+// registry/repository@digest, so that the container always runs the same
+// image even if the tag is later moved to a different image. If the image does
+// not specify a tag, it uses the "latest" tag. If the image is already pinned
+// to a digest, the copy uses the image as-is. This returns an error if the
+// image is not in ECR or the tag cannot be resolved.
+func PinImageDigest(ctx context.Context, ecrc cocoa.ECRClient, containerDef *ecs.ContainerDefinition) (*ecs.ContainerDefinition, error) {
+	if containerDef == nil {
+		return nil, errors.New("cannot pin image for nil container definition")
+	}
+	image := utility.FromStringPtr(containerDef.Image)
+	if image == "" {
+		return nil, errors.New("container definition must specify an image")
+	}
+
+	parsed, err := parseECRImage(image)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := *containerDef
+	if parsed.digest != "" {
+		return &pinned, nil
+	}
+
+	tag := parsed.tag
+	if tag == "" {
+		tag = defaultImageTag
+	}
+
+	out, err := ecrc.BatchGetImage(ctx, &ecr.BatchGetImageInput{
+		RegistryId:     aws.String(parsed.registryID),
+		RepositoryName: aws.String(parsed.repo),
+		ImageIds:       []*ecr.ImageIdentifier{{ImageTag: aws.String(tag)}},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting image '%s' from ECR", image)
+	}
+	if len(out.Failures) > 0 && out.Failures[0] != nil {
+		f := out.Failures[0]
+		return nil, errors.Errorf("getting image '%s' from ECR: %s: %s", image, utility.FromStringPtr(f.FailureCode), utility.FromStringPtr(f.FailureReason))
+	}
+	if len(out.Images) == 0 || out.Images[0] == nil || out.Images[0].ImageId == nil || utility.FromStringPtr(out.Images[0].ImageId.ImageDigest) == "" {
+		return nil, errors.Errorf("ECR did not return a digest for image '%s'", image)
+	}
+
+	pinned.Image = aws.String(parsed.registry + "/" + parsed.repo + "@" + utility.FromStringPtr(out.Images[0].ImageId.ImageDigest))
+
+	return &pinned, nil
+}
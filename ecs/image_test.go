@@ -0,0 +1,52 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseECRImage(t *testing.T) {
+	const registry = "123456789012.dkr.ecr.us-east-1.amazonaws.com"
+
+	t.Run("ParsesImageWithTag", func(t *testing.T) {
+		parsed, err := parseECRImage(registry + "/team/app:v1")
+		require.NoError(t, err)
+		assert.Equal(t, ecrImage{
+			registry:   registry,
+			registryID: "123456789012",
+			repo:       "team/app",
+			tag:        "v1",
+		}, *parsed)
+	})
+	t.Run("ParsesImageWithoutTag", func(t *testing.T) {
+		parsed, err := parseECRImage(registry + "/app")
+		require.NoError(t, err)
+		assert.Equal(t, "app", parsed.repo)
+		assert.Empty(t, parsed.tag)
+	})
+	t.Run("ParsesImageWithDigest", func(t *testing.T) {
+		parsed, err := parseECRImage(registry + "/app@sha256:abc")
+		require.NoError(t, err)
+		assert.Equal(t, "app", parsed.repo)
+		assert.Equal(t, "sha256:abc", parsed.digest)
+	})
+	t.Run("ParsesFIPSRegistry", func(t *testing.T) {
+		parsed, err := parseECRImage("123456789012.dkr.ecr-fips.us-gov-west-1.amazonaws.com/app:v1")
+		require.NoError(t, err)
+		assert.Equal(t, "123456789012", parsed.registryID)
+	})
+	t.Run("FailsWithDockerHubImage", func(t *testing.T) {
+		_, err := parseECRImage("busybox:latest")
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithNonECRRegistry", func(t *testing.T) {
+		_, err := parseECRImage("ghcr.io/org/app:v1")
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithoutRepository", func(t *testing.T) {
+		_, err := parseECRImage(registry + "/:v1")
+		assert.Error(t, err)
+	})
+}
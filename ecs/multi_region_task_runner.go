@@ -0,0 +1,176 @@
+package ecs
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// defaultMultiRegionConcurrency is the default maximum number of regions that
+// tasks are run in concurrently.
+const defaultMultiRegionConcurrency = 5
+
+// MultiRegionTaskRunner runs the same task in multiple AWS regions.
+type MultiRegionTaskRunner struct {
+	clients        map[string]cocoa.ECSClient
+	maxConcurrency int
+}
+
+// NewMultiRegionTaskRunner returns a new runner that runs tasks using the
+// client for each region, keyed by region.
+func NewMultiRegionTaskRunner(clients map[string]cocoa.ECSClient) (*MultiRegionTaskRunner, error) {
+	if len(clients) == 0 {
+		return nil, errors.New("must specify at least one region's client")
+	}
+	catcher := grip.NewBasicCatcher()
+	for region, c := range clients {
+		catcher.NewWhen(region == "", "region cannot be empty")
+		catcher.ErrorfWhen(c == nil, "missing client for region '%s'", region)
+	}
+	if catcher.HasErrors() {
+		return nil, catcher.Resolve()
+	}
+
+	copied := make(map[string]cocoa.ECSClient, len(clients))
+	for region, c := range clients {
+		copied[region] = c
+	}
+
+	return &MultiRegionTaskRunner{
+		clients:        copied,
+		maxConcurrency: defaultMultiRegionConcurrency,
+	}, nil
+}
+
+// SetMaxConcurrency sets the maximum number of regions to run tasks in
+// concurrently. By default, it is 5.
+func (r *MultiRegionTaskRunner) SetMaxConcurrency(n int) *MultiRegionTaskRunner {
+	if n > 0 {
+		r.maxConcurrency = n
+	}
+	return r
+}
+
+// MultiRegionRunResult is the result of running a task in multiple regions.
+type MultiRegionRunResult struct {
+	// Regions are the results for each region, keyed by region.
+	Regions map[string]RegionRunResult
+}
+
+// HasErrors returns whether or not the task could not be run in any region.
+func (r *MultiRegionRunResult) HasErrors() bool {
+	for _, res := range r.Regions {
+		if res.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns a cocoa.MultiError containing the errors for the regions where
+// the task could not be run. It returns nil if the task ran in all regions.
+func (r *MultiRegionRunResult) Err() error {
+	regions := make([]string, 0, len(r.Regions))
+	for region := range r.Regions {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	var errs cocoa.MultiError
+	for _, region := range regions {
+		errs.Wrapf(r.Regions[region].Err, "region '%s'", region)
+	}
+	return errs.Resolve()
+}
+
+// RegionRunResult is the result of running a task in a single region.
+type RegionRunResult struct {
+	// TaskARNs are the ARNs of the tasks that started in the region.
+	TaskARNs []string
+	// Err is the error that prevented the task from running in the region, if
+	// any. If some of the tasks started but others failed, both the ARNs of
+	// the started tasks and the error are set.
+	Err error
+}
+
+// Run runs the task in every region concurrently and returns each region's
+// result. Failing to run the task in one region does not prevent it from
+// running in the other regions; instead, the error is recorded in that
+// region's result. The input must be valid in every region (e.g. its cluster
+// and task definition must exist in each region).
+func (r *MultiRegionTaskRunner) Run(ctx context.Context, in *ecs.RunTaskInput) (*MultiRegionRunResult, error) {
+	if in == nil {
+		return nil, errors.New("cannot run task with nil input")
+	}
+
+	result := &MultiRegionRunResult{Regions: make(map[string]RegionRunResult, len(r.clients))}
+	var mu sync.Mutex
+	sem := make(chan struct{}, r.maxConcurrency)
+	var wg sync.WaitGroup
+	recordResult := func(region string, res RegionRunResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Regions[region] = res
+	}
+	for region, c := range r.clients {
+		// Check the context first, since select picks randomly between the
+		// ready cases.
+		if err := ctx.Err(); err != nil {
+			recordResult(region, RegionRunResult{Err: errors.Wrap(err, "running task")})
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			recordResult(region, RegionRunResult{Err: errors.Wrap(ctx.Err(), "running task")})
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(region string, c cocoa.ECSClient) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			// Each region gets its own copy of the input in case the client
+			// modifies it.
+			regionIn := *in
+			recordResult(region, runTaskInRegion(ctx, c, &regionIn))
+		}(region, c)
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// runTaskInRegion runs the task using the region's client.
+func runTaskInRegion(ctx context.Context, c cocoa.ECSClient, in *ecs.RunTaskInput) RegionRunResult {
+	out, err := c.RunTask(ctx, in)
+	if err != nil {
+		return RegionRunResult{Err: errors.Wrap(err, "running task")}
+	}
+
+	var res RegionRunResult
+	for _, task := range out.Tasks {
+		if task != nil && task.TaskArn != nil {
+			res.TaskARNs = append(res.TaskARNs, utility.FromStringPtr(task.TaskArn))
+		}
+	}
+	catcher := grip.NewBasicCatcher()
+	for _, f := range out.Failures {
+		if f != nil {
+			catcher.Add(ConvertFailureToError(f))
+		}
+	}
+	catcher.NewWhen(len(res.TaskARNs) == 0 && !catcher.HasErrors(), "running task returned neither tasks nor failures")
+	res.Err = catcher.Resolve()
+
+	return res
+}
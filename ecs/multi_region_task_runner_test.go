@@ -0,0 +1,31 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/cocoa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMultiRegionTaskRunner(t *testing.T) {
+	t.Run("FailsWithoutClients", func(t *testing.T) {
+		_, err := NewMultiRegionTaskRunner(nil)
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithNilClient", func(t *testing.T) {
+		_, err := NewMultiRegionTaskRunner(map[string]cocoa.ECSClient{"us-east-1": nil})
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithEmptyRegion", func(t *testing.T) {
+		_, err := NewMultiRegionTaskRunner(map[string]cocoa.ECSClient{"": &BasicClient{}})
+		assert.Error(t, err)
+	})
+	t.Run("SetMaxConcurrencyIgnoresNonpositiveValues", func(t *testing.T) {
+		r, err := NewMultiRegionTaskRunner(map[string]cocoa.ECSClient{"us-east-1": &BasicClient{}})
+		assert.NoError(t, err)
+		r.SetMaxConcurrency(0)
+		assert.Equal(t, defaultMultiRegionConcurrency, r.maxConcurrency)
+		r.SetMaxConcurrency(2)
+		assert.Equal(t, 2, r.maxConcurrency)
+	})
+}
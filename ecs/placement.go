@@ -0,0 +1,121 @@
+package ecs
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+)
+
+// PlacementFailureCategory represents a category of reasons that ECS could not
+// place a task.
+type PlacementFailureCategory string
+
+const (
+	// PlacementFailureInsufficientCPU indicates that no container instance has
+	// enough CPU available to place the task.
+	PlacementFailureInsufficientCPU PlacementFailureCategory = "InsufficientCPU"
+	// PlacementFailureInsufficientMemory indicates that no container instance
+	// has enough memory available to place the task.
+	PlacementFailureInsufficientMemory PlacementFailureCategory = "InsufficientMemory"
+	// PlacementFailureNoMatchingAttribute indicates that no container instance
+	// has the attribute required by the task definition or placement
+	// constraints.
+	PlacementFailureNoMatchingAttribute PlacementFailureCategory = "NoMatchingAttribute"
+	// PlacementFailureConstraintViolation indicates that placing the task
+	// would violate one of its placement constraints (e.g. distinctInstance).
+	PlacementFailureConstraintViolation PlacementFailureCategory = "ConstraintViolation"
+	// PlacementFailureUnknown indicates that the failure does not match any
+	// known category.
+	PlacementFailureUnknown PlacementFailureCategory = "Unknown"
+)
+
+// These are the placement failure reasons returned by ECS.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/api_failures_messages.html
+const (
+	reasonResourceCPU    = "RESOURCE:CPU"
+	reasonResourceMemory = "RESOURCE:MEMORY"
+	reasonAttribute      = "ATTRIBUTE"
+)
+
+// PlacementFailure is a single categorized ECS placement failure.
+type PlacementFailure struct {
+	// ARN is the ARN of the resource that failed, if any.
+	ARN string
+	// Reason is the raw failure reason returned by ECS.
+	Reason string
+	// Detail is the raw failure detail returned by ECS.
+	Detail string
+	// Category is the category of the failure.
+	Category PlacementFailureCategory
+}
+
+// PlacementFailureAnalysis contains the categorized failures from an attempt
+// to place tasks.
+type PlacementFailureAnalysis struct {
+	// Failures are the categorized failures in the order that ECS returned
+	// them.
+	Failures []PlacementFailure
+	// Counts is the number of failures in each category.
+	Counts map[PlacementFailureCategory]int
+}
+
+// Has returns whether or not any of the failures is in the given category.
+func (a *PlacementFailureAnalysis) Has(category PlacementFailureCategory) bool {
+	return a.Counts[category] > 0
+}
+
+// IsInsufficientResources returns whether or not there are failures and all
+// of them are due to insufficient CPU or memory. Such failures are usually
+// transient, since the cluster may scale out to provide more resources.
+func (a *PlacementFailureAnalysis) IsInsufficientResources() bool {
+	if len(a.Failures) == 0 {
+		return false
+	}
+	return a.Counts[PlacementFailureInsufficientCPU]+a.Counts[PlacementFailureInsufficientMemory] == len(a.Failures)
+}
+
+// AnalyzePlacementFailure categorizes the failures returned by ECS when it
+// cannot place tasks (e.g. from RunTask) based on their reason and detail.
+// Nil failures are ignored.
+func AnalyzePlacementFailure(failures []*ecs.Failure) *PlacementFailureAnalysis {
+	analysis := &PlacementFailureAnalysis{
+		Counts: map[PlacementFailureCategory]int{},
+	}
+	for _, f := range failures {
+		if f == nil {
+			continue
+		}
+
+		pf := PlacementFailure{
+			ARN:      utility.FromStringPtr(f.Arn),
+			Reason:   utility.FromStringPtr(f.Reason),
+			Detail:   utility.FromStringPtr(f.Detail),
+			Category: categorizePlacementFailure(f),
+		}
+		analysis.Failures = append(analysis.Failures, pf)
+		analysis.Counts[pf.Category]++
+	}
+
+	return analysis
+}
+
+// categorizePlacementFailure returns the category for a single placement
+// failure.
+func categorizePlacementFailure(f *ecs.Failure) PlacementFailureCategory {
+	reason := strings.TrimSpace(utility.FromStringPtr(f.Reason))
+	detail := strings.ToLower(utility.FromStringPtr(f.Detail))
+
+	switch {
+	case strings.EqualFold(reason, reasonResourceCPU):
+		return PlacementFailureInsufficientCPU
+	case strings.EqualFold(reason, reasonResourceMemory):
+		return PlacementFailureInsufficientMemory
+	case strings.EqualFold(reason, reasonAttribute) || strings.HasPrefix(strings.ToUpper(reason), reasonAttribute+":"):
+		return PlacementFailureNoMatchingAttribute
+	case strings.Contains(strings.ToLower(reason), "constraint") || strings.Contains(detail, "constraint"):
+		return PlacementFailureConstraintViolation
+	default:
+		return PlacementFailureUnknown
+	}
+}
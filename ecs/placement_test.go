@@ -0,0 +1,105 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzePlacementFailure(t *testing.T) {
+	t.Run("CategorizesFailures", func(t *testing.T) {
+		for tName, tCase := range map[string]struct {
+			failure  ecs.Failure
+			expected PlacementFailureCategory
+		}{
+			"InsufficientCPU": {
+				failure:  ecs.Failure{Reason: aws.String("RESOURCE:CPU")},
+				expected: PlacementFailureInsufficientCPU,
+			},
+			"InsufficientMemory": {
+				failure:  ecs.Failure{Reason: aws.String("RESOURCE:MEMORY")},
+				expected: PlacementFailureInsufficientMemory,
+			},
+			"NoMatchingAttribute": {
+				failure: ecs.Failure{
+					Reason: aws.String("ATTRIBUTE"),
+					Detail: aws.String("ecs.instance-type == t2.micro"),
+				},
+				expected: PlacementFailureNoMatchingAttribute,
+			},
+			"NoMatchingAttributeWithAttributeName": {
+				failure:  ecs.Failure{Reason: aws.String("ATTRIBUTE:ecs.capability.docker-remote-api.1.19")},
+				expected: PlacementFailureNoMatchingAttribute,
+			},
+			"ConstraintViolationInReason": {
+				failure:  ecs.Failure{Reason: aws.String("distinctInstance placement constraint unsatisfied")},
+				expected: PlacementFailureConstraintViolation,
+			},
+			"ConstraintViolationInDetail": {
+				failure: ecs.Failure{
+					Reason: aws.String("PLACEMENT"),
+					Detail: aws.String("Task could not be placed because of a memberOf Constraint"),
+				},
+				expected: PlacementFailureConstraintViolation,
+			},
+			"Unknown": {
+				failure:  ecs.Failure{Reason: aws.String("AGENT")},
+				expected: PlacementFailureUnknown,
+			},
+			"UnknownWithoutReason": {
+				expected: PlacementFailureUnknown,
+			},
+		} {
+			t.Run(tName, func(t *testing.T) {
+				analysis := AnalyzePlacementFailure([]*ecs.Failure{&tCase.failure})
+				require.Len(t, analysis.Failures, 1)
+				assert.Equal(t, tCase.expected, analysis.Failures[0].Category)
+				assert.True(t, analysis.Has(tCase.expected))
+			})
+		}
+	})
+	t.Run("PreservesFailureInformation", func(t *testing.T) {
+		analysis := AnalyzePlacementFailure([]*ecs.Failure{{
+			Arn:    aws.String("arn"),
+			Reason: aws.String("RESOURCE:CPU"),
+			Detail: aws.String("detail"),
+		}})
+		require.Len(t, analysis.Failures, 1)
+		assert.Equal(t, PlacementFailure{
+			ARN:      "arn",
+			Reason:   "RESOURCE:CPU",
+			Detail:   "detail",
+			Category: PlacementFailureInsufficientCPU,
+		}, analysis.Failures[0])
+	})
+	t.Run("CountsFailuresByCategory", func(t *testing.T) {
+		analysis := AnalyzePlacementFailure([]*ecs.Failure{
+			{Reason: aws.String("RESOURCE:CPU")},
+			nil,
+			{Reason: aws.String("RESOURCE:CPU")},
+			{Reason: aws.String("ATTRIBUTE")},
+		})
+		assert.Len(t, analysis.Failures, 3)
+		assert.Equal(t, 2, analysis.Counts[PlacementFailureInsufficientCPU])
+		assert.Equal(t, 1, analysis.Counts[PlacementFailureNoMatchingAttribute])
+		assert.False(t, analysis.Has(PlacementFailureInsufficientMemory))
+		assert.False(t, analysis.IsInsufficientResources())
+	})
+	t.Run("IsInsufficientResources", func(t *testing.T) {
+		analysis := AnalyzePlacementFailure([]*ecs.Failure{
+			{Reason: aws.String("RESOURCE:CPU")},
+			{Reason: aws.String("RESOURCE:MEMORY")},
+		})
+		assert.True(t, analysis.IsInsufficientResources())
+	})
+	t.Run("ReturnsEmptyAnalysisWithoutFailures", func(t *testing.T) {
+		analysis := AnalyzePlacementFailure(nil)
+		require.NotZero(t, analysis)
+		assert.Empty(t, analysis.Failures)
+		assert.False(t, analysis.Has(PlacementFailureUnknown))
+		assert.False(t, analysis.IsInsufficientResources())
+	})
+}
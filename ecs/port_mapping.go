@@ -0,0 +1,107 @@
+package ecs
+
+import (
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// maxPort is the largest valid port number.
+const maxPort = 65535
+
+// portMappingNameRegexp matches valid port mapping names.
+var portMappingNameRegexp = regexp.MustCompile(`^[a-z0-9_][a-z0-9_-]{0,63}$`)
+
+// PortMappingBuilder builds a port mapping for a container definition.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/APIReference/API_PortMapping.html
+type PortMappingBuilder struct {
+	containerPort int64
+	hostPort      *int64
+	protocol      string
+	name          string
+	appProtocol   string
+}
+
+// NewPortMappingBuilder returns a new builder for a port mapping. By default,
+// the port mapping uses TCP.
+func NewPortMappingBuilder() *PortMappingBuilder {
+	return &PortMappingBuilder{
+		protocol: ecs.TransportProtocolTcp,
+	}
+}
+
+// WithContainerPort sets the port in the container that the port mapping
+// exposes.
+func (b *PortMappingBuilder) WithContainerPort(p int64) *PortMappingBuilder {
+	b.containerPort = p
+	return b
+}
+
+// WithHostPort sets the port on the host that maps to the container port. For
+// tasks using the awsvpc network mode (e.g. Fargate tasks), it must either be
+// unset or equal to the container port. For the bridge network mode, 0 assigns
+// a host port dynamically.
+func (b *PortMappingBuilder) WithHostPort(p int64) *PortMappingBuilder {
+	b.hostPort = &p
+	return b
+}
+
+// WithProtocol sets the transport protocol, which must be "tcp" or "udp".
+func (b *PortMappingBuilder) WithProtocol(p string) *PortMappingBuilder {
+	b.protocol = p
+	return b
+}
+
+// WithName sets the name of the port mapping, which Service Connect uses to
+// refer to the port.
+func (b *PortMappingBuilder) WithName(name string) *PortMappingBuilder {
+	b.name = name
+	return b
+}
+
+// WithAppProtocol sets the application protocol of the port mapping (i.e.
+// "http", "http2" or "grpc"), which Service Connect uses to collect
+// protocol-specific metrics.
+func (b *PortMappingBuilder) WithAppProtocol(ap string) *PortMappingBuilder {
+	b.appProtocol = ap
+	return b
+}
+
+// Validate checks that the ports are in range and that the protocols are
+// valid.
+func (b *PortMappingBuilder) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	catcher.ErrorfWhen(b.containerPort < 1 || b.containerPort > maxPort, "container port %d must be between 1 and %d", b.containerPort, maxPort)
+	if b.hostPort != nil {
+		catcher.ErrorfWhen(*b.hostPort < 0 || *b.hostPort > maxPort, "host port %d must be between 0 and %d", *b.hostPort, maxPort)
+	}
+	catcher.ErrorfWhen(!utility.StringSliceContains(ecs.TransportProtocol_Values(), b.protocol), "invalid protocol '%s', must be '%s' or '%s'", b.protocol, ecs.TransportProtocolTcp, ecs.TransportProtocolUdp)
+	catcher.ErrorfWhen(b.name != "" && !portMappingNameRegexp.MatchString(b.name), "invalid port mapping name '%s'", b.name)
+	catcher.ErrorfWhen(b.appProtocol != "" && !utility.StringSliceContains(ecs.ApplicationProtocol_Values(), b.appProtocol), "invalid application protocol '%s'", b.appProtocol)
+	catcher.NewWhen(b.appProtocol != "" && b.protocol != ecs.TransportProtocolTcp, "application protocol can only be used with TCP")
+	return catcher.Resolve()
+}
+
+// Build validates the port mapping and returns it.
+func (b *PortMappingBuilder) Build() (*ecs.PortMapping, error) {
+	if err := b.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid port mapping")
+	}
+
+	pm := &ecs.PortMapping{
+		ContainerPort: aws.Int64(b.containerPort),
+		HostPort:      b.hostPort,
+		Protocol:      aws.String(b.protocol),
+	}
+	if b.name != "" {
+		pm.SetName(b.name)
+	}
+	if b.appProtocol != "" {
+		pm.SetAppProtocol(b.appProtocol)
+	}
+	return pm, nil
+}
@@ -0,0 +1,78 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortMappingBuilder(t *testing.T) {
+	t.Run("BuildsPortMappingWithDefaults", func(t *testing.T) {
+		pm, err := NewPortMappingBuilder().WithContainerPort(8080).Build()
+		require.NoError(t, err)
+		require.NotZero(t, pm)
+		assert.EqualValues(t, 8080, utility.FromInt64Ptr(pm.ContainerPort))
+		assert.Zero(t, pm.HostPort)
+		assert.Equal(t, ecs.TransportProtocolTcp, utility.FromStringPtr(pm.Protocol))
+		assert.Zero(t, pm.Name)
+		assert.Zero(t, pm.AppProtocol)
+	})
+	t.Run("BuildsPortMappingWithAllFields", func(t *testing.T) {
+		pm, err := NewPortMappingBuilder().
+			WithContainerPort(8080).
+			WithHostPort(80).
+			WithProtocol(ecs.TransportProtocolTcp).
+			WithName("web").
+			WithAppProtocol(ecs.ApplicationProtocolHttp).
+			Build()
+		require.NoError(t, err)
+		assert.EqualValues(t, 8080, utility.FromInt64Ptr(pm.ContainerPort))
+		require.NotZero(t, pm.HostPort)
+		assert.EqualValues(t, 80, *pm.HostPort)
+		assert.Equal(t, "web", utility.FromStringPtr(pm.Name))
+		assert.Equal(t, ecs.ApplicationProtocolHttp, utility.FromStringPtr(pm.AppProtocol))
+	})
+	t.Run("BuildsUDPPortMapping", func(t *testing.T) {
+		pm, err := NewPortMappingBuilder().WithContainerPort(53).WithProtocol(ecs.TransportProtocolUdp).Build()
+		require.NoError(t, err)
+		assert.Equal(t, ecs.TransportProtocolUdp, utility.FromStringPtr(pm.Protocol))
+	})
+	t.Run("AllowsDynamicHostPort", func(t *testing.T) {
+		pm, err := NewPortMappingBuilder().WithContainerPort(8080).WithHostPort(0).Build()
+		require.NoError(t, err)
+		require.NotZero(t, pm.HostPort)
+		assert.Zero(t, *pm.HostPort)
+	})
+	t.Run("FailsWithoutContainerPort", func(t *testing.T) {
+		pm, err := NewPortMappingBuilder().Build()
+		assert.Error(t, err)
+		assert.Zero(t, pm)
+	})
+	t.Run("FailsWithContainerPortOutOfRange", func(t *testing.T) {
+		_, err := NewPortMappingBuilder().WithContainerPort(65536).Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithHostPortOutOfRange", func(t *testing.T) {
+		_, err := NewPortMappingBuilder().WithContainerPort(8080).WithHostPort(-1).Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithInvalidProtocol", func(t *testing.T) {
+		_, err := NewPortMappingBuilder().WithContainerPort(8080).WithProtocol("sctp").Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithInvalidName", func(t *testing.T) {
+		_, err := NewPortMappingBuilder().WithContainerPort(8080).WithName("-Web").Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithInvalidAppProtocol", func(t *testing.T) {
+		_, err := NewPortMappingBuilder().WithContainerPort(8080).WithAppProtocol("ftp").Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithAppProtocolOverUDP", func(t *testing.T) {
+		_, err := NewPortMappingBuilder().WithContainerPort(8080).WithProtocol(ecs.TransportProtocolUdp).WithAppProtocol(ecs.ApplicationProtocolHttp).Build()
+		assert.Error(t, err)
+	})
+}
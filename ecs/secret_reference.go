@@ -0,0 +1,107 @@
+package ecs
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// envVarNameRegexp matches valid environment variable names.
+var envVarNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// secretReference is a reference from a container environment variable to a
+// secret stored in an external service.
+type secretReference struct {
+	envVarName string
+	arn        string
+	service    string
+	// resourcePrefix is the prefix that the resource part of the ARN must
+	// have for the service.
+	resourcePrefix string
+}
+
+// SecretReferenceBuilder builds the secrets for an ECS container definition,
+// which expose secrets stored in Secrets Manager or SSM Parameter Store to the
+// container as environment variables.
+type SecretReferenceBuilder struct {
+	refs []secretReference
+}
+
+// NewSecretReferenceBuilder returns a new builder with no secret references.
+func NewSecretReferenceBuilder() *SecretReferenceBuilder {
+	return &SecretReferenceBuilder{}
+}
+
+// AddSecretsManagerRef adds a reference to a Secrets Manager secret that will
+// be exposed as the given environment variable.
+func (b *SecretReferenceBuilder) AddSecretsManagerRef(envVarName, secretARN string) *SecretReferenceBuilder {
+	b.refs = append(b.refs, secretReference{
+		envVarName:     envVarName,
+		arn:            secretARN,
+		service:        "secretsmanager",
+		resourcePrefix: "secret:",
+	})
+	return b
+}
+
+// AddSSMParameterRef adds a reference to an SSM Parameter Store parameter that
+// will be exposed as the given environment variable.
+func (b *SecretReferenceBuilder) AddSSMParameterRef(envVarName, parameterARN string) *SecretReferenceBuilder {
+	b.refs = append(b.refs, secretReference{
+		envVarName:     envVarName,
+		arn:            parameterARN,
+		service:        "ssm",
+		resourcePrefix: "parameter/",
+	})
+	return b
+}
+
+// Validate checks that all the environment variable names are valid and unique
+// and that each ARN is a well-formed ARN for its service.
+func (b *SecretReferenceBuilder) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	envVarNames := map[string]bool{}
+	for _, ref := range b.refs {
+		catcher.ErrorfWhen(!envVarNameRegexp.MatchString(ref.envVarName), "invalid environment variable name '%s'", ref.envVarName)
+		catcher.ErrorfWhen(envVarNames[ref.envVarName], "duplicate environment variable name '%s'", ref.envVarName)
+		envVarNames[ref.envVarName] = true
+		catcher.Wrapf(ref.validateARN(), "environment variable '%s'", ref.envVarName)
+	}
+	return catcher.Resolve()
+}
+
+func (r secretReference) validateARN() error {
+	parsed, err := arn.Parse(r.arn)
+	if err != nil {
+		return errors.Wrapf(err, "parsing ARN '%s'", r.arn)
+	}
+	if parsed.Service != r.service {
+		return errors.Errorf("ARN '%s' is for service '%s', but expected service '%s'", r.arn, parsed.Service, r.service)
+	}
+	if !strings.HasPrefix(parsed.Resource, r.resourcePrefix) || len(parsed.Resource) == len(r.resourcePrefix) {
+		return errors.Errorf("ARN '%s' does not refer to a resource of the form '%s<name>'", r.arn, r.resourcePrefix)
+	}
+	return nil
+}
+
+// Build validates the secret references and returns them as ECS container
+// secrets.
+func (b *SecretReferenceBuilder) Build() ([]*ecs.Secret, error) {
+	if err := b.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid secret references")
+	}
+
+	secrets := make([]*ecs.Secret, 0, len(b.refs))
+	for _, ref := range b.refs {
+		secrets = append(secrets, &ecs.Secret{
+			Name:      aws.String(ref.envVarName),
+			ValueFrom: aws.String(ref.arn),
+		})
+	}
+	return secrets, nil
+}
@@ -0,0 +1,63 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretReferenceBuilder(t *testing.T) {
+	const (
+		secretARN    = "arn:aws:secretsmanager:us-east-1:123456789012:secret:my-secret-AbCdEf"
+		parameterARN = "arn:aws:ssm:us-east-1:123456789012:parameter/my/parameter"
+	)
+
+	t.Run("BuildSucceedsWithNoReferences", func(t *testing.T) {
+		secrets, err := NewSecretReferenceBuilder().Build()
+		require.NoError(t, err)
+		assert.Empty(t, secrets)
+	})
+	t.Run("BuildSucceedsWithValidReferences", func(t *testing.T) {
+		secrets, err := NewSecretReferenceBuilder().
+			AddSecretsManagerRef("SECRET_VAR", secretARN).
+			AddSSMParameterRef("_parameter_var1", parameterARN).
+			Build()
+		require.NoError(t, err)
+		require.Len(t, secrets, 2)
+		assert.Equal(t, "SECRET_VAR", utility.FromStringPtr(secrets[0].Name))
+		assert.Equal(t, secretARN, utility.FromStringPtr(secrets[0].ValueFrom))
+		assert.Equal(t, "_parameter_var1", utility.FromStringPtr(secrets[1].Name))
+		assert.Equal(t, parameterARN, utility.FromStringPtr(secrets[1].ValueFrom))
+	})
+	t.Run("BuildFailsWithInvalidEnvVarName", func(t *testing.T) {
+		for _, name := range []string{"", "1VAR", "SOME-VAR", "SOME VAR"} {
+			_, err := NewSecretReferenceBuilder().AddSecretsManagerRef(name, secretARN).Build()
+			assert.Error(t, err, "environment variable name '%s'", name)
+		}
+	})
+	t.Run("BuildFailsWithDuplicateEnvVarName", func(t *testing.T) {
+		_, err := NewSecretReferenceBuilder().
+			AddSecretsManagerRef("VAR", secretARN).
+			AddSSMParameterRef("VAR", parameterARN).
+			Build()
+		assert.Error(t, err)
+	})
+	t.Run("BuildFailsWithMalformedARN", func(t *testing.T) {
+		_, err := NewSecretReferenceBuilder().AddSecretsManagerRef("VAR", "my-secret").Build()
+		assert.Error(t, err)
+	})
+	t.Run("BuildFailsWithARNForWrongService", func(t *testing.T) {
+		_, err := NewSecretReferenceBuilder().AddSecretsManagerRef("VAR", parameterARN).Build()
+		assert.Error(t, err)
+		_, err = NewSecretReferenceBuilder().AddSSMParameterRef("VAR", secretARN).Build()
+		assert.Error(t, err)
+	})
+	t.Run("BuildFailsWithARNMissingResourceName", func(t *testing.T) {
+		_, err := NewSecretReferenceBuilder().AddSecretsManagerRef("VAR", "arn:aws:secretsmanager:us-east-1:123456789012:secret:").Build()
+		assert.Error(t, err)
+		_, err = NewSecretReferenceBuilder().AddSSMParameterRef("VAR", "arn:aws:ssm:us-east-1:123456789012:document/foo").Build()
+		assert.Error(t, err)
+	})
+}
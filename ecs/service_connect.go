@@ -0,0 +1,141 @@
+package ecs
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// serviceConnectDNSNameRegexp matches valid Service Connect client alias DNS
+// names.
+var serviceConnectDNSNameRegexp = regexp.MustCompile(`^[a-z0-9_.][a-z0-9_.-]{0,126}$`)
+
+// serviceConnectService is a service that the builder exposes through Service
+// Connect.
+type serviceConnectService struct {
+	portName            string
+	port                string
+	dnsName             string
+	ingressPortOverride *int64
+}
+
+// ServiceConnectConfigBuilder builds the Service Connect configuration of an
+// ECS service, which lets it connect to and be reached by other services in
+// the same Cloud Map namespace.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/service-connect.html
+type ServiceConnectConfigBuilder struct {
+	namespace string
+	services  []*serviceConnectService
+	// orphanedSettings are the service settings that were set before any
+	// service was added.
+	orphanedSettings []string
+}
+
+// NewServiceConnectConfigBuilder returns a new builder for a Service Connect
+// configuration.
+func NewServiceConnectConfigBuilder() *ServiceConnectConfigBuilder {
+	return &ServiceConnectConfigBuilder{}
+}
+
+// WithNamespace sets the name or ARN of the Cloud Map namespace that the
+// service joins.
+func (b *ServiceConnectConfigBuilder) WithNamespace(namespace string) *ServiceConnectConfigBuilder {
+	b.namespace = namespace
+	return b
+}
+
+// AddService exposes a port of the service to the other services in the
+// namespace. The name is the name of the port mapping in the task definition,
+// and the port is the port number that clients use to connect to it. If the
+// service does not expose any ports, it can only connect to other services.
+func (b *ServiceConnectConfigBuilder) AddService(name, port string) *ServiceConnectConfigBuilder {
+	b.services = append(b.services, &serviceConnectService{
+		portName: name,
+		port:     port,
+	})
+	return b
+}
+
+// WithClientAlias sets the DNS name that clients use to connect to the most
+// recently added service. By default, clients use the port mapping name
+// followed by the namespace.
+func (b *ServiceConnectConfigBuilder) WithClientAlias(dns string) *ServiceConnectConfigBuilder {
+	if svc := b.lastService("client alias"); svc != nil {
+		svc.dnsName = dns
+	}
+	return b
+}
+
+// WithIngressPortOverride sets the port that the Service Connect proxy listens
+// on for the most recently added service. By default, for the awsvpc network
+// mode, the proxy listens on the container port.
+func (b *ServiceConnectConfigBuilder) WithIngressPortOverride(port int64) *ServiceConnectConfigBuilder {
+	if svc := b.lastService("ingress port override"); svc != nil {
+		svc.ingressPortOverride = &port
+	}
+	return b
+}
+
+// lastService returns the most recently added service. If no service has been
+// added yet, it records the setting as invalid.
+func (b *ServiceConnectConfigBuilder) lastService(setting string) *serviceConnectService {
+	if len(b.services) == 0 {
+		b.orphanedSettings = append(b.orphanedSettings, setting)
+		return nil
+	}
+	return b.services[len(b.services)-1]
+}
+
+// Validate checks that the namespace is set and that every service has a
+// valid port mapping name, port and client alias.
+func (b *ServiceConnectConfigBuilder) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	for _, setting := range b.orphanedSettings {
+		catcher.Errorf("cannot set %s before adding a service", setting)
+	}
+	catcher.NewWhen(b.namespace == "", "must specify a namespace")
+	for _, svc := range b.services {
+		catcher.ErrorfWhen(!portMappingNameRegexp.MatchString(svc.portName), "invalid port mapping name '%s'", svc.portName)
+		port, err := strconv.ParseInt(svc.port, 10, 64)
+		catcher.ErrorfWhen(err != nil || port < 1 || port > maxPort, "port '%s' for service '%s' must be a number between 1 and %d", svc.port, svc.portName, maxPort)
+		catcher.ErrorfWhen(svc.dnsName != "" && !serviceConnectDNSNameRegexp.MatchString(svc.dnsName), "invalid client alias DNS name '%s' for service '%s'", svc.dnsName, svc.portName)
+		if svc.ingressPortOverride != nil {
+			catcher.ErrorfWhen(*svc.ingressPortOverride < 1 || *svc.ingressPortOverride > maxPort, "ingress port override %d for service '%s' must be between 1 and %d", *svc.ingressPortOverride, svc.portName, maxPort)
+		}
+	}
+	return catcher.Resolve()
+}
+
+// Build validates the Service Connect configuration and returns it with
+// Service Connect enabled.
+func (b *ServiceConnectConfigBuilder) Build() (*ecs.ServiceConnectConfiguration, error) {
+	if err := b.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid Service Connect configuration")
+	}
+
+	cfg := &ecs.ServiceConnectConfiguration{
+		Enabled:   aws.Bool(true),
+		Namespace: aws.String(b.namespace),
+	}
+	for _, svc := range b.services {
+		// The port is already known to be valid.
+		port, _ := strconv.ParseInt(svc.port, 10, 64)
+		alias := &ecs.ServiceConnectClientAlias{
+			Port: aws.Int64(port),
+		}
+		if svc.dnsName != "" {
+			alias.SetDnsName(svc.dnsName)
+		}
+		cfg.Services = append(cfg.Services, &ecs.ServiceConnectService{
+			PortName:            aws.String(svc.portName),
+			ClientAliases:       []*ecs.ServiceConnectClientAlias{alias},
+			IngressPortOverride: svc.ingressPortOverride,
+		})
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,74 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceConnectConfigBuilder(t *testing.T) {
+	t.Run("BuildsClientOnlyConfiguration", func(t *testing.T) {
+		cfg, err := NewServiceConnectConfigBuilder().WithNamespace("internal").Build()
+		require.NoError(t, err)
+		require.NotZero(t, cfg)
+		assert.True(t, utility.FromBoolPtr(cfg.Enabled))
+		assert.Equal(t, "internal", utility.FromStringPtr(cfg.Namespace))
+		assert.Empty(t, cfg.Services)
+	})
+	t.Run("BuildsConfigurationWithServices", func(t *testing.T) {
+		cfg, err := NewServiceConnectConfigBuilder().
+			WithNamespace("internal").
+			AddService("web", "80").
+			WithClientAlias("frontend.internal").
+			WithIngressPortOverride(8080).
+			AddService("metrics", "9090").
+			Build()
+		require.NoError(t, err)
+		require.Len(t, cfg.Services, 2)
+
+		web := cfg.Services[0]
+		assert.Equal(t, "web", utility.FromStringPtr(web.PortName))
+		require.Len(t, web.ClientAliases, 1)
+		assert.EqualValues(t, 80, utility.FromInt64Ptr(web.ClientAliases[0].Port))
+		assert.Equal(t, "frontend.internal", utility.FromStringPtr(web.ClientAliases[0].DnsName))
+		assert.EqualValues(t, 8080, utility.FromInt64Ptr(web.IngressPortOverride))
+
+		metrics := cfg.Services[1]
+		assert.Equal(t, "metrics", utility.FromStringPtr(metrics.PortName))
+		require.Len(t, metrics.ClientAliases, 1)
+		assert.EqualValues(t, 9090, utility.FromInt64Ptr(metrics.ClientAliases[0].Port))
+		assert.Zero(t, metrics.ClientAliases[0].DnsName)
+		assert.Zero(t, metrics.IngressPortOverride)
+	})
+	t.Run("FailsWithoutNamespace", func(t *testing.T) {
+		cfg, err := NewServiceConnectConfigBuilder().AddService("web", "80").Build()
+		assert.Error(t, err)
+		assert.Zero(t, cfg)
+	})
+	t.Run("FailsWithInvalidPort", func(t *testing.T) {
+		_, err := NewServiceConnectConfigBuilder().WithNamespace("internal").AddService("web", "http").Build()
+		assert.Error(t, err)
+		_, err = NewServiceConnectConfigBuilder().WithNamespace("internal").AddService("web", "70000").Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithInvalidPortMappingName", func(t *testing.T) {
+		_, err := NewServiceConnectConfigBuilder().WithNamespace("internal").AddService("", "80").Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithInvalidClientAlias", func(t *testing.T) {
+		_, err := NewServiceConnectConfigBuilder().WithNamespace("internal").AddService("web", "80").WithClientAlias("-Frontend").Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithInvalidIngressPortOverride", func(t *testing.T) {
+		_, err := NewServiceConnectConfigBuilder().WithNamespace("internal").AddService("web", "80").WithIngressPortOverride(0).Build()
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithServiceSettingsBeforeAddingService", func(t *testing.T) {
+		_, err := NewServiceConnectConfigBuilder().WithNamespace("internal").WithClientAlias("frontend").Build()
+		assert.Error(t, err)
+		_, err = NewServiceConnectConfigBuilder().WithNamespace("internal").WithIngressPortOverride(8080).Build()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,390 @@
+package ecs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// ClusterSnapshot is a point-in-time capture of a cluster's configuration, its
+// services and the task definitions that its services and tasks are using. It
+// does not capture the cluster's container instances or standalone tasks.
+type ClusterSnapshot struct {
+	// ClusterName is the name of the cluster that was captured.
+	ClusterName string
+	// Tags are the cluster's tags.
+	Tags map[string]string
+	// Settings are the cluster's settings (e.g. Container Insights).
+	Settings []*ecs.ClusterSetting
+	// Configuration is the cluster's execute command configuration.
+	Configuration *ecs.ClusterConfiguration
+	// CapacityProviders are the names of the cluster's capacity providers.
+	CapacityProviders []string
+	// DefaultCapacityProviderStrategy is the cluster's default capacity
+	// provider strategy.
+	DefaultCapacityProviderStrategy []*ecs.CapacityProviderStrategyItem
+	// Services are the definitions of the cluster's active services.
+	Services []*ecs.Service
+	// TaskDefinitions are the task definitions used by the cluster's services
+	// and running tasks.
+	TaskDefinitions []TaskDefinitionSnapshot
+}
+
+// TaskDefinitionSnapshot is a captured task definition and its tags.
+type TaskDefinitionSnapshot struct {
+	// Definition is the task definition.
+	Definition *ecs.TaskDefinition
+	// Tags are the task definition's tags.
+	Tags map[string]string
+}
+
+// SnapshotCluster captures the cluster's tags, settings, capacity provider
+// configuration, service definitions, and the task definitions used by its
+// services and running tasks. The snapshot can be restored with
+// RestoreClusterSnapshot.
+func SnapshotCluster(ctx context.Context, c cocoa.ECSClient, cluster string) (*ClusterSnapshot, error) {
+	if cluster == "" {
+		return nil, errors.New("must specify a cluster")
+	}
+
+	out, err := c.DescribeClusters(ctx, &ecs.DescribeClustersInput{
+		Clusters: []*string{aws.String(cluster)},
+		Include: aws.StringSlice([]string{
+			ecs.ClusterFieldTags,
+			ecs.ClusterFieldSettings,
+			ecs.ClusterFieldConfigurations,
+		}),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describing cluster")
+	}
+	if len(out.Failures) > 0 && out.Failures[0] != nil {
+		return nil, ConvertFailureToError(out.Failures[0])
+	}
+	if len(out.Clusters) == 0 || out.Clusters[0] == nil {
+		return nil, errors.Errorf("cluster '%s' was not returned in the response", cluster)
+	}
+	info := out.Clusters[0]
+
+	snapshot := ClusterSnapshot{
+		ClusterName:                     cluster,
+		Tags:                            importTags(info.Tags),
+		Settings:                        info.Settings,
+		Configuration:                   info.Configuration,
+		CapacityProviders:               utility.FromStringPtrSlice(info.CapacityProviders),
+		DefaultCapacityProviderStrategy: info.DefaultCapacityProviderStrategy,
+	}
+
+	services, err := describeActiveServices(ctx, c, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "describing services")
+	}
+	snapshot.Services = services
+
+	taskDefARNs, err := listTaskDefinitionsInUse(ctx, c, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing task definitions in use")
+	}
+	for _, svc := range services {
+		if arn := utility.FromStringPtr(svc.TaskDefinition); arn != "" && !utility.StringSliceContains(taskDefARNs, arn) {
+			taskDefARNs = append(taskDefARNs, arn)
+		}
+	}
+	for _, arn := range taskDefARNs {
+		def, err := c.DescribeTaskDefinition(ctx, &ecs.DescribeTaskDefinitionInput{
+			TaskDefinition: aws.String(arn),
+			Include:        []*string{aws.String(ecs.TaskDefinitionFieldTags)},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "describing task definition '%s'", arn)
+		}
+		snapshot.TaskDefinitions = append(snapshot.TaskDefinitions, TaskDefinitionSnapshot{
+			Definition: def.TaskDefinition,
+			Tags:       importTags(def.Tags),
+		})
+	}
+
+	return &snapshot, nil
+}
+
+// RestoreClusterSnapshotOptions represent optional settings to restore a
+// cluster snapshot.
+type RestoreClusterSnapshotOptions struct {
+	// RoleARNs maps the task and execution role ARNs used by the snapshot's
+	// task definitions and the role ARNs used by its services to the role
+	// ARNs to use in the restored task definitions and services. IAM roles belong to a single account, so this must be set
+	// when restoring the snapshot in another account. Role ARNs that are not
+	// in the mapping are kept as-is.
+	RoleARNs map[string]string
+}
+
+// Validate checks that the options are valid.
+func (o *RestoreClusterSnapshotOptions) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	for from, to := range o.RoleARNs {
+		catcher.ErrorfWhen(to == "", "role ARN '%s' cannot be mapped to an empty role ARN", from)
+	}
+	return catcher.Resolve()
+}
+
+// roleARN returns the role ARN to use in place of the given one.
+func (o *RestoreClusterSnapshotOptions) roleARN(roleARN *string) *string {
+	if to, ok := o.RoleARNs[utility.FromStringPtr(roleARN)]; ok {
+		return aws.String(to)
+	}
+	return roleARN
+}
+
+// RestoreClusterSnapshot creates a new cluster with the given name and the
+// snapshot's configuration, registers the snapshot's task definitions, and
+// recreates its services in the new cluster using the newly-registered task
+// definitions. Role ARNs are replaced according to the options. To restore
+// the snapshot in another region or account, use a client configured for that
+// region or account. Capacity providers backed by Auto Scaling groups are
+// specific to a region and account, so they must already exist with the same
+// names for the cluster to be created; likewise, the subnets, security groups
+// and load balancers that the services use must exist for the services to be
+// created. If any task definition or service cannot be restored, this returns
+// the aggregated errors.
+func RestoreClusterSnapshot(ctx context.Context, c cocoa.ECSClient, snapshot *ClusterSnapshot, newClusterName string, opts RestoreClusterSnapshotOptions) error {
+	if snapshot == nil {
+		return errors.New("must specify a snapshot")
+	}
+	if newClusterName == "" {
+		return errors.New("must specify a cluster name")
+	}
+	if err := opts.Validate(); err != nil {
+		return errors.Wrap(err, "invalid options")
+	}
+
+	in := &ecs.CreateClusterInput{
+		ClusterName:                     aws.String(newClusterName),
+		Settings:                        snapshot.Settings,
+		Configuration:                   snapshot.Configuration,
+		DefaultCapacityProviderStrategy: snapshot.DefaultCapacityProviderStrategy,
+	}
+	if len(snapshot.Tags) != 0 {
+		in.Tags = ExportTags(snapshot.Tags)
+	}
+	if len(snapshot.CapacityProviders) != 0 {
+		in.CapacityProviders = aws.StringSlice(snapshot.CapacityProviders)
+	}
+	if _, err := c.CreateCluster(ctx, in); err != nil {
+		return errors.Wrapf(err, "creating cluster '%s'", newClusterName)
+	}
+
+	var errs cocoa.MultiError
+	// restoredTaskDefs maps the snapshot's task definition ARNs to the ARNs
+	// of the task definitions registered from them.
+	restoredTaskDefs := map[string]string{}
+	for _, def := range snapshot.TaskDefinitions {
+		if def.Definition == nil {
+			continue
+		}
+		family := utility.FromStringPtr(def.Definition.Family)
+		registerIn := exportTaskDefinitionSnapshot(def)
+		registerIn.TaskRoleArn = opts.roleARN(registerIn.TaskRoleArn)
+		registerIn.ExecutionRoleArn = opts.roleARN(registerIn.ExecutionRoleArn)
+		out, err := c.RegisterTaskDefinition(ctx, registerIn)
+		if err != nil {
+			errs.Wrapf(err, "registering task definition for family '%s'", family)
+			continue
+		}
+		if out.TaskDefinition != nil {
+			restoredTaskDefs[utility.FromStringPtr(def.Definition.TaskDefinitionArn)] = utility.FromStringPtr(out.TaskDefinition.TaskDefinitionArn)
+		}
+	}
+
+	for _, svc := range snapshot.Services {
+		if svc == nil {
+			continue
+		}
+		name := utility.FromStringPtr(svc.ServiceName)
+		taskDef, ok := restoredTaskDefs[utility.FromStringPtr(svc.TaskDefinition)]
+		if !ok {
+			errs.Add(errors.Errorf("cannot create service '%s' because its task definition '%s' was not restored", name, utility.FromStringPtr(svc.TaskDefinition)))
+			continue
+		}
+		createIn := exportServiceSnapshot(svc)
+		createIn.Cluster = aws.String(newClusterName)
+		createIn.TaskDefinition = aws.String(taskDef)
+		createIn.Role = opts.roleARN(createIn.Role)
+		_, err := c.CreateService(ctx, createIn)
+		errs.Wrapf(err, "creating service '%s'", name)
+	}
+
+	return errs.Resolve()
+}
+
+// describeActiveServices describes all the active services in the cluster.
+func describeActiveServices(ctx context.Context, c cocoa.ECSClient, cluster string) ([]*ecs.Service, error) {
+	var serviceARNs []string
+	in := &ecs.ListServicesInput{Cluster: aws.String(cluster)}
+	for {
+		out, err := c.ListServices(ctx, in)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing services")
+		}
+		serviceARNs = append(serviceARNs, utility.FromStringPtrSlice(out.ServiceArns)...)
+		if out.NextToken == nil {
+			break
+		}
+		in.NextToken = out.NextToken
+	}
+
+	var services []*ecs.Service
+	for start := 0; start < len(serviceARNs); start += maxDescribeServices {
+		end := start + maxDescribeServices
+		if end > len(serviceARNs) {
+			end = len(serviceARNs)
+		}
+		out, err := c.DescribeServices(ctx, &ecs.DescribeServicesInput{
+			Cluster:  aws.String(cluster),
+			Services: aws.StringSlice(serviceARNs[start:end]),
+			Include:  []*string{aws.String(ecs.ServiceFieldTags)},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "describing services")
+		}
+		if len(out.Failures) > 0 && out.Failures[0] != nil {
+			return nil, ConvertFailureToError(out.Failures[0])
+		}
+		for _, svc := range out.Services {
+			if svc == nil || utility.FromStringPtr(svc.Status) != "ACTIVE" {
+				continue
+			}
+			services = append(services, svc)
+		}
+	}
+
+	return services, nil
+}
+
+// exportServiceSnapshot converts the captured service into the input to
+// create it again. The cluster and task definition must be set separately.
+func exportServiceSnapshot(svc *ecs.Service) *ecs.CreateServiceInput {
+	in := &ecs.CreateServiceInput{
+		ServiceName:                   svc.ServiceName,
+		CapacityProviderStrategy:      svc.CapacityProviderStrategy,
+		DeploymentConfiguration:       svc.DeploymentConfiguration,
+		DeploymentController:          svc.DeploymentController,
+		DesiredCount:                  svc.DesiredCount,
+		EnableECSManagedTags:          svc.EnableECSManagedTags,
+		EnableExecuteCommand:          svc.EnableExecuteCommand,
+		HealthCheckGracePeriodSeconds: svc.HealthCheckGracePeriodSeconds,
+		LoadBalancers:                 svc.LoadBalancers,
+		NetworkConfiguration:          svc.NetworkConfiguration,
+		PlacementConstraints:          svc.PlacementConstraints,
+		PlacementStrategy:             svc.PlacementStrategy,
+		PlatformVersion:               svc.PlatformVersion,
+		PropagateTags:                 svc.PropagateTags,
+		Role:                          svc.RoleArn,
+		SchedulingStrategy:            svc.SchedulingStrategy,
+		ServiceRegistries:             svc.ServiceRegistries,
+		Tags:                          svc.Tags,
+	}
+	// A service uses either a launch type or a capacity provider strategy.
+	if len(svc.CapacityProviderStrategy) == 0 {
+		in.LaunchType = svc.LaunchType
+	}
+	return in
+}
+
+// maxDescribeServices is the maximum number of services that can be described
+// in a single DescribeServices request.
+const maxDescribeServices = 10
+
+// listTaskDefinitionsInUse lists the ARNs of the task definitions used by the
+// cluster's running tasks.
+func listTaskDefinitionsInUse(ctx context.Context, c cocoa.ECSClient, cluster string) ([]string, error) {
+	var taskARNs []string
+	in := &ecs.ListTasksInput{Cluster: aws.String(cluster)}
+	for {
+		out, err := c.ListTasks(ctx, in)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing tasks")
+		}
+		taskARNs = append(taskARNs, utility.FromStringPtrSlice(out.TaskArns)...)
+		if out.NextToken == nil {
+			break
+		}
+		in.NextToken = out.NextToken
+	}
+
+	seen := map[string]bool{}
+	var taskDefARNs []string
+	for start := 0; start < len(taskARNs); start += maxDescribeTasks {
+		end := start + maxDescribeTasks
+		if end > len(taskARNs) {
+			end = len(taskARNs)
+		}
+		out, err := c.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+			Cluster: aws.String(cluster),
+			Tasks:   aws.StringSlice(taskARNs[start:end]),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "describing tasks")
+		}
+		for _, task := range out.Tasks {
+			if task == nil {
+				continue
+			}
+			arn := utility.FromStringPtr(task.TaskDefinitionArn)
+			if arn == "" || seen[arn] {
+				continue
+			}
+			seen[arn] = true
+			taskDefARNs = append(taskDefARNs, arn)
+		}
+	}
+
+	return taskDefARNs, nil
+}
+
+// exportTaskDefinitionSnapshot converts the captured task definition into the
+// input to register it again.
+func exportTaskDefinitionSnapshot(def TaskDefinitionSnapshot) *ecs.RegisterTaskDefinitionInput {
+	td := def.Definition
+	in := &ecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions:    td.ContainerDefinitions,
+		Cpu:                     td.Cpu,
+		EphemeralStorage:        td.EphemeralStorage,
+		ExecutionRoleArn:        td.ExecutionRoleArn,
+		Family:                  td.Family,
+		InferenceAccelerators:   td.InferenceAccelerators,
+		IpcMode:                 td.IpcMode,
+		Memory:                  td.Memory,
+		NetworkMode:             td.NetworkMode,
+		PidMode:                 td.PidMode,
+		PlacementConstraints:    td.PlacementConstraints,
+		ProxyConfiguration:      td.ProxyConfiguration,
+		RequiresCompatibilities: td.RequiresCompatibilities,
+		RuntimePlatform:         td.RuntimePlatform,
+		TaskRoleArn:             td.TaskRoleArn,
+		Volumes:                 td.Volumes,
+	}
+	if len(def.Tags) != 0 {
+		in.Tags = ExportTags(def.Tags)
+	}
+	return in
+}
+
+// importTags converts ECS tags into a mapping of tag names to values.
+func importTags(tags []*ecs.Tag) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	imported := map[string]string{}
+	for _, t := range tags {
+		if t == nil {
+			continue
+		}
+		imported[utility.FromStringPtr(t.Key)] = utility.FromStringPtr(t.Value)
+	}
+	return imported
+}
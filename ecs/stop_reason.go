@@ -0,0 +1,170 @@
+package ecs
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+)
+
+// StopReasonCategory is a classification of why a task stopped.
+type StopReasonCategory string
+
+// Constants representing the categories of task stop reasons.
+const (
+	// StopReasonOOMKill indicates that a container was killed because it ran
+	// out of memory.
+	StopReasonOOMKill StopReasonCategory = "OOMKill"
+	// StopReasonTaskTimeout indicates that the task was stopped because it
+	// took too long, such as a timeout while starting or running.
+	StopReasonTaskTimeout StopReasonCategory = "TaskTimeout"
+	// StopReasonUserStopped indicates that the task was explicitly stopped
+	// with StopTask.
+	StopReasonUserStopped StopReasonCategory = "UserStopped"
+	// StopReasonClusterTermination indicates that the task was stopped
+	// because its cluster or container instance was being taken out of
+	// service, such as when draining or deregistering the instance.
+	StopReasonClusterTermination StopReasonCategory = "ClusterTermination"
+	// StopReasonNodeFailure indicates that the task was stopped because the
+	// underlying host failed or was reclaimed, such as a Spot interruption.
+	StopReasonNodeFailure StopReasonCategory = "NodeFailure"
+	// StopReasonContainerError indicates that a container failed to start or
+	// an essential container exited.
+	StopReasonContainerError StopReasonCategory = "ContainerError"
+	// StopReasonUnknown indicates that the stop reason could not be
+	// classified.
+	StopReasonUnknown StopReasonCategory = "Unknown"
+)
+
+// StopReasonAnalysis is the result of classifying why a task stopped.
+type StopReasonAnalysis struct {
+	// Category is the classification of the stop reason.
+	Category StopReasonCategory
+	// StopCode is the task's stop code, if any.
+	StopCode string
+	// Reason is the task's stop reason, if any.
+	Reason string
+	// Container is the name of the container that most likely caused the task
+	// to stop, if any.
+	Container string
+	// ExitCode is the exit code of the container that most likely caused the
+	// task to stop, if it exited.
+	ExitCode *int64
+	// Message is the reason that the container that most likely caused the
+	// task to stop exited or failed to start, if any.
+	Message string
+}
+
+// stopReasonPattern matches stop reasons that contain any of the substrings.
+type stopReasonPattern struct {
+	category   StopReasonCategory
+	substrings []string
+}
+
+// stopReasonPatterns are the patterns used to classify task and container stop
+// reasons, in order of precedence. Substrings are matched case-insensitively.
+var stopReasonPatterns = []stopReasonPattern{
+	{category: StopReasonOOMKill, substrings: []string{"outofmemory", "out of memory", "oomkill"}},
+	{category: StopReasonTaskTimeout, substrings: []string{"timeout", "timed out", "deadline exceeded"}},
+	{category: StopReasonNodeFailure, substrings: []string{"host ec2", "spot", "instance health", "instance was terminated", "instance terminated"}},
+	{category: StopReasonClusterTermination, substrings: []string{"draining", "deregister", "cluster was deleted", "cluster deleted"}},
+	{category: StopReasonUserStopped, substrings: []string{"stopped by user", "user initiated"}},
+	{category: StopReasonContainerError, substrings: []string{"essential container", "cannotpullcontainer", "cannotstartcontainer", "cannotcreatecontainer", "resourceinitializationerror", "containerruntimeerror"}},
+}
+
+// AnalyzeTaskStopReason classifies why the task stopped based on its stop
+// code, stop reason and the state of its containers. It also identifies the
+// container that most likely caused the task to stop, preferring a container
+// that ran out of memory, then a container that exited with a non-zero exit
+// code, then a container that has a reason for failing. If the task has not
+// stopped or its stop reason cannot be classified, the category is
+// StopReasonUnknown.
+func AnalyzeTaskStopReason(task *ecs.Task) *StopReasonAnalysis {
+	analysis := &StopReasonAnalysis{Category: StopReasonUnknown}
+	if task == nil {
+		return analysis
+	}
+
+	analysis.StopCode = utility.FromStringPtr(task.StopCode)
+	analysis.Reason = ExtractStopReason(task)
+
+	if c := findDominantContainer(task.Containers); c != nil {
+		analysis.Container = utility.FromStringPtr(c.Name)
+		analysis.ExitCode = c.ExitCode
+		analysis.Message = utility.FromStringPtr(c.Reason)
+	}
+
+	analysis.Category = classifyStopReason(analysis)
+
+	return analysis
+}
+
+// classifyStopReason classifies the stop reason from the analyzed task
+// information.
+func classifyStopReason(analysis *StopReasonAnalysis) StopReasonCategory {
+	if category, ok := matchStopReasonPattern(analysis.Message); ok && category == StopReasonOOMKill {
+		return category
+	}
+	if category, ok := matchStopReasonPattern(analysis.Reason); ok {
+		return category
+	}
+
+	switch analysis.StopCode {
+	case ecs.TaskStopCodeSpotInterruption, ecs.TaskStopCodeTerminationNotice:
+		return StopReasonNodeFailure
+	case ecs.TaskStopCodeUserInitiated:
+		return StopReasonUserStopped
+	case ecs.TaskStopCodeEssentialContainerExited, ecs.TaskStopCodeTaskFailedToStart:
+		return StopReasonContainerError
+	}
+
+	if category, ok := matchStopReasonPattern(analysis.Message); ok {
+		return category
+	}
+	if analysis.ExitCode != nil && *analysis.ExitCode != 0 {
+		return StopReasonContainerError
+	}
+
+	return StopReasonUnknown
+}
+
+// matchStopReasonPattern returns the category of the first pattern that
+// matches the reason.
+func matchStopReasonPattern(reason string) (StopReasonCategory, bool) {
+	if reason == "" {
+		return "", false
+	}
+	reason = strings.ToLower(reason)
+	for _, p := range stopReasonPatterns {
+		for _, s := range p.substrings {
+			if strings.Contains(reason, s) {
+				return p.category, true
+			}
+		}
+	}
+	return "", false
+}
+
+// findDominantContainer returns the container that most likely caused the
+// task to stop, or nil if no container appears to have caused it.
+func findDominantContainer(containers []*ecs.Container) *ecs.Container {
+	var nonZeroExit, withReason *ecs.Container
+	for _, c := range containers {
+		if c == nil {
+			continue
+		}
+		if category, ok := matchStopReasonPattern(utility.FromStringPtr(c.Reason)); ok && category == StopReasonOOMKill {
+			return c
+		}
+		if nonZeroExit == nil && c.ExitCode != nil && *c.ExitCode != 0 {
+			nonZeroExit = c
+		}
+		if withReason == nil && utility.FromStringPtr(c.Reason) != "" {
+			withReason = c
+		}
+	}
+	if nonZeroExit != nil {
+		return nonZeroExit
+	}
+	return withReason
+}
@@ -0,0 +1,108 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeTaskStopReason(t *testing.T) {
+	t.Run("ReturnsUnknownForNilTask", func(t *testing.T) {
+		analysis := AnalyzeTaskStopReason(nil)
+		require.NotZero(t, analysis)
+		assert.Equal(t, StopReasonUnknown, analysis.Category)
+	})
+	t.Run("ReturnsUnknownForTaskWithoutStopReason", func(t *testing.T) {
+		analysis := AnalyzeTaskStopReason(&ecs.Task{LastStatus: aws.String(string(TaskStatusRunning))})
+		assert.Equal(t, StopReasonUnknown, analysis.Category)
+		assert.Empty(t, analysis.Container)
+		assert.Zero(t, analysis.ExitCode)
+	})
+	t.Run("ClassifiesOOMKill", func(t *testing.T) {
+		analysis := AnalyzeTaskStopReason(&ecs.Task{
+			StopCode:      aws.String(ecs.TaskStopCodeEssentialContainerExited),
+			StoppedReason: aws.String("Essential container in task exited"),
+			Containers: []*ecs.Container{
+				{Name: aws.String("sidecar"), ExitCode: aws.Int64(1)},
+				{Name: aws.String("app"), ExitCode: aws.Int64(137), Reason: aws.String("OutOfMemoryError: Container killed due to memory usage")},
+			},
+		})
+		assert.Equal(t, StopReasonOOMKill, analysis.Category)
+		assert.Equal(t, "app", analysis.Container)
+		assert.EqualValues(t, 137, utility.FromInt64Ptr(analysis.ExitCode))
+		assert.Contains(t, analysis.Message, "OutOfMemoryError")
+		assert.Equal(t, ecs.TaskStopCodeEssentialContainerExited, analysis.StopCode)
+		assert.Equal(t, "Essential container in task exited", analysis.Reason)
+	})
+	t.Run("ClassifiesTaskTimeout", func(t *testing.T) {
+		analysis := AnalyzeTaskStopReason(&ecs.Task{
+			StopCode:      aws.String(ecs.TaskStopCodeUserInitiated),
+			StoppedReason: aws.String("task timed out after 1h"),
+		})
+		assert.Equal(t, StopReasonTaskTimeout, analysis.Category)
+	})
+	t.Run("ClassifiesUserStopped", func(t *testing.T) {
+		analysis := AnalyzeTaskStopReason(&ecs.Task{
+			StopCode:      aws.String(ecs.TaskStopCodeUserInitiated),
+			StoppedReason: aws.String("no longer needed"),
+			Containers:    []*ecs.Container{{Name: aws.String("app"), ExitCode: aws.Int64(143)}},
+		})
+		assert.Equal(t, StopReasonUserStopped, analysis.Category)
+		assert.Equal(t, "app", analysis.Container)
+		assert.EqualValues(t, 143, utility.FromInt64Ptr(analysis.ExitCode))
+	})
+	t.Run("ClassifiesClusterTermination", func(t *testing.T) {
+		analysis := AnalyzeTaskStopReason(&ecs.Task{
+			StoppedReason: aws.String("Container instance is draining"),
+		})
+		assert.Equal(t, StopReasonClusterTermination, analysis.Category)
+	})
+	t.Run("ClassifiesNodeFailureFromReason", func(t *testing.T) {
+		analysis := AnalyzeTaskStopReason(&ecs.Task{
+			StoppedReason: aws.String("Host EC2 (instance i-1234567890abcdef0) terminated."),
+		})
+		assert.Equal(t, StopReasonNodeFailure, analysis.Category)
+	})
+	t.Run("ClassifiesNodeFailureFromStopCode", func(t *testing.T) {
+		analysis := AnalyzeTaskStopReason(&ecs.Task{
+			StopCode: aws.String(ecs.TaskStopCodeTerminationNotice),
+		})
+		assert.Equal(t, StopReasonNodeFailure, analysis.Category)
+	})
+	t.Run("ClassifiesContainerError", func(t *testing.T) {
+		analysis := AnalyzeTaskStopReason(&ecs.Task{
+			StopCode:      aws.String(ecs.TaskStopCodeEssentialContainerExited),
+			StoppedReason: aws.String("Essential container in task exited"),
+			Containers: []*ecs.Container{
+				{Name: aws.String("sidecar"), ExitCode: aws.Int64(0)},
+				{Name: aws.String("app"), ExitCode: aws.Int64(2), Reason: aws.String("exit status 2")},
+			},
+		})
+		assert.Equal(t, StopReasonContainerError, analysis.Category)
+		assert.Equal(t, "app", analysis.Container)
+		assert.EqualValues(t, 2, utility.FromInt64Ptr(analysis.ExitCode))
+		assert.Equal(t, "exit status 2", analysis.Message)
+	})
+	t.Run("ClassifiesContainerErrorForContainerThatFailedToStart", func(t *testing.T) {
+		analysis := AnalyzeTaskStopReason(&ecs.Task{
+			StopCode: aws.String(ecs.TaskStopCodeTaskFailedToStart),
+			Containers: []*ecs.Container{
+				{Name: aws.String("app"), Reason: aws.String("CannotPullContainerError: pull image manifest has been retried 5 time(s)")},
+			},
+		})
+		assert.Equal(t, StopReasonContainerError, analysis.Category)
+		assert.Equal(t, "app", analysis.Container)
+		assert.Zero(t, analysis.ExitCode)
+		assert.Contains(t, analysis.Message, "CannotPullContainerError")
+	})
+	t.Run("ClassifiesContainerErrorFromExitCodeAlone", func(t *testing.T) {
+		analysis := AnalyzeTaskStopReason(&ecs.Task{
+			Containers: []*ecs.Container{{Name: aws.String("app"), ExitCode: aws.Int64(1)}},
+		})
+		assert.Equal(t, StopReasonContainerError, analysis.Category)
+	})
+}
@@ -0,0 +1,31 @@
+package ecs
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/evergreen-ci/utility"
+)
+
+// MergeECSTags merges the overlay tags into the base tags. If a tag key is in
+// both, the overlay tag's value takes precedence. Tags are returned in the
+// order that their keys first appear.
+func MergeECSTags(base, overlay []*ecs.Tag) []*ecs.Tag {
+	return awsutil.MergeECSTags(base, overlay)
+}
+
+// FilterECSTags returns the tags without any of the excluded tag keys.
+func FilterECSTags(tags []*ecs.Tag, exclude []string) []*ecs.Tag {
+	var filtered []*ecs.Tag
+	for _, t := range tags {
+		if t == nil {
+			continue
+		}
+		key := utility.FromStringPtr(t.Key)
+		if utility.StringSliceContains(exclude, key) {
+			continue
+		}
+		filtered = append(filtered, &ecs.Tag{Key: aws.String(key), Value: aws.String(utility.FromStringPtr(t.Value))})
+	}
+	return filtered
+}
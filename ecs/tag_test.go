@@ -0,0 +1,57 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeECSTags(t *testing.T) {
+	t.Run("OverlayTakesPrecedence", func(t *testing.T) {
+		merged := MergeECSTags(
+			[]*ecs.Tag{
+				{Key: aws.String("a"), Value: aws.String("1")},
+				{Key: aws.String("b"), Value: aws.String("2")},
+			},
+			[]*ecs.Tag{
+				{Key: aws.String("b"), Value: aws.String("3")},
+				{Key: aws.String("c"), Value: aws.String("4")},
+			},
+		)
+		assert.Equal(t, []*ecs.Tag{
+			{Key: aws.String("a"), Value: aws.String("1")},
+			{Key: aws.String("b"), Value: aws.String("3")},
+			{Key: aws.String("c"), Value: aws.String("4")},
+		}, merged)
+	})
+	t.Run("ReturnsBaseWithoutOverlay", func(t *testing.T) {
+		base := []*ecs.Tag{{Key: aws.String("a"), Value: aws.String("1")}}
+		assert.Equal(t, base, MergeECSTags(base, nil))
+	})
+	t.Run("ReturnsOverlayWithoutBase", func(t *testing.T) {
+		overlay := []*ecs.Tag{{Key: aws.String("a"), Value: aws.String("1")}}
+		assert.Equal(t, overlay, MergeECSTags(nil, overlay))
+	})
+}
+
+func TestFilterECSTags(t *testing.T) {
+	tags := []*ecs.Tag{
+		{Key: aws.String("a"), Value: aws.String("1")},
+		nil,
+		{Key: aws.String("b"), Value: aws.String("2")},
+		{Key: aws.String("c"), Value: aws.String("3")},
+	}
+	t.Run("RemovesExcludedKeys", func(t *testing.T) {
+		assert.Equal(t, []*ecs.Tag{
+			{Key: aws.String("b"), Value: aws.String("2")},
+		}, FilterECSTags(tags, []string{"a", "c", "nonexistent"}))
+	})
+	t.Run("ReturnsAllTagsWithoutExclusions", func(t *testing.T) {
+		assert.Len(t, FilterECSTags(tags, nil), 3)
+	})
+	t.Run("ReturnsNoTagsForNoInput", func(t *testing.T) {
+		assert.Empty(t, FilterECSTags(nil, []string{"a"}))
+	})
+}
@@ -0,0 +1,253 @@
+package ecs
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+// defaultTaskPollInterval is the default interval between checks of a task's
+// status when waiting for it to reach a particular state.
+const defaultTaskPollInterval = time.Second
+
+// StopTaskGracefully stops the task and waits up to the grace period for it to
+// reach the stopped state. Stopping a task sends SIGTERM to its containers, but
+// ECS will forcibly kill the containers if they do not exit before the
+// container's stop timeout. If the task is still not stopped after the grace
+// period, this logs a warning that the task did not stop gracefully.
+func StopTaskGracefully(ctx context.Context, c cocoa.ECSClient, cluster, taskARN, reason string, gracePeriod time.Duration) error {
+	if _, err := c.StopTask(ctx, &ecs.StopTaskInput{
+		Cluster: aws.String(cluster),
+		Task:    aws.String(taskARN),
+		Reason:  aws.String(reason),
+	}); err != nil {
+		return errors.Wrap(err, "stopping task")
+	}
+
+	graceTimer := time.NewTimer(gracePeriod)
+	defer graceTimer.Stop()
+	pollTimer := time.NewTimer(0)
+	defer pollTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "waiting for task to stop")
+		case <-graceTimer.C:
+			grip.Warning(message.Fields{
+				"message":      "task did not stop gracefully within the grace period",
+				"task":         taskARN,
+				"cluster":      cluster,
+				"grace_period": gracePeriod.String(),
+			})
+			return nil
+		case <-pollTimer.C:
+			task, err := describeTask(ctx, c, cluster, taskARN)
+			if err != nil {
+				return errors.Wrap(err, "checking task status")
+			}
+			if TaskStatus(utility.FromStringPtr(task.LastStatus)) == TaskStatusStopped {
+				return nil
+			}
+			pollTimer.Reset(defaultTaskPollInterval)
+		}
+	}
+}
+
+// WaitForTaskStatus polls the task until the predicate is satisfied and returns
+// the task that satisfied it. This can be used to wait for arbitrary task
+// conditions, such as a particular container becoming healthy. If the poll
+// interval is not positive, it defaults to 1 second.
+func WaitForTaskStatus(ctx context.Context, c cocoa.ECSClient, cluster, taskARN string, predicate func(*ecs.Task) bool, pollInterval time.Duration) (*ecs.Task, error) {
+	if predicate == nil {
+		return nil, errors.New("must specify a predicate")
+	}
+	if pollInterval <= 0 {
+		pollInterval = defaultTaskPollInterval
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "waiting for task status")
+		case <-timer.C:
+			task, err := describeTask(ctx, c, cluster, taskARN)
+			if err != nil {
+				return nil, errors.Wrap(err, "checking task status")
+			}
+			if predicate(task) {
+				return task, nil
+			}
+			timer.Reset(pollInterval)
+		}
+	}
+}
+
+// ExtractContainerExitCodes returns the exit codes of the task's containers,
+// keyed by container name. If a container does not have an exit code (e.g.
+// because it has not exited yet or it failed to start), its exit code is -1.
+func ExtractContainerExitCodes(task *ecs.Task) map[string]int64 {
+	if task == nil {
+		return nil
+	}
+
+	exitCodes := map[string]int64{}
+	for _, c := range task.Containers {
+		if c == nil {
+			continue
+		}
+		exitCode := int64(-1)
+		if c.ExitCode != nil {
+			exitCode = *c.ExitCode
+		}
+		exitCodes[utility.FromStringPtr(c.Name)] = exitCode
+	}
+	return exitCodes
+}
+
+// ExtractStopReason returns the reason that the task stopped. If the task has
+// not stopped, this returns an empty string.
+func ExtractStopReason(task *ecs.Task) string {
+	if task == nil {
+		return ""
+	}
+	return utility.FromStringPtr(task.StoppedReason)
+}
+
+// TaskNetworkInfo contains information about the network interface of a task
+// using the awsvpc network mode.
+type TaskNetworkInfo struct {
+	// PrivateIP is the private IPv4 address of the task.
+	PrivateIP string
+	// PublicIP is the public IPv4 address of the task. ECS does not include the
+	// public IP address in the task description, so this is only set if the
+	// task's network interface details include it.
+	PublicIP string
+	// ENI is the ID of the task's elastic network interface.
+	ENI string
+	// SubnetID is the ID of the subnet that the task's network interface is
+	// in.
+	SubnetID string
+}
+
+const (
+	// attachmentTypeENI is the type of task attachment for an elastic network
+	// interface.
+	attachmentTypeENI = "ElasticNetworkInterface"
+
+	eniDetailPrivateIP = "privateIPv4Address"
+	eniDetailPublicIP  = "publicIPv4Address"
+	eniDetailID        = "networkInterfaceId"
+	eniDetailSubnetID  = "subnetId"
+)
+
+// ExtractTaskNetworkInfo returns the network information from the task's
+// elastic network interface attachment. This returns an error if the task has
+// no network interface attached, such as when the task is not running yet or
+// does not use the awsvpc network mode.
+func ExtractTaskNetworkInfo(task *ecs.Task) (*TaskNetworkInfo, error) {
+	if task == nil {
+		return nil, errors.New("cannot extract network information from nil task")
+	}
+
+	for _, a := range task.Attachments {
+		if a == nil || utility.FromStringPtr(a.Type) != attachmentTypeENI {
+			continue
+		}
+
+		var info TaskNetworkInfo
+		for _, d := range a.Details {
+			if d == nil {
+				continue
+			}
+			v := utility.FromStringPtr(d.Value)
+			switch utility.FromStringPtr(d.Name) {
+			case eniDetailPrivateIP:
+				info.PrivateIP = v
+			case eniDetailPublicIP:
+				info.PublicIP = v
+			case eniDetailID:
+				info.ENI = v
+			case eniDetailSubnetID:
+				info.SubnetID = v
+			}
+		}
+		if info.ENI == "" {
+			return nil, errors.New("network interface attachment is missing the network interface ID")
+		}
+
+		return &info, nil
+	}
+
+	return nil, errors.New("task has no network interface attachment")
+}
+
+// maxRunTaskCount is the maximum number of tasks that can be started by a
+// single RunTask request.
+const maxRunTaskCount = 10
+
+// ValidateRunTaskInput checks that the input to run a task specifies the
+// cluster, the task definition, a count between 1 and 10, and a valid launch
+// type if one is set. Tasks using the Fargate launch type always use the awsvpc
+// network mode, so they must also specify the awsvpc network configuration.
+func ValidateRunTaskInput(in *ecs.RunTaskInput) error {
+	if in == nil {
+		return errors.New("cannot validate nil input")
+	}
+
+	catcher := grip.NewBasicCatcher()
+	catcher.NewWhen(utility.FromStringPtr(in.Cluster) == "", "must specify a cluster")
+	catcher.NewWhen(utility.FromStringPtr(in.TaskDefinition) == "", "must specify a task definition")
+	if in.Count == nil {
+		catcher.New("must specify a count")
+	} else {
+		catcher.ErrorfWhen(*in.Count < 1 || *in.Count > maxRunTaskCount, "count must be between 1 and %d, but is %d", maxRunTaskCount, *in.Count)
+	}
+
+	launchType := utility.FromStringPtr(in.LaunchType)
+	if in.LaunchType != nil {
+		catcher.ErrorfWhen(!utility.StringSliceContains(ecs.LaunchType_Values(), launchType), "unrecognized launch type '%s'", launchType)
+	}
+	if launchType == ecs.LaunchTypeFargate {
+		catcher.NewWhen(in.NetworkConfiguration == nil || in.NetworkConfiguration.AwsvpcConfiguration == nil, "must specify the awsvpc network configuration for the Fargate launch type")
+	}
+	if in.NetworkConfiguration != nil && in.NetworkConfiguration.AwsvpcConfiguration != nil {
+		catcher.NewWhen(len(in.NetworkConfiguration.AwsvpcConfiguration.Subnets) == 0, "must specify at least one subnet for the awsvpc network configuration")
+	}
+
+	return catcher.Resolve()
+}
+
+// describeTask describes a single task.
+func describeTask(ctx context.Context, c cocoa.ECSClient, cluster, taskARN string) (*ecs.Task, error) {
+	out, err := c.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+		Cluster: aws.String(cluster),
+		Tasks:   []*string{aws.String(taskARN)},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describing task")
+	}
+	var errs cocoa.MultiError
+	for _, f := range out.Failures {
+		if f != nil {
+			errs.Add(ConvertFailureToError(f))
+		}
+	}
+	if err := errs.Resolve(); err != nil {
+		return nil, err
+	}
+	if len(out.Tasks) == 0 || out.Tasks[0] == nil {
+		return nil, errors.Errorf("task '%s' was not returned in the response", taskARN)
+	}
+	return out.Tasks[0], nil
+}
@@ -0,0 +1,472 @@
+package ecs
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// ValidateRegisterTaskDefinitionInput checks that the input to register a task
+// definition has a family, has a supported combination of CPU and memory if it
+// requires Fargate compatibility, and does not have multiple containers with
+// the same name.
+// These would otherwise only be caught by ECS when attempting to register the
+// task definition.
+func ValidateRegisterTaskDefinitionInput(in *ecs.RegisterTaskDefinitionInput) error {
+	if in == nil {
+		return errors.New("cannot validate nil input")
+	}
+
+	catcher := grip.NewBasicCatcher()
+	catcher.NewWhen(utility.FromStringPtr(in.Family) == "", "must specify a family")
+
+	if utility.StringSliceContains(utility.FromStringPtrSlice(in.RequiresCompatibilities), ecs.CompatibilityFargate) {
+		cpu := utility.FromStringPtr(in.Cpu)
+		memory := utility.FromStringPtr(in.Memory)
+		catcher.NewWhen(cpu == "", "must specify CPU for a task definition that requires Fargate compatibility")
+		catcher.NewWhen(memory == "", "must specify memory for a task definition that requires Fargate compatibility")
+		if cpu != "" && memory != "" {
+			catcher.Wrap(validateFargateTaskDefinitionResources(cpu, memory), "invalid Fargate resources")
+		}
+	}
+
+	names := map[string]bool{}
+	for _, def := range in.ContainerDefinitions {
+		if def == nil {
+			continue
+		}
+		name := utility.FromStringPtr(def.Name)
+		catcher.ErrorfWhen(names[name], "container name '%s' cannot be used by multiple containers", name)
+		names[name] = true
+	}
+
+	return catcher.Resolve()
+}
+
+// DeregisterOldRevisions deregisters all the active revisions of the task
+// definition family except for the most recent keepLatest revisions and returns
+// the ARNs of the task definitions that were deregistered. ECS does not have an
+// API to deregister task definitions in bulk, so each revision is deregistered
+// individually. If any revision cannot be deregistered, this returns the ARNs
+// that were successfully deregistered along with a cocoa.MultiError containing
+// the errors.
+func DeregisterOldRevisions(ctx context.Context, c cocoa.ECSClient, family string, keepLatest int) ([]string, error) {
+	if family == "" {
+		return nil, errors.New("must specify a task definition family")
+	}
+	if keepLatest < 0 {
+		return nil, errors.New("number of revisions to keep cannot be negative")
+	}
+
+	revisions, err := listActiveRevisions(ctx, c, family)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing active revisions")
+	}
+	if len(revisions) <= keepLatest {
+		return nil, nil
+	}
+
+	var errs cocoa.MultiError
+	var deregistered []string
+	for _, rev := range revisions[keepLatest:] {
+		if ctx.Err() != nil {
+			errs.Wrapf(ctx.Err(), "deregistering old revisions")
+			break
+		}
+		if _, err := c.DeregisterTaskDefinition(ctx, &ecs.DeregisterTaskDefinitionInput{
+			TaskDefinition: aws.String(rev.arn),
+		}); err != nil {
+			errs.Wrapf(err, "deregistering task definition '%s'", rev.arn)
+			continue
+		}
+		deregistered = append(deregistered, rev.arn)
+	}
+
+	return deregistered, errs.Resolve()
+}
+
+// taskDefinitionRevision is a task definition ARN and its parsed revision.
+type taskDefinitionRevision struct {
+	arn      string
+	revision int64
+}
+
+// listActiveRevisions lists all the active revisions of the task definition
+// family, sorted from newest to oldest.
+func listActiveRevisions(ctx context.Context, c cocoa.ECSClient, family string) ([]taskDefinitionRevision, error) {
+	arns, err := ListActiveTaskDefinitions(ctx, c, family)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]taskDefinitionRevision, 0, len(arns))
+	for _, arn := range utility.FromStringPtrSlice(arns) {
+		parsed, err := ParseTaskDefinitionARN(arn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing task definition ARN '%s'", arn)
+		}
+		revisions = append(revisions, taskDefinitionRevision{arn: arn, revision: parsed.Revision})
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].revision > revisions[j].revision
+	})
+
+	return revisions, nil
+}
+
+// ListActiveTaskDefinitions lists the ARNs of all the active task definitions
+// in the family, paginating through all the results. If the family is empty,
+// it lists the active task definitions in all families.
+func ListActiveTaskDefinitions(ctx context.Context, c cocoa.ECSClient, family string) ([]*string, error) {
+	return listTaskDefinitionsWithStatus(ctx, c, family, ecs.TaskDefinitionStatusActive)
+}
+
+// ListInactiveTaskDefinitions lists the ARNs of all the inactive (i.e.
+// deregistered) task definitions in the family, paginating through all the
+// results. If the family is empty, it lists the inactive task definitions in
+// all families.
+func ListInactiveTaskDefinitions(ctx context.Context, c cocoa.ECSClient, family string) ([]*string, error) {
+	return listTaskDefinitionsWithStatus(ctx, c, family, ecs.TaskDefinitionStatusInactive)
+}
+
+// listTaskDefinitionsWithStatus lists the ARNs of all the task definitions in
+// the family that have the given status.
+func listTaskDefinitionsWithStatus(ctx context.Context, c cocoa.ECSClient, family, status string) ([]*string, error) {
+	in := &ecs.ListTaskDefinitionsInput{
+		Status: aws.String(status),
+	}
+	if family != "" {
+		in.FamilyPrefix = aws.String(family)
+	}
+
+	var arns []*string
+	for {
+		out, err := c.ListTaskDefinitions(ctx, in)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing task definitions")
+		}
+
+		for _, arn := range out.TaskDefinitionArns {
+			if arn == nil {
+				continue
+			}
+			if family != "" {
+				parsed, err := ParseTaskDefinitionARN(*arn)
+				if err != nil {
+					return nil, errors.Wrapf(err, "parsing task definition ARN '%s'", *arn)
+				}
+				// The family prefix also matches other families that share
+				// the same prefix.
+				if parsed.Family != family {
+					continue
+				}
+			}
+			arns = append(arns, arn)
+		}
+
+		if out.NextToken == nil {
+			return arns, nil
+		}
+		in.NextToken = out.NextToken
+	}
+}
+
+// TaskDefinitionEqual returns whether the two task definitions are equivalent
+// for the purpose of deploying them. It compares the fields that affect how
+// tasks run, such as the container definitions, CPU, memory, network mode and
+// volumes, and ignores metadata that ECS sets when the task definition is
+// registered, such as the ARN, revision, status and registration time.
+// Fields that ECS fills in with a default when they are omitted are compared
+// using that default, so a task definition that omits them is equal to the
+// registered one; specifically, containers are essential by default, port
+// mappings use TCP by default, and port mappings in the awsvpc and host
+// network modes use the container port as the host port by default. Other
+// unset fields are considered equal to their zero values, and CPU and memory
+// given in different units (e.g. "1 vCPU" and "1024") are considered equal.
+func TaskDefinitionEqual(a, b *ecs.TaskDefinition) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return newTaskDefinitionSpec(a).equal(newTaskDefinitionSpec(b))
+}
+
+// RegisterIfChanged registers the task definition only if it differs from the
+// latest active revision in its family, as determined by TaskDefinitionEqual.
+// It returns the task definition that should be used, which is the existing
+// latest active revision if it is unchanged or the newly-registered revision
+// otherwise, along with whether a new revision was registered. This makes it
+// possible to repeatedly register the same task definition without creating
+// redundant revisions.
+func RegisterIfChanged(ctx context.Context, c cocoa.ECSClient, in *ecs.RegisterTaskDefinitionInput) (*ecs.TaskDefinition, bool, error) {
+	if err := ValidateRegisterTaskDefinitionInput(in); err != nil {
+		return nil, false, errors.Wrap(err, "invalid task definition")
+	}
+
+	family := utility.FromStringPtr(in.Family)
+	// Describing a task definition by its family returns the latest active
+	// revision in the family.
+	out, err := c.DescribeTaskDefinition(ctx, &ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(family),
+	})
+	if err != nil && !isTaskDefinitionNotFoundError(err) {
+		return nil, false, errors.Wrapf(err, "describing latest task definition in family '%s'", family)
+	}
+	if err == nil && out.TaskDefinition != nil && newTaskDefinitionSpec(out.TaskDefinition).equal(newRegisterTaskDefinitionSpec(in)) {
+		return out.TaskDefinition, false, nil
+	}
+
+	registered, err := c.RegisterTaskDefinition(ctx, in)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "registering task definition in family '%s'", family)
+	}
+	if registered.TaskDefinition == nil {
+		return nil, false, errors.New("expected a task definition in the response, but none was returned")
+	}
+
+	return registered.TaskDefinition, true, nil
+}
+
+// isTaskDefinitionNotFoundError returns whether the error is due to ECS not
+// being able to find the task definition. ECS returns a generic client error
+// when describing a task definition family that has no active revisions.
+func isTaskDefinitionNotFoundError(err error) bool {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+	switch awsErr.Code() {
+	case ecs.ErrCodeClientException, ecs.ErrCodeResourceNotFoundException:
+		return true
+	default:
+		return false
+	}
+}
+
+// taskDefinitionSpec is the deployment-relevant part of a task definition.
+type taskDefinitionSpec struct {
+	Family                  *string
+	ContainerDefinitions    []*ecs.ContainerDefinition
+	Cpu                     *string
+	Memory                  *string
+	NetworkMode             *string
+	Volumes                 []*ecs.Volume
+	TaskRoleArn             *string
+	ExecutionRoleArn        *string
+	RequiresCompatibilities []*string
+	PlacementConstraints    []*ecs.TaskDefinitionPlacementConstraint
+	PidMode                 *string
+	IpcMode                 *string
+	ProxyConfiguration      *ecs.ProxyConfiguration
+	RuntimePlatform         *ecs.RuntimePlatform
+	EphemeralStorage        *ecs.EphemeralStorage
+	InferenceAccelerators   []*ecs.InferenceAccelerator
+}
+
+func newTaskDefinitionSpec(def *ecs.TaskDefinition) taskDefinitionSpec {
+	return taskDefinitionSpec{
+		Family:                  def.Family,
+		ContainerDefinitions:    withContainerDefinitionDefaults(def.ContainerDefinitions, utility.FromStringPtr(def.NetworkMode)),
+		Cpu:                     def.Cpu,
+		Memory:                  def.Memory,
+		NetworkMode:             def.NetworkMode,
+		Volumes:                 def.Volumes,
+		TaskRoleArn:             def.TaskRoleArn,
+		ExecutionRoleArn:        def.ExecutionRoleArn,
+		RequiresCompatibilities: def.RequiresCompatibilities,
+		PlacementConstraints:    def.PlacementConstraints,
+		PidMode:                 def.PidMode,
+		IpcMode:                 def.IpcMode,
+		ProxyConfiguration:      def.ProxyConfiguration,
+		RuntimePlatform:         def.RuntimePlatform,
+		EphemeralStorage:        def.EphemeralStorage,
+		InferenceAccelerators:   def.InferenceAccelerators,
+	}
+}
+
+func newRegisterTaskDefinitionSpec(in *ecs.RegisterTaskDefinitionInput) taskDefinitionSpec {
+	return taskDefinitionSpec{
+		Family:                  in.Family,
+		ContainerDefinitions:    withContainerDefinitionDefaults(in.ContainerDefinitions, utility.FromStringPtr(in.NetworkMode)),
+		Cpu:                     in.Cpu,
+		Memory:                  in.Memory,
+		NetworkMode:             in.NetworkMode,
+		Volumes:                 in.Volumes,
+		TaskRoleArn:             in.TaskRoleArn,
+		ExecutionRoleArn:        in.ExecutionRoleArn,
+		RequiresCompatibilities: in.RequiresCompatibilities,
+		PlacementConstraints:    in.PlacementConstraints,
+		PidMode:                 in.PidMode,
+		IpcMode:                 in.IpcMode,
+		ProxyConfiguration:      in.ProxyConfiguration,
+		RuntimePlatform:         in.RuntimePlatform,
+		EphemeralStorage:        in.EphemeralStorage,
+		InferenceAccelerators:   in.InferenceAccelerators,
+	}
+}
+
+// withContainerDefinitionDefaults returns copies of the container definitions
+// with the defaults that ECS fills in for omitted fields when the task
+// definition is registered.
+func withContainerDefinitionDefaults(defs []*ecs.ContainerDefinition, networkMode string) []*ecs.ContainerDefinition {
+	if defs == nil {
+		return nil
+	}
+
+	withDefaults := make([]*ecs.ContainerDefinition, 0, len(defs))
+	for _, def := range defs {
+		if def == nil {
+			withDefaults = append(withDefaults, nil)
+			continue
+		}
+
+		defCopy := *def
+		if defCopy.Essential == nil {
+			defCopy.Essential = aws.Bool(true)
+		}
+		if def.PortMappings != nil {
+			defCopy.PortMappings = make([]*ecs.PortMapping, 0, len(def.PortMappings))
+			for _, pm := range def.PortMappings {
+				if pm == nil {
+					defCopy.PortMappings = append(defCopy.PortMappings, nil)
+					continue
+				}
+				pmCopy := *pm
+				if pmCopy.Protocol == nil {
+					pmCopy.Protocol = aws.String(ecs.TransportProtocolTcp)
+				}
+				if pmCopy.HostPort == nil && (networkMode == ecs.NetworkModeAwsvpc || networkMode == ecs.NetworkModeHost) {
+					pmCopy.HostPort = pmCopy.ContainerPort
+				}
+				defCopy.PortMappings = append(defCopy.PortMappings, &pmCopy)
+			}
+		}
+		withDefaults = append(withDefaults, &defCopy)
+	}
+
+	return withDefaults
+}
+
+func (s taskDefinitionSpec) equal(other taskDefinitionSpec) bool {
+	if !taskDefinitionResourceEqual(s.Cpu, other.Cpu, "vcpu") || !taskDefinitionResourceEqual(s.Memory, other.Memory, "gb") {
+		return false
+	}
+	compatibilities := utility.FromStringPtrSlice(s.RequiresCompatibilities)
+	otherCompatibilities := utility.FromStringPtrSlice(other.RequiresCompatibilities)
+	sort.Strings(compatibilities)
+	sort.Strings(otherCompatibilities)
+	if !equalIgnoringUnset(reflect.ValueOf(compatibilities), reflect.ValueOf(otherCompatibilities)) {
+		return false
+	}
+
+	s.Cpu, other.Cpu = nil, nil
+	s.Memory, other.Memory = nil, nil
+	s.RequiresCompatibilities, other.RequiresCompatibilities = nil, nil
+
+	return equalIgnoringUnset(reflect.ValueOf(s), reflect.ValueOf(other))
+}
+
+// taskDefinitionResourceEqual returns whether the task-level CPU or memory
+// values are equal after converting them to integer units.
+func taskDefinitionResourceEqual(a, b *string, unit string) bool {
+	aVal := utility.FromStringPtr(a)
+	bVal := utility.FromStringPtr(b)
+	if aVal == bVal {
+		return true
+	}
+	if aVal == "" || bVal == "" {
+		return false
+	}
+	aParsed, aErr := parseTaskDefinitionResource(aVal, unit)
+	bParsed, bErr := parseTaskDefinitionResource(bVal, unit)
+	return aErr == nil && bErr == nil && aParsed == bParsed
+}
+
+// equalIgnoringUnset returns whether the two values are deeply equal, treating
+// nil pointers as equal to pointers to zero values and nil slices and maps as
+// equal to empty ones. ECS fills in defaults for some unset fields when
+// registering a task definition, so a strict comparison would report
+// differences that have no effect on the deployment.
+func equalIgnoringUnset(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return isUnset(a) && isUnset(b)
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return isUnset(a) && isUnset(b)
+		}
+		return equalIgnoringUnset(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if a.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			if !equalIgnoringUnset(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalIgnoringUnset(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			bVal := b.MapIndex(iter.Key())
+			if !bVal.IsValid() || !equalIgnoringUnset(iter.Value(), bVal) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Interface() == b.Interface()
+	}
+}
+
+// isUnset returns whether the value is missing, nil, empty or the zero value.
+func isUnset(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil() || isUnset(v.Elem())
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			if !isUnset(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,221 @@
+package ecs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRegisterTaskDefinitionInput(t *testing.T) {
+	validInput := func() *ecs.RegisterTaskDefinitionInput {
+		return testutil.GenerateTaskDefinitionFixture("family", "busybox")
+	}
+
+	t.Run("SucceedsWithValidInput", func(t *testing.T) {
+		assert.NoError(t, ValidateRegisterTaskDefinitionInput(validInput()))
+	})
+	t.Run("SucceedsWithoutCPUAndMemoryForEC2", func(t *testing.T) {
+		in := validInput()
+		in.RequiresCompatibilities = []*string{aws.String(ecs.CompatibilityEc2)}
+		in.Cpu = nil
+		in.Memory = nil
+		assert.NoError(t, ValidateRegisterTaskDefinitionInput(in))
+	})
+	t.Run("FailsWithNilInput", func(t *testing.T) {
+		assert.Error(t, ValidateRegisterTaskDefinitionInput(nil))
+	})
+	t.Run("FailsWithoutFamily", func(t *testing.T) {
+		in := validInput()
+		in.Family = nil
+		assert.Error(t, ValidateRegisterTaskDefinitionInput(in))
+	})
+	t.Run("FailsWithoutCPUForFargate", func(t *testing.T) {
+		in := validInput()
+		in.Cpu = nil
+		assert.Error(t, ValidateRegisterTaskDefinitionInput(in))
+	})
+	t.Run("FailsWithoutMemoryForFargate", func(t *testing.T) {
+		in := validInput()
+		in.Memory = aws.String("")
+		assert.Error(t, ValidateRegisterTaskDefinitionInput(in))
+	})
+	t.Run("SucceedsWithFargateResourcesInUnits", func(t *testing.T) {
+		in := validInput()
+		in.Cpu = aws.String("1 vCPU")
+		in.Memory = aws.String("2 GB")
+		assert.NoError(t, ValidateRegisterTaskDefinitionInput(in))
+	})
+	t.Run("FailsWithUnsupportedFargateResources", func(t *testing.T) {
+		in := validInput()
+		in.Memory = aws.String("4096")
+		err := ValidateRegisterTaskDefinitionInput(in)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "512, 1024, 2048")
+	})
+	t.Run("FailsWithUnparseableFargateResources", func(t *testing.T) {
+		in := validInput()
+		in.Cpu = aws.String("lots")
+		assert.Error(t, ValidateRegisterTaskDefinitionInput(in))
+	})
+	t.Run("SucceedsWithUnsupportedFargateResourcesForEC2", func(t *testing.T) {
+		in := validInput()
+		in.RequiresCompatibilities = []*string{aws.String(ecs.CompatibilityEc2)}
+		in.Cpu = aws.String("128")
+		assert.NoError(t, ValidateRegisterTaskDefinitionInput(in))
+	})
+	t.Run("FailsWithDuplicateContainerNames", func(t *testing.T) {
+		in := validInput()
+		in.ContainerDefinitions = append(in.ContainerDefinitions, &ecs.ContainerDefinition{Name: aws.String("container")})
+		err := ValidateRegisterTaskDefinitionInput(in)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be used by multiple containers")
+	})
+	t.Run("ReturnsAllErrors", func(t *testing.T) {
+		in := validInput()
+		in.Family = nil
+		in.Cpu = nil
+		in.ContainerDefinitions = append(in.ContainerDefinitions, &ecs.ContainerDefinition{Name: aws.String("container")})
+		err := ValidateRegisterTaskDefinitionInput(in)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "family")
+		assert.Contains(t, err.Error(), "CPU")
+		assert.Contains(t, err.Error(), "cannot be used by multiple containers")
+	})
+}
+
+func TestTaskDefinitionEqual(t *testing.T) {
+	baseDef := func() *ecs.TaskDefinition {
+		return &ecs.TaskDefinition{
+			Family:                  aws.String("family"),
+			RequiresCompatibilities: []*string{aws.String(ecs.CompatibilityFargate)},
+			NetworkMode:             aws.String(ecs.NetworkModeAwsvpc),
+			Cpu:                     aws.String("256"),
+			Memory:                  aws.String("512"),
+			ContainerDefinitions: []*ecs.ContainerDefinition{
+				{
+					Name:  aws.String("app"),
+					Image: aws.String("image"),
+					Environment: []*ecs.KeyValuePair{
+						{Name: aws.String("key"), Value: aws.String("value")},
+					},
+				},
+			},
+			Volumes: []*ecs.Volume{{Name: aws.String("volume")}},
+		}
+	}
+
+	t.Run("SucceedsWithIdenticalDefinitions", func(t *testing.T) {
+		assert.True(t, TaskDefinitionEqual(baseDef(), baseDef()))
+	})
+	t.Run("SucceedsWithBothNil", func(t *testing.T) {
+		assert.True(t, TaskDefinitionEqual(nil, nil))
+	})
+	t.Run("FailsWithOneNil", func(t *testing.T) {
+		assert.False(t, TaskDefinitionEqual(baseDef(), nil))
+		assert.False(t, TaskDefinitionEqual(nil, baseDef()))
+	})
+	t.Run("IgnoresMetadata", func(t *testing.T) {
+		registered := baseDef()
+		registered.TaskDefinitionArn = aws.String("arn:aws:ecs:us-east-1:123456789012:task-definition/family:3")
+		registered.Revision = aws.Int64(3)
+		registered.Status = aws.String(ecs.TaskDefinitionStatusActive)
+		registered.RegisteredAt = aws.Time(time.Now())
+		registered.RegisteredBy = aws.String("user")
+		registered.Compatibilities = []*string{aws.String(ecs.CompatibilityEc2), aws.String(ecs.CompatibilityFargate)}
+		registered.RequiresAttributes = []*ecs.Attribute{{Name: aws.String("attribute")}}
+		assert.True(t, TaskDefinitionEqual(baseDef(), registered))
+	})
+	t.Run("IgnoresUnsetFieldsThatAreEmpty", func(t *testing.T) {
+		def := baseDef()
+		def.ContainerDefinitions[0].MountPoints = []*ecs.MountPoint{}
+		def.ContainerDefinitions[0].Cpu = aws.Int64(0)
+		def.PlacementConstraints = []*ecs.TaskDefinitionPlacementConstraint{}
+		assert.True(t, TaskDefinitionEqual(baseDef(), def))
+	})
+	t.Run("UsesECSDefaultsForOmittedFields", func(t *testing.T) {
+		in := baseDef()
+		in.ContainerDefinitions[0].PortMappings = []*ecs.PortMapping{{ContainerPort: aws.Int64(8080)}}
+		registered := baseDef()
+		registered.ContainerDefinitions[0].Essential = aws.Bool(true)
+		registered.ContainerDefinitions[0].PortMappings = []*ecs.PortMapping{{
+			ContainerPort: aws.Int64(8080),
+			HostPort:      aws.Int64(8080),
+			Protocol:      aws.String(ecs.TransportProtocolTcp),
+		}}
+		assert.True(t, TaskDefinitionEqual(in, registered))
+		assert.Nil(t, in.ContainerDefinitions[0].Essential, "should not modify the original task definition")
+		assert.Nil(t, in.ContainerDefinitions[0].PortMappings[0].Protocol, "should not modify the original task definition")
+	})
+	t.Run("FailsWithNonEssentialContainer", func(t *testing.T) {
+		def := baseDef()
+		def.ContainerDefinitions[0].Essential = aws.Bool(false)
+		assert.False(t, TaskDefinitionEqual(baseDef(), def))
+	})
+	t.Run("FailsWithDifferentHostPortInBridgeMode", func(t *testing.T) {
+		a := baseDef()
+		a.NetworkMode = aws.String(ecs.NetworkModeBridge)
+		a.ContainerDefinitions[0].PortMappings = []*ecs.PortMapping{{ContainerPort: aws.Int64(8080)}}
+		b := baseDef()
+		b.NetworkMode = aws.String(ecs.NetworkModeBridge)
+		b.ContainerDefinitions[0].PortMappings = []*ecs.PortMapping{{ContainerPort: aws.Int64(8080), HostPort: aws.Int64(8080)}}
+		assert.False(t, TaskDefinitionEqual(a, b), "bridge mode should assign a dynamic host port by default")
+	})
+	t.Run("IgnoresResourceUnits", func(t *testing.T) {
+		def := baseDef()
+		def.Cpu = aws.String("0.25 vCPU")
+		def.Memory = aws.String("0.5 GB")
+		assert.True(t, TaskDefinitionEqual(baseDef(), def))
+	})
+	t.Run("IgnoresCompatibilityOrder", func(t *testing.T) {
+		a := baseDef()
+		a.RequiresCompatibilities = []*string{aws.String(ecs.CompatibilityEc2), aws.String(ecs.CompatibilityFargate)}
+		b := baseDef()
+		b.RequiresCompatibilities = []*string{aws.String(ecs.CompatibilityFargate), aws.String(ecs.CompatibilityEc2)}
+		assert.True(t, TaskDefinitionEqual(a, b))
+	})
+	t.Run("FailsWithDifferentCPU", func(t *testing.T) {
+		def := baseDef()
+		def.Cpu = aws.String("512")
+		assert.False(t, TaskDefinitionEqual(baseDef(), def))
+	})
+	t.Run("FailsWithDifferentMemory", func(t *testing.T) {
+		def := baseDef()
+		def.Memory = nil
+		assert.False(t, TaskDefinitionEqual(baseDef(), def))
+	})
+	t.Run("FailsWithDifferentNetworkMode", func(t *testing.T) {
+		def := baseDef()
+		def.NetworkMode = aws.String(ecs.NetworkModeBridge)
+		assert.False(t, TaskDefinitionEqual(baseDef(), def))
+	})
+	t.Run("FailsWithDifferentVolumes", func(t *testing.T) {
+		def := baseDef()
+		def.Volumes = append(def.Volumes, &ecs.Volume{Name: aws.String("other")})
+		assert.False(t, TaskDefinitionEqual(baseDef(), def))
+	})
+	t.Run("FailsWithDifferentContainerImage", func(t *testing.T) {
+		def := baseDef()
+		def.ContainerDefinitions[0].Image = aws.String("other")
+		assert.False(t, TaskDefinitionEqual(baseDef(), def))
+	})
+	t.Run("FailsWithDifferentContainerEnvironment", func(t *testing.T) {
+		def := baseDef()
+		def.ContainerDefinitions[0].Environment[0].Value = aws.String("other")
+		assert.False(t, TaskDefinitionEqual(baseDef(), def))
+	})
+	t.Run("FailsWithAdditionalContainer", func(t *testing.T) {
+		def := baseDef()
+		def.ContainerDefinitions = append(def.ContainerDefinitions, &ecs.ContainerDefinition{Name: aws.String("sidecar")})
+		assert.False(t, TaskDefinitionEqual(baseDef(), def))
+	})
+	t.Run("FailsWithDifferentTaskRole", func(t *testing.T) {
+		def := baseDef()
+		def.TaskRoleArn = aws.String("arn:aws:iam::123456789012:role/role")
+		assert.False(t, TaskDefinitionEqual(baseDef(), def))
+	})
+}
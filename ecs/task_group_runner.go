@@ -0,0 +1,464 @@
+package ecs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+)
+
+// defaultTaskGroupConcurrency is the default maximum number of concurrent
+// requests to run tasks in a task group.
+const defaultTaskGroupConcurrency = 10
+
+// maxDescribeTasks is the maximum number of tasks that can be described in a
+// single DescribeTasks request.
+const maxDescribeTasks = 100
+
+// gracefulShutdownTimeout is the maximum amount of time to spend stopping the
+// running tasks during a graceful shutdown.
+const gracefulShutdownTimeout = time.Minute
+
+// TaskGroupRunner runs a group of ECS tasks and waits for all of them to
+// finish.
+type TaskGroupRunner struct {
+	client         cocoa.ECSClient
+	maxConcurrency int
+	pollInterval   time.Duration
+
+	gracefulShutdown bool
+	stopReason       string
+	// running maps the ARNs of the tasks that were started but not yet
+	// stopped to the cluster they run in. It is only tracked for graceful
+	// shutdown.
+	running map[string]string
+	// closed is whether the runner has been closed. Once it is closed, no
+	// more task groups can be run.
+	closed   bool
+	mu       sync.Mutex
+	closing  chan struct{}
+	cleanups sync.WaitGroup
+}
+
+// NewTaskGroupRunner returns a new runner that runs task groups using the
+// given client.
+func NewTaskGroupRunner(c cocoa.ECSClient) (*TaskGroupRunner, error) {
+	if c == nil {
+		return nil, errors.New("missing client")
+	}
+	return &TaskGroupRunner{
+		client:         c,
+		maxConcurrency: defaultTaskGroupConcurrency,
+		pollInterval:   defaultTaskPollInterval,
+		running:        map[string]string{},
+		closing:        make(chan struct{}),
+	}, nil
+}
+
+// SetMaxConcurrency sets the maximum number of concurrent requests to run
+// tasks. By default, it is 10.
+func (r *TaskGroupRunner) SetMaxConcurrency(n int) *TaskGroupRunner {
+	if n > 0 {
+		r.maxConcurrency = n
+	}
+	return r
+}
+
+// SetPollInterval sets the interval between checks of whether the tasks have
+// finished. By default, it is 1 second.
+func (r *TaskGroupRunner) SetPollInterval(d time.Duration) *TaskGroupRunner {
+	if d > 0 {
+		r.pollInterval = d
+	}
+	return r
+}
+
+// WithGracefulShutdown makes the runner stop all the tasks that it started but
+// that have not stopped yet if the context passed to Run is cancelled or the
+// runner is closed. The stop reason is recorded on each task that is stopped
+// this way. By default, tasks are left running in the cluster.
+func (r *TaskGroupRunner) WithGracefulShutdown(stopReason string) *TaskGroupRunner {
+	r.gracefulShutdown = true
+	r.stopReason = stopReason
+	return r
+}
+
+// Close stops the task groups that are currently running. If graceful shutdown
+// is enabled, it waits for the running tasks to be stopped and stops any
+// remaining tasks that were started by the runner but have not stopped yet.
+// The runner cannot be used to run tasks after it is closed.
+func (r *TaskGroupRunner) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.closing)
+	}
+	r.mu.Unlock()
+
+	if !r.gracefulShutdown {
+		return nil
+	}
+
+	r.cleanups.Wait()
+
+	return errors.Wrap(r.stopRunningTasks(ctx), "stopping running tasks")
+}
+
+// TaskGroupResult is the result of running a group of tasks.
+type TaskGroupResult struct {
+	// Tasks are the results for each task in the group. Tasks that could not
+	// be started have an error set.
+	Tasks []TaskResult
+}
+
+// HasErrors returns whether or not any task in the group could not be
+// started.
+func (r *TaskGroupResult) HasErrors() bool {
+	for _, t := range r.Tasks {
+		if t.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskResult is the result of running a single task in a task group.
+type TaskResult struct {
+	// Input is the input used to run the task.
+	Input *ecs.RunTaskInput
+	// TaskARN is the ARN of the task. This is empty if the task could not be
+	// started.
+	TaskARN string
+	// Cluster is the cluster that the task ran in.
+	Cluster string
+	// ExitCodes are the exit codes of the task's containers, keyed by
+	// container name. Containers without an exit code have an exit code of
+	// -1.
+	ExitCodes map[string]int64
+	// StopReason is the reason that the task stopped.
+	StopReason string
+	// Err is the error that prevented the task from starting, if any.
+	Err error
+}
+
+// Run runs all the tasks, waits for all of them to stop, and returns each
+// task's result. Failing to start a task does not prevent the other tasks from
+// running; instead, the error is recorded in the task's result. This returns an
+// error if it cannot wait for all started tasks to stop or if the runner is
+// already closed.
+//
+// If graceful shutdown is enabled and the context is cancelled or the runner
+// is closed before all the tasks stop, the tasks that are still running are
+// stopped before this returns.
+func (r *TaskGroupRunner) Run(ctx context.Context, inputs []*ecs.RunTaskInput) (*TaskGroupResult, error) {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	started := make(chan struct{})
+	finishCleanup, err := r.startCleanup(runCtx, cancel, started)
+	if err != nil {
+		return nil, err
+	}
+
+	results := r.runTasks(runCtx, inputs)
+	close(started)
+
+	waitErr := r.waitForTasks(runCtx, results)
+	if waitErr == nil {
+		// If the run was cancelled, the tasks may have only stopped because
+		// they were stopped during cleanup.
+		waitErr = runCtx.Err()
+	}
+	cleanupErr := finishCleanup()
+	if waitErr != nil || cleanupErr != nil {
+		var errs cocoa.MultiError
+		errs.Wrapf(waitErr, "waiting for tasks to stop")
+		errs.Wrapf(cleanupErr, "stopping running tasks")
+		return nil, errs.Resolve()
+	}
+
+	return &TaskGroupResult{Tasks: results}, nil
+}
+
+// startCleanup starts a goroutine that waits for the context to be cancelled
+// or the runner to be closed. If either happens, it cancels the run and, if
+// graceful shutdown is enabled, stops all the tasks that are still running
+// once no more tasks are being started. The returned function ends the
+// cleanup and returns the error from stopping tasks, if any. It returns an
+// error if the runner is already closed.
+func (r *TaskGroupRunner) startCleanup(ctx context.Context, cancel context.CancelFunc, started <-chan struct{}) (func() error, error) {
+	// The cleanup must be added while holding the lock so that it cannot race
+	// with Close waiting for the cleanups to finish.
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, errors.New("task group runner is closed")
+	}
+	r.cleanups.Add(1)
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	var err error
+
+	go func() {
+		defer func() {
+			close(finished)
+			r.cleanups.Done()
+		}()
+
+		select {
+		case <-done:
+			// The run can finish because it was cancelled before this
+			// noticed, in which case the tasks must still be stopped.
+			if ctx.Err() == nil {
+				return
+			}
+		case <-ctx.Done():
+		case <-r.closing:
+			cancel()
+		}
+
+		if !r.gracefulShutdown {
+			return
+		}
+
+		<-started
+
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), gracefulShutdownTimeout)
+		defer stopCancel()
+		err = r.stopRunningTasks(stopCtx)
+	}()
+
+	return func() error {
+		close(done)
+		<-finished
+		return err
+	}, nil
+}
+
+// trackRunning records that the task was started and has not stopped yet.
+func (r *TaskGroupRunner) trackRunning(cluster, taskARN string) {
+	if !r.gracefulShutdown || taskARN == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.running[taskARN] = cluster
+}
+
+// untrackRunning records that the task has stopped.
+func (r *TaskGroupRunner) untrackRunning(taskARN string) {
+	if !r.gracefulShutdown {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.running, taskARN)
+}
+
+// stopRunningTasks stops all the tasks that were started but have not stopped
+// yet.
+func (r *TaskGroupRunner) stopRunningTasks(ctx context.Context) error {
+	r.mu.Lock()
+	running := make(map[string]string, len(r.running))
+	for arn, cluster := range r.running {
+		running[arn] = cluster
+	}
+	r.mu.Unlock()
+
+	var errs cocoa.MultiError
+	for arn, cluster := range running {
+		in := &ecs.StopTaskInput{
+			Cluster: aws.String(cluster),
+			Task:    aws.String(arn),
+		}
+		if r.stopReason != "" {
+			in.SetReason(r.stopReason)
+		}
+		if _, err := r.client.StopTask(ctx, in); err != nil && !cocoa.IsECSTaskNotFoundError(err) {
+			errs.Wrapf(err, "stopping task '%s'", arn)
+			continue
+		}
+		r.untrackRunning(arn)
+	}
+
+	return errs.Resolve()
+}
+
+// runTasks runs the tasks for all the inputs concurrently and returns the
+// results for every task that was requested.
+func (r *TaskGroupRunner) runTasks(ctx context.Context, inputs []*ecs.RunTaskInput) []TaskResult {
+	resultsByInput := make([][]TaskResult, len(inputs))
+	sem := make(chan struct{}, r.maxConcurrency)
+	var wg sync.WaitGroup
+	for i, in := range inputs {
+		select {
+		case <-ctx.Done():
+			resultsByInput[i] = []TaskResult{{Input: in, Err: errors.Wrap(ctx.Err(), "running task")}}
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, in *ecs.RunTaskInput) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			resultsByInput[i] = r.runTask(ctx, in)
+		}(i, in)
+	}
+	wg.Wait()
+
+	var results []TaskResult
+	for _, res := range resultsByInput {
+		results = append(results, res...)
+	}
+	return results
+}
+
+// runTask runs the task for a single input.
+func (r *TaskGroupRunner) runTask(ctx context.Context, in *ecs.RunTaskInput) []TaskResult {
+	if in == nil {
+		return []TaskResult{{Err: errors.New("cannot run task with nil input")}}
+	}
+
+	cluster := utility.FromStringPtr(in.Cluster)
+	out, err := r.client.RunTask(ctx, in)
+	if err != nil {
+		return []TaskResult{{Input: in, Cluster: cluster, Err: errors.Wrap(err, "running task")}}
+	}
+
+	var results []TaskResult
+	for _, task := range out.Tasks {
+		if task == nil {
+			continue
+		}
+		r.trackRunning(cluster, utility.FromStringPtr(task.TaskArn))
+		results = append(results, TaskResult{
+			Input:   in,
+			Cluster: cluster,
+			TaskARN: utility.FromStringPtr(task.TaskArn),
+		})
+	}
+	for _, f := range out.Failures {
+		if f == nil {
+			continue
+		}
+		results = append(results, TaskResult{
+			Input:   in,
+			Cluster: cluster,
+			Err:     ConvertFailureToError(f),
+		})
+	}
+	if len(results) == 0 {
+		results = append(results, TaskResult{Input: in, Cluster: cluster, Err: errors.New("running task returned neither tasks nor failures")})
+	}
+
+	return results
+}
+
+// waitForTasks waits for all the started tasks to stop and records their exit
+// information in the results.
+func (r *TaskGroupRunner) waitForTasks(ctx context.Context, results []TaskResult) error {
+	pending := map[string][]int{}
+	for i, res := range results {
+		if res.Err == nil {
+			pending[res.Cluster] = append(pending[res.Cluster], i)
+		}
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			for cluster, idxs := range pending {
+				stillPending, err := r.checkStopped(ctx, cluster, idxs, results)
+				if err != nil {
+					return errors.Wrapf(err, "checking tasks in cluster '%s'", cluster)
+				}
+				if len(stillPending) == 0 {
+					delete(pending, cluster)
+				} else {
+					pending[cluster] = stillPending
+				}
+			}
+			timer.Reset(r.pollInterval)
+		}
+	}
+
+	return nil
+}
+
+// checkStopped checks whether the tasks in the cluster at the given result
+// indices have stopped. It records the exit information for stopped tasks and
+// returns the indices of the tasks that are still not stopped.
+func (r *TaskGroupRunner) checkStopped(ctx context.Context, cluster string, idxs []int, results []TaskResult) ([]int, error) {
+	var stillPending []int
+	for start := 0; start < len(idxs); start += maxDescribeTasks {
+		end := start + maxDescribeTasks
+		if end > len(idxs) {
+			end = len(idxs)
+		}
+
+		idxByARN := map[string]int{}
+		var arns []*string
+		for _, idx := range idxs[start:end] {
+			idxByARN[results[idx].TaskARN] = idx
+			arns = append(arns, aws.String(results[idx].TaskARN))
+		}
+
+		out, err := r.client.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+			Cluster: aws.String(cluster),
+			Tasks:   arns,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "describing tasks")
+		}
+		for _, f := range out.Failures {
+			if f != nil {
+				return nil, ConvertFailureToError(f)
+			}
+		}
+
+		for _, task := range out.Tasks {
+			if task == nil {
+				continue
+			}
+			idx, ok := idxByARN[utility.FromStringPtr(task.TaskArn)]
+			if !ok {
+				continue
+			}
+			delete(idxByARN, utility.FromStringPtr(task.TaskArn))
+
+			if TaskStatus(utility.FromStringPtr(task.LastStatus)) != TaskStatusStopped {
+				stillPending = append(stillPending, idx)
+				continue
+			}
+
+			r.untrackRunning(results[idx].TaskARN)
+			results[idx].StopReason = ExtractStopReason(task)
+			results[idx].ExitCodes = ExtractContainerExitCodes(task)
+		}
+
+		for _, idx := range idxByARN {
+			stillPending = append(stillPending, idx)
+		}
+	}
+
+	return stillPending, nil
+}
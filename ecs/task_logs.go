@@ -0,0 +1,131 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// logDriverAWSLogs is the log driver that sends container logs to
+	// CloudWatch Logs.
+	logDriverAWSLogs = "awslogs"
+	// awsLogsGroupOption is the awslogs log driver option for the log group.
+	awsLogsGroupOption = "awslogs-group"
+	// awsLogsStreamPrefixOption is the awslogs log driver option for the log
+	// stream prefix.
+	awsLogsStreamPrefixOption = "awslogs-stream-prefix"
+)
+
+// FetchTaskLogs gets all the logs for the task's containers that send their
+// logs to CloudWatch Logs using the awslogs log driver. The logs are returned
+// as a mapping of container names to their log lines. Containers that do not
+// use the awslogs log driver are not included.
+func FetchTaskLogs(ctx context.Context, ecsc cocoa.ECSClient, cwlc cocoa.CloudWatchLogsClient, cluster, taskARN string) (map[string][]string, error) {
+	task, err := describeTask(ctx, ecsc, cluster, taskARN)
+	if err != nil {
+		return nil, errors.Wrap(err, "describing task")
+	}
+
+	defOut, err := ecsc.DescribeTaskDefinition(ctx, &ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: task.TaskDefinitionArn,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describing task definition")
+	}
+	if defOut.TaskDefinition == nil {
+		return nil, errors.New("expected a task definition to exist in ECS, but none was returned")
+	}
+
+	parsedARN, err := ParseTaskARN(taskARN)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing task ARN")
+	}
+	taskID := parsedARN.TaskID
+	logs := map[string][]string{}
+	catcher := grip.NewBasicCatcher()
+	for _, def := range defOut.TaskDefinition.ContainerDefinitions {
+		if def == nil || def.LogConfiguration == nil {
+			continue
+		}
+		if utility.FromStringPtr(def.LogConfiguration.LogDriver) != logDriverAWSLogs {
+			continue
+		}
+
+		name := utility.FromStringPtr(def.Name)
+		group, stream, err := awsLogsLocation(def.LogConfiguration, name, taskID)
+		if err != nil {
+			catcher.Wrapf(err, "container '%s'", name)
+			continue
+		}
+
+		lines, err := getAllLogEvents(ctx, cwlc, group, stream)
+		if err != nil {
+			catcher.Wrapf(err, "getting logs for container '%s'", name)
+			continue
+		}
+		logs[name] = lines
+	}
+
+	if catcher.HasErrors() {
+		return nil, catcher.Resolve()
+	}
+
+	return logs, nil
+}
+
+// awsLogsLocation returns the log group and log stream for a container using
+// the awslogs log driver. ECS names the log stream
+// <prefix>/<container name>/<task ID>.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/using_awslogs.html
+func awsLogsLocation(logConfig *ecs.LogConfiguration, containerName, taskID string) (group, stream string, err error) {
+	group = utility.FromStringPtr(logConfig.Options[awsLogsGroupOption])
+	if group == "" {
+		return "", "", errors.Errorf("missing log driver option '%s'", awsLogsGroupOption)
+	}
+	prefix := utility.FromStringPtr(logConfig.Options[awsLogsStreamPrefixOption])
+	if prefix == "" {
+		return "", "", errors.Errorf("missing log driver option '%s', so the log stream name cannot be determined", awsLogsStreamPrefixOption)
+	}
+
+	return group, fmt.Sprintf("%s/%s/%s", prefix, containerName, taskID), nil
+}
+
+// getAllLogEvents gets all the log lines in the log stream from oldest to
+// newest.
+func getAllLogEvents(ctx context.Context, c cocoa.CloudWatchLogsClient, group, stream string) ([]string, error) {
+	in := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(group),
+		LogStreamName: aws.String(stream),
+		StartFromHead: aws.Bool(true),
+	}
+
+	var lines []string
+	for {
+		out, err := c.GetLogEvents(ctx, in)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting log events for log stream '%s' in log group '%s'", stream, group)
+		}
+		for _, e := range out.Events {
+			if e == nil {
+				continue
+			}
+			lines = append(lines, utility.FromStringPtr(e.Message))
+		}
+
+		// CloudWatch Logs returns the same token that was given when there
+		// are no more log events to read.
+		nextToken := utility.FromStringPtr(out.NextForwardToken)
+		if nextToken == "" || nextToken == utility.FromStringPtr(in.NextToken) {
+			return lines, nil
+		}
+		in.NextToken = aws.String(nextToken)
+	}
+}
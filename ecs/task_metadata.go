@@ -0,0 +1,155 @@
+package ecs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TaskMetadataEndpointEnvVar is the environment variable that ECS sets in each
+// container to the URL of the task metadata endpoint version 4.
+const TaskMetadataEndpointEnvVar = "ECS_CONTAINER_METADATA_URI_V4"
+
+// TaskMetadataLimits are the resource limits of a task or container.
+type TaskMetadataLimits struct {
+	// CPU is the CPU limit in vCPUs for a task or CPU units for a container.
+	CPU float64 `json:"CPU,omitempty"`
+	// Memory is the memory limit in MiB.
+	Memory int64 `json:"Memory,omitempty"`
+}
+
+// ContainerNetworkMetadata is the network metadata of a container.
+type ContainerNetworkMetadata struct {
+	NetworkMode              string   `json:"NetworkMode,omitempty"`
+	IPv4Addresses            []string `json:"IPv4Addresses,omitempty"`
+	IPv6Addresses            []string `json:"IPv6Addresses,omitempty"`
+	AttachmentIndex          int      `json:"AttachmentIndex,omitempty"`
+	MACAddress               string   `json:"MACAddress,omitempty"`
+	IPv4SubnetCIDRBlock      string   `json:"IPv4SubnetCIDRBlock,omitempty"`
+	IPv6SubnetCIDRBlock      string   `json:"IPv6SubnetCIDRBlock,omitempty"`
+	PrivateDNSName           string   `json:"PrivateDNSName,omitempty"`
+	SubnetGatewayIpv4Address string   `json:"SubnetGatewayIpv4Address,omitempty"`
+}
+
+// ContainerMetadata is the metadata of a container returned by the task
+// metadata endpoint.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/task-metadata-endpoint-v4-response.html
+type ContainerMetadata struct {
+	DockerID      string                     `json:"DockerId,omitempty"`
+	Name          string                     `json:"Name,omitempty"`
+	DockerName    string                     `json:"DockerName,omitempty"`
+	Image         string                     `json:"Image,omitempty"`
+	ImageID       string                     `json:"ImageID,omitempty"`
+	Labels        map[string]string          `json:"Labels,omitempty"`
+	DesiredStatus string                     `json:"DesiredStatus,omitempty"`
+	KnownStatus   string                     `json:"KnownStatus,omitempty"`
+	ExitCode      *int                       `json:"ExitCode,omitempty"`
+	Limits        TaskMetadataLimits         `json:"Limits,omitempty"`
+	CreatedAt     *time.Time                 `json:"CreatedAt,omitempty"`
+	StartedAt     *time.Time                 `json:"StartedAt,omitempty"`
+	FinishedAt    *time.Time                 `json:"FinishedAt,omitempty"`
+	Type          string                     `json:"Type,omitempty"`
+	ContainerARN  string                     `json:"ContainerARN,omitempty"`
+	LogDriver     string                     `json:"LogDriver,omitempty"`
+	LogOptions    map[string]string          `json:"LogOptions,omitempty"`
+	Networks      []ContainerNetworkMetadata `json:"Networks,omitempty"`
+}
+
+// TaskMetadata is the metadata of a task returned by the task metadata
+// endpoint.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/task-metadata-endpoint-v4-response.html
+type TaskMetadata struct {
+	Cluster            string              `json:"Cluster,omitempty"`
+	TaskARN            string              `json:"TaskARN,omitempty"`
+	Family             string              `json:"Family,omitempty"`
+	Revision           string              `json:"Revision,omitempty"`
+	DesiredStatus      string              `json:"DesiredStatus,omitempty"`
+	KnownStatus        string              `json:"KnownStatus,omitempty"`
+	Limits             TaskMetadataLimits  `json:"Limits,omitempty"`
+	PullStartedAt      *time.Time          `json:"PullStartedAt,omitempty"`
+	PullStoppedAt      *time.Time          `json:"PullStoppedAt,omitempty"`
+	ExecutionStoppedAt *time.Time          `json:"ExecutionStoppedAt,omitempty"`
+	AvailabilityZone   string              `json:"AvailabilityZone,omitempty"`
+	LaunchType         string              `json:"LaunchType,omitempty"`
+	Containers         []ContainerMetadata `json:"Containers,omitempty"`
+}
+
+// TaskMetadataClient reads the metadata of the task that it is running in
+// from the ECS task metadata endpoint version 4. It can only be used from
+// within a container in an ECS task.
+type TaskMetadataClient struct {
+	endpoint string
+	hc       *http.Client
+}
+
+// NewTaskMetadataClient returns a new client for the task metadata endpoint
+// whose URL is given by the ECS_CONTAINER_METADATA_URI_V4 environment
+// variable.
+func NewTaskMetadataClient() (*TaskMetadataClient, error) {
+	endpoint := os.Getenv(TaskMetadataEndpointEnvVar)
+	if endpoint == "" {
+		return nil, errors.Errorf("environment variable '%s' is not set", TaskMetadataEndpointEnvVar)
+	}
+	return &TaskMetadataClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		hc:       http.DefaultClient,
+	}, nil
+}
+
+// SetHTTPClient sets the HTTP client used to make requests to the task
+// metadata endpoint. By default, it uses the default HTTP client.
+func (c *TaskMetadataClient) SetHTTPClient(hc *http.Client) *TaskMetadataClient {
+	if hc != nil {
+		c.hc = hc
+	}
+	return c
+}
+
+// GetTaskMetadata returns the metadata of the task, including the metadata of
+// all its containers.
+func (c *TaskMetadataClient) GetTaskMetadata(ctx context.Context) (*TaskMetadata, error) {
+	var md TaskMetadata
+	if err := c.get(ctx, c.endpoint+"/task", &md); err != nil {
+		return nil, errors.Wrap(err, "getting task metadata")
+	}
+	return &md, nil
+}
+
+// GetContainerMetadata returns the metadata of the container that the client
+// is running in.
+func (c *TaskMetadataClient) GetContainerMetadata(ctx context.Context) (*ContainerMetadata, error) {
+	var md ContainerMetadata
+	if err := c.get(ctx, c.endpoint, &md); err != nil {
+		return nil, errors.Wrap(err, "getting container metadata")
+	}
+	return &md, nil
+}
+
+// get makes a GET request to the URL and decodes the JSON response body into
+// out.
+func (c *TaskMetadataClient) get(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "making request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return errors.Wrap(json.NewDecoder(resp.Body).Decode(out), "decoding response")
+}
@@ -0,0 +1,221 @@
+package ecs
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultExecutionRoleName is the name of the execution role that ECS
+	// creates by default.
+	defaultExecutionRoleName = "ecsTaskExecutionRole"
+	// ecsTasksServicePrincipal is the service principal that ECS uses to
+	// assume the task and execution roles.
+	ecsTasksServicePrincipal = "ecs-tasks.amazonaws.com"
+)
+
+var (
+	// accountIDRegexp matches valid AWS account IDs.
+	accountIDRegexp = regexp.MustCompile(`^[0-9]{12}$`)
+	// roleNameRegexp matches valid IAM role names.
+	roleNameRegexp = regexp.MustCompile(`^[\w+=,.@-]{1,64}$`)
+)
+
+// taskRoleKind is the kind of role that a task uses.
+type taskRoleKind string
+
+const (
+	taskRoleKindExecution taskRoleKind = "execution"
+	taskRoleKindTask      taskRoleKind = "task"
+)
+
+// TaskRoleARNBuilder builds the ARN of an IAM role for a task definition and
+// verifies that the role exists and that ECS can assume it. A task's
+// execution role is used by ECS to start the task (e.g. to pull images and
+// read secrets), whereas its task role is used by the task's containers to
+// make AWS API calls.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/task-iam-roles.html
+type TaskRoleARNBuilder struct {
+	client    cocoa.IAMClient
+	accountID string
+	roleName  string
+	kind      taskRoleKind
+}
+
+// NewTaskRoleARNBuilder returns a new builder for a task role ARN that uses
+// the given client to verify the role.
+func NewTaskRoleARNBuilder(c cocoa.IAMClient) *TaskRoleARNBuilder {
+	return &TaskRoleARNBuilder{client: c}
+}
+
+// ForAccount sets the ID of the AWS account that owns the role. If it is set,
+// the role must belong to this account.
+func (b *TaskRoleARNBuilder) ForAccount(accountID string) *TaskRoleARNBuilder {
+	b.accountID = accountID
+	return b
+}
+
+// WithRoleName sets the name of the role.
+func (b *TaskRoleARNBuilder) WithRoleName(name string) *TaskRoleARNBuilder {
+	b.roleName = name
+	return b
+}
+
+// WithExecutionRole makes the role the task's execution role. If the role
+// name is not set, it defaults to ecsTaskExecutionRole.
+func (b *TaskRoleARNBuilder) WithExecutionRole() *TaskRoleARNBuilder {
+	b.kind = taskRoleKindExecution
+	return b
+}
+
+// WithTaskRole makes the role the task's task role.
+func (b *TaskRoleARNBuilder) WithTaskRole() *TaskRoleARNBuilder {
+	b.kind = taskRoleKindTask
+	return b
+}
+
+// Validate checks that the client, role name and kind of role are set and
+// that the account ID and role name are valid.
+func (b *TaskRoleARNBuilder) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	catcher.NewWhen(b.client == nil, "must specify an IAM client")
+	catcher.NewWhen(b.kind == "", "must specify whether the role is an execution role or a task role")
+	catcher.ErrorfWhen(b.accountID != "" && !accountIDRegexp.MatchString(b.accountID), "invalid account ID '%s'", b.accountID)
+	name := b.effectiveRoleName()
+	catcher.NewWhen(name == "", "must specify a role name")
+	catcher.ErrorfWhen(name != "" && !roleNameRegexp.MatchString(name), "invalid role name '%s'", name)
+	return catcher.Resolve()
+}
+
+// Build validates the builder, verifies that the role exists, belongs to the
+// account (if set) and trusts ECS tasks to assume it, and returns its ARN.
+func (b *TaskRoleARNBuilder) Build(ctx context.Context) (string, error) {
+	if err := b.Validate(); err != nil {
+		return "", errors.Wrapf(err, "invalid %s role", b.kind)
+	}
+
+	name := b.effectiveRoleName()
+	out, err := b.client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(name)})
+	if err != nil {
+		return "", errors.Wrapf(err, "getting %s role '%s'", b.kind, name)
+	}
+	if out == nil || out.Role == nil {
+		return "", errors.Errorf("%s role '%s' not found", b.kind, name)
+	}
+
+	roleARN := utility.FromStringPtr(out.Role.Arn)
+	parsed, err := arn.Parse(roleARN)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing ARN for %s role '%s'", b.kind, name)
+	}
+	if b.accountID != "" && parsed.AccountID != b.accountID {
+		return "", errors.Errorf("%s role '%s' belongs to account '%s', but should belong to account '%s'", b.kind, name, parsed.AccountID, b.accountID)
+	}
+
+	trusted, err := trustsECSTasks(utility.FromStringPtr(out.Role.AssumeRolePolicyDocument))
+	if err != nil {
+		return "", errors.Wrapf(err, "checking trust policy for %s role '%s'", b.kind, name)
+	}
+	if !trusted {
+		return "", errors.Errorf("%s role '%s' does not allow '%s' to assume it", b.kind, name, ecsTasksServicePrincipal)
+	}
+
+	return roleARN, nil
+}
+
+// effectiveRoleName returns the role name, including defaults.
+func (b *TaskRoleARNBuilder) effectiveRoleName() string {
+	if b.roleName == "" && b.kind == taskRoleKindExecution {
+		return defaultExecutionRoleName
+	}
+	return b.roleName
+}
+
+// trustPolicy is the subset of an IAM trust policy needed to check which
+// services can assume a role. IAM allows single values in place of lists, so
+// the fields that can be lists are decoded as raw JSON.
+type trustPolicy struct {
+	Statement json.RawMessage `json:"Statement"`
+}
+
+type trustPolicyStatement struct {
+	Effect    string          `json:"Effect"`
+	Action    json.RawMessage `json:"Action"`
+	Principal json.RawMessage `json:"Principal"`
+}
+
+type trustPolicyPrincipal struct {
+	Service json.RawMessage `json:"Service"`
+}
+
+// trustsECSTasks returns whether the URL-encoded trust policy document allows
+// ECS tasks to assume the role.
+func trustsECSTasks(encodedDoc string) (bool, error) {
+	if encodedDoc == "" {
+		return false, errors.New("role has no trust policy")
+	}
+	doc, err := url.PathUnescape(encodedDoc)
+	if err != nil {
+		return false, errors.Wrap(err, "decoding trust policy")
+	}
+
+	var policy trustPolicy
+	if err := json.Unmarshal([]byte(doc), &policy); err != nil {
+		return false, errors.Wrap(err, "parsing trust policy")
+	}
+	var stmts []trustPolicyStatement
+	if err := unmarshalOneOrMany(policy.Statement, &stmts); err != nil {
+		return false, errors.Wrap(err, "parsing trust policy statements")
+	}
+
+	for _, stmt := range stmts {
+		if stmt.Effect != "Allow" {
+			continue
+		}
+		var actions []string
+		if err := unmarshalOneOrMany(stmt.Action, &actions); err != nil {
+			return false, errors.Wrap(err, "parsing trust policy actions")
+		}
+		if !utility.StringSliceContains(actions, "sts:AssumeRole") && !utility.StringSliceContains(actions, "sts:*") {
+			continue
+		}
+		var principal trustPolicyPrincipal
+		if err := json.Unmarshal(stmt.Principal, &principal); err != nil {
+			// The principal may be a wildcard string rather than an object,
+			// which does not specifically trust ECS.
+			continue
+		}
+		var services []string
+		if err := unmarshalOneOrMany(principal.Service, &services); err != nil {
+			return false, errors.Wrap(err, "parsing trust policy service principals")
+		}
+		if utility.StringSliceContains(services, ecsTasksServicePrincipal) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// unmarshalOneOrMany decodes the raw JSON, which is either a single value or
+// a list of values, into the list.
+func unmarshalOneOrMany(raw json.RawMessage, out interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if raw[0] == '[' {
+		return json.Unmarshal(raw, out)
+	}
+	wrapped := append(append([]byte{'['}, raw...), ']')
+	return json.Unmarshal(wrapped, out)
+}
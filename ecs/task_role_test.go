@@ -0,0 +1,57 @@
+package ecs
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskRoleARNBuilderValidate(t *testing.T) {
+	t.Run("FailsWithoutClient", func(t *testing.T) {
+		assert.Error(t, NewTaskRoleARNBuilder(nil).WithTaskRole().WithRoleName("app").Validate())
+	})
+}
+
+func TestTrustsECSTasks(t *testing.T) {
+	for tName, tCase := range map[string]struct {
+		doc      string
+		expected bool
+	}{
+		"AllowsECSTasksWithSingleValues": {
+			doc:      `{"Version": "2012-10-17", "Statement": {"Effect": "Allow", "Principal": {"Service": "ecs-tasks.amazonaws.com"}, "Action": "sts:AssumeRole"}}`,
+			expected: true,
+		},
+		"AllowsECSTasksWithLists": {
+			doc:      `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": {"Service": ["ec2.amazonaws.com", "ecs-tasks.amazonaws.com"]}, "Action": ["sts:AssumeRole"]}]}`,
+			expected: true,
+		},
+		"RejectsOtherServices": {
+			doc: `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": {"Service": "ec2.amazonaws.com"}, "Action": "sts:AssumeRole"}]}`,
+		},
+		"RejectsDeniedStatements": {
+			doc: `{"Version": "2012-10-17", "Statement": [{"Effect": "Deny", "Principal": {"Service": "ecs-tasks.amazonaws.com"}, "Action": "sts:AssumeRole"}]}`,
+		},
+		"RejectsOtherActions": {
+			doc: `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": {"Service": "ecs-tasks.amazonaws.com"}, "Action": "sts:TagSession"}]}`,
+		},
+		"RejectsWildcardPrincipal": {
+			doc: `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": "*", "Action": "sts:AssumeRole"}]}`,
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			trusted, err := trustsECSTasks(url.PathEscape(tCase.doc))
+			require.NoError(t, err)
+			assert.Equal(t, tCase.expected, trusted)
+		})
+	}
+	t.Run("FailsWithEmptyDocument", func(t *testing.T) {
+		_, err := trustsECSTasks("")
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithInvalidJSON", func(t *testing.T) {
+		_, err := trustsECSTasks(url.PathEscape("{"))
+		assert.Error(t, err)
+	})
+}
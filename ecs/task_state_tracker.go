@@ -0,0 +1,111 @@
+package ecs
+
+import (
+	"context"
+	"time"
+
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+)
+
+// TaskStateEvent represents a transition of an ECS task from one status to
+// another.
+type TaskStateEvent struct {
+	// OldStatus is the task's status before the transition. This is empty for
+	// the first event, which reports the task's initial status.
+	OldStatus TaskStatus
+	// NewStatus is the task's status after the transition.
+	NewStatus TaskStatus
+	// Timestamp is the time at which the transition was observed.
+	Timestamp time.Time
+	// Err is set if the task's status could not be checked. If it is set, this
+	// is the last event sent.
+	Err error
+}
+
+// TaskStateTracker tracks the lifecycle of ECS tasks by polling their status.
+type TaskStateTracker struct {
+	client       cocoa.ECSClient
+	pollInterval time.Duration
+}
+
+// NewTaskStateTracker returns a new tracker that checks task status using the
+// given client. If the poll interval is not positive, it defaults to 1 second.
+func NewTaskStateTracker(c cocoa.ECSClient, pollInterval time.Duration) (*TaskStateTracker, error) {
+	if c == nil {
+		return nil, errors.New("missing client")
+	}
+	if pollInterval <= 0 {
+		pollInterval = defaultTaskPollInterval
+	}
+	return &TaskStateTracker{
+		client:       c,
+		pollInterval: pollInterval,
+	}, nil
+}
+
+// Watch starts polling the task's status and returns a channel that receives
+// an event each time the task's status changes. The first event reports the
+// task's current status. The channel is closed once the task is stopped, the
+// context is done, or the task status cannot be checked.
+func (t *TaskStateTracker) Watch(ctx context.Context, cluster, taskARN string) (<-chan TaskStateEvent, error) {
+	task, err := describeTask(ctx, t.client, cluster, taskARN)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting initial task status")
+	}
+
+	events := make(chan TaskStateEvent)
+	go func() {
+		defer close(events)
+
+		send := func(e TaskStateEvent) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case events <- e:
+				return true
+			}
+		}
+
+		status := TaskStatus(utility.FromStringPtr(task.LastStatus))
+		if !send(TaskStateEvent{NewStatus: status, Timestamp: time.Now()}) || status == TaskStatusStopped {
+			return
+		}
+
+		timer := time.NewTimer(t.pollInterval)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				task, err := describeTask(ctx, t.client, cluster, taskARN)
+				if err != nil {
+					send(TaskStateEvent{
+						OldStatus: status,
+						Timestamp: time.Now(),
+						Err:       errors.Wrap(err, "checking task status"),
+					})
+					return
+				}
+
+				newStatus := TaskStatus(utility.FromStringPtr(task.LastStatus))
+				if newStatus != status {
+					if !send(TaskStateEvent{OldStatus: status, NewStatus: newStatus, Timestamp: time.Now()}) {
+						return
+					}
+					status = newStatus
+				}
+				if status == TaskStatusStopped {
+					return
+				}
+
+				timer.Reset(t.pollInterval)
+			}
+		}
+	}()
+
+	return events, nil
+}
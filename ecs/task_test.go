@@ -0,0 +1,205 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractContainerExitCodes(t *testing.T) {
+	t.Run("ReturnsExitCodeForEachContainer", func(t *testing.T) {
+		exitCodes := ExtractContainerExitCodes(&ecs.Task{
+			Containers: []*ecs.Container{
+				{Name: aws.String("c0"), ExitCode: aws.Int64(0)},
+				{Name: aws.String("c1"), ExitCode: aws.Int64(137)},
+			},
+		})
+		assert.Equal(t, map[string]int64{"c0": 0, "c1": 137}, exitCodes)
+	})
+	t.Run("DefaultsMissingExitCode", func(t *testing.T) {
+		exitCodes := ExtractContainerExitCodes(&ecs.Task{
+			Containers: []*ecs.Container{
+				{Name: aws.String("c0")},
+				nil,
+			},
+		})
+		assert.Equal(t, map[string]int64{"c0": -1}, exitCodes)
+	})
+	t.Run("ReturnsEmptyForTaskWithoutContainers", func(t *testing.T) {
+		assert.Empty(t, ExtractContainerExitCodes(&ecs.Task{}))
+	})
+	t.Run("ReturnsNilForNilTask", func(t *testing.T) {
+		assert.Nil(t, ExtractContainerExitCodes(nil))
+	})
+}
+
+func TestExtractStopReason(t *testing.T) {
+	t.Run("ReturnsStopReason", func(t *testing.T) {
+		assert.Equal(t, "Essential container in task exited", ExtractStopReason(&ecs.Task{
+			StoppedReason: aws.String("Essential container in task exited"),
+		}))
+	})
+	t.Run("ReturnsEmptyForTaskThatIsNotStopped", func(t *testing.T) {
+		assert.Empty(t, ExtractStopReason(&ecs.Task{}))
+	})
+	t.Run("ReturnsEmptyForNilTask", func(t *testing.T) {
+		assert.Empty(t, ExtractStopReason(nil))
+	})
+}
+
+func TestExtractTaskNetworkInfo(t *testing.T) {
+	eniAttachment := func(details map[string]string) *ecs.Attachment {
+		a := &ecs.Attachment{
+			Type:   aws.String("ElasticNetworkInterface"),
+			Status: aws.String("ATTACHED"),
+		}
+		for k, v := range details {
+			a.Details = append(a.Details, &ecs.KeyValuePair{Name: aws.String(k), Value: aws.String(v)})
+		}
+		return a
+	}
+
+	t.Run("ReturnsNetworkInfo", func(t *testing.T) {
+		info, err := ExtractTaskNetworkInfo(&ecs.Task{
+			Attachments: []*ecs.Attachment{eniAttachment(map[string]string{
+				"subnetId":           "subnet-12345678",
+				"networkInterfaceId": "eni-12345678",
+				"macAddress":         "0a:1b:2c:3d:4e:5f",
+				"privateIPv4Address": "10.0.0.1",
+			})},
+		})
+		require.NoError(t, err)
+		require.NotZero(t, info)
+		assert.Equal(t, "10.0.0.1", info.PrivateIP)
+		assert.Zero(t, info.PublicIP)
+		assert.Equal(t, "eni-12345678", info.ENI)
+		assert.Equal(t, "subnet-12345678", info.SubnetID)
+	})
+	t.Run("IncludesPublicIP", func(t *testing.T) {
+		info, err := ExtractTaskNetworkInfo(&ecs.Task{
+			Attachments: []*ecs.Attachment{eniAttachment(map[string]string{
+				"networkInterfaceId": "eni-12345678",
+				"privateIPv4Address": "10.0.0.1",
+				"publicIPv4Address":  "203.0.113.1",
+			})},
+		})
+		require.NoError(t, err)
+		require.NotZero(t, info)
+		assert.Equal(t, "203.0.113.1", info.PublicIP)
+	})
+	t.Run("IgnoresOtherAttachments", func(t *testing.T) {
+		info, err := ExtractTaskNetworkInfo(&ecs.Task{
+			Attachments: []*ecs.Attachment{
+				{Type: aws.String("Other")},
+				eniAttachment(map[string]string{"networkInterfaceId": "eni-12345678"}),
+			},
+		})
+		require.NoError(t, err)
+		require.NotZero(t, info)
+		assert.Equal(t, "eni-12345678", info.ENI)
+	})
+	t.Run("FailsWithoutNetworkInterfaceAttachment", func(t *testing.T) {
+		info, err := ExtractTaskNetworkInfo(&ecs.Task{})
+		assert.Error(t, err)
+		assert.Zero(t, info)
+	})
+	t.Run("FailsWithoutNetworkInterfaceID", func(t *testing.T) {
+		info, err := ExtractTaskNetworkInfo(&ecs.Task{
+			Attachments: []*ecs.Attachment{eniAttachment(map[string]string{"subnetId": "subnet-12345678"})},
+		})
+		assert.Error(t, err)
+		assert.Zero(t, info)
+	})
+	t.Run("FailsWithNilTask", func(t *testing.T) {
+		info, err := ExtractTaskNetworkInfo(nil)
+		assert.Error(t, err)
+		assert.Zero(t, info)
+	})
+}
+
+func TestValidateRunTaskInput(t *testing.T) {
+	validInput := func() *ecs.RunTaskInput {
+		return &ecs.RunTaskInput{
+			Cluster:        aws.String("cluster"),
+			TaskDefinition: aws.String("family:1"),
+			Count:          aws.Int64(1),
+			LaunchType:     aws.String(ecs.LaunchTypeFargate),
+			NetworkConfiguration: &ecs.NetworkConfiguration{
+				AwsvpcConfiguration: &ecs.AwsVpcConfiguration{
+					Subnets: []*string{aws.String("subnet-0123456789abcdef0")},
+				},
+			},
+		}
+	}
+
+	t.Run("SucceedsWithValidInput", func(t *testing.T) {
+		assert.NoError(t, ValidateRunTaskInput(validInput()))
+	})
+	t.Run("SucceedsWithoutLaunchTypeOrNetworkConfiguration", func(t *testing.T) {
+		in := validInput()
+		in.LaunchType = nil
+		in.NetworkConfiguration = nil
+		assert.NoError(t, ValidateRunTaskInput(in))
+	})
+	t.Run("SucceedsWithMaxCount", func(t *testing.T) {
+		in := validInput()
+		in.Count = aws.Int64(10)
+		assert.NoError(t, ValidateRunTaskInput(in))
+	})
+	t.Run("FailsWithNilInput", func(t *testing.T) {
+		assert.Error(t, ValidateRunTaskInput(nil))
+	})
+	t.Run("FailsWithoutCluster", func(t *testing.T) {
+		in := validInput()
+		in.Cluster = nil
+		assert.Error(t, ValidateRunTaskInput(in))
+	})
+	t.Run("FailsWithoutTaskDefinition", func(t *testing.T) {
+		in := validInput()
+		in.TaskDefinition = aws.String("")
+		assert.Error(t, ValidateRunTaskInput(in))
+	})
+	t.Run("FailsWithoutCount", func(t *testing.T) {
+		in := validInput()
+		in.Count = nil
+		assert.Error(t, ValidateRunTaskInput(in))
+	})
+	t.Run("FailsWithZeroCount", func(t *testing.T) {
+		in := validInput()
+		in.Count = aws.Int64(0)
+		assert.Error(t, ValidateRunTaskInput(in))
+	})
+	t.Run("FailsWithCountOverLimit", func(t *testing.T) {
+		in := validInput()
+		in.Count = aws.Int64(11)
+		assert.Error(t, ValidateRunTaskInput(in))
+	})
+	t.Run("FailsWithUnrecognizedLaunchType", func(t *testing.T) {
+		in := validInput()
+		in.LaunchType = aws.String("foo")
+		err := ValidateRunTaskInput(in)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "foo")
+	})
+	t.Run("FailsWithoutNetworkConfigurationForFargate", func(t *testing.T) {
+		in := validInput()
+		in.NetworkConfiguration = nil
+		assert.Error(t, ValidateRunTaskInput(in))
+	})
+	t.Run("FailsWithoutSubnets", func(t *testing.T) {
+		in := validInput()
+		in.NetworkConfiguration.AwsvpcConfiguration.Subnets = nil
+		assert.Error(t, ValidateRunTaskInput(in))
+	})
+	t.Run("ReturnsAllErrors", func(t *testing.T) {
+		err := ValidateRunTaskInput(&ecs.RunTaskInput{LaunchType: aws.String("foo")})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cluster")
+		assert.Contains(t, err.Error(), "task definition")
+		assert.Contains(t, err.Error(), "count")
+		assert.Contains(t, err.Error(), "launch type")
+	})
+}
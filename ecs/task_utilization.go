@@ -0,0 +1,158 @@
+package ecs
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+)
+
+const (
+	// containerInsightsNamespace is the CloudWatch namespace for ECS Container
+	// Insights metrics.
+	containerInsightsNamespace = "ECS/ContainerInsights"
+	// taskUtilizationWindow is how far back to look for a task's resource
+	// utilization metrics.
+	taskUtilizationWindow = 24 * time.Hour
+)
+
+// ReportTaskResourceUtilization summarizes the CPU and memory utilization of
+// the task over the last day and publishes the summary as metrics to the given
+// CloudWatch namespace. The published metrics are the average and maximum
+// percent utilization of the task's reserved CPU and memory
+// (CPUUtilizationAverage, CPUUtilizationMaximum, MemoryUtilizationAverage and
+// MemoryUtilizationMaximum), with ClusterName and TaskId dimensions.
+//
+// The task's resource usage is read from task-level ECS Container Insights
+// metrics, so the cluster must have Container Insights enabled.
+// Docs: https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/Container-Insights-metrics-ECS.html
+func ReportTaskResourceUtilization(ctx context.Context, cluster, taskARN string, cwClient cocoa.CloudWatchClient, namespace string) error {
+	if cwClient == nil {
+		return errors.New("missing client")
+	}
+	if namespace == "" {
+		return errors.New("must specify a namespace to publish to")
+	}
+
+	parsedARN, err := ParseTaskARN(taskARN)
+	if err != nil {
+		return errors.Wrap(err, "parsing task ARN")
+	}
+	// Task ARNs in the old format do not include the cluster, so the cluster
+	// name has to come from the given cluster instead.
+	clusterName := parsedARN.Cluster
+	if clusterName == "" {
+		clusterName, err = clusterNameFromIdentifier(cluster)
+		if err != nil {
+			return errors.Wrap(err, "getting cluster name")
+		}
+	}
+
+	dims := []*cloudwatch.Dimension{
+		{Name: aws.String("ClusterName"), Value: aws.String(clusterName)},
+		{Name: aws.String("TaskId"), Value: aws.String(parsedARN.TaskID)},
+	}
+	end := time.Now()
+	start := end.Add(-taskUtilizationWindow)
+
+	cpu, err := getResourceUtilization(ctx, cwClient, "CpuUtilized", "CpuReserved", dims, start, end)
+	if err != nil {
+		return errors.Wrap(err, "getting CPU utilization")
+	}
+	mem, err := getResourceUtilization(ctx, cwClient, "MemoryUtilized", "MemoryReserved", dims, start, end)
+	if err != nil {
+		return errors.Wrap(err, "getting memory utilization")
+	}
+
+	percent := aws.String(cloudwatch.StandardUnitPercent)
+	if _, err := cwClient.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+		Namespace: aws.String(namespace),
+		MetricData: []*cloudwatch.MetricDatum{
+			{MetricName: aws.String("CPUUtilizationAverage"), Dimensions: dims, Timestamp: aws.Time(end), Unit: percent, Value: aws.Float64(cpu.average)},
+			{MetricName: aws.String("CPUUtilizationMaximum"), Dimensions: dims, Timestamp: aws.Time(end), Unit: percent, Value: aws.Float64(cpu.maximum)},
+			{MetricName: aws.String("MemoryUtilizationAverage"), Dimensions: dims, Timestamp: aws.Time(end), Unit: percent, Value: aws.Float64(mem.average)},
+			{MetricName: aws.String("MemoryUtilizationMaximum"), Dimensions: dims, Timestamp: aws.Time(end), Unit: percent, Value: aws.Float64(mem.maximum)},
+		},
+	}); err != nil {
+		return errors.Wrap(err, "publishing resource utilization metrics")
+	}
+
+	return nil
+}
+
+// resourceUtilization is the percent utilization of a reserved resource.
+type resourceUtilization struct {
+	average float64
+	maximum float64
+}
+
+// getResourceUtilization computes the percent utilization of a resource from
+// the metrics for the amount of the resource used and the amount reserved.
+func getResourceUtilization(ctx context.Context, c cocoa.CloudWatchClient, utilizedMetric, reservedMetric string, dims []*cloudwatch.Dimension, start, end time.Time) (*resourceUtilization, error) {
+	utilized, err := getMetricSummary(ctx, c, utilizedMetric, dims, start, end)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting metric '%s'", utilizedMetric)
+	}
+	reserved, err := getMetricSummary(ctx, c, reservedMetric, dims, start, end)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting metric '%s'", reservedMetric)
+	}
+	if utility.FromFloat64Ptr(reserved.Average) <= 0 || utility.FromFloat64Ptr(reserved.Maximum) <= 0 {
+		return nil, errors.Errorf("metric '%s' must be positive", reservedMetric)
+	}
+
+	// The maximum utilization is relative to the maximum reservation, since
+	// the average reservation underestimates the reserved amount when the
+	// reservation changes over the time range.
+	return &resourceUtilization{
+		average: 100 * utility.FromFloat64Ptr(utilized.Average) / *reserved.Average,
+		maximum: 100 * utility.FromFloat64Ptr(utilized.Maximum) / *reserved.Maximum,
+	}, nil
+}
+
+// getMetricSummary gets the average and maximum of the Container Insights
+// metric over the time range as a single data point.
+func getMetricSummary(ctx context.Context, c cocoa.CloudWatchClient, metric string, dims []*cloudwatch.Dimension, start, end time.Time) (*cloudwatch.Datapoint, error) {
+	out, err := c.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(containerInsightsNamespace),
+		MetricName: aws.String(metric),
+		Dimensions: dims,
+		StartTime:  aws.Time(start),
+		EndTime:    aws.Time(end),
+		Period:     aws.Int64(int64(end.Sub(start) / time.Second)),
+		Statistics: []*string{aws.String(cloudwatch.StatisticAverage), aws.String(cloudwatch.StatisticMaximum)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Datapoints) == 0 || out.Datapoints[0] == nil {
+		return nil, errors.New("no data points found, the cluster may not have Container Insights enabled")
+	}
+
+	return out.Datapoints[0], nil
+}
+
+// clusterNameFromIdentifier returns the cluster name from the cluster name or
+// ARN.
+func clusterNameFromIdentifier(cluster string) (string, error) {
+	if cluster == "" {
+		return "", errors.New("must specify a cluster")
+	}
+	if !arn.IsARN(cluster) {
+		return cluster, nil
+	}
+	parsed, err := arn.Parse(cluster)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing cluster ARN")
+	}
+	if !strings.HasPrefix(parsed.Resource, "cluster/") {
+		return "", errors.Errorf("ARN resource '%s' should be in the format 'cluster/<cluster>'", parsed.Resource)
+	}
+	return strings.TrimPrefix(parsed.Resource, "cluster/"), nil
+}
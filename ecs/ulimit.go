@@ -0,0 +1,87 @@
+package ecs
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mongodb/grip"
+)
+
+// ulimitValues are the soft and hard limits of a ulimit.
+type ulimitValues struct {
+	soft int64
+	hard int64
+}
+
+// UlimitBuilder builds the ulimits of a container definition. Setting the
+// same ulimit again replaces its limits.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/APIReference/API_Ulimit.html
+type UlimitBuilder struct {
+	limits map[string]ulimitValues
+}
+
+// NewUlimitBuilder returns a new builder for container ulimits.
+func NewUlimitBuilder() *UlimitBuilder {
+	return &UlimitBuilder{
+		limits: map[string]ulimitValues{},
+	}
+}
+
+// WithNoFile sets the limits on the number of open file descriptors.
+func (b *UlimitBuilder) WithNoFile(soft, hard int64) *UlimitBuilder {
+	return b.set(ecs.UlimitNameNofile, soft, hard)
+}
+
+// WithNProc sets the limits on the number of processes.
+func (b *UlimitBuilder) WithNProc(soft, hard int64) *UlimitBuilder {
+	return b.set(ecs.UlimitNameNproc, soft, hard)
+}
+
+// WithMemlock sets the limits on the amount of memory in KiB that can be
+// locked into RAM.
+func (b *UlimitBuilder) WithMemlock(soft, hard int64) *UlimitBuilder {
+	return b.set(ecs.UlimitNameMemlock, soft, hard)
+}
+
+func (b *UlimitBuilder) set(name string, soft, hard int64) *UlimitBuilder {
+	b.limits[name] = ulimitValues{soft: soft, hard: hard}
+	return b
+}
+
+// Validate checks that the limits are not negative and that no soft limit
+// exceeds its hard limit.
+func (b *UlimitBuilder) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	for _, name := range b.names() {
+		limits := b.limits[name]
+		catcher.ErrorfWhen(limits.soft < 0 || limits.hard < 0, "ulimit '%s' cannot have negative limits", name)
+		catcher.ErrorfWhen(limits.soft > limits.hard, "ulimit '%s' soft limit %d exceeds hard limit %d", name, limits.soft, limits.hard)
+	}
+	return catcher.Resolve()
+}
+
+// BuildAll returns all the ulimits sorted by name. It does not validate the
+// ulimits, so callers should check Validate first.
+func (b *UlimitBuilder) BuildAll() []*ecs.Ulimit {
+	var ulimits []*ecs.Ulimit
+	for _, name := range b.names() {
+		limits := b.limits[name]
+		ulimits = append(ulimits, &ecs.Ulimit{
+			Name:      aws.String(name),
+			SoftLimit: aws.Int64(limits.soft),
+			HardLimit: aws.Int64(limits.hard),
+		})
+	}
+	return ulimits
+}
+
+// names returns the names of the set ulimits in sorted order.
+func (b *UlimitBuilder) names() []string {
+	names := make([]string, 0, len(b.limits))
+	for name := range b.limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,49 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUlimitBuilder(t *testing.T) {
+	t.Run("BuildsAllUlimitsSortedByName", func(t *testing.T) {
+		b := NewUlimitBuilder().WithNProc(512, 1024).WithNoFile(1024, 4096).WithMemlock(0, 0)
+		require.NoError(t, b.Validate())
+		ulimits := b.BuildAll()
+		require.Len(t, ulimits, 3)
+
+		assert.Equal(t, ecs.UlimitNameMemlock, utility.FromStringPtr(ulimits[0].Name))
+		assert.Equal(t, ecs.UlimitNameNofile, utility.FromStringPtr(ulimits[1].Name))
+		assert.EqualValues(t, 1024, utility.FromInt64Ptr(ulimits[1].SoftLimit))
+		assert.EqualValues(t, 4096, utility.FromInt64Ptr(ulimits[1].HardLimit))
+		assert.Equal(t, ecs.UlimitNameNproc, utility.FromStringPtr(ulimits[2].Name))
+		assert.EqualValues(t, 512, utility.FromInt64Ptr(ulimits[2].SoftLimit))
+		assert.EqualValues(t, 1024, utility.FromInt64Ptr(ulimits[2].HardLimit))
+	})
+	t.Run("SettingUlimitAgainReplacesLimits", func(t *testing.T) {
+		ulimits := NewUlimitBuilder().WithNoFile(1024, 4096).WithNoFile(2048, 8192).BuildAll()
+		require.Len(t, ulimits, 1)
+		assert.EqualValues(t, 2048, utility.FromInt64Ptr(ulimits[0].SoftLimit))
+		assert.EqualValues(t, 8192, utility.FromInt64Ptr(ulimits[0].HardLimit))
+	})
+	t.Run("AllowsEqualSoftAndHardLimits", func(t *testing.T) {
+		assert.NoError(t, NewUlimitBuilder().WithNoFile(4096, 4096).Validate())
+	})
+	t.Run("BuildsNothingWhenEmpty", func(t *testing.T) {
+		b := NewUlimitBuilder()
+		assert.NoError(t, b.Validate())
+		assert.Empty(t, b.BuildAll())
+	})
+	t.Run("FailsWithSoftLimitExceedingHardLimit", func(t *testing.T) {
+		assert.Error(t, NewUlimitBuilder().WithNoFile(8192, 4096).Validate())
+		assert.Error(t, NewUlimitBuilder().WithNProc(2, 1).Validate())
+		assert.Error(t, NewUlimitBuilder().WithMemlock(2, 1).Validate())
+	})
+	t.Run("FailsWithNegativeLimits", func(t *testing.T) {
+		assert.Error(t, NewUlimitBuilder().WithNoFile(-1, 4096).Validate())
+	})
+}
@@ -0,0 +1,393 @@
+//go:build awsv2
+// +build awsv2
+
+package ecs
+
+import (
+	"context"
+	"strings"
+
+	ecsv2 "github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	cocoaECS "github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/cocoa/internal/awsv2"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+)
+
+// BasicClient provides a cocoa.ECSClient implementation that wraps the AWS
+// ECS API using the AWS SDK for Go v2. It takes and returns the same v1 SDK
+// types as ecs.BasicClient, so it can be used in place of it. It supports
+// retrying requests using exponential backoff and jitter. It is safe for
+// concurrent use.
+type BasicClient struct {
+	base *awsv2.Client
+	ecs  *ecsv2.Client
+}
+
+// NewBasicClient creates a new AWS ECS client from the given options.
+func NewBasicClient(opts awsutil.ClientOptions) (*BasicClient, error) {
+	c, err := awsv2.NewClient(opts, isNonRetryableErrorCode)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+	return &BasicClient{
+		base: c,
+		ecs:  ecsv2.NewFromConfig(c.Config),
+	}, nil
+}
+
+// Close closes the client and cleans up its resources.
+func (c *BasicClient) Close(ctx context.Context) error {
+	return c.base.Close(ctx)
+}
+
+// RegisterTaskDefinition validates the input and registers a new task
+// definition.
+func (c *BasicClient) RegisterTaskDefinition(ctx context.Context, in *ecs.RegisterTaskDefinitionInput) (*ecs.RegisterTaskDefinitionOutput, error) {
+	if err := cocoaECS.ValidateRegisterTaskDefinitionInput(in); err != nil {
+		return nil, errors.Wrap(err, "invalid input")
+	}
+
+	var v2In ecsv2.RegisterTaskDefinitionInput
+	var out ecs.RegisterTaskDefinitionOutput
+	if err := c.base.Call(ctx, "RegisterTaskDefinition", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.RegisterTaskDefinition(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DescribeTaskDefinition describes an existing task definition.
+func (c *BasicClient) DescribeTaskDefinition(ctx context.Context, in *ecs.DescribeTaskDefinitionInput) (*ecs.DescribeTaskDefinitionOutput, error) {
+	var v2In ecsv2.DescribeTaskDefinitionInput
+	var out ecs.DescribeTaskDefinitionOutput
+	if err := c.base.Call(ctx, "DescribeTaskDefinition", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.DescribeTaskDefinition(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTaskDefinitions returns the ARNs for the task definitions that
+// match the input filters.
+func (c *BasicClient) ListTaskDefinitions(ctx context.Context, in *ecs.ListTaskDefinitionsInput) (*ecs.ListTaskDefinitionsOutput, error) {
+	var v2In ecsv2.ListTaskDefinitionsInput
+	var out ecs.ListTaskDefinitionsOutput
+	if err := c.base.Call(ctx, "ListTaskDefinitions", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.ListTaskDefinitions(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeregisterTaskDefinition deregisters an existing task definition.
+func (c *BasicClient) DeregisterTaskDefinition(ctx context.Context, in *ecs.DeregisterTaskDefinitionInput) (*ecs.DeregisterTaskDefinitionOutput, error) {
+	var v2In ecsv2.DeregisterTaskDefinitionInput
+	var out ecs.DeregisterTaskDefinitionOutput
+	if err := c.base.Call(ctx, "DeregisterTaskDefinition", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.DeregisterTaskDefinition(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RunTask runs a new task.
+func (c *BasicClient) RunTask(ctx context.Context, in *ecs.RunTaskInput) (*ecs.RunTaskOutput, error) {
+	var v2In ecsv2.RunTaskInput
+	var out ecs.RunTaskOutput
+	if err := c.base.Call(ctx, "RunTask", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.RunTask(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DescribeTasks describes one or more existing tasks.
+func (c *BasicClient) DescribeTasks(ctx context.Context, in *ecs.DescribeTasksInput) (*ecs.DescribeTasksOutput, error) {
+	var v2In ecsv2.DescribeTasksInput
+	var out ecs.DescribeTasksOutput
+	if err := c.base.Call(ctx, "DescribeTasks", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.DescribeTasks(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTasks returns the ARNs for the tasks that match the input filters.
+func (c *BasicClient) ListTasks(ctx context.Context, in *ecs.ListTasksInput) (*ecs.ListTasksOutput, error) {
+	var v2In ecsv2.ListTasksInput
+	var out ecs.ListTasksOutput
+	if err := c.base.Call(ctx, "ListTasks", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.ListTasks(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StopTask stops a running task.
+func (c *BasicClient) StopTask(ctx context.Context, in *ecs.StopTaskInput) (*ecs.StopTaskOutput, error) {
+	var v2In ecsv2.StopTaskInput
+	var out ecs.StopTaskOutput
+	if err := c.base.Call(ctx, "StopTask", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.StopTask(ctx, &v2In)
+	}); err != nil {
+		return nil, convertStopTaskError(in, err)
+	}
+	return &out, nil
+}
+
+// TagResource adds tags to an existing resource in ECS.
+func (c *BasicClient) TagResource(ctx context.Context, in *ecs.TagResourceInput) (*ecs.TagResourceOutput, error) {
+	var v2In ecsv2.TagResourceInput
+	var out ecs.TagResourceOutput
+	if err := c.base.Call(ctx, "TagResource", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.TagResource(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListContainerInstances lists the container instances in a cluster.
+func (c *BasicClient) ListContainerInstances(ctx context.Context, in *ecs.ListContainerInstancesInput) (*ecs.ListContainerInstancesOutput, error) {
+	var v2In ecsv2.ListContainerInstancesInput
+	var out ecs.ListContainerInstancesOutput
+	if err := c.base.Call(ctx, "ListContainerInstances", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.ListContainerInstances(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DescribeContainerInstances describes one or more container
+// instances.
+func (c *BasicClient) DescribeContainerInstances(ctx context.Context, in *ecs.DescribeContainerInstancesInput) (*ecs.DescribeContainerInstancesOutput, error) {
+	var v2In ecsv2.DescribeContainerInstancesInput
+	var out ecs.DescribeContainerInstancesOutput
+	if err := c.base.Call(ctx, "DescribeContainerInstances", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.DescribeContainerInstances(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DescribeClusters describes one or more clusters.
+func (c *BasicClient) DescribeClusters(ctx context.Context, in *ecs.DescribeClustersInput) (*ecs.DescribeClustersOutput, error) {
+	var v2In ecsv2.DescribeClustersInput
+	var out ecs.DescribeClustersOutput
+	if err := c.base.Call(ctx, "DescribeClusters", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.DescribeClusters(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAccountSettings lists the ECS account settings.
+func (c *BasicClient) ListAccountSettings(ctx context.Context, in *ecs.ListAccountSettingsInput) (*ecs.ListAccountSettingsOutput, error) {
+	var v2In ecsv2.ListAccountSettingsInput
+	var out ecs.ListAccountSettingsOutput
+	if err := c.base.Call(ctx, "ListAccountSettings", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.ListAccountSettings(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DescribeCapacityProviders describes one or more capacity
+// providers.
+func (c *BasicClient) DescribeCapacityProviders(ctx context.Context, in *ecs.DescribeCapacityProvidersInput) (*ecs.DescribeCapacityProvidersOutput, error) {
+	var v2In ecsv2.DescribeCapacityProvidersInput
+	var out ecs.DescribeCapacityProvidersOutput
+	if err := c.base.Call(ctx, "DescribeCapacityProviders", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.DescribeCapacityProviders(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTaskDefinitionFamilies lists the task definition families
+// that match the input filters.
+func (c *BasicClient) ListTaskDefinitionFamilies(ctx context.Context, in *ecs.ListTaskDefinitionFamiliesInput) (*ecs.ListTaskDefinitionFamiliesOutput, error) {
+	var v2In ecsv2.ListTaskDefinitionFamiliesInput
+	var out ecs.ListTaskDefinitionFamiliesOutput
+	if err := c.base.Call(ctx, "ListTaskDefinitionFamilies", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.ListTaskDefinitionFamilies(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateCluster creates a new cluster.
+func (c *BasicClient) CreateCluster(ctx context.Context, in *ecs.CreateClusterInput) (*ecs.CreateClusterOutput, error) {
+	var v2In ecsv2.CreateClusterInput
+	var out ecs.CreateClusterOutput
+	if err := c.base.Call(ctx, "CreateCluster", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.CreateCluster(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateService creates a new service in a cluster.
+func (c *BasicClient) CreateService(ctx context.Context, in *ecs.CreateServiceInput) (*ecs.CreateServiceOutput, error) {
+	var v2In ecsv2.CreateServiceInput
+	var out ecs.CreateServiceOutput
+	if err := c.base.Call(ctx, "CreateService", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.CreateService(ctx, &v2In)
+	}); err != nil {
+		return nil, convertClusterNotFoundError(utility.FromStringPtr(in.Cluster), err)
+	}
+	return &out, nil
+}
+
+// ListServices lists the services in a cluster.
+func (c *BasicClient) ListServices(ctx context.Context, in *ecs.ListServicesInput) (*ecs.ListServicesOutput, error) {
+	var v2In ecsv2.ListServicesInput
+	var out ecs.ListServicesOutput
+	if err := c.base.Call(ctx, "ListServices", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.ListServices(ctx, &v2In)
+	}); err != nil {
+		return nil, convertClusterNotFoundError(utility.FromStringPtr(in.Cluster), err)
+	}
+	return &out, nil
+}
+
+// DescribeServices describes one or more services in a
+// cluster.
+func (c *BasicClient) DescribeServices(ctx context.Context, in *ecs.DescribeServicesInput) (*ecs.DescribeServicesOutput, error) {
+	var v2In ecsv2.DescribeServicesInput
+	var out ecs.DescribeServicesOutput
+	if err := c.base.Call(ctx, "DescribeServices", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.DescribeServices(ctx, &v2In)
+	}); err != nil {
+		return nil, convertClusterNotFoundError(utility.FromStringPtr(in.Cluster), err)
+	}
+	return &out, nil
+}
+
+// UpdateService modifies the configuration of an existing service.
+func (c *BasicClient) UpdateService(ctx context.Context, in *ecs.UpdateServiceInput) (*ecs.UpdateServiceOutput, error) {
+	var v2In ecsv2.UpdateServiceInput
+	var out ecs.UpdateServiceOutput
+	if err := c.base.Call(ctx, "UpdateService", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.UpdateService(ctx, &v2In)
+	}); err != nil {
+		return nil, convertUpdateServiceError(in, err)
+	}
+	return &out, nil
+}
+
+// RegisterContainerInstance registers an instance as a container
+// instance in a cluster.
+func (c *BasicClient) RegisterContainerInstance(ctx context.Context, in *ecs.RegisterContainerInstanceInput) (*ecs.RegisterContainerInstanceOutput, error) {
+	var v2In ecsv2.RegisterContainerInstanceInput
+	var out ecs.RegisterContainerInstanceOutput
+	if err := c.base.Call(ctx, "RegisterContainerInstance", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.RegisterContainerInstance(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeregisterContainerInstance deregisters a container instance from
+// a cluster.
+func (c *BasicClient) DeregisterContainerInstance(ctx context.Context, in *ecs.DeregisterContainerInstanceInput) (*ecs.DeregisterContainerInstanceOutput, error) {
+	var v2In ecsv2.DeregisterContainerInstanceInput
+	var out ecs.DeregisterContainerInstanceOutput
+	if err := c.base.Call(ctx, "DeregisterContainerInstance", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.DeregisterContainerInstance(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateContainerInstancesState modifies the status of one or more
+// container instances.
+func (c *BasicClient) UpdateContainerInstancesState(ctx context.Context, in *ecs.UpdateContainerInstancesStateInput) (*ecs.UpdateContainerInstancesStateOutput, error) {
+	var v2In ecsv2.UpdateContainerInstancesStateInput
+	var out ecs.UpdateContainerInstancesStateOutput
+	if err := c.base.Call(ctx, "UpdateContainerInstancesState", in, &v2In, &out, func() (interface{}, error) {
+		return c.ecs.UpdateContainerInstancesState(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// convertStopTaskError returns the cocoa error for a missing task, like
+// ecs.BasicClient does.
+func convertStopTaskError(in *ecs.StopTaskInput, err error) error {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+	if awsErr.Code() == ecs.ErrCodeInvalidParameterException && strings.Contains(awsErr.Message(), "The referenced task was not found") {
+		return cocoa.NewECSTaskNotFoundError(utility.FromStringPtr(in.Task), awsErr)
+	}
+	return err
+}
+
+// convertUpdateServiceError returns the cocoa error for a missing service or
+// cluster, like ecs.BasicClient does.
+func convertUpdateServiceError(in *ecs.UpdateServiceInput, err error) error {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+	switch awsErr.Code() {
+	case ecs.ErrCodeServiceNotFoundException:
+		return cocoa.NewECSServiceNotFoundError(utility.FromStringPtr(in.Service), awsErr)
+	case ecs.ErrCodeClusterNotFoundException:
+		return cocoa.NewECSClusterNotFoundError(utility.FromStringPtr(in.Cluster), awsErr)
+	default:
+		return err
+	}
+}
+
+// convertClusterNotFoundError returns the cocoa error for a missing cluster,
+// like ecs.BasicClient does.
+func convertClusterNotFoundError(cluster string, err error) error {
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ecs.ErrCodeClusterNotFoundException {
+		return cocoa.NewECSClusterNotFoundError(cluster, awsErr)
+	}
+	return err
+}
+
+// isNonRetryableErrorCode returns whether or not the error code from ECS is
+// known to be not retryable.
+func isNonRetryableErrorCode(code string) bool {
+	switch code {
+	case ecs.ErrCodeAccessDeniedException,
+		ecs.ErrCodeClientException,
+		ecs.ErrCodeInvalidParameterException,
+		ecs.ErrCodeClusterNotFoundException,
+		ecs.ErrCodeServiceNotFoundException,
+		ecs.ErrCodeServiceNotActiveException,
+		request.InvalidParameterErrCode,
+		request.ParamRequiredErrCode:
+		return true
+	default:
+		return false
+	}
+}
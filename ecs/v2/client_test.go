@@ -0,0 +1,215 @@
+//go:build awsv2
+// +build awsv2
+
+package ecs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const defaultTestTimeout = time.Second
+
+// fakeECSAPI is a fake ECS API server that records the requests and responds
+// with the response for the operation.
+type fakeECSAPI struct {
+	mu        sync.Mutex
+	requests  map[string][]map[string]interface{}
+	responses map[string]fakeResponse
+}
+
+type fakeResponse struct {
+	status int
+	body   string
+}
+
+func newFakeECSAPI() *fakeECSAPI {
+	return &fakeECSAPI{
+		requests:  map[string][]map[string]interface{}{},
+		responses: map[string]fakeResponse{},
+	}
+}
+
+func (f *fakeECSAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	op := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AmazonEC2ContainerServiceV20141113.")
+	b, _ := io.ReadAll(r.Body)
+	var body map[string]interface{}
+	_ = json.Unmarshal(b, &body)
+
+	f.mu.Lock()
+	f.requests[op] = append(f.requests[op], body)
+	resp, ok := f.responses[op]
+	f.mu.Unlock()
+	if !ok {
+		resp = fakeResponse{status: http.StatusOK, body: "{}"}
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.Header().Set("X-Amzn-RequestId", "request_id")
+	w.WriteHeader(resp.status)
+	_, _ = w.Write([]byte(resp.body))
+}
+
+func (f *fakeECSAPI) getRequests(op string) []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[op]
+}
+
+func TestBasicClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.ECSClient)(nil), &BasicClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newClient := func(t *testing.T, url string) *BasicClient {
+		opts := awsutil.NewClientOptions().
+			SetCredentials(credentials.NewStaticCredentials("id", "secret", "")).
+			SetRegion("us-east-1").
+			SetEndpoint(url).
+			SetRetryOptions(utility.RetryOptions{MaxAttempts: 3, MinDelay: time.Millisecond, MaxDelay: time.Millisecond})
+		c, err := NewBasicClient(*opts)
+		require.NoError(t, err)
+		return c
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, api *fakeECSAPI, c *BasicClient){
+		"RunTaskConvertsInputAndOutput": func(ctx context.Context, t *testing.T, api *fakeECSAPI, c *BasicClient) {
+			api.responses["RunTask"] = fakeResponse{
+				status: http.StatusOK,
+				body:   `{"tasks":[{"taskArn":"task_arn","lastStatus":"PENDING","containers":[{"name":"container"}]}]}`,
+			}
+
+			out, err := c.RunTask(ctx, &ecs.RunTaskInput{
+				Cluster:        aws.String("cluster"),
+				TaskDefinition: aws.String("family:1"),
+				Count:          aws.Int64(1),
+				Tags:           []*ecs.Tag{{Key: aws.String("key"), Value: aws.String("value")}},
+			})
+			require.NoError(t, err)
+			require.Len(t, out.Tasks, 1)
+			assert.Equal(t, "task_arn", aws.StringValue(out.Tasks[0].TaskArn))
+			assert.Equal(t, "PENDING", aws.StringValue(out.Tasks[0].LastStatus))
+			require.Len(t, out.Tasks[0].Containers, 1)
+			assert.Equal(t, "container", aws.StringValue(out.Tasks[0].Containers[0].Name))
+
+			reqs := api.getRequests("RunTask")
+			require.Len(t, reqs, 1)
+			assert.Equal(t, "cluster", reqs[0]["cluster"])
+			assert.Equal(t, "family:1", reqs[0]["taskDefinition"])
+			assert.EqualValues(t, 1, reqs[0]["count"])
+			assert.Equal(t, []interface{}{map[string]interface{}{"key": "key", "value": "value"}}, reqs[0]["tags"])
+		},
+		"DescribeTasksReturnsFailures": func(ctx context.Context, t *testing.T, api *fakeECSAPI, c *BasicClient) {
+			api.responses["DescribeTasks"] = fakeResponse{
+				status: http.StatusOK,
+				body:   `{"failures":[{"arn":"task_arn","reason":"MISSING"}]}`,
+			}
+
+			out, err := c.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+				Cluster: aws.String("cluster"),
+				Tasks:   []*string{aws.String("task_arn")},
+			})
+			require.NoError(t, err)
+			assert.Empty(t, out.Tasks)
+			require.Len(t, out.Failures, 1)
+			assert.Equal(t, "MISSING", aws.StringValue(out.Failures[0].Reason))
+		},
+		"RegisterTaskDefinitionValidatesInput": func(ctx context.Context, t *testing.T, api *fakeECSAPI, c *BasicClient) {
+			out, err := c.RegisterTaskDefinition(ctx, &ecs.RegisterTaskDefinitionInput{})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+			assert.Empty(t, api.getRequests("RegisterTaskDefinition"))
+		},
+		"ReturnsV1ErrorsWithoutRetryingNonRetryableErrors": func(ctx context.Context, t *testing.T, api *fakeECSAPI, c *BasicClient) {
+			api.responses["StopTask"] = fakeResponse{
+				status: http.StatusBadRequest,
+				body:   `{"__type":"InvalidParameterException","message":"The referenced task was not found."}`,
+			}
+
+			out, err := c.StopTask(ctx, &ecs.StopTaskInput{
+				Cluster: aws.String("cluster"),
+				Task:    aws.String("task_arn"),
+			})
+			require.Error(t, err)
+			assert.Zero(t, out)
+			assert.True(t, cocoa.IsECSTaskNotFoundError(err))
+			assert.Len(t, api.getRequests("StopTask"), 1)
+		},
+		"RetriesRetryableErrors": func(ctx context.Context, t *testing.T, api *fakeECSAPI, c *BasicClient) {
+			api.responses["ListTasks"] = fakeResponse{
+				status: http.StatusInternalServerError,
+				body:   `{"__type":"ServerException","message":"internal error"}`,
+			}
+
+			out, err := c.ListTasks(ctx, &ecs.ListTasksInput{Cluster: aws.String("cluster")})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+			assert.Len(t, api.getRequests("ListTasks"), 3)
+		},
+		"CreateServiceReturnsClusterNotFoundError": func(ctx context.Context, t *testing.T, api *fakeECSAPI, c *BasicClient) {
+			api.responses["CreateService"] = fakeResponse{
+				status: http.StatusBadRequest,
+				body:   `{"__type":"ClusterNotFoundException","message":"Cluster not found."}`,
+			}
+
+			out, err := c.CreateService(ctx, &ecs.CreateServiceInput{
+				Cluster:        aws.String("cluster"),
+				ServiceName:    aws.String("service"),
+				TaskDefinition: aws.String("task_definition"),
+			})
+			require.Error(t, err)
+			assert.Zero(t, out)
+			assert.True(t, cocoa.IsECSClusterNotFoundError(err))
+			assert.Len(t, api.getRequests("CreateService"), 1)
+		},
+		"UpdateServiceReturnsServiceNotFoundError": func(ctx context.Context, t *testing.T, api *fakeECSAPI, c *BasicClient) {
+			api.responses["UpdateService"] = fakeResponse{
+				status: http.StatusBadRequest,
+				body:   `{"__type":"ServiceNotFoundException","message":"Service not found."}`,
+			}
+
+			out, err := c.UpdateService(ctx, &ecs.UpdateServiceInput{
+				Cluster: aws.String("cluster"),
+				Service: aws.String("service"),
+			})
+			require.Error(t, err)
+			assert.Zero(t, out)
+			var notFound *cocoa.ECSServiceNotFoundError
+			assert.True(t, errors.As(err, &notFound))
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			api := newFakeECSAPI()
+			srv := httptest.NewServer(api)
+			defer srv.Close()
+
+			c := newClient(t, srv.URL)
+			defer func() {
+				assert.NoError(t, c.Close(tctx))
+			}()
+
+			tCase(tctx, t, api, c)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package ecs
+
+import (
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// volumeNameRegexp matches valid task definition volume names.
+var volumeNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,255}$`)
+
+// VolumeBuilder builds a task definition volume that is backed by exactly one
+// of an EFS file system, a Docker volume, or a bind mount on the host.
+// Docs: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/using_data_volumes.html
+type VolumeBuilder struct {
+	name   string
+	efs    *ecs.EFSVolumeConfiguration
+	docker *ecs.DockerVolumeConfiguration
+	host   *ecs.HostVolumeProperties
+}
+
+// NewVolumeBuilder returns a new builder for a volume.
+func NewVolumeBuilder() *VolumeBuilder {
+	return &VolumeBuilder{}
+}
+
+// WithName sets the name of the volume, which container mount points use to
+// refer to it.
+func (b *VolumeBuilder) WithName(name string) *VolumeBuilder {
+	b.name = name
+	return b
+}
+
+// WithEFSVolume makes the volume an EFS file system. If the access point ID is
+// set, the file system is mounted through the access point, which requires
+// transit encryption, so transit encryption is also enabled.
+func (b *VolumeBuilder) WithEFSVolume(accessPointID, fileSystemID string) *VolumeBuilder {
+	b.efs = &ecs.EFSVolumeConfiguration{
+		FileSystemId: aws.String(fileSystemID),
+	}
+	if accessPointID != "" {
+		b.efs.SetTransitEncryption(ecs.EFSTransitEncryptionEnabled)
+		b.efs.SetAuthorizationConfig(&ecs.EFSAuthorizationConfig{
+			AccessPointId: aws.String(accessPointID),
+		})
+	}
+	return b
+}
+
+// WithDockerVolume makes the volume a Docker volume that uses the given volume
+// driver (e.g. "local") and driver-specific options.
+func (b *VolumeBuilder) WithDockerVolume(driver string, driverOpts map[string]string) *VolumeBuilder {
+	b.docker = &ecs.DockerVolumeConfiguration{
+		Driver:     aws.String(driver),
+		DriverOpts: aws.StringMap(driverOpts),
+	}
+	if len(driverOpts) == 0 {
+		b.docker.DriverOpts = nil
+	}
+	return b
+}
+
+// WithBindMountHost makes the volume a bind mount of the given path on the
+// host.
+func (b *VolumeBuilder) WithBindMountHost(sourcePath string) *VolumeBuilder {
+	b.host = &ecs.HostVolumeProperties{
+		SourcePath: aws.String(sourcePath),
+	}
+	return b
+}
+
+// Validate checks that the volume has a valid name and that exactly one type
+// of volume is configured with its required settings.
+func (b *VolumeBuilder) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	catcher.ErrorfWhen(!volumeNameRegexp.MatchString(b.name), "invalid volume name '%s'", b.name)
+
+	var numTypes int
+	if b.efs != nil {
+		numTypes++
+		catcher.NewWhen(aws.StringValue(b.efs.FileSystemId) == "", "must specify an EFS file system ID")
+	}
+	if b.docker != nil {
+		numTypes++
+		catcher.NewWhen(aws.StringValue(b.docker.Driver) == "", "must specify a Docker volume driver")
+	}
+	if b.host != nil {
+		numTypes++
+		catcher.NewWhen(aws.StringValue(b.host.SourcePath) == "", "must specify a host source path for a bind mount")
+	}
+	catcher.ErrorfWhen(numTypes != 1, "must specify exactly one volume type, but %d were specified", numTypes)
+
+	return catcher.Resolve()
+}
+
+// Build validates the volume and returns it.
+func (b *VolumeBuilder) Build() (*ecs.Volume, error) {
+	if err := b.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid volume")
+	}
+	return &ecs.Volume{
+		Name:                      aws.String(b.name),
+		EfsVolumeConfiguration:    b.efs,
+		DockerVolumeConfiguration: b.docker,
+		Host:                      b.host,
+	}, nil
+}
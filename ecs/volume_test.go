@@ -0,0 +1,94 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolumeBuilder(t *testing.T) {
+	t.Run("BuildsEFSVolumeWithAccessPoint", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithName("data").WithEFSVolume("fsap-12345", "fs-12345").Build()
+		require.NoError(t, err)
+		require.NotZero(t, vol)
+		assert.Equal(t, "data", utility.FromStringPtr(vol.Name))
+		require.NotZero(t, vol.EfsVolumeConfiguration)
+		assert.Equal(t, "fs-12345", utility.FromStringPtr(vol.EfsVolumeConfiguration.FileSystemId))
+		assert.Equal(t, ecs.EFSTransitEncryptionEnabled, utility.FromStringPtr(vol.EfsVolumeConfiguration.TransitEncryption))
+		require.NotZero(t, vol.EfsVolumeConfiguration.AuthorizationConfig)
+		assert.Equal(t, "fsap-12345", utility.FromStringPtr(vol.EfsVolumeConfiguration.AuthorizationConfig.AccessPointId))
+		assert.Zero(t, vol.DockerVolumeConfiguration)
+		assert.Zero(t, vol.Host)
+		assert.NoError(t, vol.Validate())
+	})
+	t.Run("BuildsEFSVolumeWithoutAccessPoint", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithName("data").WithEFSVolume("", "fs-12345").Build()
+		require.NoError(t, err)
+		require.NotZero(t, vol.EfsVolumeConfiguration)
+		assert.Zero(t, vol.EfsVolumeConfiguration.AuthorizationConfig)
+		assert.Zero(t, vol.EfsVolumeConfiguration.TransitEncryption)
+	})
+	t.Run("BuildsDockerVolume", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithName("data").WithDockerVolume("local", map[string]string{"type": "tmpfs"}).Build()
+		require.NoError(t, err)
+		require.NotZero(t, vol.DockerVolumeConfiguration)
+		assert.Equal(t, "local", utility.FromStringPtr(vol.DockerVolumeConfiguration.Driver))
+		assert.Equal(t, map[string]*string{"type": aws.String("tmpfs")}, vol.DockerVolumeConfiguration.DriverOpts)
+		assert.Zero(t, vol.EfsVolumeConfiguration)
+		assert.Zero(t, vol.Host)
+	})
+	t.Run("BuildsDockerVolumeWithoutDriverOptions", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithName("data").WithDockerVolume("local", nil).Build()
+		require.NoError(t, err)
+		require.NotZero(t, vol.DockerVolumeConfiguration)
+		assert.Nil(t, vol.DockerVolumeConfiguration.DriverOpts)
+	})
+	t.Run("BuildsBindMountVolume", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithName("data").WithBindMountHost("/var/data").Build()
+		require.NoError(t, err)
+		require.NotZero(t, vol.Host)
+		assert.Equal(t, "/var/data", utility.FromStringPtr(vol.Host.SourcePath))
+		assert.Zero(t, vol.EfsVolumeConfiguration)
+		assert.Zero(t, vol.DockerVolumeConfiguration)
+	})
+	t.Run("FailsWithoutVolumeType", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithName("data").Build()
+		assert.Error(t, err)
+		assert.Zero(t, vol)
+	})
+	t.Run("FailsWithMultipleVolumeTypes", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithName("data").WithEFSVolume("", "fs-12345").WithBindMountHost("/var/data").Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exactly one")
+		assert.Zero(t, vol)
+	})
+	t.Run("FailsWithoutName", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithBindMountHost("/var/data").Build()
+		assert.Error(t, err)
+		assert.Zero(t, vol)
+	})
+	t.Run("FailsWithInvalidName", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithName("invalid name").WithBindMountHost("/var/data").Build()
+		assert.Error(t, err)
+		assert.Zero(t, vol)
+	})
+	t.Run("FailsWithoutEFSFileSystemID", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithName("data").WithEFSVolume("fsap-12345", "").Build()
+		assert.Error(t, err)
+		assert.Zero(t, vol)
+	})
+	t.Run("FailsWithoutDockerVolumeDriver", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithName("data").WithDockerVolume("", nil).Build()
+		assert.Error(t, err)
+		assert.Zero(t, vol)
+	})
+	t.Run("FailsWithoutBindMountSourcePath", func(t *testing.T) {
+		vol, err := NewVolumeBuilder().WithName("data").WithBindMountHost("").Build()
+		assert.Error(t, err)
+		assert.Zero(t, vol)
+	})
+}
@@ -8,6 +8,14 @@ import (
 
 // ECSClient provides a common interface to interact with a client backed by
 // AWS ECS. Implementations must handle retrying and backoff.
+//
+// Implementations must be safe for concurrent use by multiple goroutines, so a
+// single client can be shared by callers that make ECS API requests in
+// parallel (e.g. to run or describe many tasks at once). The exception is
+// Close, which callers should only call once all other requests using the
+// client are finished. Implementation-specific configuration methods outside of
+// this interface are not required to be safe for concurrent use, so they
+// should be called before the client is shared.
 type ECSClient interface {
 	// RegisterTaskDefinition registers the definition for a new task with ECS.
 	RegisterTaskDefinition(context.Context, *ecs.RegisterTaskDefinitionInput) (*ecs.RegisterTaskDefinitionOutput, error)
@@ -29,6 +37,46 @@ type ECSClient interface {
 	StopTask(ctx context.Context, in *ecs.StopTaskInput) (*ecs.StopTaskOutput, error)
 	// TagResource adds tags to an ECS resource.
 	TagResource(ctx context.Context, in *ecs.TagResourceInput) (*ecs.TagResourceOutput, error)
+	// ListContainerInstances lists all ECS container instances matching the
+	// input.
+	ListContainerInstances(ctx context.Context, in *ecs.ListContainerInstancesInput) (*ecs.ListContainerInstancesOutput, error)
+	// DescribeContainerInstances gets information about the configuration and
+	// status of container instances.
+	DescribeContainerInstances(ctx context.Context, in *ecs.DescribeContainerInstancesInput) (*ecs.DescribeContainerInstancesOutput, error)
+	// DescribeClusters gets information about the configuration and status of
+	// clusters.
+	DescribeClusters(ctx context.Context, in *ecs.DescribeClustersInput) (*ecs.DescribeClustersOutput, error)
+	// ListAccountSettings lists the account settings for ECS resources.
+	ListAccountSettings(ctx context.Context, in *ecs.ListAccountSettingsInput) (*ecs.ListAccountSettingsOutput, error)
+	// DescribeCapacityProviders gets information about the configuration and
+	// status of capacity providers.
+	DescribeCapacityProviders(ctx context.Context, in *ecs.DescribeCapacityProvidersInput) (*ecs.DescribeCapacityProvidersOutput, error)
+	// ListTaskDefinitionFamilies lists all ECS task definition families
+	// matching the input.
+	ListTaskDefinitionFamilies(ctx context.Context, in *ecs.ListTaskDefinitionFamiliesInput) (*ecs.ListTaskDefinitionFamiliesOutput, error)
+	// CreateCluster creates a new ECS cluster.
+	CreateCluster(ctx context.Context, in *ecs.CreateClusterInput) (*ecs.CreateClusterOutput, error)
+	// CreateService creates a new ECS service that runs and maintains tasks
+	// from a task definition in a cluster.
+	CreateService(ctx context.Context, in *ecs.CreateServiceInput) (*ecs.CreateServiceOutput, error)
+	// ListServices lists all ECS services in a cluster matching the input.
+	ListServices(ctx context.Context, in *ecs.ListServicesInput) (*ecs.ListServicesOutput, error)
+	// DescribeServices gets information about the configuration and status of
+	// services.
+	DescribeServices(ctx context.Context, in *ecs.DescribeServicesInput) (*ecs.DescribeServicesOutput, error)
+	// UpdateService modifies the configuration of an existing ECS service, such
+	// as its task definition or desired count.
+	UpdateService(ctx context.Context, in *ecs.UpdateServiceInput) (*ecs.UpdateServiceOutput, error)
+	// RegisterContainerInstance registers an instance (e.g. an EC2 instance or
+	// an external instance managed by ECS Anywhere) as a container instance in
+	// a cluster.
+	RegisterContainerInstance(ctx context.Context, in *ecs.RegisterContainerInstanceInput) (*ecs.RegisterContainerInstanceOutput, error)
+	// DeregisterContainerInstance deregisters a container instance from a
+	// cluster.
+	DeregisterContainerInstance(ctx context.Context, in *ecs.DeregisterContainerInstanceInput) (*ecs.DeregisterContainerInstanceOutput, error)
+	// UpdateContainerInstancesState updates the status of container instances
+	// (e.g. to drain them before they are terminated).
+	UpdateContainerInstancesState(ctx context.Context, in *ecs.UpdateContainerInstancesStateInput) (*ecs.UpdateContainerInstancesStateOutput, error)
 	// Close closes the client and cleans up its resources. Implementations
 	// should ensure that this is idempotent.
 	Close(ctx context.Context) error
@@ -6,21 +6,76 @@ import (
 	"github.com/pkg/errors"
 )
 
+var (
+	// ErrECSTaskNotFound is a sentinel error that matches any
+	// ECSTaskNotFoundError when used with errors.Is.
+	ErrECSTaskNotFound = &ECSTaskNotFoundError{}
+	// ErrECSServiceNotFound is a sentinel error that matches any
+	// ECSServiceNotFoundError when used with errors.Is.
+	ErrECSServiceNotFound = &ECSServiceNotFoundError{}
+	// ErrECSClusterNotFound is a sentinel error that matches any
+	// ECSClusterNotFoundError when used with errors.Is.
+	ErrECSClusterNotFound = &ECSClusterNotFoundError{}
+	// ErrSecretNotFound is a sentinel error that matches any
+	// SecretNotFoundError when used with errors.Is.
+	ErrSecretNotFound = &SecretNotFoundError{}
+)
+
+// notFoundMessage returns the error message for a resource that could not be
+// found, including the underlying cause if there is one.
+func notFoundMessage(resource, id string, cause error) string {
+	msg := fmt.Sprintf("%s '%s' not found", resource, id)
+	if cause != nil {
+		msg += ": " + cause.Error()
+	}
+	return msg
+}
+
 // ECSTaskNotFoundError indicates that the reason for an error or failure in an
 // ECS request is because the task with the specified ARN could not be found.
 type ECSTaskNotFoundError struct {
 	ARN string
+	// Cause is the underlying error, if any.
+	Cause error
 }
 
 // Error returns the formatted error message including the ARN of the task.
 func (e *ECSTaskNotFoundError) Error() string {
-	return fmt.Sprintf("task '%s' not found", e.ARN)
+	return notFoundMessage("task", e.ARN, e.Cause)
+}
+
+// Unwrap returns the underlying cause of the error.
+func (e *ECSTaskNotFoundError) Unwrap() error {
+	return e.Cause
+}
+
+// Is returns whether or not the target is an ECSTaskNotFoundError for the same
+// task. The ErrECSTaskNotFound sentinel, which has no ARN, matches any
+// ECSTaskNotFoundError.
+func (e *ECSTaskNotFoundError) Is(target error) bool {
+	t, ok := target.(*ECSTaskNotFoundError)
+	if !ok || t == nil {
+		return false
+	}
+	return t.ARN == "" || t.ARN == e.ARN
+}
+
+// As sets the target to this error if the target is a
+// **ECSTaskNotFoundError.
+func (e *ECSTaskNotFoundError) As(target interface{}) bool {
+	t, ok := target.(**ECSTaskNotFoundError)
+	if !ok || t == nil {
+		return false
+	}
+	*t = e
+	return true
 }
 
 // NewECSTaskNotFoundError returns a new error with the given ARN indicating
-// that the task could not be found in ECS.
-func NewECSTaskNotFoundError(arn string) *ECSTaskNotFoundError {
-	return &ECSTaskNotFoundError{ARN: arn}
+// that the task could not be found in ECS. The cause is the underlying error,
+// if any.
+func NewECSTaskNotFoundError(arn string, cause error) *ECSTaskNotFoundError {
+	return &ECSTaskNotFoundError{ARN: arn, Cause: cause}
 }
 
 // IsECSTaskNotFoundError returns whether or not the error is due to not being
@@ -29,6 +84,143 @@ func IsECSTaskNotFoundError(err error) bool {
 	if err == nil {
 		return false
 	}
-	_, ok := errors.Cause(err).(*ECSTaskNotFoundError)
-	return ok
+	return errors.Is(err, ErrECSTaskNotFound)
+}
+
+// ECSServiceNotFoundError indicates that the reason for an error in an ECS
+// request is because the service with the specified name could not be found.
+type ECSServiceNotFoundError struct {
+	Service string
+	// Cause is the underlying error, if any.
+	Cause error
+}
+
+// Error returns the formatted error message including the name of the
+// service.
+func (e *ECSServiceNotFoundError) Error() string {
+	return notFoundMessage("service", e.Service, e.Cause)
+}
+
+// Unwrap returns the underlying cause of the error.
+func (e *ECSServiceNotFoundError) Unwrap() error {
+	return e.Cause
+}
+
+// Is returns whether or not the target is an ECSServiceNotFoundError for the
+// same service. The ErrECSServiceNotFound sentinel, which has no service,
+// matches any ECSServiceNotFoundError.
+func (e *ECSServiceNotFoundError) Is(target error) bool {
+	t, ok := target.(*ECSServiceNotFoundError)
+	if !ok || t == nil {
+		return false
+	}
+	return t.Service == "" || t.Service == e.Service
+}
+
+// NewECSServiceNotFoundError returns a new error with the given service name
+// indicating that the service could not be found in ECS. The cause is the
+// underlying error, if any.
+func NewECSServiceNotFoundError(service string, cause error) *ECSServiceNotFoundError {
+	return &ECSServiceNotFoundError{Service: service, Cause: cause}
+}
+
+// IsECSServiceNotFoundError returns whether or not the error is due to not
+// being able to find the service in ECS.
+func IsECSServiceNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, ErrECSServiceNotFound)
+}
+
+// ECSClusterNotFoundError indicates that the reason for an error in an ECS
+// request is because the cluster with the specified name could not be found.
+type ECSClusterNotFoundError struct {
+	Cluster string
+	// Cause is the underlying error, if any.
+	Cause error
+}
+
+// Error returns the formatted error message including the name of the
+// cluster.
+func (e *ECSClusterNotFoundError) Error() string {
+	return notFoundMessage("cluster", e.Cluster, e.Cause)
+}
+
+// Unwrap returns the underlying cause of the error.
+func (e *ECSClusterNotFoundError) Unwrap() error {
+	return e.Cause
+}
+
+// Is returns whether or not the target is an ECSClusterNotFoundError for the
+// same cluster. The ErrECSClusterNotFound sentinel, which has no cluster,
+// matches any ECSClusterNotFoundError.
+func (e *ECSClusterNotFoundError) Is(target error) bool {
+	t, ok := target.(*ECSClusterNotFoundError)
+	if !ok || t == nil {
+		return false
+	}
+	return t.Cluster == "" || t.Cluster == e.Cluster
+}
+
+// NewECSClusterNotFoundError returns a new error with the given cluster name
+// indicating that the cluster could not be found in ECS. The cause is the
+// underlying error, if any.
+func NewECSClusterNotFoundError(cluster string, cause error) *ECSClusterNotFoundError {
+	return &ECSClusterNotFoundError{Cluster: cluster, Cause: cause}
+}
+
+// IsECSClusterNotFoundError returns whether or not the error is due to not
+// being able to find the cluster in ECS.
+func IsECSClusterNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, ErrECSClusterNotFound)
+}
+
+// SecretNotFoundError indicates that the reason for an error in a Secrets
+// Manager request is because the secret with the specified ID could not be
+// found.
+type SecretNotFoundError struct {
+	ID string
+	// Cause is the underlying error, if any.
+	Cause error
+}
+
+// Error returns the formatted error message including the ID of the secret.
+func (e *SecretNotFoundError) Error() string {
+	return notFoundMessage("secret", e.ID, e.Cause)
+}
+
+// Unwrap returns the underlying cause of the error.
+func (e *SecretNotFoundError) Unwrap() error {
+	return e.Cause
+}
+
+// Is returns whether or not the target is a SecretNotFoundError for the same
+// secret. The ErrSecretNotFound sentinel, which has no ID, matches any
+// SecretNotFoundError.
+func (e *SecretNotFoundError) Is(target error) bool {
+	t, ok := target.(*SecretNotFoundError)
+	if !ok || t == nil {
+		return false
+	}
+	return t.ID == "" || t.ID == e.ID
+}
+
+// NewSecretNotFoundError returns a new error with the given secret ID
+// indicating that the secret could not be found in Secrets Manager. The cause
+// is the underlying error, if any.
+func NewSecretNotFoundError(id string, cause error) *SecretNotFoundError {
+	return &SecretNotFoundError{ID: id, Cause: cause}
+}
+
+// IsSecretNotFoundError returns whether or not the error is due to not being
+// able to find the secret in Secrets Manager.
+func IsSecretNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, ErrSecretNotFound)
 }
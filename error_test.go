@@ -1,17 +1,19 @@
 package cocoa
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/pkg/errors"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestECSTaskNotFoundError(t *testing.T) {
 	assert.Implements(t, (*error)(nil), new(ECSTaskNotFoundError))
 	t.Run("IsECSTaskNotFoundError", func(t *testing.T) {
-		err := NewECSTaskNotFoundError("arn")
+		err := NewECSTaskNotFoundError("arn", nil)
 		assert.Error(t, err)
 		assert.True(t, IsECSTaskNotFoundError(err))
 	})
@@ -20,7 +22,95 @@ func TestECSTaskNotFoundError(t *testing.T) {
 		assert.False(t, IsECSTaskNotFoundError(err))
 	})
 	t.Run("WrappedECSTaskNotFoundError", func(t *testing.T) {
-		err := errors.Wrap(NewECSTaskNotFoundError("arn"), "wrapping message")
+		err := errors.Wrap(NewECSTaskNotFoundError("arn", nil), "wrapping message")
 		assert.True(t, IsECSTaskNotFoundError(err))
 	})
 }
+
+func TestECSTaskNotFoundErrorIs(t *testing.T) {
+	t.Run("MatchesSentinel", func(t *testing.T) {
+		assert.True(t, errors.Is(NewECSTaskNotFoundError("arn", nil), ErrECSTaskNotFound))
+	})
+	t.Run("MatchesWrappedSentinel", func(t *testing.T) {
+		err := errors.Wrap(NewECSTaskNotFoundError("arn", nil), "wrapping message")
+		assert.True(t, errors.Is(err, ErrECSTaskNotFound))
+	})
+	t.Run("MatchesStandardLibraryWrappedSentinel", func(t *testing.T) {
+		err := fmt.Errorf("wrapping message: %w", NewECSTaskNotFoundError("arn", nil))
+		assert.True(t, errors.Is(err, ErrECSTaskNotFound))
+		assert.True(t, IsECSTaskNotFoundError(err))
+	})
+	t.Run("MatchesSameARN", func(t *testing.T) {
+		assert.True(t, errors.Is(NewECSTaskNotFoundError("arn", nil), NewECSTaskNotFoundError("arn", nil)))
+	})
+	t.Run("DoesNotMatchDifferentARN", func(t *testing.T) {
+		assert.False(t, errors.Is(NewECSTaskNotFoundError("arn", nil), NewECSTaskNotFoundError("other", nil)))
+	})
+	t.Run("OtherErrorsDoNotMatchSentinel", func(t *testing.T) {
+		assert.False(t, errors.Is(errors.New("some error"), ErrECSTaskNotFound))
+	})
+}
+
+func TestECSTaskNotFoundErrorAs(t *testing.T) {
+	t.Run("PopulatesTypedError", func(t *testing.T) {
+		err := fmt.Errorf("wrapping message: %w", errors.Wrap(NewECSTaskNotFoundError("arn", nil), "wrapping message"))
+		var typedErr *ECSTaskNotFoundError
+		require.True(t, errors.As(err, &typedErr))
+		assert.Equal(t, "arn", typedErr.ARN)
+	})
+	t.Run("DoesNotPopulateForOtherErrors", func(t *testing.T) {
+		var typedErr *ECSTaskNotFoundError
+		assert.False(t, errors.As(errors.New("some error"), &typedErr))
+		assert.Zero(t, typedErr)
+	})
+}
+
+func TestNotFoundErrorsUnwrap(t *testing.T) {
+	cause := errors.New("cause")
+	for tName, tCase := range map[string]struct {
+		err      error
+		sentinel error
+		is       func(error) bool
+	}{
+		"ECSTaskNotFoundError": {
+			err:      NewECSTaskNotFoundError("arn", cause),
+			sentinel: ErrECSTaskNotFound,
+			is:       IsECSTaskNotFoundError,
+		},
+		"ECSServiceNotFoundError": {
+			err:      NewECSServiceNotFoundError("service", cause),
+			sentinel: ErrECSServiceNotFound,
+			is:       IsECSServiceNotFoundError,
+		},
+		"ECSClusterNotFoundError": {
+			err:      NewECSClusterNotFoundError("cluster", cause),
+			sentinel: ErrECSClusterNotFound,
+			is:       IsECSClusterNotFoundError,
+		},
+		"SecretNotFoundError": {
+			err:      NewSecretNotFoundError("id", cause),
+			sentinel: ErrSecretNotFound,
+			is:       IsSecretNotFoundError,
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			wrapped := fmt.Errorf("wrapping message: %w", tCase.err)
+			assert.True(t, tCase.is(wrapped))
+			assert.True(t, errors.Is(wrapped, tCase.sentinel))
+			assert.True(t, errors.Is(wrapped, cause))
+			assert.Equal(t, cause, errors.Unwrap(tCase.err))
+			assert.Contains(t, tCase.err.Error(), cause.Error())
+			assert.False(t, tCase.is(cause))
+			assert.False(t, tCase.is(nil))
+		})
+	}
+	t.Run("NilCause", func(t *testing.T) {
+		err := NewECSTaskNotFoundError("arn", nil)
+		assert.NoError(t, errors.Unwrap(err))
+		assert.Equal(t, "task 'arn' not found", err.Error())
+	})
+	t.Run("DifferentTypesDoNotMatch", func(t *testing.T) {
+		assert.False(t, errors.Is(NewECSServiceNotFoundError("name", nil), ErrECSClusterNotFound))
+		assert.False(t, errors.Is(NewECSClusterNotFoundError("name", nil), ErrECSServiceNotFound))
+	})
+}
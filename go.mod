@@ -3,10 +3,15 @@ module github.com/evergreen-ci/cocoa
 go 1.16
 
 require (
-	github.com/aws/aws-sdk-go v1.44.127
+	github.com/aws/aws-sdk-go v1.44.146
+	github.com/aws/aws-sdk-go-v2 v1.17.1
+	github.com/aws/aws-sdk-go-v2/service/ecs v1.19.1
+	github.com/aws/aws-sdk-go-v2/service/secretsmanager v1.16.3
+	github.com/aws/smithy-go v1.13.4
 	github.com/evergreen-ci/utility v0.0.0-20220725171106-4730479c6118
 	github.com/mongodb/grip v0.0.0-20220401165023-6a1d9bb90c21
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.8.1
-	golang.org/x/sys v0.0.0-20220811171246-fbc7d0a398ab // indirect
+	golang.org/x/sync v0.1.0
+	gopkg.in/yaml.v3 v3.0.1
 )
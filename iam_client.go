@@ -0,0 +1,18 @@
+package cocoa
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/iam"
+)
+
+// IAMClient provides a common interface to interact with a client backed by
+// AWS Identity and Access Management. Implementations must handle retrying and
+// backoff.
+type IAMClient interface {
+	// GetRole gets information about an IAM role, including its trust policy.
+	GetRole(ctx context.Context, in *iam.GetRoleInput) (*iam.GetRoleOutput, error)
+	// Close closes the client and cleans up its resources. Implementations
+	// should ensure that this is idempotent.
+	Close(ctx context.Context) error
+}
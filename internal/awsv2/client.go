@@ -0,0 +1,190 @@
+//go:build awsv2
+// +build awsv2
+
+package awsv2
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/smithy-go"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+// Client provides the functionality shared by the v2 clients to make API calls
+// using the same client options, retries and logging as the v1 clients.
+type Client struct {
+	awsutil.BaseClient
+	// Config is the v2 SDK configuration to create the service client.
+	Config                  aws.Config
+	isNonRetryableErrorCode func(code string) bool
+}
+
+// NewClient creates a new client from the client options. The error codes for
+// which isNonRetryableErrorCode returns true are not retried.
+func NewClient(opts awsutil.ClientOptions, isNonRetryableErrorCode func(code string) bool) (*Client, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid options")
+	}
+	cfg, err := NewConfig(&opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating config")
+	}
+	return &Client{
+		BaseClient:              awsutil.NewBaseClient(opts),
+		Config:                  cfg,
+		isNonRetryableErrorCode: isNonRetryableErrorCode,
+	}, nil
+}
+
+// NewConfig returns the v2 SDK configuration for the validated client
+// options. The v2 SDK's own retryer is disabled because the client retries
+// requests using the client options' retry options.
+func NewConfig(opts *awsutil.ClientOptions) (aws.Config, error) {
+	creds, err := opts.GetCredentials()
+	if err != nil {
+		return aws.Config{}, errors.Wrap(err, "getting credentials")
+	}
+
+	cfg := aws.Config{
+		Region:      utility.FromStringPtr(opts.Region),
+		Credentials: credentialsProvider{creds: creds},
+		HTTPClient:  opts.HTTPClient,
+		Retryer: func() aws.Retryer {
+			return aws.NopRetryer{}
+		},
+		ConfigSources: []interface{}{endpointOptions{
+			fipsEnabled:      opts.FIPSEndpointsEnabled,
+			dualStackEnabled: opts.DualStackEndpointsEnabled,
+		}},
+	}
+	if endpoint := utility.FromStringPtr(opts.Endpoint); endpoint != "" {
+		cfg.EndpointResolverWithOptions = aws.EndpointResolverWithOptionsFunc(func(service, region string, _ ...interface{}) (aws.Endpoint, error) {
+			return aws.Endpoint{
+				URL:           endpoint,
+				SigningRegion: region,
+			}, nil
+		})
+	}
+
+	return cfg, nil
+}
+
+// credentialsProvider provides v2 SDK credentials from v1 SDK credentials, so
+// that the v2 clients authenticate in the same way as the v1 clients (e.g. by
+// assuming the role).
+type credentialsProvider struct {
+	creds *credentials.Credentials
+}
+
+func (p credentialsProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	val, err := p.creds.GetWithContext(ctx)
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+
+	v2Creds := aws.Credentials{
+		AccessKeyID:     val.AccessKeyID,
+		SecretAccessKey: val.SecretAccessKey,
+		SessionToken:    val.SessionToken,
+		Source:          val.ProviderName,
+	}
+	if expiresAt, err := p.creds.ExpiresAt(); err == nil && !expiresAt.IsZero() {
+		v2Creds.CanExpire = true
+		v2Creds.Expires = expiresAt
+	}
+
+	return v2Creds, nil
+}
+
+// endpointOptions is a v2 SDK configuration source for whether the service
+// clients should use FIPS and dual-stack endpoints.
+type endpointOptions struct {
+	fipsEnabled      bool
+	dualStackEnabled bool
+}
+
+func (o endpointOptions) GetUseFIPSEndpoint(context.Context) (aws.FIPSEndpointState, bool, error) {
+	if o.fipsEnabled {
+		return aws.FIPSEndpointStateEnabled, true, nil
+	}
+	return aws.FIPSEndpointStateDisabled, true, nil
+}
+
+func (o endpointOptions) GetUseDualStackEndpoint(context.Context) (aws.DualStackEndpointState, bool, error) {
+	if o.dualStackEnabled {
+		return aws.DualStackEndpointStateEnabled, true, nil
+	}
+	return aws.DualStackEndpointStateDisabled, true, nil
+}
+
+// Call makes an API call using the v2 SDK on behalf of a v1 client method. It
+// converts the v1 input into the v2 input, calls the API with retries, and
+// converts the v2 output into the v1 output. The call must use the v2 input
+// and return the v2 output.
+func (c *Client) Call(ctx context.Context, op string, in, v2In, out interface{}, call func() (interface{}, error)) error {
+	if err := Convert(in, v2In); err != nil {
+		return errors.Wrap(err, "converting input")
+	}
+
+	var v2Out interface{}
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage(op, in, awsutil.RequestIDFrom(ctx))
+		var err error
+		v2Out, err = call()
+		err = ConvertError(err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage(op, stats)))
+		return err
+	}
+
+	return errors.Wrap(Convert(v2Out, out), "converting output")
+}
+
+// Convert converts between equivalent v1 and v2 SDK types. The v1 and v2 types
+// are generated from the same API models, so they have the same field names
+// and can be converted through their JSON representation. Unset fields that
+// are not pointers in the v2 types are converted to their zero values.
+func Convert(from, to interface{}) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling %T", from)
+	}
+	return errors.Wrapf(json.Unmarshal(b, to), "unmarshalling into %T", to)
+}
+
+// ConvertError converts an API error returned by the v2 SDK into the
+// equivalent v1 SDK error, so that existing checks of the error code (e.g.
+// cocoa.IsECSTaskNotFoundError) work with the v2 clients. Other errors are
+// returned as-is.
+func ConvertError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	awsErr := awserr.New(apiErr.ErrorCode(), apiErr.ErrorMessage(), err)
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		return awserr.NewRequestFailure(awsErr, respErr.HTTPStatusCode(), respErr.ServiceRequestID())
+	}
+	return awsErr
+}
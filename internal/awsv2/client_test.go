@@ -0,0 +1,165 @@
+//go:build awsv2
+// +build awsv2
+
+package awsv2
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	ecsv2 "github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	awsV1 "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	t.Run("ConvertsV1InputToV2Input", func(t *testing.T) {
+		in := &ecs.RunTaskInput{
+			Cluster:        awsV1.String("cluster"),
+			TaskDefinition: awsV1.String("family:1"),
+			Count:          awsV1.Int64(2),
+			LaunchType:     awsV1.String(ecs.LaunchTypeFargate),
+			Overrides: &ecs.TaskOverride{
+				ContainerOverrides: []*ecs.ContainerOverride{{
+					Name:        awsV1.String("container"),
+					Environment: []*ecs.KeyValuePair{{Name: awsV1.String("key"), Value: awsV1.String("value")}},
+				}},
+			},
+		}
+		var v2In ecsv2.RunTaskInput
+		require.NoError(t, Convert(in, &v2In))
+		assert.Equal(t, "cluster", aws.ToString(v2In.Cluster))
+		assert.Equal(t, "family:1", aws.ToString(v2In.TaskDefinition))
+		assert.EqualValues(t, 2, aws.ToInt32(v2In.Count))
+		assert.Equal(t, types.LaunchTypeFargate, v2In.LaunchType)
+		require.NotNil(t, v2In.Overrides)
+		require.Len(t, v2In.Overrides.ContainerOverrides, 1)
+		require.Len(t, v2In.Overrides.ContainerOverrides[0].Environment, 1)
+		assert.Equal(t, "value", aws.ToString(v2In.Overrides.ContainerOverrides[0].Environment[0].Value))
+	})
+	t.Run("ConvertsV2OutputToV1Output", func(t *testing.T) {
+		createdAt := time.Now().UTC().Truncate(time.Second)
+		v2Out := &ecsv2.DescribeTasksOutput{
+			Tasks: []types.Task{{
+				TaskArn:    aws.String("task_arn"),
+				LastStatus: aws.String(ecs.DesiredStatusRunning),
+				CreatedAt:  aws.Time(createdAt),
+				Containers: []types.Container{{Name: aws.String("container"), ExitCode: aws.Int32(1)}},
+			}},
+			Failures: []types.Failure{{Arn: aws.String("missing_arn"), Reason: aws.String("MISSING")}},
+		}
+		var out ecs.DescribeTasksOutput
+		require.NoError(t, Convert(v2Out, &out))
+		require.Len(t, out.Tasks, 1)
+		assert.Equal(t, "task_arn", awsV1.StringValue(out.Tasks[0].TaskArn))
+		assert.Equal(t, ecs.DesiredStatusRunning, awsV1.StringValue(out.Tasks[0].LastStatus))
+		assert.True(t, createdAt.Equal(awsV1.TimeValue(out.Tasks[0].CreatedAt)))
+		require.Len(t, out.Tasks[0].Containers, 1)
+		assert.EqualValues(t, 1, awsV1.Int64Value(out.Tasks[0].Containers[0].ExitCode))
+		require.Len(t, out.Failures, 1)
+		assert.Equal(t, "MISSING", awsV1.StringValue(out.Failures[0].Reason))
+	})
+}
+
+func TestConvertError(t *testing.T) {
+	t.Run("ReturnsNilForNoError", func(t *testing.T) {
+		assert.NoError(t, ConvertError(nil))
+	})
+	t.Run("ReturnsNonAPIErrorAsIs", func(t *testing.T) {
+		err := errors.New("connection refused")
+		assert.Equal(t, err, ConvertError(err))
+	})
+	t.Run("ConvertsAPIErrorToAWSError", func(t *testing.T) {
+		apiErr := &smithy.GenericAPIError{Code: ecs.ErrCodeInvalidParameterException, Message: "invalid"}
+		err := ConvertError(&smithy.OperationError{ServiceID: "ECS", OperationName: "RunTask", Err: apiErr})
+		awsErr, ok := err.(awserr.Error)
+		require.True(t, ok)
+		assert.Equal(t, ecs.ErrCodeInvalidParameterException, awsErr.Code())
+		assert.Equal(t, "invalid", awsErr.Message())
+	})
+	t.Run("IncludesResponseInformation", func(t *testing.T) {
+		apiErr := &smithy.GenericAPIError{Code: ecs.ErrCodeClusterNotFoundException, Message: "not found"}
+		respErr := &awshttp.ResponseError{
+			ResponseError: &smithyhttp.ResponseError{
+				Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusBadRequest}},
+				Err:      apiErr,
+			},
+			RequestID: "request_id",
+		}
+		err := ConvertError(&smithy.OperationError{ServiceID: "ECS", OperationName: "RunTask", Err: respErr})
+		reqErr, ok := err.(awserr.RequestFailure)
+		require.True(t, ok)
+		assert.Equal(t, ecs.ErrCodeClusterNotFoundException, reqErr.Code())
+		assert.Equal(t, http.StatusBadRequest, reqErr.StatusCode())
+		assert.Equal(t, "request_id", reqErr.RequestID())
+	})
+}
+
+func TestNewConfig(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	validOpts := func() *awsutil.ClientOptions {
+		opts := awsutil.NewClientOptions().
+			SetCredentials(credentials.NewStaticCredentials("id", "secret", "token")).
+			SetRegion("us-east-1")
+		require.NoError(t, opts.Validate())
+		return opts
+	}
+
+	t.Run("UsesClientOptions", func(t *testing.T) {
+		opts := validOpts()
+		cfg, err := NewConfig(opts)
+		require.NoError(t, err)
+		assert.Equal(t, "us-east-1", cfg.Region)
+		assert.Equal(t, opts.HTTPClient, cfg.HTTPClient)
+		assert.Nil(t, cfg.EndpointResolverWithOptions)
+		_, ok := cfg.Retryer().(aws.NopRetryer)
+		assert.True(t, ok, "v2 SDK retries should be disabled")
+
+		creds, err := cfg.Credentials.Retrieve(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "id", creds.AccessKeyID)
+		assert.Equal(t, "secret", creds.SecretAccessKey)
+		assert.Equal(t, "token", creds.SessionToken)
+		assert.False(t, creds.CanExpire)
+	})
+	t.Run("UsesEndpoint", func(t *testing.T) {
+		opts := validOpts().SetEndpoint("http://localhost:4566")
+		cfg, err := NewConfig(opts)
+		require.NoError(t, err)
+		require.NotNil(t, cfg.EndpointResolverWithOptions)
+		endpoint, err := cfg.EndpointResolverWithOptions.ResolveEndpoint("ECS", "us-east-1")
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:4566", endpoint.URL)
+	})
+	t.Run("UsesFIPSAndDualStackEndpoints", func(t *testing.T) {
+		opts := validOpts().SetFIPSEndpoints(true).SetDualStackEndpoints(true)
+		cfg, err := NewConfig(opts)
+		require.NoError(t, err)
+		require.Len(t, cfg.ConfigSources, 1)
+		src, ok := cfg.ConfigSources[0].(endpointOptions)
+		require.True(t, ok)
+		fips, found, err := src.GetUseFIPSEndpoint(ctx)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, aws.FIPSEndpointStateEnabled, fips)
+		dualStack, found, err := src.GetUseDualStackEndpoint(ctx)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, aws.DualStackEndpointStateEnabled, dualStack)
+	})
+}
@@ -0,0 +1,9 @@
+//go:build awsv2
+// +build awsv2
+
+/*
+Package awsv2 provides the shared functionality to implement the cocoa client
+interfaces using the AWS SDK for Go v2. It is only compiled with the awsv2
+build tag.
+*/
+package awsv2
@@ -78,6 +78,35 @@ func ECSClientTests() map[string]ECSClientTestCase {
 			assert.Error(t, err)
 			assert.Zero(t, out)
 		},
+		"RunTaskFailsWithNonexistentCluster": func(ctx context.Context, t *testing.T, c cocoa.ECSClient) {
+			registerOut := testutil.RegisterTaskDefinition(ctx, t, c, testutil.ValidRegisterTaskDefinitionInput(t))
+			defer cleanupTaskDefinition(ctx, t, c, &registerOut)
+
+			out, err := c.RunTask(ctx, &awsECS.RunTaskInput{
+				Cluster:        aws.String(utility.RandomString()),
+				TaskDefinition: registerOut.TaskDefinition.TaskDefinitionArn,
+			})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+		},
+		"RunTaskReturnsFailureWithInsufficientResources": func(ctx context.Context, t *testing.T, c cocoa.ECSClient) {
+			in := testutil.ValidRegisterTaskDefinitionInput(t)
+			in.Memory = aws.String("1000000")
+			registerOut := testutil.RegisterTaskDefinition(ctx, t, c, in)
+			defer cleanupTaskDefinition(ctx, t, c, &registerOut)
+
+			out, err := c.RunTask(ctx, &awsECS.RunTaskInput{
+				Cluster:        aws.String(testutil.ECSClusterName()),
+				LaunchType:     aws.String(awsECS.LaunchTypeEc2),
+				TaskDefinition: registerOut.TaskDefinition.TaskDefinitionArn,
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			defer cleanupTask(ctx, t, c, out)
+			assert.Empty(t, out.Tasks, "task should not be placed without enough resources")
+			require.NotEmpty(t, out.Failures)
+			assert.NotZero(t, utility.FromStringPtr(out.Failures[0].Reason))
+		},
 		"StopTaskFailsWithInvalidInput": func(ctx context.Context, t *testing.T, c cocoa.ECSClient) {
 			out, err := c.StopTask(ctx, &awsECS.StopTaskInput{})
 			assert.Error(t, err)
@@ -92,11 +121,27 @@ func ECSClientTests() map[string]ECSClientTestCase {
 			assert.True(t, cocoa.IsECSTaskNotFoundError(err))
 			assert.Zero(t, out)
 		},
+		"StopTaskFailsWithNonexistentCluster": func(ctx context.Context, t *testing.T, c cocoa.ECSClient) {
+			out, err := c.StopTask(ctx, &awsECS.StopTaskInput{
+				Cluster: aws.String(utility.RandomString()),
+				Task:    aws.String(utility.RandomString()),
+			})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+		},
 		"DescribeTasksFailsWithInvalidInput": func(ctx context.Context, t *testing.T, c cocoa.ECSClient) {
 			out, err := c.DescribeTasks(ctx, &awsECS.DescribeTasksInput{})
 			assert.Error(t, err)
 			assert.Zero(t, out)
 		},
+		"DescribeTasksFailsWithNonexistentCluster": func(ctx context.Context, t *testing.T, c cocoa.ECSClient) {
+			out, err := c.DescribeTasks(ctx, &awsECS.DescribeTasksInput{
+				Cluster: aws.String(utility.RandomString()),
+				Tasks:   []*string{aws.String(utility.RandomString())},
+			})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+		},
 		"DescribeTaskDefinitionFailsWithInvalidInput": func(ctx context.Context, t *testing.T, c cocoa.ECSClient) {
 			out, err := c.DescribeTaskDefinition(ctx, &awsECS.DescribeTaskDefinitionInput{})
 			assert.Error(t, err)
@@ -109,6 +154,34 @@ func ECSClientTests() map[string]ECSClientTestCase {
 			assert.Error(t, err)
 			assert.Zero(t, out)
 		},
+		"ListTaskDefinitionsSucceedsWithPagination": func(ctx context.Context, t *testing.T, c cocoa.ECSClient) {
+			in := testutil.ValidRegisterTaskDefinitionInput(t)
+			var registeredARNs []string
+			for i := 0; i < 3; i++ {
+				registerOut := testutil.RegisterTaskDefinition(ctx, t, c, in)
+				defer cleanupTaskDefinition(ctx, t, c, &registerOut)
+				registeredARNs = append(registeredARNs, utility.FromStringPtr(registerOut.TaskDefinition.TaskDefinitionArn))
+			}
+
+			listIn := &awsECS.ListTaskDefinitionsInput{
+				FamilyPrefix: in.Family,
+				Status:       aws.String(awsECS.TaskDefinitionStatusActive),
+				MaxResults:   aws.Int64(1),
+			}
+			var listedARNs []string
+			for i := 0; i < len(registeredARNs)+1; i++ {
+				out, err := c.ListTaskDefinitions(ctx, listIn)
+				require.NoError(t, err)
+				require.NotZero(t, out)
+				assert.LessOrEqual(t, len(out.TaskDefinitionArns), 1, "page should not exceed the max results")
+				listedARNs = append(listedARNs, utility.FromStringPtrSlice(out.TaskDefinitionArns)...)
+				if out.NextToken == nil {
+					break
+				}
+				listIn.NextToken = out.NextToken
+			}
+			assert.Equal(t, registeredARNs, listedARNs, "paginated results should include every revision in order")
+		},
 		"ListTasksSucceedsWithNoResultWithZeroInput": func(ctx context.Context, t *testing.T, c cocoa.ECSClient) {
 			out, err := c.ListTasks(ctx, &awsECS.ListTasksInput{})
 			assert.NoError(t, err)
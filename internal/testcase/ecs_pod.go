@@ -9,6 +9,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/awsutil"
 	"github.com/evergreen-ci/cocoa/internal/testutil"
 	"github.com/evergreen-ci/utility"
 	"github.com/stretchr/testify/assert"
@@ -433,7 +434,7 @@ func checkPodDeleted(ctx context.Context, t *testing.T, c cocoa.ECSClient, v coc
 						return false, nil
 					}
 					return true, nil
-				}, utility.RetryOptions{MaxAttempts: 5}))
+				}, awsutil.NewRetryOptions().WithMaxAttempts(5).Build()))
 
 				assert.True(t, isDeleted)
 			} else {
@@ -2,11 +2,13 @@ package testcase
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
 	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/awsutil"
 	"github.com/evergreen-ci/cocoa/internal/testutil"
 	"github.com/evergreen-ci/utility"
 	"github.com/stretchr/testify/assert"
@@ -66,7 +68,7 @@ func SecretsManagerClientTests() map[string]SecretsManagerClientTestCase {
 			out, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
 				SecretId: aws.String(testutil.NewSecretName(t)),
 			})
-			assert.Error(t, err)
+			assert.True(t, cocoa.IsSecretNotFoundError(err))
 			assert.Zero(t, out)
 		},
 		"UpdateSecretValueSucceedsWithExistingSecret": func(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClient) {
@@ -107,7 +109,7 @@ func SecretsManagerClientTests() map[string]SecretsManagerClientTestCase {
 				SecretId:     aws.String(testutil.NewSecretName(t)),
 				SecretString: aws.String("hello"),
 			})
-			assert.Error(t, err)
+			assert.True(t, cocoa.IsSecretNotFoundError(err))
 			assert.Zero(t, out)
 		},
 		"DescribeSecretSucceeds": func(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClient) {
@@ -234,6 +236,205 @@ func SecretsManagerClientTests() map[string]SecretsManagerClientTestCase {
 			assert.Equal(t, utility.FromStringPtr(describeOut.Tags[0].Key), utility.FromStringPtr(tags[0].Key))
 			assert.Equal(t, utility.FromStringPtr(describeOut.Tags[0].Value), utility.FromStringPtr(tags[0].Value))
 		},
+		"ListSecretsSucceedsWithTagFilters": func(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClient) {
+			tags := map[string]string{"cocoa-test-tag": utility.RandomString()}
+			createOut := testutil.CreateSecret(ctx, t, c, secretsmanager.CreateSecretInput{
+				Name:         aws.String(testutil.NewSecretName(t)),
+				SecretString: aws.String(utility.RandomString()),
+				Tags: []*secretsmanager.Tag{
+					{
+						Key:   aws.String("cocoa-test-tag"),
+						Value: aws.String(tags["cocoa-test-tag"]),
+					},
+				},
+			})
+			defer cleanupSecret(ctx, t, c, &createOut)
+
+			untaggedOut := testutil.CreateSecret(ctx, t, c, secretsmanager.CreateSecretInput{
+				Name:         aws.String(testutil.NewSecretName(t)),
+				SecretString: aws.String(utility.RandomString()),
+			})
+			defer cleanupSecret(ctx, t, c, &untaggedOut)
+
+			out, err := c.ListSecrets(ctx, &secretsmanager.ListSecretsInput{
+				Filters: awsutil.BuildTagFilters(tags),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			require.Len(t, out.SecretList, 1)
+			assert.Equal(t, utility.FromStringPtr(createOut.ARN), utility.FromStringPtr(out.SecretList[0].ARN))
+		},
+		"GetSecretValueSucceedsConcurrentlyForMultipleSecrets": func(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClient) {
+			const numSecrets = 5
+			// Map each secret ARN to its value.
+			values := map[string]string{}
+			for i := 0; i < numSecrets; i++ {
+				value := utility.RandomString()
+				createOut := testutil.CreateSecret(ctx, t, c, secretsmanager.CreateSecretInput{
+					Name:         aws.String(testutil.NewSecretName(t)),
+					SecretString: aws.String(value),
+				})
+				defer cleanupSecret(ctx, t, c, &createOut)
+				values[utility.FromStringPtr(createOut.ARN)] = value
+			}
+
+			type result struct {
+				arn   string
+				value string
+				err   error
+			}
+			results := make(chan result, numSecrets)
+			var wg sync.WaitGroup
+			for arn := range values {
+				wg.Add(1)
+				go func(arn string) {
+					defer wg.Done()
+					out, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(arn)})
+					if err != nil {
+						results <- result{arn: arn, err: err}
+						return
+					}
+					results <- result{arn: arn, value: utility.FromStringPtr(out.SecretString)}
+				}(arn)
+			}
+			wg.Wait()
+			close(results)
+
+			var numResults int
+			for res := range results {
+				numResults++
+				require.NoError(t, res.err)
+				assert.Equal(t, values[res.arn], res.value)
+			}
+			assert.Equal(t, numSecrets, numResults)
+		},
+		"ListSecretsReturnsOnlySecretsMatchingAllTagFilters": func(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClient) {
+			key := "cocoa-test-tag"
+			value := utility.RandomString()
+			matchingOut := testutil.CreateSecret(ctx, t, c, secretsmanager.CreateSecretInput{
+				Name:         aws.String(testutil.NewSecretName(t)),
+				SecretString: aws.String(utility.RandomString()),
+				Tags:         []*secretsmanager.Tag{{Key: aws.String(key), Value: aws.String(value)}},
+			})
+			defer cleanupSecret(ctx, t, c, &matchingOut)
+
+			otherValueOut := testutil.CreateSecret(ctx, t, c, secretsmanager.CreateSecretInput{
+				Name:         aws.String(testutil.NewSecretName(t)),
+				SecretString: aws.String(utility.RandomString()),
+				Tags:         []*secretsmanager.Tag{{Key: aws.String(key), Value: aws.String(utility.RandomString())}},
+			})
+			defer cleanupSecret(ctx, t, c, &otherValueOut)
+
+			out, err := c.ListSecrets(ctx, &secretsmanager.ListSecretsInput{
+				Filters: awsutil.BuildTagFilters(map[string]string{key: value}),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			require.Len(t, out.SecretList, 1)
+			assert.Equal(t, utility.FromStringPtr(matchingOut.ARN), utility.FromStringPtr(out.SecretList[0].ARN))
+
+			out, err = c.ListSecrets(ctx, &secretsmanager.ListSecretsInput{
+				Filters: awsutil.BuildTagFilters(map[string]string{key: utility.RandomString()}),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			assert.Empty(t, out.SecretList)
+		},
+		"ListSecretsWithTagFiltersCanBeNarrowedToExactTags": func(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClient) {
+			first := utility.RandomString()
+			second := utility.RandomString()
+			tags := map[string]string{"cocoa-test-tag-a": first, "cocoa-test-tag-b": second}
+			exactOut := testutil.CreateSecret(ctx, t, c, secretsmanager.CreateSecretInput{
+				Name:         aws.String(testutil.NewSecretName(t)),
+				SecretString: aws.String(utility.RandomString()),
+				Tags: []*secretsmanager.Tag{
+					{Key: aws.String("cocoa-test-tag-a"), Value: aws.String(first)},
+					{Key: aws.String("cocoa-test-tag-b"), Value: aws.String(second)},
+				},
+			})
+			defer cleanupSecret(ctx, t, c, &exactOut)
+
+			swappedOut := testutil.CreateSecret(ctx, t, c, secretsmanager.CreateSecretInput{
+				Name:         aws.String(testutil.NewSecretName(t)),
+				SecretString: aws.String(utility.RandomString()),
+				Tags: []*secretsmanager.Tag{
+					{Key: aws.String("cocoa-test-tag-a"), Value: aws.String(second)},
+					{Key: aws.String("cocoa-test-tag-b"), Value: aws.String(first)},
+				},
+			})
+			defer cleanupSecret(ctx, t, c, &swappedOut)
+
+			out, err := c.ListSecrets(ctx, &secretsmanager.ListSecretsInput{
+				Filters: awsutil.BuildTagFilters(tags),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+
+			filtered := awsutil.FilterSecretsByTags(out.SecretList, tags)
+			require.Len(t, filtered, 1)
+			assert.Equal(t, utility.FromStringPtr(exactOut.ARN), utility.FromStringPtr(filtered[0].ARN))
+		},
+		"RestoreSecretSucceedsAfterDeletion": func(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClient) {
+			createOut := testutil.CreateSecret(ctx, t, c, secretsmanager.CreateSecretInput{
+				Name:         aws.String(testutil.NewSecretName(t)),
+				SecretString: aws.String("value"),
+			})
+			defer cleanupSecret(ctx, t, c, &createOut)
+
+			_, err := c.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{
+				SecretId:             createOut.ARN,
+				RecoveryWindowInDays: aws.Int64(7),
+			})
+			require.NoError(t, err)
+
+			_, err = c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: createOut.ARN})
+			assert.Error(t, err, "secret scheduled for deletion should not be accessible")
+
+			restoreOut, err := c.RestoreSecret(ctx, &secretsmanager.RestoreSecretInput{SecretId: createOut.ARN})
+			require.NoError(t, err)
+			require.NotZero(t, restoreOut)
+			assert.Equal(t, utility.FromStringPtr(createOut.ARN), utility.FromStringPtr(restoreOut.ARN))
+
+			getOut, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: createOut.ARN})
+			require.NoError(t, err)
+			require.NotZero(t, getOut)
+			assert.Equal(t, "value", utility.FromStringPtr(getOut.SecretString))
+		},
+		"RestoreSecretFailsWithNonexistentSecret": func(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClient) {
+			out, err := c.RestoreSecret(ctx, &secretsmanager.RestoreSecretInput{
+				SecretId: aws.String(testutil.NewSecretName(t)),
+			})
+			assert.True(t, cocoa.IsSecretNotFoundError(err))
+			assert.Zero(t, out)
+		},
+		"UpdateSecretValueCreatesNewVersion": func(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClient) {
+			createOut := testutil.CreateSecret(ctx, t, c, secretsmanager.CreateSecretInput{
+				Name:         aws.String(testutil.NewSecretName(t)),
+				SecretString: aws.String("foo"),
+			})
+			defer cleanupSecret(ctx, t, c, &createOut)
+
+			originalOut, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: createOut.ARN})
+			require.NoError(t, err)
+			require.NotZero(t, originalOut.VersionId)
+
+			_, err = c.UpdateSecretValue(ctx, &secretsmanager.UpdateSecretInput{
+				SecretId:     createOut.ARN,
+				SecretString: aws.String("bar"),
+			})
+			require.NoError(t, err)
+
+			updatedOut, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: createOut.ARN})
+			require.NoError(t, err)
+			require.NotZero(t, updatedOut.VersionId)
+			assert.NotEqual(t, utility.FromStringPtr(originalOut.VersionId), utility.FromStringPtr(updatedOut.VersionId))
+
+			describeOut, err := c.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: createOut.ARN})
+			require.NoError(t, err)
+			require.NotZero(t, describeOut)
+			assert.Equal(t, []string{"AWSCURRENT"}, utility.FromStringPtrSlice(describeOut.VersionIdsToStages[utility.FromStringPtr(updatedOut.VersionId)]))
+			assert.Equal(t, []string{"AWSPREVIOUS"}, utility.FromStringPtrSlice(describeOut.VersionIdsToStages[utility.FromStringPtr(originalOut.VersionId)]))
+		},
 		"TagResourceFailsWithZeroInput": func(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClient) {
 			_, err := c.TagResource(ctx, &secretsmanager.TagResourceInput{})
 			assert.Error(t, err)
@@ -1,12 +1,16 @@
 package testutil
 
 import (
+	"io/ioutil"
 	"net/http"
 	"os"
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/evergreen-ci/cocoa/awsutil"
 	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 )
 
 // runtimeNamespace is a random string generated during testing runtime that
@@ -29,6 +33,16 @@ func AWSRole() string {
 	return os.Getenv("AWS_ROLE")
 }
 
+// KMSKeyID returns the KMS key ID from the environment variable.
+func KMSKeyID() string {
+	return os.Getenv("AWS_KMS_KEY_ID")
+}
+
+// ECRRepository returns the ECR repository name from the environment variable.
+func ECRRepository() string {
+	return os.Getenv("AWS_ECR_REPOSITORY")
+}
+
 // ValidIntegrationAWSOptions returns valid options to create an AWS client that
 // can make actual requests to AWS for integration testing.
 func ValidIntegrationAWSOptions(hc *http.Client) awsutil.ClientOptions {
@@ -46,3 +60,46 @@ func ValidNonIntegrationAWSOptions() awsutil.ClientOptions {
 		SetCredentials(credentials.NewEnvCredentials()).
 		SetRegion("us-east-1")
 }
+
+// AWSConfigFile represents the AWS configuration for testing, such as against
+// a local AWS emulator like LocalStack, that is loaded from a file rather than
+// from environment variables.
+type AWSConfigFile struct {
+	Region          string `yaml:"region"`
+	Endpoint        string `yaml:"endpoint"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+}
+
+// LoadAWSConfigFromFile returns options to create an AWS client from the YAML or
+// JSON config file at the given path. The config file must specify the region
+// and credentials, and may optionally specify an endpoint to send requests to.
+func LoadAWSConfigFromFile(configPath string) (awsutil.ClientOptions, error) {
+	b, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return awsutil.ClientOptions{}, errors.Wrapf(err, "reading config file '%s'", configPath)
+	}
+
+	// YAML is a superset of JSON, so this can parse either format.
+	var conf AWSConfigFile
+	if err := yaml.Unmarshal(b, &conf); err != nil {
+		return awsutil.ClientOptions{}, errors.Wrapf(err, "unmarshalling config file '%s'", configPath)
+	}
+
+	catcher := grip.NewBasicCatcher()
+	catcher.NewWhen(conf.Region == "", "must specify a region")
+	catcher.NewWhen(conf.AccessKeyID == "", "must specify an access key ID")
+	catcher.NewWhen(conf.SecretAccessKey == "", "must specify a secret access key")
+	if catcher.HasErrors() {
+		return awsutil.ClientOptions{}, errors.Wrapf(catcher.Resolve(), "invalid config file '%s'", configPath)
+	}
+
+	opts := awsutil.NewClientOptions().
+		SetCredentials(credentials.NewStaticCredentials(conf.AccessKeyID, conf.SecretAccessKey, "")).
+		SetRegion(conf.Region)
+	if conf.Endpoint != "" {
+		opts.SetEndpoint(conf.Endpoint)
+	}
+
+	return *opts, nil
+}
@@ -0,0 +1,67 @@
+package testutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAWSConfigFromFile(t *testing.T) {
+	writeConfig := func(t *testing.T, name, contents string) string {
+		dir, err := ioutil.TempDir("", "cocoa")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, os.RemoveAll(dir))
+		})
+		configPath := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(configPath, []byte(contents), 0600))
+		return configPath
+	}
+
+	t.Run("SucceedsWithYAML", func(t *testing.T) {
+		configPath := writeConfig(t, "config.yml", `
+region: us-west-2
+endpoint: http://localhost:4566
+access_key_id: access_key_id
+secret_access_key: secret_access_key
+`)
+		opts, err := LoadAWSConfigFromFile(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, "us-west-2", utility.FromStringPtr(opts.Region))
+		assert.Equal(t, "http://localhost:4566", utility.FromStringPtr(opts.Endpoint))
+		require.NotZero(t, opts.Creds)
+		creds, err := opts.Creds.Get()
+		require.NoError(t, err)
+		assert.Equal(t, "access_key_id", creds.AccessKeyID)
+		assert.Equal(t, "secret_access_key", creds.SecretAccessKey)
+	})
+	t.Run("SucceedsWithJSON", func(t *testing.T) {
+		configPath := writeConfig(t, "config.json", `{"region": "us-east-1", "access_key_id": "access_key_id", "secret_access_key": "secret_access_key"}`)
+		opts, err := LoadAWSConfigFromFile(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, "us-east-1", utility.FromStringPtr(opts.Region))
+		assert.Zero(t, opts.Endpoint)
+	})
+	t.Run("FailsWithMissingFields", func(t *testing.T) {
+		configPath := writeConfig(t, "config.yml", "endpoint: http://localhost:4566\n")
+		_, err := LoadAWSConfigFromFile(configPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must specify a region")
+		assert.Contains(t, err.Error(), "must specify an access key ID")
+		assert.Contains(t, err.Error(), "must specify a secret access key")
+	})
+	t.Run("FailsWithInvalidFile", func(t *testing.T) {
+		configPath := writeConfig(t, "config.yml", "region: [")
+		_, err := LoadAWSConfigFromFile(configPath)
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithNonexistentFile", func(t *testing.T) {
+		_, err := LoadAWSConfigFromFile(filepath.Join(os.TempDir(), utility.RandomString()))
+		assert.Error(t, err)
+	})
+}
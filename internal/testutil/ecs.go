@@ -114,7 +114,8 @@ func CleanupTasks(ctx context.Context, t *testing.T, c cocoa.ECSClient) {
 // on the results from the pagination token.
 func cleanupTasksWithToken(ctx context.Context, t *testing.T, c cocoa.ECSClient, token *string) (nextToken *string) {
 	out, err := c.ListTasks(ctx, &ecs.ListTasksInput{
-		Cluster: aws.String(ECSClusterName()),
+		Cluster:   aws.String(ECSClusterName()),
+		NextToken: token,
 	})
 	if !assert.NoError(t, err) {
 		return nil
@@ -123,7 +124,7 @@ func cleanupTasksWithToken(ctx context.Context, t *testing.T, c cocoa.ECSClient,
 		return nil
 	}
 	if len(out.TaskArns) == 0 {
-		return nil
+		return out.NextToken
 	}
 
 	describeOut, err := c.DescribeTasks(ctx, &ecs.DescribeTasksInput{
@@ -133,7 +134,7 @@ func cleanupTasksWithToken(ctx context.Context, t *testing.T, c cocoa.ECSClient,
 	if !assert.NoError(t, err) {
 		return nil
 	}
-	if !assert.NotZero(t, out) {
+	if !assert.NotZero(t, describeOut) {
 		return nil
 	}
 
@@ -191,6 +192,46 @@ func ValidRegisterTaskDefinitionInput(t *testing.T) ecs.RegisterTaskDefinitionIn
 	}
 }
 
+// GenerateTaskDefinitionFixture returns a minimal valid input to register a
+// Fargate task definition with a single container running the image. The task
+// uses 256 CPU units and 512 MiB of memory, and the container sends its logs
+// to CloudWatch Logs in the test region (or us-east-1 if it is not set) using
+// the awslogs log driver. The task uses the test execution role, which Fargate
+// requires to send logs to CloudWatch Logs. Fields that ECS fills in with
+// defaults, such as whether the container is essential, are left unset.
+func GenerateTaskDefinitionFixture(family, image string) *ecs.RegisterTaskDefinitionInput {
+	region := AWSRegion()
+	if region == "" {
+		region = "us-east-1"
+	}
+	in := &ecs.RegisterTaskDefinitionInput{
+		Family:                  aws.String(family),
+		RequiresCompatibilities: []*string{aws.String(ecs.CompatibilityFargate)},
+		NetworkMode:             aws.String(ecs.NetworkModeAwsvpc),
+		Cpu:                     aws.String("256"),
+		Memory:                  aws.String("512"),
+		ContainerDefinitions: []*ecs.ContainerDefinition{
+			{
+				Name:  aws.String("container"),
+				Image: aws.String(image),
+				LogConfiguration: &ecs.LogConfiguration{
+					LogDriver: aws.String(ecs.LogDriverAwslogs),
+					Options: map[string]*string{
+						"awslogs-group":         aws.String(fmt.Sprintf("/ecs/%s", family)),
+						"awslogs-region":        aws.String(region),
+						"awslogs-stream-prefix": aws.String("ecs"),
+						"awslogs-create-group":  aws.String("true"),
+					},
+				},
+			},
+		},
+	}
+	if role := ECSExecutionRole(); role != "" {
+		in.SetExecutionRoleArn(role)
+	}
+	return in
+}
+
 // RegisterTaskDefinition is a convenience function for registering an ECS task
 // definition and verifying that the result is successful and populates the task
 // definition ARN.
@@ -202,3 +243,27 @@ func RegisterTaskDefinition(ctx context.Context, t *testing.T, c cocoa.ECSClient
 	require.NotZero(t, out.TaskDefinition.TaskDefinitionArn)
 	return *out
 }
+
+// CreateAndCleanupTaskDefinition registers the task definition and registers a
+// cleanup function with the test to deregister it once the test completes. It
+// returns the task definition ARN.
+func CreateAndCleanupTaskDefinition(ctx context.Context, t *testing.T, c cocoa.ECSClient, in *ecs.RegisterTaskDefinitionInput) (arn string) {
+	require.NotZero(t, in, "must specify a task definition to register")
+
+	out := RegisterTaskDefinition(ctx, t, c, *in)
+	arn = utility.FromStringPtr(out.TaskDefinition.TaskDefinitionArn)
+
+	t.Cleanup(func() {
+		// The test's context may already be done by the time cleanup runs, so
+		// cleanup uses its own context.
+		ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+
+		_, err := c.DeregisterTaskDefinition(ctx, &ecs.DeregisterTaskDefinitionInput{
+			TaskDefinition: aws.String(arn),
+		})
+		assert.NoError(t, err, "cleaning up task definition '%s'", arn)
+	})
+
+	return arn
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -20,9 +21,10 @@ func CheckAWSEnvVars(t *testing.T) {
 }
 
 // CheckAWSEnvVarsForECS checks that the required environment variables are
-// defined for testing against AWS ECS.
+// defined for testing against AWS ECS. If they are not defined, the test is
+// skipped.
 func CheckAWSEnvVarsForECS(t *testing.T) {
-	CheckEnvVars(t,
+	CheckEnvVarsOrSkip(t,
 		"AWS_ACCESS_KEY",
 		"AWS_SECRET_ACCESS_KEY",
 		"AWS_ROLE",
@@ -64,17 +66,83 @@ func CheckAWSEnvVarsForECSAndSecretsManager(t *testing.T) {
 	)
 }
 
+// CheckAWSEnvVarsForSSM checks that the required environment variables are
+// defined for testing against AWS Systems Manager Parameter Store. If they are
+// not defined, the test is skipped.
+func CheckAWSEnvVarsForSSM(t *testing.T) {
+	CheckEnvVarsOrSkip(t,
+		"AWS_ACCESS_KEY",
+		"AWS_SECRET_ACCESS_KEY",
+		"AWS_ROLE",
+		"AWS_REGION",
+		"AWS_SSM_PARAMETER_PREFIX",
+	)
+}
+
+// CheckAWSEnvVarsForKMS checks that the required environment variables are
+// defined for testing against AWS KMS. If they are not defined, the test is
+// skipped.
+func CheckAWSEnvVarsForKMS(t *testing.T) {
+	CheckEnvVarsOrSkip(t,
+		"AWS_ACCESS_KEY",
+		"AWS_SECRET_ACCESS_KEY",
+		"AWS_ROLE",
+		"AWS_REGION",
+		"AWS_KMS_KEY_ID",
+	)
+}
+
+// CheckAWSEnvVarsForECR checks that the required environment variables are
+// defined for testing against AWS ECR. If they are not defined, the test is
+// skipped.
+func CheckAWSEnvVarsForECR(t *testing.T) {
+	CheckEnvVarsOrSkip(t,
+		"AWS_ACCESS_KEY",
+		"AWS_SECRET_ACCESS_KEY",
+		"AWS_ROLE",
+		"AWS_REGION",
+		"AWS_ECR_REPOSITORY",
+	)
+}
+
 // CheckEnvVars checks that the required environment variables are set.
 func CheckEnvVars(t *testing.T, envVars ...string) {
-	var missing []string
+	if missing := missingEnvVars(envVars...); len(missing) > 0 {
+		require.FailNow(t, fmt.Sprintf("missing required AWS environment variables: %s", missing))
+	}
+}
+
+// CheckEnvVarsOrSkip checks that the required environment variables are set. If
+// any are missing, the test is skipped rather than failed. This is intended for
+// services that are optional in the testing environment.
+func CheckEnvVarsOrSkip(t *testing.T, envVars ...string) {
+	if missing := missingEnvVars(envVars...); len(missing) > 0 {
+		t.Skipf("skipping test because of missing required AWS environment variables: %s", missing)
+	}
+}
+
+// OverrideEnvVar sets the environment variable to the value for the duration
+// of the test. When the test finishes, the environment variable is restored to
+// its original value, or unset if it was not originally set.
+func OverrideEnvVar(t *testing.T, key, value string) {
+	original, wasSet := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value), "setting environment variable '%s'", key)
+	t.Cleanup(func() {
+		if wasSet {
+			assert.NoError(t, os.Setenv(key, original), "restoring environment variable '%s'", key)
+			return
+		}
+		assert.NoError(t, os.Unsetenv(key), "unsetting environment variable '%s'", key)
+	})
+}
 
+// missingEnvVars returns the environment variables that are not set.
+func missingEnvVars(envVars ...string) []string {
+	var missing []string
 	for _, envVar := range envVars {
 		if os.Getenv(envVar) == "" {
 			missing = append(missing, envVar)
 		}
 	}
-
-	if len(missing) > 0 {
-		require.FailNow(t, fmt.Sprintf("missing required AWS environment variables: %s", missing))
-	}
+	return missing
 }
@@ -0,0 +1,236 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RecordFixturesEnvVar is the environment variable that, when set to a
+// non-empty value, makes the recording transport send requests to AWS and
+// record the responses as fixtures instead of playing back existing fixtures.
+const RecordFixturesEnvVar = "COCOA_RECORD_FIXTURES"
+
+// Fixture represents a recorded sequence of HTTP interactions with AWS for a
+// single test.
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction represents a single recorded HTTP request to AWS and its
+// response.
+type Interaction struct {
+	// Operation is the name of the AWS API operation that was requested (e.g.
+	// "ListTasks").
+	Operation string `json:"operation"`
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int `json:"status_code"`
+	// Headers are the HTTP response headers.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is the HTTP response body.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// recordingTransport is an HTTP transport that either plays back recorded AWS
+// responses from a fixture file or records real AWS responses to a fixture
+// file.
+type recordingTransport struct {
+	t         *testing.T
+	path      string
+	recording bool
+	base      http.RoundTripper
+
+	mu      sync.Mutex
+	fixture Fixture
+	next    int
+}
+
+// NewRecordingTransport returns an HTTP transport for the test that plays back
+// the AWS responses recorded in the test's fixture file in fixtureDir. The
+// fixture file is named after the test. If RecordFixturesEnvVar is set, the
+// transport instead sends requests to AWS and writes the responses to the
+// test's fixture file when the test finishes.
+func NewRecordingTransport(t *testing.T, fixtureDir string) http.RoundTripper {
+	rt := &recordingTransport{
+		t:         t,
+		path:      filepath.Join(fixtureDir, filepath.FromSlash(t.Name())+".json"),
+		recording: IsRecordingFixtures(),
+		base:      http.DefaultTransport,
+	}
+
+	if rt.recording {
+		t.Cleanup(rt.writeFixture)
+		return rt
+	}
+
+	b, err := ioutil.ReadFile(rt.path)
+	require.NoError(t, err, "reading fixture file '%s'", rt.path)
+	require.NoError(t, json.Unmarshal(b, &rt.fixture), "unmarshalling fixture file '%s'", rt.path)
+	t.Cleanup(rt.checkAllPlayed)
+
+	return rt
+}
+
+// IsRecordingFixtures returns whether or not the recording transport should
+// record new fixtures rather than play back existing ones.
+func IsRecordingFixtures() bool {
+	return os.Getenv(RecordFixturesEnvVar) != ""
+}
+
+// RoundTrip either plays back the next recorded response or sends the request
+// to AWS and records the response.
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	op := operationFromRequest(req)
+	if rt.recording {
+		return rt.record(op, req)
+	}
+	return rt.playback(op, req)
+}
+
+func (rt *recordingTransport) playback(op string, req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if op == "" {
+		return nil, errors.Errorf("cannot play back request '%s %s' because it is not an AWS API operation", req.Method, req.URL)
+	}
+
+	if rt.next >= len(rt.fixture.Interactions) {
+		return nil, errors.Errorf("no more recorded interactions in fixture '%s' to play back for operation '%s'", rt.path, op)
+	}
+	interaction := rt.fixture.Interactions[rt.next]
+	if interaction.Operation != op {
+		return nil, errors.Errorf("expected recorded interaction %d in fixture '%s' to be for operation '%s', but it is for operation '%s'", rt.next, rt.path, op, interaction.Operation)
+	}
+	rt.next++
+
+	header := http.Header{}
+	for k, v := range interaction.Headers {
+		header.Set(k, v)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(interaction.Body)),
+		ContentLength: int64(len(interaction.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (rt *recordingTransport) record(op string, req *http.Request) (*http.Response, error) {
+	resp, err := rt.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if op == "" {
+		// Requests that are not AWS JSON protocol API calls (e.g. requests to
+		// STS to assume a role) are only needed when making real requests, so
+		// they are not recorded.
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading response body")
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	interaction := Interaction{
+		Operation:  op,
+		StatusCode: resp.StatusCode,
+		Headers:    map[string]string{},
+	}
+	if json.Valid(body) {
+		interaction.Body = body
+	}
+	for _, k := range []string{"Content-Type", "X-Amzn-Errortype"} {
+		if v := resp.Header.Get(k); v != "" {
+			interaction.Headers[k] = v
+		}
+	}
+
+	rt.mu.Lock()
+	rt.fixture.Interactions = append(rt.fixture.Interactions, interaction)
+	rt.mu.Unlock()
+
+	return resp, nil
+}
+
+// writeFixture writes all the recorded interactions to the fixture file.
+func (rt *recordingTransport) writeFixture() {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	b, err := json.MarshalIndent(rt.fixture, "", "\t")
+	if !assert.NoError(rt.t, err, "marshalling fixture") {
+		return
+	}
+	if !assert.NoError(rt.t, os.MkdirAll(filepath.Dir(rt.path), 0755), "making fixture directory") {
+		return
+	}
+	assert.NoError(rt.t, ioutil.WriteFile(rt.path, append(b, '\n'), 0644), "writing fixture file '%s'", rt.path)
+}
+
+// checkAllPlayed checks that every recorded interaction was played back.
+func (rt *recordingTransport) checkAllPlayed() {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	assert.Equal(rt.t, len(rt.fixture.Interactions), rt.next, "not all recorded interactions in fixture '%s' were played back", rt.path)
+}
+
+// operationFromRequest returns the name of the AWS API operation for the
+// request. AWS JSON protocol APIs specify the operation in the target header as
+// "<service>.<operation>". If the request does not specify an operation, this
+// returns an empty string.
+func operationFromRequest(req *http.Request) string {
+	target := req.Header.Get("X-Amz-Target")
+	return target[strings.LastIndex(target, ".")+1:]
+}
+
+// RecordedAWSOptions returns options to create an AWS client whose requests
+// are served by a recording transport using the fixtures in fixtureDir. When
+// playing back fixtures, the client does not need real AWS credentials. When
+// recording fixtures, the client uses the same credentials as integration
+// tests.
+func RecordedAWSOptions(t *testing.T, fixtureDir string) awsutil.ClientOptions {
+	// The AWS SDK requires the HTTP client's transport to be an
+	// *http.Transport in some cases (e.g. to load a custom CA bundle), so the
+	// recording transport handles HTTPS requests on behalf of a standard
+	// transport.
+	transport := &http.Transport{}
+	transport.RegisterProtocol("https", NewRecordingTransport(t, fixtureDir))
+	hc := &http.Client{Transport: transport}
+	retryOpts := awsutil.NewRetryOptions().WithMaxAttempts(3).Build()
+
+	if IsRecordingFixtures() {
+		CheckAWSEnvVars(t)
+		opts := ValidIntegrationAWSOptions(hc)
+		return *opts.SetRetryOptions(retryOpts)
+	}
+
+	return *awsutil.NewClientOptions().
+		SetHTTPClient(hc).
+		SetCredentials(credentials.NewStaticCredentials("access_key_id", "secret_access_key", "")).
+		SetRegion("us-east-1").
+		SetRetryOptions(retryOpts)
+}
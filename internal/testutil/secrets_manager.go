@@ -6,6 +6,7 @@ import (
 	"path"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
@@ -96,3 +97,33 @@ func CreateSecret(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClien
 	require.NotZero(t, out.ARN)
 	return *out
 }
+
+// cleanupTimeout is the maximum amount of time that test cleanup is allowed to
+// take.
+const cleanupTimeout = time.Minute
+
+// CreateAndCleanupSecret creates a secret whose name starts with the given
+// prefix and registers a cleanup function with the test to delete it once the
+// test completes. It returns the secret ID.
+func CreateAndCleanupSecret(ctx context.Context, t *testing.T, c cocoa.SecretsManagerClient, prefix string) (secretID string) {
+	out := CreateSecret(ctx, t, c, secretsmanager.CreateSecretInput{
+		Name:         aws.String(path.Join(secretName(t), prefix, utility.RandomString())),
+		SecretString: aws.String(utility.RandomString()),
+	})
+	secretID = utility.FromStringPtr(out.ARN)
+
+	t.Cleanup(func() {
+		// The test's context may already be done by the time cleanup runs, so
+		// cleanup uses its own context.
+		ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+
+		_, err := c.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{
+			ForceDeleteWithoutRecovery: utility.TruePtr(),
+			SecretId:                   aws.String(secretID),
+		})
+		assert.NoError(t, err, "cleaning up secret '%s'", secretID)
+	})
+
+	return secretID
+}
@@ -0,0 +1,91 @@
+package kms
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+// BasicClient provides a cocoa.KMSClient implementation that wraps the AWS
+// Key Management Service API. It supports retrying requests using exponential
+// backoff and jitter.
+type BasicClient struct {
+	awsutil.BaseClient
+	kms *kms.KMS
+}
+
+// NewBasicClient creates a new AWS Key Management Service client from the
+// given options.
+func NewBasicClient(opts awsutil.ClientOptions) (*BasicClient, error) {
+	c := &BasicClient{
+		BaseClient: awsutil.NewBaseClient(opts),
+	}
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	return c, nil
+}
+
+func (c *BasicClient) setup() error {
+	if c.kms != nil {
+		return nil
+	}
+
+	sess, err := c.GetSession()
+	if err != nil {
+		return errors.Wrap(err, "initializing session")
+	}
+
+	c.kms = kms.New(sess)
+
+	return nil
+}
+
+// DescribeKey gets information about a KMS key.
+func (c *BasicClient) DescribeKey(ctx context.Context, in *kms.DescribeKeyInput) (*kms.DescribeKeyOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *kms.DescribeKeyOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DescribeKey", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.kms.DescribeKeyWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DescribeKey", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// Close cleans up all resources owned by the client.
+func (c *BasicClient) Close(ctx context.Context) error {
+	return c.BaseClient.Close(ctx)
+}
+
+func (c *BasicClient) isNonRetryableErrorCode(code string) bool {
+	switch code {
+	case "AccessDeniedException",
+		kms.ErrCodeInvalidArnException,
+		kms.ErrCodeNotFoundException,
+		request.InvalidParameterErrCode,
+		request.ParamRequiredErrCode:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,94 @@
+package kms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultTestTimeout is the standard timeout for tests against KMS.
+const defaultTestTimeout = time.Minute
+
+func TestBasicClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.KMSClient)(nil), &BasicClient{})
+
+	t.Run("FailsWithInvalidOptions", func(t *testing.T) {
+		c, err := NewBasicClient(*awsutil.NewClientOptions())
+		assert.Error(t, err)
+		assert.Zero(t, c)
+	})
+}
+
+func TestBasicClientWithAWS(t *testing.T) {
+	testutil.CheckAWSEnvVarsForKMS(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	hc := utility.GetHTTPClient()
+	defer utility.PutHTTPClient(hc)
+
+	c, err := NewBasicClient(testutil.ValidIntegrationAWSOptions(hc))
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, c.Close(ctx))
+	}()
+
+	out, err := c.DescribeKey(ctx, &kms.DescribeKeyInput{
+		KeyId: aws.String(testutil.KMSKeyID()),
+	})
+	require.NoError(t, err)
+	require.NotZero(t, out)
+	require.NotZero(t, out.KeyMetadata)
+	assert.NotZero(t, utility.FromStringPtr(out.KeyMetadata.Arn))
+}
+
+func TestBasicClientWithRecordedFixtures(t *testing.T) {
+	const keyARN = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *BasicClient){
+		"DescribeKey": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			out, err := c.DescribeKey(ctx, &kms.DescribeKeyInput{
+				KeyId: aws.String("alias/cocoa"),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			require.NotZero(t, out.KeyMetadata)
+			assert.Equal(t, keyARN, utility.FromStringPtr(out.KeyMetadata.Arn))
+			assert.Equal(t, kms.KeyStateEnabled, utility.FromStringPtr(out.KeyMetadata.KeyState))
+		},
+		"DescribeKeyFailsWithNonexistentKey": func(ctx context.Context, t *testing.T, c *BasicClient) {
+			out, err := c.DescribeKey(ctx, &kms.DescribeKeyInput{
+				KeyId: aws.String("alias/nonexistent"),
+			})
+			require.Error(t, err)
+			assert.Zero(t, out)
+			awsErr, ok := err.(awserr.Error)
+			require.True(t, ok, "error should be an AWS error")
+			assert.Equal(t, kms.ErrCodeNotFoundException, awsErr.Code())
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+			defer cancel()
+
+			c, err := NewBasicClient(testutil.RecordedAWSOptions(t, "testdata/fixtures"))
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, c.Close(ctx))
+			}()
+
+			tCase(ctx, t, c)
+		})
+	}
+}
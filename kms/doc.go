@@ -0,0 +1,4 @@
+/*
+Package kms provides interfaces to interact with AWS Key Management Service.
+*/
+package kms
@@ -0,0 +1,19 @@
+package cocoa
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+)
+
+// KMSClient provides a common interface to interact with a client backed by
+// AWS Key Management Service. Implementations must handle retrying and
+// backoff.
+type KMSClient interface {
+	// DescribeKey gets information about a KMS key by its key ID, key ARN,
+	// alias name or alias ARN.
+	DescribeKey(ctx context.Context, in *kms.DescribeKeyInput) (*kms.DescribeKeyOutput, error)
+	// Close closes the client and cleans up its resources. Implementations
+	// should ensure that this is idempotent.
+	Close(ctx context.Context) error
+}
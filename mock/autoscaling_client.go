@@ -0,0 +1,110 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/evergreen-ci/utility"
+)
+
+// AutoScalingActivity is a representation of a scaling activity stored in the
+// fake Auto Scaling storage.
+type AutoScalingActivity struct {
+	ID          string
+	Description string
+	Cause       string
+	StatusCode  string
+	Progress    int64
+	StartTime   time.Time
+	EndTime     *time.Time
+}
+
+func (a *AutoScalingActivity) export(group string) *autoscaling.Activity {
+	return &autoscaling.Activity{
+		ActivityId:           utility.ToStringPtr(a.ID),
+		AutoScalingGroupName: utility.ToStringPtr(group),
+		Description:          utility.ToStringPtr(a.Description),
+		Cause:                utility.ToStringPtr(a.Cause),
+		StatusCode:           utility.ToStringPtr(a.StatusCode),
+		Progress:             utility.ToInt64Ptr(a.Progress),
+		StartTime:            utility.ToTimePtr(a.StartTime),
+		EndTime:              a.EndTime,
+	}
+}
+
+// GlobalAutoScalingActivities is a global fake Auto Scaling storage that maps
+// each Auto Scaling group name to its scaling activities. This can be used
+// indirectly with the AutoScalingClient to access scaling activities, or used
+// directly.
+var GlobalAutoScalingActivities map[string][]AutoScalingActivity
+
+func init() {
+	ResetGlobalAutoScalingActivities()
+}
+
+// ResetGlobalAutoScalingActivities resets the global fake Auto Scaling storage
+// to an initialized but clean state.
+func ResetGlobalAutoScalingActivities() {
+	GlobalAutoScalingActivities = map[string][]AutoScalingActivity{}
+}
+
+// AutoScalingClient provides a mock implementation of a
+// cocoa.AutoScalingClient. This makes it possible to introspect on inputs to
+// the client and control the client's output. It provides some default
+// implementations where possible. By default, it will issue the API calls to
+// the fake GlobalAutoScalingActivities.
+type AutoScalingClient struct {
+	DescribeScalingActivitiesInput  *autoscaling.DescribeScalingActivitiesInput
+	DescribeScalingActivitiesOutput *autoscaling.DescribeScalingActivitiesOutput
+	DescribeScalingActivitiesError  error
+
+	CloseError error
+}
+
+// DescribeScalingActivities saves the input and returns the scaling activities
+// for the Auto Scaling group. The mock output can be customized. By default, it
+// will return the matching activities in the global fake Auto Scaling storage,
+// ordered from most to least recently started. It does not paginate results.
+func (c *AutoScalingClient) DescribeScalingActivities(ctx context.Context, in *autoscaling.DescribeScalingActivitiesInput) (*autoscaling.DescribeScalingActivitiesOutput, error) {
+	c.DescribeScalingActivitiesInput = in
+
+	if c.DescribeScalingActivitiesOutput != nil || c.DescribeScalingActivitiesError != nil {
+		return c.DescribeScalingActivitiesOutput, c.DescribeScalingActivitiesError
+	}
+
+	group := utility.FromStringPtr(in.AutoScalingGroupName)
+	if group == "" {
+		return nil, awserr.New("ValidationError", "missing Auto Scaling group name", nil)
+	}
+
+	ids := utility.FromStringPtrSlice(in.ActivityIds)
+
+	var activities []*autoscaling.Activity
+	for _, a := range GlobalAutoScalingActivities[group] {
+		if len(ids) != 0 && !utility.StringSliceContains(ids, a.ID) {
+			continue
+		}
+		activities = append(activities, a.export(group))
+	}
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].StartTime.After(*activities[j].StartTime)
+	})
+	if max := int(utility.FromInt64Ptr(in.MaxRecords)); max > 0 && len(activities) > max {
+		activities = activities[:max]
+	}
+
+	return &autoscaling.DescribeScalingActivitiesOutput{Activities: activities}, nil
+}
+
+// Close closes the mock client. The mock output can be customized. By default,
+// it is a no-op that returns no error.
+func (c *AutoScalingClient) Close(ctx context.Context) error {
+	if c.CloseError != nil {
+		return c.CloseError
+	}
+
+	return nil
+}
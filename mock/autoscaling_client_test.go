@@ -0,0 +1,78 @@
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoScalingClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.AutoScalingClient)(nil), &AutoScalingClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer ResetGlobalAutoScalingActivities()
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *AutoScalingClient){
+		"DescribeScalingActivitiesReturnsMostRecentFirst": func(ctx context.Context, t *testing.T, c *AutoScalingClient) {
+			now := time.Now()
+			GlobalAutoScalingActivities["group"] = []AutoScalingActivity{
+				{ID: "first", StatusCode: autoscaling.ScalingActivityStatusCodeSuccessful, StartTime: now.Add(-time.Minute)},
+				{ID: "second", StatusCode: autoscaling.ScalingActivityStatusCodeInProgress, StartTime: now},
+			}
+
+			out, err := c.DescribeScalingActivities(ctx, &autoscaling.DescribeScalingActivitiesInput{
+				AutoScalingGroupName: aws.String("group"),
+			})
+			require.NoError(t, err)
+			require.Len(t, out.Activities, 2)
+			assert.Equal(t, "second", utility.FromStringPtr(out.Activities[0].ActivityId))
+			assert.Equal(t, "group", utility.FromStringPtr(out.Activities[0].AutoScalingGroupName))
+			assert.Equal(t, "first", utility.FromStringPtr(out.Activities[1].ActivityId))
+		},
+		"DescribeScalingActivitiesLimitsRecords": func(ctx context.Context, t *testing.T, c *AutoScalingClient) {
+			now := time.Now()
+			GlobalAutoScalingActivities["group"] = []AutoScalingActivity{
+				{ID: "first", StartTime: now.Add(-time.Minute)},
+				{ID: "second", StartTime: now},
+			}
+
+			out, err := c.DescribeScalingActivities(ctx, &autoscaling.DescribeScalingActivitiesInput{
+				AutoScalingGroupName: aws.String("group"),
+				MaxRecords:           aws.Int64(1),
+			})
+			require.NoError(t, err)
+			require.Len(t, out.Activities, 1)
+			assert.Equal(t, "second", utility.FromStringPtr(out.Activities[0].ActivityId))
+		},
+		"DescribeScalingActivitiesReturnsNoActivitiesForNonexistentGroup": func(ctx context.Context, t *testing.T, c *AutoScalingClient) {
+			out, err := c.DescribeScalingActivities(ctx, &autoscaling.DescribeScalingActivitiesInput{
+				AutoScalingGroupName: aws.String("nonexistent"),
+			})
+			require.NoError(t, err)
+			assert.Empty(t, out.Activities)
+		},
+		"DescribeScalingActivitiesFailsWithoutGroupName": func(ctx context.Context, t *testing.T, c *AutoScalingClient) {
+			out, err := c.DescribeScalingActivities(ctx, &autoscaling.DescribeScalingActivitiesInput{})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalAutoScalingActivities()
+
+			tCase(tctx, t, &AutoScalingClient{})
+		})
+	}
+}
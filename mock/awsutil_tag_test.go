@@ -0,0 +1,95 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagAllResources(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer func() {
+		ResetGlobalECSService()
+		ResetGlobalSecretCache()
+	}()
+
+	tags := map[string]string{"owner": "cocoa", "env": "test"}
+
+	createResources := func(ctx context.Context, t *testing.T, ecsc *ECSClient, smc *SecretsManagerClient) (ecsARNs, secretARNs []string) {
+		for i := 0; i < 3; i++ {
+			out := testutil.RegisterTaskDefinition(ctx, t, ecsc, testutil.ValidRegisterTaskDefinitionInput(t))
+			ecsARNs = append(ecsARNs, utility.FromStringPtr(out.TaskDefinition.TaskDefinitionArn))
+		}
+		for _, name := range []string{"secret0", "secret1"} {
+			out, err := smc.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
+				Name:         aws.String(name),
+				SecretString: aws.String("value"),
+			})
+			require.NoError(t, err)
+			secretARNs = append(secretARNs, utility.FromStringPtr(out.ARN))
+		}
+		return ecsARNs, secretARNs
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, ecsc *ECSClient, smc *SecretsManagerClient){
+		"TagsAllResources": func(ctx context.Context, t *testing.T, ecsc *ECSClient, smc *SecretsManagerClient) {
+			ecsARNs, secretARNs := createResources(ctx, t, ecsc, smc)
+
+			errs := awsutil.TagAllResources(ctx, ecsc, smc, tags, ecsARNs, secretARNs)
+			assert.Empty(t, errs)
+
+			for _, arn := range ecsARNs {
+				def, err := GlobalECSService.getTaskDefinition(arn)
+				require.NoError(t, err)
+				assert.Equal(t, tags, def.Tags)
+			}
+			for _, arn := range secretARNs {
+				s, ok := GlobalSecretCache[arn]
+				require.True(t, ok)
+				assert.Equal(t, tags, s.Tags)
+			}
+		},
+		"ReturnsErrorsForResourcesThatFailToBeTagged": func(ctx context.Context, t *testing.T, ecsc *ECSClient, smc *SecretsManagerClient) {
+			ecsARNs, secretARNs := createResources(ctx, t, ecsc, smc)
+			ecsARNs = append(ecsARNs, "arn:aws:ecs:us-east-1:123456789012:task-definition/nonexistent:1")
+			secretARNs = append(secretARNs, "nonexistent")
+
+			errs := awsutil.TagAllResources(ctx, ecsc, smc, tags, ecsARNs, secretARNs)
+			require.Len(t, errs, 2)
+			assert.Contains(t, errs[0].Error(), "task-definition/nonexistent")
+			assert.Contains(t, errs[1].Error(), "nonexistent")
+
+			s, ok := GlobalSecretCache[secretARNs[0]]
+			require.True(t, ok)
+			assert.Equal(t, tags, s.Tags, "resources that exist should still be tagged")
+		},
+		"ReturnsErrorsWithoutClients": func(ctx context.Context, t *testing.T, ecsc *ECSClient, smc *SecretsManagerClient) {
+			errs := awsutil.TagAllResources(ctx, nil, nil, tags, []string{"ecs_arn"}, []string{"secret_arn"})
+			assert.Len(t, errs, 2)
+		},
+		"NoopsWithoutResources": func(ctx context.Context, t *testing.T, ecsc *ECSClient, smc *SecretsManagerClient) {
+			assert.Empty(t, awsutil.TagAllResources(ctx, ecsc, smc, tags, nil, nil))
+			assert.Zero(t, ecsc.TagResourceInput)
+			assert.Zero(t, smc.TagResourceInput)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalECSService()
+			ResetGlobalSecretCache()
+
+			tCase(tctx, t, &ECSClient{}, &SecretsManagerClient{})
+		})
+	}
+}
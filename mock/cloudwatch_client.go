@@ -0,0 +1,195 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/evergreen-ci/utility"
+)
+
+// StoredMetricDatum is a representation of a metric data point stored in the
+// fake CloudWatch metrics storage.
+type StoredMetricDatum struct {
+	Name       string
+	Dimensions map[string]string
+	Value      float64
+	Unit       string
+	Timestamp  time.Time
+}
+
+func newStoredMetricDatum(d *cloudwatch.MetricDatum, ts time.Time) StoredMetricDatum {
+	stored := StoredMetricDatum{
+		Name:       utility.FromStringPtr(d.MetricName),
+		Dimensions: map[string]string{},
+		Value:      utility.FromFloat64Ptr(d.Value),
+		Unit:       utility.FromStringPtr(d.Unit),
+		Timestamp:  ts,
+	}
+	if d.Timestamp != nil {
+		stored.Timestamp = *d.Timestamp
+	}
+	for _, dim := range d.Dimensions {
+		if dim == nil {
+			continue
+		}
+		stored.Dimensions[utility.FromStringPtr(dim.Name)] = utility.FromStringPtr(dim.Value)
+	}
+	return stored
+}
+
+// matches returns whether or not the data point is for the metric with the
+// given name and exact dimensions.
+func (d *StoredMetricDatum) matches(name string, dims []*cloudwatch.Dimension) bool {
+	if d.Name != name || len(d.Dimensions) != len(dims) {
+		return false
+	}
+	for _, dim := range dims {
+		if dim == nil {
+			return false
+		}
+		if v, ok := d.Dimensions[utility.FromStringPtr(dim.Name)]; !ok || v != utility.FromStringPtr(dim.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// GlobalCloudWatchMetrics is a global fake CloudWatch metrics storage that maps
+// each metric namespace to its data points. This can be used indirectly with
+// the CloudWatchClient to access and publish metrics, or used directly.
+var GlobalCloudWatchMetrics map[string][]StoredMetricDatum
+
+func init() {
+	ResetGlobalCloudWatchMetrics()
+}
+
+// ResetGlobalCloudWatchMetrics resets the global fake CloudWatch metrics
+// storage to an initialized but clean state.
+func ResetGlobalCloudWatchMetrics() {
+	GlobalCloudWatchMetrics = map[string][]StoredMetricDatum{}
+}
+
+// CloudWatchClient provides a mock implementation of a cocoa.CloudWatchClient.
+// This makes it possible to introspect on inputs to the client and control the
+// client's output. It provides some default implementations where possible. By
+// default, it will issue the API calls to the fake GlobalCloudWatchMetrics.
+type CloudWatchClient struct {
+	GetMetricStatisticsInput  *cloudwatch.GetMetricStatisticsInput
+	GetMetricStatisticsOutput *cloudwatch.GetMetricStatisticsOutput
+	GetMetricStatisticsError  error
+
+	PutMetricDataInput  *cloudwatch.PutMetricDataInput
+	PutMetricDataOutput *cloudwatch.PutMetricDataOutput
+	PutMetricDataError  error
+
+	CloseError error
+}
+
+// GetMetricStatistics saves the input and returns the statistics for the
+// metric. The mock output can be customized. By default, it will return a
+// single data point summarizing all the matching data points in the global
+// fake CloudWatch metrics within the time range, regardless of the period.
+func (c *CloudWatchClient) GetMetricStatistics(ctx context.Context, in *cloudwatch.GetMetricStatisticsInput) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	c.GetMetricStatisticsInput = in
+
+	if c.GetMetricStatisticsOutput != nil || c.GetMetricStatisticsError != nil {
+		return c.GetMetricStatisticsOutput, c.GetMetricStatisticsError
+	}
+
+	if in.Namespace == nil || in.MetricName == nil || in.StartTime == nil || in.EndTime == nil {
+		return nil, awserr.New(cloudwatch.ErrCodeMissingRequiredParameterException, "missing required parameter", nil)
+	}
+
+	var matching []StoredMetricDatum
+	for _, d := range GlobalCloudWatchMetrics[utility.FromStringPtr(in.Namespace)] {
+		if !d.matches(utility.FromStringPtr(in.MetricName), in.Dimensions) {
+			continue
+		}
+		if d.Timestamp.Before(*in.StartTime) || !d.Timestamp.Before(*in.EndTime) {
+			continue
+		}
+		matching = append(matching, d)
+	}
+
+	out := &cloudwatch.GetMetricStatisticsOutput{Label: in.MetricName}
+	if len(matching) == 0 {
+		return out, nil
+	}
+
+	var sum float64
+	min := matching[0].Value
+	max := matching[0].Value
+	for _, d := range matching {
+		sum += d.Value
+		if d.Value < min {
+			min = d.Value
+		}
+		if d.Value > max {
+			max = d.Value
+		}
+	}
+	count := float64(len(matching))
+
+	dp := &cloudwatch.Datapoint{
+		Timestamp: in.StartTime,
+		Unit:      utility.ToStringPtr(matching[0].Unit),
+	}
+	for _, stat := range utility.FromStringPtrSlice(in.Statistics) {
+		switch stat {
+		case cloudwatch.StatisticAverage:
+			dp.Average = utility.ToFloat64Ptr(sum / count)
+		case cloudwatch.StatisticMaximum:
+			dp.Maximum = utility.ToFloat64Ptr(max)
+		case cloudwatch.StatisticMinimum:
+			dp.Minimum = utility.ToFloat64Ptr(min)
+		case cloudwatch.StatisticSum:
+			dp.Sum = utility.ToFloat64Ptr(sum)
+		case cloudwatch.StatisticSampleCount:
+			dp.SampleCount = utility.ToFloat64Ptr(count)
+		}
+	}
+	out.Datapoints = []*cloudwatch.Datapoint{dp}
+
+	return out, nil
+}
+
+// PutMetricData saves the input and publishes the metric data points. The mock
+// output can be customized. By default, it will store the data points in the
+// global fake CloudWatch metrics.
+func (c *CloudWatchClient) PutMetricData(ctx context.Context, in *cloudwatch.PutMetricDataInput) (*cloudwatch.PutMetricDataOutput, error) {
+	c.PutMetricDataInput = in
+
+	if c.PutMetricDataOutput != nil || c.PutMetricDataError != nil {
+		return c.PutMetricDataOutput, c.PutMetricDataError
+	}
+
+	namespace := utility.FromStringPtr(in.Namespace)
+	if namespace == "" {
+		return nil, awserr.New(cloudwatch.ErrCodeMissingRequiredParameterException, "missing namespace", nil)
+	}
+
+	ts := time.Now()
+	for _, d := range in.MetricData {
+		if d == nil {
+			continue
+		}
+		if d.MetricName == nil {
+			return nil, awserr.New(cloudwatch.ErrCodeMissingRequiredParameterException, "missing metric name", nil)
+		}
+		GlobalCloudWatchMetrics[namespace] = append(GlobalCloudWatchMetrics[namespace], newStoredMetricDatum(d, ts))
+	}
+
+	return &cloudwatch.PutMetricDataOutput{}, nil
+}
+
+// Close closes the mock client. The mock output can be customized. By default,
+// it is a no-op that returns no error.
+func (c *CloudWatchClient) Close(ctx context.Context) error {
+	if c.CloseError != nil {
+		return c.CloseError
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudWatchClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.CloudWatchClient)(nil), &CloudWatchClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer ResetGlobalCloudWatchMetrics()
+
+	dims := []*cloudwatch.Dimension{{Name: aws.String("name"), Value: aws.String("value")}}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *CloudWatchClient){
+		"PutMetricDataStoresDataPoints": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			out, err := c.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+				Namespace: aws.String("namespace"),
+				MetricData: []*cloudwatch.MetricDatum{{
+					MetricName: aws.String("metric"),
+					Dimensions: dims,
+					Value:      aws.Float64(1),
+					Unit:       aws.String(cloudwatch.StandardUnitCount),
+				}},
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+
+			require.Len(t, GlobalCloudWatchMetrics["namespace"], 1)
+			stored := GlobalCloudWatchMetrics["namespace"][0]
+			assert.Equal(t, "metric", stored.Name)
+			assert.Equal(t, map[string]string{"name": "value"}, stored.Dimensions)
+			assert.Equal(t, 1.0, stored.Value)
+			assert.Equal(t, cloudwatch.StandardUnitCount, stored.Unit)
+			assert.NotZero(t, stored.Timestamp)
+		},
+		"PutMetricDataFailsWithoutNamespace": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			out, err := c.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+				MetricData: []*cloudwatch.MetricDatum{{MetricName: aws.String("metric")}},
+			})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+		},
+		"GetMetricStatisticsSummarizesMatchingDataPoints": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			for _, v := range []float64{1, 2, 6} {
+				_, err := c.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+					Namespace: aws.String("namespace"),
+					MetricData: []*cloudwatch.MetricDatum{{
+						MetricName: aws.String("metric"),
+						Dimensions: dims,
+						Value:      aws.Float64(v),
+					}},
+				})
+				require.NoError(t, err)
+			}
+			_, err := c.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+				Namespace: aws.String("namespace"),
+				MetricData: []*cloudwatch.MetricDatum{{
+					MetricName: aws.String("metric"),
+					Value:      aws.Float64(100),
+				}},
+			})
+			require.NoError(t, err)
+
+			out, err := c.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+				Namespace:  aws.String("namespace"),
+				MetricName: aws.String("metric"),
+				Dimensions: dims,
+				StartTime:  aws.Time(time.Now().Add(-time.Hour)),
+				EndTime:    aws.Time(time.Now().Add(time.Hour)),
+				Period:     aws.Int64(60),
+				Statistics: aws.StringSlice([]string{
+					cloudwatch.StatisticAverage,
+					cloudwatch.StatisticMaximum,
+					cloudwatch.StatisticMinimum,
+					cloudwatch.StatisticSum,
+					cloudwatch.StatisticSampleCount,
+				}),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			require.Len(t, out.Datapoints, 1)
+			dp := out.Datapoints[0]
+			assert.Equal(t, 3.0, utility.FromFloat64Ptr(dp.Average))
+			assert.Equal(t, 6.0, utility.FromFloat64Ptr(dp.Maximum))
+			assert.Equal(t, 1.0, utility.FromFloat64Ptr(dp.Minimum))
+			assert.Equal(t, 9.0, utility.FromFloat64Ptr(dp.Sum))
+			assert.Equal(t, 3.0, utility.FromFloat64Ptr(dp.SampleCount))
+		},
+		"GetMetricStatisticsReturnsNoDataPointsOutsideTimeRange": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			_, err := c.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+				Namespace: aws.String("namespace"),
+				MetricData: []*cloudwatch.MetricDatum{{
+					MetricName: aws.String("metric"),
+					Value:      aws.Float64(1),
+					Timestamp:  aws.Time(time.Now().Add(-2 * time.Hour)),
+				}},
+			})
+			require.NoError(t, err)
+
+			out, err := c.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+				Namespace:  aws.String("namespace"),
+				MetricName: aws.String("metric"),
+				StartTime:  aws.Time(time.Now().Add(-time.Hour)),
+				EndTime:    aws.Time(time.Now()),
+				Period:     aws.Int64(60),
+				Statistics: aws.StringSlice([]string{cloudwatch.StatisticAverage}),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			assert.Empty(t, out.Datapoints)
+		},
+		"GetMetricStatisticsFailsWithoutTimeRange": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			out, err := c.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+				Namespace:  aws.String("namespace"),
+				MetricName: aws.String("metric"),
+			})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalCloudWatchMetrics()
+
+			c := &CloudWatchClient{}
+			defer func() {
+				assert.NoError(t, c.Close(tctx))
+			}()
+
+			tCase(tctx, t, c)
+		})
+	}
+}
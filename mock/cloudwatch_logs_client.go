@@ -0,0 +1,91 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/evergreen-ci/utility"
+)
+
+// CloudWatchLogGroup represents a mock CloudWatch log group, which maps each
+// log stream name to its log messages.
+type CloudWatchLogGroup map[string][]string
+
+// GlobalCloudWatchLogs is a global fake CloudWatch Logs storage that maps each
+// log group name to its log group. This can be used indirectly with the
+// CloudWatchLogsClient to read logs, or used directly.
+var GlobalCloudWatchLogs map[string]CloudWatchLogGroup
+
+func init() {
+	ResetGlobalCloudWatchLogs()
+}
+
+// ResetGlobalCloudWatchLogs resets the global fake CloudWatch Logs storage to
+// an initialized but clean state.
+func ResetGlobalCloudWatchLogs() {
+	GlobalCloudWatchLogs = map[string]CloudWatchLogGroup{}
+}
+
+// endOfLogStreamToken is the token returned by the mock GetLogEvents to
+// indicate that all the log events in the log stream have been read.
+const endOfLogStreamToken = "f/end"
+
+// CloudWatchLogsClient provides a mock implementation of a
+// cocoa.CloudWatchLogsClient. This makes it possible to introspect on inputs to
+// the client and control the client's output. It provides some default
+// implementations where possible. By default, it will issue the API calls to
+// the fake GlobalCloudWatchLogs.
+type CloudWatchLogsClient struct {
+	GetLogEventsInput  *cloudwatchlogs.GetLogEventsInput
+	GetLogEventsOutput *cloudwatchlogs.GetLogEventsOutput
+	GetLogEventsError  error
+
+	CloseError error
+}
+
+// GetLogEvents saves the input and returns the log events from the log stream.
+// The mock output can be customized. By default, it will return all the log
+// events in the log stream from the global fake CloudWatch Logs in a single
+// page.
+func (c *CloudWatchLogsClient) GetLogEvents(ctx context.Context, in *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	c.GetLogEventsInput = in
+
+	if c.GetLogEventsOutput != nil || c.GetLogEventsError != nil {
+		return c.GetLogEventsOutput, c.GetLogEventsError
+	}
+
+	group, ok := GlobalCloudWatchLogs[utility.FromStringPtr(in.LogGroupName)]
+	if !ok {
+		return nil, awserr.New(cloudwatchlogs.ErrCodeResourceNotFoundException, "log group not found", nil)
+	}
+	messages, ok := group[utility.FromStringPtr(in.LogStreamName)]
+	if !ok {
+		return nil, awserr.New(cloudwatchlogs.ErrCodeResourceNotFoundException, "log stream not found", nil)
+	}
+
+	out := &cloudwatchlogs.GetLogEventsOutput{
+		NextForwardToken: utility.ToStringPtr(endOfLogStreamToken),
+	}
+	if utility.FromStringPtr(in.NextToken) == endOfLogStreamToken {
+		return out, nil
+	}
+
+	for _, msg := range messages {
+		out.Events = append(out.Events, &cloudwatchlogs.OutputLogEvent{
+			Message: utility.ToStringPtr(msg),
+		})
+	}
+
+	return out, nil
+}
+
+// Close closes the mock client. The mock output can be customized. By default,
+// it is a no-op that returns no error.
+func (c *CloudWatchLogsClient) Close(ctx context.Context) error {
+	if c.CloseError != nil {
+		return c.CloseError
+	}
+
+	return nil
+}
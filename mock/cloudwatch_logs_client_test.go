@@ -0,0 +1,89 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudWatchLogsClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.CloudWatchLogsClient)(nil), &CloudWatchLogsClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer ResetGlobalCloudWatchLogs()
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *CloudWatchLogsClient){
+		"GetLogEventsReturnsAllLogEvents": func(ctx context.Context, t *testing.T, c *CloudWatchLogsClient) {
+			GlobalCloudWatchLogs["group"] = CloudWatchLogGroup{"stream": {"foo", "bar"}}
+
+			in := &cloudwatchlogs.GetLogEventsInput{
+				LogGroupName:  aws.String("group"),
+				LogStreamName: aws.String("stream"),
+			}
+			out, err := c.GetLogEvents(ctx, in)
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			require.Len(t, out.Events, 2)
+			assert.Equal(t, "foo", utility.FromStringPtr(out.Events[0].Message))
+			assert.Equal(t, "bar", utility.FromStringPtr(out.Events[1].Message))
+			assert.Equal(t, in, c.GetLogEventsInput)
+
+			in.NextToken = out.NextForwardToken
+			out, err = c.GetLogEvents(ctx, in)
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			assert.Empty(t, out.Events)
+			assert.Equal(t, utility.FromStringPtr(in.NextToken), utility.FromStringPtr(out.NextForwardToken))
+		},
+		"GetLogEventsFailsWithNonexistentLogGroup": func(ctx context.Context, t *testing.T, c *CloudWatchLogsClient) {
+			out, err := c.GetLogEvents(ctx, &cloudwatchlogs.GetLogEventsInput{
+				LogGroupName:  aws.String("nonexistent"),
+				LogStreamName: aws.String("stream"),
+			})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+		},
+		"GetLogEventsFailsWithNonexistentLogStream": func(ctx context.Context, t *testing.T, c *CloudWatchLogsClient) {
+			GlobalCloudWatchLogs["group"] = CloudWatchLogGroup{}
+
+			out, err := c.GetLogEvents(ctx, &cloudwatchlogs.GetLogEventsInput{
+				LogGroupName:  aws.String("group"),
+				LogStreamName: aws.String("nonexistent"),
+			})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+		},
+		"GetLogEventsReturnsCustomOutput": func(ctx context.Context, t *testing.T, c *CloudWatchLogsClient) {
+			c.GetLogEventsOutput = &cloudwatchlogs.GetLogEventsOutput{}
+
+			out, err := c.GetLogEvents(ctx, &cloudwatchlogs.GetLogEventsInput{
+				LogGroupName:  aws.String("nonexistent"),
+				LogStreamName: aws.String("nonexistent"),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, c.GetLogEventsOutput, out)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalCloudWatchLogs()
+
+			c := &CloudWatchLogsClient{}
+			defer func() {
+				assert.NoError(t, c.Close(tctx))
+			}()
+
+			tCase(tctx, t, c)
+		})
+	}
+}
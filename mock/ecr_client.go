@@ -0,0 +1,137 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/evergreen-ci/utility"
+)
+
+// ECRImage is a representation of an image stored in the fake ECR storage.
+type ECRImage struct {
+	Digest   string
+	Tags     []string
+	Manifest string
+}
+
+func (i *ECRImage) export(registryID, repo, tag string) *ecr.Image {
+	id := &ecr.ImageIdentifier{ImageDigest: utility.ToStringPtr(i.Digest)}
+	if tag != "" {
+		id.ImageTag = utility.ToStringPtr(tag)
+	}
+	return &ecr.Image{
+		ImageId:        id,
+		ImageManifest:  utility.ToStringPtr(i.Manifest),
+		RegistryId:     utility.ToStringPtr(registryID),
+		RepositoryName: utility.ToStringPtr(repo),
+	}
+}
+
+// GlobalECRImages is a global fake ECR storage that maps each repository name
+// to its images. This can be used indirectly with the ECRClient to access
+// images, or used directly.
+var GlobalECRImages map[string][]ECRImage
+
+func init() {
+	ResetGlobalECRImages()
+}
+
+// ResetGlobalECRImages resets the global fake ECR storage to an initialized
+// but clean state.
+func ResetGlobalECRImages() {
+	GlobalECRImages = map[string][]ECRImage{}
+}
+
+// ECRRegistryID is the default registry ID for images in the fake ECR storage.
+const ECRRegistryID = "123456789012"
+
+// ECRClient provides a mock implementation of a cocoa.ECRClient. This makes it
+// possible to introspect on inputs to the client and control the client's
+// output. It provides some default implementations where possible. By
+// default, it will issue the API calls to the fake GlobalECRImages.
+type ECRClient struct {
+	BatchGetImageInput  *ecr.BatchGetImageInput
+	BatchGetImageOutput *ecr.BatchGetImageOutput
+	BatchGetImageError  error
+
+	CloseError error
+}
+
+// BatchGetImage saves the input and returns the matching images. The mock
+// output can be customized. By default, it will return the images in the
+// global fake ECR storage that match the image IDs and return a failure for
+// each image ID that does not match any image.
+func (c *ECRClient) BatchGetImage(ctx context.Context, in *ecr.BatchGetImageInput) (*ecr.BatchGetImageOutput, error) {
+	c.BatchGetImageInput = in
+
+	if c.BatchGetImageOutput != nil || c.BatchGetImageError != nil {
+		return c.BatchGetImageOutput, c.BatchGetImageError
+	}
+
+	repo := utility.FromStringPtr(in.RepositoryName)
+	if repo == "" {
+		return nil, awserr.New(ecr.ErrCodeInvalidParameterException, "missing repository name", nil)
+	}
+	if len(in.ImageIds) == 0 {
+		return nil, awserr.New(ecr.ErrCodeInvalidParameterException, "missing image IDs", nil)
+	}
+	images, ok := GlobalECRImages[repo]
+	if !ok {
+		return nil, awserr.New(ecr.ErrCodeRepositoryNotFoundException, "repository not found", nil)
+	}
+
+	registryID := utility.FromStringPtr(in.RegistryId)
+	if registryID == "" {
+		registryID = ECRRegistryID
+	}
+
+	out := &ecr.BatchGetImageOutput{}
+	for _, id := range in.ImageIds {
+		if id == nil {
+			continue
+		}
+		tag := utility.FromStringPtr(id.ImageTag)
+		digest := utility.FromStringPtr(id.ImageDigest)
+		if tag == "" && digest == "" {
+			out.Failures = append(out.Failures, &ecr.ImageFailure{
+				ImageId:       id,
+				FailureCode:   utility.ToStringPtr(ecr.ImageFailureCodeMissingDigestAndTag),
+				FailureReason: utility.ToStringPtr("Missing image digest and tag"),
+			})
+			continue
+		}
+
+		var found bool
+		for _, img := range images {
+			if tag != "" && !utility.StringSliceContains(img.Tags, tag) {
+				continue
+			}
+			if digest != "" && img.Digest != digest {
+				continue
+			}
+			out.Images = append(out.Images, img.export(registryID, repo, tag))
+			found = true
+			break
+		}
+		if !found {
+			out.Failures = append(out.Failures, &ecr.ImageFailure{
+				ImageId:       id,
+				FailureCode:   utility.ToStringPtr(ecr.ImageFailureCodeImageNotFound),
+				FailureReason: utility.ToStringPtr("Requested image not found"),
+			})
+		}
+	}
+
+	return out, nil
+}
+
+// Close closes the mock client. The mock output can be customized. By default,
+// it is a no-op that returns no error.
+func (c *ECRClient) Close(ctx context.Context) error {
+	if c.CloseError != nil {
+		return c.CloseError
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECRClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.ECRClient)(nil), &ECRClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer ResetGlobalECRImages()
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECRClient){
+		"BatchGetImageReturnsImageByTag": func(ctx context.Context, t *testing.T, c *ECRClient) {
+			out, err := c.BatchGetImage(ctx, &ecr.BatchGetImageInput{
+				RepositoryName: aws.String("app"),
+				ImageIds:       []*ecr.ImageIdentifier{{ImageTag: aws.String("v1")}},
+			})
+			require.NoError(t, err)
+			assert.Empty(t, out.Failures)
+			require.Len(t, out.Images, 1)
+			assert.Equal(t, "sha256:v1", utility.FromStringPtr(out.Images[0].ImageId.ImageDigest))
+			assert.Equal(t, "v1", utility.FromStringPtr(out.Images[0].ImageId.ImageTag))
+		},
+		"BatchGetImageReturnsImageByDigest": func(ctx context.Context, t *testing.T, c *ECRClient) {
+			out, err := c.BatchGetImage(ctx, &ecr.BatchGetImageInput{
+				RepositoryName: aws.String("app"),
+				ImageIds:       []*ecr.ImageIdentifier{{ImageDigest: aws.String("sha256:v2")}},
+			})
+			require.NoError(t, err)
+			require.Len(t, out.Images, 1)
+			assert.Equal(t, "sha256:v2", utility.FromStringPtr(out.Images[0].ImageId.ImageDigest))
+		},
+		"BatchGetImageReturnsFailureForNonexistentTag": func(ctx context.Context, t *testing.T, c *ECRClient) {
+			out, err := c.BatchGetImage(ctx, &ecr.BatchGetImageInput{
+				RepositoryName: aws.String("app"),
+				ImageIds:       []*ecr.ImageIdentifier{{ImageTag: aws.String("nonexistent")}},
+			})
+			require.NoError(t, err)
+			assert.Empty(t, out.Images)
+			require.Len(t, out.Failures, 1)
+			assert.Equal(t, ecr.ImageFailureCodeImageNotFound, utility.FromStringPtr(out.Failures[0].FailureCode))
+		},
+		"BatchGetImageFailsWithNonexistentRepository": func(ctx context.Context, t *testing.T, c *ECRClient) {
+			_, err := c.BatchGetImage(ctx, &ecr.BatchGetImageInput{
+				RepositoryName: aws.String("nonexistent"),
+				ImageIds:       []*ecr.ImageIdentifier{{ImageTag: aws.String("v1")}},
+			})
+			assert.Error(t, err)
+		},
+		"BatchGetImageFailsWithoutImageIDs": func(ctx context.Context, t *testing.T, c *ECRClient) {
+			_, err := c.BatchGetImage(ctx, &ecr.BatchGetImageInput{
+				RepositoryName: aws.String("app"),
+			})
+			assert.Error(t, err)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalECRImages()
+			GlobalECRImages["app"] = []ECRImage{
+				{Digest: "sha256:v1", Tags: []string{"v1", "latest"}},
+				{Digest: "sha256:v2", Tags: []string{"v2"}},
+			}
+
+			tCase(tctx, t, &ECRClient{})
+		})
+	}
+}
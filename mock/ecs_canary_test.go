@@ -0,0 +1,237 @@
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	awsECS "github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanaryDeployment(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const (
+		cluster     = "cluster"
+		serviceName = "service"
+	)
+
+	defer ResetGlobalECSService()
+
+	checkCanariesStopped := func(t *testing.T, expected int) {
+		var canaries int
+		for _, task := range GlobalECSService.Clusters[cluster] {
+			canaries++
+			assert.Equal(t, awsECS.DesiredStatusStopped, utility.FromStringPtr(task.GoalStatus), "canary task should be stopped")
+		}
+		assert.Equal(t, expected, canaries)
+	}
+	getService := func(t *testing.T) ECSServiceDeployment {
+		svc, ok := GlobalECSService.Services[cluster][serviceName]
+		require.True(t, ok)
+		return svc
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, d *ecs.CanaryDeployment, oldDef, newDef string){
+		"UpdatesServiceWithHealthyCanaries": func(ctx context.Context, t *testing.T, d *ecs.CanaryDeployment, oldDef, newDef string) {
+			var checked bool
+			require.NoError(t, d.Deploy(ctx, ecs.CanaryDeploymentOptions{
+				Cluster:        cluster,
+				Service:        serviceName,
+				TaskDefinition: newDef,
+				CanaryCount:    2,
+				HealthCheck: func(context.Context) error {
+					checked = true
+					return nil
+				},
+			}))
+
+			assert.True(t, checked, "health check should run")
+			svc := getService(t)
+			assert.Equal(t, newDef, utility.FromStringPtr(svc.TaskDefinition))
+			assert.Equal(t, 1, svc.Updates)
+			checkCanariesStopped(t, 2)
+		},
+		"UpdatesServiceWithoutHealthCheck": func(ctx context.Context, t *testing.T, d *ecs.CanaryDeployment, oldDef, newDef string) {
+			require.NoError(t, d.Deploy(ctx, ecs.CanaryDeploymentOptions{
+				Cluster:        cluster,
+				Service:        serviceName,
+				TaskDefinition: newDef,
+			}))
+
+			assert.Equal(t, newDef, utility.FromStringPtr(getService(t).TaskDefinition))
+			checkCanariesStopped(t, 1)
+		},
+		"RollsBackWhenHealthCheckFails": func(ctx context.Context, t *testing.T, d *ecs.CanaryDeployment, oldDef, newDef string) {
+			err := d.Deploy(ctx, ecs.CanaryDeploymentOptions{
+				Cluster:        cluster,
+				Service:        serviceName,
+				TaskDefinition: newDef,
+				CanaryCount:    3,
+				HealthCheck: func(context.Context) error {
+					return errors.New("smoke test failed")
+				},
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "smoke test failed")
+
+			svc := getService(t)
+			assert.Equal(t, oldDef, utility.FromStringPtr(svc.TaskDefinition), "service should not be updated")
+			assert.Zero(t, svc.Updates)
+			checkCanariesStopped(t, 3)
+		},
+		"RollsBackWhenCanariesDoNotStartInTime": func(ctx context.Context, t *testing.T, d *ecs.CanaryDeployment, oldDef, newDef string) {
+			GlobalECSService.TaskLifecycle.Clock = NewVirtualClock(time.Now())
+			GlobalECSService.TaskLifecycle.TransitionDelay = time.Minute
+
+			assert.Error(t, d.Deploy(ctx, ecs.CanaryDeploymentOptions{
+				Cluster:        cluster,
+				Service:        serviceName,
+				TaskDefinition: newDef,
+				StartTimeout:   50 * time.Millisecond,
+			}))
+
+			assert.Equal(t, oldDef, utility.FromStringPtr(getService(t).TaskDefinition), "service should not be updated")
+			checkCanariesStopped(t, 1)
+		},
+		"StopsCanariesWhenContextIsDone": func(ctx context.Context, t *testing.T, _ *ecs.CanaryDeployment, oldDef, newDef string) {
+			d, err := ecs.NewCanaryDeployment(&contextCheckingECSClient{})
+			require.NoError(t, err)
+			d.SetPollInterval(10 * time.Millisecond)
+
+			deployCtx, deployCancel := context.WithCancel(ctx)
+			defer deployCancel()
+			assert.Error(t, d.Deploy(deployCtx, ecs.CanaryDeploymentOptions{
+				Cluster:        cluster,
+				Service:        serviceName,
+				TaskDefinition: newDef,
+				HealthCheck: func(ctx context.Context) error {
+					deployCancel()
+					return ctx.Err()
+				},
+			}))
+
+			assert.Equal(t, oldDef, utility.FromStringPtr(getService(t).TaskDefinition), "service should not be updated")
+			checkCanariesStopped(t, 1)
+		},
+		"StopsCanariesWhenServiceUpdateFails": func(ctx context.Context, t *testing.T, d *ecs.CanaryDeployment, oldDef, newDef string) {
+			assert.Error(t, d.Deploy(ctx, ecs.CanaryDeploymentOptions{
+				Cluster:        cluster,
+				Service:        "nonexistent",
+				TaskDefinition: newDef,
+			}))
+
+			checkCanariesStopped(t, 1)
+		},
+		"FailsWithNonexistentTaskDefinition": func(ctx context.Context, t *testing.T, d *ecs.CanaryDeployment, oldDef, newDef string) {
+			assert.Error(t, d.Deploy(ctx, ecs.CanaryDeploymentOptions{
+				Cluster:        cluster,
+				Service:        serviceName,
+				TaskDefinition: "nonexistent:1",
+			}))
+
+			assert.Equal(t, oldDef, utility.FromStringPtr(getService(t).TaskDefinition), "service should not be updated")
+			checkCanariesStopped(t, 0)
+		},
+		"FailsWithInvalidOptions": func(ctx context.Context, t *testing.T, d *ecs.CanaryDeployment, oldDef, newDef string) {
+			assert.Error(t, d.Deploy(ctx, ecs.CanaryDeploymentOptions{
+				Cluster:        cluster,
+				TaskDefinition: newDef,
+			}))
+
+			checkCanariesStopped(t, 0)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalECSService()
+			GlobalECSService.Clusters[cluster] = ECSCluster{}
+			GlobalECSService.TaskLifecycle = &ECSTaskLifecycle{}
+
+			c := &ECSClient{}
+			oldDef := testutil.RegisterTaskDefinition(tctx, t, c, testutil.ValidRegisterTaskDefinitionInput(t))
+			newDef := testutil.RegisterTaskDefinition(tctx, t, c, testutil.ValidRegisterTaskDefinitionInput(t))
+			GlobalECSService.Services[cluster] = map[string]ECSServiceDeployment{
+				serviceName: {
+					ARN:            "arn:aws:ecs:us-east-1:123456789012:service/cluster/" + serviceName,
+					Name:           serviceName,
+					TaskDefinition: oldDef.TaskDefinition.TaskDefinitionArn,
+					DesiredCount:   utility.ToInt64Ptr(2),
+				},
+			}
+
+			d, err := ecs.NewCanaryDeployment(c)
+			require.NoError(t, err)
+			d.SetPollInterval(10 * time.Millisecond)
+
+			tCase(tctx, t, d, utility.FromStringPtr(oldDef.TaskDefinition.TaskDefinitionArn), utility.FromStringPtr(newDef.TaskDefinition.TaskDefinitionArn))
+		})
+	}
+}
+
+// contextCheckingECSClient is an ECS client that fails to stop tasks if the
+// context is done.
+type contextCheckingECSClient struct {
+	ECSClient
+}
+
+func (c *contextCheckingECSClient) StopTask(ctx context.Context, in *awsECS.StopTaskInput) (*awsECS.StopTaskOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ECSClient.StopTask(ctx, in)
+}
+
+func TestECSClientUpdateService(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	const cluster = "cluster"
+
+	ResetGlobalECSService()
+	defer ResetGlobalECSService()
+	GlobalECSService.Clusters[cluster] = ECSCluster{}
+
+	c := &ECSClient{}
+	def := testutil.RegisterTaskDefinition(ctx, t, c, testutil.ValidRegisterTaskDefinitionInput(t))
+	GlobalECSService.Services[cluster] = map[string]ECSServiceDeployment{
+		"service": {Name: "service"},
+	}
+
+	t.Run("UpdatesTaskDefinitionAndDesiredCount", func(t *testing.T) {
+		out, err := c.UpdateService(ctx, &awsECS.UpdateServiceInput{
+			Cluster:        utility.ToStringPtr(cluster),
+			Service:        utility.ToStringPtr("service"),
+			TaskDefinition: def.TaskDefinition.Family,
+			DesiredCount:   utility.ToInt64Ptr(3),
+		})
+		require.NoError(t, err)
+		require.NotZero(t, out.Service)
+		assert.Equal(t, utility.FromStringPtr(def.TaskDefinition.TaskDefinitionArn), utility.FromStringPtr(out.Service.TaskDefinition))
+		assert.EqualValues(t, 3, utility.FromInt64Ptr(out.Service.DesiredCount))
+	})
+	t.Run("FailsWithNonexistentService", func(t *testing.T) {
+		_, err := c.UpdateService(ctx, &awsECS.UpdateServiceInput{
+			Cluster: utility.ToStringPtr(cluster),
+			Service: utility.ToStringPtr("nonexistent"),
+		})
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithNonexistentTaskDefinition", func(t *testing.T) {
+		_, err := c.UpdateService(ctx, &awsECS.UpdateServiceInput{
+			Cluster:        utility.ToStringPtr(cluster),
+			Service:        utility.ToStringPtr("service"),
+			TaskDefinition: utility.ToStringPtr("nonexistent:1"),
+		})
+		assert.Error(t, err)
+	})
+}
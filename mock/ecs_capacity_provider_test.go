@@ -0,0 +1,197 @@
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequencedScalingActivitiesClient is an Auto Scaling client that returns each
+// output in the sequence on successive calls to DescribeScalingActivities.
+type sequencedScalingActivitiesClient struct {
+	AutoScalingClient
+	outputs []*autoscaling.DescribeScalingActivitiesOutput
+	calls   int
+}
+
+func (c *sequencedScalingActivitiesClient) DescribeScalingActivities(ctx context.Context, in *autoscaling.DescribeScalingActivitiesInput) (*autoscaling.DescribeScalingActivitiesOutput, error) {
+	if c.calls >= len(c.outputs) {
+		return nil, errors.New("no more outputs")
+	}
+	out := c.outputs[c.calls]
+	c.calls++
+	return out, nil
+}
+
+func TestPollCapacityProviderScalingActivity(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const (
+		provider = "provider"
+		group    = "group"
+	)
+
+	setupProvider := func() {
+		GlobalECSService.CapacityProviders[provider] = ECSCapacityProvider{
+			ARN:                 "arn:aws:ecs:us-east-1:123456789012:capacity-provider/" + provider,
+			Name:                provider,
+			Status:              aws.String("ACTIVE"),
+			AutoScalingGroupARN: aws.String("arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:01234567-89ab-cdef-0123-456789abcdef:autoScalingGroupName/" + group),
+		}
+	}
+
+	collectEvents := func(t *testing.T, events <-chan ecs.ScalingActivityEvent) []ecs.ScalingActivityEvent {
+		var collected []ecs.ScalingActivityEvent
+		for e := range events {
+			collected = append(collected, e)
+		}
+		return collected
+	}
+
+	activity := func(id, description, status string, start time.Time) *autoscaling.Activity {
+		return &autoscaling.Activity{
+			ActivityId:           aws.String(id),
+			AutoScalingGroupName: aws.String(group),
+			Description:          aws.String(description),
+			StatusCode:           aws.String(status),
+			StartTime:            aws.Time(start),
+		}
+	}
+
+	t.Run("FailsWithoutClients", func(t *testing.T) {
+		events, err := ecs.PollCapacityProviderScalingActivity(ctx, nil, &AutoScalingClient{}, provider, time.Millisecond)
+		assert.Error(t, err)
+		assert.Zero(t, events)
+
+		events, err = ecs.PollCapacityProviderScalingActivity(ctx, &ECSClient{}, nil, provider, time.Millisecond)
+		assert.Error(t, err)
+		assert.Zero(t, events)
+	})
+	t.Run("FailsWithNonexistentCapacityProvider", func(t *testing.T) {
+		ResetGlobalECSService()
+		defer ResetGlobalECSService()
+
+		events, err := ecs.PollCapacityProviderScalingActivity(ctx, &ECSClient{}, &AutoScalingClient{}, "nonexistent", time.Millisecond)
+		assert.Error(t, err)
+		assert.Zero(t, events)
+	})
+	t.Run("FailsWithCapacityProviderWithoutAutoScalingGroup", func(t *testing.T) {
+		ResetGlobalECSService()
+		defer ResetGlobalECSService()
+
+		GlobalECSService.CapacityProviders[provider] = ECSCapacityProvider{Name: provider}
+
+		events, err := ecs.PollCapacityProviderScalingActivity(ctx, &ECSClient{}, &AutoScalingClient{}, provider, time.Millisecond)
+		assert.Error(t, err)
+		assert.Zero(t, events)
+	})
+	t.Run("EmitsEventsForInProgressActivitiesInGlobalStorage", func(t *testing.T) {
+		ResetGlobalECSService()
+		defer ResetGlobalECSService()
+		ResetGlobalAutoScalingActivities()
+		defer ResetGlobalAutoScalingActivities()
+
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		setupProvider()
+		now := time.Now()
+		GlobalAutoScalingActivities[group] = []AutoScalingActivity{
+			{
+				ID:          "finished",
+				Description: "Terminating EC2 instance: i-0",
+				StatusCode:  autoscaling.ScalingActivityStatusCodeSuccessful,
+				StartTime:   now.Add(-time.Hour),
+			},
+			{
+				ID:          "in_progress",
+				Description: "Launching a new EC2 instance: i-1",
+				StatusCode:  autoscaling.ScalingActivityStatusCodeInProgress,
+				StartTime:   now,
+			},
+		}
+
+		events, err := ecs.PollCapacityProviderScalingActivity(tctx, &ECSClient{}, &AutoScalingClient{}, provider, time.Hour)
+		require.NoError(t, err)
+
+		e := <-events
+		assert.Equal(t, "in_progress", e.ActivityID)
+		assert.Equal(t, ecs.ScalingActivityScaleOut, e.Type)
+		assert.Equal(t, group, e.AutoScalingGroupName)
+		assert.Equal(t, autoscaling.ScalingActivityStatusCodeInProgress, e.StatusCode)
+		assert.NoError(t, e.Err)
+	})
+	t.Run("EmitsEventForEachNewActivityAndStatusChange", func(t *testing.T) {
+		ResetGlobalECSService()
+		defer ResetGlobalECSService()
+
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		setupProvider()
+		now := time.Now()
+		asc := &sequencedScalingActivitiesClient{outputs: []*autoscaling.DescribeScalingActivitiesOutput{
+			{Activities: []*autoscaling.Activity{
+				activity("old", "Terminating EC2 instance: i-0", autoscaling.ScalingActivityStatusCodeSuccessful, now.Add(-time.Hour)),
+			}},
+			{Activities: []*autoscaling.Activity{
+				activity("launch", "Launching a new EC2 instance: i-1", autoscaling.ScalingActivityStatusCodeWaitingForInstanceId, now),
+				activity("old", "Terminating EC2 instance: i-0", autoscaling.ScalingActivityStatusCodeSuccessful, now.Add(-time.Hour)),
+			}},
+			{Activities: []*autoscaling.Activity{
+				activity("launch", "Launching a new EC2 instance: i-1", autoscaling.ScalingActivityStatusCodeWaitingForInstanceId, now),
+				activity("old", "Terminating EC2 instance: i-0", autoscaling.ScalingActivityStatusCodeSuccessful, now.Add(-time.Hour)),
+			}},
+			{Activities: []*autoscaling.Activity{
+				activity("terminate", "Terminating EC2 instance: i-2", autoscaling.ScalingActivityStatusCodeInProgress, now.Add(time.Second)),
+				activity("launch", "Launching a new EC2 instance: i-1", autoscaling.ScalingActivityStatusCodeSuccessful, now),
+				activity("old", "Terminating EC2 instance: i-0", autoscaling.ScalingActivityStatusCodeSuccessful, now.Add(-time.Hour)),
+			}},
+		}}
+
+		events, err := ecs.PollCapacityProviderScalingActivity(tctx, &ECSClient{}, asc, provider, time.Millisecond)
+		require.NoError(t, err)
+
+		collected := collectEvents(t, events)
+		require.Len(t, collected, 4)
+
+		assert.Equal(t, "launch", collected[0].ActivityID)
+		assert.Equal(t, ecs.ScalingActivityScaleOut, collected[0].Type)
+		assert.Equal(t, autoscaling.ScalingActivityStatusCodeWaitingForInstanceId, collected[0].StatusCode)
+
+		assert.Equal(t, "launch", collected[1].ActivityID)
+		assert.Equal(t, autoscaling.ScalingActivityStatusCodeSuccessful, collected[1].StatusCode)
+
+		assert.Equal(t, "terminate", collected[2].ActivityID)
+		assert.Equal(t, ecs.ScalingActivityScaleIn, collected[2].Type)
+		assert.Equal(t, autoscaling.ScalingActivityStatusCodeInProgress, collected[2].StatusCode)
+
+		assert.Error(t, collected[3].Err, "should send an error once the scaling activities cannot be checked")
+	})
+	t.Run("ClosesChannelWhenContextIsDone", func(t *testing.T) {
+		ResetGlobalECSService()
+		defer ResetGlobalECSService()
+		ResetGlobalAutoScalingActivities()
+		defer ResetGlobalAutoScalingActivities()
+
+		tctx, tcancel := context.WithCancel(ctx)
+
+		setupProvider()
+
+		events, err := ecs.PollCapacityProviderScalingActivity(tctx, &ECSClient{}, &AutoScalingClient{}, provider, time.Hour)
+		require.NoError(t, err)
+
+		tcancel()
+
+		_, ok := <-events
+		assert.False(t, ok)
+	})
+}
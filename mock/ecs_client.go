@@ -3,8 +3,10 @@ package mock
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -27,6 +29,8 @@ type ECSTaskDefinition struct {
 	CPU           *string
 	TaskRole      *string
 	ExecutionRole *string
+	NetworkMode   *string
+	Compatibility []string
 	Tags          map[string]string
 	Status        *string
 	Registered    *time.Time
@@ -37,7 +41,7 @@ func newECSTaskDefinition(def *awsECS.RegisterTaskDefinitionInput, rev int) ECST
 	id := arn.ARN{
 		Partition: "aws",
 		Service:   "ecs",
-		Resource:  fmt.Sprintf("task-definition:%s/%s", utility.FromStringPtr(def.Family), strconv.Itoa(rev)),
+		Resource:  fmt.Sprintf("task-definition/%s:%s", utility.FromStringPtr(def.Family), strconv.Itoa(rev)),
 	}
 
 	taskDef := ECSTaskDefinition{
@@ -48,6 +52,8 @@ func newECSTaskDefinition(def *awsECS.RegisterTaskDefinitionInput, rev int) ECST
 		MemoryMB:      def.Memory,
 		TaskRole:      def.TaskRoleArn,
 		ExecutionRole: def.ExecutionRoleArn,
+		NetworkMode:   def.NetworkMode,
+		Compatibility: utility.FromStringPtrSlice(def.RequiresCompatibilities),
 		Status:        utility.ToStringPtr(awsECS.TaskDefinitionStatusActive),
 		Registered:    utility.ToTimePtr(time.Now()),
 	}
@@ -71,53 +77,66 @@ func (d *ECSTaskDefinition) export() *awsECS.TaskDefinition {
 	}
 
 	return &awsECS.TaskDefinition{
-		TaskDefinitionArn:    utility.ToStringPtr(d.ARN),
-		Family:               d.Family,
-		Revision:             d.Revision,
-		Cpu:                  d.CPU,
-		Memory:               d.MemoryMB,
-		TaskRoleArn:          d.TaskRole,
-		ExecutionRoleArn:     d.ExecutionRole,
-		Status:               d.Status,
-		ContainerDefinitions: containerDefs,
-		RegisteredAt:         d.Registered,
-		DeregisteredAt:       d.Deregistered,
+		TaskDefinitionArn:       utility.ToStringPtr(d.ARN),
+		Family:                  d.Family,
+		Revision:                d.Revision,
+		Cpu:                     d.CPU,
+		Memory:                  d.MemoryMB,
+		TaskRoleArn:             d.TaskRole,
+		ExecutionRoleArn:        d.ExecutionRole,
+		NetworkMode:             d.NetworkMode,
+		RequiresCompatibilities: utility.ToStringPtrSlice(d.Compatibility),
+		Status:                  d.Status,
+		ContainerDefinitions:    containerDefs,
+		RegisteredAt:            d.Registered,
+		DeregisteredAt:          d.Deregistered,
 	}
 }
 
 // ECSContainerDefinition represents a mock ECS container definition in a mock
 // ECS task definition.
 type ECSContainerDefinition struct {
-	Name     *string
-	Image    *string
-	Command  []string
-	MemoryMB *int64
-	CPU      *int64
-	EnvVars  map[string]string
-	Secrets  map[string]string
+	Name      *string
+	Image     *string
+	Command   []string
+	MemoryMB  *int64
+	CPU       *int64
+	Essential *bool
+	EnvVars   map[string]string
+	Secrets   map[string]string
+	LogConfig *awsECS.LogConfiguration
 }
 
 func newECSContainerDefinition(def *awsECS.ContainerDefinition) ECSContainerDefinition {
+	// ECS treats containers as essential unless specified otherwise.
+	essential := def.Essential
+	if essential == nil {
+		essential = utility.TruePtr()
+	}
 	return ECSContainerDefinition{
-		Name:     def.Name,
-		Image:    def.Image,
-		Command:  utility.FromStringPtrSlice(def.Command),
-		MemoryMB: def.Memory,
-		CPU:      def.Cpu,
-		EnvVars:  newEnvVars(def.Environment),
-		Secrets:  newSecrets(def.Secrets),
+		Name:      def.Name,
+		Image:     def.Image,
+		Command:   utility.FromStringPtrSlice(def.Command),
+		MemoryMB:  def.Memory,
+		CPU:       def.Cpu,
+		Essential: essential,
+		EnvVars:   newEnvVars(def.Environment),
+		Secrets:   newSecrets(def.Secrets),
+		LogConfig: def.LogConfiguration,
 	}
 }
 
 func (d *ECSContainerDefinition) export() *awsECS.ContainerDefinition {
 	return &awsECS.ContainerDefinition{
-		Name:        d.Name,
-		Image:       d.Image,
-		Command:     utility.ToStringPtrSlice(d.Command),
-		Memory:      d.MemoryMB,
-		Cpu:         d.CPU,
-		Environment: exportEnvVars(d.EnvVars),
-		Secrets:     exportSecrets(d.Secrets),
+		Name:             d.Name,
+		Image:            d.Image,
+		Command:          utility.ToStringPtrSlice(d.Command),
+		Memory:           d.MemoryMB,
+		Cpu:              d.CPU,
+		Essential:        d.Essential,
+		Environment:      exportEnvVars(d.EnvVars),
+		Secrets:          exportSecrets(d.Secrets),
+		LogConfiguration: d.LogConfig,
 	}
 }
 
@@ -141,6 +160,7 @@ type ECSTask struct {
 	Created           *time.Time
 	StopCode          *string
 	StopReason        *string
+	Stopping          *time.Time
 	Stopped           *time.Time
 	Tags              map[string]string
 }
@@ -149,7 +169,7 @@ func newECSTask(in *awsECS.RunTaskInput, taskDef ECSTaskDefinition) ECSTask {
 	id := arn.ARN{
 		Partition: "aws",
 		Service:   "ecs",
-		Resource:  fmt.Sprintf("task:%s/%s", utility.FromStringPtr(taskDef.Family), strconv.Itoa(int(utility.FromInt64Ptr(taskDef.Revision)))),
+		Resource:  fmt.Sprintf("task/%s/%s", utility.FromStringPtr(in.Cluster), utility.RandomString()),
 	}
 
 	t := ECSTask{
@@ -189,6 +209,7 @@ func (t *ECSTask) export(includeTags bool) *awsECS.Task {
 		CreatedAt:            t.Created,
 		StopCode:             t.StopCode,
 		StoppedReason:        t.StopReason,
+		StoppingAt:           t.Stopping,
 		StoppedAt:            t.Stopped,
 	}
 	if includeTags {
@@ -212,6 +233,7 @@ type ECSContainer struct {
 	MemoryMB   *int64
 	Status     *string
 	GoalStatus *string
+	ExitCode   *int64
 }
 
 func newECSContainer(def ECSContainerDefinition, task ECSTask) ECSContainer {
@@ -244,6 +266,7 @@ func (c *ECSContainer) export() *awsECS.Container {
 		Name:         c.Name,
 		Image:        c.Image,
 		LastStatus:   c.Status,
+		ExitCode:     c.ExitCode,
 	}
 
 	if c.CPU != nil {
@@ -320,6 +343,148 @@ func exportSecrets(secrets map[string]string) []*awsECS.Secret {
 	return exported
 }
 
+// ECSContainerInstance represents a mock container instance registered to a
+// cluster.
+type ECSContainerInstance struct {
+	ARN            string
+	EC2InstanceID  *string
+	Status         *string
+	AgentConnected *bool
+	AgentVersion   *string
+	Registered     *time.Time
+	// RegisteredCPU and RegisteredMemoryMiB are the total CPU units and memory
+	// that the container instance registered with ECS.
+	RegisteredCPU       *int64
+	RegisteredMemoryMiB *int64
+	// RemainingCPU and RemainingMemoryMiB are the CPU units and memory that
+	// are not yet reserved by tasks on the container instance.
+	RemainingCPU       *int64
+	RemainingMemoryMiB *int64
+}
+
+func (i *ECSContainerInstance) export() *awsECS.ContainerInstance {
+	exported := &awsECS.ContainerInstance{
+		ContainerInstanceArn: utility.ToStringPtr(i.ARN),
+		Ec2InstanceId:        i.EC2InstanceID,
+		Status:               i.Status,
+		AgentConnected:       i.AgentConnected,
+		RegisteredAt:         i.Registered,
+	}
+	if i.AgentVersion != nil {
+		exported.VersionInfo = &awsECS.VersionInfo{AgentVersion: i.AgentVersion}
+	}
+	exported.RegisteredResources = exportResources(i.RegisteredCPU, i.RegisteredMemoryMiB)
+	exported.RemainingResources = exportResources(i.RemainingCPU, i.RemainingMemoryMiB)
+	return exported
+}
+
+// exportResources exports the CPU and memory as container instance resources.
+func exportResources(cpu, memoryMiB *int64) []*awsECS.Resource {
+	var resources []*awsECS.Resource
+	if cpu != nil {
+		resources = append(resources, &awsECS.Resource{
+			Name:         utility.ToStringPtr("CPU"),
+			Type:         utility.ToStringPtr("INTEGER"),
+			IntegerValue: cpu,
+		})
+	}
+	if memoryMiB != nil {
+		resources = append(resources, &awsECS.Resource{
+			Name:         utility.ToStringPtr("MEMORY"),
+			Type:         utility.ToStringPtr("INTEGER"),
+			IntegerValue: memoryMiB,
+		})
+	}
+	return resources
+}
+
+// ECSClusterMetadata represents the configuration of a mock cluster.
+type ECSClusterMetadata struct {
+	Tags                            map[string]string
+	Settings                        []*awsECS.ClusterSetting
+	Configuration                   *awsECS.ClusterConfiguration
+	CapacityProviders               []string
+	DefaultCapacityProviderStrategy []*awsECS.CapacityProviderStrategyItem
+}
+
+// ECSServiceDeployment represents a mock ECS service that runs tasks from a
+// task definition in a cluster.
+type ECSServiceDeployment struct {
+	ARN                      string
+	Name                     string
+	TaskDefinition           *string
+	DesiredCount             *int64
+	LaunchType               *string
+	CapacityProviderStrategy []*awsECS.CapacityProviderStrategyItem
+	NetworkConfiguration     *awsECS.NetworkConfiguration
+	Role                     *string
+	Tags                     map[string]string
+	// Updates is the number of times that the service has been updated.
+	Updates int
+}
+
+func newECSServiceDeployment(cluster string, in *awsECS.CreateServiceInput, taskDef ECSTaskDefinition) ECSServiceDeployment {
+	id := arn.ARN{
+		Partition: "aws",
+		Service:   "ecs",
+		Resource:  fmt.Sprintf("service/%s/%s", cluster, utility.FromStringPtr(in.ServiceName)),
+	}
+
+	return ECSServiceDeployment{
+		ARN:                      id.String(),
+		Name:                     utility.FromStringPtr(in.ServiceName),
+		TaskDefinition:           utility.ToStringPtr(taskDef.ARN),
+		DesiredCount:             in.DesiredCount,
+		LaunchType:               in.LaunchType,
+		CapacityProviderStrategy: in.CapacityProviderStrategy,
+		NetworkConfiguration:     in.NetworkConfiguration,
+		Role:                     in.Role,
+		Tags:                     newECSTags(in.Tags),
+	}
+}
+
+func (d *ECSServiceDeployment) export(cluster string, includeTags bool) *awsECS.Service {
+	exported := &awsECS.Service{
+		ServiceArn:               utility.ToStringPtr(d.ARN),
+		ServiceName:              utility.ToStringPtr(d.Name),
+		ClusterArn:               utility.ToStringPtr(cluster),
+		TaskDefinition:           d.TaskDefinition,
+		DesiredCount:             d.DesiredCount,
+		LaunchType:               d.LaunchType,
+		CapacityProviderStrategy: d.CapacityProviderStrategy,
+		NetworkConfiguration:     d.NetworkConfiguration,
+		RoleArn:                  d.Role,
+		Status:                   utility.ToStringPtr("ACTIVE"),
+	}
+	if includeTags && len(d.Tags) != 0 {
+		exported.Tags = ecs.ExportTags(d.Tags)
+	}
+	return exported
+}
+
+// ECSCapacityProvider represents a mock capacity provider backed by an Auto
+// Scaling group.
+type ECSCapacityProvider struct {
+	ARN                 string
+	Name                string
+	Status              *string
+	AutoScalingGroupARN *string
+}
+
+func (p *ECSCapacityProvider) export() *awsECS.CapacityProvider {
+	exported := &awsECS.CapacityProvider{
+		CapacityProviderArn: utility.ToStringPtr(p.ARN),
+		Name:                utility.ToStringPtr(p.Name),
+		Status:              p.Status,
+	}
+	if p.AutoScalingGroupARN != nil {
+		exported.AutoScalingGroupProvider = &awsECS.AutoScalingGroupProvider{
+			AutoScalingGroupArn: p.AutoScalingGroupARN,
+		}
+	}
+	return exported
+}
+
 // ECSService is a global implementation of ECS that provides a simplified
 // in-memory implementation of the service that only stores metadata and does
 // not orchestrate real containers or container instances. This can be used
@@ -328,6 +493,27 @@ func exportSecrets(secrets map[string]string) []*awsECS.Secret {
 type ECSService struct {
 	Clusters map[string]ECSCluster
 	TaskDefs map[string][]ECSTaskDefinition
+	// ContainerInstances maps each cluster name to its container instances,
+	// keyed by container instance ARN.
+	ContainerInstances map[string]map[string]ECSContainerInstance
+	// AccountSettings maps each account setting name to its value.
+	AccountSettings map[string]string
+	// CapacityProviders maps each capacity provider name to the capacity
+	// provider.
+	CapacityProviders map[string]ECSCapacityProvider
+	// ClusterMetadata maps each cluster name to its configuration. Clusters
+	// that do not have any metadata have the default configuration.
+	ClusterMetadata map[string]ECSClusterMetadata
+	// Services maps each cluster name to its services, keyed by service name.
+	Services map[string]map[string]ECSServiceDeployment
+	// TaskLifecycle simulates the task lifecycle if it is set. If it is not
+	// set, tasks are pending once they are started and are stopped as soon as
+	// they are requested to stop.
+	TaskLifecycle *ECSTaskLifecycle
+	// SimulatePlacement makes RunTask check that tasks using the EC2 launch
+	// type can be placed on one of the cluster's container instances. If it
+	// is not set, tasks are always placed.
+	SimulatePlacement bool
 }
 
 // GlobalECSService represents the global fake ECS service state.
@@ -341,8 +527,13 @@ func init() {
 // initialized but clean state.
 func ResetGlobalECSService() {
 	GlobalECSService = ECSService{
-		Clusters: map[string]ECSCluster{},
-		TaskDefs: map[string][]ECSTaskDefinition{},
+		Clusters:           map[string]ECSCluster{},
+		TaskDefs:           map[string][]ECSTaskDefinition{},
+		ContainerInstances: map[string]map[string]ECSContainerInstance{},
+		AccountSettings:    map[string]string{},
+		CapacityProviders:  map[string]ECSCapacityProvider{},
+		ClusterMetadata:    map[string]ECSClusterMetadata{},
+		Services:           map[string]map[string]ECSServiceDeployment{},
 	}
 }
 
@@ -419,6 +610,23 @@ func parseFamilyAndRevision(taskDef string) (family string, revNum int, err erro
 	return family, revNum, nil
 }
 
+// countContainerInstanceTasks returns the number of running and pending tasks
+// placed on the container instance in the cluster.
+func (s *ECSService) countContainerInstanceTasks(cluster, instanceARN string) (running, pending int64) {
+	for _, task := range s.Clusters[cluster] {
+		if utility.FromStringPtr(task.ContainerInstance) != instanceARN {
+			continue
+		}
+		switch utility.FromStringPtr(task.Status) {
+		case awsECS.DesiredStatusRunning:
+			running++
+		case awsECS.DesiredStatusPending:
+			pending++
+		}
+	}
+	return running, pending
+}
+
 func (s *ECSService) taskDefIndexFromARN(arn string) (family string, revNum int, found bool) {
 	for family, revisions := range GlobalECSService.TaskDefs {
 		for revIdx, def := range revisions {
@@ -430,10 +638,56 @@ func (s *ECSService) taskDefIndexFromARN(arn string) (family string, revNum int,
 	return "", -1, false
 }
 
+// placementFailureReason returns the reason that a task using the task
+// definition cannot be placed on any of the cluster's container instances. If
+// the task can be placed, this returns an empty string. Container instances
+// that do not track their remaining resources are assumed to have enough room
+// for any task. The reasons match the ones returned by ECS.
+func (s *ECSService) placementFailureReason(cluster string, def ECSTaskDefinition) string {
+	instances := s.ContainerInstances[cluster]
+	if len(instances) == 0 {
+		return "No Container Instances were found in your cluster."
+	}
+
+	cpu, _ := strconv.ParseInt(utility.FromStringPtr(def.CPU), 10, 64)
+	memMiB, _ := strconv.ParseInt(utility.FromStringPtr(def.MemoryMB), 10, 64)
+
+	var reason string
+	for _, instance := range instances {
+		if instance.RemainingCPU != nil && *instance.RemainingCPU < cpu {
+			reason = "RESOURCE:CPU"
+			continue
+		}
+		if instance.RemainingMemoryMiB != nil && *instance.RemainingMemoryMiB < memMiB {
+			reason = "RESOURCE:MEMORY"
+			continue
+		}
+		return ""
+	}
+
+	return reason
+}
+
+// findService finds the service in the cluster by name or ARN.
+func (s *ECSService) findService(cluster, id string) (ECSServiceDeployment, bool) {
+	for name, svc := range s.Services[cluster] {
+		if name == id || svc.ARN == id {
+			return svc, true
+		}
+	}
+	return ECSServiceDeployment{}, false
+}
+
+// globalECSServiceMu synchronizes the ECSClient's access to the fake
+// GlobalECSService and to its own inputs.
+var globalECSServiceMu sync.Mutex
+
 // ECSClient provides a mock implementation of a cocoa.ECSClient. This makes
 // it possible to introspect on inputs to the client and control the client's
 // output. It provides some default implementations where possible. By default,
-// it will issue the API calls to the fake GlobalECSService.
+// it will issue the API calls to the fake GlobalECSService. Its methods are
+// safe for concurrent use, but accessing its fields or the GlobalECSService
+// directly while API calls are in progress is not.
 type ECSClient struct {
 	RegisterTaskDefinitionInput  *awsECS.RegisterTaskDefinitionInput
 	RegisterTaskDefinitionOutput *awsECS.RegisterTaskDefinitionOutput
@@ -471,6 +725,62 @@ type ECSClient struct {
 	TagResourceOutput *awsECS.TagResourceOutput
 	TagResourceError  error
 
+	ListContainerInstancesInput  *awsECS.ListContainerInstancesInput
+	ListContainerInstancesOutput *awsECS.ListContainerInstancesOutput
+	ListContainerInstancesError  error
+
+	DescribeContainerInstancesInput  *awsECS.DescribeContainerInstancesInput
+	DescribeContainerInstancesOutput *awsECS.DescribeContainerInstancesOutput
+	DescribeContainerInstancesError  error
+
+	DescribeClustersInput  *awsECS.DescribeClustersInput
+	DescribeClustersOutput *awsECS.DescribeClustersOutput
+	DescribeClustersError  error
+
+	ListAccountSettingsInput  *awsECS.ListAccountSettingsInput
+	ListAccountSettingsOutput *awsECS.ListAccountSettingsOutput
+	ListAccountSettingsError  error
+
+	DescribeCapacityProvidersInput  *awsECS.DescribeCapacityProvidersInput
+	DescribeCapacityProvidersOutput *awsECS.DescribeCapacityProvidersOutput
+	DescribeCapacityProvidersError  error
+
+	ListTaskDefinitionFamiliesInput  *awsECS.ListTaskDefinitionFamiliesInput
+	ListTaskDefinitionFamiliesOutput *awsECS.ListTaskDefinitionFamiliesOutput
+	ListTaskDefinitionFamiliesError  error
+
+	CreateClusterInput  *awsECS.CreateClusterInput
+	CreateClusterOutput *awsECS.CreateClusterOutput
+	CreateClusterError  error
+
+	CreateServiceInput  *awsECS.CreateServiceInput
+	CreateServiceOutput *awsECS.CreateServiceOutput
+	CreateServiceError  error
+
+	ListServicesInput  *awsECS.ListServicesInput
+	ListServicesOutput *awsECS.ListServicesOutput
+	ListServicesError  error
+
+	DescribeServicesInput  *awsECS.DescribeServicesInput
+	DescribeServicesOutput *awsECS.DescribeServicesOutput
+	DescribeServicesError  error
+
+	UpdateServiceInput  *awsECS.UpdateServiceInput
+	UpdateServiceOutput *awsECS.UpdateServiceOutput
+	UpdateServiceError  error
+
+	RegisterContainerInstanceInput  *awsECS.RegisterContainerInstanceInput
+	RegisterContainerInstanceOutput *awsECS.RegisterContainerInstanceOutput
+	RegisterContainerInstanceError  error
+
+	DeregisterContainerInstanceInput  *awsECS.DeregisterContainerInstanceInput
+	DeregisterContainerInstanceOutput *awsECS.DeregisterContainerInstanceOutput
+	DeregisterContainerInstanceError  error
+
+	UpdateContainerInstancesStateInput  *awsECS.UpdateContainerInstancesStateInput
+	UpdateContainerInstancesStateOutput *awsECS.UpdateContainerInstancesStateOutput
+	UpdateContainerInstancesStateError  error
+
 	CloseError error
 }
 
@@ -478,6 +788,9 @@ type ECSClient struct {
 // definition. The mock output can be customized. By default, it will create a
 // cached task definition based on the input.
 func (c *ECSClient) RegisterTaskDefinition(ctx context.Context, in *awsECS.RegisterTaskDefinitionInput) (*awsECS.RegisterTaskDefinitionOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
 	c.RegisterTaskDefinitionInput = in
 
 	if c.RegisterTaskDefinitionOutput != nil || c.RegisterTaskDefinitionError != nil {
@@ -505,6 +818,9 @@ func (c *ECSClient) RegisterTaskDefinition(ctx context.Context, in *awsECS.Regis
 // matching task definition. The mock output can be customized. By default, it
 // will return the task definition information if it exists.
 func (c *ECSClient) DescribeTaskDefinition(ctx context.Context, in *awsECS.DescribeTaskDefinitionInput) (*awsECS.DescribeTaskDefinitionOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
 	c.DescribeTaskDefinitionInput = in
 
 	if c.DescribeTaskDefinitionOutput != nil || c.DescribeTaskDefinitionError != nil {
@@ -530,15 +846,19 @@ func (c *ECSClient) DescribeTaskDefinition(ctx context.Context, in *awsECS.Descr
 
 // ListTaskDefinitions saves the input and lists all matching task definitions.
 // The mock output can be customized. By default, it will list all cached task
-// definitions that match the input filters.
+// definitions that match the input filters, sorted by family and revision. If
+// the maximum number of results is set, it paginates the results.
 func (c *ECSClient) ListTaskDefinitions(ctx context.Context, in *awsECS.ListTaskDefinitionsInput) (*awsECS.ListTaskDefinitionsOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
 	c.ListTaskDefinitionsInput = in
 
 	if c.ListTaskDefinitionsOutput != nil || c.ListTaskDefinitionsError != nil {
 		return c.ListTaskDefinitionsOutput, c.ListTaskDefinitionsError
 	}
 
-	var arns []string
+	var matching []ECSTaskDefinition
 	for _, revisions := range GlobalECSService.TaskDefs {
 		for _, def := range revisions {
 			if in.FamilyPrefix != nil && utility.FromStringPtr(def.Family) != *in.FamilyPrefix {
@@ -548,19 +868,47 @@ func (c *ECSClient) ListTaskDefinitions(ctx context.Context, in *awsECS.ListTask
 				continue
 			}
 
-			arns = append(arns, def.ARN)
+			matching = append(matching, def)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if utility.FromStringPtr(matching[i].Family) != utility.FromStringPtr(matching[j].Family) {
+			return utility.FromStringPtr(matching[i].Family) < utility.FromStringPtr(matching[j].Family)
+		}
+		return utility.FromInt64Ptr(matching[i].Revision) < utility.FromInt64Ptr(matching[j].Revision)
+	})
+
+	start := 0
+	if in.NextToken != nil {
+		var err error
+		start, err = strconv.Atoi(*in.NextToken)
+		if err != nil || start < 0 || start > len(matching) {
+			return nil, awserr.New(awsECS.ErrCodeInvalidParameterException, "invalid next token", err)
 		}
 	}
+	end := len(matching)
+	if in.MaxResults != nil && start+int(*in.MaxResults) < end {
+		end = start + int(*in.MaxResults)
+	}
 
-	return &awsECS.ListTaskDefinitionsOutput{
-		TaskDefinitionArns: utility.ToStringPtrSlice(arns),
-	}, nil
+	out := &awsECS.ListTaskDefinitionsOutput{}
+	for _, def := range matching[start:end] {
+		out.TaskDefinitionArns = append(out.TaskDefinitionArns, utility.ToStringPtr(def.ARN))
+	}
+	if end < len(matching) {
+		out.NextToken = utility.ToStringPtr(strconv.Itoa(end))
+	}
+
+	return out, nil
 }
 
 // DeregisterTaskDefinition saves the input and deletes an existing mock task
 // definition. The mock output can be customized. By default, it will delete a
 // cached task definition if it exists.
 func (c *ECSClient) DeregisterTaskDefinition(ctx context.Context, in *awsECS.DeregisterTaskDefinitionInput) (*awsECS.DeregisterTaskDefinitionOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
 	c.DeregisterTaskDefinitionInput = in
 
 	if c.DeregisterTaskDefinitionOutput != nil || c.DeregisterTaskDefinitionError != nil {
@@ -589,8 +937,15 @@ func (c *ECSClient) DeregisterTaskDefinition(ctx context.Context, in *awsECS.Der
 
 // RunTask saves the input options and returns the mock result of running a task
 // definition. The mock output can be customized. By default, it will create
-// mock output based on the input.
+// mock output based on the input, starting as many tasks as the input's count.
+// If placement is simulated and the task uses the EC2 launch type, it returns a
+// failure instead when no container instance in the cluster has enough
+// remaining resources for the task. It does not reserve resources on the
+// container instance that the task is placed on.
 func (c *ECSClient) RunTask(ctx context.Context, in *awsECS.RunTaskInput) (*awsECS.RunTaskOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
 	c.RunTaskInput = in
 
 	if c.RunTaskOutput != nil || c.RunTaskError != nil {
@@ -614,12 +969,32 @@ func (c *ECSClient) RunTask(ctx context.Context, in *awsECS.RunTaskInput) (*awsE
 		return nil, awserr.New(awsECS.ErrCodeResourceNotFoundException, "task definition not found", err)
 	}
 
-	task := newECSTask(in, *def)
+	if GlobalECSService.SimulatePlacement && utility.FromStringPtr(in.LaunchType) == awsECS.LaunchTypeEc2 {
+		if reason := GlobalECSService.placementFailureReason(clusterName, *def); reason != "" {
+			return &awsECS.RunTaskOutput{
+				Failures: []*awsECS.Failure{{Reason: utility.ToStringPtr(reason)}},
+			}, nil
+		}
+	}
 
-	cluster[task.ARN] = task
+	count := int(utility.FromInt64Ptr(in.Count))
+	if count < 1 {
+		count = 1
+	}
+
+	var tasks []*awsECS.Task
+	for i := 0; i < count; i++ {
+		task := newECSTask(in, *def)
+		if GlobalECSService.TaskLifecycle != nil {
+			GlobalECSService.TaskLifecycle.startTask(&task)
+		}
+
+		cluster[task.ARN] = task
+		tasks = append(tasks, task.export(true))
+	}
 
 	return &awsECS.RunTaskOutput{
-		Tasks: []*awsECS.Task{task.export(true)},
+		Tasks: tasks,
 	}, nil
 }
 
@@ -634,12 +1009,17 @@ func (c *ECSClient) getOrDefaultCluster(name *string) string {
 // tasks. The mock output can be customized. By default, it will describe all
 // cached tasks that match.
 func (c *ECSClient) DescribeTasks(ctx context.Context, in *awsECS.DescribeTasksInput) (*awsECS.DescribeTasksOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
 	c.DescribeTasksInput = in
 
 	if c.DescribeTasksOutput != nil || c.DescribeTasksError != nil {
 		return c.DescribeTasksOutput, c.DescribeTasksError
 	}
 
+	GlobalECSService.updateTaskLifecycles()
+
 	cluster, ok := GlobalECSService.Clusters[c.getOrDefaultCluster(in.Cluster)]
 	if !ok {
 		return nil, awserr.New(awsECS.ErrCodeResourceNotFoundException, "cluster not found", nil)
@@ -688,12 +1068,17 @@ func shouldIncludeTags(includes []*string) bool {
 // be customized. By default, it will list all cached task definitions that
 // match the input filters.
 func (c *ECSClient) ListTasks(ctx context.Context, in *awsECS.ListTasksInput) (*awsECS.ListTasksOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
 	c.ListTasksInput = in
 
 	if c.ListTasksOutput != nil || c.ListTasksError != nil {
 		return c.ListTasksOutput, c.ListTasksError
 	}
 
+	GlobalECSService.updateTaskLifecycles()
+
 	cluster, ok := GlobalECSService.Clusters[c.getOrDefaultCluster(in.Cluster)]
 	if !ok {
 		return &awsECS.ListTasksOutput{}, nil
@@ -723,8 +1108,12 @@ func (c *ECSClient) ListTasks(ctx context.Context, in *awsECS.ListTasksInput) (*
 
 // StopTask saves the input and stops a mock task. The mock output can be
 // customized. By default, it will mark a cached task as stopped if it exists
-// and is running.
+// and is running. If the global ECS service simulates the task lifecycle, the
+// task begins transitioning to stopped instead.
 func (c *ECSClient) StopTask(ctx context.Context, in *awsECS.StopTaskInput) (*awsECS.StopTaskOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
 	c.StopTaskInput = in
 
 	if c.StopTaskOutput != nil || c.StopTaskError != nil {
@@ -738,16 +1127,21 @@ func (c *ECSClient) StopTask(ctx context.Context, in *awsECS.StopTaskInput) (*aw
 
 	task, ok := cluster[utility.FromStringPtr(in.Task)]
 	if !ok {
-		return nil, cocoa.NewECSTaskNotFoundError(utility.FromStringPtr(in.Task))
+		return nil, cocoa.NewECSTaskNotFoundError(utility.FromStringPtr(in.Task), nil)
 	}
 
-	task.Status = utility.ToStringPtr(awsECS.DesiredStatusStopped)
 	task.GoalStatus = utility.ToStringPtr(awsECS.DesiredStatusStopped)
 	task.StopCode = utility.ToStringPtr(awsECS.TaskStopCodeUserInitiated)
 	task.StopReason = in.Reason
-	task.Stopped = utility.ToTimePtr(time.Now())
-	for i := range task.Containers {
-		task.Containers[i].Status = utility.ToStringPtr(awsECS.DesiredStatusStopped)
+	if GlobalECSService.TaskLifecycle != nil {
+		GlobalECSService.TaskLifecycle.updateTask(&task)
+		GlobalECSService.TaskLifecycle.stopTask(&task)
+	} else {
+		task.Status = utility.ToStringPtr(awsECS.DesiredStatusStopped)
+		task.Stopped = utility.ToTimePtr(time.Now())
+		for i := range task.Containers {
+			task.Containers[i].Status = utility.ToStringPtr(awsECS.DesiredStatusStopped)
+		}
 	}
 
 	cluster[utility.FromStringPtr(in.Task)] = task
@@ -761,6 +1155,9 @@ func (c *ECSClient) StopTask(ctx context.Context, in *awsECS.StopTaskInput) (*aw
 // output can be customized. By default, it will add the tag to the resource if
 // it exists.
 func (c *ECSClient) TagResource(ctx context.Context, in *awsECS.TagResourceInput) (*awsECS.TagResourceOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
 	c.TagResourceInput = in
 
 	if c.TagResourceOutput != nil || c.TagResourceError != nil {
@@ -792,9 +1189,644 @@ func (c *ECSClient) TagResource(ctx context.Context, in *awsECS.TagResourceInput
 	return nil, awserr.New(awsECS.ErrCodeResourceNotFoundException, "task or task definition not found", nil)
 }
 
+// ListContainerInstances saves the input and lists all matching container
+// instances. The mock output can be customized. By default, it will list all
+// cached container instances in the cluster that match the status filter.
+func (c *ECSClient) ListContainerInstances(ctx context.Context, in *awsECS.ListContainerInstancesInput) (*awsECS.ListContainerInstancesOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.ListContainerInstancesInput = in
+
+	if c.ListContainerInstancesOutput != nil || c.ListContainerInstancesError != nil {
+		return c.ListContainerInstancesOutput, c.ListContainerInstancesError
+	}
+
+	clusterName := c.getOrDefaultCluster(in.Cluster)
+	if _, ok := GlobalECSService.Clusters[clusterName]; !ok {
+		return nil, awserr.New(awsECS.ErrCodeClusterNotFoundException, "cluster not found", nil)
+	}
+
+	var arns []string
+	for arn, instance := range GlobalECSService.ContainerInstances[clusterName] {
+		if in.Status != nil && utility.FromStringPtr(instance.Status) != *in.Status {
+			continue
+		}
+		arns = append(arns, arn)
+	}
+
+	return &awsECS.ListContainerInstancesOutput{
+		ContainerInstanceArns: utility.ToStringPtrSlice(arns),
+	}, nil
+}
+
+// DescribeContainerInstances saves the input and returns information about the
+// existing container instances. The mock output can be customized. By default,
+// it will describe all cached container instances that match.
+func (c *ECSClient) DescribeContainerInstances(ctx context.Context, in *awsECS.DescribeContainerInstancesInput) (*awsECS.DescribeContainerInstancesOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.DescribeContainerInstancesInput = in
+
+	if c.DescribeContainerInstancesOutput != nil || c.DescribeContainerInstancesError != nil {
+		return c.DescribeContainerInstancesOutput, c.DescribeContainerInstancesError
+	}
+
+	clusterName := c.getOrDefaultCluster(in.Cluster)
+	if _, ok := GlobalECSService.Clusters[clusterName]; !ok {
+		return nil, awserr.New(awsECS.ErrCodeClusterNotFoundException, "cluster not found", nil)
+	}
+
+	var instances []*awsECS.ContainerInstance
+	var failures []*awsECS.Failure
+	for _, arn := range utility.FromStringPtrSlice(in.ContainerInstances) {
+		instance, ok := GlobalECSService.ContainerInstances[clusterName][arn]
+		if !ok {
+			failures = append(failures, &awsECS.Failure{
+				Arn: utility.ToStringPtr(arn),
+				// ECS uses the same failure reason for any resource that
+				// cannot be found.
+				Reason: utility.ToStringPtr(ecs.ReasonTaskMissing),
+			})
+			continue
+		}
+		exported := instance.export()
+		running, pending := GlobalECSService.countContainerInstanceTasks(clusterName, arn)
+		exported.RunningTasksCount = utility.ToInt64Ptr(running)
+		exported.PendingTasksCount = utility.ToInt64Ptr(pending)
+		instances = append(instances, exported)
+	}
+
+	return &awsECS.DescribeContainerInstancesOutput{
+		ContainerInstances: instances,
+		Failures:           failures,
+	}, nil
+}
+
+// RegisterContainerInstance saves the input and registers a new container
+// instance in the cluster. The mock output can be customized. By default, it
+// will add a new active container instance to the global ECS service with the
+// resources given in the input. If the input specifies the ARN of an existing
+// container instance, it re-registers that container instance instead.
+func (c *ECSClient) RegisterContainerInstance(ctx context.Context, in *awsECS.RegisterContainerInstanceInput) (*awsECS.RegisterContainerInstanceOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.RegisterContainerInstanceInput = in
+
+	if c.RegisterContainerInstanceOutput != nil || c.RegisterContainerInstanceError != nil {
+		return c.RegisterContainerInstanceOutput, c.RegisterContainerInstanceError
+	}
+
+	clusterName := c.getOrDefaultCluster(in.Cluster)
+	if _, ok := GlobalECSService.Clusters[clusterName]; !ok {
+		return nil, awserr.New(awsECS.ErrCodeClusterNotFoundException, "cluster not found", nil)
+	}
+
+	instanceARN := utility.FromStringPtr(in.ContainerInstanceArn)
+	if instanceARN == "" {
+		instanceARN = arn.ARN{
+			Partition: "aws",
+			Service:   "ecs",
+			Resource:  fmt.Sprintf("container-instance/%s/%s", clusterName, utility.RandomString()),
+		}.String()
+	}
+
+	instance := ECSContainerInstance{
+		ARN:            instanceARN,
+		Status:         utility.ToStringPtr(awsECS.ContainerInstanceStatusActive),
+		AgentConnected: utility.TruePtr(),
+		Registered:     utility.ToTimePtr(time.Now()),
+	}
+	if in.VersionInfo != nil {
+		instance.AgentVersion = in.VersionInfo.AgentVersion
+	}
+	for _, r := range in.TotalResources {
+		if r == nil || r.IntegerValue == nil {
+			continue
+		}
+		val := utility.FromInt64Ptr(r.IntegerValue)
+		switch utility.FromStringPtr(r.Name) {
+		case "CPU":
+			instance.RegisteredCPU = utility.ToInt64Ptr(val)
+			instance.RemainingCPU = utility.ToInt64Ptr(val)
+		case "MEMORY":
+			instance.RegisteredMemoryMiB = utility.ToInt64Ptr(val)
+			instance.RemainingMemoryMiB = utility.ToInt64Ptr(val)
+		}
+	}
+
+	if GlobalECSService.ContainerInstances[clusterName] == nil {
+		GlobalECSService.ContainerInstances[clusterName] = map[string]ECSContainerInstance{}
+	}
+	GlobalECSService.ContainerInstances[clusterName][instanceARN] = instance
+
+	return &awsECS.RegisterContainerInstanceOutput{
+		ContainerInstance: instance.export(),
+	}, nil
+}
+
+// UpdateContainerInstancesState saves the input and updates the status of the
+// container instances. The mock output can be customized. By default, it will
+// set the status of the cached container instances in the cluster. Container
+// instances that do not exist are returned as failures. Unlike ECS, draining a
+// container instance does not stop any of its tasks.
+func (c *ECSClient) UpdateContainerInstancesState(ctx context.Context, in *awsECS.UpdateContainerInstancesStateInput) (*awsECS.UpdateContainerInstancesStateOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.UpdateContainerInstancesStateInput = in
+
+	if c.UpdateContainerInstancesStateOutput != nil || c.UpdateContainerInstancesStateError != nil {
+		return c.UpdateContainerInstancesStateOutput, c.UpdateContainerInstancesStateError
+	}
+
+	status := utility.FromStringPtr(in.Status)
+	if status != awsECS.ContainerInstanceStatusActive && status != awsECS.ContainerInstanceStatusDraining {
+		return nil, awserr.New(awsECS.ErrCodeInvalidParameterException, "status must be ACTIVE or DRAINING", nil)
+	}
+
+	clusterName := c.getOrDefaultCluster(in.Cluster)
+	if _, ok := GlobalECSService.Clusters[clusterName]; !ok {
+		return nil, awserr.New(awsECS.ErrCodeClusterNotFoundException, "cluster not found", nil)
+	}
+
+	var instances []*awsECS.ContainerInstance
+	var failures []*awsECS.Failure
+	for _, arn := range utility.FromStringPtrSlice(in.ContainerInstances) {
+		instance, ok := GlobalECSService.ContainerInstances[clusterName][arn]
+		if !ok {
+			failures = append(failures, &awsECS.Failure{
+				Arn:    utility.ToStringPtr(arn),
+				Reason: utility.ToStringPtr(ecs.ReasonTaskMissing),
+			})
+			continue
+		}
+		instance.Status = utility.ToStringPtr(status)
+		GlobalECSService.ContainerInstances[clusterName][arn] = instance
+		instances = append(instances, instance.export())
+	}
+
+	return &awsECS.UpdateContainerInstancesStateOutput{
+		ContainerInstances: instances,
+		Failures:           failures,
+	}, nil
+}
+
+// DeregisterContainerInstance saves the input and deregisters the container
+// instance from the cluster. The mock output can be customized. By default, it
+// will mark the container instance as inactive in the global ECS service. Like
+// ECS, it fails if the container instance has tasks that are not stopped
+// unless the deregistration is forced.
+func (c *ECSClient) DeregisterContainerInstance(ctx context.Context, in *awsECS.DeregisterContainerInstanceInput) (*awsECS.DeregisterContainerInstanceOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.DeregisterContainerInstanceInput = in
+
+	if c.DeregisterContainerInstanceOutput != nil || c.DeregisterContainerInstanceError != nil {
+		return c.DeregisterContainerInstanceOutput, c.DeregisterContainerInstanceError
+	}
+
+	clusterName := c.getOrDefaultCluster(in.Cluster)
+	cluster, ok := GlobalECSService.Clusters[clusterName]
+	if !ok {
+		return nil, awserr.New(awsECS.ErrCodeClusterNotFoundException, "cluster not found", nil)
+	}
+
+	instanceARN := utility.FromStringPtr(in.ContainerInstance)
+	instance, ok := GlobalECSService.ContainerInstances[clusterName][instanceARN]
+	if !ok {
+		return nil, awserr.New(awsECS.ErrCodeInvalidParameterException, "container instance not found", nil)
+	}
+
+	if !utility.FromBoolPtr(in.Force) {
+		for _, task := range cluster {
+			if utility.FromStringPtr(task.ContainerInstance) == instanceARN && utility.FromStringPtr(task.Status) != awsECS.DesiredStatusStopped {
+				return nil, awserr.New(awsECS.ErrCodeInvalidParameterException, "container instance has tasks that are not stopped", nil)
+			}
+		}
+	}
+
+	// ECS does not define a constant for the status of deregistered container
+	// instances.
+	instance.Status = utility.ToStringPtr("INACTIVE")
+	instance.AgentConnected = utility.FalsePtr()
+	GlobalECSService.ContainerInstances[clusterName][instanceARN] = instance
+
+	return &awsECS.DeregisterContainerInstanceOutput{
+		ContainerInstance: instance.export(),
+	}, nil
+}
+
+// DescribeClusters saves the input and returns information about the existing
+// clusters. The mock output can be customized. By default, it will describe
+// all cached clusters that match.
+func (c *ECSClient) DescribeClusters(ctx context.Context, in *awsECS.DescribeClustersInput) (*awsECS.DescribeClustersOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.DescribeClustersInput = in
+
+	if c.DescribeClustersOutput != nil || c.DescribeClustersError != nil {
+		return c.DescribeClustersOutput, c.DescribeClustersError
+	}
+
+	names := utility.FromStringPtrSlice(in.Clusters)
+	if len(names) == 0 {
+		names = []string{c.getOrDefaultCluster(nil)}
+	}
+
+	var clusters []*awsECS.Cluster
+	var failures []*awsECS.Failure
+	for _, name := range names {
+		cluster, ok := GlobalECSService.Clusters[name]
+		if !ok {
+			failures = append(failures, &awsECS.Failure{
+				Arn:    utility.ToStringPtr(name),
+				Reason: utility.ToStringPtr(ecs.ReasonTaskMissing),
+			})
+			continue
+		}
+
+		var numActiveInstances int64
+		for _, instance := range GlobalECSService.ContainerInstances[name] {
+			if utility.FromStringPtr(instance.Status) == awsECS.ContainerInstanceStatusActive {
+				numActiveInstances++
+			}
+		}
+		var numRunning, numPending int64
+		for _, task := range cluster {
+			switch utility.FromStringPtr(task.Status) {
+			case awsECS.DesiredStatusRunning:
+				numRunning++
+			case awsECS.DesiredStatusPending:
+				numPending++
+			}
+		}
+
+		metadata := GlobalECSService.ClusterMetadata[name]
+		exported := &awsECS.Cluster{
+			ClusterName:                       utility.ToStringPtr(name),
+			Status:                            utility.ToStringPtr("ACTIVE"),
+			RegisteredContainerInstancesCount: utility.ToInt64Ptr(numActiveInstances),
+			RunningTasksCount:                 utility.ToInt64Ptr(numRunning),
+			PendingTasksCount:                 utility.ToInt64Ptr(numPending),
+			CapacityProviders:                 utility.ToStringPtrSlice(metadata.CapacityProviders),
+			DefaultCapacityProviderStrategy:   metadata.DefaultCapacityProviderStrategy,
+		}
+		for _, include := range utility.FromStringPtrSlice(in.Include) {
+			switch include {
+			case awsECS.ClusterFieldTags:
+				exported.Tags = ecs.ExportTags(metadata.Tags)
+			case awsECS.ClusterFieldSettings:
+				exported.Settings = metadata.Settings
+			case awsECS.ClusterFieldConfigurations:
+				exported.Configuration = metadata.Configuration
+			}
+		}
+		clusters = append(clusters, exported)
+	}
+
+	return &awsECS.DescribeClustersOutput{
+		Clusters: clusters,
+		Failures: failures,
+	}, nil
+}
+
+// ListAccountSettings saves the input and lists the account settings. The mock
+// output can be customized. By default, it will list all cached account
+// settings that match.
+func (c *ECSClient) ListAccountSettings(ctx context.Context, in *awsECS.ListAccountSettingsInput) (*awsECS.ListAccountSettingsOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.ListAccountSettingsInput = in
+
+	if c.ListAccountSettingsOutput != nil || c.ListAccountSettingsError != nil {
+		return c.ListAccountSettingsOutput, c.ListAccountSettingsError
+	}
+
+	var settings []*awsECS.Setting
+	for name, val := range GlobalECSService.AccountSettings {
+		if in.Name != nil && *in.Name != name {
+			continue
+		}
+		if in.Value != nil && *in.Value != val {
+			continue
+		}
+		settings = append(settings, &awsECS.Setting{
+			Name:  utility.ToStringPtr(name),
+			Value: utility.ToStringPtr(val),
+		})
+	}
+
+	return &awsECS.ListAccountSettingsOutput{
+		Settings: settings,
+	}, nil
+}
+
+// DescribeCapacityProviders saves the input and returns information about the
+// matching capacity providers. The mock output can be customized. By default,
+// it will return information about the cached capacity providers that match by
+// name or ARN, or all cached capacity providers if none are specified.
+func (c *ECSClient) DescribeCapacityProviders(ctx context.Context, in *awsECS.DescribeCapacityProvidersInput) (*awsECS.DescribeCapacityProvidersOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.DescribeCapacityProvidersInput = in
+
+	if c.DescribeCapacityProvidersOutput != nil || c.DescribeCapacityProvidersError != nil {
+		return c.DescribeCapacityProvidersOutput, c.DescribeCapacityProvidersError
+	}
+
+	var out awsECS.DescribeCapacityProvidersOutput
+	if len(in.CapacityProviders) == 0 {
+		for _, p := range GlobalECSService.CapacityProviders {
+			out.CapacityProviders = append(out.CapacityProviders, p.export())
+		}
+		return &out, nil
+	}
+
+	for _, id := range utility.FromStringPtrSlice(in.CapacityProviders) {
+		p, ok := findECSCapacityProvider(id)
+		if !ok {
+			out.Failures = append(out.Failures, &awsECS.Failure{
+				Arn:    utility.ToStringPtr(id),
+				Reason: utility.ToStringPtr(ecs.ReasonTaskMissing),
+			})
+			continue
+		}
+		out.CapacityProviders = append(out.CapacityProviders, p.export())
+	}
+
+	return &out, nil
+}
+
+// findECSCapacityProvider finds a cached capacity provider by name or ARN.
+func findECSCapacityProvider(id string) (*ECSCapacityProvider, bool) {
+	if p, ok := GlobalECSService.CapacityProviders[id]; ok {
+		return &p, true
+	}
+	for _, p := range GlobalECSService.CapacityProviders {
+		if p.ARN == id {
+			return &p, true
+		}
+	}
+	return nil, false
+}
+
+// ListTaskDefinitionFamilies saves the input and lists the task definition
+// families. The mock output can be customized. By default, it will list the
+// cached task definition families that match the prefix. Like ECS, filtering by
+// the ACTIVE status matches families that have at least one active revision,
+// while filtering by the INACTIVE status matches families that have no active
+// revisions. It does not paginate results.
+func (c *ECSClient) ListTaskDefinitionFamilies(ctx context.Context, in *awsECS.ListTaskDefinitionFamiliesInput) (*awsECS.ListTaskDefinitionFamiliesOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.ListTaskDefinitionFamiliesInput = in
+
+	if c.ListTaskDefinitionFamiliesOutput != nil || c.ListTaskDefinitionFamiliesError != nil {
+		return c.ListTaskDefinitionFamiliesOutput, c.ListTaskDefinitionFamiliesError
+	}
+
+	var families []string
+	for family, revisions := range GlobalECSService.TaskDefs {
+		if in.FamilyPrefix != nil && !strings.HasPrefix(family, *in.FamilyPrefix) {
+			continue
+		}
+
+		var hasActive bool
+		for _, def := range revisions {
+			if utility.FromStringPtr(def.Status) == awsECS.TaskDefinitionStatusActive {
+				hasActive = true
+				break
+			}
+		}
+		switch utility.FromStringPtr(in.Status) {
+		case awsECS.TaskDefinitionFamilyStatusActive:
+			if !hasActive {
+				continue
+			}
+		case awsECS.TaskDefinitionFamilyStatusInactive:
+			if hasActive {
+				continue
+			}
+		}
+
+		families = append(families, family)
+	}
+	sort.Strings(families)
+
+	return &awsECS.ListTaskDefinitionFamiliesOutput{
+		Families: utility.ToStringPtrSlice(families),
+	}, nil
+}
+
+// CreateService saves the input and creates a new mock service. The mock
+// output can be customized. By default, it will create a cached service in the
+// cluster that runs the task definition. It does not start any tasks for the
+// service.
+func (c *ECSClient) CreateService(ctx context.Context, in *awsECS.CreateServiceInput) (*awsECS.CreateServiceOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.CreateServiceInput = in
+
+	if c.CreateServiceOutput != nil || c.CreateServiceError != nil {
+		return c.CreateServiceOutput, c.CreateServiceError
+	}
+
+	if in.ServiceName == nil {
+		return nil, awserr.New(awsECS.ErrCodeInvalidParameterException, "missing service name", nil)
+	}
+
+	clusterName := c.getOrDefaultCluster(in.Cluster)
+	if _, ok := GlobalECSService.Clusters[clusterName]; !ok {
+		return nil, awserr.New(awsECS.ErrCodeClusterNotFoundException, "cluster not found", nil)
+	}
+	if _, ok := GlobalECSService.Services[clusterName][*in.ServiceName]; ok {
+		return nil, awserr.New(awsECS.ErrCodeInvalidParameterException, "service already exists", nil)
+	}
+
+	def, err := GlobalECSService.getLatestTaskDefinition(utility.FromStringPtr(in.TaskDefinition))
+	if err != nil {
+		return nil, awserr.New(awsECS.ErrCodeClientException, "task definition not found", err)
+	}
+
+	svc := newECSServiceDeployment(clusterName, in, *def)
+	if GlobalECSService.Services[clusterName] == nil {
+		GlobalECSService.Services[clusterName] = map[string]ECSServiceDeployment{}
+	}
+	GlobalECSService.Services[clusterName][svc.Name] = svc
+
+	return &awsECS.CreateServiceOutput{
+		Service: svc.export(clusterName, true),
+	}, nil
+}
+
+// ListServices saves the input and lists the services in a cluster. The mock
+// output can be customized. By default, it will list the ARNs of all cached
+// services in the cluster, sorted by name.
+func (c *ECSClient) ListServices(ctx context.Context, in *awsECS.ListServicesInput) (*awsECS.ListServicesOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.ListServicesInput = in
+
+	if c.ListServicesOutput != nil || c.ListServicesError != nil {
+		return c.ListServicesOutput, c.ListServicesError
+	}
+
+	clusterName := c.getOrDefaultCluster(in.Cluster)
+	if _, ok := GlobalECSService.Clusters[clusterName]; !ok {
+		return nil, awserr.New(awsECS.ErrCodeClusterNotFoundException, "cluster not found", nil)
+	}
+
+	names := make([]string, 0, len(GlobalECSService.Services[clusterName]))
+	for name := range GlobalECSService.Services[clusterName] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := &awsECS.ListServicesOutput{}
+	for _, name := range names {
+		out.ServiceArns = append(out.ServiceArns, utility.ToStringPtr(GlobalECSService.Services[clusterName][name].ARN))
+	}
+	return out, nil
+}
+
+// DescribeServices saves the input and describes the services in a cluster.
+// The mock output can be customized. By default, it will describe the matching
+// cached services by name or ARN, and return a failure for each service that
+// does not exist.
+func (c *ECSClient) DescribeServices(ctx context.Context, in *awsECS.DescribeServicesInput) (*awsECS.DescribeServicesOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.DescribeServicesInput = in
+
+	if c.DescribeServicesOutput != nil || c.DescribeServicesError != nil {
+		return c.DescribeServicesOutput, c.DescribeServicesError
+	}
+
+	clusterName := c.getOrDefaultCluster(in.Cluster)
+	if _, ok := GlobalECSService.Clusters[clusterName]; !ok {
+		return nil, awserr.New(awsECS.ErrCodeClusterNotFoundException, "cluster not found", nil)
+	}
+
+	includeTags := utility.StringSliceContains(utility.FromStringPtrSlice(in.Include), awsECS.ServiceFieldTags)
+
+	out := &awsECS.DescribeServicesOutput{}
+	for _, id := range utility.FromStringPtrSlice(in.Services) {
+		svc, ok := GlobalECSService.findService(clusterName, id)
+		if !ok {
+			out.Failures = append(out.Failures, &awsECS.Failure{
+				Arn:    utility.ToStringPtr(id),
+				Reason: utility.ToStringPtr("MISSING"),
+			})
+			continue
+		}
+		out.Services = append(out.Services, svc.export(clusterName, includeTags))
+	}
+	return out, nil
+}
+
+// UpdateService saves the input and updates an existing mock service. The mock
+// output can be customized. By default, it will update the task definition
+// and desired count of a cached service if it exists.
+func (c *ECSClient) UpdateService(ctx context.Context, in *awsECS.UpdateServiceInput) (*awsECS.UpdateServiceOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.UpdateServiceInput = in
+
+	if c.UpdateServiceOutput != nil || c.UpdateServiceError != nil {
+		return c.UpdateServiceOutput, c.UpdateServiceError
+	}
+
+	if in.Service == nil {
+		return nil, awserr.New(awsECS.ErrCodeInvalidParameterException, "missing service", nil)
+	}
+
+	clusterName := c.getOrDefaultCluster(in.Cluster)
+	if _, ok := GlobalECSService.Clusters[clusterName]; !ok {
+		return nil, awserr.New(awsECS.ErrCodeClusterNotFoundException, "cluster not found", nil)
+	}
+	svc, ok := GlobalECSService.Services[clusterName][*in.Service]
+	if !ok {
+		return nil, awserr.New(awsECS.ErrCodeServiceNotFoundException, "service not found", nil)
+	}
+
+	if in.TaskDefinition != nil {
+		def, err := GlobalECSService.getLatestTaskDefinition(*in.TaskDefinition)
+		if err != nil {
+			return nil, awserr.New(awsECS.ErrCodeClientException, "task definition not found", err)
+		}
+		svc.TaskDefinition = utility.ToStringPtr(def.ARN)
+	}
+	if in.DesiredCount != nil {
+		svc.DesiredCount = in.DesiredCount
+	}
+	svc.Updates++
+	GlobalECSService.Services[clusterName][*in.Service] = svc
+
+	return &awsECS.UpdateServiceOutput{
+		Service: svc.export(clusterName, false),
+	}, nil
+}
+
+// CreateCluster saves the input and creates a new mock cluster. The mock
+// output can be customized. By default, it will create a cached cluster with
+// the given configuration.
+func (c *ECSClient) CreateCluster(ctx context.Context, in *awsECS.CreateClusterInput) (*awsECS.CreateClusterOutput, error) {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
+	c.CreateClusterInput = in
+
+	if c.CreateClusterOutput != nil || c.CreateClusterError != nil {
+		return c.CreateClusterOutput, c.CreateClusterError
+	}
+
+	name := utility.FromStringPtr(in.ClusterName)
+	if name == "" {
+		name = "default"
+	}
+	if _, ok := GlobalECSService.Clusters[name]; !ok {
+		GlobalECSService.Clusters[name] = ECSCluster{}
+	}
+	GlobalECSService.ClusterMetadata[name] = ECSClusterMetadata{
+		Tags:                            newECSTags(in.Tags),
+		Settings:                        in.Settings,
+		Configuration:                   in.Configuration,
+		CapacityProviders:               utility.FromStringPtrSlice(in.CapacityProviders),
+		DefaultCapacityProviderStrategy: in.DefaultCapacityProviderStrategy,
+	}
+
+	return &awsECS.CreateClusterOutput{
+		Cluster: &awsECS.Cluster{
+			ClusterArn:                      utility.ToStringPtr(arn.ARN{Partition: "aws", Service: "ecs", Resource: fmt.Sprintf("cluster/%s", name)}.String()),
+			ClusterName:                     utility.ToStringPtr(name),
+			Status:                          utility.ToStringPtr("ACTIVE"),
+			Tags:                            in.Tags,
+			Settings:                        in.Settings,
+			Configuration:                   in.Configuration,
+			CapacityProviders:               in.CapacityProviders,
+			DefaultCapacityProviderStrategy: in.DefaultCapacityProviderStrategy,
+		},
+	}, nil
+}
+
 // Close closes the mock client. The mock output can be customized. By default,
 // it is a no-op that returns no error.
 func (c *ECSClient) Close(ctx context.Context) error {
+	globalECSServiceMu.Lock()
+	defer globalECSServiceMu.Unlock()
+
 	if c.CloseError != nil {
 		return c.CloseError
 	}
@@ -5,10 +5,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	awsECS "github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/evergreen-ci/cocoa"
 	"github.com/evergreen-ci/cocoa/internal/testcase"
 	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/evergreen-ci/utility"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // defaultTestTimeout is the default test timeout for mock tests.
@@ -33,6 +37,9 @@ func TestECSClient(t *testing.T) {
 			defer tcancel()
 
 			resetECSAndSecretsManagerCache()
+			// The shared tests include placement failures, which the mock
+			// only returns when placement is simulated.
+			GlobalECSService.SimulatePlacement = true
 
 			tCase(tctx, t, c)
 		})
@@ -48,3 +55,328 @@ func TestECSClient(t *testing.T) {
 		})
 	}
 }
+
+func TestECSClientListTaskDefinitionFamilies(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	ResetGlobalECSService()
+	defer ResetGlobalECSService()
+
+	c := &ECSClient{}
+	for _, family := range []string{"prefix-active", "prefix-inactive", "other"} {
+		out, err := c.RegisterTaskDefinition(ctx, testutil.GenerateTaskDefinitionFixture(family, "image"))
+		require.NoError(t, err)
+		if family == "prefix-inactive" {
+			_, err = c.DeregisterTaskDefinition(ctx, &awsECS.DeregisterTaskDefinitionInput{
+				TaskDefinition: out.TaskDefinition.TaskDefinitionArn,
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	for tName, tCase := range map[string]struct {
+		in       *awsECS.ListTaskDefinitionFamiliesInput
+		expected []string
+	}{
+		"ListsAllFamiliesByDefault": {
+			in:       &awsECS.ListTaskDefinitionFamiliesInput{},
+			expected: []string{"other", "prefix-active", "prefix-inactive"},
+		},
+		"FiltersByPrefix": {
+			in:       &awsECS.ListTaskDefinitionFamiliesInput{FamilyPrefix: aws.String("prefix")},
+			expected: []string{"prefix-active", "prefix-inactive"},
+		},
+		"FiltersByActiveStatus": {
+			in: &awsECS.ListTaskDefinitionFamiliesInput{
+				FamilyPrefix: aws.String("prefix"),
+				Status:       aws.String(awsECS.TaskDefinitionFamilyStatusActive),
+			},
+			expected: []string{"prefix-active"},
+		},
+		"FiltersByInactiveStatus": {
+			in: &awsECS.ListTaskDefinitionFamiliesInput{
+				Status: aws.String(awsECS.TaskDefinitionFamilyStatusInactive),
+			},
+			expected: []string{"prefix-inactive"},
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			out, err := c.ListTaskDefinitionFamilies(ctx, tCase.in)
+			require.NoError(t, err)
+			assert.Equal(t, tCase.expected, utility.FromStringPtrSlice(out.Families))
+		})
+	}
+}
+
+func TestECSClientRunTaskPlacement(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	const instanceARN = "arn:aws:ecs:us-east-1:123456789012:container-instance/cluster/instance"
+
+	for tName, tCase := range map[string]struct {
+		simulatePlacement bool
+		remainingCPU      int64
+		remainingMemMiB   int64
+		expectedReason    string
+	}{
+		"SucceedsWithEnoughResources": {
+			simulatePlacement: true,
+			remainingCPU:      1024,
+			remainingMemMiB:   2048,
+		},
+		"FailsWithInsufficientCPU": {
+			simulatePlacement: true,
+			remainingCPU:      64,
+			remainingMemMiB:   2048,
+			expectedReason:    "RESOURCE:CPU",
+		},
+		"FailsWithInsufficientMemory": {
+			simulatePlacement: true,
+			remainingCPU:      1024,
+			remainingMemMiB:   128,
+			expectedReason:    "RESOURCE:MEMORY",
+		},
+		"SucceedsWithInsufficientResourcesWithoutSimulatedPlacement": {
+			remainingCPU:    64,
+			remainingMemMiB: 128,
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			resetECSAndSecretsManagerCache()
+			defer resetECSAndSecretsManagerCache()
+
+			GlobalECSService.ContainerInstances[testutil.ECSClusterName()] = map[string]ECSContainerInstance{
+				instanceARN: {
+					ARN:                instanceARN,
+					Status:             aws.String("ACTIVE"),
+					RemainingCPU:       aws.Int64(tCase.remainingCPU),
+					RemainingMemoryMiB: aws.Int64(tCase.remainingMemMiB),
+				},
+			}
+
+			GlobalECSService.SimulatePlacement = tCase.simulatePlacement
+
+			c := &ECSClient{}
+			registerOut := testutil.RegisterTaskDefinition(ctx, t, c, testutil.ValidRegisterTaskDefinitionInput(t))
+
+			out, err := c.RunTask(ctx, &awsECS.RunTaskInput{
+				Cluster:        aws.String(testutil.ECSClusterName()),
+				LaunchType:     aws.String(awsECS.LaunchTypeEc2),
+				TaskDefinition: registerOut.TaskDefinition.TaskDefinitionArn,
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			if tCase.expectedReason == "" {
+				assert.Empty(t, out.Failures)
+				assert.Len(t, out.Tasks, 1)
+				return
+			}
+			assert.Empty(t, out.Tasks)
+			require.Len(t, out.Failures, 1)
+			assert.Equal(t, tCase.expectedReason, utility.FromStringPtr(out.Failures[0].Reason))
+		})
+	}
+}
+
+func TestECSClientContainerInstanceRegistration(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	register := func(ctx context.Context, t *testing.T, c *ECSClient) *awsECS.ContainerInstance {
+		out, err := c.RegisterContainerInstance(ctx, &awsECS.RegisterContainerInstanceInput{
+			Cluster: aws.String(testutil.ECSClusterName()),
+			TotalResources: []*awsECS.Resource{
+				{Name: aws.String("CPU"), Type: aws.String("INTEGER"), IntegerValue: aws.Int64(2048)},
+				{Name: aws.String("MEMORY"), Type: aws.String("INTEGER"), IntegerValue: aws.Int64(4096)},
+			},
+			VersionInfo: &awsECS.VersionInfo{AgentVersion: aws.String("1.2.3")},
+		})
+		require.NoError(t, err)
+		require.NotZero(t, out.ContainerInstance)
+		return out.ContainerInstance
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECSClient){
+		"RegisterContainerInstanceAddsActiveInstance": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			instance := register(ctx, t, c)
+			assert.Equal(t, awsECS.ContainerInstanceStatusActive, utility.FromStringPtr(instance.Status))
+			assert.Equal(t, "1.2.3", utility.FromStringPtr(instance.VersionInfo.AgentVersion))
+
+			out, err := c.DescribeContainerInstances(ctx, &awsECS.DescribeContainerInstancesInput{
+				Cluster:            aws.String(testutil.ECSClusterName()),
+				ContainerInstances: []*string{instance.ContainerInstanceArn},
+			})
+			require.NoError(t, err)
+			require.Len(t, out.ContainerInstances, 1)
+			assert.Equal(t, instance.ContainerInstanceArn, out.ContainerInstances[0].ContainerInstanceArn)
+			require.Len(t, out.ContainerInstances[0].RegisteredResources, 2)
+		},
+		"RegisterContainerInstanceFailsWithNonexistentCluster": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			_, err := c.RegisterContainerInstance(ctx, &awsECS.RegisterContainerInstanceInput{
+				Cluster: aws.String("nonexistent"),
+			})
+			assert.Error(t, err)
+		},
+		"DeregisterContainerInstanceMarksInstanceInactive": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			instance := register(ctx, t, c)
+
+			out, err := c.DeregisterContainerInstance(ctx, &awsECS.DeregisterContainerInstanceInput{
+				Cluster:           aws.String(testutil.ECSClusterName()),
+				ContainerInstance: instance.ContainerInstanceArn,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "INACTIVE", utility.FromStringPtr(out.ContainerInstance.Status))
+
+			listOut, err := c.ListContainerInstances(ctx, &awsECS.ListContainerInstancesInput{
+				Cluster: aws.String(testutil.ECSClusterName()),
+				Status:  aws.String(awsECS.ContainerInstanceStatusActive),
+			})
+			require.NoError(t, err)
+			assert.Empty(t, listOut.ContainerInstanceArns)
+		},
+		"DeregisterContainerInstanceFailsWithRunningTasksUnlessForced": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			instance := register(ctx, t, c)
+			arn := utility.FromStringPtr(instance.ContainerInstanceArn)
+			GlobalECSService.Clusters[testutil.ECSClusterName()]["task"] = ECSTask{
+				ARN:               "task",
+				ContainerInstance: aws.String(arn),
+				Status:            aws.String(awsECS.DesiredStatusRunning),
+			}
+
+			_, err := c.DeregisterContainerInstance(ctx, &awsECS.DeregisterContainerInstanceInput{
+				Cluster:           aws.String(testutil.ECSClusterName()),
+				ContainerInstance: aws.String(arn),
+			})
+			assert.Error(t, err)
+
+			_, err = c.DeregisterContainerInstance(ctx, &awsECS.DeregisterContainerInstanceInput{
+				Cluster:           aws.String(testutil.ECSClusterName()),
+				ContainerInstance: aws.String(arn),
+				Force:             aws.Bool(true),
+			})
+			assert.NoError(t, err)
+		},
+		"DeregisterContainerInstanceFailsWithNonexistentInstance": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			_, err := c.DeregisterContainerInstance(ctx, &awsECS.DeregisterContainerInstanceInput{
+				Cluster:           aws.String(testutil.ECSClusterName()),
+				ContainerInstance: aws.String("nonexistent"),
+			})
+			assert.Error(t, err)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+			defer resetECSAndSecretsManagerCache()
+
+			tCase(tctx, t, &ECSClient{})
+		})
+	}
+}
+
+func TestECSClientConcurrentUse(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const cluster = "cluster"
+
+	ResetGlobalECSService()
+	defer ResetGlobalECSService()
+	GlobalECSService.Clusters[cluster] = ECSCluster{}
+
+	c := &ECSClient{}
+	defer func() {
+		assert.NoError(t, c.Close(ctx))
+	}()
+
+	runTaskLifecycle := func(ctx context.Context, t *testing.T, family string) {
+		registerOut, err := c.RegisterTaskDefinition(ctx, testutil.GenerateTaskDefinitionFixture(family, "image"))
+		require.NoError(t, err)
+
+		runOut, err := c.RunTask(ctx, &awsECS.RunTaskInput{
+			Cluster:        aws.String(cluster),
+			TaskDefinition: registerOut.TaskDefinition.TaskDefinitionArn,
+		})
+		require.NoError(t, err)
+		require.Len(t, runOut.Tasks, 1)
+		taskARN := runOut.Tasks[0].TaskArn
+
+		describeOut, err := c.DescribeTasks(ctx, &awsECS.DescribeTasksInput{
+			Cluster: aws.String(cluster),
+			Tasks:   []*string{taskARN},
+		})
+		require.NoError(t, err)
+		require.Len(t, describeOut.Tasks, 1)
+
+		_, err = c.ListTasks(ctx, &awsECS.ListTasksInput{Cluster: aws.String(cluster)})
+		require.NoError(t, err)
+
+		_, err = c.StopTask(ctx, &awsECS.StopTaskInput{
+			Cluster: aws.String(cluster),
+			Task:    taskARN,
+		})
+		require.NoError(t, err)
+
+		_, err = c.DeregisterTaskDefinition(ctx, &awsECS.DeregisterTaskDefinitionInput{
+			TaskDefinition: registerOut.TaskDefinition.TaskDefinitionArn,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("Parallel", func(t *testing.T) {
+		for _, family := range []string{"family0", "family1", "family2", "family3", "family4"} {
+			family := family
+			t.Run(family, func(t *testing.T) {
+				t.Parallel()
+
+				tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+				defer tcancel()
+
+				runTaskLifecycle(tctx, t, family)
+			})
+		}
+	})
+
+	assert.Len(t, GlobalECSService.TaskDefs, 5)
+	assert.Len(t, GlobalECSService.Clusters[cluster], 5)
+}
+
+func TestCreateAndCleanupTaskDefinition(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resetECSAndSecretsManagerCache()
+	defer resetECSAndSecretsManagerCache()
+
+	c := &ECSClient{}
+	defer func() {
+		assert.NoError(t, c.Close(ctx))
+	}()
+
+	var arn string
+	t.Run("Subtest", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		in := testutil.ValidRegisterTaskDefinitionInput(t)
+		arn = testutil.CreateAndCleanupTaskDefinition(tctx, t, c, &in)
+		require.NotZero(t, arn)
+
+		out, err := c.DescribeTaskDefinition(tctx, &awsECS.DescribeTaskDefinitionInput{
+			TaskDefinition: aws.String(arn),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, awsECS.TaskDefinitionStatusActive, utility.FromStringPtr(out.TaskDefinition.Status))
+	})
+
+	require.NotZero(t, arn)
+	out, err := c.DescribeTaskDefinition(ctx, &awsECS.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(arn),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, awsECS.TaskDefinitionStatusInactive, utility.FromStringPtr(out.TaskDefinition.Status), "task definition should be deregistered after the subtest completes")
+}
@@ -0,0 +1,191 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsECS "github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckClusterCapacity(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addInstances := func(instances ...ECSContainerInstance) {
+		cluster := map[string]ECSContainerInstance{}
+		for _, instance := range instances {
+			cluster[instance.ARN] = instance
+		}
+		GlobalECSService.ContainerInstances[testutil.ECSClusterName()] = cluster
+	}
+	newInstance := func(arn string, remainingCPU, remainingMemoryMiB int64) ECSContainerInstance {
+		return ECSContainerInstance{
+			ARN:                 arn,
+			Status:              aws.String(awsECS.ContainerInstanceStatusActive),
+			RegisteredCPU:       aws.Int64(2048),
+			RegisteredMemoryMiB: aws.Int64(4096),
+			RemainingCPU:        aws.Int64(remainingCPU),
+			RemainingMemoryMiB:  aws.Int64(remainingMemoryMiB),
+		}
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECSClient){
+		"ReportsCapacityWhenAnInstanceCanFitTheTask": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			addInstances(
+				newInstance("instance0", 256, 4096),
+				newInstance("instance1", 1024, 2048),
+			)
+
+			res, err := ecs.CheckClusterCapacity(ctx, c, testutil.ECSClusterName(), 512, 1024)
+			require.NoError(t, err)
+			require.NotZero(t, res)
+			assert.True(t, res.HasCapacity)
+			assert.EqualValues(t, 2, res.ContainerInstances)
+			assert.EqualValues(t, 4096, res.RegisteredCPU)
+			assert.EqualValues(t, 8192, res.RegisteredMemoryMiB)
+			assert.EqualValues(t, 1280, res.RemainingCPU)
+			assert.EqualValues(t, 6144, res.RemainingMemoryMiB)
+		},
+		"ReportsNoCapacityWhenNoSingleInstanceCanFitTheTask": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			addInstances(
+				newInstance("instance0", 256, 4096),
+				newInstance("instance1", 1024, 512),
+			)
+
+			res, err := ecs.CheckClusterCapacity(ctx, c, testutil.ECSClusterName(), 512, 1024)
+			require.NoError(t, err)
+			require.NotZero(t, res)
+			assert.False(t, res.HasCapacity)
+			assert.EqualValues(t, 1280, res.RemainingCPU)
+			assert.EqualValues(t, 4608, res.RemainingMemoryMiB)
+		},
+		"IgnoresInactiveInstances": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			draining := newInstance("instance1", 1024, 2048)
+			draining.Status = aws.String(awsECS.ContainerInstanceStatusDraining)
+			addInstances(newInstance("instance0", 256, 4096), draining)
+
+			res, err := ecs.CheckClusterCapacity(ctx, c, testutil.ECSClusterName(), 512, 1024)
+			require.NoError(t, err)
+			require.NotZero(t, res)
+			assert.False(t, res.HasCapacity)
+			assert.EqualValues(t, 1, res.ContainerInstances)
+			assert.EqualValues(t, 256, res.RemainingCPU)
+		},
+		"ReportsNoCapacityForClusterWithoutInstances": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			res, err := ecs.CheckClusterCapacity(ctx, c, testutil.ECSClusterName(), 512, 1024)
+			require.NoError(t, err)
+			require.NotZero(t, res)
+			assert.False(t, res.HasCapacity)
+			assert.Zero(t, res.ContainerInstances)
+		},
+		"FailsWithNonexistentCluster": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			res, err := ecs.CheckClusterCapacity(ctx, c, "nonexistent", 512, 1024)
+			assert.Error(t, err)
+			assert.Zero(t, res)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+			defer resetECSAndSecretsManagerCache()
+
+			tCase(tctx, t, &ECSClient{})
+		})
+	}
+}
+
+func TestMultiClusterECSDispatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const (
+		cluster      = "cluster"
+		otherCluster = "other_cluster"
+	)
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECSClient, taskDefARN string){
+		"RunsTaskInSelectedCluster": func(ctx context.Context, t *testing.T, c *ECSClient, taskDefARN string) {
+			d, err := ecs.NewMultiClusterECSDispatcher(c)
+			require.NoError(t, err)
+			require.NoError(t, d.AddCluster("name", cluster, 1))
+
+			in := &awsECS.RunTaskInput{TaskDefinition: aws.String(taskDefARN)}
+			out, name, err := d.RunTask(ctx, in)
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			assert.Equal(t, "name", name)
+			require.Len(t, out.Tasks, 1)
+			assert.Equal(t, cluster, aws.StringValue(out.Tasks[0].ClusterArn))
+			assert.Nil(t, in.Cluster, "input should not be modified")
+		},
+		"UsesClusterNameWithoutARN": func(ctx context.Context, t *testing.T, c *ECSClient, taskDefARN string) {
+			d, err := ecs.NewMultiClusterECSDispatcher(c)
+			require.NoError(t, err)
+			require.NoError(t, d.AddCluster(cluster, "", 1))
+
+			out, name, err := d.RunTask(ctx, &awsECS.RunTaskInput{TaskDefinition: aws.String(taskDefARN)})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			assert.Equal(t, cluster, name)
+			assert.Equal(t, cluster, aws.StringValue(c.RunTaskInput.Cluster))
+		},
+		"SpreadsTasksAcrossClusters": func(ctx context.Context, t *testing.T, c *ECSClient, taskDefARN string) {
+			GlobalECSService.Clusters[otherCluster] = ECSCluster{}
+
+			d, err := ecs.NewMultiClusterECSDispatcher(c)
+			require.NoError(t, err)
+			require.NoError(t, d.AddCluster(cluster, "", 1))
+			require.NoError(t, d.AddCluster(otherCluster, "", 1))
+
+			names := map[string]bool{}
+			for i := 0; i < 100; i++ {
+				_, name, err := d.RunTask(ctx, &awsECS.RunTaskInput{TaskDefinition: aws.String(taskDefARN)})
+				require.NoError(t, err)
+				names[name] = true
+			}
+			assert.Len(t, names, 2)
+			assert.NotEmpty(t, GlobalECSService.Clusters[cluster])
+			assert.NotEmpty(t, GlobalECSService.Clusters[otherCluster])
+		},
+		"ReturnsClusterNameWhenRunTaskFails": func(ctx context.Context, t *testing.T, c *ECSClient, taskDefARN string) {
+			d, err := ecs.NewMultiClusterECSDispatcher(c)
+			require.NoError(t, err)
+			require.NoError(t, d.AddCluster("nonexistent", "", 1))
+
+			out, name, err := d.RunTask(ctx, &awsECS.RunTaskInput{TaskDefinition: aws.String(taskDefARN)})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+			assert.Equal(t, "nonexistent", name)
+		},
+		"FailsWithoutClusters": func(ctx context.Context, t *testing.T, c *ECSClient, taskDefARN string) {
+			d, err := ecs.NewMultiClusterECSDispatcher(c)
+			require.NoError(t, err)
+
+			out, name, err := d.RunTask(ctx, &awsECS.RunTaskInput{TaskDefinition: aws.String(taskDefARN)})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+			assert.Zero(t, name)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+			defer resetECSAndSecretsManagerCache()
+			GlobalECSService.Clusters[cluster] = ECSCluster{}
+
+			c := &ECSClient{}
+			registerOut := testutil.RegisterTaskDefinition(tctx, t, c, testutil.ValidRegisterTaskDefinitionInput(t))
+
+			tCase(tctx, t, c, aws.StringValue(registerOut.TaskDefinition.TaskDefinitionArn))
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsECS "github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetContainerInstanceAgentVersions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const (
+		ec2ARN      = "arn:aws:ecs:us-east-1:123456789012:container-instance/cluster/ec2"
+		externalARN = "arn:aws:ecs:us-east-1:123456789012:container-instance/cluster/external"
+	)
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECSClient){
+		"ReturnsAgentVersionsByInstanceID": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			GlobalECSService.ContainerInstances[testutil.ECSClusterName()] = map[string]ECSContainerInstance{
+				ec2ARN: {
+					ARN:           ec2ARN,
+					EC2InstanceID: aws.String("i-0123456789abcdef0"),
+					Status:        aws.String("ACTIVE"),
+					AgentVersion:  aws.String("1.2.3"),
+				},
+				externalARN: {
+					ARN:          externalARN,
+					Status:       aws.String("ACTIVE"),
+					AgentVersion: aws.String("4.5.6"),
+				},
+			}
+
+			versions, err := ecs.GetContainerInstanceAgentVersions(ctx, c, testutil.ECSClusterName())
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{
+				"i-0123456789abcdef0": "1.2.3",
+				externalARN:           "4.5.6",
+			}, versions)
+		},
+		"ReturnsNoVersionsForClusterWithoutInstances": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			versions, err := ecs.GetContainerInstanceAgentVersions(ctx, c, testutil.ECSClusterName())
+			require.NoError(t, err)
+			assert.Empty(t, versions)
+		},
+		"FailsWithNonexistentCluster": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			versions, err := ecs.GetContainerInstanceAgentVersions(ctx, c, "nonexistent")
+			assert.Error(t, err)
+			assert.Zero(t, versions)
+		},
+		"FailsWhenInstancesCannotBeDescribed": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			c.ListContainerInstancesOutput = &awsECS.ListContainerInstancesOutput{
+				ContainerInstanceArns: []*string{aws.String(ec2ARN)},
+			}
+
+			versions, err := ecs.GetContainerInstanceAgentVersions(ctx, c, testutil.ECSClusterName())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), ec2ARN)
+			assert.Zero(t, versions)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+			defer resetECSAndSecretsManagerCache()
+
+			tCase(tctx, t, &ECSClient{})
+		})
+	}
+}
+
+func TestDrainAndWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const (
+		cluster     = "cluster"
+		instanceARN = "arn:aws:ecs:us-east-1:123456789012:container-instance/cluster/instance"
+		taskARN     = "arn:aws:ecs:us-east-1:123456789012:task/cluster/task"
+	)
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECSClient){
+		"SucceedsImmediatelyWithoutTasks": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			require.NoError(t, ecs.DrainAndWait(ctx, c, cluster, instanceARN, time.Millisecond))
+
+			instance := GlobalECSService.ContainerInstances[cluster][instanceARN]
+			assert.Equal(t, awsECS.ContainerInstanceStatusDraining, utility.FromStringPtr(instance.Status))
+		},
+		"WaitsForTasksToStop": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			GlobalECSService.Clusters[cluster][taskARN] = ECSTask{
+				ARN:               taskARN,
+				ContainerInstance: aws.String(instanceARN),
+				Status:            aws.String(awsECS.DesiredStatusRunning),
+			}
+
+			go func() {
+				time.Sleep(20 * time.Millisecond)
+				_, err := (&ECSClient{}).StopTask(ctx, &awsECS.StopTaskInput{
+					Cluster: aws.String(cluster),
+					Task:    aws.String(taskARN),
+				})
+				assert.NoError(t, err)
+			}()
+
+			require.NoError(t, ecs.DrainAndWait(ctx, c, cluster, instanceARN, time.Millisecond))
+		},
+		"FailsWhenTasksDoNotStopBeforeContextIsDone": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			GlobalECSService.Clusters[cluster][taskARN] = ECSTask{
+				ARN:               taskARN,
+				ContainerInstance: aws.String(instanceARN),
+				Status:            aws.String(awsECS.DesiredStatusPending),
+			}
+
+			tctx, tcancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer tcancel()
+
+			assert.Error(t, ecs.DrainAndWait(tctx, c, cluster, instanceARN, time.Millisecond))
+		},
+		"FailsWithNonexistentInstance": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			assert.Error(t, ecs.DrainAndWait(ctx, c, cluster, "nonexistent", time.Millisecond))
+		},
+		"FailsWithNonexistentCluster": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			assert.Error(t, ecs.DrainAndWait(ctx, c, "nonexistent", instanceARN, time.Millisecond))
+		},
+		"FailsWhenDrainingFails": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			c.UpdateContainerInstancesStateError = errors.New("fake error")
+			assert.Error(t, ecs.DrainAndWait(ctx, c, cluster, instanceARN, time.Millisecond))
+		},
+		"UpdateContainerInstancesStateFailsWithInvalidStatus": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			_, err := c.UpdateContainerInstancesState(ctx, &awsECS.UpdateContainerInstancesStateInput{
+				Cluster:            aws.String(cluster),
+				ContainerInstances: []*string{aws.String(instanceARN)},
+				Status:             aws.String("INVALID"),
+			})
+			assert.Error(t, err)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalECSService()
+			defer ResetGlobalECSService()
+			GlobalECSService.Clusters[cluster] = ECSCluster{}
+
+			GlobalECSService.ContainerInstances[cluster] = map[string]ECSContainerInstance{
+				instanceARN: {
+					ARN:    instanceARN,
+					Status: aws.String(awsECS.ContainerInstanceStatusActive),
+				},
+			}
+
+			tCase(tctx, t, &ECSClient{})
+		})
+	}
+}
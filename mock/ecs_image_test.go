@@ -0,0 +1,82 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsECS "github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinImageDigest(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	const registry = "123456789012.dkr.ecr.us-east-1.amazonaws.com"
+
+	ResetGlobalECRImages()
+	defer ResetGlobalECRImages()
+	GlobalECRImages["app"] = []ECRImage{
+		{Digest: "sha256:v1", Tags: []string{"v1", "latest"}},
+	}
+
+	t.Run("ResolvesTagToDigest", func(t *testing.T) {
+		def := &awsECS.ContainerDefinition{
+			Name:  aws.String("app"),
+			Image: aws.String(registry + "/app:v1"),
+		}
+		pinned, err := ecs.PinImageDigest(ctx, &ECRClient{}, def)
+		require.NoError(t, err)
+		assert.Equal(t, registry+"/app@sha256:v1", utility.FromStringPtr(pinned.Image))
+		assert.Equal(t, "app", utility.FromStringPtr(pinned.Name))
+		assert.Equal(t, registry+"/app:v1", utility.FromStringPtr(def.Image), "original container definition should not be modified")
+	})
+	t.Run("ResolvesLatestTagByDefault", func(t *testing.T) {
+		c := &ECRClient{}
+		pinned, err := ecs.PinImageDigest(ctx, c, &awsECS.ContainerDefinition{
+			Image: aws.String(registry + "/app"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, registry+"/app@sha256:v1", utility.FromStringPtr(pinned.Image))
+		require.NotZero(t, c.BatchGetImageInput)
+		assert.Equal(t, "123456789012", utility.FromStringPtr(c.BatchGetImageInput.RegistryId))
+		require.Len(t, c.BatchGetImageInput.ImageIds, 1)
+		assert.Equal(t, "latest", utility.FromStringPtr(c.BatchGetImageInput.ImageIds[0].ImageTag))
+	})
+	t.Run("NoopsWithImageAlreadyPinned", func(t *testing.T) {
+		c := &ECRClient{}
+		pinned, err := ecs.PinImageDigest(ctx, c, &awsECS.ContainerDefinition{
+			Image: aws.String(registry + "/app@sha256:other"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, registry+"/app@sha256:other", utility.FromStringPtr(pinned.Image))
+		assert.Zero(t, c.BatchGetImageInput, "should not look up an image that is already pinned")
+	})
+	t.Run("FailsWithNonexistentTag", func(t *testing.T) {
+		_, err := ecs.PinImageDigest(ctx, &ECRClient{}, &awsECS.ContainerDefinition{
+			Image: aws.String(registry + "/app:nonexistent"),
+		})
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithNonexistentRepository", func(t *testing.T) {
+		_, err := ecs.PinImageDigest(ctx, &ECRClient{}, &awsECS.ContainerDefinition{
+			Image: aws.String(registry + "/nonexistent:v1"),
+		})
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithImageNotInECR", func(t *testing.T) {
+		_, err := ecs.PinImageDigest(ctx, &ECRClient{}, &awsECS.ContainerDefinition{
+			Image: aws.String("busybox:latest"),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not in ECR")
+	})
+	t.Run("FailsWithoutImage", func(t *testing.T) {
+		_, err := ecs.PinImageDigest(ctx, &ECRClient{}, &awsECS.ContainerDefinition{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,213 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsECS "github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrencyTrackingECSClient is an ECS client that records the maximum
+// number of concurrent RunTask requests.
+type concurrencyTrackingECSClient struct {
+	ECSClient
+	mu      *sync.Mutex
+	current *int
+	max     *int
+}
+
+func (c *concurrencyTrackingECSClient) RunTask(ctx context.Context, in *awsECS.RunTaskInput) (*awsECS.RunTaskOutput, error) {
+	c.mu.Lock()
+	*c.current++
+	if *c.current > *c.max {
+		*c.max = *c.current
+	}
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		*c.current--
+		c.mu.Unlock()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	return c.ECSClient.RunTask(ctx, in)
+}
+
+// blockingECSClient is an ECS client whose RunTask signals that it started and
+// then blocks until the context is done.
+type blockingECSClient struct {
+	ECSClient
+	started chan<- struct{}
+}
+
+func (c *blockingECSClient) RunTask(ctx context.Context, in *awsECS.RunTaskInput) (*awsECS.RunTaskOutput, error) {
+	c.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestMultiRegionTaskRunner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const cluster = "cluster"
+
+	defer ResetGlobalECSService()
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, in *awsECS.RunTaskInput){
+		"RunsTaskInEveryRegion": func(ctx context.Context, t *testing.T, in *awsECS.RunTaskInput) {
+			r, err := ecs.NewMultiRegionTaskRunner(map[string]cocoa.ECSClient{
+				"us-east-1": &ECSClient{},
+				"us-west-2": &ECSClient{},
+			})
+			require.NoError(t, err)
+
+			res, err := r.Run(ctx, in)
+			require.NoError(t, err)
+			require.Len(t, res.Regions, 2)
+			assert.False(t, res.HasErrors())
+			assert.NoError(t, res.Err())
+			for region, regionRes := range res.Regions {
+				assert.NoError(t, regionRes.Err, region)
+				assert.Len(t, regionRes.TaskARNs, 1, region)
+			}
+		},
+		"RecordsErrorsPerRegion": func(ctx context.Context, t *testing.T, in *awsECS.RunTaskInput) {
+			r, err := ecs.NewMultiRegionTaskRunner(map[string]cocoa.ECSClient{
+				"us-east-1": &ECSClient{},
+				"us-west-2": &ECSClient{RunTaskError: errors.New("region outage")},
+				"eu-west-1": &ECSClient{RunTaskOutput: &awsECS.RunTaskOutput{
+					Failures: []*awsECS.Failure{{Reason: aws.String("RESOURCE:MEMORY")}},
+				}},
+			})
+			require.NoError(t, err)
+
+			res, err := r.Run(ctx, in)
+			require.NoError(t, err)
+			require.Len(t, res.Regions, 3)
+			assert.True(t, res.HasErrors())
+			require.Error(t, res.Err())
+			assert.Contains(t, res.Err().Error(), "us-west-2")
+			assert.Contains(t, res.Err().Error(), "eu-west-1")
+
+			assert.NoError(t, res.Regions["us-east-1"].Err)
+			assert.Len(t, res.Regions["us-east-1"].TaskARNs, 1)
+			assert.Error(t, res.Regions["us-west-2"].Err)
+			assert.Empty(t, res.Regions["us-west-2"].TaskARNs)
+			assert.Error(t, res.Regions["eu-west-1"].Err)
+			assert.Empty(t, res.Regions["eu-west-1"].TaskARNs)
+		},
+		"RespectsMaxConcurrency": func(ctx context.Context, t *testing.T, in *awsECS.RunTaskInput) {
+			var mu sync.Mutex
+			var current, max int
+			clients := map[string]cocoa.ECSClient{}
+			for i := 0; i < 6; i++ {
+				clients[fmt.Sprintf("region-%d", i)] = &concurrencyTrackingECSClient{mu: &mu, current: &current, max: &max}
+			}
+			r, err := ecs.NewMultiRegionTaskRunner(clients)
+			require.NoError(t, err)
+			r.SetMaxConcurrency(2)
+
+			res, err := r.Run(ctx, in)
+			require.NoError(t, err)
+			assert.Len(t, res.Regions, 6)
+			assert.NoError(t, res.Err())
+			assert.LessOrEqual(t, max, 2)
+		},
+		"RecordsContextErrorForRegionsNotStarted": func(ctx context.Context, t *testing.T, in *awsECS.RunTaskInput) {
+			r, err := ecs.NewMultiRegionTaskRunner(map[string]cocoa.ECSClient{
+				"us-east-1": &ECSClient{},
+				"us-west-2": &ECSClient{},
+			})
+			require.NoError(t, err)
+
+			cctx, ccancel := context.WithCancel(ctx)
+			ccancel()
+
+			res, err := r.Run(cctx, in)
+			require.NoError(t, err)
+			require.Len(t, res.Regions, 2)
+			for region, regionRes := range res.Regions {
+				assert.True(t, errors.Is(regionRes.Err, context.Canceled), region)
+				assert.Empty(t, regionRes.TaskARNs, region)
+			}
+			assert.Empty(t, GlobalECSService.Clusters[cluster])
+		},
+		"RecordsContextErrorWhenCancelledMidRun": func(ctx context.Context, t *testing.T, in *awsECS.RunTaskInput) {
+			started := make(chan struct{})
+			clients := map[string]cocoa.ECSClient{}
+			for i := 0; i < 5; i++ {
+				clients[fmt.Sprintf("region-%d", i)] = &blockingECSClient{started: started}
+			}
+			r, err := ecs.NewMultiRegionTaskRunner(clients)
+			require.NoError(t, err)
+			r.SetMaxConcurrency(1)
+
+			cctx, ccancel := context.WithCancel(ctx)
+			defer ccancel()
+
+			type runResult struct {
+				res *ecs.MultiRegionRunResult
+				err error
+			}
+			done := make(chan runResult, 1)
+			go func() {
+				res, err := r.Run(cctx, in)
+				done <- runResult{res: res, err: err}
+			}()
+
+			select {
+			case <-started:
+			case <-ctx.Done():
+				require.FailNow(t, "timed out waiting for the first region to start")
+			}
+			ccancel()
+
+			var out runResult
+			select {
+			case out = <-done:
+			case <-ctx.Done():
+				require.FailNow(t, "timed out waiting for the run to finish")
+			}
+			require.NoError(t, out.err)
+			require.Len(t, out.res.Regions, 5)
+			for region, regionRes := range out.res.Regions {
+				assert.True(t, errors.Is(regionRes.Err, context.Canceled), region)
+			}
+		},
+		"FailsWithNilInput": func(ctx context.Context, t *testing.T, in *awsECS.RunTaskInput) {
+			r, err := ecs.NewMultiRegionTaskRunner(map[string]cocoa.ECSClient{"us-east-1": &ECSClient{}})
+			require.NoError(t, err)
+
+			_, err = r.Run(ctx, nil)
+			assert.Error(t, err)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalECSService()
+			GlobalECSService.Clusters[cluster] = ECSCluster{}
+
+			def := testutil.RegisterTaskDefinition(tctx, t, &ECSClient{}, testutil.ValidRegisterTaskDefinitionInput(t))
+
+			tCase(tctx, t, &awsECS.RunTaskInput{
+				Cluster:        aws.String(cluster),
+				TaskDefinition: def.TaskDefinition.TaskDefinitionArn,
+			})
+		})
+	}
+}
@@ -212,7 +212,7 @@ func ecsPodTests() map[string]func(ctx context.Context, t *testing.T, pc cocoa.E
 			p, err := pc.CreatePod(ctx, *opts)
 			require.NoError(t, err)
 
-			c.StopTaskError = cocoa.NewECSTaskNotFoundError(utility.FromStringPtr(p.Resources().TaskID))
+			c.StopTaskError = cocoa.NewECSTaskNotFoundError(utility.FromStringPtr(p.Resources().TaskID), nil)
 
 			assert.NoError(t, p.Stop(ctx), "should successfully stop pod when its task cannot be found")
 		},
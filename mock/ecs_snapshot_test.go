@@ -0,0 +1,262 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsECS "github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotCluster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const cluster = "cluster"
+
+	createCluster := func(ctx context.Context, t *testing.T, c *ECSClient) {
+		_, err := c.CreateCluster(ctx, &awsECS.CreateClusterInput{
+			ClusterName: aws.String(cluster),
+			Tags:        ecs.ExportTags(map[string]string{"team": "evergreen"}),
+			Settings: []*awsECS.ClusterSetting{
+				{Name: aws.String(awsECS.ClusterSettingNameContainerInsights), Value: aws.String("enabled")},
+			},
+			CapacityProviders: aws.StringSlice([]string{"provider"}),
+			DefaultCapacityProviderStrategy: []*awsECS.CapacityProviderStrategyItem{
+				{CapacityProvider: aws.String("provider"), Weight: aws.Int64(1)},
+			},
+		})
+		require.NoError(t, err)
+	}
+	runTask := func(ctx context.Context, t *testing.T, c *ECSClient, in awsECS.RegisterTaskDefinitionInput) {
+		registerOut := testutil.RegisterTaskDefinition(ctx, t, c, in)
+		_, err := c.RunTask(ctx, &awsECS.RunTaskInput{
+			Cluster:        aws.String(cluster),
+			TaskDefinition: registerOut.TaskDefinition.TaskDefinitionArn,
+		})
+		require.NoError(t, err)
+	}
+
+	const serviceRole = "arn:aws:iam::111111111111:role/service"
+	createService := func(ctx context.Context, t *testing.T, c *ECSClient, in awsECS.RegisterTaskDefinitionInput) {
+		registerOut := testutil.RegisterTaskDefinition(ctx, t, c, in)
+		_, err := c.CreateService(ctx, &awsECS.CreateServiceInput{
+			Cluster:        aws.String(cluster),
+			ServiceName:    aws.String("service"),
+			TaskDefinition: registerOut.TaskDefinition.TaskDefinitionArn,
+			DesiredCount:   aws.Int64(2),
+			LaunchType:     aws.String(awsECS.LaunchTypeFargate),
+			Role:           aws.String(serviceRole),
+			Tags:           ecs.ExportTags(map[string]string{"owner": "cocoa"}),
+		})
+		require.NoError(t, err)
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECSClient){
+		"CapturesClusterConfiguration": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			createCluster(ctx, t, c)
+
+			snapshot, err := ecs.SnapshotCluster(ctx, c, cluster)
+			require.NoError(t, err)
+			require.NotZero(t, snapshot)
+			assert.Equal(t, cluster, snapshot.ClusterName)
+			assert.Equal(t, map[string]string{"team": "evergreen"}, snapshot.Tags)
+			require.Len(t, snapshot.Settings, 1)
+			assert.Equal(t, "enabled", aws.StringValue(snapshot.Settings[0].Value))
+			assert.Equal(t, []string{"provider"}, snapshot.CapacityProviders)
+			require.Len(t, snapshot.DefaultCapacityProviderStrategy, 1)
+			assert.Equal(t, "provider", aws.StringValue(snapshot.DefaultCapacityProviderStrategy[0].CapacityProvider))
+			assert.Empty(t, snapshot.TaskDefinitions)
+		},
+		"CapturesUniqueTaskDefinitionsInUse": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			createCluster(ctx, t, c)
+
+			in := testutil.ValidRegisterTaskDefinitionInput(t)
+			in.Tags = ecs.ExportTags(map[string]string{"owner": "cocoa"})
+			runTask(ctx, t, c, in)
+			_, err := c.RunTask(ctx, &awsECS.RunTaskInput{
+				Cluster:        aws.String(cluster),
+				TaskDefinition: in.Family,
+			})
+			require.NoError(t, err)
+
+			snapshot, err := ecs.SnapshotCluster(ctx, c, cluster)
+			require.NoError(t, err)
+			require.NotZero(t, snapshot)
+			require.Len(t, snapshot.TaskDefinitions, 1)
+			assert.Equal(t, aws.StringValue(in.Family), aws.StringValue(snapshot.TaskDefinitions[0].Definition.Family))
+			assert.Equal(t, map[string]string{"owner": "cocoa"}, snapshot.TaskDefinitions[0].Tags)
+		},
+		"CapturesServices": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			createCluster(ctx, t, c)
+			in := testutil.ValidRegisterTaskDefinitionInput(t)
+			createService(ctx, t, c, in)
+
+			snapshot, err := ecs.SnapshotCluster(ctx, c, cluster)
+			require.NoError(t, err)
+			require.NotZero(t, snapshot)
+			require.Len(t, snapshot.Services, 1)
+			svc := snapshot.Services[0]
+			assert.Equal(t, "service", aws.StringValue(svc.ServiceName))
+			assert.EqualValues(t, 2, aws.Int64Value(svc.DesiredCount))
+			assert.Equal(t, awsECS.LaunchTypeFargate, aws.StringValue(svc.LaunchType))
+			assert.Equal(t, serviceRole, aws.StringValue(svc.RoleArn))
+			assert.Equal(t, ecs.ExportTags(map[string]string{"owner": "cocoa"}), svc.Tags)
+			require.Len(t, snapshot.TaskDefinitions, 1, "service's task definition should be captured")
+			assert.Equal(t, aws.StringValue(svc.TaskDefinition), aws.StringValue(snapshot.TaskDefinitions[0].Definition.TaskDefinitionArn))
+		},
+		"FailsWhenServicesCannotBeDescribed": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			createCluster(ctx, t, c)
+			c.DescribeServicesOutput = &awsECS.DescribeServicesOutput{
+				Failures: []*awsECS.Failure{{Arn: aws.String("service"), Reason: aws.String("MISSING")}},
+			}
+			c.ListServicesOutput = &awsECS.ListServicesOutput{ServiceArns: aws.StringSlice([]string{"service"})}
+
+			snapshot, err := ecs.SnapshotCluster(ctx, c, cluster)
+			assert.Error(t, err)
+			assert.Zero(t, snapshot)
+		},
+		"FailsWithNonexistentCluster": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			snapshot, err := ecs.SnapshotCluster(ctx, c, "nonexistent")
+			assert.Error(t, err)
+			assert.Zero(t, snapshot)
+		},
+		"FailsWithoutCluster": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			snapshot, err := ecs.SnapshotCluster(ctx, c, "")
+			assert.Error(t, err)
+			assert.Zero(t, snapshot)
+		},
+		"RestoresSnapshotToNewCluster": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			createCluster(ctx, t, c)
+			in := testutil.ValidRegisterTaskDefinitionInput(t)
+			runTask(ctx, t, c, in)
+
+			snapshot, err := ecs.SnapshotCluster(ctx, c, cluster)
+			require.NoError(t, err)
+
+			require.NoError(t, ecs.RestoreClusterSnapshot(ctx, c, snapshot, "restored", ecs.RestoreClusterSnapshotOptions{}))
+
+			restored, err := ecs.SnapshotCluster(ctx, c, "restored")
+			require.NoError(t, err)
+			assert.Equal(t, snapshot.Tags, restored.Tags)
+			assert.Equal(t, snapshot.Settings, restored.Settings)
+			assert.Equal(t, snapshot.CapacityProviders, restored.CapacityProviders)
+			assert.Equal(t, snapshot.DefaultCapacityProviderStrategy, restored.DefaultCapacityProviderStrategy)
+
+			family := aws.StringValue(in.Family)
+			revisions := GlobalECSService.TaskDefs[family]
+			require.Len(t, revisions, 2, "task definition should be registered again")
+			assert.Equal(t, aws.StringValue(in.Cpu), aws.StringValue(revisions[1].CPU))
+			assert.Equal(t, aws.StringValue(in.Memory), aws.StringValue(revisions[1].MemoryMB))
+		},
+		"RestoresServicesWithRestoredTaskDefinitions": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			const newServiceRole = "arn:aws:iam::222222222222:role/service"
+			createCluster(ctx, t, c)
+			in := testutil.ValidRegisterTaskDefinitionInput(t)
+			createService(ctx, t, c, in)
+
+			snapshot, err := ecs.SnapshotCluster(ctx, c, cluster)
+			require.NoError(t, err)
+
+			require.NoError(t, ecs.RestoreClusterSnapshot(ctx, c, snapshot, "restored", ecs.RestoreClusterSnapshotOptions{
+				RoleARNs: map[string]string{serviceRole: newServiceRole},
+			}))
+
+			revisions := GlobalECSService.TaskDefs[aws.StringValue(in.Family)]
+			require.Len(t, revisions, 2)
+
+			svc, ok := GlobalECSService.Services["restored"]["service"]
+			require.True(t, ok, "service should be created in the restored cluster")
+			assert.Equal(t, revisions[1].ARN, aws.StringValue(svc.TaskDefinition), "service should use the restored task definition")
+			assert.EqualValues(t, 2, aws.Int64Value(svc.DesiredCount))
+			assert.Equal(t, awsECS.LaunchTypeFargate, aws.StringValue(svc.LaunchType))
+			assert.Equal(t, newServiceRole, aws.StringValue(svc.Role))
+			assert.Equal(t, map[string]string{"owner": "cocoa"}, svc.Tags)
+			assert.Equal(t, serviceRole, aws.StringValue(snapshot.Services[0].RoleArn), "snapshot should not be modified")
+		},
+		"RestoreFailsWhenServiceTaskDefinitionIsNotRestored": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			snapshot := &ecs.ClusterSnapshot{
+				ClusterName: cluster,
+				Services: []*awsECS.Service{{
+					ServiceName:    aws.String("service"),
+					TaskDefinition: aws.String("arn:aws:ecs:us-east-1:111111111111:task-definition/nonexistent:1"),
+				}},
+			}
+
+			assert.Error(t, ecs.RestoreClusterSnapshot(ctx, c, snapshot, "restored", ecs.RestoreClusterSnapshotOptions{}))
+			assert.Empty(t, GlobalECSService.Services["restored"])
+		},
+		"RestoreFailsWhenServiceCannotBeCreated": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			createCluster(ctx, t, c)
+			createService(ctx, t, c, testutil.ValidRegisterTaskDefinitionInput(t))
+
+			snapshot, err := ecs.SnapshotCluster(ctx, c, cluster)
+			require.NoError(t, err)
+
+			c.CreateServiceError = errors.New("fake error")
+			assert.Error(t, ecs.RestoreClusterSnapshot(ctx, c, snapshot, "restored", ecs.RestoreClusterSnapshotOptions{}))
+		},
+		"RestoreRemapsRoleARNs": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			const (
+				taskRole      = "arn:aws:iam::111111111111:role/task"
+				executionRole = "arn:aws:iam::111111111111:role/execution"
+				newTaskRole   = "arn:aws:iam::222222222222:role/task"
+				otherRole     = "arn:aws:iam::111111111111:role/other"
+			)
+			createCluster(ctx, t, c)
+			in := testutil.ValidRegisterTaskDefinitionInput(t)
+			in.TaskRoleArn = aws.String(taskRole)
+			in.ExecutionRoleArn = aws.String(executionRole)
+			runTask(ctx, t, c, in)
+
+			snapshot, err := ecs.SnapshotCluster(ctx, c, cluster)
+			require.NoError(t, err)
+
+			require.NoError(t, ecs.RestoreClusterSnapshot(ctx, c, snapshot, "restored", ecs.RestoreClusterSnapshotOptions{
+				RoleARNs: map[string]string{
+					taskRole:  newTaskRole,
+					otherRole: "arn:aws:iam::222222222222:role/other",
+				},
+			}))
+
+			revisions := GlobalECSService.TaskDefs[aws.StringValue(in.Family)]
+			require.Len(t, revisions, 2)
+			assert.Equal(t, newTaskRole, aws.StringValue(revisions[1].TaskRole))
+			assert.Equal(t, executionRole, aws.StringValue(revisions[1].ExecutionRole), "unmapped role should be kept")
+			assert.Equal(t, taskRole, aws.StringValue(snapshot.TaskDefinitions[0].Definition.TaskRoleArn), "snapshot should not be modified")
+		},
+		"RestoreFailsWithEmptyMappedRoleARN": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			err := ecs.RestoreClusterSnapshot(ctx, c, &ecs.ClusterSnapshot{ClusterName: cluster}, "restored", ecs.RestoreClusterSnapshotOptions{
+				RoleARNs: map[string]string{"arn:aws:iam::111111111111:role/task": ""},
+			})
+			assert.Error(t, err)
+			assert.Zero(t, c.CreateClusterInput)
+		},
+		"RestoreFailsWhenClusterCannotBeCreated": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			c.CreateClusterError = errors.New("fake error")
+
+			assert.Error(t, ecs.RestoreClusterSnapshot(ctx, c, &ecs.ClusterSnapshot{ClusterName: cluster}, "restored", ecs.RestoreClusterSnapshotOptions{}))
+		},
+		"RestoreFailsWithoutSnapshot": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			assert.Error(t, ecs.RestoreClusterSnapshot(ctx, c, nil, "restored", ecs.RestoreClusterSnapshotOptions{}))
+		},
+		"RestoreFailsWithoutClusterName": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			assert.Error(t, ecs.RestoreClusterSnapshot(ctx, c, &ecs.ClusterSnapshot{ClusterName: cluster}, "", ecs.RestoreClusterSnapshotOptions{}))
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+			defer resetECSAndSecretsManagerCache()
+
+			tCase(tctx, t, &ECSClient{})
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package mock
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsECS "github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeregisterOldRevisions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const family = "family"
+
+	registerRevisions := func(ctx context.Context, t *testing.T, c *ECSClient, family string, n int) []string {
+		var arns []string
+		for i := 0; i < n; i++ {
+			out, err := c.RegisterTaskDefinition(ctx, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			require.NoError(t, err)
+			arns = append(arns, utility.FromStringPtr(out.TaskDefinition.TaskDefinitionArn))
+		}
+		return arns
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECSClient){
+		"DeregistersAllButLatestRevisions": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			arns := registerRevisions(ctx, t, c, family, 5)
+
+			deregistered, err := ecs.DeregisterOldRevisions(ctx, c, family, 2)
+			require.NoError(t, err)
+			assert.Equal(t, []string{arns[2], arns[1], arns[0]}, deregistered)
+
+			for i, def := range GlobalECSService.TaskDefs[family] {
+				if i < 3 {
+					assert.Equal(t, awsECS.TaskDefinitionStatusInactive, utility.FromStringPtr(def.Status))
+				} else {
+					assert.Equal(t, awsECS.TaskDefinitionStatusActive, utility.FromStringPtr(def.Status))
+				}
+			}
+		},
+		"DeregistersAllRevisionsWhenKeepingNone": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			arns := registerRevisions(ctx, t, c, family, 2)
+
+			deregistered, err := ecs.DeregisterOldRevisions(ctx, c, family, 0)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, arns, deregistered)
+		},
+		"NoopsWithFewerRevisionsThanKept": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			registerRevisions(ctx, t, c, family, 2)
+
+			deregistered, err := ecs.DeregisterOldRevisions(ctx, c, family, 3)
+			require.NoError(t, err)
+			assert.Empty(t, deregistered)
+			assert.Zero(t, c.DeregisterTaskDefinitionInput)
+		},
+		"IgnoresInactiveRevisions": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			arns := registerRevisions(ctx, t, c, family, 3)
+			_, err := c.DeregisterTaskDefinition(ctx, &awsECS.DeregisterTaskDefinitionInput{
+				TaskDefinition: aws.String(arns[2]),
+			})
+			require.NoError(t, err)
+
+			deregistered, err := ecs.DeregisterOldRevisions(ctx, c, family, 1)
+			require.NoError(t, err)
+			assert.Equal(t, []string{arns[0]}, deregistered)
+		},
+		"IgnoresFamiliesSharingPrefix": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			prefixedArns := registerRevisions(ctx, t, c, family+"-other", 2)
+			registerRevisions(ctx, t, c, family, 1)
+			pc := &prefixListTaskDefinitionsClient{ECSClient: c}
+
+			deregistered, err := ecs.DeregisterOldRevisions(ctx, pc, family, 0)
+			require.NoError(t, err)
+			require.Len(t, deregistered, 1)
+			assert.NotContains(t, deregistered, prefixedArns[0])
+			assert.NotContains(t, deregistered, prefixedArns[1])
+		},
+		"ReturnsMultiErrorForRevisionsThatFailToDeregister": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			registerRevisions(ctx, t, c, family, 3)
+			c.DeregisterTaskDefinitionError = cocoa.NewECSTaskNotFoundError("arn", nil)
+
+			deregistered, err := ecs.DeregisterOldRevisions(ctx, c, family, 1)
+			require.Error(t, err)
+			assert.Empty(t, deregistered)
+
+			var merr cocoa.MultiError
+			require.True(t, errors.As(err, &merr))
+			assert.Len(t, merr.Errors(), 2)
+			assert.True(t, errors.Is(err, cocoa.ErrECSTaskNotFound))
+		},
+		"FailsWithNegativeRevisionsToKeep": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			deregistered, err := ecs.DeregisterOldRevisions(ctx, c, family, -1)
+			assert.Error(t, err)
+			assert.Empty(t, deregistered)
+		},
+		"FailsWithoutFamily": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			deregistered, err := ecs.DeregisterOldRevisions(ctx, c, "", 1)
+			assert.Error(t, err)
+			assert.Empty(t, deregistered)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+			defer resetECSAndSecretsManagerCache()
+
+			tCase(tctx, t, &ECSClient{})
+		})
+	}
+}
+
+// prefixListTaskDefinitionsClient is an ECS client that lists task definitions
+// by matching the family prefix rather than the exact family, like ECS does.
+type prefixListTaskDefinitionsClient struct {
+	*ECSClient
+}
+
+func (c *prefixListTaskDefinitionsClient) ListTaskDefinitions(ctx context.Context, in *awsECS.ListTaskDefinitionsInput) (*awsECS.ListTaskDefinitionsOutput, error) {
+	var arns []*string
+	for family := range GlobalECSService.TaskDefs {
+		if !strings.HasPrefix(family, utility.FromStringPtr(in.FamilyPrefix)) {
+			continue
+		}
+		out, err := c.ECSClient.ListTaskDefinitions(ctx, &awsECS.ListTaskDefinitionsInput{
+			FamilyPrefix: aws.String(family),
+			Status:       in.Status,
+		})
+		if err != nil {
+			return nil, err
+		}
+		arns = append(arns, out.TaskDefinitionArns...)
+	}
+	return &awsECS.ListTaskDefinitionsOutput{TaskDefinitionArns: arns}, nil
+}
+
+func TestRegisterIfChanged(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const family = "family"
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECSClient){
+		"RegistersFirstRevision": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			def, registered, err := ecs.RegisterIfChanged(ctx, c, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			require.NoError(t, err)
+			assert.True(t, registered)
+			require.NotZero(t, def)
+			assert.EqualValues(t, 1, utility.FromInt64Ptr(def.Revision))
+			assert.Len(t, GlobalECSService.TaskDefs[family], 1)
+		},
+		"SkipsUnchangedTaskDefinition": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			first, registered, err := ecs.RegisterIfChanged(ctx, c, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			require.NoError(t, err)
+			require.True(t, registered)
+
+			second, registered, err := ecs.RegisterIfChanged(ctx, c, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			require.NoError(t, err)
+			assert.False(t, registered)
+			require.NotZero(t, second)
+			assert.Equal(t, utility.FromStringPtr(first.TaskDefinitionArn), utility.FromStringPtr(second.TaskDefinitionArn))
+			assert.Len(t, GlobalECSService.TaskDefs[family], 1)
+		},
+		"SkipsTaskDefinitionThatOmitsECSDefaults": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			in := testutil.GenerateTaskDefinitionFixture(family, "image")
+			first, registered, err := ecs.RegisterIfChanged(ctx, c, in)
+			require.NoError(t, err)
+			require.True(t, registered)
+			require.NotEmpty(t, first.ContainerDefinitions)
+			assert.True(t, utility.FromBoolPtr(first.ContainerDefinitions[0].Essential))
+
+			_, registered, err = ecs.RegisterIfChanged(ctx, c, in)
+			require.NoError(t, err)
+			assert.False(t, registered)
+			assert.Len(t, GlobalECSService.TaskDefs[family], 1)
+		},
+		"DescribesLatestRevisionByFamily": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			_, err := c.RegisterTaskDefinition(ctx, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			require.NoError(t, err)
+
+			_, registered, err := ecs.RegisterIfChanged(ctx, c, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			require.NoError(t, err)
+			assert.False(t, registered)
+			require.NotZero(t, c.DescribeTaskDefinitionInput)
+			assert.Equal(t, family, utility.FromStringPtr(c.DescribeTaskDefinitionInput.TaskDefinition))
+			assert.Zero(t, c.ListTaskDefinitionsInput, "should not list revisions")
+		},
+		"RegistersChangedTaskDefinition": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			_, registered, err := ecs.RegisterIfChanged(ctx, c, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			require.NoError(t, err)
+			require.True(t, registered)
+
+			def, registered, err := ecs.RegisterIfChanged(ctx, c, testutil.GenerateTaskDefinitionFixture(family, "new_image"))
+			require.NoError(t, err)
+			assert.True(t, registered)
+			require.NotZero(t, def)
+			assert.EqualValues(t, 2, utility.FromInt64Ptr(def.Revision))
+			assert.Len(t, GlobalECSService.TaskDefs[family], 2)
+		},
+		"ComparesAgainstLatestActiveRevision": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			_, err := c.RegisterTaskDefinition(ctx, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			require.NoError(t, err)
+			latest, err := c.RegisterTaskDefinition(ctx, testutil.GenerateTaskDefinitionFixture(family, "new_image"))
+			require.NoError(t, err)
+			_, err = c.DeregisterTaskDefinition(ctx, &awsECS.DeregisterTaskDefinitionInput{
+				TaskDefinition: latest.TaskDefinition.TaskDefinitionArn,
+			})
+			require.NoError(t, err)
+
+			def, registered, err := ecs.RegisterIfChanged(ctx, c, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			require.NoError(t, err)
+			assert.False(t, registered)
+			assert.EqualValues(t, 1, utility.FromInt64Ptr(def.Revision))
+		},
+		"FailsWithInvalidInput": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			in := testutil.GenerateTaskDefinitionFixture(family, "image")
+			in.Family = nil
+
+			def, registered, err := ecs.RegisterIfChanged(ctx, c, in)
+			assert.Error(t, err)
+			assert.False(t, registered)
+			assert.Zero(t, def)
+			assert.Zero(t, c.RegisterTaskDefinitionInput)
+		},
+		"FailsWhenDescribeFails": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			_, err := c.RegisterTaskDefinition(ctx, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			require.NoError(t, err)
+			c.RegisterTaskDefinitionInput = nil
+			c.DescribeTaskDefinitionError = errors.New("fake error")
+
+			def, registered, err := ecs.RegisterIfChanged(ctx, c, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			assert.Error(t, err)
+			assert.False(t, registered)
+			assert.Zero(t, def)
+			assert.Zero(t, c.RegisterTaskDefinitionInput)
+		},
+		"FailsWhenRegisterFails": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			c.RegisterTaskDefinitionError = errors.New("fake error")
+
+			def, registered, err := ecs.RegisterIfChanged(ctx, c, testutil.GenerateTaskDefinitionFixture(family, "image"))
+			assert.Error(t, err)
+			assert.False(t, registered)
+			assert.Zero(t, def)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+			defer resetECSAndSecretsManagerCache()
+
+			tCase(tctx, t, &ECSClient{})
+		})
+	}
+}
+
+func TestListTaskDefinitionsByStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	register := func(ctx context.Context, t *testing.T, c *ECSClient, family string) string {
+		out, err := c.RegisterTaskDefinition(ctx, testutil.GenerateTaskDefinitionFixture(family, "image"))
+		require.NoError(t, err)
+		return utility.FromStringPtr(out.TaskDefinition.TaskDefinitionArn)
+	}
+	deregister := func(ctx context.Context, t *testing.T, c *ECSClient, arn string) {
+		_, err := c.DeregisterTaskDefinition(ctx, &awsECS.DeregisterTaskDefinitionInput{
+			TaskDefinition: aws.String(arn),
+		})
+		require.NoError(t, err)
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECSClient){
+		"ListsTaskDefinitionsWithEachStatus": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			first := register(ctx, t, c, "family")
+			second := register(ctx, t, c, "family")
+			third := register(ctx, t, c, "family")
+			deregister(ctx, t, c, second)
+
+			active, err := ecs.ListActiveTaskDefinitions(ctx, c, "family")
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{first, third}, utility.FromStringPtrSlice(active))
+
+			inactive, err := ecs.ListInactiveTaskDefinitions(ctx, c, "family")
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{second}, utility.FromStringPtrSlice(inactive))
+		},
+		"PaginatesThroughAllResults": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			var arns []string
+			for i := 0; i < 3; i++ {
+				arns = append(arns, register(ctx, t, c, "family"))
+			}
+			pc := &paginatedListTaskDefinitionsClient{ECSClient: c}
+
+			active, err := ecs.ListActiveTaskDefinitions(ctx, pc, "family")
+			require.NoError(t, err)
+			assert.ElementsMatch(t, arns, utility.FromStringPtrSlice(active))
+			assert.Equal(t, 3, pc.calls)
+		},
+		"ListsAllFamiliesWithoutFamily": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			first := register(ctx, t, c, "family")
+			second := register(ctx, t, c, "other")
+
+			active, err := ecs.ListActiveTaskDefinitions(ctx, c, "")
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{first, second}, utility.FromStringPtrSlice(active))
+		},
+		"IgnoresFamiliesSharingPrefix": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			arn := register(ctx, t, c, "family")
+			register(ctx, t, c, "family-other")
+
+			active, err := ecs.ListActiveTaskDefinitions(ctx, &prefixListTaskDefinitionsClient{ECSClient: c}, "family")
+			require.NoError(t, err)
+			assert.Equal(t, []string{arn}, utility.FromStringPtrSlice(active))
+		},
+		"FailsWhenListingFails": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			c.ListTaskDefinitionsError = errors.New("fake error")
+
+			active, err := ecs.ListActiveTaskDefinitions(ctx, c, "family")
+			assert.Error(t, err)
+			assert.Empty(t, active)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+			defer resetECSAndSecretsManagerCache()
+
+			tCase(tctx, t, &ECSClient{})
+		})
+	}
+}
+
+// paginatedListTaskDefinitionsClient is an ECS client that lists one task
+// definition per page and counts how many pages were listed.
+type paginatedListTaskDefinitionsClient struct {
+	*ECSClient
+	calls int
+}
+
+func (c *paginatedListTaskDefinitionsClient) ListTaskDefinitions(ctx context.Context, in *awsECS.ListTaskDefinitionsInput) (*awsECS.ListTaskDefinitionsOutput, error) {
+	c.calls++
+	paged := *in
+	paged.MaxResults = aws.Int64(1)
+	return c.ECSClient.ListTaskDefinitions(ctx, &paged)
+}
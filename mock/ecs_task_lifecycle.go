@@ -0,0 +1,129 @@
+package mock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/utility"
+)
+
+// VirtualClock is a clock whose time only changes when it is explicitly
+// advanced. It is safe for concurrent use.
+type VirtualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewVirtualClock returns a new virtual clock starting at the given time.
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+// Now returns the current virtual time.
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the virtual time forward by the given duration.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// ECSTaskLifecycle simulates the ECS task lifecycle for tasks in the global
+// ECS service. Started tasks transition from PROVISIONING to PENDING to
+// RUNNING, and stopped tasks transition from DEACTIVATING to STOPPING to
+// STOPPED. Each intermediate status lasts for the transition delay.
+type ECSTaskLifecycle struct {
+	// Clock is the clock used to determine how long a task has been in its
+	// current status. If this is not set, it uses the real time.
+	Clock *VirtualClock
+	// TransitionDelay is how long a task stays in each intermediate status
+	// before it transitions to the next one.
+	TransitionDelay time.Duration
+}
+
+func (l *ECSTaskLifecycle) now() time.Time {
+	if l.Clock == nil {
+		return time.Now()
+	}
+	return l.Clock.Now()
+}
+
+// startTask sets the initial status of a newly-started task.
+func (l *ECSTaskLifecycle) startTask(task *ECSTask) {
+	task.Status = utility.ToStringPtr(string(ecs.TaskStatusProvisioning))
+	task.Created = utility.ToTimePtr(l.now())
+}
+
+// stopTask begins stopping the task if it is not already stopping.
+func (l *ECSTaskLifecycle) stopTask(task *ECSTask) {
+	if task.Stopping != nil {
+		return
+	}
+	task.Status = utility.ToStringPtr(string(ecs.TaskStatusDeactivating))
+	task.Stopping = utility.ToTimePtr(l.now())
+}
+
+// updateTask transitions the task to the status that it should be in at the
+// current time.
+func (l *ECSTaskLifecycle) updateTask(task *ECSTask) {
+	now := l.now()
+
+	var status ecs.TaskStatus
+	var containerStatus ecs.TaskStatus
+	if task.Stopping != nil {
+		elapsed := now.Sub(*task.Stopping)
+		switch {
+		case elapsed < l.TransitionDelay:
+			status = ecs.TaskStatusDeactivating
+			containerStatus = ecs.TaskStatusRunning
+		case elapsed < 2*l.TransitionDelay:
+			status = ecs.TaskStatusStopping
+			containerStatus = ecs.TaskStatusRunning
+		default:
+			status = ecs.TaskStatusStopped
+			containerStatus = ecs.TaskStatusStopped
+			if task.Stopped == nil {
+				task.Stopped = utility.ToTimePtr(task.Stopping.Add(2 * l.TransitionDelay))
+			}
+		}
+	} else {
+		elapsed := now.Sub(utility.FromTimePtr(task.Created))
+		switch {
+		case elapsed < l.TransitionDelay:
+			status = ecs.TaskStatusProvisioning
+			containerStatus = ecs.TaskStatusPending
+		case elapsed < 2*l.TransitionDelay:
+			status = ecs.TaskStatusPending
+			containerStatus = ecs.TaskStatusPending
+		default:
+			status = ecs.TaskStatusRunning
+			containerStatus = ecs.TaskStatusRunning
+		}
+	}
+
+	task.Status = utility.ToStringPtr(string(status))
+	for i := range task.Containers {
+		task.Containers[i].Status = utility.ToStringPtr(string(containerStatus))
+	}
+}
+
+// updateTaskLifecycles transitions all tasks in the global ECS service to the
+// status that they should be in at the current time. This is a no-op if the
+// task lifecycle is not being simulated.
+func (s *ECSService) updateTaskLifecycles() {
+	if s.TaskLifecycle == nil {
+		return
+	}
+	for _, cluster := range s.Clusters {
+		for arn, task := range cluster {
+			s.TaskLifecycle.updateTask(&task)
+			cluster[arn] = task
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsECS "github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtualClock(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewVirtualClock(start)
+	assert.Equal(t, start, clock.Now())
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+}
+
+func TestECSTaskLifecycle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const delay = time.Minute
+
+	describeTask := func(ctx context.Context, t *testing.T, c *ECSClient, taskARN string) *awsECS.Task {
+		out, err := c.DescribeTasks(ctx, &awsECS.DescribeTasksInput{
+			Cluster: aws.String(testutil.ECSClusterName()),
+			Tasks:   []*string{aws.String(taskARN)},
+		})
+		require.NoError(t, err)
+		require.Len(t, out.Tasks, 1)
+		return out.Tasks[0]
+	}
+	checkStatus := func(t *testing.T, task *awsECS.Task, status, containerStatus ecs.TaskStatus) {
+		assert.Equal(t, string(status), utility.FromStringPtr(task.LastStatus))
+		for _, container := range task.Containers {
+			assert.Equal(t, string(containerStatus), utility.FromStringPtr(container.LastStatus))
+		}
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECSClient, clock *VirtualClock){
+		"StartedTaskTransitionsToRunning": func(ctx context.Context, t *testing.T, c *ECSClient, clock *VirtualClock) {
+			task := runTestTask(ctx, t, c)
+			checkStatus(t, task, ecs.TaskStatusProvisioning, ecs.TaskStatusPending)
+			taskARN := utility.FromStringPtr(task.TaskArn)
+
+			clock.Advance(delay)
+			checkStatus(t, describeTask(ctx, t, c, taskARN), ecs.TaskStatusPending, ecs.TaskStatusPending)
+
+			clock.Advance(delay)
+			task = describeTask(ctx, t, c, taskARN)
+			checkStatus(t, task, ecs.TaskStatusRunning, ecs.TaskStatusRunning)
+			assert.Equal(t, awsECS.DesiredStatusRunning, utility.FromStringPtr(task.DesiredStatus))
+		},
+		"StoppedTaskTransitionsToStopped": func(ctx context.Context, t *testing.T, c *ECSClient, clock *VirtualClock) {
+			taskARN := utility.FromStringPtr(runTestTask(ctx, t, c).TaskArn)
+			clock.Advance(2 * delay)
+
+			out, err := c.StopTask(ctx, &awsECS.StopTaskInput{
+				Cluster: aws.String(testutil.ECSClusterName()),
+				Task:    aws.String(taskARN),
+				Reason:  aws.String("reason"),
+			})
+			require.NoError(t, err)
+			checkStatus(t, out.Task, ecs.TaskStatusDeactivating, ecs.TaskStatusRunning)
+			assert.Equal(t, awsECS.DesiredStatusStopped, utility.FromStringPtr(out.Task.DesiredStatus))
+			stoppingAt := clock.Now()
+			assert.Equal(t, stoppingAt, utility.FromTimePtr(out.Task.StoppingAt))
+			assert.Zero(t, out.Task.StoppedAt)
+
+			clock.Advance(delay)
+			checkStatus(t, describeTask(ctx, t, c, taskARN), ecs.TaskStatusStopping, ecs.TaskStatusRunning)
+
+			clock.Advance(delay)
+			task := describeTask(ctx, t, c, taskARN)
+			checkStatus(t, task, ecs.TaskStatusStopped, ecs.TaskStatusStopped)
+			assert.Equal(t, stoppingAt.Add(2*delay), utility.FromTimePtr(task.StoppedAt))
+			assert.Equal(t, "reason", utility.FromStringPtr(task.StoppedReason))
+		},
+		"StoppingTaskAgainDoesNotRestartTransition": func(ctx context.Context, t *testing.T, c *ECSClient, clock *VirtualClock) {
+			taskARN := utility.FromStringPtr(runTestTask(ctx, t, c).TaskArn)
+			stopIn := &awsECS.StopTaskInput{
+				Cluster: aws.String(testutil.ECSClusterName()),
+				Task:    aws.String(taskARN),
+			}
+			_, err := c.StopTask(ctx, stopIn)
+			require.NoError(t, err)
+
+			clock.Advance(delay)
+			out, err := c.StopTask(ctx, stopIn)
+			require.NoError(t, err)
+			checkStatus(t, out.Task, ecs.TaskStatusStopping, ecs.TaskStatusRunning)
+		},
+		"ListTasksReflectsTransitions": func(ctx context.Context, t *testing.T, c *ECSClient, clock *VirtualClock) {
+			taskARN := utility.FromStringPtr(runTestTask(ctx, t, c).TaskArn)
+			_, err := c.StopTask(ctx, &awsECS.StopTaskInput{
+				Cluster: aws.String(testutil.ECSClusterName()),
+				Task:    aws.String(taskARN),
+			})
+			require.NoError(t, err)
+
+			out, err := c.ListTasks(ctx, &awsECS.ListTasksInput{
+				Cluster:       aws.String(testutil.ECSClusterName()),
+				DesiredStatus: aws.String(awsECS.DesiredStatusStopped),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{taskARN}, utility.FromStringPtrSlice(out.TaskArns))
+		},
+		"WaitForTaskStatusReturnsOnceTaskIsRunning": func(ctx context.Context, t *testing.T, c *ECSClient, clock *VirtualClock) {
+			taskARN := utility.FromStringPtr(runTestTask(ctx, t, c).TaskArn)
+
+			var polls int
+			task, err := ecs.WaitForTaskStatus(ctx, c, testutil.ECSClusterName(), taskARN, func(task *awsECS.Task) bool {
+				polls++
+				if utility.FromStringPtr(task.LastStatus) == string(ecs.TaskStatusRunning) {
+					return true
+				}
+				// Simulate time passing between polls without sleeping.
+				clock.Advance(delay)
+				return false
+			}, time.Nanosecond)
+			require.NoError(t, err)
+			checkStatus(t, task, ecs.TaskStatusRunning, ecs.TaskStatusRunning)
+			assert.Equal(t, 3, polls)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+			defer resetECSAndSecretsManagerCache()
+
+			clock := NewVirtualClock(time.Now())
+			GlobalECSService.TaskLifecycle = &ECSTaskLifecycle{
+				Clock:           clock,
+				TransitionDelay: delay,
+			}
+
+			tCase(tctx, t, &ECSClient{}, clock)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+
+	"github.com/evergreen-ci/cocoa/ecs"
+)
+
+// TaskMetadataServer is a fake ECS task metadata endpoint version 4 server
+// that serves the task and container metadata that it is configured with.
+type TaskMetadataServer struct {
+	// TaskMetadata is the metadata returned for the task.
+	TaskMetadata ecs.TaskMetadata
+	// ContainerMetadata is the metadata returned for the container.
+	ContainerMetadata ecs.ContainerMetadata
+	// StatusCode, if set, is the status code returned for all requests
+	// instead of the metadata.
+	StatusCode int
+
+	mu     sync.Mutex
+	server *httptest.Server
+	prev   *string
+}
+
+// NewTaskMetadataServer starts a new fake task metadata server. Callers must
+// call Close once they are done with it.
+func NewTaskMetadataServer() *TaskMetadataServer {
+	s := &TaskMetadataServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v4/task", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.write(w, s.TaskMetadata)
+	})
+	mux.HandleFunc("/v4", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.write(w, s.ContainerMetadata)
+	})
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+// write writes the metadata as the JSON response body, or the configured
+// error status code.
+func (s *TaskMetadataServer) write(w http.ResponseWriter, md interface{}) {
+	if s.StatusCode != 0 && s.StatusCode != http.StatusOK {
+		http.Error(w, http.StatusText(s.StatusCode), s.StatusCode)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(md)
+}
+
+// URL returns the URL of the fake task metadata endpoint.
+func (s *TaskMetadataServer) URL() string {
+	return s.server.URL + "/v4"
+}
+
+// SetEnv sets the ECS_CONTAINER_METADATA_URI_V4 environment variable to the
+// URL of the fake task metadata endpoint. The previous value is restored when
+// the server is closed.
+func (s *TaskMetadataServer) SetEnv() error {
+	if s.prev == nil {
+		prev, ok := os.LookupEnv(ecs.TaskMetadataEndpointEnvVar)
+		if ok {
+			s.prev = &prev
+		} else {
+			s.prev = new(string)
+		}
+	}
+	return os.Setenv(ecs.TaskMetadataEndpointEnvVar, s.URL())
+}
+
+// Close shuts down the fake server and restores the environment variable if
+// it was set by SetEnv.
+func (s *TaskMetadataServer) Close() error {
+	s.server.Close()
+	if s.prev == nil {
+		return nil
+	}
+	defer func() {
+		s.prev = nil
+	}()
+	if *s.prev == "" {
+		return os.Unsetenv(ecs.TaskMetadataEndpointEnvVar)
+	}
+	return os.Setenv(ecs.TaskMetadataEndpointEnvVar, *s.prev)
+}
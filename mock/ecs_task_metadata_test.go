@@ -0,0 +1,91 @@
+package mock
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskMetadataClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, s *TaskMetadataServer){
+		"GetTaskMetadataReturnsTaskMetadata": func(ctx context.Context, t *testing.T, s *TaskMetadataServer) {
+			s.TaskMetadata = ecs.TaskMetadata{
+				Cluster:    "cluster",
+				TaskARN:    "arn:aws:ecs:us-east-1:123456789012:task/cluster/abc",
+				Family:     "family",
+				Revision:   "1",
+				LaunchType: "FARGATE",
+				Limits:     ecs.TaskMetadataLimits{CPU: 0.25, Memory: 512},
+				Containers: []ecs.ContainerMetadata{{Name: "app", Image: "busybox"}},
+			}
+			c, err := ecs.NewTaskMetadataClient()
+			require.NoError(t, err)
+
+			md, err := c.GetTaskMetadata(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, s.TaskMetadata, *md)
+		},
+		"GetContainerMetadataReturnsContainerMetadata": func(ctx context.Context, t *testing.T, s *TaskMetadataServer) {
+			s.ContainerMetadata = ecs.ContainerMetadata{
+				DockerID: "docker-id",
+				Name:     "app",
+				Image:    "busybox",
+				Labels:   map[string]string{"label": "value"},
+				Networks: []ecs.ContainerNetworkMetadata{{NetworkMode: "awsvpc", IPv4Addresses: []string{"10.0.0.1"}}},
+			}
+			c, err := ecs.NewTaskMetadataClient()
+			require.NoError(t, err)
+
+			md, err := c.GetContainerMetadata(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, s.ContainerMetadata, *md)
+		},
+		"FailsWithErrorStatusCode": func(ctx context.Context, t *testing.T, s *TaskMetadataServer) {
+			s.StatusCode = http.StatusInternalServerError
+			c, err := ecs.NewTaskMetadataClient()
+			require.NoError(t, err)
+
+			md, err := c.GetTaskMetadata(ctx)
+			assert.Error(t, err)
+			assert.Zero(t, md)
+
+			cmd, err := c.GetContainerMetadata(ctx)
+			assert.Error(t, err)
+			assert.Zero(t, cmd)
+		},
+		"FailsWithCanceledContext": func(ctx context.Context, t *testing.T, s *TaskMetadataServer) {
+			c, err := ecs.NewTaskMetadataClient()
+			require.NoError(t, err)
+
+			cctx, ccancel := context.WithCancel(ctx)
+			ccancel()
+			_, err = c.GetTaskMetadata(cctx)
+			assert.Error(t, err)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			s := NewTaskMetadataServer()
+			defer func() {
+				assert.NoError(t, s.Close())
+			}()
+			require.NoError(t, s.SetEnv())
+
+			tCase(ctx, t, s)
+		})
+	}
+
+	t.Run("NewTaskMetadataClientFailsWithoutEnvVar", func(t *testing.T) {
+		testutil.OverrideEnvVar(t, ecs.TaskMetadataEndpointEnvVar, "")
+
+		_, err := ecs.NewTaskMetadataClient()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,89 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskRoleARNBuilder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer ResetGlobalIAMRoles()
+
+	const (
+		ecsTrustPolicy = `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": {"Service": "ecs-tasks.amazonaws.com"}, "Action": "sts:AssumeRole"}]}`
+		ec2TrustPolicy = `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": {"Service": "ec2.amazonaws.com"}, "Action": "sts:AssumeRole"}]}`
+	)
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *IAMClient){
+		"BuildsTaskRoleARN": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			roleARN, err := ecs.NewTaskRoleARNBuilder(c).
+				ForAccount(IAMAccountID).
+				WithRoleName("app").
+				WithTaskRole().
+				Build(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "arn:aws:iam::123456789012:role/app", roleARN)
+			require.NotZero(t, c.GetRoleInput)
+			assert.Equal(t, "app", utility.FromStringPtr(c.GetRoleInput.RoleName))
+		},
+		"BuildsDefaultExecutionRoleARN": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			roleARN, err := ecs.NewTaskRoleARNBuilder(c).WithExecutionRole().Build(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "arn:aws:iam::123456789012:role/ecsTaskExecutionRole", roleARN)
+		},
+		"FailsWithNonexistentRole": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			_, err := ecs.NewTaskRoleARNBuilder(c).WithRoleName("nonexistent").WithTaskRole().Build(ctx)
+			assert.Error(t, err)
+		},
+		"FailsWithRoleInDifferentAccount": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			_, err := ecs.NewTaskRoleARNBuilder(c).ForAccount("210987654321").WithRoleName("app").WithTaskRole().Build(ctx)
+			assert.Error(t, err)
+		},
+		"FailsWithRoleThatECSCannotAssume": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			_, err := ecs.NewTaskRoleARNBuilder(c).WithRoleName("ec2").WithTaskRole().Build(ctx)
+			assert.Error(t, err)
+		},
+		"FailsWithoutRoleKind": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			_, err := ecs.NewTaskRoleARNBuilder(c).WithRoleName("app").Build(ctx)
+			assert.Error(t, err)
+			assert.Zero(t, c.GetRoleInput)
+		},
+		"FailsWithoutTaskRoleName": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			_, err := ecs.NewTaskRoleARNBuilder(c).WithTaskRole().Build(ctx)
+			assert.Error(t, err)
+			assert.Zero(t, c.GetRoleInput)
+		},
+		"FailsWithInvalidAccountID": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			_, err := ecs.NewTaskRoleARNBuilder(c).ForAccount("1234").WithRoleName("app").WithTaskRole().Build(ctx)
+			assert.Error(t, err)
+			assert.Zero(t, c.GetRoleInput)
+		},
+		"FailsWithInvalidRoleName": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			_, err := ecs.NewTaskRoleARNBuilder(c).WithRoleName("app/role").WithTaskRole().Build(ctx)
+			assert.Error(t, err)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalIAMRoles()
+			for _, role := range []IAMRole{
+				{Name: "app", AssumeRolePolicyDocument: ecsTrustPolicy},
+				{Name: "ecsTaskExecutionRole", AssumeRolePolicyDocument: ecsTrustPolicy},
+				{Name: "ec2", AssumeRolePolicyDocument: ec2TrustPolicy},
+			} {
+				GlobalIAMRoles[role.Name] = role
+			}
+
+			tCase(tctx, t, &IAMClient{})
+		})
+	}
+}
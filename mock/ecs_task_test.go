@@ -0,0 +1,837 @@
+package mock
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsCloudWatch "github.com/aws/aws-sdk-go/service/cloudwatch"
+	awsECS "github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/ecs"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runTestTask registers a task definition and runs a task from it in the test
+// cluster.
+func runTestTask(ctx context.Context, t *testing.T, c cocoa.ECSClient) *awsECS.Task {
+	registerOut := testutil.RegisterTaskDefinition(ctx, t, c, testutil.ValidRegisterTaskDefinitionInput(t))
+	runOut, err := c.RunTask(ctx, &awsECS.RunTaskInput{
+		Cluster:        aws.String(testutil.ECSClusterName()),
+		TaskDefinition: registerOut.TaskDefinition.TaskDefinitionArn,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, runOut)
+	require.Len(t, runOut.Tasks, 1)
+	return runOut.Tasks[0]
+}
+
+func TestStopTaskGracefully(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer resetECSAndSecretsManagerCache()
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *ECSClient){
+		"SucceedsWhenTaskStops": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			task := runTestTask(ctx, t, c)
+
+			require.NoError(t, ecs.StopTaskGracefully(ctx, c, testutil.ECSClusterName(), utility.FromStringPtr(task.TaskArn), "reason", time.Minute))
+
+			require.NotZero(t, c.StopTaskInput)
+			assert.Equal(t, "reason", utility.FromStringPtr(c.StopTaskInput.Reason))
+			stopped := GlobalECSService.Clusters[testutil.ECSClusterName()][utility.FromStringPtr(task.TaskArn)]
+			assert.Equal(t, awsECS.DesiredStatusStopped, utility.FromStringPtr(stopped.Status))
+		},
+		"SucceedsWhenTaskDoesNotStopWithinGracePeriod": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			task := runTestTask(ctx, t, c)
+			c.StopTaskOutput = &awsECS.StopTaskOutput{}
+
+			require.NoError(t, ecs.StopTaskGracefully(ctx, c, testutil.ECSClusterName(), utility.FromStringPtr(task.TaskArn), "reason", 10*time.Millisecond))
+
+			require.NotZero(t, c.DescribeTasksInput)
+			running := GlobalECSService.Clusters[testutil.ECSClusterName()][utility.FromStringPtr(task.TaskArn)]
+			assert.NotEqual(t, awsECS.DesiredStatusStopped, utility.FromStringPtr(running.Status))
+		},
+		"FailsWithNonexistentTask": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			err := ecs.StopTaskGracefully(ctx, c, testutil.ECSClusterName(), "nonexistent", "reason", time.Minute)
+			assert.True(t, cocoa.IsECSTaskNotFoundError(err))
+		},
+		"FailsWhenTaskCannotBeDescribed": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			task := runTestTask(ctx, t, c)
+			c.StopTaskOutput = &awsECS.StopTaskOutput{}
+			c.DescribeTasksOutput = &awsECS.DescribeTasksOutput{}
+
+			assert.Error(t, ecs.StopTaskGracefully(ctx, c, testutil.ECSClusterName(), utility.FromStringPtr(task.TaskArn), "reason", time.Minute))
+		},
+		"ReturnsAllFailuresWhenTaskCannotBeDescribed": func(ctx context.Context, t *testing.T, c *ECSClient) {
+			task := runTestTask(ctx, t, c)
+			c.StopTaskOutput = &awsECS.StopTaskOutput{}
+			c.DescribeTasksOutput = &awsECS.DescribeTasksOutput{
+				Failures: []*awsECS.Failure{
+					{Arn: task.TaskArn, Reason: aws.String("first reason")},
+					{Arn: task.TaskArn, Reason: aws.String("MISSING")},
+				},
+			}
+
+			err := ecs.StopTaskGracefully(ctx, c, testutil.ECSClusterName(), utility.FromStringPtr(task.TaskArn), "reason", time.Minute)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "first reason")
+			assert.True(t, cocoa.IsECSTaskNotFoundError(err))
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+
+			c := &ECSClient{}
+			defer func() {
+				assert.NoError(t, c.Close(tctx))
+			}()
+
+			tCase(tctx, t, c)
+		})
+	}
+}
+
+// sequencedStatusECSClient is an ECS client whose tasks report each status in
+// the sequence on successive calls to DescribeTasks.
+type sequencedStatusECSClient struct {
+	ECSClient
+	statuses []string
+	calls    int
+}
+
+func (c *sequencedStatusECSClient) DescribeTasks(ctx context.Context, in *awsECS.DescribeTasksInput) (*awsECS.DescribeTasksOutput, error) {
+	if c.calls >= len(c.statuses) {
+		return nil, errors.New("no more statuses")
+	}
+	status := c.statuses[c.calls]
+	c.calls++
+	return &awsECS.DescribeTasksOutput{
+		Tasks: []*awsECS.Task{{
+			TaskArn:    in.Tasks[0],
+			LastStatus: aws.String(status),
+		}},
+	}, nil
+}
+
+func TestTaskStateTracker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	collectEvents := func(t *testing.T, events <-chan ecs.TaskStateEvent) []ecs.TaskStateEvent {
+		var collected []ecs.TaskStateEvent
+		for e := range events {
+			collected = append(collected, e)
+		}
+		return collected
+	}
+
+	t.Run("FailsWithoutClient", func(t *testing.T) {
+		_, err := ecs.NewTaskStateTracker(nil, time.Millisecond)
+		assert.Error(t, err)
+	})
+	t.Run("EmitsEventForEachTransitionUntilStopped", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		c := &sequencedStatusECSClient{statuses: []string{
+			string(ecs.TaskStatusProvisioning),
+			string(ecs.TaskStatusProvisioning),
+			string(ecs.TaskStatusPending),
+			string(ecs.TaskStatusRunning),
+			string(ecs.TaskStatusRunning),
+			string(ecs.TaskStatusStopping),
+			string(ecs.TaskStatusStopped),
+		}}
+		tracker, err := ecs.NewTaskStateTracker(c, time.Millisecond)
+		require.NoError(t, err)
+
+		events, err := tracker.Watch(tctx, "cluster", "task")
+		require.NoError(t, err)
+
+		collected := collectEvents(t, events)
+		expected := []struct {
+			old ecs.TaskStatus
+			new ecs.TaskStatus
+		}{
+			{new: ecs.TaskStatusProvisioning},
+			{old: ecs.TaskStatusProvisioning, new: ecs.TaskStatusPending},
+			{old: ecs.TaskStatusPending, new: ecs.TaskStatusRunning},
+			{old: ecs.TaskStatusRunning, new: ecs.TaskStatusStopping},
+			{old: ecs.TaskStatusStopping, new: ecs.TaskStatusStopped},
+		}
+		require.Len(t, collected, len(expected))
+		for i, e := range collected {
+			assert.NoError(t, e.Err)
+			assert.Equal(t, expected[i].old, e.OldStatus)
+			assert.Equal(t, expected[i].new, e.NewStatus)
+			assert.NotZero(t, e.Timestamp)
+		}
+	})
+	t.Run("ClosesChannelImmediatelyForStoppedTask", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		c := &sequencedStatusECSClient{statuses: []string{string(ecs.TaskStatusStopped)}}
+		tracker, err := ecs.NewTaskStateTracker(c, time.Millisecond)
+		require.NoError(t, err)
+
+		events, err := tracker.Watch(tctx, "cluster", "task")
+		require.NoError(t, err)
+
+		collected := collectEvents(t, events)
+		require.Len(t, collected, 1)
+		assert.Equal(t, ecs.TaskStatusStopped, collected[0].NewStatus)
+	})
+	t.Run("EmitsErrorWhenStatusCannotBeChecked", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		c := &sequencedStatusECSClient{statuses: []string{string(ecs.TaskStatusRunning)}}
+		tracker, err := ecs.NewTaskStateTracker(c, time.Millisecond)
+		require.NoError(t, err)
+
+		events, err := tracker.Watch(tctx, "cluster", "task")
+		require.NoError(t, err)
+
+		collected := collectEvents(t, events)
+		require.Len(t, collected, 2)
+		assert.Equal(t, ecs.TaskStatusRunning, collected[0].NewStatus)
+		assert.Error(t, collected[1].Err)
+		assert.Equal(t, ecs.TaskStatusRunning, collected[1].OldStatus)
+	})
+	t.Run("FailsWithNonexistentTask", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		resetECSAndSecretsManagerCache()
+		defer resetECSAndSecretsManagerCache()
+
+		tracker, err := ecs.NewTaskStateTracker(&ECSClient{}, time.Millisecond)
+		require.NoError(t, err)
+
+		events, err := tracker.Watch(tctx, testutil.ECSClusterName(), "nonexistent")
+		assert.True(t, cocoa.IsECSTaskNotFoundError(err))
+		assert.Zero(t, events)
+	})
+}
+
+// stoppingECSClient is an ECS client that stops all the described tasks with
+// the given exit code before describing them.
+type stoppingECSClient struct {
+	ECSClient
+	exitCode int64
+}
+
+func (c *stoppingECSClient) DescribeTasks(ctx context.Context, in *awsECS.DescribeTasksInput) (*awsECS.DescribeTasksOutput, error) {
+	cluster := GlobalECSService.Clusters[utility.FromStringPtr(in.Cluster)]
+	for _, arn := range utility.FromStringPtrSlice(in.Tasks) {
+		task, ok := cluster[arn]
+		if !ok {
+			continue
+		}
+		task.Status = aws.String(string(ecs.TaskStatusStopped))
+		task.StopReason = aws.String("task finished")
+		for i := range task.Containers {
+			task.Containers[i].ExitCode = aws.Int64(c.exitCode)
+		}
+		cluster[arn] = task
+	}
+	return c.ECSClient.DescribeTasks(ctx, in)
+}
+
+// runNotifyingECSClient is an ECS client that signals each time it has run a
+// task.
+type runNotifyingECSClient struct {
+	ECSClient
+	ran chan struct{}
+}
+
+func (c *runNotifyingECSClient) RunTask(ctx context.Context, in *awsECS.RunTaskInput) (*awsECS.RunTaskOutput, error) {
+	out, err := c.ECSClient.RunTask(ctx, in)
+	c.ran <- struct{}{}
+	return out, err
+}
+
+// cancelAfterRuns cancels once the client has run the given number of tasks.
+func cancelAfterRuns(ctx context.Context, c *runNotifyingECSClient, n int, cancel context.CancelFunc) {
+	go func() {
+		defer cancel()
+		for i := 0; i < n; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.ran:
+			}
+		}
+	}()
+}
+
+func TestTaskGroupRunner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer resetECSAndSecretsManagerCache()
+
+	t.Run("FailsWithoutClient", func(t *testing.T) {
+		r, err := ecs.NewTaskGroupRunner(nil)
+		assert.Error(t, err)
+		assert.Zero(t, r)
+	})
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *stoppingECSClient, taskDefARN string){
+		"ReturnsExitCodesAndStopReasons": func(ctx context.Context, t *testing.T, c *stoppingECSClient, taskDefARN string) {
+			c.exitCode = 2
+			r, err := ecs.NewTaskGroupRunner(c)
+			require.NoError(t, err)
+			// The mock client is not thread-safe, so tasks are run one at a
+			// time.
+			r.SetMaxConcurrency(1).SetPollInterval(time.Millisecond)
+
+			var inputs []*awsECS.RunTaskInput
+			for i := 0; i < 3; i++ {
+				inputs = append(inputs, &awsECS.RunTaskInput{
+					Cluster:        aws.String(testutil.ECSClusterName()),
+					TaskDefinition: aws.String(taskDefARN),
+				})
+			}
+
+			res, err := r.Run(ctx, inputs)
+			require.NoError(t, err)
+			require.NotZero(t, res)
+			assert.False(t, res.HasErrors())
+			require.Len(t, res.Tasks, len(inputs))
+			for i, task := range res.Tasks {
+				assert.NoError(t, task.Err)
+				assert.Equal(t, inputs[i], task.Input)
+				assert.NotZero(t, task.TaskARN)
+				assert.Equal(t, testutil.ECSClusterName(), task.Cluster)
+				assert.Equal(t, "task finished", task.StopReason)
+				require.NotEmpty(t, task.ExitCodes)
+				for _, exitCode := range task.ExitCodes {
+					assert.EqualValues(t, 2, exitCode)
+				}
+			}
+		},
+		"RecordsErrorsForTasksThatFailToStart": func(ctx context.Context, t *testing.T, c *stoppingECSClient, taskDefARN string) {
+			r, err := ecs.NewTaskGroupRunner(c)
+			require.NoError(t, err)
+			// The mock client is not thread-safe, so tasks are run one at a
+			// time.
+			r.SetMaxConcurrency(1).SetPollInterval(time.Millisecond)
+
+			inputs := []*awsECS.RunTaskInput{
+				{
+					Cluster:        aws.String(testutil.ECSClusterName()),
+					TaskDefinition: aws.String(taskDefARN),
+				},
+				{
+					Cluster:        aws.String(testutil.ECSClusterName()),
+					TaskDefinition: aws.String("nonexistent"),
+				},
+			}
+
+			res, err := r.Run(ctx, inputs)
+			require.NoError(t, err)
+			require.NotZero(t, res)
+			assert.True(t, res.HasErrors())
+			require.Len(t, res.Tasks, 2)
+
+			assert.NoError(t, res.Tasks[0].Err)
+			assert.NotZero(t, res.Tasks[0].TaskARN)
+			assert.NotEmpty(t, res.Tasks[0].ExitCodes)
+
+			assert.Error(t, res.Tasks[1].Err)
+			assert.Zero(t, res.Tasks[1].TaskARN)
+			assert.Equal(t, inputs[1], res.Tasks[1].Input)
+		},
+		"FailsWhenTasksDoNotStopBeforeContextIsDone": func(ctx context.Context, t *testing.T, c *stoppingECSClient, taskDefARN string) {
+			rc := &runNotifyingECSClient{ran: make(chan struct{}, 1)}
+			r, err := ecs.NewTaskGroupRunner(rc)
+			require.NoError(t, err)
+			// The mock client is not thread-safe, so tasks are run one at a
+			// time.
+			r.SetMaxConcurrency(1).SetPollInterval(time.Millisecond)
+
+			tctx, tcancel := context.WithCancel(ctx)
+			defer tcancel()
+			cancelAfterRuns(tctx, rc, 1, tcancel)
+
+			res, err := r.Run(tctx, []*awsECS.RunTaskInput{{
+				Cluster:        aws.String(testutil.ECSClusterName()),
+				TaskDefinition: aws.String(taskDefARN),
+			}})
+			assert.Error(t, err)
+			assert.Zero(t, res)
+		},
+		"StopsRunningTasksWithGracefulShutdownWhenContextIsDone": func(ctx context.Context, t *testing.T, c *stoppingECSClient, taskDefARN string) {
+			inputs := []*awsECS.RunTaskInput{
+				{
+					Cluster:        aws.String(testutil.ECSClusterName()),
+					TaskDefinition: aws.String(taskDefARN),
+				},
+				{
+					Cluster:        aws.String(testutil.ECSClusterName()),
+					TaskDefinition: aws.String(taskDefARN),
+				},
+			}
+
+			rc := &runNotifyingECSClient{ran: make(chan struct{}, len(inputs))}
+			r, err := ecs.NewTaskGroupRunner(rc)
+			require.NoError(t, err)
+			r.SetMaxConcurrency(1).SetPollInterval(time.Millisecond).WithGracefulShutdown("shutting down")
+
+			tctx, tcancel := context.WithCancel(ctx)
+			defer tcancel()
+			cancelAfterRuns(tctx, rc, len(inputs), tcancel)
+			res, err := r.Run(tctx, inputs)
+			assert.Error(t, err)
+			assert.Zero(t, res)
+
+			tasks := GlobalECSService.Clusters[testutil.ECSClusterName()]
+			require.Len(t, tasks, len(inputs))
+			for _, task := range tasks {
+				assert.Equal(t, awsECS.DesiredStatusStopped, utility.FromStringPtr(task.Status))
+				assert.Equal(t, "shutting down", utility.FromStringPtr(task.StopReason))
+			}
+
+			assert.NoError(t, r.Close(ctx))
+		},
+		"StopsRunningTasksWithGracefulShutdownWhenClosed": func(ctx context.Context, t *testing.T, c *stoppingECSClient, taskDefARN string) {
+			rc := &runNotifyingECSClient{ran: make(chan struct{}, 1)}
+			r, err := ecs.NewTaskGroupRunner(rc)
+			require.NoError(t, err)
+			r.SetMaxConcurrency(1).SetPollInterval(time.Millisecond).WithGracefulShutdown("shutting down")
+
+			errs := make(chan error, 1)
+			go func() {
+				_, err := r.Run(ctx, []*awsECS.RunTaskInput{{
+					Cluster:        aws.String(testutil.ECSClusterName()),
+					TaskDefinition: aws.String(taskDefARN),
+				}})
+				errs <- err
+			}()
+
+			select {
+			case <-ctx.Done():
+				require.FailNow(t, "context is done before task started")
+			case <-rc.ran:
+			}
+			assert.NoError(t, r.Close(ctx))
+			assert.Error(t, <-errs)
+
+			tasks := GlobalECSService.Clusters[testutil.ECSClusterName()]
+			require.Len(t, tasks, 1)
+			for _, task := range tasks {
+				assert.Equal(t, awsECS.DesiredStatusStopped, utility.FromStringPtr(task.Status))
+				assert.Equal(t, "shutting down", utility.FromStringPtr(task.StopReason))
+			}
+		},
+		"LeavesTasksRunningWithoutGracefulShutdown": func(ctx context.Context, t *testing.T, c *stoppingECSClient, taskDefARN string) {
+			rc := &runNotifyingECSClient{ran: make(chan struct{}, 1)}
+			r, err := ecs.NewTaskGroupRunner(rc)
+			require.NoError(t, err)
+			r.SetMaxConcurrency(1).SetPollInterval(time.Millisecond)
+
+			tctx, tcancel := context.WithCancel(ctx)
+			defer tcancel()
+			cancelAfterRuns(tctx, rc, 1, tcancel)
+
+			_, err = r.Run(tctx, []*awsECS.RunTaskInput{{
+				Cluster:        aws.String(testutil.ECSClusterName()),
+				TaskDefinition: aws.String(taskDefARN),
+			}})
+			assert.Error(t, err)
+			assert.NoError(t, r.Close(ctx))
+
+			tasks := GlobalECSService.Clusters[testutil.ECSClusterName()]
+			require.Len(t, tasks, 1)
+			for _, task := range tasks {
+				assert.NotEqual(t, awsECS.DesiredStatusStopped, utility.FromStringPtr(task.Status))
+			}
+		},
+		"FailsAfterClose": func(ctx context.Context, t *testing.T, c *stoppingECSClient, taskDefARN string) {
+			r, err := ecs.NewTaskGroupRunner(c)
+			require.NoError(t, err)
+			require.NoError(t, r.Close(ctx))
+
+			res, err := r.Run(ctx, []*awsECS.RunTaskInput{{
+				Cluster:        aws.String(testutil.ECSClusterName()),
+				TaskDefinition: aws.String(taskDefARN),
+			}})
+			assert.Error(t, err)
+			assert.Zero(t, res)
+			assert.Empty(t, GlobalECSService.Clusters[testutil.ECSClusterName()])
+		},
+		"SucceedsWithNoInputs": func(ctx context.Context, t *testing.T, c *stoppingECSClient, taskDefARN string) {
+			r, err := ecs.NewTaskGroupRunner(c)
+			require.NoError(t, err)
+
+			res, err := r.Run(ctx, nil)
+			require.NoError(t, err)
+			require.NotZero(t, res)
+			assert.Empty(t, res.Tasks)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+
+			c := &stoppingECSClient{}
+			defer func() {
+				assert.NoError(t, c.Close(tctx))
+			}()
+
+			registerOut := testutil.RegisterTaskDefinition(tctx, t, c, testutil.ValidRegisterTaskDefinitionInput(t))
+
+			tCase(tctx, t, c, utility.FromStringPtr(registerOut.TaskDefinition.TaskDefinitionArn))
+		})
+	}
+}
+
+func TestFetchTaskLogs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer func() {
+		resetECSAndSecretsManagerCache()
+		ResetGlobalCloudWatchLogs()
+	}()
+
+	const logGroup = "log_group"
+
+	// runTaskWithLogConfigs runs a task whose containers have the given log
+	// configurations, keyed by container name.
+	runTaskWithLogConfigs := func(ctx context.Context, t *testing.T, c *ECSClient, logConfigs map[string]*awsECS.LogConfiguration) *awsECS.Task {
+		in := testutil.ValidRegisterTaskDefinitionInput(t)
+		in.ContainerDefinitions = nil
+		for name, logConfig := range logConfigs {
+			in.ContainerDefinitions = append(in.ContainerDefinitions, &awsECS.ContainerDefinition{
+				Name:             aws.String(name),
+				Image:            aws.String("busybox"),
+				LogConfiguration: logConfig,
+			})
+		}
+		registerOut := testutil.RegisterTaskDefinition(ctx, t, c, in)
+		runOut, err := c.RunTask(ctx, &awsECS.RunTaskInput{
+			Cluster:        aws.String(testutil.ECSClusterName()),
+			TaskDefinition: registerOut.TaskDefinition.TaskDefinitionArn,
+		})
+		require.NoError(t, err)
+		require.Len(t, runOut.Tasks, 1)
+		return runOut.Tasks[0]
+	}
+	awsLogsConfig := func(prefix string) *awsECS.LogConfiguration {
+		return &awsECS.LogConfiguration{
+			LogDriver: aws.String("awslogs"),
+			Options: map[string]*string{
+				"awslogs-group":         aws.String(logGroup),
+				"awslogs-stream-prefix": aws.String(prefix),
+			},
+		}
+	}
+	taskID := func(task *awsECS.Task) string {
+		arn := utility.FromStringPtr(task.TaskArn)
+		return arn[strings.LastIndex(arn, "/")+1:]
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, ecsc *ECSClient, cwlc *CloudWatchLogsClient){
+		"ReturnsLogsForEachContainer": func(ctx context.Context, t *testing.T, ecsc *ECSClient, cwlc *CloudWatchLogsClient) {
+			task := runTaskWithLogConfigs(ctx, t, ecsc, map[string]*awsECS.LogConfiguration{
+				"c0": awsLogsConfig("prefix"),
+				"c1": awsLogsConfig("prefix"),
+				"c2": {LogDriver: aws.String("json-file")},
+			})
+			GlobalCloudWatchLogs[logGroup] = CloudWatchLogGroup{
+				"prefix/c0/" + taskID(task): {"foo", "bar"},
+				"prefix/c1/" + taskID(task): {"bat"},
+			}
+
+			logs, err := ecs.FetchTaskLogs(ctx, ecsc, cwlc, testutil.ECSClusterName(), utility.FromStringPtr(task.TaskArn))
+			require.NoError(t, err)
+			assert.Equal(t, map[string][]string{
+				"c0": {"foo", "bar"},
+				"c1": {"bat"},
+			}, logs)
+		},
+		"ReadsAllPagesOfLogs": func(ctx context.Context, t *testing.T, ecsc *ECSClient, cwlc *CloudWatchLogsClient) {
+			task := runTaskWithLogConfigs(ctx, t, ecsc, map[string]*awsECS.LogConfiguration{
+				"c0": awsLogsConfig("prefix"),
+			})
+			GlobalCloudWatchLogs[logGroup] = CloudWatchLogGroup{
+				"prefix/c0/" + taskID(task): {"foo"},
+			}
+
+			logs, err := ecs.FetchTaskLogs(ctx, ecsc, cwlc, testutil.ECSClusterName(), utility.FromStringPtr(task.TaskArn))
+			require.NoError(t, err)
+			assert.Equal(t, map[string][]string{"c0": {"foo"}}, logs)
+			require.NotZero(t, cwlc.GetLogEventsInput)
+			assert.NotZero(t, cwlc.GetLogEventsInput.NextToken, "should have requested the next page of logs")
+		},
+		"ReturnsNoLogsWithoutAWSLogsDriver": func(ctx context.Context, t *testing.T, ecsc *ECSClient, cwlc *CloudWatchLogsClient) {
+			task := runTaskWithLogConfigs(ctx, t, ecsc, map[string]*awsECS.LogConfiguration{
+				"c0": nil,
+			})
+
+			logs, err := ecs.FetchTaskLogs(ctx, ecsc, cwlc, testutil.ECSClusterName(), utility.FromStringPtr(task.TaskArn))
+			require.NoError(t, err)
+			assert.Empty(t, logs)
+			assert.Zero(t, cwlc.GetLogEventsInput)
+		},
+		"FailsWithoutLogStreamPrefix": func(ctx context.Context, t *testing.T, ecsc *ECSClient, cwlc *CloudWatchLogsClient) {
+			task := runTaskWithLogConfigs(ctx, t, ecsc, map[string]*awsECS.LogConfiguration{
+				"c0": awsLogsConfig(""),
+			})
+
+			logs, err := ecs.FetchTaskLogs(ctx, ecsc, cwlc, testutil.ECSClusterName(), utility.FromStringPtr(task.TaskArn))
+			assert.Error(t, err)
+			assert.Zero(t, logs)
+		},
+		"FailsWithNonexistentLogStream": func(ctx context.Context, t *testing.T, ecsc *ECSClient, cwlc *CloudWatchLogsClient) {
+			task := runTaskWithLogConfigs(ctx, t, ecsc, map[string]*awsECS.LogConfiguration{
+				"c0": awsLogsConfig("prefix"),
+			})
+
+			logs, err := ecs.FetchTaskLogs(ctx, ecsc, cwlc, testutil.ECSClusterName(), utility.FromStringPtr(task.TaskArn))
+			assert.Error(t, err)
+			assert.Zero(t, logs)
+		},
+		"FailsWithNonexistentTask": func(ctx context.Context, t *testing.T, ecsc *ECSClient, cwlc *CloudWatchLogsClient) {
+			logs, err := ecs.FetchTaskLogs(ctx, ecsc, cwlc, testutil.ECSClusterName(), "nonexistent")
+			assert.True(t, cocoa.IsECSTaskNotFoundError(err))
+			assert.Zero(t, logs)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			resetECSAndSecretsManagerCache()
+			ResetGlobalCloudWatchLogs()
+
+			ecsc := &ECSClient{}
+			defer func() {
+				assert.NoError(t, ecsc.Close(tctx))
+			}()
+			cwlc := &CloudWatchLogsClient{}
+			defer func() {
+				assert.NoError(t, cwlc.Close(tctx))
+			}()
+
+			tCase(tctx, t, ecsc, cwlc)
+		})
+	}
+}
+
+func TestReportTaskResourceUtilization(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer ResetGlobalCloudWatchMetrics()
+
+	const (
+		cluster   = "arn:aws:ecs:us-east-1:123456789012:cluster/cluster"
+		taskARN   = "arn:aws:ecs:us-east-1:123456789012:task/cluster/0123456789abcdef"
+		namespace = "cocoa"
+	)
+
+	putContainerInsightsMetric := func(t *testing.T, name string, values ...float64) {
+		for _, v := range values {
+			GlobalCloudWatchMetrics["ECS/ContainerInsights"] = append(GlobalCloudWatchMetrics["ECS/ContainerInsights"], StoredMetricDatum{
+				Name: name,
+				Dimensions: map[string]string{
+					"ClusterName": "cluster",
+					"TaskId":      "0123456789abcdef",
+				},
+				Value:     v,
+				Timestamp: time.Now().Add(-time.Hour),
+			})
+		}
+	}
+	publishedValue := func(t *testing.T, name string) float64 {
+		for _, d := range GlobalCloudWatchMetrics[namespace] {
+			if d.Name == name {
+				assert.Equal(t, awsCloudWatch.StandardUnitPercent, d.Unit)
+				assert.Equal(t, map[string]string{"ClusterName": "cluster", "TaskId": "0123456789abcdef"}, d.Dimensions)
+				return d.Value
+			}
+		}
+		require.FailNow(t, "metric was not published", name)
+		return 0
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *CloudWatchClient){
+		"PublishesUtilizationSummary": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			putContainerInsightsMetric(t, "CpuUtilized", 128, 384)
+			putContainerInsightsMetric(t, "CpuReserved", 512, 512)
+			putContainerInsightsMetric(t, "MemoryUtilized", 512)
+			putContainerInsightsMetric(t, "MemoryReserved", 1024)
+
+			require.NoError(t, ecs.ReportTaskResourceUtilization(ctx, cluster, taskARN, c, namespace))
+
+			require.Len(t, GlobalCloudWatchMetrics[namespace], 4)
+			assert.Equal(t, 50.0, publishedValue(t, "CPUUtilizationAverage"))
+			assert.Equal(t, 75.0, publishedValue(t, "CPUUtilizationMaximum"))
+			assert.Equal(t, 50.0, publishedValue(t, "MemoryUtilizationAverage"))
+			assert.Equal(t, 50.0, publishedValue(t, "MemoryUtilizationMaximum"))
+		},
+		"ComputesMaximumRelativeToMaximumReservation": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			putContainerInsightsMetric(t, "CpuUtilized", 128, 512)
+			putContainerInsightsMetric(t, "CpuReserved", 256, 1024)
+			putContainerInsightsMetric(t, "MemoryUtilized", 512)
+			putContainerInsightsMetric(t, "MemoryReserved", 1024)
+
+			require.NoError(t, ecs.ReportTaskResourceUtilization(ctx, cluster, taskARN, c, namespace))
+
+			assert.Equal(t, 50.0, publishedValue(t, "CPUUtilizationAverage"))
+			assert.Equal(t, 50.0, publishedValue(t, "CPUUtilizationMaximum"))
+		},
+		"UsesGivenClusterWithOldTaskARNFormat": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			putContainerInsightsMetric(t, "CpuUtilized", 128)
+			putContainerInsightsMetric(t, "CpuReserved", 512)
+			putContainerInsightsMetric(t, "MemoryUtilized", 512)
+			putContainerInsightsMetric(t, "MemoryReserved", 1024)
+
+			require.NoError(t, ecs.ReportTaskResourceUtilization(ctx, cluster, "arn:aws:ecs:us-east-1:123456789012:task/0123456789abcdef", c, namespace))
+
+			assert.Equal(t, 25.0, publishedValue(t, "CPUUtilizationAverage"))
+		},
+		"FailsWithInvalidTaskARN": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			assert.Error(t, ecs.ReportTaskResourceUtilization(ctx, cluster, "0123456789abcdef", c, namespace))
+			assert.Zero(t, c.GetMetricStatisticsInput)
+		},
+		"FailsWithoutContainerInsightsMetrics": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			assert.Error(t, ecs.ReportTaskResourceUtilization(ctx, cluster, taskARN, c, namespace))
+			assert.Empty(t, GlobalCloudWatchMetrics[namespace])
+		},
+		"FailsWithZeroReservedResources": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			putContainerInsightsMetric(t, "CpuUtilized", 128)
+			putContainerInsightsMetric(t, "CpuReserved", 0)
+			putContainerInsightsMetric(t, "MemoryUtilized", 512)
+			putContainerInsightsMetric(t, "MemoryReserved", 1024)
+
+			assert.Error(t, ecs.ReportTaskResourceUtilization(ctx, cluster, taskARN, c, namespace))
+			assert.Empty(t, GlobalCloudWatchMetrics[namespace])
+		},
+		"FailsWithoutNamespace": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			assert.Error(t, ecs.ReportTaskResourceUtilization(ctx, cluster, taskARN, c, ""))
+			assert.Zero(t, c.GetMetricStatisticsInput)
+		},
+		"FailsWhenMetricsCannotBePublished": func(ctx context.Context, t *testing.T, c *CloudWatchClient) {
+			putContainerInsightsMetric(t, "CpuUtilized", 128)
+			putContainerInsightsMetric(t, "CpuReserved", 512)
+			putContainerInsightsMetric(t, "MemoryUtilized", 512)
+			putContainerInsightsMetric(t, "MemoryReserved", 1024)
+			c.PutMetricDataError = errors.New("fake error")
+
+			assert.Error(t, ecs.ReportTaskResourceUtilization(ctx, cluster, taskARN, c, namespace))
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalCloudWatchMetrics()
+
+			c := &CloudWatchClient{}
+			defer func() {
+				assert.NoError(t, c.Close(tctx))
+			}()
+
+			tCase(tctx, t, c)
+		})
+	}
+}
+
+func TestWaitForTaskStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	isRunning := func(task *awsECS.Task) bool {
+		return utility.FromStringPtr(task.LastStatus) == string(ecs.TaskStatusRunning)
+	}
+
+	t.Run("ReturnsTaskOncePredicateIsSatisfied", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		c := &sequencedStatusECSClient{statuses: []string{
+			string(ecs.TaskStatusProvisioning),
+			string(ecs.TaskStatusPending),
+			string(ecs.TaskStatusRunning),
+		}}
+
+		task, err := ecs.WaitForTaskStatus(tctx, c, "cluster", "task", isRunning, time.Millisecond)
+		require.NoError(t, err)
+		require.NotZero(t, task)
+		assert.Equal(t, "task", utility.FromStringPtr(task.TaskArn))
+		assert.Equal(t, string(ecs.TaskStatusRunning), utility.FromStringPtr(task.LastStatus))
+		assert.Equal(t, 3, c.calls)
+	})
+	t.Run("ReturnsImmediatelyIfPredicateIsAlreadySatisfied", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		c := &sequencedStatusECSClient{statuses: []string{string(ecs.TaskStatusRunning)}}
+
+		task, err := ecs.WaitForTaskStatus(tctx, c, "cluster", "task", isRunning, time.Hour)
+		require.NoError(t, err)
+		require.NotZero(t, task)
+		assert.Equal(t, 1, c.calls)
+	})
+	t.Run("FailsWhenTaskCannotBeDescribed", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		c := &sequencedStatusECSClient{statuses: []string{string(ecs.TaskStatusPending)}}
+
+		task, err := ecs.WaitForTaskStatus(tctx, c, "cluster", "task", isRunning, time.Millisecond)
+		assert.Error(t, err)
+		assert.Zero(t, task)
+	})
+	t.Run("FailsWhenContextIsDone", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		resetECSAndSecretsManagerCache()
+		defer resetECSAndSecretsManagerCache()
+
+		c := &ECSClient{}
+		runTask := runTestTask(tctx, t, c)
+
+		timeoutCtx, timeoutCancel := context.WithTimeout(tctx, 10*time.Millisecond)
+		defer timeoutCancel()
+
+		task, err := ecs.WaitForTaskStatus(timeoutCtx, c, testutil.ECSClusterName(), utility.FromStringPtr(runTask.TaskArn), func(*awsECS.Task) bool { return false }, time.Millisecond)
+		assert.Error(t, err)
+		assert.Zero(t, task)
+	})
+	t.Run("FailsWithNonexistentTask", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		resetECSAndSecretsManagerCache()
+		defer resetECSAndSecretsManagerCache()
+
+		task, err := ecs.WaitForTaskStatus(tctx, &ECSClient{}, testutil.ECSClusterName(), "nonexistent", isRunning, time.Millisecond)
+		assert.True(t, cocoa.IsECSTaskNotFoundError(err))
+		assert.Zero(t, task)
+	})
+	t.Run("FailsWithoutPredicate", func(t *testing.T) {
+		task, err := ecs.WaitForTaskStatus(ctx, &ECSClient{}, "cluster", "task", nil, time.Millisecond)
+		assert.Error(t, err)
+		assert.Zero(t, task)
+	})
+}
@@ -0,0 +1,102 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/evergreen-ci/utility"
+)
+
+// IAMRole is a representation of a role stored in the fake IAM storage.
+type IAMRole struct {
+	// Name is the name of the role.
+	Name string
+	// AssumeRolePolicyDocument is the JSON trust policy that determines which
+	// principals can assume the role.
+	AssumeRolePolicyDocument string
+}
+
+// ARN returns the ARN of the role.
+func (r *IAMRole) ARN() string {
+	return fmt.Sprintf("arn:aws:iam::%s:role/%s", IAMAccountID, r.Name)
+}
+
+func (r *IAMRole) export() *iam.Role {
+	role := &iam.Role{
+		RoleName: utility.ToStringPtr(r.Name),
+		RoleId:   utility.ToStringPtr("AROA" + r.Name),
+		Arn:      utility.ToStringPtr(r.ARN()),
+		Path:     utility.ToStringPtr("/"),
+	}
+	if r.AssumeRolePolicyDocument != "" {
+		// IAM returns the policy document URL-encoded.
+		role.AssumeRolePolicyDocument = utility.ToStringPtr(url.PathEscape(r.AssumeRolePolicyDocument))
+	}
+	return role
+}
+
+// IAMAccountID is the ID of the account that owns the roles in the fake IAM
+// storage.
+const IAMAccountID = "123456789012"
+
+// GlobalIAMRoles is a global fake IAM storage that maps each role name to its
+// role. This can be used indirectly with the IAMClient to access roles, or
+// used directly.
+var GlobalIAMRoles map[string]IAMRole
+
+func init() {
+	ResetGlobalIAMRoles()
+}
+
+// ResetGlobalIAMRoles resets the global fake IAM storage to an initialized but
+// clean state.
+func ResetGlobalIAMRoles() {
+	GlobalIAMRoles = map[string]IAMRole{}
+}
+
+// IAMClient provides a mock implementation of a cocoa.IAMClient. This makes it
+// possible to introspect on inputs to the client and control the client's
+// output. It provides some default implementations where possible. By
+// default, it will issue the API calls to the fake GlobalIAMRoles.
+type IAMClient struct {
+	GetRoleInput  *iam.GetRoleInput
+	GetRoleOutput *iam.GetRoleOutput
+	GetRoleError  error
+
+	CloseError error
+}
+
+// GetRole saves the input and returns information about the matching role.
+// The mock output can be customized. By default, it will return the role in
+// the global fake IAM storage with the given name.
+func (c *IAMClient) GetRole(ctx context.Context, in *iam.GetRoleInput) (*iam.GetRoleOutput, error) {
+	c.GetRoleInput = in
+
+	if c.GetRoleOutput != nil || c.GetRoleError != nil {
+		return c.GetRoleOutput, c.GetRoleError
+	}
+
+	name := utility.FromStringPtr(in.RoleName)
+	if name == "" {
+		return nil, awserr.New(iam.ErrCodeInvalidInputException, "missing role name", nil)
+	}
+	role, ok := GlobalIAMRoles[name]
+	if !ok {
+		return nil, awserr.New(iam.ErrCodeNoSuchEntityException, "role not found", nil)
+	}
+
+	return &iam.GetRoleOutput{Role: role.export()}, nil
+}
+
+// Close closes the mock client. The mock output can be customized. By default,
+// it is a no-op that returns no error.
+func (c *IAMClient) Close(ctx context.Context) error {
+	if c.CloseError != nil {
+		return c.CloseError
+	}
+
+	return nil
+}
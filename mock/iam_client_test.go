@@ -0,0 +1,56 @@
+package mock
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIAMClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.IAMClient)(nil), &IAMClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer ResetGlobalIAMRoles()
+
+	const policy = `{"Version": "2012-10-17", "Statement": []}`
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *IAMClient){
+		"GetRoleReturnsRole": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			out, err := c.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String("app")})
+			require.NoError(t, err)
+			require.NotZero(t, out.Role)
+			assert.Equal(t, "app", utility.FromStringPtr(out.Role.RoleName))
+			assert.Equal(t, "arn:aws:iam::123456789012:role/app", utility.FromStringPtr(out.Role.Arn))
+
+			doc, err := url.PathUnescape(utility.FromStringPtr(out.Role.AssumeRolePolicyDocument))
+			require.NoError(t, err)
+			assert.Equal(t, policy, doc)
+		},
+		"GetRoleFailsWithNonexistentRole": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			_, err := c.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String("nonexistent")})
+			assert.Error(t, err)
+		},
+		"GetRoleFailsWithoutRoleName": func(ctx context.Context, t *testing.T, c *IAMClient) {
+			_, err := c.GetRole(ctx, &iam.GetRoleInput{})
+			assert.Error(t, err)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalIAMRoles()
+			GlobalIAMRoles["app"] = IAMRole{Name: "app", AssumeRolePolicyDocument: policy}
+
+			tCase(tctx, t, &IAMClient{})
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/evergreen-ci/utility"
+)
+
+// KMSKey is a representation of a key stored in the fake KMS storage.
+type KMSKey struct {
+	// ID is the unique key ID.
+	ID string
+	// Aliases are the alias names (e.g. "alias/my-key") that refer to the
+	// key.
+	Aliases []string
+}
+
+// ARN returns the ARN of the key.
+func (k *KMSKey) ARN() string {
+	return fmt.Sprintf("arn:aws:kms:%s:%s:key/%s", KMSRegion, KMSAccountID, k.ID)
+}
+
+// matches returns whether the key identifier refers to this key. The
+// identifier can be the key ID, key ARN, alias name or alias ARN.
+func (k *KMSKey) matches(keyID string) bool {
+	if keyID == k.ID || keyID == k.ARN() {
+		return true
+	}
+	for _, alias := range k.Aliases {
+		if keyID == alias || keyID == fmt.Sprintf("arn:aws:kms:%s:%s:%s", KMSRegion, KMSAccountID, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *KMSKey) export() *kms.KeyMetadata {
+	return &kms.KeyMetadata{
+		KeyId:    utility.ToStringPtr(k.ID),
+		Arn:      utility.ToStringPtr(k.ARN()),
+		KeyState: utility.ToStringPtr(kms.KeyStateEnabled),
+		Enabled:  utility.TruePtr(),
+	}
+}
+
+const (
+	// KMSRegion is the region of the keys in the fake KMS storage.
+	KMSRegion = "us-east-1"
+	// KMSAccountID is the ID of the account that owns the keys in the fake KMS
+	// storage.
+	KMSAccountID = "123456789012"
+)
+
+// GlobalKMSKeys is a global fake KMS storage that maps each key ID to its key.
+// This can be used indirectly with the KMSClient to access keys, or used
+// directly.
+var GlobalKMSKeys map[string]KMSKey
+
+func init() {
+	ResetGlobalKMSKeys()
+}
+
+// ResetGlobalKMSKeys resets the global fake KMS storage to an initialized but
+// clean state.
+func ResetGlobalKMSKeys() {
+	globalKMSKeysMu.Lock()
+	defer globalKMSKeysMu.Unlock()
+
+	GlobalKMSKeys = map[string]KMSKey{}
+}
+
+// globalKMSKeysMu synchronizes the KMSClient's access to the fake
+// GlobalKMSKeys and to its own inputs.
+var globalKMSKeysMu sync.Mutex
+
+// KMSClient provides a mock implementation of a cocoa.KMSClient. This makes it
+// possible to introspect on inputs to the client and control the client's
+// output. It provides some default implementations where possible. By
+// default, it will issue the API calls to the fake GlobalKMSKeys. Its methods
+// are safe for concurrent use, but accessing its fields or the GlobalKMSKeys
+// directly while API calls are in progress is not.
+type KMSClient struct {
+	DescribeKeyInput  *kms.DescribeKeyInput
+	DescribeKeyOutput *kms.DescribeKeyOutput
+	DescribeKeyError  error
+
+	CloseError error
+}
+
+// DescribeKey saves the input and returns information about the matching key.
+// The mock output can be customized. By default, it will return the key in
+// the global fake KMS storage that matches the key ID, key ARN, alias name or
+// alias ARN.
+func (c *KMSClient) DescribeKey(ctx context.Context, in *kms.DescribeKeyInput) (*kms.DescribeKeyOutput, error) {
+	globalKMSKeysMu.Lock()
+	defer globalKMSKeysMu.Unlock()
+
+	c.DescribeKeyInput = in
+
+	if c.DescribeKeyOutput != nil || c.DescribeKeyError != nil {
+		return c.DescribeKeyOutput, c.DescribeKeyError
+	}
+
+	keyID := utility.FromStringPtr(in.KeyId)
+	if keyID == "" {
+		return nil, awserr.New(kms.ErrCodeInvalidArnException, "missing key ID", nil)
+	}
+
+	for _, key := range GlobalKMSKeys {
+		if key.matches(keyID) {
+			return &kms.DescribeKeyOutput{KeyMetadata: key.export()}, nil
+		}
+	}
+
+	return nil, awserr.New(kms.ErrCodeNotFoundException, "key not found", nil)
+}
+
+// Close closes the mock client. The mock output can be customized. By default,
+// it is a no-op that returns no error.
+func (c *KMSClient) Close(ctx context.Context) error {
+	if c.CloseError != nil {
+		return c.CloseError
+	}
+
+	return nil
+}
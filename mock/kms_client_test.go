@@ -0,0 +1,70 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKMSClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.KMSClient)(nil), &KMSClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer ResetGlobalKMSKeys()
+
+	key := KMSKey{ID: "1234abcd-12ab-34cd-56ef-1234567890ab", Aliases: []string{"alias/app"}}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *KMSClient){
+		"DescribeKeyReturnsKeyByID": func(ctx context.Context, t *testing.T, c *KMSClient) {
+			out, err := c.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String(key.ID)})
+			require.NoError(t, err)
+			require.NotZero(t, out.KeyMetadata)
+			assert.Equal(t, key.ID, utility.FromStringPtr(out.KeyMetadata.KeyId))
+			assert.Equal(t, key.ARN(), utility.FromStringPtr(out.KeyMetadata.Arn))
+		},
+		"DescribeKeyReturnsKeyByARN": func(ctx context.Context, t *testing.T, c *KMSClient) {
+			out, err := c.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String(key.ARN())})
+			require.NoError(t, err)
+			require.NotZero(t, out.KeyMetadata)
+			assert.Equal(t, key.ID, utility.FromStringPtr(out.KeyMetadata.KeyId))
+		},
+		"DescribeKeyReturnsKeyByAliasName": func(ctx context.Context, t *testing.T, c *KMSClient) {
+			out, err := c.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String("alias/app")})
+			require.NoError(t, err)
+			require.NotZero(t, out.KeyMetadata)
+			assert.Equal(t, key.ARN(), utility.FromStringPtr(out.KeyMetadata.Arn))
+		},
+		"DescribeKeyReturnsKeyByAliasARN": func(ctx context.Context, t *testing.T, c *KMSClient) {
+			out, err := c.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String("arn:aws:kms:us-east-1:123456789012:alias/app")})
+			require.NoError(t, err)
+			require.NotZero(t, out.KeyMetadata)
+			assert.Equal(t, key.ARN(), utility.FromStringPtr(out.KeyMetadata.Arn))
+		},
+		"DescribeKeyFailsWithNonexistentAlias": func(ctx context.Context, t *testing.T, c *KMSClient) {
+			_, err := c.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String("alias/nonexistent")})
+			assert.Error(t, err)
+		},
+		"DescribeKeyFailsWithoutKeyID": func(ctx context.Context, t *testing.T, c *KMSClient) {
+			_, err := c.DescribeKey(ctx, &kms.DescribeKeyInput{})
+			assert.Error(t, err)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalKMSKeys()
+			GlobalKMSKeys[key.ID] = key
+
+			tCase(tctx, t, &KMSClient{})
+		})
+	}
+}
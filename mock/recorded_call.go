@@ -0,0 +1,9 @@
+package mock
+
+// RecordedCall represents a single call made to a mock client.
+type RecordedCall struct {
+	// Operation is the name of the client method that was called.
+	Operation string
+	// Input is the input that was passed to the client method.
+	Input interface{}
+}
@@ -0,0 +1,96 @@
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteExpiredSecrets(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const (
+		tagKey   = "environment"
+		tagValue = "ephemeral"
+	)
+
+	createSecret := func(ctx context.Context, t *testing.T, c *SecretsManagerClient, name string, age time.Duration, tags map[string]string) {
+		in := &secretsmanager.CreateSecretInput{
+			Name:         aws.String(name),
+			SecretString: aws.String("value"),
+		}
+		for k, v := range tags {
+			in.Tags = append(in.Tags, &secretsmanager.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+		_, err := c.CreateSecret(ctx, in)
+		require.NoError(t, err)
+
+		s := GlobalSecretCache[name]
+		s.Created = time.Now().Add(-age)
+		GlobalSecretCache[name] = s
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *SecretsManagerClient){
+		"DeletesOnlyExpiredSecretsWithTag": func(ctx context.Context, t *testing.T, c *SecretsManagerClient) {
+			createSecret(ctx, t, c, "expired", 2*time.Hour, map[string]string{tagKey: tagValue})
+			createSecret(ctx, t, c, "recent", time.Minute, map[string]string{tagKey: tagValue})
+			createSecret(ctx, t, c, "untagged", 2*time.Hour, nil)
+			createSecret(ctx, t, c, "other_value", 2*time.Hour, map[string]string{tagKey: "permanent"})
+
+			deleted, err := secret.DeleteExpiredSecrets(ctx, c, tagKey, tagValue, time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"expired"}, deleted)
+
+			require.NotZero(t, c.DeleteSecretInput)
+			assert.True(t, aws.BoolValue(c.DeleteSecretInput.ForceDeleteWithoutRecovery))
+			assert.True(t, GlobalSecretCache["expired"].IsDeleted)
+			assert.False(t, GlobalSecretCache["recent"].IsDeleted)
+			assert.False(t, GlobalSecretCache["untagged"].IsDeleted)
+			assert.False(t, GlobalSecretCache["other_value"].IsDeleted)
+		},
+		"IgnoresSecretsWithTagKeyAndValueOnDifferentTags": func(ctx context.Context, t *testing.T, c *SecretsManagerClient) {
+			createSecret(ctx, t, c, "mismatched", 2*time.Hour, map[string]string{
+				tagKey:  "permanent",
+				"other": tagValue,
+			})
+
+			deleted, err := secret.DeleteExpiredSecrets(ctx, c, tagKey, tagValue, time.Hour)
+			require.NoError(t, err)
+			assert.Empty(t, deleted)
+			assert.False(t, GlobalSecretCache["mismatched"].IsDeleted)
+		},
+		"NoopsWithoutMatchingSecrets": func(ctx context.Context, t *testing.T, c *SecretsManagerClient) {
+			deleted, err := secret.DeleteExpiredSecrets(ctx, c, tagKey, tagValue, time.Hour)
+			require.NoError(t, err)
+			assert.Empty(t, deleted)
+			assert.Zero(t, c.DeleteSecretInput)
+		},
+		"FailsWithoutTagKey": func(ctx context.Context, t *testing.T, c *SecretsManagerClient) {
+			deleted, err := secret.DeleteExpiredSecrets(ctx, c, "", tagValue, time.Hour)
+			assert.Error(t, err)
+			assert.Empty(t, deleted)
+		},
+		"FailsWithNonpositiveMaxAge": func(ctx context.Context, t *testing.T, c *SecretsManagerClient) {
+			deleted, err := secret.DeleteExpiredSecrets(ctx, c, tagKey, tagValue, 0)
+			assert.Error(t, err)
+			assert.Empty(t, deleted)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalSecretCache()
+			defer ResetGlobalSecretCache()
+
+			tCase(tctx, t, &SecretsManagerClient{})
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/evergreen-ci/cocoa/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveKMSKeyARN(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	ResetGlobalKMSKeys()
+	defer ResetGlobalKMSKeys()
+
+	key := KMSKey{
+		ID:      "key",
+		Aliases: []string{"alias/key"},
+	}
+	GlobalKMSKeys[key.ID] = key
+
+	t.Run("ResolvesAlias", func(t *testing.T) {
+		c := &KMSClient{}
+		keyARN, err := secret.ResolveKMSKeyARN(ctx, c, key.Aliases[0])
+		require.NoError(t, err)
+		assert.Equal(t, key.ARN(), keyARN)
+		require.NotZero(t, c.DescribeKeyInput)
+		assert.Equal(t, key.Aliases[0], *c.DescribeKeyInput.KeyId)
+	})
+	t.Run("ReturnsKeyARNAsIs", func(t *testing.T) {
+		c := &KMSClient{}
+		keyARN, err := secret.ResolveKMSKeyARN(ctx, c, key.ARN())
+		require.NoError(t, err)
+		assert.Equal(t, key.ARN(), keyARN)
+		assert.Zero(t, c.DescribeKeyInput, "should not describe a key ARN")
+	})
+	t.Run("FailsWithNonexistentAlias", func(t *testing.T) {
+		_, err := secret.ResolveKMSKeyARN(ctx, &KMSClient{}, "alias/nonexistent")
+		assert.Error(t, err)
+	})
+	t.Run("FailsWithoutClient", func(t *testing.T) {
+		_, err := secret.ResolveKMSKeyARN(ctx, nil, key.Aliases[0])
+		assert.Error(t, err)
+	})
+}
+
+func TestNewKMSKeyResolver(t *testing.T) {
+	t.Run("Succeeds", func(t *testing.T) {
+		r, err := secret.NewKMSKeyResolver(&KMSClient{})
+		require.NoError(t, err)
+		assert.NotZero(t, r)
+	})
+	t.Run("FailsWithoutClient", func(t *testing.T) {
+		r, err := secret.NewKMSKeyResolver(nil)
+		assert.Error(t, err)
+		assert.Zero(t, r)
+	})
+}
+
+func TestKMSKeyResolver(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defer ResetGlobalKMSKeys()
+
+	key := KMSKey{
+		ID:      "key",
+		Aliases: []string{"alias/key"},
+	}
+	otherKey := KMSKey{
+		ID: "other-key",
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient){
+		"ReturnsKeyARNAsIs": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			keyARN, err := r.Resolve(ctx, key.ARN())
+			require.NoError(t, err)
+			assert.Equal(t, key.ARN(), keyARN)
+			assert.Zero(t, c.DescribeKeyInput, "should not describe a key ARN")
+		},
+		"ResolvesAliasName": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			keyARN, err := r.Resolve(ctx, key.Aliases[0])
+			require.NoError(t, err)
+			assert.Equal(t, key.ARN(), keyARN)
+			require.NotZero(t, c.DescribeKeyInput)
+			assert.Equal(t, key.Aliases[0], *c.DescribeKeyInput.KeyId)
+		},
+		"ResolvesAliasARN": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			keyARN, err := r.Resolve(ctx, "arn:aws:kms:"+KMSRegion+":"+KMSAccountID+":"+key.Aliases[0])
+			require.NoError(t, err)
+			assert.Equal(t, key.ARN(), keyARN)
+		},
+		"CachesResolvedAlias": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			keyARN, err := r.Resolve(ctx, key.Aliases[0])
+			require.NoError(t, err)
+			assert.Equal(t, key.ARN(), keyARN)
+			require.NotZero(t, c.DescribeKeyInput)
+
+			c.DescribeKeyInput = nil
+			ResetGlobalKMSKeys()
+
+			keyARN, err = r.Resolve(ctx, key.Aliases[0])
+			require.NoError(t, err)
+			assert.Equal(t, key.ARN(), keyARN)
+			assert.Zero(t, c.DescribeKeyInput, "should use cached key ARN")
+		},
+		"DoesNotShareCacheBetweenResolvers": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			_, err := r.Resolve(ctx, key.Aliases[0])
+			require.NoError(t, err)
+
+			otherClient := &KMSClient{}
+			otherResolver, err := secret.NewKMSKeyResolver(otherClient)
+			require.NoError(t, err)
+			keyARN, err := otherResolver.Resolve(ctx, key.Aliases[0])
+			require.NoError(t, err)
+			assert.Equal(t, key.ARN(), keyARN)
+			assert.NotZero(t, otherClient.DescribeKeyInput, "should describe the key with the new resolver's client")
+		},
+		"InvalidateResolvesAliasAgain": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			keyARN, err := r.Resolve(ctx, key.Aliases[0])
+			require.NoError(t, err)
+			assert.Equal(t, key.ARN(), keyARN)
+
+			movedKey := otherKey
+			movedKey.Aliases = key.Aliases
+			ResetGlobalKMSKeys()
+			GlobalKMSKeys[movedKey.ID] = movedKey
+
+			keyARN, err = r.Resolve(ctx, key.Aliases[0])
+			require.NoError(t, err)
+			assert.Equal(t, key.ARN(), keyARN, "should use cached key ARN before invalidation")
+
+			r.Invalidate(key.Aliases[0])
+
+			keyARN, err = r.Resolve(ctx, key.Aliases[0])
+			require.NoError(t, err)
+			assert.Equal(t, movedKey.ARN(), keyARN)
+		},
+		"InvalidateAllResolvesAliasesAgain": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			_, err := r.Resolve(ctx, key.Aliases[0])
+			require.NoError(t, err)
+
+			r.InvalidateAll()
+			ResetGlobalKMSKeys()
+
+			_, err = r.Resolve(ctx, key.Aliases[0])
+			assert.Error(t, err, "should describe the key again after invalidation")
+		},
+		"DoesNotCacheFailures": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			ResetGlobalKMSKeys()
+			_, err := r.Resolve(ctx, key.Aliases[0])
+			assert.Error(t, err)
+
+			GlobalKMSKeys[key.ID] = key
+			keyARN, err := r.Resolve(ctx, key.Aliases[0])
+			require.NoError(t, err)
+			assert.Equal(t, key.ARN(), keyARN)
+		},
+		"ResolvesConcurrently": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					keyARN, err := r.Resolve(ctx, key.Aliases[0])
+					assert.NoError(t, err)
+					assert.Equal(t, key.ARN(), keyARN)
+				}()
+			}
+			wg.Wait()
+		},
+		"FailsWithNonexistentAlias": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			_, err := r.Resolve(ctx, "alias/nonexistent")
+			assert.Error(t, err)
+		},
+		"FailsWithNonKMSARN": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			_, err := r.Resolve(ctx, "arn:aws:secretsmanager:us-east-1:123456789012:secret:name-abcdef")
+			assert.Error(t, err)
+			assert.Zero(t, c.DescribeKeyInput)
+		},
+		"FailsWithEmptyInput": func(ctx context.Context, t *testing.T, r *secret.KMSKeyResolver, c *KMSClient) {
+			_, err := r.Resolve(ctx, "")
+			assert.Error(t, err)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalKMSKeys()
+			GlobalKMSKeys[key.ID] = key
+
+			c := &KMSClient{}
+			r, err := secret.NewKMSKeyResolver(c)
+			require.NoError(t, err)
+
+			tCase(tctx, t, r, c)
+		})
+	}
+}
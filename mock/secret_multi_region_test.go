@@ -0,0 +1,159 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/secret"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiRegionSecretsManagerClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.SecretsManagerClient)(nil), &secret.MultiRegionSecretsManagerClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const secretName = "secret"
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, primary, secondary *SecretsManagerClient){
+		"ReturnsValueFromPrimaryRegion": func(ctx context.Context, t *testing.T, primary, secondary *SecretsManagerClient) {
+			c, err := secret.NewMultiRegionSecretsManagerClient([]secret.RegionalSecretsManagerClient{
+				{Region: "us-east-1", Client: primary},
+				{Region: "us-west-2", Client: secondary},
+			})
+			require.NoError(t, err)
+
+			out, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretName)})
+			require.NoError(t, err)
+			assert.Equal(t, "value", utility.FromStringPtr(out.SecretString))
+			assert.Len(t, primary.RecordedCalls(), 1)
+			assert.Empty(t, secondary.RecordedCalls())
+		},
+		"FallsBackToNextRegionOnFailure": func(ctx context.Context, t *testing.T, primary, secondary *SecretsManagerClient) {
+			primary.GetSecretValueError = awserr.New(secretsmanager.ErrCodeInternalServiceError, "region outage", nil)
+			c, err := secret.NewMultiRegionSecretsManagerClient([]secret.RegionalSecretsManagerClient{
+				{Region: "us-east-1", Client: primary},
+				{Region: "us-west-2", Client: secondary},
+			})
+			require.NoError(t, err)
+
+			out, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretName)})
+			require.NoError(t, err)
+			assert.Equal(t, "value", utility.FromStringPtr(out.SecretString))
+			assert.Len(t, primary.RecordedCalls(), 1)
+			assert.Len(t, secondary.RecordedCalls(), 1)
+		},
+		"RewritesSecretARNForEachRegion": func(ctx context.Context, t *testing.T, primary, secondary *SecretsManagerClient) {
+			primary.GetSecretValueError = awserr.New(secretsmanager.ErrCodeInternalServiceError, "region outage", nil)
+			secondary.GetSecretValueOutput = &secretsmanager.GetSecretValueOutput{SecretString: aws.String("replica value")}
+			c, err := secret.NewMultiRegionSecretsManagerClient([]secret.RegionalSecretsManagerClient{
+				{Region: "us-east-1", Client: primary},
+				{Region: "us-west-2", Client: secondary},
+			})
+			require.NoError(t, err)
+
+			const secretARN = "arn:aws:secretsmanager:us-east-1:123456789012:secret:secret-abcdef"
+			out, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretARN)})
+			require.NoError(t, err)
+			assert.Equal(t, "replica value", utility.FromStringPtr(out.SecretString))
+			require.NotZero(t, primary.GetSecretValueInput)
+			assert.Equal(t, secretARN, utility.FromStringPtr(primary.GetSecretValueInput.SecretId))
+			require.NotZero(t, secondary.GetSecretValueInput)
+			assert.Equal(t, "arn:aws:secretsmanager:us-west-2:123456789012:secret:secret-abcdef", utility.FromStringPtr(secondary.GetSecretValueInput.SecretId))
+		},
+		"ReturnsNotFoundErrorWithoutTryingOtherRegions": func(ctx context.Context, t *testing.T, primary, secondary *SecretsManagerClient) {
+			c, err := secret.NewMultiRegionSecretsManagerClient([]secret.RegionalSecretsManagerClient{
+				{Region: "us-east-1", Client: primary},
+				{Region: "us-west-2", Client: secondary},
+			})
+			require.NoError(t, err)
+
+			out, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String("nonexistent")})
+			assert.True(t, cocoa.IsSecretNotFoundError(err))
+			assert.True(t, errors.Is(err, cocoa.ErrSecretNotFound))
+			assert.Zero(t, out)
+			assert.Len(t, primary.RecordedCalls(), 1)
+			assert.Empty(t, secondary.RecordedCalls())
+		},
+		"FailsWhenAllRegionsFail": func(ctx context.Context, t *testing.T, primary, secondary *SecretsManagerClient) {
+			primary.GetSecretValueError = errors.New("primary outage")
+			secondary.GetSecretValueError = errors.New("secondary outage")
+			c, err := secret.NewMultiRegionSecretsManagerClient([]secret.RegionalSecretsManagerClient{
+				{Region: "us-east-1", Client: primary},
+				{Region: "us-west-2", Client: secondary},
+			})
+			require.NoError(t, err)
+
+			out, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretName)})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "us-east-1")
+			assert.Contains(t, err.Error(), "us-west-2")
+			assert.Zero(t, out)
+		},
+		"PassesOtherMethodsToPrimaryRegion": func(ctx context.Context, t *testing.T, primary, secondary *SecretsManagerClient) {
+			c, err := secret.NewMultiRegionSecretsManagerClient([]secret.RegionalSecretsManagerClient{
+				{Region: "us-east-1", Client: primary},
+				{Region: "us-west-2", Client: secondary},
+			})
+			require.NoError(t, err)
+
+			_, err = c.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: aws.String(secretName)})
+			require.NoError(t, err)
+			assert.Len(t, primary.RecordedCalls(), 1)
+			assert.Empty(t, secondary.RecordedCalls())
+		},
+		"CloseClosesAllRegions": func(ctx context.Context, t *testing.T, primary, secondary *SecretsManagerClient) {
+			secondary.CloseError = errors.New("fake error")
+			c, err := secret.NewMultiRegionSecretsManagerClient([]secret.RegionalSecretsManagerClient{
+				{Region: "us-east-1", Client: primary},
+				{Region: "us-west-2", Client: secondary},
+			})
+			require.NoError(t, err)
+
+			err = c.Close(ctx)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "us-west-2")
+		},
+		"FailsWithoutClients": func(ctx context.Context, t *testing.T, primary, secondary *SecretsManagerClient) {
+			_, err := secret.NewMultiRegionSecretsManagerClient(nil)
+			assert.Error(t, err)
+		},
+		"FailsWithDuplicateRegions": func(ctx context.Context, t *testing.T, primary, secondary *SecretsManagerClient) {
+			_, err := secret.NewMultiRegionSecretsManagerClient([]secret.RegionalSecretsManagerClient{
+				{Region: "us-east-1", Client: primary},
+				{Region: "us-east-1", Client: secondary},
+			})
+			assert.Error(t, err)
+		},
+		"FailsWithMissingClient": func(ctx context.Context, t *testing.T, primary, secondary *SecretsManagerClient) {
+			_, err := secret.NewMultiRegionSecretsManagerClient([]secret.RegionalSecretsManagerClient{
+				{Region: "us-east-1"},
+			})
+			assert.Error(t, err)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			ResetGlobalSecretCache()
+			defer ResetGlobalSecretCache()
+
+			_, err := (&SecretsManagerClient{}).CreateSecret(tctx, &secretsmanager.CreateSecretInput{
+				Name:         aws.String(secretName),
+				SecretString: aws.String("value"),
+			})
+			require.NoError(t, err)
+
+			tCase(tctx, t, &SecretsManagerClient{}, &SecretsManagerClient{})
+		})
+	}
+}
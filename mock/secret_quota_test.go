@@ -0,0 +1,110 @@
+package mock
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa/secret"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedListSecretsClient is a Secrets Manager client that returns each output
+// in the sequence on successive calls to ListSecrets.
+type pagedListSecretsClient struct {
+	SecretsManagerClient
+	pages  []*secretsmanager.ListSecretsOutput
+	tokens []*string
+}
+
+func (c *pagedListSecretsClient) ListSecrets(ctx context.Context, in *secretsmanager.ListSecretsInput) (*secretsmanager.ListSecretsOutput, error) {
+	c.tokens = append(c.tokens, in.NextToken)
+	if len(c.tokens) > len(c.pages) {
+		return nil, errors.New("no more pages")
+	}
+	return c.pages[len(c.tokens)-1], nil
+}
+
+func TestCheckSecretQuota(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("CountsExistingSecrets", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		ResetGlobalSecretCache()
+		defer ResetGlobalSecretCache()
+
+		c := &SecretsManagerClient{}
+		for i := 0; i < 3; i++ {
+			_, err := c.CreateSecret(tctx, &secretsmanager.CreateSecretInput{
+				Name:         aws.String("secret" + strconv.Itoa(i)),
+				SecretString: aws.String("value"),
+			})
+			require.NoError(t, err)
+		}
+
+		info, err := secret.CheckSecretQuota(tctx, c)
+		require.NoError(t, err)
+		require.NotZero(t, info)
+		assert.Equal(t, 3, info.Current)
+		assert.Equal(t, secret.DefaultSecretQuota, info.Limit)
+		assert.Equal(t, secret.DefaultSecretQuota-3, info.Available)
+	})
+	t.Run("CountsSecretsAcrossPages", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		c := &pagedListSecretsClient{pages: []*secretsmanager.ListSecretsOutput{
+			{
+				SecretList: []*secretsmanager.SecretListEntry{{Name: aws.String("secret0")}, {Name: aws.String("secret1")}},
+				NextToken:  aws.String("token"),
+			},
+			{
+				SecretList: []*secretsmanager.SecretListEntry{{Name: aws.String("secret2")}},
+			},
+		}}
+
+		info, err := secret.CheckSecretQuotaWithLimit(tctx, c, 5)
+		require.NoError(t, err)
+		require.NotZero(t, info)
+		assert.Equal(t, 3, info.Current)
+		assert.Equal(t, 5, info.Limit)
+		assert.Equal(t, 2, info.Available)
+		assert.Equal(t, []*string{nil, aws.String("token")}, c.tokens)
+	})
+	t.Run("ReportsNoneAvailableWhenOverLimit", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		c := &pagedListSecretsClient{pages: []*secretsmanager.ListSecretsOutput{{
+			SecretList: []*secretsmanager.SecretListEntry{{Name: aws.String("secret0")}, {Name: aws.String("secret1")}},
+		}}}
+
+		info, err := secret.CheckSecretQuotaWithLimit(tctx, c, 1)
+		require.NoError(t, err)
+		require.NotZero(t, info)
+		assert.Equal(t, 2, info.Current)
+		assert.Zero(t, info.Available)
+	})
+	t.Run("FailsWhenListingSecretsFails", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		c := &SecretsManagerClient{ListSecretsError: errors.New("fake error")}
+
+		info, err := secret.CheckSecretQuota(tctx, c)
+		assert.Error(t, err)
+		assert.Zero(t, info)
+	})
+	t.Run("FailsWithNonPositiveLimit", func(t *testing.T) {
+		info, err := secret.CheckSecretQuotaWithLimit(ctx, &SecretsManagerClient{}, 0)
+		assert.Error(t, err)
+		assert.Zero(t, info)
+	})
+}
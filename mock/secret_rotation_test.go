@@ -0,0 +1,205 @@
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa/secret"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequencedDescribeSecretClient is a Secrets Manager client that returns each
+// output in the sequence on successive calls to DescribeSecret.
+type sequencedDescribeSecretClient struct {
+	SecretsManagerClient
+	outputs []*secretsmanager.DescribeSecretOutput
+	calls   int
+}
+
+func (c *sequencedDescribeSecretClient) DescribeSecret(ctx context.Context, in *secretsmanager.DescribeSecretInput) (*secretsmanager.DescribeSecretOutput, error) {
+	if c.calls >= len(c.outputs) {
+		return nil, errors.New("no more outputs")
+	}
+	out := c.outputs[c.calls]
+	c.calls++
+	return out, nil
+}
+
+func TestMonitorRotationStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	collectEvents := func(t *testing.T, events <-chan secret.RotationStatusEvent) []secret.RotationStatusEvent {
+		var collected []secret.RotationStatusEvent
+		for e := range events {
+			collected = append(collected, e)
+		}
+		return collected
+	}
+
+	t.Run("FailsWithoutClient", func(t *testing.T) {
+		events, err := secret.MonitorRotationStatus(ctx, nil, "secret", time.Millisecond)
+		assert.Error(t, err)
+		assert.Zero(t, events)
+	})
+	t.Run("FailsWithNonexistentSecret", func(t *testing.T) {
+		ResetGlobalSecretCache()
+		defer ResetGlobalSecretCache()
+
+		events, err := secret.MonitorRotationStatus(ctx, &SecretsManagerClient{}, "nonexistent", time.Millisecond)
+		assert.Error(t, err)
+		assert.Zero(t, events)
+	})
+	t.Run("EmitsEventForEachRotationStatusChange", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		rotated := time.Now().Round(time.Second)
+		c := &sequencedDescribeSecretClient{outputs: []*secretsmanager.DescribeSecretOutput{
+			{RotationEnabled: aws.Bool(false)},
+			{RotationEnabled: aws.Bool(false)},
+			{RotationEnabled: aws.Bool(true)},
+			{
+				RotationEnabled: aws.Bool(true),
+				VersionIdsToStages: map[string][]*string{
+					"v0": {aws.String("AWSCURRENT")},
+					"v1": {aws.String("AWSPENDING")},
+				},
+			},
+			{
+				RotationEnabled: aws.Bool(true),
+				LastRotatedDate: aws.Time(rotated),
+				VersionIdsToStages: map[string][]*string{
+					"v1": {aws.String("AWSCURRENT")},
+				},
+			},
+		}}
+
+		events, err := secret.MonitorRotationStatus(tctx, c, "secret", time.Millisecond)
+		require.NoError(t, err)
+
+		collected := collectEvents(t, events)
+		require.Len(t, collected, 5)
+
+		assert.Equal(t, secret.RotationStatusInitial, collected[0].Type)
+		assert.False(t, collected[0].RotationEnabled)
+
+		assert.Equal(t, secret.RotationStatusEnabledChanged, collected[1].Type)
+		assert.True(t, collected[1].RotationEnabled)
+
+		assert.Equal(t, secret.RotationStatusPending, collected[2].Type)
+		assert.Equal(t, "v1", collected[2].PendingVersionID)
+
+		assert.Equal(t, secret.RotationStatusRotated, collected[3].Type)
+		assert.True(t, rotated.Equal(collected[3].LastRotated))
+		assert.Zero(t, collected[3].PendingVersionID)
+
+		for _, e := range collected[:4] {
+			assert.NoError(t, e.Err)
+			assert.NotZero(t, e.Timestamp)
+		}
+
+		assert.Error(t, collected[4].Err, "should emit an error once the status cannot be checked")
+	})
+	t.Run("StopsWhenContextIsDone", func(t *testing.T) {
+		tctx, tcancel := context.WithCancel(ctx)
+
+		ResetGlobalSecretCache()
+		defer ResetGlobalSecretCache()
+
+		c := &SecretsManagerClient{}
+		out, err := c.CreateSecret(tctx, &secretsmanager.CreateSecretInput{
+			Name:         aws.String("secret"),
+			SecretString: aws.String("value"),
+		})
+		require.NoError(t, err)
+
+		events, err := secret.MonitorRotationStatus(tctx, c, *out.ARN, time.Hour)
+		require.NoError(t, err)
+
+		e := <-events
+		assert.Equal(t, secret.RotationStatusInitial, e.Type)
+
+		tcancel()
+		for range events {
+		}
+	})
+}
+
+func TestRotateSecretDryRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	defer ResetGlobalSecretCache()
+
+	createSecret := func(t *testing.T, c *SecretsManagerClient) {
+		_, err := c.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
+			Name:         aws.String("name"),
+			SecretString: aws.String("value"),
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("FailsWithoutClient", func(t *testing.T) {
+		assert.Error(t, secret.RotateSecretDryRun(ctx, nil, "name"))
+	})
+	t.Run("FailsWithoutSecretID", func(t *testing.T) {
+		assert.Error(t, secret.RotateSecretDryRun(ctx, &SecretsManagerClient{}, ""))
+	})
+	t.Run("SucceedsAndCancelsRotation", func(t *testing.T) {
+		ResetGlobalSecretCache()
+		c := &SecretsManagerClient{}
+		createSecret(t, c)
+		versionID := GlobalSecretCache["name"].VersionID
+
+		require.NoError(t, secret.RotateSecretDryRun(ctx, c, "name"))
+
+		require.NotZero(t, c.RotateSecretInput)
+		assert.False(t, aws.BoolValue(c.RotateSecretInput.RotateImmediately))
+		require.NotZero(t, c.CancelRotateSecretInput)
+		assert.Equal(t, "name", aws.StringValue(c.CancelRotateSecretInput.SecretId))
+
+		s := GlobalSecretCache["name"]
+		assert.False(t, s.RotationEnabled)
+		assert.Equal(t, versionID, s.VersionID, "dry run should not change the current version")
+	})
+	t.Run("FailsAndCancelsRotationWhenVersionStaysPending", func(t *testing.T) {
+		c := &SecretsManagerClient{
+			RotateSecretOutput: &secretsmanager.RotateSecretOutput{VersionId: aws.String("v1")},
+			DescribeSecretOutput: &secretsmanager.DescribeSecretOutput{
+				VersionIdsToStages: map[string][]*string{
+					"v0": {aws.String("AWSCURRENT")},
+					"v1": {aws.String("AWSPENDING")},
+				},
+			},
+			CancelRotateSecretOutput: &secretsmanager.CancelRotateSecretOutput{},
+		}
+
+		tctx, tcancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer tcancel()
+
+		assert.Error(t, secret.RotateSecretDryRun(tctx, c, "name"))
+		assert.NotZero(t, c.CancelRotateSecretInput)
+	})
+	t.Run("FailsWhenCancellingRotationFails", func(t *testing.T) {
+		ResetGlobalSecretCache()
+		c := &SecretsManagerClient{CancelRotateSecretError: errors.New("fake error")}
+		createSecret(t, c)
+
+		err := secret.RotateSecretDryRun(ctx, c, "name")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fake error")
+	})
+	t.Run("FailsWithoutCancellingWhenRotationCannotStart", func(t *testing.T) {
+		ResetGlobalSecretCache()
+		c := &SecretsManagerClient{}
+
+		assert.Error(t, secret.RotateSecretDryRun(ctx, c, "nonexistent"))
+		assert.Zero(t, c.CancelRotateSecretInput)
+	})
+}
@@ -0,0 +1,254 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/secret"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingGetSecretValueClient is a Secrets Manager client whose
+// GetSecretValue calls block until they are released and counts how many calls
+// were made.
+type blockingGetSecretValueClient struct {
+	SecretsManagerClient
+	release chan struct{}
+	calls   int64
+	err     error
+}
+
+func (c *blockingGetSecretValueClient) GetSecretValue(ctx context.Context, in *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	atomic.AddInt64(&c.calls, 1)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.release:
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &secretsmanager.GetSecretValueOutput{
+		Name:         in.SecretId,
+		SecretString: aws.String("value-" + utility.FromStringPtr(in.SecretId)),
+	}, nil
+}
+
+func TestSingleflightSecretsManagerClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.SecretsManagerClient)(nil), &secret.SingleflightSecretsManagerClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// getConcurrently calls GetSecretValue concurrently n times for each of the
+	// secret IDs. The wrapped client's calls are only released once all the
+	// calls have started.
+	getConcurrently := func(ctx context.Context, t *testing.T, wrapped *blockingGetSecretValueClient, c *secret.SingleflightSecretsManagerClient, n int, ids ...string) ([]*secretsmanager.GetSecretValueOutput, []error) {
+		outs := make([]*secretsmanager.GetSecretValueOutput, n*len(ids))
+		errs := make([]error, n*len(ids))
+		var started, done sync.WaitGroup
+		for i := range outs {
+			started.Add(1)
+			done.Add(1)
+			go func(i int) {
+				defer done.Done()
+				started.Done()
+				outs[i], errs[i] = c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+					SecretId: aws.String(ids[i%len(ids)]),
+				})
+			}(i)
+		}
+
+		// There's no way to know exactly when every call is waiting on an
+		// in-flight request, so give the calls some time after they've all
+		// started before releasing the in-flight requests.
+		started.Wait()
+		time.Sleep(100 * time.Millisecond)
+		close(wrapped.release)
+		done.Wait()
+
+		return outs, errs
+	}
+
+	t.Run("NewFailsWithoutClient", func(t *testing.T) {
+		c, err := secret.NewSingleflightSecretsManagerClient(nil)
+		assert.Error(t, err)
+		assert.Zero(t, c)
+	})
+	t.Run("ConcurrentCallsForSameSecretMakeOneRequest", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		wrapped := &blockingGetSecretValueClient{release: make(chan struct{})}
+		c, err := secret.NewSingleflightSecretsManagerClient(wrapped)
+		require.NoError(t, err)
+
+		outs, errs := getConcurrently(tctx, t, wrapped, c, 10, "id")
+		for i := range outs {
+			require.NoError(t, errs[i])
+			require.NotZero(t, outs[i])
+			assert.Equal(t, "value-id", utility.FromStringPtr(outs[i].SecretString))
+		}
+		assert.EqualValues(t, 1, atomic.LoadInt64(&wrapped.calls))
+	})
+	t.Run("ConcurrentCallsForDifferentSecretsMakeSeparateRequests", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		wrapped := &blockingGetSecretValueClient{release: make(chan struct{})}
+		c, err := secret.NewSingleflightSecretsManagerClient(wrapped)
+		require.NoError(t, err)
+
+		outs, errs := getConcurrently(tctx, t, wrapped, c, 5, "id0", "id1")
+		for i := range outs {
+			require.NoError(t, errs[i])
+			require.NotZero(t, outs[i])
+			assert.Equal(t, "value-"+utility.FromStringPtr(outs[i].Name), utility.FromStringPtr(outs[i].SecretString))
+		}
+		assert.EqualValues(t, 2, atomic.LoadInt64(&wrapped.calls))
+	})
+	t.Run("ConcurrentCallsShareError", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		wrapped := &blockingGetSecretValueClient{
+			release: make(chan struct{}),
+			err:     errors.New("fake error"),
+		}
+		c, err := secret.NewSingleflightSecretsManagerClient(wrapped)
+		require.NoError(t, err)
+
+		outs, errs := getConcurrently(tctx, t, wrapped, c, 10, "id")
+		for i := range outs {
+			assert.Error(t, errs[i])
+			assert.Zero(t, outs[i])
+		}
+		assert.EqualValues(t, 1, atomic.LoadInt64(&wrapped.calls))
+	})
+	t.Run("CancelledCallerDoesNotCancelSharedRequest", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		wrapped := &blockingGetSecretValueClient{release: make(chan struct{})}
+		c, err := secret.NewSingleflightSecretsManagerClient(wrapped)
+		require.NoError(t, err)
+
+		firstCtx, firstCancel := context.WithCancel(tctx)
+		firstErr := make(chan error, 1)
+		go func() {
+			_, err := c.GetSecretValue(firstCtx, &secretsmanager.GetSecretValueInput{SecretId: aws.String("id")})
+			firstErr <- err
+		}()
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt64(&wrapped.calls) == 1
+		}, time.Second, time.Millisecond, "shared request should start")
+
+		type result struct {
+			out *secretsmanager.GetSecretValueOutput
+			err error
+		}
+		secondRes := make(chan result, 1)
+		go func() {
+			out, err := c.GetSecretValue(tctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String("id")})
+			secondRes <- result{out: out, err: err}
+		}()
+		// There's no way to know exactly when the second call is waiting on
+		// the in-flight request, so give it some time to join.
+		time.Sleep(100 * time.Millisecond)
+
+		firstCancel()
+		assert.True(t, errors.Is(<-firstErr, context.Canceled))
+
+		close(wrapped.release)
+		res := <-secondRes
+		require.NoError(t, res.err)
+		require.NotZero(t, res.out)
+		assert.Equal(t, "value-id", utility.FromStringPtr(res.out.SecretString))
+		assert.EqualValues(t, 1, atomic.LoadInt64(&wrapped.calls))
+	})
+	t.Run("SharedRequestFailsAfterRequestTimeout", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		wrapped := &blockingGetSecretValueClient{release: make(chan struct{})}
+		c, err := secret.NewSingleflightSecretsManagerClient(wrapped)
+		require.NoError(t, err)
+		c.WithRequestTimeout(10 * time.Millisecond)
+
+		out, err := c.GetSecretValue(tctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String("id")})
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Zero(t, out)
+		assert.NoError(t, tctx.Err())
+	})
+	t.Run("SequentialCallsMakeSeparateRequests", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		wrapped := &blockingGetSecretValueClient{release: make(chan struct{})}
+		close(wrapped.release)
+		c, err := secret.NewSingleflightSecretsManagerClient(wrapped)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			out, err := c.GetSecretValue(tctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String("id")})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+		}
+		assert.EqualValues(t, 3, atomic.LoadInt64(&wrapped.calls))
+	})
+	t.Run("ValidatesSecretValueWithValidator", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		wrapped := &blockingGetSecretValueClient{release: make(chan struct{})}
+		close(wrapped.release)
+		c, err := secret.NewSingleflightSecretsManagerClient(wrapped)
+		require.NoError(t, err)
+		c.WithValidator(func(value string) error {
+			if value != "value-valid" {
+				return errors.New("invalid value")
+			}
+			return nil
+		})
+
+		out, err := c.GetSecretValue(tctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String("valid")})
+		require.NoError(t, err)
+		assert.Equal(t, "value-valid", utility.FromStringPtr(out.SecretString))
+
+		out, err = c.GetSecretValue(tctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String("invalid")})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid value")
+		assert.Zero(t, out)
+	})
+	t.Run("PassesThroughOtherMethods", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+		defer tcancel()
+
+		ResetGlobalSecretCache()
+		defer ResetGlobalSecretCache()
+
+		c, err := secret.NewSingleflightSecretsManagerClient(&SecretsManagerClient{})
+		require.NoError(t, err)
+
+		createOut, err := c.CreateSecret(tctx, &secretsmanager.CreateSecretInput{
+			Name:         aws.String("name"),
+			SecretString: aws.String("value"),
+		})
+		require.NoError(t, err)
+		require.NotZero(t, createOut)
+
+		getOut, err := c.GetSecretValue(tctx, &secretsmanager.GetSecretValueInput{SecretId: createOut.ARN})
+		require.NoError(t, err)
+		require.NotZero(t, getOut)
+		assert.Equal(t, "value", utility.FromStringPtr(getOut.SecretString))
+	})
+}
@@ -0,0 +1,734 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+)
+
+const (
+	// SecretsManagerAccountID is the default AWS account ID that the fake
+	// Secrets Manager uses in secret ARNs.
+	SecretsManagerAccountID = "123456789012"
+	// SecretsManagerRegion is the default region that the fake Secrets Manager
+	// uses in secret ARNs.
+	SecretsManagerRegion = "us-east-1"
+
+	// versionStageCurrent is the staging label for the current version of a
+	// secret.
+	versionStageCurrent = "AWSCURRENT"
+	// versionStagePrevious is the staging label for the version of a secret
+	// that was current before the latest update.
+	versionStagePrevious = "AWSPREVIOUS"
+
+	defaultRecoveryWindowDays = 30
+	minRecoveryWindowDays     = 7
+	maxRecoveryWindowDays     = 30
+)
+
+// SecretsManager is an in-memory fake of Secrets Manager that simulates the
+// lifecycle of secrets more faithfully than the SecretsManagerClient:
+//   - Secrets have well-formed ARNs containing the account ID and region.
+//   - Each update creates a new version of the secret. The new version is
+//     labeled AWSCURRENT and the version that was current before it is labeled
+//     AWSPREVIOUS.
+//   - Rotating a secret simulates a rotation function that always succeeds.
+//     Rotating immediately creates a new version of the secret with the same
+//     value, while otherwise the rotation is only tested and the secret's
+//     versions do not change.
+//   - Deleting a secret schedules it for deletion after a recovery window.
+//     Until the window ends, the secret cannot be accessed, but it can be
+//     restored and its name cannot be reused. Once the window ends, the secret
+//     is permanently deleted.
+//
+// Unlike the SecretsManagerClient, each SecretsManager keeps its own state
+// rather than using the global secret cache. It is safe for concurrent use.
+type SecretsManager struct {
+	// AccountID is the AWS account ID used in secret ARNs. If this is not set,
+	// it defaults to SecretsManagerAccountID.
+	AccountID string
+	// Region is the region used in secret ARNs. If this is not set, it
+	// defaults to SecretsManagerRegion.
+	Region string
+	// Clock is the clock used to timestamp secrets and determine when their
+	// recovery window ends. If this is not set, it uses the real time.
+	Clock *VirtualClock
+
+	mu sync.Mutex
+	// secrets maps each secret ARN to the secret.
+	secrets map[string]*fakeSecret
+}
+
+// NewSecretsManager returns a new fake Secrets Manager without any secrets.
+func NewSecretsManager() *SecretsManager {
+	return &SecretsManager{}
+}
+
+// fakeSecret is a secret stored in the fake Secrets Manager.
+type fakeSecret struct {
+	arn          string
+	name         string
+	description  *string
+	kmsKeyID     *string
+	tags         map[string]string
+	versions     map[string]*fakeSecretVersion
+	created      time.Time
+	lastChanged  time.Time
+	lastAccessed time.Time
+	// deletionDate is when the secret will be permanently deleted. If it is
+	// zero, the secret is not scheduled for deletion.
+	deletionDate time.Time
+	// rotationLambdaARN is the ARN of the secret's rotation function. If it is
+	// nil, the secret cannot be rotated.
+	rotationLambdaARN *string
+	rotationEnabled   bool
+	lastRotated       time.Time
+	// forceDeleted indicates that the secret was deleted without recovery.
+	// Secrets Manager deletes these secrets asynchronously, so they can still
+	// be described for a short time, but their name can be reused immediately.
+	forceDeleted bool
+}
+
+// fakeSecretVersion is a single version of a secret's value.
+type fakeSecretVersion struct {
+	id           string
+	secretString *string
+	secretBinary []byte
+	stages       []string
+	created      time.Time
+}
+
+func (v *fakeSecretVersion) hasStage(stage string) bool {
+	return utility.StringSliceContains(v.stages, stage)
+}
+
+func (v *fakeSecretVersion) removeStage(stage string) {
+	var stages []string
+	for _, s := range v.stages {
+		if s != stage {
+			stages = append(stages, s)
+		}
+	}
+	v.stages = stages
+}
+
+func (v *fakeSecretVersion) hasSameValue(secretString *string, secretBinary []byte) bool {
+	return utility.FromStringPtr(v.secretString) == utility.FromStringPtr(secretString) && string(v.secretBinary) == string(secretBinary)
+}
+
+func (s *fakeSecret) isScheduledForDeletion() bool {
+	return !s.deletionDate.IsZero()
+}
+
+// versionWithStage returns the secret version with the given staging label.
+func (s *fakeSecret) versionWithStage(stage string) *fakeSecretVersion {
+	for _, v := range s.versions {
+		if v.hasStage(stage) {
+			return v
+		}
+	}
+	return nil
+}
+
+func (s *fakeSecret) versionIDsToStages() map[string][]*string {
+	versions := map[string][]*string{}
+	for id, v := range s.versions {
+		if len(v.stages) == 0 {
+			continue
+		}
+		versions[id] = utility.ToStringPtrSlice(v.stages)
+	}
+	return versions
+}
+
+func (s *fakeSecret) exportListEntry() *secretsmanager.SecretListEntry {
+	entry := &secretsmanager.SecretListEntry{
+		ARN:                    utility.ToStringPtr(s.arn),
+		Name:                   utility.ToStringPtr(s.name),
+		Description:            s.description,
+		KmsKeyId:               s.kmsKeyID,
+		CreatedDate:            utility.ToTimePtr(s.created),
+		LastChangedDate:        utility.ToTimePtr(s.lastChanged),
+		LastAccessedDate:       utility.ToTimePtr(s.lastAccessed),
+		SecretVersionsToStages: s.versionIDsToStages(),
+		Tags:                   exportSecretsManagerTags(s.tags),
+	}
+	if s.isScheduledForDeletion() {
+		entry.DeletedDate = utility.ToTimePtr(s.deletionDate)
+	}
+	return entry
+}
+
+func (m *SecretsManager) now() time.Time {
+	if m.Clock == nil {
+		return time.Now()
+	}
+	return m.Clock.Now()
+}
+
+func (m *SecretsManager) newARN(name string) string {
+	accountID := m.AccountID
+	if accountID == "" {
+		accountID = SecretsManagerAccountID
+	}
+	region := m.Region
+	if region == "" {
+		region = SecretsManagerRegion
+	}
+	return arn.ARN{
+		Partition: "aws",
+		Service:   "secretsmanager",
+		Region:    region,
+		AccountID: accountID,
+		Resource:  fmt.Sprintf("secret:%s-%s", name, utility.RandomString()[:6]),
+	}.String()
+}
+
+// purgeDeletedSecrets permanently deletes all secrets whose recovery window has
+// ended.
+func (m *SecretsManager) purgeDeletedSecrets() {
+	if m.secrets == nil {
+		m.secrets = map[string]*fakeSecret{}
+	}
+	now := m.now()
+	for id, s := range m.secrets {
+		if s.isScheduledForDeletion() && !now.Before(s.deletionDate) {
+			delete(m.secrets, id)
+		}
+	}
+}
+
+// findSecret finds the secret by its ARN or name. Secrets that were deleted
+// without recovery are only found by their ARN.
+func (m *SecretsManager) findSecret(id string) *fakeSecret {
+	if s, ok := m.secrets[id]; ok {
+		return s
+	}
+	for _, s := range m.secrets {
+		if s.name == id && !s.forceDeleted {
+			return s
+		}
+	}
+	return nil
+}
+
+// findAccessibleSecret finds the secret by its ARN or name and checks that it
+// is not deleted.
+func (m *SecretsManager) findAccessibleSecret(id *string) (*fakeSecret, error) {
+	if id == nil {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, "missing secret ID", nil)
+	}
+	s := m.findSecret(*id)
+	if s == nil || s.forceDeleted {
+		return nil, cocoa.NewSecretNotFoundError(*id, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil))
+	}
+	if s.isScheduledForDeletion() {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidRequestException, "secret is scheduled for deletion", nil)
+	}
+	return s, nil
+}
+
+func validateSecretValue(secretString *string, secretBinary []byte) error {
+	if secretBinary != nil && secretString != nil {
+		return awserr.New(secretsmanager.ErrCodeInvalidParameterException, "cannot specify both secret binary and secret string", nil)
+	}
+	if secretBinary == nil && secretString == nil {
+		return awserr.New(secretsmanager.ErrCodeInvalidParameterException, "must specify either secret binary or secret string", nil)
+	}
+	return nil
+}
+
+// CreateSecret creates a new secret with an initial version labeled
+// AWSCURRENT. It fails if a secret with the same name already exists or is
+// scheduled for deletion.
+func (m *SecretsManager) CreateSecret(ctx context.Context, in *secretsmanager.CreateSecretInput) (*secretsmanager.CreateSecretOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeDeletedSecrets()
+
+	if utility.FromStringPtr(in.Name) == "" {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, "missing secret name", nil)
+	}
+	if err := validateSecretValue(in.SecretString, in.SecretBinary); err != nil {
+		return nil, err
+	}
+
+	name := utility.FromStringPtr(in.Name)
+	if existing := m.findSecret(name); existing != nil {
+		if existing.isScheduledForDeletion() {
+			return nil, awserr.New(secretsmanager.ErrCodeInvalidRequestException, "a secret with this name is already scheduled for deletion", nil)
+		}
+		return nil, awserr.New(secretsmanager.ErrCodeResourceExistsException, "secret already exists", nil)
+	}
+
+	ts := m.now()
+	versionID := utility.FromStringPtr(in.ClientRequestToken)
+	if versionID == "" {
+		versionID = utility.RandomString()
+	}
+	s := &fakeSecret{
+		arn:          m.newARN(name),
+		name:         name,
+		description:  in.Description,
+		kmsKeyID:     in.KmsKeyId,
+		tags:         newSecretsManagerTags(in.Tags),
+		created:      ts,
+		lastChanged:  ts,
+		lastAccessed: ts,
+		versions: map[string]*fakeSecretVersion{
+			versionID: {
+				id:           versionID,
+				secretString: in.SecretString,
+				secretBinary: in.SecretBinary,
+				stages:       []string{versionStageCurrent},
+				created:      ts,
+			},
+		},
+	}
+	m.secrets[s.arn] = s
+
+	return &secretsmanager.CreateSecretOutput{
+		ARN:       utility.ToStringPtr(s.arn),
+		Name:      utility.ToStringPtr(s.name),
+		VersionId: utility.ToStringPtr(versionID),
+	}, nil
+}
+
+// GetSecretValue gets the value of a secret's version. If neither the version
+// ID nor the version stage is given, it gets the AWSCURRENT version.
+func (m *SecretsManager) GetSecretValue(ctx context.Context, in *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeDeletedSecrets()
+
+	s, err := m.findAccessibleSecret(in.SecretId)
+	if err != nil {
+		return nil, err
+	}
+
+	var v *fakeSecretVersion
+	if in.VersionId != nil {
+		v = s.versions[*in.VersionId]
+		if v != nil && in.VersionStage != nil && !v.hasStage(*in.VersionStage) {
+			return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, "version ID and version stage do not refer to the same version", nil)
+		}
+	} else {
+		stage := utility.FromStringPtr(in.VersionStage)
+		if stage == "" {
+			stage = versionStageCurrent
+		}
+		v = s.versionWithStage(stage)
+	}
+	if v == nil {
+		return nil, cocoa.NewSecretNotFoundError(utility.FromStringPtr(in.SecretId), awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret version not found", nil))
+	}
+
+	s.lastAccessed = m.now()
+
+	return &secretsmanager.GetSecretValueOutput{
+		ARN:           utility.ToStringPtr(s.arn),
+		Name:          utility.ToStringPtr(s.name),
+		VersionId:     utility.ToStringPtr(v.id),
+		VersionStages: utility.ToStringPtrSlice(v.stages),
+		SecretString:  v.secretString,
+		SecretBinary:  v.secretBinary,
+		CreatedDate:   utility.ToTimePtr(v.created),
+	}, nil
+}
+
+// DescribeSecret gets the metadata for a secret, including the staging labels
+// of its versions. Secrets that are scheduled for deletion can still be
+// described.
+func (m *SecretsManager) DescribeSecret(ctx context.Context, in *secretsmanager.DescribeSecretInput) (*secretsmanager.DescribeSecretOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeDeletedSecrets()
+
+	if in.SecretId == nil {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, "missing secret ID", nil)
+	}
+	s := m.findSecret(*in.SecretId)
+	if s == nil {
+		return nil, cocoa.NewSecretNotFoundError(*in.SecretId, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil))
+	}
+
+	entry := s.exportListEntry()
+	return &secretsmanager.DescribeSecretOutput{
+		ARN:                entry.ARN,
+		Name:               entry.Name,
+		Description:        entry.Description,
+		KmsKeyId:           entry.KmsKeyId,
+		CreatedDate:        entry.CreatedDate,
+		LastChangedDate:    entry.LastChangedDate,
+		LastAccessedDate:   entry.LastAccessedDate,
+		DeletedDate:        entry.DeletedDate,
+		VersionIdsToStages: entry.SecretVersionsToStages,
+		Tags:               entry.Tags,
+		RotationEnabled:    utility.ToBoolPtr(s.rotationEnabled),
+		RotationLambdaARN:  s.rotationLambdaARN,
+		LastRotatedDate:    utility.ToTimePtr(s.lastRotated),
+	}, nil
+}
+
+// ListSecrets lists the secrets that match all of the filters, sorted by name.
+// It supports the "all", "name", "tag-key", "tag-value", and "description"
+// filters, where each filter value matches by prefix and can be negated with a
+// leading "!". Secrets that are scheduled for deletion are not included.
+func (m *SecretsManager) ListSecrets(ctx context.Context, in *secretsmanager.ListSecretsInput) (*secretsmanager.ListSecretsOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeDeletedSecrets()
+
+	var matching []*fakeSecret
+	for _, s := range m.secrets {
+		if s.forceDeleted || s.isScheduledForDeletion() {
+			continue
+		}
+
+		matchesAll := true
+		for _, f := range in.Filters {
+			if f == nil {
+				continue
+			}
+			ok, err := s.matchesFilter(utility.FromStringPtr(f.Key), utility.FromStringPtrSlice(f.Values))
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				matchesAll = false
+				break
+			}
+		}
+		if matchesAll {
+			matching = append(matching, s)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].name < matching[j].name
+	})
+
+	start := 0
+	if in.NextToken != nil {
+		var err error
+		start, err = strconv.Atoi(*in.NextToken)
+		if err != nil || start < 0 || start > len(matching) {
+			return nil, awserr.New(secretsmanager.ErrCodeInvalidNextTokenException, "invalid next token", err)
+		}
+	}
+	end := len(matching)
+	if in.MaxResults != nil && start+int(*in.MaxResults) < end {
+		end = start + int(*in.MaxResults)
+	}
+
+	out := &secretsmanager.ListSecretsOutput{}
+	for _, s := range matching[start:end] {
+		out.SecretList = append(out.SecretList, s.exportListEntry())
+	}
+	if end < len(matching) {
+		out.NextToken = utility.ToStringPtr(strconv.Itoa(end))
+	}
+
+	return out, nil
+}
+
+// matchesFilter returns whether the secret matches any of the filter values.
+func (s *fakeSecret) matchesFilter(key string, vals []string) (bool, error) {
+	var fields []string
+	switch key {
+	case "all":
+		fields = append(fields, s.name, utility.FromStringPtr(s.description))
+		for k, v := range s.tags {
+			fields = append(fields, k, v)
+		}
+	case "name":
+		fields = []string{s.name}
+	case "description":
+		fields = []string{utility.FromStringPtr(s.description)}
+	case "tag-key":
+		for k := range s.tags {
+			fields = append(fields, k)
+		}
+	case "tag-value":
+		for _, v := range s.tags {
+			fields = append(fields, v)
+		}
+	default:
+		return false, awserr.New(secretsmanager.ErrCodeInvalidParameterException, fmt.Sprintf("unsupported filter key '%s'", key), nil)
+	}
+
+	for _, val := range vals {
+		negated := strings.HasPrefix(val, "!")
+		prefix := strings.TrimPrefix(val, "!")
+		var matched bool
+		for _, field := range fields {
+			if strings.HasPrefix(field, prefix) {
+				matched = true
+				break
+			}
+		}
+		if matched != negated {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// UpdateSecretValue updates a secret. If a new value is given, it creates a new
+// version labeled AWSCURRENT, moves the AWSPREVIOUS label to the version that
+// was current, and removes the AWSPREVIOUS label from the version that had it.
+// If the client request token matches an existing version with the same value,
+// the update is idempotent.
+func (m *SecretsManager) UpdateSecretValue(ctx context.Context, in *secretsmanager.UpdateSecretInput) (*secretsmanager.UpdateSecretOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeDeletedSecrets()
+
+	s, err := m.findAccessibleSecret(in.SecretId)
+	if err != nil {
+		return nil, err
+	}
+	hasValue := in.SecretString != nil || in.SecretBinary != nil
+	if !hasValue && in.Description == nil && in.KmsKeyId == nil {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, "must specify a new value, description, or KMS key", nil)
+	}
+	if hasValue {
+		if err := validateSecretValue(in.SecretString, in.SecretBinary); err != nil {
+			return nil, err
+		}
+	}
+
+	ts := m.now()
+	out := &secretsmanager.UpdateSecretOutput{
+		ARN:  utility.ToStringPtr(s.arn),
+		Name: utility.ToStringPtr(s.name),
+	}
+
+	if hasValue {
+		versionID := utility.FromStringPtr(in.ClientRequestToken)
+		if versionID == "" {
+			versionID = utility.RandomString()
+		}
+		if existing, ok := s.versions[versionID]; ok {
+			if !existing.hasSameValue(in.SecretString, in.SecretBinary) {
+				return nil, awserr.New(secretsmanager.ErrCodeResourceExistsException, "a version with this client request token already exists with a different value", nil)
+			}
+			out.VersionId = utility.ToStringPtr(versionID)
+			return out, nil
+		}
+
+		if previous := s.versionWithStage(versionStagePrevious); previous != nil {
+			previous.removeStage(versionStagePrevious)
+		}
+		if current := s.versionWithStage(versionStageCurrent); current != nil {
+			current.removeStage(versionStageCurrent)
+			current.stages = append(current.stages, versionStagePrevious)
+		}
+		s.versions[versionID] = &fakeSecretVersion{
+			id:           versionID,
+			secretString: in.SecretString,
+			secretBinary: in.SecretBinary,
+			stages:       []string{versionStageCurrent},
+			created:      ts,
+		}
+		out.VersionId = utility.ToStringPtr(versionID)
+	}
+	if in.Description != nil {
+		s.description = in.Description
+	}
+	if in.KmsKeyId != nil {
+		s.kmsKeyID = in.KmsKeyId
+	}
+	s.lastChanged = ts
+
+	return out, nil
+}
+
+// DeleteSecret schedules a secret for deletion after the recovery window, which
+// defaults to 30 days. If ForceDeleteWithoutRecovery is set, the secret is
+// deleted immediately instead.
+func (m *SecretsManager) DeleteSecret(ctx context.Context, in *secretsmanager.DeleteSecretInput) (*secretsmanager.DeleteSecretOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeDeletedSecrets()
+
+	if in.SecretId == nil {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, "missing secret ID", nil)
+	}
+	force := utility.FromBoolPtr(in.ForceDeleteWithoutRecovery)
+	if force && in.RecoveryWindowInDays != nil {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, "cannot force delete without recovery and also schedule a recovery window", nil)
+	}
+	window := int(utility.FromInt64Ptr(in.RecoveryWindowInDays))
+	if in.RecoveryWindowInDays != nil && (window < minRecoveryWindowDays || window > maxRecoveryWindowDays) {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, fmt.Sprintf("recovery window must be between %d and %d days", minRecoveryWindowDays, maxRecoveryWindowDays), nil)
+	}
+	if window == 0 {
+		window = defaultRecoveryWindowDays
+	}
+
+	// Force deleting a secret that is already being deleted without recovery
+	// succeeds, since the secret has not been removed yet.
+	s := m.findSecret(*in.SecretId)
+	if s == nil || (s.forceDeleted && !force) {
+		return nil, cocoa.NewSecretNotFoundError(*in.SecretId, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil))
+	}
+
+	ts := m.now()
+	if force {
+		s.forceDeleted = true
+		s.deletionDate = time.Time{}
+		return &secretsmanager.DeleteSecretOutput{
+			ARN:          utility.ToStringPtr(s.arn),
+			Name:         utility.ToStringPtr(s.name),
+			DeletionDate: utility.ToTimePtr(ts),
+		}, nil
+	}
+
+	if s.isScheduledForDeletion() {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidRequestException, "secret is already scheduled for deletion", nil)
+	}
+	s.deletionDate = ts.AddDate(0, 0, window)
+
+	return &secretsmanager.DeleteSecretOutput{
+		ARN:          utility.ToStringPtr(s.arn),
+		Name:         utility.ToStringPtr(s.name),
+		DeletionDate: utility.ToTimePtr(s.deletionDate),
+	}, nil
+}
+
+// RestoreSecret cancels the scheduled deletion of a secret that is still within
+// its recovery window.
+func (m *SecretsManager) RestoreSecret(ctx context.Context, in *secretsmanager.RestoreSecretInput) (*secretsmanager.RestoreSecretOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeDeletedSecrets()
+
+	if in.SecretId == nil {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, "missing secret ID", nil)
+	}
+	s := m.findSecret(*in.SecretId)
+	if s == nil || s.forceDeleted {
+		return nil, cocoa.NewSecretNotFoundError(*in.SecretId, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil))
+	}
+
+	s.deletionDate = time.Time{}
+
+	return &secretsmanager.RestoreSecretOutput{
+		ARN:  utility.ToStringPtr(s.arn),
+		Name: utility.ToStringPtr(s.name),
+	}, nil
+}
+
+// RotateSecret enables rotation for a secret and rotates it. The secret must
+// either already have a rotation function or be given one in the input. If
+// RotateImmediately is explicitly false, the rotation is only tested;
+// otherwise, a new version of the secret with the same value is labeled
+// AWSCURRENT as if the rotation function had succeeded.
+func (m *SecretsManager) RotateSecret(ctx context.Context, in *secretsmanager.RotateSecretInput) (*secretsmanager.RotateSecretOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeDeletedSecrets()
+
+	s, err := m.findAccessibleSecret(in.SecretId)
+	if err != nil {
+		return nil, err
+	}
+	if in.RotationLambdaARN != nil {
+		s.rotationLambdaARN = in.RotationLambdaARN
+	}
+	if s.rotationLambdaARN == nil {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidRequestException, "secret does not have a rotation function", nil)
+	}
+
+	versionID := utility.FromStringPtr(in.ClientRequestToken)
+	if versionID == "" {
+		versionID = utility.RandomString()
+	}
+	if _, ok := s.versions[versionID]; ok {
+		return nil, awserr.New(secretsmanager.ErrCodeResourceExistsException, "a version with this client request token already exists", nil)
+	}
+
+	s.rotationEnabled = true
+	if in.RotateImmediately == nil || *in.RotateImmediately {
+		ts := m.now()
+		current := s.versionWithStage(versionStageCurrent)
+		if previous := s.versionWithStage(versionStagePrevious); previous != nil {
+			previous.removeStage(versionStagePrevious)
+		}
+		rotated := &fakeSecretVersion{
+			id:      versionID,
+			stages:  []string{versionStageCurrent},
+			created: ts,
+		}
+		if current != nil {
+			current.removeStage(versionStageCurrent)
+			current.stages = append(current.stages, versionStagePrevious)
+			rotated.secretString = current.secretString
+			rotated.secretBinary = current.secretBinary
+		}
+		s.versions[versionID] = rotated
+		s.lastRotated = ts
+		s.lastChanged = ts
+	}
+
+	return &secretsmanager.RotateSecretOutput{
+		ARN:       utility.ToStringPtr(s.arn),
+		Name:      utility.ToStringPtr(s.name),
+		VersionId: utility.ToStringPtr(versionID),
+	}, nil
+}
+
+// CancelRotateSecret turns off rotation for a secret. Since rotations in the
+// fake Secrets Manager finish immediately, there is never a rotation in
+// progress to cancel.
+func (m *SecretsManager) CancelRotateSecret(ctx context.Context, in *secretsmanager.CancelRotateSecretInput) (*secretsmanager.CancelRotateSecretOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeDeletedSecrets()
+
+	s, err := m.findAccessibleSecret(in.SecretId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rotationEnabled = false
+
+	return &secretsmanager.CancelRotateSecretOutput{
+		ARN:  utility.ToStringPtr(s.arn),
+		Name: utility.ToStringPtr(s.name),
+	}, nil
+}
+
+// TagResource adds tags to a secret, overwriting the values of existing tags
+// with the same keys.
+func (m *SecretsManager) TagResource(ctx context.Context, in *secretsmanager.TagResourceInput) (*secretsmanager.TagResourceOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeDeletedSecrets()
+
+	s, err := m.findAccessibleSecret(in.SecretId)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range newSecretsManagerTags(in.Tags) {
+		s.tags[k] = v
+	}
+
+	return &secretsmanager.TagResourceOutput{}, nil
+}
+
+// Close is a no-op.
+func (m *SecretsManager) Close(ctx context.Context) error {
+	return nil
+}
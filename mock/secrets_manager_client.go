@@ -3,10 +3,12 @@ package mock
 import (
 	"context"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
 	"github.com/evergreen-ci/utility"
 )
 
@@ -24,6 +26,15 @@ type StoredSecret struct {
 	LastAccessed time.Time
 	Deleted      time.Time
 	Tags         map[string]string
+	// VersionID is the ID of the current version of the secret's value.
+	VersionID string
+	// PreviousVersionID is the ID of the version of the secret's value before
+	// the latest update, if any.
+	PreviousVersionID string
+	// RotationEnabled is whether or not rotation is enabled for the secret.
+	RotationEnabled bool
+	// LastRotated is the last time the secret was rotated.
+	LastRotated time.Time
 }
 
 func newStoredSecret(in *secretsmanager.CreateSecretInput, ts time.Time) StoredSecret {
@@ -34,19 +45,33 @@ func newStoredSecret(in *secretsmanager.CreateSecretInput, ts time.Time) StoredS
 		Created:      ts,
 		LastAccessed: ts,
 		Tags:         newSecretsManagerTags(in.Tags),
+		VersionID:    utility.RandomString(),
 	}
 	return s
 }
 
+// versionIDsToStages returns the staging labels of the secret's versions.
+func (s StoredSecret) versionIDsToStages() map[string][]*string {
+	versions := map[string][]*string{}
+	if s.VersionID != "" {
+		versions[s.VersionID] = []*string{utility.ToStringPtr("AWSCURRENT")}
+	}
+	if s.PreviousVersionID != "" {
+		versions[s.PreviousVersionID] = []*string{utility.ToStringPtr("AWSPREVIOUS")}
+	}
+	return versions
+}
+
 func exportSecretListEntry(s StoredSecret) *secretsmanager.SecretListEntry {
 	return &secretsmanager.SecretListEntry{
-		ARN:              utility.ToStringPtr(s.Name),
-		Name:             utility.ToStringPtr(s.Name),
-		CreatedDate:      utility.ToTimePtr(s.Created),
-		LastAccessedDate: utility.ToTimePtr(s.LastAccessed),
-		LastChangedDate:  utility.ToTimePtr(s.LastUpdated),
-		DeletedDate:      utility.ToTimePtr(s.Deleted),
-		Tags:             exportSecretsManagerTags(s.Tags),
+		ARN:                    utility.ToStringPtr(s.Name),
+		Name:                   utility.ToStringPtr(s.Name),
+		CreatedDate:            utility.ToTimePtr(s.Created),
+		LastAccessedDate:       utility.ToTimePtr(s.LastAccessed),
+		LastChangedDate:        utility.ToTimePtr(s.LastUpdated),
+		DeletedDate:            utility.ToTimePtr(s.Deleted),
+		Tags:                   exportSecretsManagerTags(s.Tags),
+		SecretVersionsToStages: s.versionIDsToStages(),
 	}
 }
 
@@ -72,6 +97,10 @@ func exportSecretsManagerTags(tags map[string]string) []*secretsmanager.Tag {
 	return exported
 }
 
+// globalSecretCacheMu protects the global secret cache so that the
+// SecretsManagerClient is safe for concurrent use.
+var globalSecretCacheMu sync.Mutex
+
 // GlobalSecretCache is a global secret storage cache that provides a simplified
 // in-memory implementation of a secrets storage service. This can be used
 // indirectly with the SecretsManagerClient to access and modify secrets, or
@@ -118,18 +147,49 @@ type SecretsManagerClient struct {
 	DeleteSecretOutput *secretsmanager.DeleteSecretOutput
 	DeleteSecretError  error
 
+	RestoreSecretInput  *secretsmanager.RestoreSecretInput
+	RestoreSecretOutput *secretsmanager.RestoreSecretOutput
+	RestoreSecretError  error
+
+	RotateSecretInput  *secretsmanager.RotateSecretInput
+	RotateSecretOutput *secretsmanager.RotateSecretOutput
+	RotateSecretError  error
+
+	CancelRotateSecretInput  *secretsmanager.CancelRotateSecretInput
+	CancelRotateSecretOutput *secretsmanager.CancelRotateSecretOutput
+	CancelRotateSecretError  error
+
 	TagResourceInput  *secretsmanager.TagResourceInput
 	TagResourceOutput *secretsmanager.TagResourceOutput
 	TagResourceError  error
 
 	CloseError error
+
+	recordedCalls []RecordedCall
+}
+
+// RecordedCalls returns all the calls made to the mock client in the order
+// that they were made.
+func (c *SecretsManagerClient) RecordedCalls() []RecordedCall {
+	globalSecretCacheMu.Lock()
+	defer globalSecretCacheMu.Unlock()
+
+	return c.recordedCalls
+}
+
+func (c *SecretsManagerClient) recordCall(op string, in interface{}) {
+	c.recordedCalls = append(c.recordedCalls, RecordedCall{Operation: op, Input: in})
 }
 
 // CreateSecret saves the input options and returns a new mock secret. The mock
 // output can be customized. By default, it will create and save a cached mock
 // secret based on the input in the global secret cache.
 func (c *SecretsManagerClient) CreateSecret(ctx context.Context, in *secretsmanager.CreateSecretInput) (*secretsmanager.CreateSecretOutput, error) {
+	globalSecretCacheMu.Lock()
+	defer globalSecretCacheMu.Unlock()
+
 	c.CreateSecretInput = in
+	c.recordCall("CreateSecret", in)
 
 	if c.CreateSecretOutput != nil || c.CreateSecretError != nil {
 		return c.CreateSecretOutput, c.CreateSecretError
@@ -163,7 +223,11 @@ func (c *SecretsManagerClient) CreateSecret(ctx context.Context, in *secretsmana
 // value. The mock output can be customized. By default, it will return a cached
 // mock secret if it exists in the global secret cache.
 func (c *SecretsManagerClient) GetSecretValue(ctx context.Context, in *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	globalSecretCacheMu.Lock()
+	defer globalSecretCacheMu.Unlock()
+
 	c.GetSecretValueInput = in
+	c.recordCall("GetSecretValue", in)
 
 	if c.GetSecretValueOutput != nil || c.GetSecretValueError != nil {
 		return c.GetSecretValueOutput, c.GetSecretValueError
@@ -176,7 +240,7 @@ func (c *SecretsManagerClient) GetSecretValue(ctx context.Context, in *secretsma
 	id := utility.FromStringPtr(in.SecretId)
 	s := c.getSecret(id)
 	if s == nil {
-		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil)
+		return nil, cocoa.NewSecretNotFoundError(id, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil))
 	}
 
 	if s.IsDeleted {
@@ -187,11 +251,13 @@ func (c *SecretsManagerClient) GetSecretValue(ctx context.Context, in *secretsma
 	GlobalSecretCache[id] = *s
 
 	return &secretsmanager.GetSecretValueOutput{
-		ARN:          utility.ToStringPtr(s.Name),
-		Name:         utility.ToStringPtr(s.Name),
-		SecretString: utility.ToStringPtr(s.Value),
-		SecretBinary: s.BinaryValue,
-		CreatedDate:  utility.ToTimePtr(s.Created),
+		ARN:           utility.ToStringPtr(s.Name),
+		Name:          utility.ToStringPtr(s.Name),
+		SecretString:  utility.ToStringPtr(s.Value),
+		SecretBinary:  s.BinaryValue,
+		CreatedDate:   utility.ToTimePtr(s.Created),
+		VersionId:     utility.ToStringPtr(s.VersionID),
+		VersionStages: []*string{utility.ToStringPtr("AWSCURRENT")},
 	}, nil
 }
 
@@ -212,7 +278,11 @@ func (c *SecretsManagerClient) getSecret(id string) *StoredSecret {
 // return information about the cached mock secret if it exists in the global
 // secret cache.
 func (c *SecretsManagerClient) DescribeSecret(ctx context.Context, in *secretsmanager.DescribeSecretInput) (*secretsmanager.DescribeSecretOutput, error) {
+	globalSecretCacheMu.Lock()
+	defer globalSecretCacheMu.Unlock()
+
 	c.DescribeSecretInput = in
+	c.recordCall("DescribeSecret", in)
 
 	if c.DescribeSecretOutput != nil || c.DescribeSecretError != nil {
 		return c.DescribeSecretOutput, c.DescribeSecretError
@@ -224,17 +294,20 @@ func (c *SecretsManagerClient) DescribeSecret(ctx context.Context, in *secretsma
 
 	s, ok := GlobalSecretCache[utility.FromStringPtr(in.SecretId)]
 	if !ok {
-		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil)
+		return nil, cocoa.NewSecretNotFoundError(utility.FromStringPtr(in.SecretId), awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil))
 	}
 
 	return &secretsmanager.DescribeSecretOutput{
-		ARN:              utility.ToStringPtr(s.Name),
-		Name:             utility.ToStringPtr(s.Name),
-		CreatedDate:      utility.ToTimePtr(s.Created),
-		LastAccessedDate: utility.ToTimePtr(s.LastAccessed),
-		LastChangedDate:  utility.ToTimePtr(s.LastUpdated),
-		DeletedDate:      utility.ToTimePtr(s.Deleted),
-		Tags:             exportSecretsManagerTags(s.Tags),
+		ARN:                utility.ToStringPtr(s.Name),
+		Name:               utility.ToStringPtr(s.Name),
+		CreatedDate:        utility.ToTimePtr(s.Created),
+		LastAccessedDate:   utility.ToTimePtr(s.LastAccessed),
+		LastChangedDate:    utility.ToTimePtr(s.LastUpdated),
+		DeletedDate:        utility.ToTimePtr(s.Deleted),
+		Tags:               exportSecretsManagerTags(s.Tags),
+		VersionIdsToStages: s.versionIDsToStages(),
+		RotationEnabled:    utility.ToBoolPtr(s.RotationEnabled),
+		LastRotatedDate:    utility.ToTimePtr(s.LastRotated),
 	}, nil
 }
 
@@ -242,7 +315,11 @@ func (c *SecretsManagerClient) DescribeSecret(ctx context.Context, in *secretsma
 // metadata information. The mock output can be customized. By default, it will
 // return any matching cached mock secrets in the global secret cache.
 func (c *SecretsManagerClient) ListSecrets(ctx context.Context, in *secretsmanager.ListSecretsInput) (*secretsmanager.ListSecretsOutput, error) {
+	globalSecretCacheMu.Lock()
+	defer globalSecretCacheMu.Unlock()
+
 	c.ListSecretsInput = in
+	c.recordCall("ListSecrets", in)
 
 	if c.ListSecretsOutput != nil || c.ListSecretsError != nil {
 		return c.ListSecretsOutput, c.ListSecretsError
@@ -260,6 +337,10 @@ func (c *SecretsManagerClient) ListSecrets(ctx context.Context, in *secretsmanag
 			switch utility.FromStringPtr(f.Key) {
 			case "name":
 				matchingValues = c.secretsMatchingAnyNameValue(utility.FromStringPtrSlice(f.Values))
+			case "tag-key":
+				matchingValues = c.secretsMatchingAnyTag(utility.FromStringPtrSlice(f.Values), func(k, _ string) string { return k })
+			case "tag-value":
+				matchingValues = c.secretsMatchingAnyTag(utility.FromStringPtrSlice(f.Values), func(_, v string) string { return v })
 				// This could support other filter keys, but it's not worth it
 				// unless the need arises.
 			default:
@@ -321,11 +402,35 @@ func (c *SecretsManagerClient) secretsMatchingAnyNameValue(vals []string) map[st
 	return secrets
 }
 
+// secretsMatchingAnyTag returns all secrets that have a tag matching any of the
+// given values. The tag's key or value to compare against is chosen by the
+// given tag field function.
+func (c *SecretsManagerClient) secretsMatchingAnyTag(vals []string, tagField func(k, v string) string) map[string]StoredSecret {
+	secrets := map[string]StoredSecret{}
+	for _, s := range GlobalSecretCache {
+		if s.IsDeleted {
+			continue
+		}
+
+		for k, v := range s.Tags {
+			if utility.StringSliceContains(vals, tagField(k, v)) {
+				secrets[s.Name] = s
+				break
+			}
+		}
+	}
+	return secrets
+}
+
 // UpdateSecretValue saves the input options and returns an updated mock secret
 // value. The mock output can be customized. By default, it will update a cached
 // mock secret if it exists in the global secret cache.
 func (c *SecretsManagerClient) UpdateSecretValue(ctx context.Context, in *secretsmanager.UpdateSecretInput) (*secretsmanager.UpdateSecretOutput, error) {
+	globalSecretCacheMu.Lock()
+	defer globalSecretCacheMu.Unlock()
+
 	c.UpdateSecretInput = in
+	c.recordCall("UpdateSecretValue", in)
 
 	if c.UpdateSecretOutput != nil || c.UpdateSecretError != nil {
 		return c.UpdateSecretOutput, c.UpdateSecretError
@@ -344,7 +449,7 @@ func (c *SecretsManagerClient) UpdateSecretValue(ctx context.Context, in *secret
 	id := utility.FromStringPtr(in.SecretId)
 	s, ok := GlobalSecretCache[id]
 	if !ok {
-		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil)
+		return nil, cocoa.NewSecretNotFoundError(id, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil))
 	}
 
 	if s.IsDeleted {
@@ -358,6 +463,9 @@ func (c *SecretsManagerClient) UpdateSecretValue(ctx context.Context, in *secret
 		s.Value = *in.SecretString
 	}
 
+	s.PreviousVersionID = s.VersionID
+	s.VersionID = utility.RandomString()
+
 	ts := time.Now()
 	s.LastAccessed = ts
 	s.LastUpdated = ts
@@ -365,8 +473,9 @@ func (c *SecretsManagerClient) UpdateSecretValue(ctx context.Context, in *secret
 	GlobalSecretCache[id] = s
 
 	return &secretsmanager.UpdateSecretOutput{
-		ARN:  utility.ToStringPtr(s.Name),
-		Name: utility.ToStringPtr(s.Name),
+		ARN:       utility.ToStringPtr(s.Name),
+		Name:      utility.ToStringPtr(s.Name),
+		VersionId: utility.ToStringPtr(s.VersionID),
 	}, nil
 }
 
@@ -374,7 +483,11 @@ func (c *SecretsManagerClient) UpdateSecretValue(ctx context.Context, in *secret
 // mock output can be customized. By default, it will delete a cached mock
 // secret if it exists.
 func (c *SecretsManagerClient) DeleteSecret(ctx context.Context, in *secretsmanager.DeleteSecretInput) (*secretsmanager.DeleteSecretOutput, error) {
+	globalSecretCacheMu.Lock()
+	defer globalSecretCacheMu.Unlock()
+
 	c.DeleteSecretInput = in
+	c.recordCall("DeleteSecret", in)
 
 	if c.DeleteSecretOutput != nil || c.DeleteSecretError != nil {
 		return c.DeleteSecretOutput, c.DeleteSecretError
@@ -399,7 +512,7 @@ func (c *SecretsManagerClient) DeleteSecret(ctx context.Context, in *secretsmana
 	id := utility.FromStringPtr(in.SecretId)
 	s, ok := GlobalSecretCache[id]
 	if !utility.FromBoolPtr(in.ForceDeleteWithoutRecovery) && !ok {
-		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil)
+		return nil, cocoa.NewSecretNotFoundError(id, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil))
 	}
 
 	ts := time.Now()
@@ -418,11 +531,142 @@ func (c *SecretsManagerClient) DeleteSecret(ctx context.Context, in *secretsmana
 	}, nil
 }
 
+// RestoreSecret saves the input options and restores a mock secret that is
+// scheduled for deletion. The mock output can be customized. By default, it
+// will cancel the deletion of the cached mock secret if it exists and was not
+// deleted without recovery.
+func (c *SecretsManagerClient) RestoreSecret(ctx context.Context, in *secretsmanager.RestoreSecretInput) (*secretsmanager.RestoreSecretOutput, error) {
+	globalSecretCacheMu.Lock()
+	defer globalSecretCacheMu.Unlock()
+
+	c.RestoreSecretInput = in
+	c.recordCall("RestoreSecret", in)
+
+	if c.RestoreSecretOutput != nil || c.RestoreSecretError != nil {
+		return c.RestoreSecretOutput, c.RestoreSecretError
+	}
+
+	if in.SecretId == nil {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, "missing secret ID", nil)
+	}
+
+	id := utility.FromStringPtr(in.SecretId)
+	s, ok := GlobalSecretCache[id]
+	if !ok {
+		return nil, cocoa.NewSecretNotFoundError(id, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil))
+	}
+	if s.IsDeleted && s.Deleted.IsZero() {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidRequestException, "secret was deleted without recovery", nil)
+	}
+
+	s.IsDeleted = false
+	s.Deleted = time.Time{}
+	s.LastUpdated = time.Now()
+	GlobalSecretCache[id] = s
+
+	return &secretsmanager.RestoreSecretOutput{
+		ARN:  utility.ToStringPtr(s.Name),
+		Name: utility.ToStringPtr(s.Name),
+	}, nil
+}
+
+// RotateSecret saves the input options and rotates an existing mock secret.
+// The mock output can be customized. By default, it will enable rotation for
+// the cached mock secret if it exists and simulate a rotation function that
+// succeeds. If RotateImmediately is explicitly false, the rotation is only
+// tested, so the secret's current version does not change; otherwise, a new
+// version of the secret with the same value becomes the current version.
+func (c *SecretsManagerClient) RotateSecret(ctx context.Context, in *secretsmanager.RotateSecretInput) (*secretsmanager.RotateSecretOutput, error) {
+	globalSecretCacheMu.Lock()
+	defer globalSecretCacheMu.Unlock()
+
+	c.RotateSecretInput = in
+	c.recordCall("RotateSecret", in)
+
+	if c.RotateSecretOutput != nil || c.RotateSecretError != nil {
+		return c.RotateSecretOutput, c.RotateSecretError
+	}
+
+	if in.SecretId == nil {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, "missing secret ID", nil)
+	}
+
+	id := utility.FromStringPtr(in.SecretId)
+	s, ok := GlobalSecretCache[id]
+	if !ok {
+		return nil, cocoa.NewSecretNotFoundError(id, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil))
+	}
+	if s.IsDeleted {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidRequestException, "secret is deleted", nil)
+	}
+
+	versionID := utility.FromStringPtr(in.ClientRequestToken)
+	if versionID == "" {
+		versionID = utility.RandomString()
+	}
+
+	s.RotationEnabled = true
+	if in.RotateImmediately == nil || *in.RotateImmediately {
+		ts := time.Now()
+		s.PreviousVersionID = s.VersionID
+		s.VersionID = versionID
+		s.LastRotated = ts
+		s.LastUpdated = ts
+	}
+	GlobalSecretCache[id] = s
+
+	return &secretsmanager.RotateSecretOutput{
+		ARN:       utility.ToStringPtr(s.Name),
+		Name:      utility.ToStringPtr(s.Name),
+		VersionId: utility.ToStringPtr(versionID),
+	}, nil
+}
+
+// CancelRotateSecret saves the input options and turns off rotation for an
+// existing mock secret. The mock output can be customized. By default, it will
+// disable rotation for the cached mock secret if it exists.
+func (c *SecretsManagerClient) CancelRotateSecret(ctx context.Context, in *secretsmanager.CancelRotateSecretInput) (*secretsmanager.CancelRotateSecretOutput, error) {
+	globalSecretCacheMu.Lock()
+	defer globalSecretCacheMu.Unlock()
+
+	c.CancelRotateSecretInput = in
+	c.recordCall("CancelRotateSecret", in)
+
+	if c.CancelRotateSecretOutput != nil || c.CancelRotateSecretError != nil {
+		return c.CancelRotateSecretOutput, c.CancelRotateSecretError
+	}
+
+	if in.SecretId == nil {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidParameterException, "missing secret ID", nil)
+	}
+
+	id := utility.FromStringPtr(in.SecretId)
+	s, ok := GlobalSecretCache[id]
+	if !ok {
+		return nil, cocoa.NewSecretNotFoundError(id, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "secret not found", nil))
+	}
+	if s.IsDeleted {
+		return nil, awserr.New(secretsmanager.ErrCodeInvalidRequestException, "secret is deleted", nil)
+	}
+
+	s.RotationEnabled = false
+	GlobalSecretCache[id] = s
+
+	return &secretsmanager.CancelRotateSecretOutput{
+		ARN:  utility.ToStringPtr(s.Name),
+		Name: utility.ToStringPtr(s.Name),
+	}, nil
+}
+
 // TagResource saves the input options and tags an existing mock secret. The
 // mock output can be customized. By default, it will tag the cached mock
 // secret if it exists.
 func (c *SecretsManagerClient) TagResource(ctx context.Context, in *secretsmanager.TagResourceInput) (*secretsmanager.TagResourceOutput, error) {
+	globalSecretCacheMu.Lock()
+	defer globalSecretCacheMu.Unlock()
+
 	c.TagResourceInput = in
+	c.recordCall("TagResource", in)
 
 	if c.TagResourceOutput != nil || c.TagResourceError != nil {
 		return c.TagResourceOutput, c.TagResourceError
@@ -4,9 +4,13 @@ import (
 	"context"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
 	"github.com/evergreen-ci/cocoa"
 	"github.com/evergreen-ci/cocoa/internal/testcase"
+	"github.com/evergreen-ci/cocoa/internal/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSecretsManagerClient(t *testing.T) {
@@ -31,3 +35,93 @@ func TestSecretsManagerClient(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretsManagerClientRecordedCalls(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	t.Run("IsEmptyWithoutCalls", func(t *testing.T) {
+		c := &SecretsManagerClient{}
+		assert.Empty(t, c.RecordedCalls())
+	})
+	t.Run("TracksOperationsInOrder", func(t *testing.T) {
+		resetECSAndSecretsManagerCache()
+
+		c := &SecretsManagerClient{}
+		createIn := &secretsmanager.CreateSecretInput{
+			Name:         aws.String("name"),
+			SecretString: aws.String("value"),
+		}
+		_, err := c.CreateSecret(ctx, createIn)
+		require.NoError(t, err)
+
+		getIn := &secretsmanager.GetSecretValueInput{SecretId: aws.String("name")}
+		_, err = c.GetSecretValue(ctx, getIn)
+		require.NoError(t, err)
+
+		deleteIn := &secretsmanager.DeleteSecretInput{SecretId: aws.String("name")}
+		_, err = c.DeleteSecret(ctx, deleteIn)
+		require.NoError(t, err)
+
+		calls := c.RecordedCalls()
+		require.Len(t, calls, 3)
+		assert.Equal(t, "CreateSecret", calls[0].Operation)
+		assert.Equal(t, createIn, calls[0].Input)
+		assert.Equal(t, "GetSecretValue", calls[1].Operation)
+		assert.Equal(t, getIn, calls[1].Input)
+		assert.Equal(t, "DeleteSecret", calls[2].Operation)
+		assert.Equal(t, deleteIn, calls[2].Input)
+	})
+	t.Run("TracksCallsWithCustomizedOutput", func(t *testing.T) {
+		c := &SecretsManagerClient{
+			DescribeSecretOutput: &secretsmanager.DescribeSecretOutput{},
+		}
+		in := &secretsmanager.DescribeSecretInput{SecretId: aws.String("name")}
+		_, err := c.DescribeSecret(ctx, in)
+		require.NoError(t, err)
+
+		calls := c.RecordedCalls()
+		require.Len(t, calls, 1)
+		assert.Equal(t, "DescribeSecret", calls[0].Operation)
+		assert.Equal(t, in, calls[0].Input)
+	})
+}
+
+func TestCreateAndCleanupSecret(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ResetGlobalSecretCache()
+	defer ResetGlobalSecretCache()
+
+	c := &SecretsManagerClient{}
+	defer func() {
+		assert.NoError(t, c.Close(ctx))
+	}()
+
+	var secretIDs []string
+	for _, tName := range []string{"FirstSubtest", "SecondSubtest"} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			secretID := testutil.CreateAndCleanupSecret(tctx, t, c, "prefix")
+			assert.Contains(t, secretID, "prefix")
+
+			s, ok := GlobalSecretCache[secretID]
+			require.True(t, ok)
+			assert.False(t, s.IsDeleted)
+			assert.NotZero(t, s.Value)
+
+			secretIDs = append(secretIDs, secretID)
+		})
+	}
+
+	require.Len(t, secretIDs, 2)
+	assert.NotEqual(t, secretIDs[0], secretIDs[1], "each subtest should get a unique secret")
+	for _, id := range secretIDs {
+		s, ok := GlobalSecretCache[id]
+		require.True(t, ok)
+		assert.True(t, s.IsDeleted, "secret should be deleted after the subtest completes")
+	}
+}
@@ -0,0 +1,332 @@
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/internal/testcase"
+	"github.com/evergreen-ci/cocoa/secret"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager(t *testing.T) {
+	assert.Implements(t, (*cocoa.SecretsManagerClient)(nil), &SecretsManager{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("SecretsManagerClientTests", func(t *testing.T) {
+		for tName, tCase := range testcase.SecretsManagerClientTests() {
+			t.Run(tName, func(t *testing.T) {
+				tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+				defer tcancel()
+
+				c := NewSecretsManager()
+				defer func() {
+					assert.NoError(t, c.Close(tctx))
+				}()
+
+				tCase(tctx, t, c)
+			})
+		}
+	})
+
+	createSecret := func(ctx context.Context, t *testing.T, c *SecretsManager, name, value string) *secretsmanager.CreateSecretOutput {
+		out, err := c.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
+			Name:         aws.String(name),
+			SecretString: aws.String(value),
+		})
+		require.NoError(t, err)
+		require.NotZero(t, out)
+		return out
+	}
+	getSecretValue := func(ctx context.Context, t *testing.T, c *SecretsManager, in *secretsmanager.GetSecretValueInput) *secretsmanager.GetSecretValueOutput {
+		out, err := c.GetSecretValue(ctx, in)
+		require.NoError(t, err)
+		require.NotZero(t, out)
+		return out
+	}
+	checkErrorCode := func(t *testing.T, err error, code string) {
+		require.Error(t, err)
+		var awsErr awserr.Error
+		require.True(t, errors.As(err, &awsErr))
+		assert.Equal(t, code, awsErr.Code())
+		if code == secretsmanager.ErrCodeResourceNotFoundException {
+			assert.True(t, errors.Is(err, cocoa.ErrSecretNotFound))
+		}
+	}
+	updateSecretValue := func(ctx context.Context, t *testing.T, c *SecretsManager, id *string, value string) {
+		_, err := c.UpdateSecretValue(ctx, &secretsmanager.UpdateSecretInput{
+			SecretId:     id,
+			SecretString: aws.String(value),
+		})
+		require.NoError(t, err)
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock){
+		"CreateSecretReturnsWellFormedARN": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "value")
+
+			parsed, err := secret.ParseSecretARN(utility.FromStringPtr(out.ARN))
+			require.NoError(t, err)
+			assert.Equal(t, SecretsManagerAccountID, parsed.AccountID)
+			assert.Equal(t, SecretsManagerRegion, parsed.Region)
+			assert.Equal(t, "name", parsed.SecretName)
+			assert.NotZero(t, out.VersionId)
+		},
+		"CreateSecretUsesConfiguredAccountIDAndRegion": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			c.AccountID = "111111111111"
+			c.Region = "us-west-2"
+			out := createSecret(ctx, t, c, "name", "value")
+
+			parsed, err := secret.ParseSecretARN(utility.FromStringPtr(out.ARN))
+			require.NoError(t, err)
+			assert.Equal(t, "111111111111", parsed.AccountID)
+			assert.Equal(t, "us-west-2", parsed.Region)
+		},
+		"CreateSecretFailsWithDuplicateName": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			createSecret(ctx, t, c, "name", "value")
+
+			_, err := c.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
+				Name:         aws.String("name"),
+				SecretString: aws.String("other_value"),
+			})
+			checkErrorCode(t, err, secretsmanager.ErrCodeResourceExistsException)
+		},
+		"GetSecretValueWorksWithNameOrARN": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "value")
+
+			byName := getSecretValue(ctx, t, c, &secretsmanager.GetSecretValueInput{SecretId: aws.String("name")})
+			byARN := getSecretValue(ctx, t, c, &secretsmanager.GetSecretValueInput{SecretId: out.ARN})
+			assert.Equal(t, "value", utility.FromStringPtr(byName.SecretString))
+			assert.Equal(t, byName.ARN, byARN.ARN)
+			assert.Equal(t, []string{versionStageCurrent}, utility.FromStringPtrSlice(byARN.VersionStages))
+		},
+		"UpdateSecretValueRotatesVersionStages": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "v1")
+			updateSecretValue(ctx, t, c, out.ARN, "v2")
+			updateSecretValue(ctx, t, c, out.ARN, "v3")
+
+			current := getSecretValue(ctx, t, c, &secretsmanager.GetSecretValueInput{SecretId: out.ARN})
+			assert.Equal(t, "v3", utility.FromStringPtr(current.SecretString))
+			previous := getSecretValue(ctx, t, c, &secretsmanager.GetSecretValueInput{
+				SecretId:     out.ARN,
+				VersionStage: aws.String(versionStagePrevious),
+			})
+			assert.Equal(t, "v2", utility.FromStringPtr(previous.SecretString))
+
+			original := getSecretValue(ctx, t, c, &secretsmanager.GetSecretValueInput{
+				SecretId:  out.ARN,
+				VersionId: out.VersionId,
+			})
+			assert.Equal(t, "v1", utility.FromStringPtr(original.SecretString))
+			assert.Empty(t, original.VersionStages, "oldest version should no longer have any staging labels")
+
+			describeOut, err := c.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: out.ARN})
+			require.NoError(t, err)
+			assert.Len(t, describeOut.VersionIdsToStages, 2)
+		},
+		"UpdateSecretValueIsIdempotentWithClientRequestToken": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "v1")
+			in := &secretsmanager.UpdateSecretInput{
+				SecretId:           out.ARN,
+				SecretString:       aws.String("v2"),
+				ClientRequestToken: aws.String("token"),
+			}
+			for i := 0; i < 2; i++ {
+				updateOut, err := c.UpdateSecretValue(ctx, in)
+				require.NoError(t, err)
+				assert.Equal(t, "token", utility.FromStringPtr(updateOut.VersionId))
+			}
+
+			previous := getSecretValue(ctx, t, c, &secretsmanager.GetSecretValueInput{
+				SecretId:     out.ARN,
+				VersionStage: aws.String(versionStagePrevious),
+			})
+			assert.Equal(t, "v1", utility.FromStringPtr(previous.SecretString), "retried update should not create another version")
+
+			in.SecretString = aws.String("v3")
+			_, err := c.UpdateSecretValue(ctx, in)
+			checkErrorCode(t, err, secretsmanager.ErrCodeResourceExistsException)
+		},
+		"RotateSecretCreatesNewCurrentVersionWithSameValue": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "value")
+
+			rotateOut, err := c.RotateSecret(ctx, &secretsmanager.RotateSecretInput{
+				SecretId:          out.ARN,
+				RotationLambdaARN: aws.String("arn:aws:lambda:us-east-1:123456789012:function:rotate"),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, rotateOut.VersionId)
+			assert.NotEqual(t, utility.FromStringPtr(out.VersionId), utility.FromStringPtr(rotateOut.VersionId))
+
+			current := getSecretValue(ctx, t, c, &secretsmanager.GetSecretValueInput{SecretId: out.ARN})
+			assert.Equal(t, "value", utility.FromStringPtr(current.SecretString))
+			assert.Equal(t, utility.FromStringPtr(rotateOut.VersionId), utility.FromStringPtr(current.VersionId))
+
+			describeOut, err := c.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: out.ARN})
+			require.NoError(t, err)
+			assert.True(t, utility.FromBoolPtr(describeOut.RotationEnabled))
+			assert.Equal(t, clock.Now(), utility.FromTimePtr(describeOut.LastRotatedDate))
+			assert.Equal(t, []string{versionStagePrevious}, utility.FromStringPtrSlice(describeOut.VersionIdsToStages[utility.FromStringPtr(out.VersionId)]))
+		},
+		"RotateSecretOnlyTestsRotationWithoutRotatingImmediately": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "value")
+
+			_, err := c.RotateSecret(ctx, &secretsmanager.RotateSecretInput{
+				SecretId:          out.ARN,
+				RotationLambdaARN: aws.String("arn:aws:lambda:us-east-1:123456789012:function:rotate"),
+				RotateImmediately: aws.Bool(false),
+			})
+			require.NoError(t, err)
+
+			describeOut, err := c.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: out.ARN})
+			require.NoError(t, err)
+			assert.True(t, utility.FromBoolPtr(describeOut.RotationEnabled))
+			assert.Zero(t, utility.FromTimePtr(describeOut.LastRotatedDate))
+			assert.Len(t, describeOut.VersionIdsToStages, 1)
+		},
+		"RotateSecretFailsWithoutRotationFunction": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "value")
+
+			_, err := c.RotateSecret(ctx, &secretsmanager.RotateSecretInput{SecretId: out.ARN})
+			assert.Error(t, err)
+		},
+		"CancelRotateSecretDisablesRotation": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "value")
+			_, err := c.RotateSecret(ctx, &secretsmanager.RotateSecretInput{
+				SecretId:          out.ARN,
+				RotationLambdaARN: aws.String("arn:aws:lambda:us-east-1:123456789012:function:rotate"),
+			})
+			require.NoError(t, err)
+
+			_, err = c.CancelRotateSecret(ctx, &secretsmanager.CancelRotateSecretInput{SecretId: out.ARN})
+			require.NoError(t, err)
+
+			describeOut, err := c.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: out.ARN})
+			require.NoError(t, err)
+			assert.False(t, utility.FromBoolPtr(describeOut.RotationEnabled))
+		},
+		"DeleteSecretSchedulesDeletionAfterRecoveryWindow": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "value")
+
+			deleteOut, err := c.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{
+				SecretId:             out.ARN,
+				RecoveryWindowInDays: aws.Int64(7),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, clock.Now().AddDate(0, 0, 7), utility.FromTimePtr(deleteOut.DeletionDate))
+
+			_, err = c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: out.ARN})
+			checkErrorCode(t, err, secretsmanager.ErrCodeInvalidRequestException)
+			_, err = c.CreateSecret(ctx, &secretsmanager.CreateSecretInput{Name: aws.String("name"), SecretString: aws.String("value")})
+			checkErrorCode(t, err, secretsmanager.ErrCodeInvalidRequestException)
+			listOut, err := c.ListSecrets(ctx, &secretsmanager.ListSecretsInput{})
+			require.NoError(t, err)
+			assert.Empty(t, listOut.SecretList)
+			describeOut, err := c.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: out.ARN})
+			require.NoError(t, err)
+			assert.Equal(t, deleteOut.DeletionDate, describeOut.DeletedDate)
+
+			clock.Advance(7 * 24 * time.Hour)
+
+			_, err = c.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: out.ARN})
+			checkErrorCode(t, err, secretsmanager.ErrCodeResourceNotFoundException)
+			newOut := createSecret(ctx, t, c, "name", "new_value")
+			assert.NotEqual(t, utility.FromStringPtr(out.ARN), utility.FromStringPtr(newOut.ARN))
+		},
+		"RestoreSecretCancelsDeletion": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "value")
+			_, err := c.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{SecretId: out.ARN})
+			require.NoError(t, err)
+
+			clock.Advance(29 * 24 * time.Hour)
+			_, err = c.RestoreSecret(ctx, &secretsmanager.RestoreSecretInput{SecretId: out.ARN})
+			require.NoError(t, err)
+			clock.Advance(24 * time.Hour)
+
+			getOut := getSecretValue(ctx, t, c, &secretsmanager.GetSecretValueInput{SecretId: out.ARN})
+			assert.Equal(t, "value", utility.FromStringPtr(getOut.SecretString))
+		},
+		"DeleteSecretFailsWithInvalidRecoveryWindow": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "value")
+			_, err := c.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{
+				SecretId:             out.ARN,
+				RecoveryWindowInDays: aws.Int64(1),
+			})
+			checkErrorCode(t, err, secretsmanager.ErrCodeInvalidParameterException)
+		},
+		"ForceDeletedSecretNameCanBeReused": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			out := createSecret(ctx, t, c, "name", "value")
+			_, err := c.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{
+				SecretId:                   out.ARN,
+				ForceDeleteWithoutRecovery: aws.Bool(true),
+			})
+			require.NoError(t, err)
+
+			_, err = c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: out.ARN})
+			checkErrorCode(t, err, secretsmanager.ErrCodeResourceNotFoundException)
+
+			createSecret(ctx, t, c, "name", "new_value")
+			getOut := getSecretValue(ctx, t, c, &secretsmanager.GetSecretValueInput{SecretId: aws.String("name")})
+			assert.Equal(t, "new_value", utility.FromStringPtr(getOut.SecretString))
+		},
+		"ListSecretsFiltersAndPaginatesByName": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			for _, name := range []string{"prefix/c", "prefix/a", "other", "prefix/b"} {
+				createSecret(ctx, t, c, name, "value")
+			}
+
+			in := &secretsmanager.ListSecretsInput{
+				Filters: []*secretsmanager.Filter{
+					{Key: aws.String("name"), Values: []*string{aws.String("prefix/")}},
+				},
+				MaxResults: aws.Int64(2),
+			}
+			var names []string
+			for {
+				out, err := c.ListSecrets(ctx, in)
+				require.NoError(t, err)
+				for _, entry := range out.SecretList {
+					names = append(names, utility.FromStringPtr(entry.Name))
+				}
+				if out.NextToken == nil {
+					break
+				}
+				in.NextToken = out.NextToken
+			}
+			assert.Equal(t, []string{"prefix/a", "prefix/b", "prefix/c"}, names)
+		},
+		"ListSecretsSupportsNegatedFilters": func(ctx context.Context, t *testing.T, c *SecretsManager, clock *VirtualClock) {
+			createSecret(ctx, t, c, "prefix/a", "value")
+			createSecret(ctx, t, c, "other", "value")
+
+			out, err := c.ListSecrets(ctx, &secretsmanager.ListSecretsInput{
+				Filters: []*secretsmanager.Filter{
+					{Key: aws.String("name"), Values: []*string{aws.String("!prefix/")}},
+				},
+			})
+			require.NoError(t, err)
+			require.Len(t, out.SecretList, 1)
+			assert.Equal(t, "other", utility.FromStringPtr(out.SecretList[0].Name))
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			clock := NewVirtualClock(time.Now())
+			c := NewSecretsManager()
+			c.Clock = clock
+
+			tCase(tctx, t, c, clock)
+		})
+	}
+}
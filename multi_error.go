@@ -0,0 +1,99 @@
+package cocoa
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MultiError is an error that aggregates the errors from an operation on
+// multiple resources, such as a batch helper that stops many tasks. Unlike an
+// error that only combines the messages of its errors, each of its errors can
+// still be checked with errors.Is and errors.As. It is not safe for concurrent
+// use.
+type MultiError struct {
+	errs []error
+}
+
+// NewMultiError returns a new MultiError containing all the non-nil errors.
+func NewMultiError(errs ...error) MultiError {
+	var m MultiError
+	for _, err := range errs {
+		m.Add(err)
+	}
+	return m
+}
+
+// Add adds the error if it is non-nil.
+func (m *MultiError) Add(err error) {
+	if err != nil {
+		m.errs = append(m.errs, err)
+	}
+}
+
+// Wrapf adds the error annotated with the formatted message if the error is
+// non-nil.
+func (m *MultiError) Wrapf(err error, format string, args ...interface{}) {
+	m.Add(errors.Wrapf(err, format, args...))
+}
+
+// Errors returns all the errors.
+func (m MultiError) Errors() []error {
+	return append([]error{}, m.errs...)
+}
+
+// HasErrors returns whether or not there are any errors.
+func (m MultiError) HasErrors() bool {
+	return len(m.errs) != 0
+}
+
+// Filter returns a new MultiError containing only the errors that match the
+// predicate.
+func (m MultiError) Filter(predicate func(error) bool) MultiError {
+	var filtered MultiError
+	for _, err := range m.errs {
+		if predicate(err) {
+			filtered.Add(err)
+		}
+	}
+	return filtered
+}
+
+// Is returns whether or not any of the errors matches the target.
+func (m MultiError) Is(target error) bool {
+	for _, err := range m.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches the target and, if there is
+// one, sets the target to that error value and returns true.
+func (m MultiError) As(target interface{}) bool {
+	for _, err := range m.errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Error returns the messages of all the errors, each on a separate line.
+func (m MultiError) Error() string {
+	msgs := make([]string, 0, len(m.errs))
+	for _, err := range m.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Resolve returns the MultiError as an error if there are any errors.
+// Otherwise, it returns nil.
+func (m MultiError) Resolve() error {
+	if !m.HasErrors() {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,69 @@
+package cocoa
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiError(t *testing.T) {
+	assert.Implements(t, (*error)(nil), MultiError{})
+
+	t.Run("NewMultiErrorIgnoresNilErrors", func(t *testing.T) {
+		m := NewMultiError(nil, errors.New("error"), nil)
+		assert.True(t, m.HasErrors())
+		assert.Len(t, m.Errors(), 1)
+	})
+	t.Run("EmptyResolvesToNil", func(t *testing.T) {
+		m := NewMultiError()
+		assert.False(t, m.HasErrors())
+		assert.Empty(t, m.Errors())
+		assert.NoError(t, m.Resolve())
+	})
+	t.Run("WrapfAnnotatesErrors", func(t *testing.T) {
+		var m MultiError
+		m.Wrapf(nil, "ignored")
+		m.Wrapf(errors.New("error"), "resource '%s'", "arn")
+		require.Len(t, m.Errors(), 1)
+		assert.Equal(t, "resource 'arn': error", m.Errors()[0].Error())
+	})
+	t.Run("ErrorIncludesAllMessages", func(t *testing.T) {
+		m := NewMultiError(errors.New("first"), errors.New("second"))
+		assert.Equal(t, "first\nsecond", m.Error())
+	})
+	t.Run("IsMatchesAnyError", func(t *testing.T) {
+		err := NewMultiError(errors.New("error"), errors.Wrap(NewECSTaskNotFoundError("arn", nil), "wrapped")).Resolve()
+		assert.True(t, errors.Is(err, ErrECSTaskNotFound))
+		assert.True(t, IsECSTaskNotFoundError(err))
+		assert.True(t, IsECSTaskNotFoundError(fmt.Errorf("wrapped: %w", err)))
+		assert.False(t, errors.Is(err, ErrSecretNotFound))
+	})
+	t.Run("AsFindsFirstMatchingError", func(t *testing.T) {
+		err := NewMultiError(errors.New("error"), NewECSTaskNotFoundError("first", nil), NewECSTaskNotFoundError("second", nil)).Resolve()
+		var typedErr *ECSTaskNotFoundError
+		require.True(t, errors.As(err, &typedErr))
+		assert.Equal(t, "first", typedErr.ARN)
+
+		var merr MultiError
+		require.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &merr))
+		assert.Len(t, merr.Errors(), 3)
+	})
+	t.Run("FilterReturnsMatchingErrors", func(t *testing.T) {
+		m := NewMultiError(errors.New("error"), NewECSTaskNotFoundError("arn", nil), NewSecretNotFoundError("id", nil))
+		filtered := m.Filter(IsECSTaskNotFoundError)
+		require.Len(t, filtered.Errors(), 1)
+		assert.True(t, IsECSTaskNotFoundError(filtered.Errors()[0]))
+		assert.Len(t, m.Errors(), 3)
+
+		assert.False(t, m.Filter(IsECSClusterNotFoundError).HasErrors())
+	})
+	t.Run("ErrorsReturnsCopy", func(t *testing.T) {
+		m := NewMultiError(errors.New("error"))
+		errs := m.Errors()
+		errs[0] = nil
+		assert.Error(t, m.Errors()[0])
+	})
+}
@@ -0,0 +1,61 @@
+package secret
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/pkg/errors"
+)
+
+// SecretARN represents the parts of a Secrets Manager secret ARN.
+type SecretARN struct {
+	// Region is the region that the secret is in.
+	Region string
+	// AccountID is the ID of the AWS account that owns the secret.
+	AccountID string
+	// SecretName is the name of the secret.
+	SecretName string
+	// RandomSuffix is the random suffix that Secrets Manager appends to the
+	// secret name in the ARN.
+	RandomSuffix string
+}
+
+// randomSuffixRegexp matches the random suffix that Secrets Manager appends to
+// the secret name in the ARN.
+var randomSuffixRegexp = regexp.MustCompile(`^[a-zA-Z0-9]{6}$`)
+
+// ParseSecretARN parses a Secrets Manager secret ARN, which has the format
+// arn:aws:secretsmanager:<region>:<account ID>:secret:<name>-<random suffix>.
+// Docs: https://docs.aws.amazon.com/secretsmanager/latest/userguide/reference_iam-permissions.html#iam-resources
+func ParseSecretARN(secretARN string) (*SecretARN, error) {
+	parsed, err := arn.Parse(secretARN)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing ARN")
+	}
+	if parsed.Service != "secretsmanager" {
+		return nil, errors.Errorf("ARN service is '%s', but should be 'secretsmanager'", parsed.Service)
+	}
+
+	const resourcePrefix = "secret:"
+	if !strings.HasPrefix(parsed.Resource, resourcePrefix) {
+		return nil, errors.Errorf("ARN resource '%s' should start with '%s'", parsed.Resource, resourcePrefix)
+	}
+	nameWithSuffix := strings.TrimPrefix(parsed.Resource, resourcePrefix)
+
+	suffixIdx := strings.LastIndex(nameWithSuffix, "-")
+	if suffixIdx <= 0 {
+		return nil, errors.Errorf("ARN resource '%s' is missing the secret name or random suffix", parsed.Resource)
+	}
+	suffix := nameWithSuffix[suffixIdx+1:]
+	if !randomSuffixRegexp.MatchString(suffix) {
+		return nil, errors.Errorf("ARN random suffix '%s' should be 6 alphanumeric characters", suffix)
+	}
+
+	return &SecretARN{
+		Region:       parsed.Region,
+		AccountID:    parsed.AccountID,
+		SecretName:   nameWithSuffix[:suffixIdx],
+		RandomSuffix: suffix,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSecretARN(t *testing.T) {
+	t.Run("ParsesARN", func(t *testing.T) {
+		secretARN, err := ParseSecretARN("arn:aws:secretsmanager:us-east-1:123456789012:secret:cocoa/secret-AbCdEf")
+		require.NoError(t, err)
+		require.NotZero(t, secretARN)
+		assert.Equal(t, "us-east-1", secretARN.Region)
+		assert.Equal(t, "123456789012", secretARN.AccountID)
+		assert.Equal(t, "cocoa/secret", secretARN.SecretName)
+		assert.Equal(t, "AbCdEf", secretARN.RandomSuffix)
+	})
+	t.Run("ParsesSecretNameContainingHyphens", func(t *testing.T) {
+		secretARN, err := ParseSecretARN("arn:aws:secretsmanager:us-east-1:123456789012:secret:my-secret-name-AbCdEf")
+		require.NoError(t, err)
+		require.NotZero(t, secretARN)
+		assert.Equal(t, "my-secret-name", secretARN.SecretName)
+		assert.Equal(t, "AbCdEf", secretARN.RandomSuffix)
+	})
+	t.Run("FailsWithSecretName", func(t *testing.T) {
+		secretARN, err := ParseSecretARN("cocoa/secret")
+		assert.Error(t, err)
+		assert.Zero(t, secretARN)
+	})
+	t.Run("FailsWithNonSecretsManagerARN", func(t *testing.T) {
+		secretARN, err := ParseSecretARN("arn:aws:ssm:us-east-1:123456789012:parameter/name-AbCdEf")
+		assert.Error(t, err)
+		assert.Zero(t, secretARN)
+	})
+	t.Run("FailsWithNonSecretResource", func(t *testing.T) {
+		secretARN, err := ParseSecretARN("arn:aws:secretsmanager:us-east-1:123456789012:other:name-AbCdEf")
+		assert.Error(t, err)
+		assert.Zero(t, secretARN)
+	})
+	t.Run("FailsWithoutRandomSuffix", func(t *testing.T) {
+		secretARN, err := ParseSecretARN("arn:aws:secretsmanager:us-east-1:123456789012:secret:name")
+		assert.Error(t, err)
+		assert.Zero(t, secretARN)
+	})
+	t.Run("FailsWithInvalidRandomSuffix", func(t *testing.T) {
+		secretARN, err := ParseSecretARN("arn:aws:secretsmanager:us-east-1:123456789012:secret:name-AbC")
+		assert.Error(t, err)
+		assert.Zero(t, secretARN)
+	})
+	t.Run("FailsWithoutSecretName", func(t *testing.T) {
+		secretARN, err := ParseSecretARN("arn:aws:secretsmanager:us-east-1:123456789012:secret:-AbCdEf")
+		assert.Error(t, err)
+		assert.Zero(t, secretARN)
+	})
+}
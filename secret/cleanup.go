@@ -0,0 +1,100 @@
+package secret
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+)
+
+// DeleteExpiredSecrets deletes all secrets tagged with the given tag key and
+// value that were created more than maxAge ago and returns the ARNs of the
+// deleted secrets. This is intended to clean up secrets for short-lived
+// environments that were not cleaned up, so the secrets are deleted
+// immediately without a recovery window. If any secret cannot be checked or
+// deleted, this returns the ARNs of the secrets that were successfully deleted
+// along with a cocoa.MultiError containing the errors.
+func DeleteExpiredSecrets(ctx context.Context, c cocoa.SecretsManagerClient, tagKey, tagValue string, maxAge time.Duration) ([]string, error) {
+	if c == nil {
+		return nil, errors.New("missing client")
+	}
+	if tagKey == "" {
+		return nil, errors.New("must specify a tag key")
+	}
+	if maxAge <= 0 {
+		return nil, errors.New("max age must be positive")
+	}
+
+	ids, err := listSecretsWithTag(ctx, c, tagKey, tagValue)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing secrets with tag")
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	var errs cocoa.MultiError
+	var deleted []string
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			errs.Wrapf(ctx.Err(), "deleting expired secrets")
+			break
+		}
+
+		desc, err := c.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{
+			SecretId: aws.String(id),
+		})
+		if err != nil {
+			errs.Wrapf(err, "describing secret '%s'", id)
+			continue
+		}
+		// Secrets that are already scheduled for deletion are left as-is.
+		if !utility.FromTimePtr(desc.DeletedDate).IsZero() {
+			continue
+		}
+		created := utility.FromTimePtr(desc.CreatedDate)
+		if created.IsZero() || !created.Before(cutoff) {
+			continue
+		}
+
+		arn := utility.FromStringPtr(desc.ARN)
+		if _, err := c.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{
+			SecretId:                   aws.String(arn),
+			ForceDeleteWithoutRecovery: aws.Bool(true),
+		}); err != nil {
+			errs.Wrapf(err, "deleting secret '%s'", arn)
+			continue
+		}
+		deleted = append(deleted, arn)
+	}
+
+	return deleted, errs.Resolve()
+}
+
+// listSecretsWithTag lists the ARNs of all secrets that have the tag.
+func listSecretsWithTag(ctx context.Context, c cocoa.SecretsManagerClient, tagKey, tagValue string) ([]string, error) {
+	tags := map[string]string{tagKey: tagValue}
+	in := &secretsmanager.ListSecretsInput{
+		Filters:    awsutil.BuildTagFilters(tags),
+		MaxResults: aws.Int64(maxListSecretsResults),
+	}
+
+	var arns []string
+	for {
+		out, err := c.ListSecrets(ctx, in)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing secrets")
+		}
+		for _, s := range awsutil.FilterSecretsByTags(out.SecretList, tags) {
+			arns = append(arns, utility.FromStringPtr(s.ARN))
+		}
+
+		if out.NextToken == nil {
+			return arns, nil
+		}
+		in.NextToken = out.NextToken
+	}
+}
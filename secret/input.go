@@ -0,0 +1,63 @@
+package secret
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// maxSecretNameLength is the maximum number of characters allowed in a secret
+// name.
+const maxSecretNameLength = 512
+
+// ValidateCreateSecretInput checks that the input to create a secret has a
+// name within the length limit, has a secret value, does not have duplicate tag
+// keys, and has a valid KMS key ARN if the KMS key is specified by ARN. KMS
+// keys may also be specified by key ID or alias, which are not validated.
+func ValidateCreateSecretInput(in *secretsmanager.CreateSecretInput) error {
+	if in == nil {
+		return errors.New("cannot validate nil input")
+	}
+
+	catcher := grip.NewBasicCatcher()
+
+	name := utility.FromStringPtr(in.Name)
+	catcher.NewWhen(name == "", "must specify a name")
+	catcher.ErrorfWhen(len(name) > maxSecretNameLength, "name cannot exceed %d characters", maxSecretNameLength)
+	catcher.NewWhen(in.SecretString == nil && in.SecretBinary == nil, "must specify either a secret string or secret binary")
+
+	keys := map[string]bool{}
+	for _, tag := range in.Tags {
+		if tag == nil {
+			continue
+		}
+		key := utility.FromStringPtr(tag.Key)
+		catcher.ErrorfWhen(keys[key], "tag key '%s' cannot be used by multiple tags", key)
+		keys[key] = true
+	}
+
+	if kmsKeyID := utility.FromStringPtr(in.KmsKeyId); strings.HasPrefix(kmsKeyID, "arn:") {
+		catcher.Wrapf(validateKMSKeyARN(kmsKeyID), "invalid KMS key ARN '%s'", kmsKeyID)
+	}
+
+	return catcher.Resolve()
+}
+
+// validateKMSKeyARN checks that the ARN refers to a KMS key or alias.
+func validateKMSKeyARN(kmsKeyARN string) error {
+	parsed, err := arn.Parse(kmsKeyARN)
+	if err != nil {
+		return errors.Wrap(err, "parsing ARN")
+	}
+	if parsed.Service != "kms" {
+		return errors.Errorf("ARN service is '%s', but should be 'kms'", parsed.Service)
+	}
+	if !strings.HasPrefix(parsed.Resource, "key/") && !strings.HasPrefix(parsed.Resource, "alias/") {
+		return errors.Errorf("ARN resource '%s' should be a key or alias", parsed.Resource)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package secret
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCreateSecretInput(t *testing.T) {
+	validInput := func() *secretsmanager.CreateSecretInput {
+		return &secretsmanager.CreateSecretInput{
+			Name:         aws.String("name"),
+			SecretString: aws.String("value"),
+			KmsKeyId:     aws.String("arn:aws:kms:us-east-1:123456789012:key/0123abcd-0123-abcd-0123-0123456789ab"),
+			Tags: []*secretsmanager.Tag{
+				{Key: aws.String("key0"), Value: aws.String("value0")},
+				{Key: aws.String("key1"), Value: aws.String("value1")},
+			},
+		}
+	}
+
+	t.Run("SucceedsWithValidInput", func(t *testing.T) {
+		assert.NoError(t, ValidateCreateSecretInput(validInput()))
+	})
+	t.Run("SucceedsWithSecretBinary", func(t *testing.T) {
+		in := validInput()
+		in.SecretString = nil
+		in.SecretBinary = []byte("value")
+		assert.NoError(t, ValidateCreateSecretInput(in))
+	})
+	t.Run("SucceedsWithKMSKeyAliasARN", func(t *testing.T) {
+		in := validInput()
+		in.KmsKeyId = aws.String("arn:aws:kms:us-east-1:123456789012:alias/alias")
+		assert.NoError(t, ValidateCreateSecretInput(in))
+	})
+	t.Run("SucceedsWithKMSKeyID", func(t *testing.T) {
+		in := validInput()
+		in.KmsKeyId = aws.String("0123abcd-0123-abcd-0123-0123456789ab")
+		assert.NoError(t, ValidateCreateSecretInput(in))
+	})
+	t.Run("SucceedsWithMaxLengthName", func(t *testing.T) {
+		in := validInput()
+		in.Name = aws.String(strings.Repeat("a", maxSecretNameLength))
+		assert.NoError(t, ValidateCreateSecretInput(in))
+	})
+	t.Run("FailsWithNilInput", func(t *testing.T) {
+		assert.Error(t, ValidateCreateSecretInput(nil))
+	})
+	t.Run("FailsWithoutName", func(t *testing.T) {
+		in := validInput()
+		in.Name = nil
+		assert.Error(t, ValidateCreateSecretInput(in))
+	})
+	t.Run("FailsWithNameOverLimit", func(t *testing.T) {
+		in := validInput()
+		in.Name = aws.String(strings.Repeat("a", maxSecretNameLength+1))
+		assert.Error(t, ValidateCreateSecretInput(in))
+	})
+	t.Run("FailsWithoutSecretValue", func(t *testing.T) {
+		in := validInput()
+		in.SecretString = nil
+		assert.Error(t, ValidateCreateSecretInput(in))
+	})
+	t.Run("FailsWithDuplicateTagKeys", func(t *testing.T) {
+		in := validInput()
+		in.Tags = append(in.Tags, &secretsmanager.Tag{Key: aws.String("key0"), Value: aws.String("value2")})
+		err := ValidateCreateSecretInput(in)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "key0")
+	})
+	t.Run("FailsWithNonKMSARN", func(t *testing.T) {
+		in := validInput()
+		in.KmsKeyId = aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:name-AbCdEf")
+		assert.Error(t, ValidateCreateSecretInput(in))
+	})
+	t.Run("FailsWithMalformedKMSKeyARN", func(t *testing.T) {
+		in := validInput()
+		in.KmsKeyId = aws.String("arn:aws:kms")
+		assert.Error(t, ValidateCreateSecretInput(in))
+	})
+	t.Run("FailsWithKMSARNThatIsNotAKeyOrAlias", func(t *testing.T) {
+		in := validInput()
+		in.KmsKeyId = aws.String("arn:aws:kms:us-east-1:123456789012:grant/grant")
+		assert.Error(t, ValidateCreateSecretInput(in))
+	})
+}
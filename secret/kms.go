@@ -0,0 +1,117 @@
+package secret
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+)
+
+// ResolveKMSKeyARN returns the ARN of the KMS key identified by the alias or
+// ARN. A key ARN is returned as-is. Otherwise, the identifier is treated as an
+// alias (either an alias name such as "alias/my-key" or an alias ARN) or a key
+// ID, which is resolved using DescribeKey. The resolved key ARN is not cached,
+// so callers that resolve the same alias repeatedly should use a
+// KMSKeyResolver instead.
+func ResolveKMSKeyARN(ctx context.Context, kmsc cocoa.KMSClient, aliasOrARN string) (string, error) {
+	r, err := NewKMSKeyResolver(kmsc)
+	if err != nil {
+		return "", errors.Wrap(err, "creating KMS key resolver")
+	}
+	return r.Resolve(ctx, aliasOrARN)
+}
+
+// KMSKeyResolver resolves KMS key aliases to key ARNs and caches the resolved
+// key ARNs for the lifetime of the resolver. An alias only refers to a key
+// within a single region and account, so each resolver should only be used
+// with the KMS client for one region and account. It is safe for concurrent
+// use.
+type KMSKeyResolver struct {
+	client cocoa.KMSClient
+
+	mu   sync.RWMutex
+	arns map[string]string
+}
+
+// NewKMSKeyResolver returns a new resolver that resolves aliases using the
+// given client.
+func NewKMSKeyResolver(c cocoa.KMSClient) (*KMSKeyResolver, error) {
+	if c == nil {
+		return nil, errors.New("missing KMS client")
+	}
+	return &KMSKeyResolver{
+		client: c,
+		arns:   map[string]string{},
+	}, nil
+}
+
+// Resolve returns the ARN of the KMS key identified by the alias or ARN. A key
+// ARN is returned as-is. Otherwise, the identifier is treated as an alias
+// (either an alias name such as "alias/my-key" or an alias ARN) or a key ID,
+// which is resolved using DescribeKey. Resolved key ARNs are cached, so each
+// alias is only described once unless it is invalidated.
+func (r *KMSKeyResolver) Resolve(ctx context.Context, aliasOrARN string) (string, error) {
+	if aliasOrARN == "" {
+		return "", errors.New("must specify a KMS key alias or ARN")
+	}
+
+	if arn.IsARN(aliasOrARN) {
+		parsed, err := arn.Parse(aliasOrARN)
+		if err != nil {
+			return "", errors.Wrap(err, "parsing ARN")
+		}
+		if parsed.Service != "kms" {
+			return "", errors.Errorf("ARN service is '%s', but should be 'kms'", parsed.Service)
+		}
+		if strings.HasPrefix(parsed.Resource, "key/") {
+			return aliasOrARN, nil
+		}
+	}
+
+	r.mu.RLock()
+	keyARN, ok := r.arns[aliasOrARN]
+	r.mu.RUnlock()
+	if ok {
+		return keyARN, nil
+	}
+
+	out, err := r.client.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String(aliasOrARN)})
+	if err != nil {
+		return "", errors.Wrapf(err, "describing KMS key '%s'", aliasOrARN)
+	}
+	if out == nil || out.KeyMetadata == nil {
+		return "", errors.Errorf("KMS key '%s' is missing key metadata", aliasOrARN)
+	}
+	keyARN = utility.FromStringPtr(out.KeyMetadata.Arn)
+	if keyARN == "" {
+		return "", errors.Errorf("KMS key '%s' is missing an ARN", aliasOrARN)
+	}
+
+	r.mu.Lock()
+	r.arns[aliasOrARN] = keyARN
+	r.mu.Unlock()
+
+	return keyARN, nil
+}
+
+// Invalidate removes the cached key ARN for the alias, so that the next call
+// to Resolve describes the key again. This should be called after the alias is
+// changed to refer to a different key (e.g. with UpdateAlias).
+func (r *KMSKeyResolver) Invalidate(alias string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.arns, alias)
+}
+
+// InvalidateAll removes all the cached key ARNs.
+func (r *KMSKeyResolver) InvalidateAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.arns = map[string]string{}
+}
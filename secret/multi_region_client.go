@@ -0,0 +1,115 @@
+package secret
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// RegionalSecretsManagerClient is a Secrets Manager client for a particular
+// region.
+type RegionalSecretsManagerClient struct {
+	// Region is the region that the client makes requests to.
+	Region string
+	// Client is the client for the region.
+	Client cocoa.SecretsManagerClient
+}
+
+// MultiRegionSecretsManagerClient reads secrets that are replicated across
+// multiple regions, falling back to other regions when a region is
+// unavailable. Reads of secret values try each region in priority order. All
+// other methods are passed through to the client for the highest priority
+// region.
+type MultiRegionSecretsManagerClient struct {
+	cocoa.SecretsManagerClient
+	clients []RegionalSecretsManagerClient
+}
+
+// NewMultiRegionSecretsManagerClient returns a new client that reads secrets
+// from the given regional clients. The clients are in priority order, so the
+// first client is the primary region.
+func NewMultiRegionSecretsManagerClient(clients []RegionalSecretsManagerClient) (*MultiRegionSecretsManagerClient, error) {
+	if len(clients) == 0 {
+		return nil, errors.New("must specify at least one regional client")
+	}
+
+	catcher := grip.NewBasicCatcher()
+	seen := map[string]bool{}
+	for i, c := range clients {
+		catcher.ErrorfWhen(c.Region == "", "client %d is missing a region", i)
+		catcher.ErrorfWhen(c.Client == nil, "client %d is missing a client", i)
+		catcher.ErrorfWhen(c.Region != "" && seen[c.Region], "region '%s' is specified more than once", c.Region)
+		seen[c.Region] = true
+	}
+	if catcher.HasErrors() {
+		return nil, catcher.Resolve()
+	}
+
+	return &MultiRegionSecretsManagerClient{
+		SecretsManagerClient: clients[0].Client,
+		clients:              append([]RegionalSecretsManagerClient{}, clients...),
+	}, nil
+}
+
+// GetSecretValue gets the decrypted value of a secret from the first region
+// that returns it successfully. If a region fails for any reason other than
+// the secret not existing, it tries the next region. If the secret does not
+// exist, it returns the error immediately, since the secret is replicated to
+// the other regions from the primary region. If the secret is identified by
+// its ARN, the ARN's region is replaced with each region that is tried, since
+// a replica secret has the same ARN as the primary secret apart from the
+// region.
+func (c *MultiRegionSecretsManagerClient) GetSecretValue(ctx context.Context, in *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	var errs cocoa.MultiError
+	for _, rc := range c.clients {
+		if err := ctx.Err(); err != nil {
+			errs.Add(err)
+			break
+		}
+
+		regionalIn := *in
+		if in.SecretId != nil {
+			regionalIn.SecretId = utility.ToStringPtr(secretIDInRegion(*in.SecretId, rc.Region))
+		}
+
+		out, err := rc.Client.GetSecretValue(ctx, &regionalIn)
+		if err == nil {
+			return out, nil
+		}
+		if cocoa.IsSecretNotFoundError(err) {
+			return nil, errors.Wrapf(err, "getting secret value in region '%s'", rc.Region)
+		}
+		errs.Wrapf(err, "getting secret value in region '%s'", rc.Region)
+	}
+
+	return nil, errors.Wrap(errs.Resolve(), "getting secret value in all regions")
+}
+
+// secretIDInRegion returns the secret ID that refers to the replica of the
+// secret in the given region. A secret ARN is rewritten to the given region;
+// any other ID (e.g. a secret name) is the same in all regions.
+func secretIDInRegion(id, region string) string {
+	if !arn.IsARN(id) {
+		return id
+	}
+	parsed, err := arn.Parse(id)
+	if err != nil || parsed.Service != "secretsmanager" {
+		return id
+	}
+	parsed.Region = region
+	return parsed.String()
+}
+
+// Close closes the clients for all the regions.
+func (c *MultiRegionSecretsManagerClient) Close(ctx context.Context) error {
+	var errs cocoa.MultiError
+	for _, rc := range c.clients {
+		errs.Wrapf(rc.Client.Close(ctx), "closing client for region '%s'", rc.Region)
+	}
+	return errs.Resolve()
+}
@@ -0,0 +1,71 @@
+package secret
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/pkg/errors"
+)
+
+// DefaultSecretQuota is the default maximum number of secrets allowed per
+// region in an AWS account.
+// Docs: https://docs.aws.amazon.com/secretsmanager/latest/userguide/reference_limits.html
+const DefaultSecretQuota = 500000
+
+// maxListSecretsResults is the maximum number of secrets that can be returned
+// in a single ListSecrets request.
+const maxListSecretsResults = 100
+
+// SecretQuotaInfo contains information about how much of the secret quota is
+// in use.
+type SecretQuotaInfo struct {
+	// Current is the number of existing secrets.
+	Current int
+	// Limit is the maximum number of secrets allowed.
+	Limit int
+	// Available is the number of secrets that can still be created before
+	// reaching the limit.
+	Available int
+}
+
+// CheckSecretQuota counts the existing secrets and checks how many more
+// secrets can be created under the default quota. This can be used to fail
+// early before attempting to create secrets.
+func CheckSecretQuota(ctx context.Context, c cocoa.SecretsManagerClient) (*SecretQuotaInfo, error) {
+	return CheckSecretQuotaWithLimit(ctx, c, DefaultSecretQuota)
+}
+
+// CheckSecretQuotaWithLimit is the same as CheckSecretQuota but checks against
+// the given limit, such as if the account's quota has been adjusted.
+func CheckSecretQuotaWithLimit(ctx context.Context, c cocoa.SecretsManagerClient, limit int) (*SecretQuotaInfo, error) {
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	in := &secretsmanager.ListSecretsInput{MaxResults: aws.Int64(maxListSecretsResults)}
+	var count int
+	for {
+		out, err := c.ListSecrets(ctx, in)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing secrets")
+		}
+		count += len(out.SecretList)
+		if out.NextToken == nil {
+			break
+		}
+		in.NextToken = out.NextToken
+	}
+
+	available := limit - count
+	if available < 0 {
+		available = 0
+	}
+
+	return &SecretQuotaInfo{
+		Current:   count,
+		Limit:     limit,
+		Available: available,
+	}, nil
+}
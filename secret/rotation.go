@@ -0,0 +1,287 @@
+package secret
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultRotationPollInterval is the default interval between checks of a
+	// secret's rotation status.
+	defaultRotationPollInterval = time.Minute
+	// rotationDryRunPollInterval is the interval between checks of whether a
+	// dry-run rotation has finished.
+	rotationDryRunPollInterval = 5 * time.Second
+	// rotationDryRunTimeout is the default maximum amount of time to wait for
+	// a dry-run rotation to finish.
+	rotationDryRunTimeout = 30 * time.Minute
+	// rotationStallTimeout is the maximum amount of time that a rotation can
+	// go without making progress before it is considered to have failed. Each
+	// step of a rotation is a single invocation of the rotation function,
+	// which cannot run for longer than 15 minutes.
+	rotationStallTimeout = 15 * time.Minute
+	// rotationCancelTimeout is the maximum amount of time to spend cancelling
+	// a dry-run rotation.
+	rotationCancelTimeout = time.Minute
+	// versionStageCurrent is the staging label for the current version of a
+	// secret.
+	versionStageCurrent = "AWSCURRENT"
+	// versionStagePending is the staging label for the version of a secret
+	// that is being rotated in.
+	versionStagePending = "AWSPENDING"
+)
+
+// RotationStatusEventType describes the kind of change in a secret's rotation
+// status.
+type RotationStatusEventType string
+
+const (
+	// RotationStatusInitial reports the secret's rotation status when
+	// monitoring starts.
+	RotationStatusInitial RotationStatusEventType = "initial"
+	// RotationStatusEnabledChanged indicates that rotation was enabled or
+	// disabled.
+	RotationStatusEnabledChanged RotationStatusEventType = "enabled-changed"
+	// RotationStatusRotated indicates that the secret was rotated.
+	RotationStatusRotated RotationStatusEventType = "rotated"
+	// RotationStatusPending indicates that a new version of the secret is
+	// waiting to be rotated in. Secrets Manager leaves the pending version in
+	// place if rotation fails, so a pending version that is not followed by a
+	// RotationStatusRotated event indicates a rotation failure.
+	RotationStatusPending RotationStatusEventType = "pending"
+)
+
+// RotationStatusEvent represents a change in a secret's rotation status.
+type RotationStatusEvent struct {
+	// Type is the kind of change.
+	Type RotationStatusEventType
+	// RotationEnabled is whether or not rotation is enabled for the secret.
+	RotationEnabled bool
+	// LastRotated is the last time the secret was rotated. This is zero if it
+	// has never been rotated.
+	LastRotated time.Time
+	// PendingVersionID is the ID of the version of the secret that is waiting
+	// to be rotated in, if any.
+	PendingVersionID string
+	// Timestamp is the time at which the change was observed.
+	Timestamp time.Time
+	// Err is set if the secret's rotation status could not be checked. If it
+	// is set, this is the last event sent.
+	Err error
+}
+
+// rotationStatus is a snapshot of a secret's rotation status.
+type rotationStatus struct {
+	enabled          bool
+	lastRotated      time.Time
+	lastChanged      time.Time
+	pendingVersionID string
+}
+
+// MonitorRotationStatus starts polling the secret's rotation status and
+// returns a channel that receives an event each time the rotation status
+// changes. The first event reports the secret's current rotation status. The
+// channel is closed once the context is done or the rotation status cannot be
+// checked. If the poll interval is not positive, it defaults to 1 minute.
+func MonitorRotationStatus(ctx context.Context, c cocoa.SecretsManagerClient, secretID string, pollInterval time.Duration) (<-chan RotationStatusEvent, error) {
+	if c == nil {
+		return nil, errors.New("missing client")
+	}
+	if pollInterval <= 0 {
+		pollInterval = defaultRotationPollInterval
+	}
+
+	status, err := getRotationStatus(ctx, c, secretID)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting initial rotation status")
+	}
+
+	events := make(chan RotationStatusEvent)
+	go func() {
+		defer close(events)
+
+		send := func(eventType RotationStatusEventType, s rotationStatus) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case events <- s.event(eventType):
+				return true
+			}
+		}
+
+		if !send(RotationStatusInitial, *status) {
+			return
+		}
+
+		timer := time.NewTimer(pollInterval)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				newStatus, err := getRotationStatus(ctx, c, secretID)
+				if err != nil {
+					select {
+					case <-ctx.Done():
+					case events <- RotationStatusEvent{
+						Timestamp: time.Now(),
+						Err:       errors.Wrap(err, "checking rotation status"),
+					}:
+					}
+					return
+				}
+
+				for _, eventType := range status.changes(*newStatus) {
+					if !send(eventType, *newStatus) {
+						return
+					}
+				}
+				status = newStatus
+
+				timer.Reset(pollInterval)
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// getRotationStatus gets the current rotation status of the secret.
+func getRotationStatus(ctx context.Context, c cocoa.SecretsManagerClient, secretID string) (*rotationStatus, error) {
+	out, err := c.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describing secret")
+	}
+
+	status := rotationStatus{
+		enabled:     utility.FromBoolPtr(out.RotationEnabled),
+		lastRotated: utility.FromTimePtr(out.LastRotatedDate),
+		lastChanged: utility.FromTimePtr(out.LastChangedDate),
+	}
+	for versionID, stages := range out.VersionIdsToStages {
+		labels := utility.FromStringPtrSlice(stages)
+		if utility.StringSliceContains(labels, versionStagePending) && !utility.StringSliceContains(labels, versionStageCurrent) {
+			status.pendingVersionID = versionID
+		}
+	}
+
+	return &status, nil
+}
+
+// changes returns the kinds of changes between the old and new rotation
+// status.
+func (s rotationStatus) changes(newStatus rotationStatus) []RotationStatusEventType {
+	var changes []RotationStatusEventType
+	if s.enabled != newStatus.enabled {
+		changes = append(changes, RotationStatusEnabledChanged)
+	}
+	if !s.lastRotated.Equal(newStatus.lastRotated) {
+		changes = append(changes, RotationStatusRotated)
+	}
+	if newStatus.pendingVersionID != "" && s.pendingVersionID != newStatus.pendingVersionID {
+		changes = append(changes, RotationStatusPending)
+	}
+	return changes
+}
+
+func (s rotationStatus) event(eventType RotationStatusEventType) RotationStatusEvent {
+	return RotationStatusEvent{
+		Type:             eventType,
+		RotationEnabled:  s.enabled,
+		LastRotated:      s.lastRotated,
+		PendingVersionID: s.pendingVersionID,
+		Timestamp:        time.Now(),
+	}
+}
+
+// RotateSecretDryRun checks that the secret's rotation function will succeed
+// without committing to a rotation. It starts a rotation that only tests the
+// rotation function (i.e. RotateImmediately is false) and polls until the
+// version being tested is no longer pending. Secrets Manager leaves the
+// pending version in place if the rotation function fails, so the rotation is
+// considered to have failed if the secret stops changing while the version is
+// still pending, or if the rotation does not finish within 30 minutes or
+// before the context is done.
+//
+// Regardless of the outcome, it cancels the rotation afterwards. Cancelling a
+// rotation permanently turns off the secret's existing automatic rotation
+// schedule, so callers must enable rotation again (e.g. with RotateSecret and
+// the original rotation rules) if the secret should still rotate
+// automatically. This returns nil only if the rotation succeeded.
+func RotateSecretDryRun(ctx context.Context, c cocoa.SecretsManagerClient, secretID string) error {
+	if c == nil {
+		return errors.New("missing client")
+	}
+	if secretID == "" {
+		return errors.New("must specify a secret ID")
+	}
+
+	out, err := c.RotateSecret(ctx, &secretsmanager.RotateSecretInput{
+		SecretId:          aws.String(secretID),
+		RotateImmediately: aws.Bool(false),
+	})
+	if err != nil {
+		return errors.Wrap(err, "starting rotation")
+	}
+
+	var errs cocoa.MultiError
+	waitCtx, waitCancel := context.WithTimeout(ctx, rotationDryRunTimeout)
+	defer waitCancel()
+	errs.Wrapf(waitForRotation(waitCtx, c, secretID, utility.FromStringPtr(out.VersionId), rotationDryRunPollInterval, rotationStallTimeout), "waiting for rotation to finish")
+
+	// The rotation must be cancelled even if the context is done.
+	cancelCtx, cancel := context.WithTimeout(context.Background(), rotationCancelTimeout)
+	defer cancel()
+	_, err = c.CancelRotateSecret(cancelCtx, &secretsmanager.CancelRotateSecretInput{
+		SecretId: aws.String(secretID),
+	})
+	errs.Wrapf(err, "cancelling rotation")
+
+	return errs.Resolve()
+}
+
+// waitForRotation polls the secret's rotation status until the version being
+// rotated is no longer pending. If the version ID is empty, it waits until no
+// version is pending. It fails if the pending version and the secret's last
+// changed time both stay the same for longer than the stall timeout, since
+// that means that the rotation function has stopped making progress.
+func waitForRotation(ctx context.Context, c cocoa.SecretsManagerClient, secretID, versionID string, pollInterval, stallTimeout time.Duration) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	var lastStatus *rotationStatus
+	var lastProgress time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "rotation did not finish")
+		case <-timer.C:
+			status, err := getRotationStatus(ctx, c, secretID)
+			if err != nil {
+				return errors.Wrap(err, "checking rotation status")
+			}
+			if status.pendingVersionID == "" || (versionID != "" && status.pendingVersionID != versionID) {
+				return nil
+			}
+
+			if lastStatus == nil || status.pendingVersionID != lastStatus.pendingVersionID || !status.lastChanged.Equal(lastStatus.lastChanged) {
+				lastStatus = status
+				lastProgress = time.Now()
+			} else if time.Since(lastProgress) > stallTimeout {
+				return errors.Errorf("pending version '%s' has not changed in over %s, so the rotation function likely failed", status.pendingVersionID, stallTimeout)
+			}
+
+			timer.Reset(pollInterval)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package secret
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// describeSecretClient is a cocoa.SecretsManagerClient that returns a
+// sequence of DescribeSecret outputs, repeating the last one once the
+// sequence is exhausted.
+type describeSecretClient struct {
+	cocoa.SecretsManagerClient
+	outputs []*secretsmanager.DescribeSecretOutput
+}
+
+func (c *describeSecretClient) DescribeSecret(ctx context.Context, in *secretsmanager.DescribeSecretInput) (*secretsmanager.DescribeSecretOutput, error) {
+	out := c.outputs[0]
+	if len(c.outputs) > 1 {
+		c.outputs = c.outputs[1:]
+	}
+	return out, nil
+}
+
+func TestWaitForRotation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	pending := func(lastChanged time.Time) *secretsmanager.DescribeSecretOutput {
+		return &secretsmanager.DescribeSecretOutput{
+			LastChangedDate: aws.Time(lastChanged),
+			VersionIdsToStages: map[string][]*string{
+				"v0": {aws.String(versionStageCurrent)},
+				"v1": {aws.String(versionStagePending)},
+			},
+		}
+	}
+	finished := &secretsmanager.DescribeSecretOutput{
+		VersionIdsToStages: map[string][]*string{
+			"v0": {aws.String(versionStageCurrent)},
+		},
+	}
+	start := time.Now()
+
+	t.Run("SucceedsWhenVersionIsNoLongerPending", func(t *testing.T) {
+		c := &describeSecretClient{outputs: []*secretsmanager.DescribeSecretOutput{pending(start), finished}}
+		assert.NoError(t, waitForRotation(ctx, c, "secret", "v1", time.Millisecond, time.Minute))
+	})
+	t.Run("FailsWhenPendingVersionStopsChanging", func(t *testing.T) {
+		c := &describeSecretClient{outputs: []*secretsmanager.DescribeSecretOutput{pending(start)}}
+		err := waitForRotation(ctx, c, "secret", "v1", time.Millisecond, 10*time.Millisecond)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "v1")
+		assert.NoError(t, ctx.Err(), "should fail before the context is done")
+	})
+	t.Run("SucceedsWhilePendingVersionKeepsChanging", func(t *testing.T) {
+		outputs := []*secretsmanager.DescribeSecretOutput{}
+		for i := 0; i < 50; i++ {
+			outputs = append(outputs, pending(start.Add(time.Duration(i)*time.Second)))
+		}
+		outputs = append(outputs, finished)
+		c := &describeSecretClient{outputs: outputs}
+		assert.NoError(t, waitForRotation(ctx, c, "secret", "v1", time.Millisecond, 5*time.Millisecond))
+	})
+	t.Run("FailsWhenContextIsDone", func(t *testing.T) {
+		tctx, tcancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer tcancel()
+
+		c := &describeSecretClient{outputs: []*secretsmanager.DescribeSecretOutput{pending(start)}}
+		assert.Error(t, waitForRotation(tctx, c, "secret", "v1", time.Millisecond, time.Minute))
+	})
+}
@@ -0,0 +1,261 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// SecretValidator checks that a secret value is valid.
+type SecretValidator func(value string) error
+
+// NewSchemaValidator returns a validator that checks that secret values are
+// JSON documents that conform to the JSON Schema. See ValidateSecretSchema for
+// the supported schema keywords.
+func NewSchemaValidator(schema []byte) (SecretValidator, error) {
+	s, err := parseSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	return func(value string) error {
+		return s.validateValue(value)
+	}, nil
+}
+
+// ValidateSecretSchema checks that the secret value is a JSON document that
+// conforms to the JSON Schema. This supports a lightweight subset of JSON
+// Schema that is sufficient for structured secrets such as credentials:
+//   - Any type: type (a single type or a list of types), enum and const.
+//   - Objects: properties, required, additionalProperties, minProperties and
+//     maxProperties.
+//   - Arrays: items, minItems and maxItems.
+//   - Strings: minLength, maxLength and pattern.
+//   - Numbers: minimum, maximum, exclusiveMinimum and exclusiveMaximum.
+//
+// Other keywords (e.g. $ref) are ignored. All violations are reported rather
+// than only the first one.
+func ValidateSecretSchema(value string, schema []byte) error {
+	s, err := parseSchema(schema)
+	if err != nil {
+		return err
+	}
+	return s.validateValue(value)
+}
+
+// jsonSchema is a parsed JSON Schema.
+type jsonSchema struct {
+	root interface{}
+}
+
+func parseSchema(schema []byte) (*jsonSchema, error) {
+	var root interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, errors.Wrap(err, "parsing schema")
+	}
+	switch root.(type) {
+	case map[string]interface{}, bool:
+	default:
+		return nil, errors.New("schema must be a JSON object or boolean")
+	}
+	return &jsonSchema{root: root}, nil
+}
+
+func (s *jsonSchema) validateValue(value string) error {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(value), &doc); err != nil {
+		return errors.Wrap(err, "secret value is not valid JSON")
+	}
+
+	catcher := grip.NewBasicCatcher()
+	validateSchemaNode(catcher, s.root, doc, "$")
+	return errors.Wrap(catcher.Resolve(), "secret value does not match schema")
+}
+
+// validateSchemaNode checks that the JSON value at the given path conforms to
+// the schema node and records any violations in the catcher.
+func validateSchemaNode(catcher grip.Catcher, schema interface{}, val interface{}, path string) {
+	switch sch := schema.(type) {
+	case bool:
+		catcher.ErrorfWhen(!sch, "%s: value is not allowed", path)
+		return
+	case map[string]interface{}:
+		validateSchemaObject(catcher, sch, val, path)
+	default:
+		catcher.Errorf("%s: invalid schema", path)
+	}
+}
+
+func validateSchemaObject(catcher grip.Catcher, sch map[string]interface{}, val interface{}, path string) {
+	if t, ok := sch["type"]; ok && !matchesSchemaType(t, val) {
+		catcher.Errorf("%s: expected type %s but got %s", path, formatSchemaType(t), jsonTypeName(val))
+		return
+	}
+	if enum, ok := sch["enum"].([]interface{}); ok {
+		var found bool
+		for _, e := range enum {
+			if reflect.DeepEqual(e, val) {
+				found = true
+				break
+			}
+		}
+		catcher.ErrorfWhen(!found, "%s: value is not one of the allowed values", path)
+	}
+	if c, ok := sch["const"]; ok {
+		catcher.ErrorfWhen(!reflect.DeepEqual(c, val), "%s: value does not match the required constant", path)
+	}
+
+	switch v := val.(type) {
+	case map[string]interface{}:
+		validateSchemaProperties(catcher, sch, v, path)
+	case []interface{}:
+		if n, ok := schemaNumber(sch, "minItems"); ok {
+			catcher.ErrorfWhen(float64(len(v)) < n, "%s: expected at least %v items", path, n)
+		}
+		if n, ok := schemaNumber(sch, "maxItems"); ok {
+			catcher.ErrorfWhen(float64(len(v)) > n, "%s: expected at most %v items", path, n)
+		}
+		if items, ok := sch["items"]; ok {
+			for i, item := range v {
+				validateSchemaNode(catcher, items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if n, ok := schemaNumber(sch, "minLength"); ok {
+			catcher.ErrorfWhen(length < n, "%s: expected at least %v characters", path, n)
+		}
+		if n, ok := schemaNumber(sch, "maxLength"); ok {
+			catcher.ErrorfWhen(length > n, "%s: expected at most %v characters", path, n)
+		}
+		if pattern, ok := sch["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				catcher.Wrapf(err, "%s: invalid pattern", path)
+			} else {
+				catcher.ErrorfWhen(!re.MatchString(v), "%s: value does not match pattern '%s'", path, pattern)
+			}
+		}
+	case float64:
+		if n, ok := schemaNumber(sch, "minimum"); ok {
+			catcher.ErrorfWhen(v < n, "%s: expected a value of at least %v", path, n)
+		}
+		if n, ok := schemaNumber(sch, "maximum"); ok {
+			catcher.ErrorfWhen(v > n, "%s: expected a value of at most %v", path, n)
+		}
+		if n, ok := schemaNumber(sch, "exclusiveMinimum"); ok {
+			catcher.ErrorfWhen(v <= n, "%s: expected a value greater than %v", path, n)
+		}
+		if n, ok := schemaNumber(sch, "exclusiveMaximum"); ok {
+			catcher.ErrorfWhen(v >= n, "%s: expected a value less than %v", path, n)
+		}
+	}
+}
+
+func validateSchemaProperties(catcher grip.Catcher, sch map[string]interface{}, obj map[string]interface{}, path string) {
+	if required, ok := sch["required"].([]interface{}); ok {
+		for _, r := range required {
+			name, ok := r.(string)
+			if !ok {
+				continue
+			}
+			_, ok = obj[name]
+			catcher.ErrorfWhen(!ok, "%s: missing required property '%s'", path, name)
+		}
+	}
+	if n, ok := schemaNumber(sch, "minProperties"); ok {
+		catcher.ErrorfWhen(float64(len(obj)) < n, "%s: expected at least %v properties", path, n)
+	}
+	if n, ok := schemaNumber(sch, "maxProperties"); ok {
+		catcher.ErrorfWhen(float64(len(obj)) > n, "%s: expected at most %v properties", path, n)
+	}
+
+	props, _ := sch["properties"].(map[string]interface{})
+	additional, hasAdditional := sch["additionalProperties"]
+
+	// Check the properties in a stable order so that errors are reported
+	// consistently.
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propPath := path + "." + name
+		if propSchema, ok := props[name]; ok {
+			validateSchemaNode(catcher, propSchema, obj[name], propPath)
+			continue
+		}
+		if hasAdditional {
+			if allowed, ok := additional.(bool); ok {
+				catcher.ErrorfWhen(!allowed, "%s: property is not allowed", propPath)
+				continue
+			}
+			validateSchemaNode(catcher, additional, obj[name], propPath)
+		}
+	}
+}
+
+// matchesSchemaType returns whether the JSON value matches the schema type,
+// which is either a single type name or a list of type names.
+func matchesSchemaType(t interface{}, val interface{}) bool {
+	switch types := t.(type) {
+	case string:
+		return matchesSchemaTypeName(types, val)
+	case []interface{}:
+		for _, name := range types {
+			if s, ok := name.(string); ok && matchesSchemaTypeName(s, val) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesSchemaTypeName(name string, val interface{}) bool {
+	if name == "integer" {
+		f, ok := val.(float64)
+		return ok && f == math.Trunc(f)
+	}
+	return name == jsonTypeName(val)
+}
+
+func formatSchemaType(t interface{}) string {
+	if s, ok := t.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(t)
+	return string(b)
+}
+
+// jsonTypeName returns the JSON Schema type name of the decoded JSON value.
+func jsonTypeName(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+func schemaNumber(sch map[string]interface{}, key string) (float64, bool) {
+	n, ok := sch[key].(float64)
+	return n, ok
+}
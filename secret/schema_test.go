@@ -0,0 +1,98 @@
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSecretSchema(t *testing.T) {
+	credentialsSchema := []byte(`{
+  "type": "object",
+  "required": ["username", "password", "port"],
+  "properties": {
+    "username": {"type": "string", "minLength": 1},
+    "password": {"type": "string", "minLength": 8},
+    "port": {"type": "integer", "minimum": 1, "maximum": 65535},
+    "engine": {"enum": ["mysql", "postgres"]},
+    "hosts": {"type": "array", "items": {"type": "string", "pattern": "^[a-z.]+$"}, "minItems": 1}
+  },
+  "additionalProperties": false
+}`)
+
+	t.Run("SucceedsWithMatchingValue", func(t *testing.T) {
+		assert.NoError(t, ValidateSecretSchema(`{"username": "admin", "password": "hunter2!", "port": 5432, "engine": "postgres", "hosts": ["db.example.com"]}`, credentialsSchema))
+	})
+	t.Run("FailsWithMissingRequiredProperty", func(t *testing.T) {
+		err := ValidateSecretSchema(`{"username": "admin", "port": 5432}`, credentialsSchema)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "password")
+	})
+	t.Run("FailsWithWrongType", func(t *testing.T) {
+		err := ValidateSecretSchema(`{"username": "admin", "password": "hunter2!", "port": "5432"}`, credentialsSchema)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "$.port")
+	})
+	t.Run("FailsWithNonIntegerNumber", func(t *testing.T) {
+		assert.Error(t, ValidateSecretSchema(`{"username": "admin", "password": "hunter2!", "port": 54.32}`, credentialsSchema))
+	})
+	t.Run("FailsWithNumberOutOfRange", func(t *testing.T) {
+		assert.Error(t, ValidateSecretSchema(`{"username": "admin", "password": "hunter2!", "port": 70000}`, credentialsSchema))
+	})
+	t.Run("FailsWithStringTooShort", func(t *testing.T) {
+		assert.Error(t, ValidateSecretSchema(`{"username": "admin", "password": "short", "port": 5432}`, credentialsSchema))
+	})
+	t.Run("FailsWithValueNotInEnum", func(t *testing.T) {
+		assert.Error(t, ValidateSecretSchema(`{"username": "admin", "password": "hunter2!", "port": 5432, "engine": "oracle"}`, credentialsSchema))
+	})
+	t.Run("FailsWithArrayItemNotMatchingPattern", func(t *testing.T) {
+		err := ValidateSecretSchema(`{"username": "admin", "password": "hunter2!", "port": 5432, "hosts": ["DB"]}`, credentialsSchema)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "$.hosts[0]")
+	})
+	t.Run("FailsWithAdditionalProperty", func(t *testing.T) {
+		err := ValidateSecretSchema(`{"username": "admin", "password": "hunter2!", "port": 5432, "extra": true}`, credentialsSchema)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "$.extra")
+	})
+	t.Run("ReportsAllViolations", func(t *testing.T) {
+		err := ValidateSecretSchema(`{"username": "", "password": "short", "port": 0}`, credentialsSchema)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "$.username")
+		assert.Contains(t, err.Error(), "$.password")
+		assert.Contains(t, err.Error(), "$.port")
+	})
+	t.Run("SucceedsWithMultipleAllowedTypes", func(t *testing.T) {
+		schema := []byte(`{"type": ["string", "null"]}`)
+		assert.NoError(t, ValidateSecretSchema(`"value"`, schema))
+		assert.NoError(t, ValidateSecretSchema(`null`, schema))
+		assert.Error(t, ValidateSecretSchema(`1`, schema))
+	})
+	t.Run("SucceedsWithEmptySchema", func(t *testing.T) {
+		assert.NoError(t, ValidateSecretSchema(`{"key": "value"}`, []byte(`{}`)))
+	})
+	t.Run("FailsWithNonJSONValue", func(t *testing.T) {
+		assert.Error(t, ValidateSecretSchema("plaintext", credentialsSchema))
+	})
+	t.Run("FailsWithInvalidSchema", func(t *testing.T) {
+		assert.Error(t, ValidateSecretSchema(`{}`, []byte("{")))
+		assert.Error(t, ValidateSecretSchema(`{}`, []byte(`"string"`)))
+	})
+}
+
+func TestNewSchemaValidator(t *testing.T) {
+	t.Run("ValidatesValues", func(t *testing.T) {
+		v, err := NewSchemaValidator([]byte(`{"type": "object", "required": ["key"]}`))
+		require.NoError(t, err)
+		require.NotZero(t, v)
+
+		assert.NoError(t, v(`{"key": "value"}`))
+		assert.Error(t, v(`{"other": "value"}`))
+	})
+	t.Run("FailsWithInvalidSchema", func(t *testing.T) {
+		v, err := NewSchemaValidator([]byte("not json"))
+		assert.Error(t, err)
+		assert.Zero(t, v)
+	})
+}
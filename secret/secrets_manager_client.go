@@ -3,13 +3,14 @@ package secret
 import (
 	"context"
 
-	"github.com/mongodb/grip"
 	"github.com/mongodb/grip/message"
 	"github.com/pkg/errors"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
 	"github.com/evergreen-ci/cocoa/awsutil"
 	"github.com/evergreen-ci/utility"
 )
@@ -19,7 +20,8 @@ import (
 // retrying requests using exponential backoff and jitter.
 type BasicSecretsManagerClient struct {
 	awsutil.BaseClient
-	sm *secretsmanager.SecretsManager
+	sm       *secretsmanager.SecretsManager
+	connPool awsutil.ConnectionPoolTracker
 }
 
 // NewBasicSecretsManagerClient creates a new AWS Secrets Manager client from
@@ -46,29 +48,41 @@ func (c *BasicSecretsManagerClient) setup() error {
 	}
 
 	c.sm = secretsmanager.New(sess)
+	c.connPool.Attach(&c.sm.Handlers)
 
 	return nil
 }
 
-// CreateSecret creates a new secret.
+// ConnectionPoolStats returns statistics about the HTTP connections used to
+// make requests to Secrets Manager. This can be used to tune the size of the
+// HTTP client's connection pool based on the observed request load.
+func (c *BasicSecretsManagerClient) ConnectionPoolStats() awsutil.ConnectionPoolStats {
+	return c.connPool.Stats(c.GetHTTPClient())
+}
+
+// CreateSecret validates the input and creates a new secret.
 func (c *BasicSecretsManagerClient) CreateSecret(ctx context.Context, in *secretsmanager.CreateSecretInput) (*secretsmanager.CreateSecretOutput, error) {
+	if err := ValidateCreateSecretInput(in); err != nil {
+		return nil, errors.Wrap(err, "invalid input")
+	}
 	if err := c.setup(); err != nil {
 		return nil, errors.Wrap(err, "setting up client")
 	}
 
 	var out *secretsmanager.CreateSecretOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("CreateSecret", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("CreateSecret", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.sm.CreateSecretWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("CreateSecret", stats)))
 		return nil, err
 	}
 	return out, nil
@@ -82,17 +96,21 @@ func (c *BasicSecretsManagerClient) GetSecretValue(ctx context.Context, in *secr
 
 	var out *secretsmanager.GetSecretValueOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("GetSecretValue", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("GetSecretValue", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.sm.GetSecretValueWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if isSecretNotFoundError(awsErr) {
+				return false, cocoa.NewSecretNotFoundError(utility.FromStringPtr(in.SecretId), awsErr)
+			}
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("GetSecretValue", stats)))
 		return nil, err
 	}
 	return out, nil
@@ -106,17 +124,21 @@ func (c *BasicSecretsManagerClient) DescribeSecret(ctx context.Context, in *secr
 
 	var out *secretsmanager.DescribeSecretOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("DescribeSecret", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DescribeSecret", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.sm.DescribeSecretWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if isSecretNotFoundError(awsErr) {
+				return false, cocoa.NewSecretNotFoundError(utility.FromStringPtr(in.SecretId), awsErr)
+			}
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DescribeSecret", stats)))
 		return nil, err
 	}
 
@@ -131,17 +153,18 @@ func (c *BasicSecretsManagerClient) ListSecrets(ctx context.Context, in *secrets
 
 	var out *secretsmanager.ListSecretsOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("ListSecrets", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("ListSecrets", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.sm.ListSecretsWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("ListSecrets", stats)))
 		return nil, err
 	}
 
@@ -156,17 +179,21 @@ func (c *BasicSecretsManagerClient) UpdateSecretValue(ctx context.Context, in *s
 
 	var out *secretsmanager.UpdateSecretOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("UpdateSecret", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("UpdateSecret", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.sm.UpdateSecretWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if isSecretNotFoundError(awsErr) {
+				return false, cocoa.NewSecretNotFoundError(utility.FromStringPtr(in.SecretId), awsErr)
+			}
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("UpdateSecret", stats)))
 		return nil, err
 	}
 	return out, nil
@@ -180,17 +207,21 @@ func (c *BasicSecretsManagerClient) TagResource(ctx context.Context, in *secrets
 
 	var out *secretsmanager.TagResourceOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("TagResource", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("TagResource", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.sm.TagResourceWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if isSecretNotFoundError(awsErr) {
+				return false, cocoa.NewSecretNotFoundError(utility.FromStringPtr(in.SecretId), awsErr)
+			}
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("TagResource", stats)))
 		return nil, err
 	}
 	return out, nil
@@ -204,22 +235,155 @@ func (c *BasicSecretsManagerClient) DeleteSecret(ctx context.Context, in *secret
 
 	var out *secretsmanager.DeleteSecretOutput
 	var err error
-	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("DeleteSecret", in)
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("DeleteSecret", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.sm.DeleteSecretWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if isSecretNotFoundError(awsErr) {
+				return false, cocoa.NewSecretNotFoundError(utility.FromStringPtr(in.SecretId), awsErr)
+			}
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("DeleteSecret", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// RestoreSecret cancels the scheduled deletion of a secret.
+func (c *BasicSecretsManagerClient) RestoreSecret(ctx context.Context, in *secretsmanager.RestoreSecretInput) (*secretsmanager.RestoreSecretOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *secretsmanager.RestoreSecretOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("RestoreSecret", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.sm.RestoreSecretWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if isSecretNotFoundError(awsErr) {
+				return false, cocoa.NewSecretNotFoundError(utility.FromStringPtr(in.SecretId), awsErr)
+			}
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("RestoreSecret", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// RotateSecret starts rotating a secret using its rotation function.
+func (c *BasicSecretsManagerClient) RotateSecret(ctx context.Context, in *secretsmanager.RotateSecretInput) (*secretsmanager.RotateSecretOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *secretsmanager.RotateSecretOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("RotateSecret", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.sm.RotateSecretWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if isSecretNotFoundError(awsErr) {
+				return false, cocoa.NewSecretNotFoundError(utility.FromStringPtr(in.SecretId), awsErr)
+			}
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("RotateSecret", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// CancelRotateSecret turns off automatic rotation for a secret and cancels the
+// rotation if one is in progress.
+func (c *BasicSecretsManagerClient) CancelRotateSecret(ctx context.Context, in *secretsmanager.CancelRotateSecretInput) (*secretsmanager.CancelRotateSecretOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *secretsmanager.CancelRotateSecretOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("CancelRotateSecret", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.sm.CancelRotateSecretWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if isSecretNotFoundError(awsErr) {
+				return false, cocoa.NewSecretNotFoundError(utility.FromStringPtr(in.SecretId), awsErr)
+			}
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}
 		}
 		return true, err
 	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("CancelRotateSecret", stats)))
 		return nil, err
 	}
 	return out, nil
 }
 
+// ValidateSecretPolicy checks that a resource-based policy is valid for a
+// secret before it is attached to the secret. Malformed policies are not
+// retried.
+func (c *BasicSecretsManagerClient) ValidateSecretPolicy(ctx context.Context, in *secretsmanager.ValidateResourcePolicyInput) (*secretsmanager.ValidateResourcePolicyOutput, error) {
+	if err := c.setup(); err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+
+	var out *secretsmanager.ValidateResourcePolicyOutput
+	var err error
+	if stats, err := awsutil.RetryWithStats(ctx, func() (bool, error) {
+		msg := awsutil.MakeAPILogMessage("ValidateResourcePolicy", in, awsutil.RequestIDFrom(ctx))
+		out, err = c.sm.ValidateResourcePolicyWithContext(ctx, in)
+		if awsErr, ok := err.(awserr.Error); ok {
+			c.LogAPICall(message.WrapError(awsErr, msg))
+			if c.isNonRetryableErrorCode(awsErr.Code()) {
+				return false, err
+			}
+		}
+		return true, err
+	}, c.GetRetryOptions()); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPIRetryFailureLogMessage("ValidateResourcePolicy", stats)))
+		return nil, err
+	}
+	return out, nil
+}
+
+// HealthCheck checks that the client can reach Secrets Manager by listing at
+// most one secret. It makes a single request without the client's retry options
+// so that it reports failures promptly instead of backing off, which makes it
+// suitable for readiness probes.
+func (c *BasicSecretsManagerClient) HealthCheck(ctx context.Context) error {
+	if err := c.setup(); err != nil {
+		return errors.Wrap(err, "setting up client")
+	}
+
+	in := &secretsmanager.ListSecretsInput{MaxResults: aws.Int64(1)}
+	if _, err := c.sm.ListSecretsWithContext(ctx, in); err != nil {
+		c.LogAPICall(message.WrapError(err, awsutil.MakeAPILogMessage("ListSecrets", in, awsutil.RequestIDFrom(ctx))))
+		return errors.Wrap(err, "checking Secrets Manager health by listing secrets")
+	}
+	return nil
+}
+
 // Close cleans up all resources owned by the client.
 func (c *BasicSecretsManagerClient) Close(ctx context.Context) error {
 	return c.BaseClient.Close(ctx)
@@ -234,6 +398,7 @@ func (c *BasicSecretsManagerClient) isNonRetryableErrorCode(code string) bool {
 		secretsmanager.ErrCodeInvalidRequestException,
 		secretsmanager.ErrCodeResourceNotFoundException,
 		secretsmanager.ErrCodeResourceExistsException,
+		secretsmanager.ErrCodeMalformedPolicyDocumentException,
 		request.InvalidParameterErrCode,
 		request.ParamRequiredErrCode:
 		return true
@@ -241,3 +406,13 @@ func (c *BasicSecretsManagerClient) isNonRetryableErrorCode(code string) bool {
 		return false
 	}
 }
+
+// isSecretNotFoundError returns whether or not the error returned from Secrets
+// Manager is because the secret cannot be found.
+func isSecretNotFoundError(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	return awsErr.Code() == secretsmanager.ErrCodeResourceNotFoundException
+}
@@ -5,10 +5,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
 	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/awsutil"
 	"github.com/evergreen-ci/cocoa/internal/testcase"
 	"github.com/evergreen-ci/cocoa/internal/testutil"
 	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -46,3 +52,106 @@ func TestBasicSecretsManagerClient(t *testing.T) {
 	}
 
 }
+
+func TestBasicSecretsManagerClientFIPSEndpoints(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+	defer cancel()
+
+	opts := awsutil.NewClientOptions().
+		SetCredentials(credentials.NewStaticCredentials("id", "secret", "")).
+		SetRegion("us-east-1").
+		SetFIPSEndpoints(true)
+	c, err := NewBasicSecretsManagerClient(*opts)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, c.Close(ctx))
+	}()
+
+	require.NoError(t, c.setup())
+	assert.Equal(t, "https://secretsmanager-fips.us-east-1.amazonaws.com", c.sm.Endpoint)
+}
+
+func TestBasicSecretsManagerClientWithRecordedFixtures(t *testing.T) {
+	const (
+		secretName = "cocoa/secret"
+		secretARN  = "arn:aws:secretsmanager:us-east-1:123456789012:secret:cocoa/secret-AbCdEf"
+	)
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, c *BasicSecretsManagerClient){
+		"CreateSecret": func(ctx context.Context, t *testing.T, c *BasicSecretsManagerClient) {
+			out, err := c.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
+				Name:         aws.String(secretName),
+				SecretString: aws.String("foo"),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			assert.Equal(t, secretARN, utility.FromStringPtr(out.ARN))
+			assert.Equal(t, secretName, utility.FromStringPtr(out.Name))
+		},
+		"GetSecretValueRetriesOnThrottling": func(ctx context.Context, t *testing.T, c *BasicSecretsManagerClient) {
+			out, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+				SecretId: aws.String(secretARN),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			assert.Equal(t, "foo", utility.FromStringPtr(out.SecretString))
+		},
+		"GetSecretValueFailsWithNonexistentSecret": func(ctx context.Context, t *testing.T, c *BasicSecretsManagerClient) {
+			out, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+				SecretId: aws.String(secretARN),
+			})
+			assert.True(t, errors.Is(err, cocoa.ErrSecretNotFound))
+			assert.Zero(t, out)
+		},
+		"DeleteSecret": func(ctx context.Context, t *testing.T, c *BasicSecretsManagerClient) {
+			out, err := c.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{
+				SecretId:                   aws.String(secretARN),
+				ForceDeleteWithoutRecovery: aws.Bool(true),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			assert.Equal(t, secretARN, utility.FromStringPtr(out.ARN))
+		},
+		"HealthCheck": func(ctx context.Context, t *testing.T, c *BasicSecretsManagerClient) {
+			assert.NoError(t, c.HealthCheck(ctx))
+		},
+		"HealthCheckFailsWithAccessDenied": func(ctx context.Context, t *testing.T, c *BasicSecretsManagerClient) {
+			err := c.HealthCheck(ctx)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "AccessDeniedException")
+		},
+		"ValidateSecretPolicy": func(ctx context.Context, t *testing.T, c *BasicSecretsManagerClient) {
+			out, err := c.ValidateSecretPolicy(ctx, &secretsmanager.ValidateResourcePolicyInput{
+				SecretId:       aws.String(secretARN),
+				ResourcePolicy: aws.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::123456789012:root"},"Action":"secretsmanager:GetSecretValue","Resource":"*"}]}`),
+			})
+			require.NoError(t, err)
+			require.NotZero(t, out)
+			assert.True(t, utility.FromBoolPtr(out.PolicyValidationPassed))
+		},
+		"ValidateSecretPolicyDoesNotRetryMalformedPolicy": func(ctx context.Context, t *testing.T, c *BasicSecretsManagerClient) {
+			out, err := c.ValidateSecretPolicy(ctx, &secretsmanager.ValidateResourcePolicyInput{
+				SecretId:       aws.String(secretARN),
+				ResourcePolicy: aws.String("not a policy"),
+			})
+			require.Error(t, err)
+			assert.Zero(t, out)
+			awsErr, ok := err.(awserr.Error)
+			require.True(t, ok)
+			assert.Equal(t, secretsmanager.ErrCodeMalformedPolicyDocumentException, awsErr.Code())
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultTestTimeout)
+			defer cancel()
+
+			c, err := NewBasicSecretsManagerClient(testutil.RecordedAWSOptions(t, "testdata/fixtures"))
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, c.Close(ctx))
+			}()
+
+			tCase(ctx, t, c)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package secret
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultSingleflightRequestTimeout is the default maximum amount of time
+// that a shared request to get a secret value can take.
+const defaultSingleflightRequestTimeout = time.Minute
+
+// SingleflightSecretsManagerClient wraps a cocoa.SecretsManagerClient so that
+// concurrent identical requests to get a secret value share a single API call
+// instead of each making their own. All other methods are passed through to
+// the wrapped client unchanged.
+type SingleflightSecretsManagerClient struct {
+	cocoa.SecretsManagerClient
+	group          singleflight.Group
+	validator      SecretValidator
+	requestTimeout time.Duration
+}
+
+// NewSingleflightSecretsManagerClient returns a new client that deduplicates
+// concurrent requests to get a secret value made using the given client.
+func NewSingleflightSecretsManagerClient(c cocoa.SecretsManagerClient) (*SingleflightSecretsManagerClient, error) {
+	if c == nil {
+		return nil, errors.New("missing client")
+	}
+	return &SingleflightSecretsManagerClient{
+		SecretsManagerClient: c,
+		requestTimeout:       defaultSingleflightRequestTimeout,
+	}, nil
+}
+
+// WithValidator sets a validator that checks each string secret value after
+// it is fetched. If the value is invalid, getting the secret value returns an
+// error instead of the value. By default, secret values are not validated.
+func (c *SingleflightSecretsManagerClient) WithValidator(v SecretValidator) *SingleflightSecretsManagerClient {
+	c.validator = v
+	return c
+}
+
+// WithRequestTimeout sets the maximum amount of time that a shared request to
+// get a secret value can take. By default, it is 1 minute. Non-positive values
+// are ignored.
+func (c *SingleflightSecretsManagerClient) WithRequestTimeout(timeout time.Duration) *SingleflightSecretsManagerClient {
+	if timeout > 0 {
+		c.requestTimeout = timeout
+	}
+	return c
+}
+
+// GetSecretValue gets the decrypted value of an existing secret. If there is
+// already a request in flight for the same secret ID, version ID, and version
+// stage, this waits for that request's result instead of making another
+// request. The output is shared by all the callers, so it must not be
+// modified. The shared request is not cancelled when the caller that started
+// it stops waiting, since other callers may still be waiting for it; instead,
+// it is bounded by the client's request timeout. Each caller stops waiting for
+// the result when its own context is done. If the client has a validator, the
+// secret value is validated once for all the callers.
+func (c *SingleflightSecretsManagerClient) GetSecretValue(ctx context.Context, in *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	if in == nil {
+		return c.SecretsManagerClient.GetSecretValue(ctx, in)
+	}
+
+	key := strings.Join([]string{
+		utility.FromStringPtr(in.SecretId),
+		utility.FromStringPtr(in.VersionId),
+		utility.FromStringPtr(in.VersionStage),
+	}, "\x00")
+	resChan := c.group.DoChan(key, func() (interface{}, error) {
+		reqCtx, cancel := context.WithTimeout(detachedContext{Context: ctx}, c.requestTimeout)
+		defer cancel()
+
+		out, err := c.SecretsManagerClient.GetSecretValue(reqCtx, in)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.validate(out); err != nil {
+			return nil, errors.Wrapf(err, "validating value of secret '%s'", utility.FromStringPtr(in.SecretId))
+		}
+		return out, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-resChan:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*secretsmanager.GetSecretValueOutput), nil
+	}
+}
+
+// detachedContext is a context that has the values of its parent context but
+// is never cancelled and has no deadline, even if its parent does.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// validate checks the string value of the secret with the validator, if
+// there is one. Binary secret values are not validated.
+func (c *SingleflightSecretsManagerClient) validate(out *secretsmanager.GetSecretValueOutput) error {
+	if c.validator == nil || out == nil || out.SecretString == nil {
+		return nil
+	}
+	return c.validator(*out.SecretString)
+}
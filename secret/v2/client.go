@@ -0,0 +1,202 @@
+//go:build awsv2
+// +build awsv2
+
+package secret
+
+import (
+	"context"
+
+	secretsmanagerv2 "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/evergreen-ci/cocoa/internal/awsv2"
+	"github.com/evergreen-ci/cocoa/secret"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+)
+
+// BasicSecretsManagerClient provides a cocoa.SecretsManagerClient
+// implementation that wraps the AWS Secrets Manager API using the AWS SDK for
+// Go v2. It takes and returns the same v1 SDK types as
+// secret.BasicSecretsManagerClient, so it can be used in place of it. It
+// supports retrying requests using exponential backoff and jitter. It is safe
+// for concurrent use.
+type BasicSecretsManagerClient struct {
+	base *awsv2.Client
+	sm   *secretsmanagerv2.Client
+}
+
+// NewBasicSecretsManagerClient creates a new AWS Secrets Manager client from
+// the given options.
+func NewBasicSecretsManagerClient(opts awsutil.ClientOptions) (*BasicSecretsManagerClient, error) {
+	c, err := awsv2.NewClient(opts, isNonRetryableErrorCode)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting up client")
+	}
+	return &BasicSecretsManagerClient{
+		base: c,
+		sm:   secretsmanagerv2.NewFromConfig(c.Config),
+	}, nil
+}
+
+// Close closes the client and cleans up its resources.
+func (c *BasicSecretsManagerClient) Close(ctx context.Context) error {
+	return c.base.Close(ctx)
+}
+
+// CreateSecret validates the input and creates a new secret.
+func (c *BasicSecretsManagerClient) CreateSecret(ctx context.Context, in *secretsmanager.CreateSecretInput) (*secretsmanager.CreateSecretOutput, error) {
+	if err := secret.ValidateCreateSecretInput(in); err != nil {
+		return nil, errors.Wrap(err, "invalid input")
+	}
+
+	var v2In secretsmanagerv2.CreateSecretInput
+	var out secretsmanager.CreateSecretOutput
+	if err := c.base.Call(ctx, "CreateSecret", in, &v2In, &out, func() (interface{}, error) {
+		return c.sm.CreateSecret(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSecretValue gets the decrypted value of an existing secret.
+func (c *BasicSecretsManagerClient) GetSecretValue(ctx context.Context, in *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	var v2In secretsmanagerv2.GetSecretValueInput
+	var out secretsmanager.GetSecretValueOutput
+	if err := c.base.Call(ctx, "GetSecretValue", in, &v2In, &out, func() (interface{}, error) {
+		return c.sm.GetSecretValue(ctx, &v2In)
+	}); err != nil {
+		return nil, convertSecretNotFoundError(err, v2In.SecretId)
+	}
+	return &out, nil
+}
+
+// DescribeSecret gets the metadata information about a secret.
+func (c *BasicSecretsManagerClient) DescribeSecret(ctx context.Context, in *secretsmanager.DescribeSecretInput) (*secretsmanager.DescribeSecretOutput, error) {
+	var v2In secretsmanagerv2.DescribeSecretInput
+	var out secretsmanager.DescribeSecretOutput
+	if err := c.base.Call(ctx, "DescribeSecret", in, &v2In, &out, func() (interface{}, error) {
+		return c.sm.DescribeSecret(ctx, &v2In)
+	}); err != nil {
+		return nil, convertSecretNotFoundError(err, v2In.SecretId)
+	}
+	return &out, nil
+}
+
+// ListSecrets lists the metadata information for secrets matching the filters.
+func (c *BasicSecretsManagerClient) ListSecrets(ctx context.Context, in *secretsmanager.ListSecretsInput) (*secretsmanager.ListSecretsOutput, error) {
+	var v2In secretsmanagerv2.ListSecretsInput
+	var out secretsmanager.ListSecretsOutput
+	if err := c.base.Call(ctx, "ListSecrets", in, &v2In, &out, func() (interface{}, error) {
+		return c.sm.ListSecrets(ctx, &v2In)
+	}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateSecretValue updates the value of an existing secret.
+func (c *BasicSecretsManagerClient) UpdateSecretValue(ctx context.Context, in *secretsmanager.UpdateSecretInput) (*secretsmanager.UpdateSecretOutput, error) {
+	var v2In secretsmanagerv2.UpdateSecretInput
+	var out secretsmanager.UpdateSecretOutput
+	if err := c.base.Call(ctx, "UpdateSecret", in, &v2In, &out, func() (interface{}, error) {
+		return c.sm.UpdateSecret(ctx, &v2In)
+	}); err != nil {
+		return nil, convertSecretNotFoundError(err, v2In.SecretId)
+	}
+	return &out, nil
+}
+
+// DeleteSecret deletes an existing secret.
+func (c *BasicSecretsManagerClient) DeleteSecret(ctx context.Context, in *secretsmanager.DeleteSecretInput) (*secretsmanager.DeleteSecretOutput, error) {
+	var v2In secretsmanagerv2.DeleteSecretInput
+	var out secretsmanager.DeleteSecretOutput
+	if err := c.base.Call(ctx, "DeleteSecret", in, &v2In, &out, func() (interface{}, error) {
+		return c.sm.DeleteSecret(ctx, &v2In)
+	}); err != nil {
+		return nil, convertSecretNotFoundError(err, v2In.SecretId)
+	}
+	return &out, nil
+}
+
+// RestoreSecret cancels the scheduled deletion of a secret.
+func (c *BasicSecretsManagerClient) RestoreSecret(ctx context.Context, in *secretsmanager.RestoreSecretInput) (*secretsmanager.RestoreSecretOutput, error) {
+	var v2In secretsmanagerv2.RestoreSecretInput
+	var out secretsmanager.RestoreSecretOutput
+	if err := c.base.Call(ctx, "RestoreSecret", in, &v2In, &out, func() (interface{}, error) {
+		return c.sm.RestoreSecret(ctx, &v2In)
+	}); err != nil {
+		return nil, convertSecretNotFoundError(err, v2In.SecretId)
+	}
+	return &out, nil
+}
+
+// RotateSecret starts rotating a secret using its rotation function.
+func (c *BasicSecretsManagerClient) RotateSecret(ctx context.Context, in *secretsmanager.RotateSecretInput) (*secretsmanager.RotateSecretOutput, error) {
+	var v2In secretsmanagerv2.RotateSecretInput
+	var out secretsmanager.RotateSecretOutput
+	if err := c.base.Call(ctx, "RotateSecret", in, &v2In, &out, func() (interface{}, error) {
+		return c.sm.RotateSecret(ctx, &v2In)
+	}); err != nil {
+		return nil, convertSecretNotFoundError(err, v2In.SecretId)
+	}
+	return &out, nil
+}
+
+// CancelRotateSecret turns off automatic rotation for a secret and cancels the
+// rotation if one is in progress.
+func (c *BasicSecretsManagerClient) CancelRotateSecret(ctx context.Context, in *secretsmanager.CancelRotateSecretInput) (*secretsmanager.CancelRotateSecretOutput, error) {
+	var v2In secretsmanagerv2.CancelRotateSecretInput
+	var out secretsmanager.CancelRotateSecretOutput
+	if err := c.base.Call(ctx, "CancelRotateSecret", in, &v2In, &out, func() (interface{}, error) {
+		return c.sm.CancelRotateSecret(ctx, &v2In)
+	}); err != nil {
+		return nil, convertSecretNotFoundError(err, v2In.SecretId)
+	}
+	return &out, nil
+}
+
+// TagResource tags an existing secret.
+func (c *BasicSecretsManagerClient) TagResource(ctx context.Context, in *secretsmanager.TagResourceInput) (*secretsmanager.TagResourceOutput, error) {
+	var v2In secretsmanagerv2.TagResourceInput
+	var out secretsmanager.TagResourceOutput
+	if err := c.base.Call(ctx, "TagResource", in, &v2In, &out, func() (interface{}, error) {
+		return c.sm.TagResource(ctx, &v2In)
+	}); err != nil {
+		return nil, convertSecretNotFoundError(err, v2In.SecretId)
+	}
+	return &out, nil
+}
+
+// isNonRetryableErrorCode returns whether or not the error code from Secrets
+// Manager is known to be not retryable.
+func isNonRetryableErrorCode(code string) bool {
+	switch code {
+	case "AccessDeniedException",
+		secretsmanager.ErrCodeInvalidParameterException,
+		secretsmanager.ErrCodeInvalidRequestException,
+		secretsmanager.ErrCodeResourceNotFoundException,
+		secretsmanager.ErrCodeResourceExistsException,
+		secretsmanager.ErrCodeMalformedPolicyDocumentException,
+		request.InvalidParameterErrCode,
+		request.ParamRequiredErrCode:
+		return true
+	default:
+		return false
+	}
+}
+
+// convertSecretNotFoundError returns a cocoa.SecretNotFoundError if the error
+// is because Secrets Manager could not find the secret. Otherwise, it returns
+// the error as-is.
+func convertSecretNotFoundError(err error, id *string) error {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == secretsmanager.ErrCodeResourceNotFoundException {
+		return cocoa.NewSecretNotFoundError(utility.FromStringPtr(id), awsErr)
+	}
+	return err
+}
@@ -0,0 +1,177 @@
+//go:build awsv2
+// +build awsv2
+
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/cocoa"
+	"github.com/evergreen-ci/cocoa/awsutil"
+	"github.com/evergreen-ci/utility"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const defaultTestTimeout = time.Second
+
+// fakeSecretsManagerAPI is a fake Secrets Manager API server that records the
+// requests and responds with the response for the operation.
+type fakeSecretsManagerAPI struct {
+	mu        sync.Mutex
+	requests  map[string][]map[string]interface{}
+	responses map[string]fakeResponse
+}
+
+type fakeResponse struct {
+	status int
+	body   string
+}
+
+func newFakeSecretsManagerAPI() *fakeSecretsManagerAPI {
+	return &fakeSecretsManagerAPI{
+		requests:  map[string][]map[string]interface{}{},
+		responses: map[string]fakeResponse{},
+	}
+}
+
+func (f *fakeSecretsManagerAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	op := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "secretsmanager.")
+	b, _ := io.ReadAll(r.Body)
+	var body map[string]interface{}
+	_ = json.Unmarshal(b, &body)
+
+	f.mu.Lock()
+	f.requests[op] = append(f.requests[op], body)
+	resp, ok := f.responses[op]
+	f.mu.Unlock()
+	if !ok {
+		resp = fakeResponse{status: http.StatusOK, body: "{}"}
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.WriteHeader(resp.status)
+	_, _ = w.Write([]byte(resp.body))
+}
+
+func (f *fakeSecretsManagerAPI) getRequests(op string) []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[op]
+}
+
+func TestBasicSecretsManagerClient(t *testing.T) {
+	assert.Implements(t, (*cocoa.SecretsManagerClient)(nil), &BasicSecretsManagerClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newClient := func(t *testing.T, url string) *BasicSecretsManagerClient {
+		opts := awsutil.NewClientOptions().
+			SetCredentials(credentials.NewStaticCredentials("id", "secret", "")).
+			SetRegion("us-east-1").
+			SetEndpoint(url).
+			SetRetryOptions(utility.RetryOptions{MaxAttempts: 3, MinDelay: time.Millisecond, MaxDelay: time.Millisecond})
+		c, err := NewBasicSecretsManagerClient(*opts)
+		require.NoError(t, err)
+		return c
+	}
+
+	for tName, tCase := range map[string]func(ctx context.Context, t *testing.T, api *fakeSecretsManagerAPI, c *BasicSecretsManagerClient){
+		"GetSecretValueConvertsInputAndOutput": func(ctx context.Context, t *testing.T, api *fakeSecretsManagerAPI, c *BasicSecretsManagerClient) {
+			api.responses["GetSecretValue"] = fakeResponse{
+				status: http.StatusOK,
+				body:   `{"ARN":"secret_arn","Name":"name","SecretString":"value","VersionStages":["AWSCURRENT"],"CreatedDate":1.6e9}`,
+			}
+
+			out, err := c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String("name")})
+			require.NoError(t, err)
+			assert.Equal(t, "secret_arn", aws.StringValue(out.ARN))
+			assert.Equal(t, "value", aws.StringValue(out.SecretString))
+			assert.Equal(t, []string{"AWSCURRENT"}, aws.StringValueSlice(out.VersionStages))
+			assert.Equal(t, int64(1.6e9), aws.TimeValue(out.CreatedDate).Unix())
+
+			reqs := api.getRequests("GetSecretValue")
+			require.Len(t, reqs, 1)
+			assert.Equal(t, "name", reqs[0]["SecretId"])
+		},
+		"UpdateSecretValueUsesUpdateSecret": func(ctx context.Context, t *testing.T, api *fakeSecretsManagerAPI, c *BasicSecretsManagerClient) {
+			api.responses["UpdateSecret"] = fakeResponse{
+				status: http.StatusOK,
+				body:   `{"ARN":"secret_arn","VersionId":"version"}`,
+			}
+
+			out, err := c.UpdateSecretValue(ctx, &secretsmanager.UpdateSecretInput{
+				SecretId:     aws.String("name"),
+				SecretString: aws.String("new_value"),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "version", aws.StringValue(out.VersionId))
+
+			reqs := api.getRequests("UpdateSecret")
+			require.Len(t, reqs, 1)
+			assert.Equal(t, "new_value", reqs[0]["SecretString"])
+		},
+		"CreateSecretValidatesInput": func(ctx context.Context, t *testing.T, api *fakeSecretsManagerAPI, c *BasicSecretsManagerClient) {
+			out, err := c.CreateSecret(ctx, &secretsmanager.CreateSecretInput{})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+			assert.Empty(t, api.getRequests("CreateSecret"))
+		},
+		"ReturnsV1ErrorsWithoutRetryingNonRetryableErrors": func(ctx context.Context, t *testing.T, api *fakeSecretsManagerAPI, c *BasicSecretsManagerClient) {
+			api.responses["DescribeSecret"] = fakeResponse{
+				status: http.StatusBadRequest,
+				body:   `{"__type":"ResourceNotFoundException","Message":"Secrets Manager can't find the specified secret."}`,
+			}
+
+			out, err := c.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: aws.String("name")})
+			require.Error(t, err)
+			assert.Zero(t, out)
+			assert.True(t, errors.Is(err, cocoa.ErrSecretNotFound))
+			var awsErr awserr.Error
+			require.True(t, errors.As(err, &awsErr))
+			assert.Equal(t, secretsmanager.ErrCodeResourceNotFoundException, awsErr.Code())
+			assert.Len(t, api.getRequests("DescribeSecret"), 1)
+		},
+		"RetriesRetryableErrors": func(ctx context.Context, t *testing.T, api *fakeSecretsManagerAPI, c *BasicSecretsManagerClient) {
+			api.responses["ListSecrets"] = fakeResponse{
+				status: http.StatusInternalServerError,
+				body:   `{"__type":"InternalServiceError","Message":"internal error"}`,
+			}
+
+			out, err := c.ListSecrets(ctx, &secretsmanager.ListSecretsInput{})
+			assert.Error(t, err)
+			assert.Zero(t, out)
+			assert.Len(t, api.getRequests("ListSecrets"), 3)
+		},
+	} {
+		t.Run(tName, func(t *testing.T) {
+			tctx, tcancel := context.WithTimeout(ctx, defaultTestTimeout)
+			defer tcancel()
+
+			api := newFakeSecretsManagerAPI()
+			srv := httptest.NewServer(api)
+			defer srv.Close()
+
+			c := newClient(t, srv.URL)
+			defer func() {
+				assert.NoError(t, c.Close(tctx))
+			}()
+
+			tCase(tctx, t, api, c)
+		})
+	}
+}
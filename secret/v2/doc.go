@@ -0,0 +1,9 @@
+//go:build awsv2
+// +build awsv2
+
+/*
+Package secret provides a cocoa.SecretsManagerClient implementation that uses
+the AWS SDK for Go v2. It is only compiled with the awsv2 build tag. See
+docs/aws-sdk-v2-migration.md for how to migrate to it.
+*/
+package secret
@@ -0,0 +1,35 @@
+package secret
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pkg/errors"
+)
+
+// ExtractSecretValue returns the value of the secret, which is either its
+// string value or its binary value. If the secret has a string value, that
+// takes precedence. Secrets Manager transmits binary values base64-encoded,
+// but the AWS SDK decodes them when it unmarshals the response, so the binary
+// value is returned as-is.
+func ExtractSecretValue(out *secretsmanager.GetSecretValueOutput) (string, error) {
+	if out == nil {
+		return "", errors.New("cannot extract value from nil output")
+	}
+	if out.SecretString != nil {
+		return *out.SecretString, nil
+	}
+	if out.SecretBinary != nil {
+		return string(out.SecretBinary), nil
+	}
+	return "", errors.New("secret has neither a string nor a binary value")
+}
+
+// CreateSecretBinaryInput returns the input to create a secret with the given
+// name whose value is the binary data. The AWS SDK base64-encodes the data
+// when it sends the request, so the data should not already be encoded.
+func CreateSecretBinaryInput(name string, data []byte) *secretsmanager.CreateSecretInput {
+	return &secretsmanager.CreateSecretInput{
+		Name:         aws.String(name),
+		SecretBinary: data,
+	}
+}
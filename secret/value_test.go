@@ -0,0 +1,56 @@
+package secret
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/evergreen-ci/utility"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractSecretValue(t *testing.T) {
+	t.Run("ReturnsSecretString", func(t *testing.T) {
+		val, err := ExtractSecretValue(&secretsmanager.GetSecretValueOutput{SecretString: aws.String("value")})
+		require.NoError(t, err)
+		assert.Equal(t, "value", val)
+	})
+	t.Run("ReturnsEmptySecretString", func(t *testing.T) {
+		val, err := ExtractSecretValue(&secretsmanager.GetSecretValueOutput{SecretString: aws.String("")})
+		require.NoError(t, err)
+		assert.Empty(t, val)
+	})
+	t.Run("ReturnsSecretBinary", func(t *testing.T) {
+		val, err := ExtractSecretValue(&secretsmanager.GetSecretValueOutput{SecretBinary: []byte{0x00, 0xff, 'a'}})
+		require.NoError(t, err)
+		assert.Equal(t, "\x00\xffa", val)
+	})
+	t.Run("PrefersSecretString", func(t *testing.T) {
+		val, err := ExtractSecretValue(&secretsmanager.GetSecretValueOutput{
+			SecretString: aws.String("string"),
+			SecretBinary: []byte("binary"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "string", val)
+	})
+	t.Run("FailsWithoutValue", func(t *testing.T) {
+		val, err := ExtractSecretValue(&secretsmanager.GetSecretValueOutput{})
+		assert.Error(t, err)
+		assert.Zero(t, val)
+	})
+	t.Run("FailsWithNilOutput", func(t *testing.T) {
+		val, err := ExtractSecretValue(nil)
+		assert.Error(t, err)
+		assert.Zero(t, val)
+	})
+}
+
+func TestCreateSecretBinaryInput(t *testing.T) {
+	in := CreateSecretBinaryInput("name", []byte{0x00, 0x01})
+	require.NotZero(t, in)
+	assert.Equal(t, "name", utility.FromStringPtr(in.Name))
+	assert.Equal(t, []byte{0x00, 0x01}, in.SecretBinary)
+	assert.Nil(t, in.SecretString)
+	assert.NoError(t, ValidateCreateSecretInput(in))
+}
@@ -23,6 +23,13 @@ type SecretsManagerClient interface {
 	UpdateSecretValue(ctx context.Context, in *secretsmanager.UpdateSecretInput) (*secretsmanager.UpdateSecretOutput, error)
 	// DeleteSecret deletes an existing secret.
 	DeleteSecret(ctx context.Context, in *secretsmanager.DeleteSecretInput) (*secretsmanager.DeleteSecretOutput, error)
+	// RestoreSecret cancels the scheduled deletion of a secret.
+	RestoreSecret(ctx context.Context, in *secretsmanager.RestoreSecretInput) (*secretsmanager.RestoreSecretOutput, error)
+	// RotateSecret starts rotating a secret using its rotation function.
+	RotateSecret(ctx context.Context, in *secretsmanager.RotateSecretInput) (*secretsmanager.RotateSecretOutput, error)
+	// CancelRotateSecret turns off automatic rotation for a secret and cancels
+	// the rotation if one is in progress.
+	CancelRotateSecret(ctx context.Context, in *secretsmanager.CancelRotateSecretInput) (*secretsmanager.CancelRotateSecretOutput, error)
 	// TagResource adds tags to an existing secret.
 	TagResource(ctx context.Context, in *secretsmanager.TagResourceInput) (*secretsmanager.TagResourceOutput, error)
 	// Close closes the client and cleans up its resources. Implementations
@@ -3,7 +3,6 @@ package tag
 import (
 	"context"
 
-	"github.com/mongodb/grip"
 	"github.com/mongodb/grip/message"
 	"github.com/pkg/errors"
 
@@ -58,10 +57,10 @@ func (c *BasicTagClient) GetResources(ctx context.Context, in *resourcegroupstag
 	var out *resourcegroupstaggingapi.GetResourcesOutput
 	var err error
 	if err := utility.Retry(ctx, func() (bool, error) {
-		msg := awsutil.MakeAPILogMessage("GetResources", in)
+		msg := awsutil.MakeAPILogMessage("GetResources", in, awsutil.RequestIDFrom(ctx))
 		out, err = c.rgt.GetResourcesWithContext(ctx, in)
 		if awsErr, ok := err.(awserr.Error); ok {
-			grip.Debug(message.WrapError(awsErr, msg))
+			c.LogAPICall(message.WrapError(awsErr, msg))
 			if c.isNonRetryableErrorCode(awsErr.Code()) {
 				return false, err
 			}